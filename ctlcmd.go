@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/llehouerou/waves/internal/ctl"
+)
+
+const ctlUsage = `usage: waves ctl COMMAND [ARGS]
+
+commands:
+  play | pause | toggle | stop | next | prev
+  seek [+|-]SECONDS|MM:SS   seek to a position, or by an offset with + or -
+  volume [+|-]PERCENT       set the volume, or change it with + or -
+  add PATH...               append files and folders to the queue
+  insert PATH...            insert files and folders after the playing track
+  queue                     list the queue
+  status                    print the playback state as JSON
+  follow                    print playback events as JSON lines
+  shutdown                  stop the daemon started by "waves daemon"
+
+The socket is $WAVES_SOCKET, or waves/waves.sock in $XDG_RUNTIME_DIR.
+`
+
+// runCtl sends a command to the running waves through its control socket.
+func runCtl(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(stderr, ctlUsage)
+		return 2
+	}
+	req, err := ctlRequest(args[0], args[1:])
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n\n%s", err, ctlUsage)
+		return 2
+	}
+
+	path, err := ctl.SocketPath()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error locating the control socket: %v\n", err)
+		return 1
+	}
+	client, err := ctl.Dial(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "waves is not running (%v)\n", err)
+		return 1
+	}
+	defer client.Close()
+
+	if req.Command == ctl.CmdFollow {
+		enc := json.NewEncoder(stdout)
+		err := client.Follow(func(e ctl.Event) error { return enc.Encode(e) })
+		if err != nil && !errors.Is(err, io.EOF) {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	resp, err := client.Call(req)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	switch req.Command {
+	case ctl.CmdStatus:
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(resp.Status)
+	case ctl.CmdQueue:
+		printQueue(stdout, resp.Queue)
+	case ctl.CmdAdd, ctl.CmdInsert:
+		fmt.Fprintf(stdout, "Added %d tracks\n", resp.Added)
+	}
+	return 0
+}
+
+// ctlRequest builds the request of a command line.
+func ctlRequest(cmd string, args []string) (ctl.Request, error) {
+	req := ctl.Request{Command: cmd}
+	switch cmd {
+	case ctl.CmdPlay, ctl.CmdPause, ctl.CmdToggle, ctl.CmdStop, ctl.CmdNext, ctl.CmdPrev,
+		ctl.CmdQueue, ctl.CmdStatus, ctl.CmdFollow, ctl.CmdShutdown:
+		if len(args) != 0 {
+			return req, fmt.Errorf("%s takes no argument", cmd)
+		}
+	case ctl.CmdSeek, ctl.CmdVolume:
+		if len(args) != 1 {
+			return req, fmt.Errorf("%s takes one argument", cmd)
+		}
+		value, relative, err := parseCtlValue(args[0], cmd == ctl.CmdSeek)
+		if err != nil {
+			return req, err
+		}
+		req.Value, req.Relative = value, relative
+	case ctl.CmdAdd, ctl.CmdInsert:
+		if len(args) == 0 {
+			return req, fmt.Errorf("%s takes at least one path", cmd)
+		}
+		for _, arg := range args {
+			path, err := filepath.Abs(arg)
+			if err != nil {
+				return req, err
+			}
+			req.Paths = append(req.Paths, path)
+		}
+	default:
+		return req, fmt.Errorf("unknown command %q", cmd)
+	}
+	return req, nil
+}
+
+// parseCtlValue parses a number, relative when signed. Positions can also
+// be written MM:SS.
+func parseCtlValue(s string, position bool) (value float64, relative bool, err error) {
+	sign := 1.0
+	if strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-") {
+		relative = true
+		if s[0] == '-' {
+			sign = -1
+		}
+		s = s[1:]
+	}
+	if minutes, secs, ok := strings.Cut(s, ":"); ok && position {
+		m, err1 := strconv.Atoi(minutes)
+		sec, err2 := strconv.ParseFloat(secs, 64)
+		if err1 != nil || err2 != nil {
+			return 0, false, fmt.Errorf("invalid position %q", s)
+		}
+		return sign * (float64(m)*60 + sec), relative, nil
+	}
+	value, err = strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid number %q", s)
+	}
+	return sign * value, relative, nil
+}
+
+// printQueue lists the queue, marking the current track.
+func printQueue(w io.Writer, q *ctl.Queue) {
+	if q == nil {
+		return
+	}
+	for i, t := range q.Tracks {
+		mark := " "
+		if i == q.Index {
+			mark = ">"
+		}
+		name := t.Title
+		if t.Artist != "" {
+			name = t.Artist + " - " + t.Title
+		}
+		fmt.Fprintf(w, "%s %3d  %s\n", mark, i+1, name)
+	}
+}
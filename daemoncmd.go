@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/llehouerou/waves/internal/config"
+	"github.com/llehouerou/waves/internal/ctl"
+	"github.com/llehouerou/waves/internal/daemon"
+	"github.com/llehouerou/waves/internal/state"
+)
+
+const daemonUsage = `usage: waves daemon
+
+Plays without the interface until "waves ctl shutdown", SIGINT or SIGTERM.
+Running waves then attaches to the daemon, and quitting it detaches.
+`
+
+// runDaemon runs waves headless, keeping playback going between attached
+// interfaces.
+func runDaemon(args []string, stderr io.Writer) int {
+	if len(args) != 0 {
+		fmt.Fprint(stderr, daemonUsage)
+		return 2
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	stateMgr, err := state.Open()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error opening state: %v\n", err)
+		return 1
+	}
+	defer stateMgr.Close()
+
+	d, err := daemon.New(cfg, stateMgr)
+	if errors.Is(err, ctl.ErrRunning) {
+		fmt.Fprintln(stderr, "waves is already running")
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error starting the daemon: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stderr, "waves daemon listening on %s\n", d.SocketPath())
+	for _, err := range d.Errors() {
+		fmt.Fprintf(stderr, "Error starting the %v\n", err)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	select {
+	case <-signals:
+	case <-d.Done():
+	}
+
+	if err := d.Close(); err != nil {
+		fmt.Fprintf(stderr, "Error stopping the daemon: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -36,6 +36,7 @@ require (
 	github.com/stretchr/testify v1.11.1
 	go.senan.xyz/taglib v0.11.1
 	golang.org/x/sys v0.39.0
+	golang.org/x/text v0.31.0
 	modernc.org/sqlite v1.40.1
 )
 
@@ -76,7 +77,6 @@ require (
 	github.com/tetratelabs/wazero v1.11.0 // indirect
 	github.com/xo/terminfo v0.0.0-20220910002029-abceb7e1c41e // indirect
 	golang.org/x/exp v0.0.0-20250620022241-b7579e27df2b // indirect
-	golang.org/x/text v0.31.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 	modernc.org/libc v1.66.10 // indirect
 	modernc.org/mathutil v1.7.1 // indirect
@@ -6,17 +6,20 @@ package albumpreset
 type GroupField int
 
 const (
-	GroupFieldArtist  GroupField = iota // Album Artist
-	GroupFieldGenre                     // Genre
-	GroupFieldLabel                     // Label/Publisher
-	GroupFieldYear                      // Year from BestDate
-	GroupFieldMonth                     // Month from BestDate
-	GroupFieldWeek                      // Week from BestDate
-	GroupFieldAddedAt                   // When added (Today, This Week, etc.)
+	GroupFieldArtist     GroupField = iota // Album Artist
+	GroupFieldGenre                        // Genre
+	GroupFieldLabel                        // Label/Publisher
+	GroupFieldYear                         // Year from BestDate
+	GroupFieldMonth                        // Month from BestDate
+	GroupFieldWeek                         // Week from BestDate
+	GroupFieldAddedAt                      // When added (Today, This Week, etc.)
+	GroupFieldPlayCount                    // Play count range (Never Played, Played Once, etc.)
+	GroupFieldLastPlayed                   // When last played (Today, This Week, etc.)
+	GroupFieldRating                       // Album rating (5 Stars, Unrated, etc.)
 )
 
 // GroupFieldCount is the total number of group fields.
-const GroupFieldCount = 7
+const GroupFieldCount = 10
 
 // SortField represents a single sort field for multi-field sorting.
 type SortField int
@@ -29,10 +32,14 @@ const (
 	SortFieldAlbum
 	SortFieldTrackCount
 	SortFieldLabel
+	SortFieldPlayCount
+	SortFieldLastPlayed
+	SortFieldSkipCount
+	SortFieldRating
 )
 
 // SortFieldCount is the total number of sort fields.
-const SortFieldCount = 7
+const SortFieldCount = 11
 
 // SortOrder specifies ascending or descending.
 type SortOrder int
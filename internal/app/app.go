@@ -3,24 +3,32 @@ package app
 
 import (
 	"os"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/llehouerou/waves/internal/app/navctl"
 	"github.com/llehouerou/waves/internal/app/popupctl"
+	"github.com/llehouerou/waves/internal/bookmarks"
 	"github.com/llehouerou/waves/internal/config"
+	"github.com/llehouerou/waves/internal/ctl"
+	"github.com/llehouerou/waves/internal/daemon"
 	"github.com/llehouerou/waves/internal/downloads"
+	"github.com/llehouerou/waves/internal/errmsg"
 	"github.com/llehouerou/waves/internal/export"
+	"github.com/llehouerou/waves/internal/history"
+	"github.com/llehouerou/waves/internal/httpapi"
 	"github.com/llehouerou/waves/internal/keymap"
 	"github.com/llehouerou/waves/internal/lastfm"
 	"github.com/llehouerou/waves/internal/library"
+	"github.com/llehouerou/waves/internal/loudness"
 	"github.com/llehouerou/waves/internal/lyrics"
+	"github.com/llehouerou/waves/internal/mpd"
 	"github.com/llehouerou/waves/internal/mpris"
 	"github.com/llehouerou/waves/internal/navigator"
 	"github.com/llehouerou/waves/internal/notify"
 	"github.com/llehouerou/waves/internal/playback"
-	"github.com/llehouerou/waves/internal/player"
 	"github.com/llehouerou/waves/internal/playlist"
 	"github.com/llehouerou/waves/internal/playlists"
 	"github.com/llehouerou/waves/internal/radio"
@@ -28,9 +36,11 @@ import (
 	"github.com/llehouerou/waves/internal/state"
 	"github.com/llehouerou/waves/internal/ui/albumart"
 	dlview "github.com/llehouerou/waves/internal/ui/downloads"
+	histview "github.com/llehouerou/waves/internal/ui/history"
 	"github.com/llehouerou/waves/internal/ui/jobbar"
 	"github.com/llehouerou/waves/internal/ui/librarybrowser"
 	"github.com/llehouerou/waves/internal/ui/queuepanel"
+	"github.com/llehouerou/waves/internal/waveform"
 )
 
 // loadingPhase represents the current state of the loading screen.
@@ -51,13 +61,18 @@ type Model struct {
 	Library              *library.Library
 	Playlists            *playlists.Playlists
 	Downloads            *downloads.Manager
+	DownloadControl      downloads.Control // Changes the downloads, in the daemon when attached
 	DownloadsView        dlview.Model
+	HistoryView          histview.Model
 	Popups               *popupctl.Manager
 	Input                InputManager
 	Layout               LayoutManager
 	PlaybackService      playback.Service
 	playbackSub          *playback.Subscription
 	mprisAdapter         *mpris.Adapter
+	ctlServer            *ctl.Server
+	mpdServer            *mpd.Server
+	httpServer           *httpapi.Server
 	notifier             notify.Notifier
 	lastNowPlayingID     uint32
 	notificationsConfig  config.NotificationsConfig
@@ -81,6 +96,29 @@ type Model struct {
 	tickRunning bool
 	Favorites   map[int64]bool // Track IDs that are favorited
 
+	// Spectrum analyzer and level meters of the expanded player bar
+	viz visualizerState
+
+	// Waveforms of the current and next tracks, for the seek bar
+	waveforms waveformState
+
+	// Bookmarks of the playing track, and the start of the A-B loop being set
+	bookmarks bookmarkState
+	loopStart loopMark
+
+	// Playback service and what follows it, nil when attached
+	playback *daemon.Playback
+
+	// Records what was played, listed in the history view
+	history historyState
+
+	// Set when attached to a daemon, which owns the playback (see Attach)
+	remote      *ctl.Remote
+	remoteQueue *playlist.PlayingQueue // Copy of the daemon queue, shown by the queue panel
+
+	// How rating keys change ratings, and whether ratings go to file tags
+	ratings ratingState
+
 	// Last.fm scrobbling
 	Lastfm          *lastfm.Client       // nil if not configured
 	LastfmSession   *state.LastfmSession // nil if not linked
@@ -98,6 +136,13 @@ type Model struct {
 	ExportJobs   map[string]*export.Job
 	ExportParams map[string]export.Params // Active export params by job ID
 
+	// Loudness analysis (nil when idle)
+	LoudnessJob     *loudness.Job
+	LoudnessPending []loudness.Album // Queued while a canceled job winds down
+
+	// Waveform computation after library scans (nil when idle)
+	WaveformJob *waveform.Job
+
 	// Lyrics
 	LyricsSource *lyrics.Source
 
@@ -132,25 +177,95 @@ func (m Model) Init() tea.Cmd {
 			m.startInitialization(),
 			ShowLoadingAfterDelayCmd(), // Show loading screen after 400ms if init not done
 			WatchStderr(),              // Watch for stderr output from C libraries
+			m.watchHistory(),           // Refresh the history view on new plays
 		)
 	}
-	return tea.Batch(m.WatchServiceEvents(), WatchStderr())
+	return tea.Batch(m.WatchServiceEvents(), WatchStderr(), m.watchHistory())
 }
 
 // New creates a new application model with deferred initialization.
 // The actual loading happens asynchronously after the UI starts.
 func New(cfg *config.Config, stateMgr *state.Manager) (Model, error) {
 	lib := library.New(stateMgr.DB())
-	pls := playlists.New(stateMgr.DB(), lib)
-	dl := downloads.New(stateMgr.DB())
+	p, err := daemon.NewPlayer(cfg, stateMgr, lib)
+	if err != nil {
+		return Model{}, err
+	}
+
+	// Create playback service wrapping player and queue
 	queue := playlist.NewQueue()
-	p := player.New()
+	pb := daemon.NewPlayback(cfg, stateMgr.DB(), lib, p, queue)
+	svc := pb.Service
+	m := newModel(cfg, stateMgr, svc, queue)
+	m.playback = pb
+	m.history.recorder = pb.Recorder
+
+	// Initialize MPRIS adapter (optional - app works fine without D-Bus)
+	m.mprisAdapter, _ = mpris.New(svc, lib, stateMgr)
+
+	// Serve the control socket for "waves ctl" (optional - app works fine without it)
+	if path, err := ctl.SocketPath(); err == nil {
+		m.ctlServer, _ = ctl.Listen(path, svc, lib, stateMgr)
+	}
+
+	// Serve MPD clients when enabled, showing why it failed once started
+	var serveErrs []string
+	if mpdCfg := cfg.GetMPDConfig(); mpdCfg.Enabled {
+		var err error
+		m.mpdServer, err = mpd.Listen(svc, mpd.Config{
+			Address:   mpdCfg.Address,
+			Password:  mpdCfg.Password,
+			Library:   lib,
+			Playlists: m.Playlists,
+			Volumes:   stateMgr,
+		})
+		if err != nil {
+			serveErrs = append(serveErrs, errmsg.Format(errmsg.OpMPDServe, err))
+		}
+	}
+
+	// Serve the HTTP API when enabled, the same way
+	if httpCfg := cfg.GetHTTPConfig(); httpCfg.Enabled {
+		var err error
+		m.httpServer, err = httpapi.Listen(svc, httpapi.Config{
+			Address:   httpCfg.Address,
+			Token:     httpCfg.Token,
+			Library:   lib,
+			Playlists: m.Playlists,
+			Volumes:   stateMgr,
+		})
+		if err != nil {
+			serveErrs = append(serveErrs, errmsg.Format(errmsg.OpHTTPServe, err))
+		}
+	}
 
-	// Load volume from state
-	if volState, err := stateMgr.GetVolume(); err == nil {
-		p.SetVolume(volState.Volume)
-		p.SetMuted(volState.Muted)
+	if len(serveErrs) > 0 {
+		m.Popups.ShowError(strings.Join(serveErrs, "\n"))
 	}
+	return m, nil
+}
+
+// Attach creates an application model attached to a daemon, with deferred
+// initialization like New. The daemon owns the playback and its servers:
+// the model shows and controls it through remote, and quitting detaches.
+// The daemon takes the replay gain, crossfade and equalizer settings of the
+// interface, and manages the downloads.
+func Attach(cfg *config.Config, stateMgr *state.Manager, remote *ctl.Remote) Model {
+	queue := playlist.NewQueue()
+	remote.SyncQueue(queue)
+	daemon.ApplySettings(remote.Player(), cfg, stateMgr)
+	m := newModel(cfg, stateMgr, remote, queue)
+	m.remote = remote
+	m.remoteQueue = queue
+	m.DownloadControl = remote.Downloads()
+	m.viz.enabled = false // The audio plays in the daemon
+	return m
+}
+
+// newModel creates the model of New and Attach, showing a playback service
+// and its queue.
+func newModel(cfg *config.Config, stateMgr *state.Manager, svc playback.Service, queue *playlist.PlayingQueue) Model {
+	lib := library.New(stateMgr.DB())
 
 	// Initialize Last.fm client if configured
 	var lfmClient *lastfm.Client
@@ -173,38 +288,24 @@ func New(cfg *config.Config, stateMgr *state.Manager) (Model, error) {
 	downloadsView := dlview.New()
 	downloadsView.SetConfigured(cfg.HasSlskdConfig())
 
-	// Create playback service wrapping player and queue
-	svc := playback.New(p, queue)
-	sub := svc.Subscribe()
-
-	// Set up gapless playback preload callback
-	p.SetPreloadFunc(func() string {
-		next := svc.QueuePeekNext()
-		if next == nil {
-			return ""
-		}
-		return next.Path
-	})
-
-	// Initialize MPRIS adapter (optional - app works fine without D-Bus)
-	mprisAdapter, _ := mpris.New(svc)
-
 	// Initialize desktop notifier (optional - app works fine without D-Bus)
 	notifier, _ := notify.New()
 	notifConfig := cfg.GetNotificationsConfig()
 
+	dlMgr := downloads.New(stateMgr.DB())
 	return Model{
 		Navigation:          navctl.New(),
 		Library:             lib,
-		Playlists:           pls,
-		Downloads:           dl,
+		Playlists:           playlists.New(stateMgr.DB(), lib),
+		Downloads:           dlMgr,
+		DownloadControl:     daemon.NewDownloads(cfg, dlMgr),
 		DownloadsView:       downloadsView,
+		HistoryView:         histview.New(),
 		Popups:              popupctl.New(),
 		Input:               NewInputManager(),
 		Layout:              NewLayoutManager(queuepanel.New(queue)),
 		PlaybackService:     svc,
-		playbackSub:         sub,
-		mprisAdapter:        mprisAdapter,
+		playbackSub:         svc.Subscribe(),
 		notifier:            notifier,
 		notificationsConfig: notifConfig,
 		Keys:                keymap.NewResolver(keymap.Bindings),
@@ -226,7 +327,12 @@ func New(cfg *config.Config, stateMgr *state.Manager) (Model, error) {
 		LoadingStatus:       "Loading navigators...",
 		initConfig:          &initConfig{cfg: cfg, stateMgr: stateMgr},
 		AlbumArt:            newAlbumArtIfSupported(),
-	}, nil
+		viz:                 newVisualizerState(cfg.GetVisualizerConfig()),
+		waveforms:           newWaveformState(cfg.GetWaveformConfig(), waveform.NewStore(stateMgr.DB())),
+		bookmarks:           newBookmarkState(bookmarks.NewStore(stateMgr.DB())),
+		history:             historyState{store: history.NewStore(stateMgr.DB())},
+		ratings:             newRatingState(cfg.GetRatingsConfig()),
+	}
 }
 
 // newAlbumArtIfSupported creates an album art renderer only if the terminal supports it.
@@ -242,6 +348,7 @@ func newAlbumArtIfSupported() *albumart.Renderer {
 func (m Model) startInitialization() tea.Cmd {
 	cfg := m.initConfig.cfg
 	stateMgr := m.initConfig.stateMgr
+	attached := m.remote != nil
 
 	return func() tea.Msg {
 		result := InitResult{SavedView: navctl.ViewLibrary}
@@ -343,35 +450,12 @@ func (m Model) startInitialization() tea.Cmd {
 		plsNav.SetFocused(true)
 		result.PlsNav = plsNav
 
-		// Restore queue state
-		queue := playlist.NewQueue()
-		if queueState, err := stateMgr.GetQueue(); err == nil && queueState != nil {
-			for _, t := range queueState.Tracks {
-				track := playlist.Track{
-					ID:          t.TrackID,
-					Path:        t.Path,
-					Title:       t.Title,
-					Artist:      t.Artist,
-					Album:       t.Album,
-					TrackNumber: t.TrackNumber,
-				}
-				// Enrich with library metadata (genre, disc number, year)
-				if t.TrackID > 0 {
-					if lt, err := lib.TrackByID(t.TrackID); err == nil {
-						track = playlist.FromLibraryTrack(*lt)
-					}
-				}
-				queue.AddWithoutHistory(track)
-			}
-			if queueState.CurrentIndex >= 0 && queueState.CurrentIndex < queue.Len() {
-				queue.JumpTo(queueState.CurrentIndex)
-			}
-			queue.SetRepeatMode(playlist.RepeatMode(queueState.RepeatMode))
-			queue.SetShuffle(queueState.Shuffle)
-			queue.SaveToHistory() // Save loaded state as initial history entry
+		// Restore queue state, unless the daemon attached to plays it
+		if !attached {
+			queue := daemon.RestoreQueue(stateMgr, lib)
+			result.Queue = queue
+			result.QueuePanel = queuepanel.New(queue)
 		}
-		result.Queue = queue
-		result.QueuePanel = queuepanel.New(queue)
 
 		return result
 	}
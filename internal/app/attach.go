@@ -0,0 +1,40 @@
+package app
+
+// attached reports whether the model is attached to a daemon.
+func (m *Model) attached() bool {
+	return m.remote != nil
+}
+
+// syncRemoteQueue copies the daemon queue to the one the queue panel shows.
+func (m *Model) syncRemoteQueue() {
+	if m.attached() {
+		m.remote.SyncQueue(m.remoteQueue)
+	}
+}
+
+// reorderRemoteQueue makes the daemon queue follow the edits of the queue
+// panel, which moves and removes tracks of its copy.
+func (m *Model) reorderRemoteQueue() {
+	tracks := m.remoteQueue.Tracks()
+	paths := make([]string, len(tracks))
+	for i, t := range tracks {
+		paths[i] = t.Path
+	}
+	m.remote.Reorder(paths)
+}
+
+// unavailableAttached reports whether the model is attached, showing that
+// feature, which needs the audio, isn't available then.
+func (m *Model) unavailableAttached(feature string) bool {
+	if !m.attached() {
+		return false
+	}
+	m.Popups.ShowError(feature + " not available while attached to the daemon")
+	return true
+}
+
+// scrobbles reports whether the model scrobbles: Last.fm is linked and no
+// daemon scrobbles instead.
+func (m *Model) scrobbles() bool {
+	return m.isLastfmLinked() && !m.attached()
+}
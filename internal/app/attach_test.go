@@ -0,0 +1,175 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/app/navctl"
+	"github.com/llehouerou/waves/internal/app/popupctl"
+	"github.com/llehouerou/waves/internal/config"
+	"github.com/llehouerou/waves/internal/ctl"
+	"github.com/llehouerou/waves/internal/downloads"
+	"github.com/llehouerou/waves/internal/keymap"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/playlist"
+	"github.com/llehouerou/waves/internal/radio"
+	"github.com/llehouerou/waves/internal/state"
+	dlview "github.com/llehouerou/waves/internal/ui/downloads"
+	"github.com/llehouerou/waves/internal/ui/queuepanel"
+)
+
+// newAttachedTestModel returns a test model attached to a daemon serving
+// the returned service with the returned server.
+func newAttachedTestModel(t *testing.T) (*Model, playback.Service, *ctl.Server) {
+	t.Helper()
+	daemonSvc := playback.New(player.NewMock(), playlist.NewQueue())
+	server, err := ctl.Listen(filepath.Join(t.TempDir(), "run", "waves.sock"), daemonSvc, nil, nil)
+	if err != nil {
+		t.Fatalf("Listen() error: %v", err)
+	}
+	server.OnShutdown(func() {})
+	t.Cleanup(func() {
+		server.Close()
+		daemonSvc.Close()
+	})
+
+	remote, err := ctl.Attach(server.Path())
+	if err != nil {
+		t.Fatalf("Attach() error: %v", err)
+	}
+	t.Cleanup(func() { remote.Close() })
+	queue := playlist.NewQueue()
+	return &Model{
+		Navigation:      navctl.New(),
+		Layout:          NewLayoutManager(queuepanel.New(queue)),
+		Popups:          popupctl.New(),
+		PlaybackService: remote,
+		playbackSub:     remote.Subscribe(),
+		Keys:            keymap.NewResolver(keymap.Bindings),
+		StateMgr:        state.NewMock(),
+		DownloadControl: remote.Downloads(),
+		remote:          remote,
+		remoteQueue:     queue,
+	}, daemonSvc, server
+}
+
+func TestAttached_QueuePanelEditsDaemonQueue(t *testing.T) {
+	m, daemonSvc, _ := newAttachedTestModel(t)
+	m.PlaybackService.AddTracks(
+		playback.Track{Path: "/a.mp3"},
+		playback.Track{Path: "/b.mp3"},
+		playback.Track{Path: "/c.mp3"},
+	)
+
+	m.syncRemoteQueue()
+	if m.remoteQueue.Len() != 3 {
+		t.Fatalf("queue panel shows %d tracks, want 3", m.remoteQueue.Len())
+	}
+
+	// The panel edits its copy, then reports the change
+	m.remoteQueue.RemoveAt(0)
+	m.remoteQueue.MoveIndices([]int{1}, -1)
+	m.handleQueuePanelAction(queuepanel.QueueChanged{})
+
+	tracks := daemonSvc.QueueTracks()
+	if len(tracks) != 2 || tracks[0].Path != "/c.mp3" || tracks[1].Path != "/b.mp3" {
+		t.Errorf("daemon queue = %v, want /c.mp3 then /b.mp3", tracks)
+	}
+}
+
+func TestAttached_QuitDetaches(t *testing.T) {
+	m, daemonSvc, _ := newAttachedTestModel(t)
+	m.PlaybackService.AddTracks(playback.Track{Path: "/a.mp3"})
+	if err := m.PlaybackService.JumpTo(0); err != nil {
+		t.Fatalf("JumpTo() error: %v", err)
+	}
+	if err := m.PlaybackService.Play(); err != nil {
+		t.Fatalf("Play() error: %v", err)
+	}
+
+	result := m.handleQuitKeys("q")
+	if !result.Handled || result.Cmd == nil {
+		t.Fatal("quit key not handled")
+	}
+	if !daemonSvc.IsPlaying() {
+		t.Error("daemon stopped when the interface quit")
+	}
+
+	// The closed remote makes the model quit, as when the daemon stops
+	_, cmd := m.handlePlaybackMsg(ServiceClosedMsg{})
+	if cmd == nil {
+		t.Fatal("no command after the service closed")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("model doesn't quit when the service closes")
+	}
+}
+
+func TestAttached_DaemonFillsRadio(t *testing.T) {
+	m, _, _ := newAttachedTestModel(t)
+	m.Radio = radio.New(nil, nil, nil, config.RadioConfig{})
+	m.PlaybackService.AddTracks(playback.Track{Path: "/a.mp3", Artist: "Blur"})
+	if err := m.PlaybackService.JumpTo(0); err != nil {
+		t.Fatalf("JumpTo() error: %v", err)
+	}
+	m.PlaybackService.SetRepeatMode(playback.RepeatRadio)
+
+	if cmd := m.triggerRadioFill(); cmd != nil {
+		t.Error("the interface fills the queue of the daemon")
+	}
+}
+
+func TestAttached_NoSpectrum(t *testing.T) {
+	m, _, _ := newAttachedTestModel(t)
+	m.viz = newVisualizerState(config.VisualizerConfig{FPS: defaultVisualizerFPS})
+
+	if cmd := m.ToggleVisualizer(); cmd != nil || m.viz.enabled {
+		t.Error("the spectrum analyzer shows while the audio plays in the daemon")
+	}
+	if !strings.Contains(m.Popups.ErrorMsg(), "attached") {
+		t.Errorf("error = %q, want the spectrum analyzer unavailable while attached", m.Popups.ErrorMsg())
+	}
+}
+
+// daemonDownloads records the downloads deleted by the daemon.
+type daemonDownloads struct {
+	downloads.Control
+	deleted []int64
+}
+
+func (d *daemonDownloads) Delete(id int64) error {
+	d.deleted = append(d.deleted, id)
+	return nil
+}
+
+func TestAttached_DownloadsGoToDaemon(t *testing.T) {
+	m, _, server := newAttachedTestModel(t)
+	dl := &daemonDownloads{}
+	server.SetDownloads(dl)
+
+	m.Popups = popupctl.New()
+	m.HasSlskdConfig = true
+	model, _ := m.handleFSequence("d")
+	next, ok := model.(Model)
+	if !ok {
+		t.Fatal("expected Model")
+	}
+	if !next.Popups.IsVisible(popupctl.Download) {
+		t.Error("the download popup doesn't open while attached")
+	}
+
+	_, cmd := m.handleDownloadsViewAction(dlview.DeleteDownload{ID: 3})
+	if cmd == nil {
+		t.Fatal("deleting a download returned no command")
+	}
+	if msg, ok := cmd().(DownloadDeletedMsg); !ok || msg.Err != nil {
+		t.Errorf("delete result = %+v, want the download deleted", msg)
+	}
+	if len(dl.deleted) != 1 || dl.deleted[0] != 3 {
+		t.Errorf("daemon deleted %v, want download 3", dl.deleted)
+	}
+}
@@ -0,0 +1,168 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/app/popupctl"
+	"github.com/llehouerou/waves/internal/bookmarks"
+	"github.com/llehouerou/waves/internal/errmsg"
+	"github.com/llehouerou/waves/internal/ui/action"
+	bookmarksui "github.com/llehouerou/waves/internal/ui/bookmarks"
+)
+
+// bookmarkState holds the bookmarks of the playing track, drawn in the
+// seek bar.
+type bookmarkState struct {
+	store *bookmarks.Store // nil disables bookmarks
+	path  string           // Track the list belongs to
+	list  []bookmarks.Bookmark
+}
+
+func newBookmarkState(store *bookmarks.Store) bookmarkState {
+	return bookmarkState{store: store}
+}
+
+// currentBookmarks returns the bookmarks of the playing track.
+func (m *Model) currentBookmarks() []bookmarks.Bookmark {
+	track := m.PlaybackService.CurrentTrack()
+	if track == nil || track.Path != m.bookmarks.path {
+		return nil
+	}
+	return m.bookmarks.list
+}
+
+// loadBookmarks loads the bookmarks of the playing track if they aren't
+// loaded yet.
+func (m *Model) loadBookmarks() {
+	track := m.PlaybackService.CurrentTrack()
+	if track == nil || track.Path == m.bookmarks.path {
+		return
+	}
+	if err := m.reloadBookmarks(track.Path); err != nil {
+		m.bookmarks.list = nil
+	}
+}
+
+// reloadBookmarks reads the bookmarks of a track from the store, and
+// updates the bookmarks popup if it is open.
+func (m *Model) reloadBookmarks(path string) error {
+	if m.bookmarks.store == nil {
+		return nil
+	}
+	list, err := m.bookmarks.store.List(path)
+	if err != nil {
+		return err
+	}
+	m.bookmarks.path = path
+	m.bookmarks.list = list
+	if bm := m.Popups.Bookmarks(); bm != nil {
+		bm.SetBookmarks(list)
+	}
+	return nil
+}
+
+// handleAddBookmark asks for the name of a bookmark at the current
+// position, defaulting to the position itself.
+func (m *Model) handleAddBookmark() {
+	if m.bookmarks.store == nil {
+		return
+	}
+	track := m.PlaybackService.CurrentTrack()
+	if track == nil || m.PlaybackService.IsStopped() {
+		m.Popups.ShowError("No track playing")
+		return
+	}
+	if !m.PlaybackService.Seekable() {
+		m.Popups.ShowError("Streams can't be bookmarked")
+		return
+	}
+	pos := m.PlaybackService.Position()
+	m.Popups.ShowTextInput(InputNewBookmark, "New Bookmark", bookmarks.FormatPosition(pos), BookmarkInputContext{
+		Mode:     InputNewBookmark,
+		Path:     track.Path,
+		Position: pos,
+	})
+}
+
+// handleShowBookmarks shows or hides the bookmarks of the playing track.
+func (m *Model) handleShowBookmarks() tea.Cmd {
+	if m.Popups.IsVisible(popupctl.Bookmarks) {
+		m.Popups.Hide(popupctl.Bookmarks)
+		return nil
+	}
+	track := m.PlaybackService.CurrentTrack()
+	if track == nil {
+		m.Popups.ShowError("No track playing")
+		return nil
+	}
+	if err := m.reloadBookmarks(track.Path); err != nil {
+		m.Popups.ShowOpError(errmsg.OpBookmarkLoad, err)
+		return nil
+	}
+	title := track.Title
+	if title == "" {
+		title = filepath.Base(track.Path)
+	}
+	return m.Popups.ShowBookmarks(title, m.bookmarks.list)
+}
+
+// processBookmarkInput adds or renames a bookmark once it is named.
+func (m Model) processBookmarkInput(ctx BookmarkInputContext, text string) (tea.Model, tea.Cmd) {
+	name := strings.TrimSpace(text)
+	switch ctx.Mode { //nolint:exhaustive // only handling bookmark inputs
+	case InputNewBookmark:
+		if name == "" {
+			name = bookmarks.FormatPosition(ctx.Position)
+		}
+		if _, err := m.bookmarks.store.Add(ctx.Path, ctx.Position, name); err != nil {
+			m.Popups.ShowOpError(errmsg.OpBookmarkAdd, err)
+			return m, nil
+		}
+		if err := m.reloadBookmarks(ctx.Path); err != nil {
+			m.Popups.ShowOpError(errmsg.OpBookmarkLoad, err)
+		}
+	case InputRenameBookmark:
+		if name == "" {
+			return m, nil
+		}
+		if err := m.bookmarks.store.Rename(ctx.ID, name); err != nil {
+			m.Popups.ShowOpError(errmsg.OpBookmarkRename, err)
+			return m, nil
+		}
+		if err := m.reloadBookmarks(ctx.Path); err != nil {
+			m.Popups.ShowOpError(errmsg.OpBookmarkLoad, err)
+		}
+	}
+	return m, nil
+}
+
+// handleBookmarksAction handles actions from the bookmarks popup.
+func (m Model) handleBookmarksAction(a action.Action) (tea.Model, tea.Cmd) {
+	switch act := a.(type) {
+	case bookmarksui.Close:
+		m.Popups.Hide(popupctl.Bookmarks)
+	case bookmarksui.Jump:
+		m.Popups.Hide(popupctl.Bookmarks)
+		if err := m.PlaybackService.SeekTo(act.Position); err != nil {
+			m.Popups.ShowOpError(errmsg.OpPlaybackSeek, err)
+		}
+	case bookmarksui.Rename:
+		m.Popups.ShowTextInput(InputRenameBookmark, "Rename Bookmark", act.Bookmark.Name, BookmarkInputContext{
+			Mode: InputRenameBookmark,
+			ID:   act.Bookmark.ID,
+			Path: act.Bookmark.Path,
+		})
+	case bookmarksui.Delete:
+		if err := m.bookmarks.store.Delete(act.ID); err != nil {
+			m.Popups.ShowOpError(errmsg.OpBookmarkDelete, err)
+			return m, nil
+		}
+		if err := m.reloadBookmarks(m.bookmarks.path); err != nil {
+			m.Popups.ShowOpError(errmsg.OpBookmarkLoad, err)
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	_ "modernc.org/sqlite"
+
+	"github.com/llehouerou/waves/internal/app/popupctl"
+	"github.com/llehouerou/waves/internal/bookmarks"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/player"
+	bookmarksui "github.com/llehouerou/waves/internal/ui/bookmarks"
+	"github.com/llehouerou/waves/internal/ui/textinput"
+)
+
+// newBookmarkTestModel returns a test model playing a track, with a
+// bookmark store on an in-memory database.
+func newBookmarkTestModel(t *testing.T) (*Model, *player.Mock) {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec(`
+		CREATE TABLE bookmarks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			path TEXT NOT NULL,
+			position_ms INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			created_at INTEGER NOT NULL
+		)
+	`); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+
+	m := newTestModel()
+	m.bookmarks = newBookmarkState(bookmarks.NewStore(db))
+	m.PlaybackService.AddTracks(playback.Track{Path: "/1.mp3", Title: "Song"})
+	_ = m.PlaybackService.JumpTo(0)
+	_ = m.PlaybackService.Play()
+	mock, ok := m.PlaybackService.Player().(*player.Mock)
+	if !ok {
+		t.Fatal("expected mock player")
+	}
+	mock.SetDuration(3 * time.Minute)
+	return m, mock
+}
+
+func TestBookmarks_AddShowAndJump(t *testing.T) {
+	m, mock := newBookmarkTestModel(t)
+	mock.SetPosition(75 * time.Second)
+
+	m.handlePlaybackKeys("b")
+	if m.Popups.InputMode() != InputNewBookmark {
+		t.Fatalf("InputMode() = %v, want InputNewBookmark", m.Popups.InputMode())
+	}
+	// Playback moves on while the name is typed
+	mock.SetPosition(80 * time.Second)
+	next, _ := m.handleTextInputResultAction(textinput.Result{
+		Text: "Solo",
+		Context: BookmarkInputContext{
+			Mode:     InputNewBookmark,
+			Path:     "/1.mp3",
+			Position: 75 * time.Second,
+		},
+	})
+	m = asModel(t, next)
+
+	got := m.playerBarState().Bookmarks
+	if len(got) != 1 || got[0] != 75*time.Second {
+		t.Fatalf("player bar bookmarks = %v, want [1m15s]", got)
+	}
+
+	m.handlePlaybackKeys("B")
+	if !m.Popups.IsVisible(popupctl.Bookmarks) {
+		t.Fatal("B should open the bookmarks popup")
+	}
+	next, _ = m.handleBookmarksAction(bookmarksui.Jump{Position: 75 * time.Second})
+	m = asModel(t, next)
+	if m.Popups.IsVisible(popupctl.Bookmarks) {
+		t.Error("jumping should close the popup")
+	}
+	if seeks := mock.SeekCalls(); len(seeks) != 1 || seeks[0] != -5*time.Second {
+		t.Errorf("SeekCalls() = %v, want back to the bookmark", seeks)
+	}
+}
+
+func TestBookmarks_EmptyNameUsesPosition(t *testing.T) {
+	m, _ := newBookmarkTestModel(t)
+
+	next, _ := m.processBookmarkInput(BookmarkInputContext{
+		Mode:     InputNewBookmark,
+		Path:     "/1.mp3",
+		Position: 65 * time.Second,
+	}, "  ")
+	m = asModel(t, next)
+
+	list := m.currentBookmarks()
+	if len(list) != 1 || list[0].Name != "1:05" {
+		t.Fatalf("bookmarks = %+v, want one named 1:05", list)
+	}
+
+	next, _ = m.handleBookmarksAction(bookmarksui.Delete{ID: list[0].ID})
+	m = asModel(t, next)
+	if list := m.currentBookmarks(); len(list) != 0 {
+		t.Errorf("bookmarks = %+v after delete, want none", list)
+	}
+}
+
+// asModel returns the model returned by a handler.
+func asModel(t *testing.T, next tea.Model) *Model {
+	t.Helper()
+	m, ok := next.(Model)
+	if !ok {
+		t.Fatalf("expected Model, got %T", next)
+	}
+	return &m
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"errors"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/app/popupctl"
+	"github.com/llehouerou/waves/internal/errmsg"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/tags"
+	"github.com/llehouerou/waves/internal/ui/action"
+	chaptersui "github.com/llehouerou/waves/internal/ui/chapters"
+)
+
+// handleChapterChange moves to the next or previous chapter. Nothing
+// happens past the last chapter.
+func (m *Model) handleChapterChange(move func() error) {
+	if len(m.PlaybackService.Chapters()) == 0 {
+		m.Popups.ShowError("This track has no chapters")
+		return
+	}
+	if err := move(); err != nil && !errors.Is(err, playback.ErrNoChapter) {
+		m.Popups.ShowOpError(errmsg.OpPlaybackSeek, err)
+	}
+}
+
+// handleShowChapters shows or hides the chapters of the playing track.
+func (m *Model) handleShowChapters() tea.Cmd {
+	if m.Popups.IsVisible(popupctl.Chapters) {
+		m.Popups.Hide(popupctl.Chapters)
+		return nil
+	}
+	track := m.PlaybackService.CurrentTrack()
+	if track == nil || m.PlaybackService.IsStopped() {
+		m.Popups.ShowError("No track playing")
+		return nil
+	}
+	title := track.Title
+	if title == "" {
+		title = filepath.Base(track.Path)
+	}
+	chapters := m.PlaybackService.Chapters()
+	current := tags.ChapterAt(chapters, m.PlaybackService.Position())
+	return m.Popups.ShowChapters(title, chapters, current)
+}
+
+// handleChaptersAction handles actions from the chapters popup.
+func (m Model) handleChaptersAction(a action.Action) (tea.Model, tea.Cmd) {
+	switch act := a.(type) {
+	case chaptersui.Close:
+		m.Popups.Hide(popupctl.Chapters)
+	case chaptersui.Jump:
+		m.Popups.Hide(popupctl.Chapters)
+		if err := m.PlaybackService.SeekTo(act.Position); err != nil {
+			m.Popups.ShowOpError(errmsg.OpPlaybackSeek, err)
+		}
+	}
+	return m, nil
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/llehouerou/waves/internal/downloads"
 	importpopup "github.com/llehouerou/waves/internal/importer/popup"
 	"github.com/llehouerou/waves/internal/library"
-	"github.com/llehouerou/waves/internal/slskd"
 	"github.com/llehouerou/waves/internal/stderr"
 )
 
@@ -20,6 +19,14 @@ func TickCmd(gen int) tea.Cmd {
 	})
 }
 
+// VisualizerTickCmd returns a command that sends a VisualizerTickMsg tagged
+// with gen after interval.
+func VisualizerTickCmd(gen int, interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(t time.Time) tea.Msg {
+		return VisualizerTickMsg{Gen: gen, Time: t}
+	})
+}
+
 // KeySequenceTimeoutCmd returns a command that sends KeySequenceTimeoutMsg after 300ms.
 func KeySequenceTimeoutCmd() tea.Cmd {
 	return tea.Tick(300*time.Millisecond, func(_ time.Time) tea.Msg {
@@ -134,32 +141,16 @@ func DownloadsRefreshTickCmd() tea.Cmd {
 	})
 }
 
-// RefreshDownloadsCmd fetches download status from slskd and syncs with local database.
-// It also verifies completed files on disk if completedPath is set.
-func RefreshDownloadsCmd(dlMgr *downloads.Manager, client *slskd.Client, completedPath string) tea.Cmd {
+// RefreshDownloadsCmd syncs the downloads with slskd, verifying completed
+// files on disk.
+func RefreshDownloadsCmd(ctrl downloads.Control) tea.Cmd {
 	return func() tea.Msg {
-		slskdDownloads, err := client.GetDownloads()
-		if err != nil {
-			return DownloadsRefreshResultMsg{Err: err}
-		}
-
-		if err := dlMgr.UpdateFromSlskd(slskdDownloads); err != nil {
-			return DownloadsRefreshResultMsg{Err: err}
-		}
-
-		// Verify completed files on disk
-		if completedPath != "" {
-			if err := dlMgr.VerifyOnDisk(completedPath); err != nil {
-				return DownloadsRefreshResultMsg{Err: err}
-			}
-		}
-
-		return DownloadsRefreshResultMsg{}
+		return DownloadsRefreshResultMsg{Err: ctrl.Sync()}
 	}
 }
 
 // CreateDownloadCmd persists a new download to the database.
-func CreateDownloadCmd(dlMgr *downloads.Manager, msg DownloadCreatedMsg) tea.Cmd {
+func CreateDownloadCmd(ctrl downloads.Control, msg DownloadCreatedMsg) tea.Cmd {
 	return func() tea.Msg {
 		dl := downloads.Download{
 			MBReleaseGroupID: msg.MBReleaseGroupID,
@@ -181,7 +172,7 @@ func CreateDownloadCmd(dlMgr *downloads.Manager, msg DownloadCreatedMsg) tea.Cmd
 			})
 		}
 
-		_, err := dlMgr.Create(dl)
+		_, err := ctrl.Create(dl)
 		if err != nil {
 			return DownloadsRefreshResultMsg{Err: err}
 		}
@@ -191,47 +182,17 @@ func CreateDownloadCmd(dlMgr *downloads.Manager, msg DownloadCreatedMsg) tea.Cmd
 	}
 }
 
-// DeleteDownloadParams contains parameters for deleting a download.
-type DeleteDownloadParams struct {
-	Manager       *downloads.Manager
-	ID            int64
-	SlskdClient   *slskd.Client // May be nil if slskd not configured
-	CompletedPath string
-}
-
 // DeleteDownloadCmd removes a download from slskd, disk, and database.
-func DeleteDownloadCmd(params DeleteDownloadParams) tea.Cmd {
+func DeleteDownloadCmd(ctrl downloads.Control, id int64) tea.Cmd {
 	return func() tea.Msg {
-		// Get download details first (need files and slskd info)
-		download, err := params.Manager.Get(params.ID)
-		if err != nil {
-			return DownloadDeletedMsg{ID: params.ID, Err: err}
-		}
-
-		// Cancel downloads on slskd (if client available)
-		if params.SlskdClient != nil && download.SlskdUsername != "" {
-			// Build list of filenames to cancel
-			for _, f := range download.Files {
-				// Use filename as the ID for slskd cancel
-				_ = params.SlskdClient.CancelDownload(download.SlskdUsername, f.Filename)
-			}
-		}
-
-		// Delete files from disk
-		if params.CompletedPath != "" {
-			_ = downloads.DeleteFilesFromDisk(params.CompletedPath, download)
-		}
-
-		// Delete from database
-		err = params.Manager.Delete(params.ID)
-		return DownloadDeletedMsg{ID: params.ID, Err: err}
+		return DownloadDeletedMsg{ID: id, Err: ctrl.Delete(id)}
 	}
 }
 
 // ClearCompletedDownloadsCmd removes all completed downloads.
-func ClearCompletedDownloadsCmd(dlMgr *downloads.Manager) tea.Cmd {
+func ClearCompletedDownloadsCmd(ctrl downloads.Control) tea.Cmd {
 	return func() tea.Msg {
-		err := dlMgr.DeleteCompleted()
+		err := ctrl.ClearCompleted()
 		return CompletedDownloadsClearedMsg{Err: err}
 	}
 }
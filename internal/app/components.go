@@ -24,6 +24,7 @@ func (m *Model) ResizeComponents() {
 
 	// Resize downloads view
 	m.DownloadsView.SetSize(navWidth, navHeight)
+	m.HistoryView.SetSize(navWidth, navHeight)
 
 	// Queue panel uses QueueHeight (different from navigator in narrow mode)
 	m.Layout.ResizeQueuePanel(m.QueueHeight())
@@ -41,6 +42,7 @@ func (m *Model) SetFocus(target navctl.FocusTarget) {
 	m.Layout.QueuePanel().SetFocused(target == navctl.FocusQueue)
 	// Update downloads view focus when switching views
 	m.DownloadsView.SetFocused(target == navctl.FocusNavigator && m.Navigation.ViewMode() == navctl.ViewDownloads)
+	m.HistoryView.SetFocused(target == navctl.FocusNavigator && m.Navigation.ViewMode() == navctl.ViewHistory)
 }
 
 // HandleLibrarySearchResult navigates to the selected search result.
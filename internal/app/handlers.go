@@ -13,11 +13,32 @@ func (m *Model) handleQuitKeys(key string) handler.Result {
 	if m.Keys.Resolve(key) != keymap.ActionQuit {
 		return handler.NotHandled
 	}
+	if m.attached() {
+		// Detach, leaving the daemon playing
+		_ = m.remote.Close()
+		m.StateMgr.Close()
+		return handler.Handled(tea.Quit)
+	}
 	_ = m.PlaybackService.Stop() //nolint:errcheck // Ignore errors during shutdown; app is exiting
+	_ = m.PlaybackService.Player().Close()
 	if m.mprisAdapter != nil {
 		_ = m.mprisAdapter.Close()
 	}
+	if m.ctlServer != nil {
+		_ = m.ctlServer.Close()
+	}
+	if m.mpdServer != nil {
+		_ = m.mpdServer.Close()
+	}
+	if m.httpServer != nil {
+		_ = m.httpServer.Close()
+	}
 	m.SaveQueueState()
+	// Let the play in progress be recorded before the database closes
+	_ = m.PlaybackService.Close()
+	if m.history.recorder != nil {
+		m.history.recorder.Wait()
+	}
 	m.StateMgr.Close()
 	return handler.Handled(tea.Quit)
 }
@@ -0,0 +1,70 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/app/popupctl"
+	"github.com/llehouerou/waves/internal/errmsg"
+	"github.com/llehouerou/waves/internal/ui/action"
+	equalizerui "github.com/llehouerou/waves/internal/ui/equalizer"
+)
+
+// handleShowEqualizer opens the equalizer editor with the saved settings.
+func (m *Model) handleShowEqualizer() tea.Cmd {
+	state, err := m.StateMgr.GetEqualizer()
+	if err != nil {
+		m.Popups.ShowOpError(errmsg.OpEqualizerLoad, err)
+		return nil
+	}
+	presets, err := m.StateMgr.ListEqualizerPresets()
+	if err != nil {
+		m.Popups.ShowOpError(errmsg.OpEqualizerLoad, err)
+		return nil
+	}
+	return m.Popups.ShowEqualizer(state, presets)
+}
+
+// handleEqualizerAction handles actions from the equalizer popup.
+// Edits are previewed on the player right away and only saved on apply.
+func (m Model) handleEqualizerAction(a action.Action) (tea.Model, tea.Cmd) {
+	switch act := a.(type) {
+	case equalizerui.Preview:
+		m.PlaybackService.Player().SetEqualizer(act.Settings)
+	case equalizerui.Applied:
+		m.Popups.Hide(popupctl.Equalizer)
+		m.PlaybackService.Player().SetEqualizer(act.State.Active())
+		if err := m.StateMgr.SaveEqualizer(act.State); err != nil {
+			m.Popups.ShowOpError(errmsg.OpEqualizerSave, err)
+		}
+	case equalizerui.Canceled:
+		m.Popups.Hide(popupctl.Equalizer)
+		m.PlaybackService.Player().SetEqualizer(act.Original.Active())
+	case equalizerui.PresetSaved:
+		if _, err := m.StateMgr.SaveEqualizerPreset(act.Name, act.Settings); err != nil {
+			m.Popups.ShowOpError(errmsg.OpEqualizerPreset, err)
+			return m, nil
+		}
+		m.refreshEqualizerPresets()
+	case equalizerui.PresetDeleted:
+		if err := m.StateMgr.DeleteEqualizerPreset(act.ID); err != nil {
+			m.Popups.ShowOpError(errmsg.OpEqualizerDelete, err)
+			return m, nil
+		}
+		m.refreshEqualizerPresets()
+	}
+	return m, nil
+}
+
+// refreshEqualizerPresets reloads the preset list of the open popup.
+func (m *Model) refreshEqualizerPresets() {
+	eq := m.Popups.Equalizer()
+	if eq == nil {
+		return
+	}
+	presets, err := m.StateMgr.ListEqualizerPresets()
+	if err != nil {
+		m.Popups.ShowOpError(errmsg.OpEqualizerLoad, err)
+		return
+	}
+	eq.SetPresets(presets)
+}
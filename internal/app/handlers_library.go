@@ -35,6 +35,12 @@ func (m *Model) handleLibraryKeys(key string) handler.Result {
 		return m.handleSimilarArtists()
 	}
 
+	// 0-5, < and > rate the selected track or album (works in all sub-modes)
+	switch action { //nolint:exhaustive // only handling rating actions
+	case keymap.ActionRate, keymap.ActionRatingUp, keymap.ActionRatingDown:
+		return m.handleRateKey(action, key)
+	}
+
 	// Browser mode: handle F and d keys at track level
 	if m.Navigation.IsBrowserViewActive() {
 		browser := m.Navigation.LibraryBrowser()
@@ -0,0 +1,214 @@
+// internal/app/handlers_loudness.go
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/app/navctl"
+	"github.com/llehouerou/waves/internal/errmsg"
+	"github.com/llehouerou/waves/internal/library"
+	"github.com/llehouerou/waves/internal/loudness"
+	"github.com/llehouerou/waves/internal/ui/librarybrowser"
+)
+
+// handleAnalyzeLoudness starts loudness analysis of the library selection
+// (album or artist), or of the whole library. While a job is running it
+// offers to cancel it instead.
+func (m *Model) handleAnalyzeLoudness(wholeLibrary bool) tea.Cmd {
+	if m.LoudnessJob != nil {
+		return m.Popups.ShowConfirm(
+			"Loudness Analysis",
+			"Cancel the running loudness analysis?",
+			LoudnessCancelContext{},
+		)
+	}
+
+	var tracks []library.Track
+	var err error
+	if wholeLibrary {
+		tracks, err = m.allLibraryTracks()
+	} else {
+		tracks, err = m.collectLoudnessTracks()
+	}
+	if err != nil {
+		m.Popups.ShowOpError(errmsg.OpLoudnessAnalyze, err)
+		return nil
+	}
+
+	albums := groupLoudnessAlbums(tracks)
+	if len(albums) == 0 {
+		return nil
+	}
+	return m.startLoudnessJob(albums)
+}
+
+// startLoudnessJob starts analyzing the given albums, or queues them on the
+// running job. Albums requested while a canceled job winds down are kept
+// for the job started when it completes.
+func (m *Model) startLoudnessJob(albums []loudness.Album) tea.Cmd {
+	if m.LoudnessJob != nil && !m.LoudnessJob.IsCanceled() {
+		m.LoudnessJob.Add(albums...)
+		return nil
+	}
+	if m.LoudnessJob != nil {
+		m.LoudnessPending = append(m.LoudnessPending, albums...)
+		return nil
+	}
+
+	m.LoudnessJob = loudness.NewJob(albums)
+	m.ResizeComponents() // Show job bar
+	return loudness.BatchCmd(m.LoudnessJob)
+}
+
+// collectLoudnessTracks returns the tracks of the selected album or artist.
+// At the root of the Miller view the whole library is selected.
+func (m *Model) collectLoudnessTracks() ([]library.Track, error) {
+	switch m.Navigation.LibrarySubMode() {
+	case navctl.LibraryModeAlbum:
+		album := m.Navigation.AlbumView().SelectedAlbum()
+		if album == nil {
+			return nil, nil
+		}
+		return m.Library.Tracks(album.AlbumArtist, album.Album)
+
+	case navctl.LibraryModeBrowser:
+		browser := m.Navigation.LibraryBrowser()
+		artist := browser.SelectedArtist()
+		if artist == "" {
+			return nil, nil
+		}
+		if browser.ActiveColumn() != librarybrowser.ColumnArtists {
+			if album := browser.SelectedAlbum(); album != nil {
+				return m.Library.Tracks(artist, album.Name)
+			}
+		}
+		return m.Library.ArtistTracks(artist)
+
+	case navctl.LibraryModeMiller:
+		selected := m.Navigation.LibraryNav().Selected()
+		if selected == nil {
+			return nil, nil
+		}
+		switch selected.Level() {
+		case library.LevelRoot:
+			return m.allLibraryTracks()
+		case library.LevelArtist:
+			return m.Library.ArtistTracks(selected.Artist())
+		case library.LevelAlbum, library.LevelTrack:
+			// Album gain needs the whole album, even from a track
+			return m.Library.Tracks(selected.Artist(), selected.Album())
+		}
+	}
+	return nil, nil
+}
+
+// allLibraryTracks returns every track in the library, grouped by artist.
+func (m *Model) allLibraryTracks() ([]library.Track, error) {
+	artists, err := m.Library.Artists()
+	if err != nil {
+		return nil, err
+	}
+	var tracks []library.Track
+	for _, artist := range artists {
+		artistTracks, err := m.Library.ArtistTracks(artist)
+		if err != nil {
+			return nil, err
+		}
+		tracks = append(tracks, artistTracks...)
+	}
+	return tracks, nil
+}
+
+// groupLoudnessAlbums groups tracks by album artist and album, keeping the
+// order in which albums first appear.
+func groupLoudnessAlbums(tracks []library.Track) []loudness.Album {
+	index := make(map[string]int)
+	var albums []loudness.Album
+	for i := range tracks {
+		t := &tracks[i]
+		key := t.AlbumArtist + "\x00" + t.Album
+		if t.Album == "" {
+			// Untagged tracks are albums of their own folder
+			key = t.AlbumArtist + "\x00" + filepath.Dir(t.Path)
+		}
+		idx, ok := index[key]
+		if !ok {
+			idx = len(albums)
+			index[key] = idx
+			albums = append(albums, loudness.Album{Name: albumDisplayName(t.AlbumArtist, t.Album)})
+		}
+		albums[idx].Paths = append(albums[idx].Paths, t.Path)
+	}
+	return albums
+}
+
+// albumDisplayName formats "Artist - Album" for job labels.
+func albumDisplayName(artist, album string) string {
+	switch {
+	case artist != "" && album != "":
+		return artist + " - " + album
+	case album != "":
+		return album
+	case artist != "":
+		return artist
+	default:
+		return "Unknown album"
+	}
+}
+
+// handleLoudnessMsg routes loudness job messages.
+func (m Model) handleLoudnessMsg(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case loudness.ProgressMsg:
+		if m.LoudnessJob == nil || m.LoudnessJob.ID() != msg.JobID {
+			return m, nil
+		}
+		return m, loudness.ContinueCmd(m.LoudnessJob)
+
+	case loudness.CompleteMsg:
+		var cmds []tea.Cmd
+		if m.LoudnessJob != nil && m.LoudnessJob.ID() == msg.JobID {
+			m.LoudnessJob = nil
+			if pending := m.LoudnessPending; len(pending) > 0 {
+				m.LoudnessPending = nil
+				cmds = append(cmds, m.startLoudnessJob(pending))
+			}
+		}
+		m.ResizeComponents()
+
+		if len(msg.Written) > 0 {
+			// Tags changed on disk: update the library so the next refresh
+			// doesn't see the files as modified
+			lib, paths := m.Library, msg.Written
+			cmds = append(cmds, func() tea.Msg {
+				_ = lib.AddTracks(paths)
+				return nil
+			})
+		}
+
+		if len(msg.Errors) > 0 {
+			first := msg.Errors[0]
+			m.Popups.ShowError(errmsg.FormatWith(errmsg.OpLoudnessAnalyze, filepath.Base(first.Path), first.Err) +
+				fmt.Sprintf(" (%d files failed)", len(msg.Errors)))
+			return m, tea.Batch(cmds...)
+		}
+
+		notifMsg := fmt.Sprintf("Loudness analyzed: %d files tagged", len(msg.Written))
+		if msg.Canceled {
+			notifMsg = fmt.Sprintf("Loudness analysis canceled: %d files tagged", len(msg.Written))
+		}
+		m.nextNotificationID++
+		notifID := m.nextNotificationID
+		m.Notifications = append(m.Notifications, Notification{
+			ID:      notifID,
+			Message: notifMsg,
+		})
+		m.ResizeComponents()
+		cmds = append(cmds, NotificationClearCmd(notifID))
+		return m, tea.Batch(cmds...)
+	}
+	return m, nil
+}
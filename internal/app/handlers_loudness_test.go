@@ -0,0 +1,39 @@
+// internal/app/handlers_loudness_test.go
+package app
+
+import (
+	"testing"
+
+	"github.com/llehouerou/waves/internal/loudness"
+)
+
+func TestStartLoudnessJob_AfterCanceledJob(t *testing.T) {
+	m := newTestModel()
+	m.Layout.SetSize(120, 40)
+	canceled := loudness.NewJob([]loudness.Album{{Name: "A", Paths: []string{"/a/1.flac"}}})
+	canceled.Cancel()
+	m.LoudnessJob = canceled
+
+	imported := []loudness.Album{{Name: "B", Paths: []string{"/b/1.flac", "/b/2.flac"}}}
+	if cmd := m.startLoudnessJob(imported); cmd != nil {
+		t.Error("no job should start while the canceled one winds down")
+	}
+	if len(m.LoudnessPending) != 1 {
+		t.Fatalf("pending = %v, want the imported album", m.LoudnessPending)
+	}
+
+	model, cmd := m.handleLoudnessMsg(loudness.CompleteMsg{JobID: canceled.ID(), Canceled: true})
+	next, ok := model.(Model)
+	if !ok {
+		t.Fatal("expected Model")
+	}
+	if next.LoudnessJob == nil || next.LoudnessJob == canceled {
+		t.Fatal("a new job should start with the pending albums")
+	}
+	if next.LoudnessJob.IsCanceled() || len(next.LoudnessPending) != 0 {
+		t.Errorf("new job canceled %v, pending %v", next.LoudnessJob.IsCanceled(), next.LoudnessPending)
+	}
+	if cmd == nil {
+		t.Error("expected the command running the new job")
+	}
+}
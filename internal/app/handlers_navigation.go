@@ -9,7 +9,7 @@ import (
 	"github.com/llehouerou/waves/internal/keymap"
 )
 
-// handleViewKeys handles F1, F2, F3, F4 view switching.
+// handleViewKeys handles F1, F2, F3, F4, F5 view switching.
 func (m *Model) handleViewKeys(key string) handler.Result {
 	var newMode navctl.ViewMode
 	switch m.Keys.Resolve(key) { //nolint:exhaustive // only handling view switching actions
@@ -21,6 +21,8 @@ func (m *Model) handleViewKeys(key string) handler.Result {
 		newMode = navctl.ViewPlaylists
 	case keymap.ActionViewDownloads:
 		newMode = navctl.ViewDownloads
+	case keymap.ActionViewHistory:
+		newMode = navctl.ViewHistory
 	default:
 		return handler.NotHandled
 	}
@@ -35,6 +37,9 @@ func (m *Model) handleViewKeys(key string) handler.Result {
 		if newMode == navctl.ViewDownloads && m.HasSlskdConfig {
 			cmd = m.loadAndRefreshDownloads()
 		}
+		if newMode == navctl.ViewHistory {
+			m.reloadHistory()
+		}
 	}
 	return handler.Handled(cmd)
 }
@@ -105,6 +110,8 @@ func (m *Model) applicableContexts() []string {
 			contexts = append(contexts, "filebrowser")
 		case navctl.ViewDownloads:
 			contexts = append(contexts, "downloads")
+		case navctl.ViewHistory:
+			contexts = append(contexts, "history")
 		}
 	case navctl.FocusQueue:
 		contexts = append(contexts, "queue")
@@ -46,8 +46,8 @@ func (m *Model) handleNavigatorActionKeys(key string) handler.Result {
 			}
 		case navctl.ViewPlaylists:
 			m.Input.StartLocalSearch(m.CurrentPlaylistSearchItems())
-		case navctl.ViewDownloads:
-			// No local search for downloads view
+		case navctl.ViewDownloads, navctl.ViewHistory:
+			// No local search for downloads and history views
 		}
 		return handler.HandledNoCmd
 	case keymap.ActionSelect:
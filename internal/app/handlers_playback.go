@@ -3,16 +3,19 @@ package app
 
 import (
 	"math"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/llehouerou/waves/internal/app/handler"
 	"github.com/llehouerou/waves/internal/app/popupctl"
+	"github.com/llehouerou/waves/internal/errmsg"
 	"github.com/llehouerou/waves/internal/keymap"
 	"github.com/llehouerou/waves/internal/playback"
 )
 
-// handlePlaybackKeys handles space, s, pgup/pgdown, seek, R, S, L.
+// handlePlaybackKeys handles space, s, pgup/pgdown, seek, R, S, L, volume, speed, sleep timer,
+// A-B loop, bookmarks and chapters.
 func (m *Model) handlePlaybackKeys(key string) handler.Result {
 	switch m.Keys.Resolve(key) { //nolint:exhaustive // only handling playback actions
 	case keymap.ActionPlayPause:
@@ -37,7 +40,7 @@ func (m *Model) handlePlaybackKeys(key string) handler.Result {
 		return handler.HandledNoCmd
 	case keymap.ActionTogglePlayerDisplay:
 		m.TogglePlayerDisplayMode()
-		return handler.HandledNoCmd
+		return handler.Handled(m.ensureVisualizerTick())
 	case keymap.ActionSeekBack:
 		m.handleSeek(-5)
 		return handler.HandledNoCmd
@@ -64,6 +67,41 @@ func (m *Model) handlePlaybackKeys(key string) handler.Result {
 		return handler.Handled(m.handleVolumeChange(-0.10))
 	case keymap.ActionToggleMute:
 		return handler.Handled(m.handleToggleMute())
+	case keymap.ActionSpeedUp:
+		m.handleSpeedChange(0.1)
+		return handler.HandledNoCmd
+	case keymap.ActionSpeedDown:
+		m.handleSpeedChange(-0.1)
+		return handler.HandledNoCmd
+	case keymap.ActionSpeedReset:
+		m.PlaybackService.SetSpeed(1)
+		return handler.HandledNoCmd
+	case keymap.ActionSleepTimer:
+		m.PlaybackService.SetSleepTimer(nextSleepTimer(m.PlaybackService.SleepTimer()))
+		return handler.HandledNoCmd
+	case keymap.ActionLoopStart:
+		m.handleLoopStart()
+		return handler.HandledNoCmd
+	case keymap.ActionLoopEnd:
+		m.handleLoopEnd()
+		return handler.HandledNoCmd
+	case keymap.ActionLoopClear:
+		m.PlaybackService.ClearLoop()
+		m.loopStart = loopMark{}
+		return handler.HandledNoCmd
+	case keymap.ActionAddBookmark:
+		m.handleAddBookmark()
+		return handler.HandledNoCmd
+	case keymap.ActionShowBookmarks:
+		return handler.Handled(m.handleShowBookmarks())
+	case keymap.ActionNextChapter:
+		m.handleChapterChange(m.PlaybackService.NextChapter)
+		return handler.HandledNoCmd
+	case keymap.ActionPrevChapter:
+		m.handleChapterChange(m.PlaybackService.PreviousChapter)
+		return handler.HandledNoCmd
+	case keymap.ActionShowChapters:
+		return handler.Handled(m.handleShowChapters())
 	}
 	return handler.NotHandled
 }
@@ -163,13 +201,13 @@ func (m *Model) handleVolumeChange(delta float64) tea.Cmd {
 
 	// If muted, unmute first
 	if player.Muted() {
-		player.SetMuted(false)
+		m.PlaybackService.SetMuted(false)
 	}
 
 	// Round to nearest step to avoid floating point precision issues (e.g., 0.99999 instead of 1.0)
 	newLevel := player.Volume() + delta
 	newLevel = math.Round(newLevel*100) / 100
-	player.SetVolume(newLevel)
+	m.PlaybackService.SetVolume(newLevel)
 
 	// Save to state in background
 	return func() tea.Msg {
@@ -178,10 +216,92 @@ func (m *Model) handleVolumeChange(delta float64) tea.Cmd {
 	}
 }
 
+// sleepDurations are the durations the sleep timer key cycles through.
+var sleepDurations = []time.Duration{
+	15 * time.Minute, 30 * time.Minute, 45 * time.Minute, 60 * time.Minute, 90 * time.Minute,
+}
+
+// nextSleepTimer returns the sleep timer following t in the cycle:
+// Off -> 15/30/45/60/90 min -> End of track -> End of album -> Off
+func nextSleepTimer(t playback.SleepTimer) (playback.SleepMode, time.Duration) {
+	switch t.Mode {
+	case playback.SleepOff:
+		return playback.SleepAfter, sleepDurations[0]
+	case playback.SleepAfter:
+		for _, d := range sleepDurations {
+			if d > t.After {
+				return playback.SleepAfter, d
+			}
+		}
+		return playback.SleepEndOfTrack, 0
+	case playback.SleepEndOfTrack:
+		return playback.SleepEndOfAlbum, 0
+	case playback.SleepEndOfAlbum:
+	}
+	return playback.SleepOff, 0
+}
+
+// loopMark is the start of an A-B loop whose end isn't set yet.
+type loopMark struct {
+	path string // Track the mark belongs to, empty when unset
+	pos  time.Duration
+}
+
+// handleLoopStart sets the start of the A-B loop at the current position.
+// The loop starts once its end is set, unless one is already set: it is
+// then moved.
+func (m *Model) handleLoopStart() {
+	track := m.PlaybackService.CurrentTrack()
+	if track == nil || m.PlaybackService.IsStopped() {
+		return
+	}
+	if !m.PlaybackService.Seekable() {
+		m.Popups.ShowOpError(errmsg.OpPlaybackLoop, playback.ErrNotSeekable)
+		return
+	}
+	pos := m.PlaybackService.Position()
+	if l := m.PlaybackService.Loop(); l.Active() && pos < l.B {
+		if err := m.PlaybackService.SetLoop(playback.Loop{A: pos, B: l.B}); err != nil {
+			m.Popups.ShowOpError(errmsg.OpPlaybackLoop, err)
+		}
+		return
+	}
+	m.PlaybackService.ClearLoop()
+	m.loopStart = loopMark{path: track.Path, pos: pos}
+}
+
+// handleLoopEnd sets the end of the A-B loop at the current position and
+// starts looping. Without a start, the loop starts at the beginning of
+// the track.
+func (m *Model) handleLoopEnd() {
+	track := m.PlaybackService.CurrentTrack()
+	if track == nil || m.PlaybackService.IsStopped() {
+		return
+	}
+	var a time.Duration
+	if l := m.PlaybackService.Loop(); l.Active() {
+		a = l.A
+	} else if m.loopStart.path == track.Path {
+		a = m.loopStart.pos
+	}
+	if err := m.PlaybackService.SetLoop(playback.Loop{A: a, B: m.PlaybackService.Position()}); err != nil {
+		m.Popups.ShowOpError(errmsg.OpPlaybackLoop, err)
+		return
+	}
+	m.loopStart = loopMark{}
+}
+
+// handleSpeedChange adjusts the playback speed by delta.
+func (m *Model) handleSpeedChange(delta float64) {
+	// Round to avoid drifting away from round values (e.g., 1.2000000000000002)
+	speed := m.PlaybackService.Speed() + delta
+	m.PlaybackService.SetSpeed(math.Round(speed*100) / 100)
+}
+
 // handleToggleMute toggles the mute state and saves to state.
 func (m *Model) handleToggleMute() tea.Cmd {
 	player := m.PlaybackService.Player()
-	player.SetMuted(!player.Muted())
+	m.PlaybackService.SetMuted(!player.Muted())
 
 	// Save to state in background
 	return func() tea.Msg {
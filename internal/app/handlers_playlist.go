@@ -12,7 +12,8 @@ import (
 	"github.com/llehouerou/waves/internal/playlists"
 )
 
-// handlePlaylistKeys handles playlist-specific keys (n/N/ctrl+r/ctrl+d/d/J/K).
+// handlePlaylistKeys handles playlist-specific keys (n/N/ctrl+r/ctrl+d/d/J/K,
+// and ctrl+n/E/ctrl+f for smart playlists).
 func (m *Model) handlePlaylistKeys(key string) handler.Result {
 	if m.Navigation.ViewMode() != navctl.ViewPlaylists || !m.Navigation.IsNavigatorFocused() {
 		return handler.NotHandled
@@ -27,10 +28,18 @@ func (m *Model) handlePlaylistKeys(key string) handler.Result {
 		if current.Level() == playlists.LevelPlaylist {
 			return handler.NotHandled
 		}
+		if current.Level() == playlists.LevelStations {
+			return m.handleStationCreate(action)
+		}
 		parentFolderID := m.getPlaylistParentFolder(current)
 		return m.handlePlaylistCreate(action, parentFolderID)
 	}
 
+	// Smart playlists (ctrl+n/E/ctrl+f)
+	if action == keymap.ActionNewSmartPlaylist || action == keymap.ActionEditRules || action == keymap.ActionFreezePlaylist {
+		return m.handleSmartPlaylistKeys(action, selected, current)
+	}
+
 	// Rename (ctrl+r)
 	if action == keymap.ActionRename {
 		return m.handlePlaylistRename(selected)
@@ -58,8 +67,8 @@ func (m *Model) getPlaylistParentFolder(current playlists.Node) *int64 {
 		return current.FolderID()
 	case playlists.LevelPlaylist:
 		return current.ParentFolderID()
-	case playlists.LevelTrack:
-		// Should not happen - tracks are not containers
+	case playlists.LevelTrack, playlists.LevelStations, playlists.LevelStation:
+		// Tracks are not containers, and stations are not in folders
 		return nil
 	}
 	return nil
@@ -98,15 +107,36 @@ func (m *Model) handlePlaylistCreate(action keymap.Action, parentFolderID *int64
 	return handler.NotHandled
 }
 
-// handlePlaylistRename handles "ctrl+r" to rename a playlist or folder.
+// handleStationCreate handles "n" inside Stations: it asks for the URL of
+// the new station.
+func (m *Model) handleStationCreate(action keymap.Action) handler.Result {
+	if action != keymap.ActionNewPlaylist {
+		return handler.HandledNoCmd
+	}
+	m.Popups.ShowTextInput(InputNewStation, "New Station (URL)", "", PlaylistInputContext{
+		Mode: InputNewStation,
+	})
+	return handler.HandledNoCmd
+}
+
+// handlePlaylistRename handles "ctrl+r" to rename a playlist, folder or station.
 func (m *Model) handlePlaylistRename(selected *playlists.Node) handler.Result {
 	if selected == nil {
 		return handler.HandledNoCmd
 	}
 
 	level := selected.Level()
-	if level == playlists.LevelRoot || level == playlists.LevelTrack {
-		// Can't rename root or tracks
+	if level == playlists.LevelRoot || level == playlists.LevelTrack || level == playlists.LevelStations {
+		// Can't rename root, tracks or the stations section
+		return handler.HandledNoCmd
+	}
+
+	if station := selected.Station(); station != nil {
+		m.Popups.ShowTextInput(InputRename, "Rename", station.Name, PlaylistInputContext{
+			Mode:      InputRename,
+			ItemID:    station.ID,
+			IsStation: true,
+		})
 		return handler.HandledNoCmd
 	}
 
@@ -141,15 +171,23 @@ func (m *Model) handlePlaylistRename(selected *playlists.Node) handler.Result {
 	return handler.HandledNoCmd
 }
 
-// handlePlaylistDelete handles "ctrl+d" to delete a playlist or folder.
+// handlePlaylistDelete handles "ctrl+d" to delete a playlist, folder or station.
 func (m *Model) handlePlaylistDelete(selected *playlists.Node) handler.Result {
 	if selected == nil {
 		return handler.HandledNoCmd
 	}
 
 	level := selected.Level()
-	if level == playlists.LevelRoot || level == playlists.LevelTrack {
-		// Can't delete root or tracks (use track removal)
+	if level == playlists.LevelRoot || level == playlists.LevelTrack || level == playlists.LevelStations {
+		// Can't delete root, tracks (use track removal) or the stations section
+		return handler.HandledNoCmd
+	}
+
+	if station := selected.Station(); station != nil {
+		m.Popups.ShowConfirm("Delete", "Delete station \""+station.Name+"\"?", DeleteConfirmContext{
+			ItemID:    station.ID,
+			IsStation: true,
+		})
 		return handler.HandledNoCmd
 	}
 
@@ -221,6 +259,11 @@ func (m *Model) getPlaylistDeleteInfo(selected *playlists.Node, level playlists.
 		return false, 0, "", false, errFavoritesProtected
 	}
 
+	// Smart playlists hold no tracks but always ask: their rules would be lost
+	if selected.IsSmart() {
+		return false, *playlistID, selected.DisplayName(), false, nil
+	}
+
 	empty, perr := m.Playlists.IsPlaylistEmpty(*playlistID)
 	if perr != nil {
 		return false, 0, "", false, perr
@@ -238,6 +281,10 @@ func (m *Model) handlePlaylistTrackOps(action keymap.Action, selected *playlists
 	if playlistID == nil {
 		return handler.HandledNoCmd
 	}
+	if selected.IsSmart() {
+		m.Popups.ShowError("Smart playlist tracks follow their rules, press E to edit them")
+		return handler.HandledNoCmd
+	}
 
 	switch action { //nolint:exhaustive // only handling track operations
 	case keymap.ActionDelete:
@@ -101,6 +101,11 @@ func (m *Model) handleRadioFillResult(msg RadioFillResultMsg) {
 // shouldFillRadio checks if radio should fill the queue.
 // Called when a track starts playing to pre-fetch more tracks.
 func (m *Model) shouldFillRadio() bool {
+	// An attached daemon fills its own queue
+	if m.attached() {
+		return false
+	}
+
 	// Only active when in RepeatRadio mode
 	if m.PlaybackService.RepeatMode() != playback.RepeatRadio {
 		return false
@@ -121,6 +126,11 @@ func (m *Model) shouldFillRadio() bool {
 // shouldFillRadioNearEnd checks if radio should fill because track is near end with no next.
 // This handles the case where tracks were deleted/moved and current track became the last.
 func (m *Model) shouldFillRadioNearEnd() bool {
+	// An attached daemon fills its own queue
+	if m.attached() {
+		return false
+	}
+
 	// Only active when in RepeatRadio mode
 	if m.PlaybackService.RepeatMode() != playback.RepeatRadio {
 		return false
@@ -0,0 +1,102 @@
+// internal/app/handlers_smartplaylist.go
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/app/handler"
+	"github.com/llehouerou/waves/internal/app/popupctl"
+	"github.com/llehouerou/waves/internal/errmsg"
+	"github.com/llehouerou/waves/internal/keymap"
+	"github.com/llehouerou/waves/internal/playlists"
+	"github.com/llehouerou/waves/internal/ui/action"
+	"github.com/llehouerou/waves/internal/ui/smartplaylist"
+)
+
+// handleSmartPlaylistKeys handles ctrl+n (new), E (edit rules) and ctrl+f
+// (freeze). E and ctrl+f act on the selected smart playlist, or on the one
+// being browsed.
+func (m *Model) handleSmartPlaylistKeys(action keymap.Action, selected *playlists.Node, current playlists.Node) handler.Result {
+	if action == keymap.ActionNewSmartPlaylist {
+		switch current.Level() { //nolint:exhaustive // only containers hold playlists
+		case playlists.LevelPlaylist:
+			return handler.NotHandled
+		case playlists.LevelStations:
+			return handler.HandledNoCmd
+		}
+		return handler.Handled(m.Popups.ShowNewSmartPlaylist(m.getPlaylistParentFolder(current)))
+	}
+
+	var id *int64
+	switch {
+	case selected != nil && selected.IsSmart():
+		id = selected.PlaylistID()
+	case current.IsSmart():
+		id = current.PlaylistID()
+	}
+	if id == nil {
+		return handler.HandledNoCmd
+	}
+
+	pl, err := m.Playlists.Get(*id)
+	if err != nil {
+		m.Popups.ShowOpError(errmsg.OpPlaylistRules, err)
+		return handler.HandledNoCmd
+	}
+
+	if action == keymap.ActionFreezePlaylist {
+		return handler.Handled(m.Popups.ShowConfirm("Freeze",
+			"Freeze \""+pl.Name+"\" to a static playlist of its current tracks?",
+			FreezeConfirmContext{PlaylistID: pl.ID}))
+	}
+
+	rules, _, err := m.Playlists.SmartRules(pl.ID)
+	if err != nil {
+		m.Popups.ShowOpError(errmsg.OpPlaylistRules, err)
+		return handler.HandledNoCmd
+	}
+	return handler.Handled(m.Popups.ShowSmartPlaylist(pl.ID, pl.Name, rules))
+}
+
+// handleSmartPlaylistAction handles actions from the smart playlist editor.
+func (m Model) handleSmartPlaylistAction(a action.Action) (tea.Model, tea.Cmd) {
+	act, ok := a.(smartplaylist.Saved)
+	m.Popups.Hide(popupctl.SmartPlaylist)
+	if !ok {
+		return m, nil
+	}
+
+	id := act.ID
+	if id == 0 {
+		var err error
+		if id, err = m.Playlists.CreateSmart(act.FolderID, act.Name, act.Rules); err != nil {
+			m.Popups.ShowOpError(errmsg.OpPlaylistCreate, err)
+			return m, nil
+		}
+	} else {
+		if err := m.Playlists.SetRules(id, act.Rules); err != nil {
+			m.Popups.ShowOpError(errmsg.OpPlaylistRules, err)
+			return m, nil
+		}
+		if err := m.Playlists.Rename(id, act.Name); err != nil {
+			m.Popups.ShowOpError(errmsg.OpPlaylistRename, err)
+			return m, nil
+		}
+	}
+
+	m.Navigation.PlaylistNav().Refresh()
+	if act.ID == 0 {
+		m.Navigation.PlaylistNav().NavigateTo("playlists:playlist:" + formatInt64(id))
+	}
+	return m, nil
+}
+
+// handleFreezeConfirm snapshots a smart playlist into a static one.
+func (m Model) handleFreezeConfirm(ctx FreezeConfirmContext) (tea.Model, tea.Cmd) {
+	if err := m.Playlists.Freeze(ctx.PlaylistID); err != nil {
+		m.Popups.ShowOpError(errmsg.OpPlaylistFreeze, err)
+		return m, nil
+	}
+	m.refreshPlaylistNavigator(true)
+	return m, nil
+}
@@ -3,14 +3,19 @@ package app
 
 import (
 	"testing"
+	"time"
 
 	"github.com/llehouerou/waves/internal/app/navctl"
 	"github.com/llehouerou/waves/internal/app/popupctl"
+	"github.com/llehouerou/waves/internal/equalizer"
 	"github.com/llehouerou/waves/internal/keymap"
+	"github.com/llehouerou/waves/internal/library"
 	"github.com/llehouerou/waves/internal/playback"
 	"github.com/llehouerou/waves/internal/player"
 	"github.com/llehouerou/waves/internal/playlist"
 	"github.com/llehouerou/waves/internal/state"
+	"github.com/llehouerou/waves/internal/tags"
+	equalizerui "github.com/llehouerou/waves/internal/ui/equalizer"
 	"github.com/llehouerou/waves/internal/ui/queuepanel"
 )
 
@@ -200,6 +205,165 @@ func TestHandlePlaybackKeys(t *testing.T) {
 			t.Error("expected 'v' to be handled")
 		}
 	})
+
+	t.Run("] and [ change speed, = resets it", func(t *testing.T) {
+		m := newTestModel()
+
+		for range 3 {
+			m.handlePlaybackKeys("]")
+		}
+		if got := m.PlaybackService.Speed(); got != 1.3 {
+			t.Errorf("speed after 3x ']' = %v, want 1.3", got)
+		}
+
+		for range 10 {
+			m.handlePlaybackKeys("[")
+		}
+		if got := m.PlaybackService.Speed(); got != player.MinSpeed {
+			t.Errorf("speed after 10x '[' = %v, want %v", got, player.MinSpeed)
+		}
+
+		result := m.handlePlaybackKeys("=")
+		if !result.Handled {
+			t.Error("expected '=' to be handled")
+		}
+		if got := m.PlaybackService.Speed(); got != 1 {
+			t.Errorf("speed after '=' = %v, want 1", got)
+		}
+	})
+
+	t.Run("z cycles the sleep timer", func(t *testing.T) {
+		m := newTestModel()
+
+		want := []playback.SleepTimer{
+			{Mode: playback.SleepAfter, After: 15 * time.Minute},
+			{Mode: playback.SleepAfter, After: 30 * time.Minute},
+			{Mode: playback.SleepAfter, After: 45 * time.Minute},
+			{Mode: playback.SleepAfter, After: 60 * time.Minute},
+			{Mode: playback.SleepAfter, After: 90 * time.Minute},
+			{Mode: playback.SleepEndOfTrack},
+			{Mode: playback.SleepEndOfAlbum},
+			{Mode: playback.SleepOff},
+		}
+		for _, w := range want {
+			result := m.handlePlaybackKeys("z")
+			if !result.Handled {
+				t.Fatal("expected 'z' to be handled")
+			}
+			got := m.PlaybackService.SleepTimer()
+			if got.Mode != w.Mode || got.After != w.After {
+				t.Errorf("sleep timer = %v %v, want %v %v", got.Mode, got.After, w.Mode, w.After)
+			}
+		}
+	})
+
+	t.Run("( and ) set the A-B loop, | clears it", func(t *testing.T) {
+		m := newTestModel()
+		m.PlaybackService.AddTracks(playback.Track{Path: "/1.mp3"})
+		_ = m.PlaybackService.JumpTo(0)
+		_ = m.PlaybackService.Play()
+		mock, ok := m.PlaybackService.Player().(*player.Mock)
+		if !ok {
+			t.Fatal("expected mock player")
+		}
+		mock.SetDuration(3 * time.Minute)
+
+		mock.SetPosition(10 * time.Second)
+		m.handlePlaybackKeys("(")
+		if m.PlaybackService.Loop().Active() {
+			t.Fatal("the loop should wait for its end")
+		}
+		if got := m.playerBarState().LoopA; got != 10*time.Second {
+			t.Errorf("player bar LoopA = %v, want the pending start", got)
+		}
+
+		mock.SetPosition(40 * time.Second)
+		m.handlePlaybackKeys(")")
+		want := playback.Loop{A: 10 * time.Second, B: 40 * time.Second}
+		if got := m.PlaybackService.Loop(); got != want {
+			t.Errorf("Loop() = %+v, want %+v", got, want)
+		}
+
+		// Moving A keeps B
+		mock.SetPosition(20 * time.Second)
+		m.handlePlaybackKeys("(")
+		if got := m.PlaybackService.Loop(); got.A != 20*time.Second || got.B != want.B {
+			t.Errorf("Loop() = %+v after moving A", got)
+		}
+
+		m.handlePlaybackKeys("|")
+		if m.PlaybackService.Loop().Active() {
+			t.Error("| should clear the loop")
+		}
+	})
+
+	t.Run(") before ( shows an error", func(t *testing.T) {
+		m := newTestModel()
+		m.PlaybackService.AddTracks(playback.Track{Path: "/1.mp3"})
+		_ = m.PlaybackService.JumpTo(0)
+		_ = m.PlaybackService.Play()
+		mock, ok := m.PlaybackService.Player().(*player.Mock)
+		if !ok {
+			t.Fatal("expected mock player")
+		}
+		mock.SetPosition(30 * time.Second)
+		m.handlePlaybackKeys("(")
+		mock.SetPosition(10 * time.Second)
+		m.handlePlaybackKeys(")")
+
+		if m.PlaybackService.Loop().Active() {
+			t.Error("no loop should be set")
+		}
+		if m.Popups.ErrorMsg() == "" {
+			t.Error("expected an error")
+		}
+	})
+
+	t.Run("{ and } move between chapters, C lists them", func(t *testing.T) {
+		m := newTestModel()
+		m.PlaybackService.AddTracks(playback.Track{Path: "/book.m4b"})
+		_ = m.PlaybackService.JumpTo(0)
+		_ = m.PlaybackService.Play()
+		mock, ok := m.PlaybackService.Player().(*player.Mock)
+		if !ok {
+			t.Fatal("expected mock player")
+		}
+		mock.SetTrackInfo(&tags.FileInfo{Chapters: []tags.Chapter{
+			{Title: "One", Start: 0, End: time.Minute},
+			{Title: "Two", Start: time.Minute, End: 2 * time.Minute},
+		}})
+		mock.SetPosition(30 * time.Second)
+
+		m.handlePlaybackKeys("}")
+		if seeks := mock.SeekCalls(); len(seeks) != 1 || seeks[0] != 30*time.Second {
+			t.Errorf("SeekCalls() = %v, want [30s] to chapter two", seeks)
+		}
+		m.handlePlaybackKeys("{")
+		if seeks := mock.SeekCalls(); len(seeks) != 2 || seeks[1] != -30*time.Second {
+			t.Errorf("SeekCalls() = %v, want back to chapter one", seeks)
+		}
+
+		m.handlePlaybackKeys("C")
+		if !m.Popups.IsVisible(popupctl.Chapters) {
+			t.Error("C should show the chapters popup")
+		}
+		m.handlePlaybackKeys("C")
+		if m.Popups.IsVisible(popupctl.Chapters) {
+			t.Error("C should hide the chapters popup")
+		}
+	})
+
+	t.Run("} without chapters shows an error", func(t *testing.T) {
+		m := newTestModel()
+		m.PlaybackService.AddTracks(playback.Track{Path: "/1.mp3"})
+		_ = m.PlaybackService.JumpTo(0)
+		_ = m.PlaybackService.Play()
+
+		m.handlePlaybackKeys("}")
+		if m.Popups.ErrorMsg() == "" {
+			t.Error("expected an error")
+		}
+	})
 }
 
 func TestHandleNavigatorActionKeys(t *testing.T) {
@@ -413,3 +577,73 @@ func TestHandlePlaybackKeys_UnhandledReturnsNotHandled(t *testing.T) {
 		})
 	}
 }
+
+func TestGroupLoudnessAlbums(t *testing.T) {
+	tracks := []library.Track{
+		{Path: "/m/a/1.flac", AlbumArtist: "A", Album: "One"},
+		{Path: "/m/b/1.flac", AlbumArtist: "B", Album: "Two"},
+		{Path: "/m/a/2.flac", AlbumArtist: "A", Album: "One"},
+		{Path: "/m/x/1.mp3", AlbumArtist: "C"},
+		{Path: "/m/y/1.mp3", AlbumArtist: "C"},
+	}
+
+	albums := groupLoudnessAlbums(tracks)
+
+	if len(albums) != 4 {
+		t.Fatalf("got %d albums, want 4: %+v", len(albums), albums)
+	}
+	if albums[0].Name != "A - One" || len(albums[0].Paths) != 2 {
+		t.Errorf("albums[0] = %+v, want A - One with 2 tracks", albums[0])
+	}
+	if albums[1].Name != "B - Two" {
+		t.Errorf("albums[1].Name = %q, want B - Two", albums[1].Name)
+	}
+	// Untagged albums are split by folder
+	if albums[2].Paths[0] != "/m/x/1.mp3" || albums[3].Paths[0] != "/m/y/1.mp3" {
+		t.Errorf("untagged albums = %+v, %+v", albums[2], albums[3])
+	}
+}
+
+func TestHandleEqualizerAction(t *testing.T) {
+	m := newTestModel()
+	p, ok := m.PlaybackService.Player().(*player.Mock)
+	if !ok {
+		t.Fatal("expected player mock")
+	}
+
+	if cmd := m.handleShowEqualizer(); m.Popups.Equalizer() == nil {
+		t.Fatalf("equalizer popup not shown (cmd %v)", cmd)
+	}
+
+	edited := equalizer.DefaultState()
+	edited.Speakers.Enabled = true
+	edited.Speakers.Bands[0].GainDB = 4
+
+	// Preview changes the player without saving
+	model, _ := m.handleEqualizerAction(equalizerui.Preview{Settings: edited.Speakers})
+	m2, _ := model.(Model)
+	if !p.Equalizer().Enabled {
+		t.Error("preview should apply the settings to the player")
+	}
+	if saved, _ := m2.StateMgr.GetEqualizer(); saved.Speakers.Enabled {
+		t.Error("preview should not save")
+	}
+
+	// Cancel restores the original settings
+	model, _ = m2.handleEqualizerAction(equalizerui.Canceled{Original: equalizer.DefaultState()})
+	m2, _ = model.(Model)
+	if p.Equalizer().Enabled {
+		t.Error("cancel should restore the original settings")
+	}
+	if m2.Popups.Equalizer() != nil {
+		t.Error("cancel should close the popup")
+	}
+
+	// Apply saves
+	model, _ = m2.handleEqualizerAction(equalizerui.Applied{State: edited})
+	m2, _ = model.(Model)
+	saved, _ := m2.StateMgr.GetEqualizer()
+	if saved.Speakers.Bands[0].GainDB != 4 || !p.Equalizer().Enabled {
+		t.Errorf("apply should save and keep the settings, saved %+v", saved.Speakers)
+	}
+}
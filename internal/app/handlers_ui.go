@@ -1,9 +1,11 @@
 package app
 
 import (
+	"net/url"
 	"os"
 	"slices"
 	"strconv"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 
@@ -12,8 +14,10 @@ import (
 	"github.com/llehouerou/waves/internal/download"
 	"github.com/llehouerou/waves/internal/errmsg"
 	"github.com/llehouerou/waves/internal/export"
+	"github.com/llehouerou/waves/internal/icy"
 	importpopup "github.com/llehouerou/waves/internal/importer/popup"
 	"github.com/llehouerou/waves/internal/library"
+	"github.com/llehouerou/waves/internal/loudness"
 	"github.com/llehouerou/waves/internal/musicbrainz"
 	"github.com/llehouerou/waves/internal/navigator"
 	"github.com/llehouerou/waves/internal/navigator/sourceutil"
@@ -22,11 +26,13 @@ import (
 	"github.com/llehouerou/waves/internal/playlists"
 	"github.com/llehouerou/waves/internal/retag"
 	"github.com/llehouerou/waves/internal/search"
-	"github.com/llehouerou/waves/internal/slskd"
 	"github.com/llehouerou/waves/internal/ui/action"
 	"github.com/llehouerou/waves/internal/ui/albumview"
+	bookmarksui "github.com/llehouerou/waves/internal/ui/bookmarks"
+	chaptersui "github.com/llehouerou/waves/internal/ui/chapters"
 	"github.com/llehouerou/waves/internal/ui/confirm"
 	dlview "github.com/llehouerou/waves/internal/ui/downloads"
+	equalizerui "github.com/llehouerou/waves/internal/ui/equalizer"
 	exportui "github.com/llehouerou/waves/internal/ui/export"
 	"github.com/llehouerou/waves/internal/ui/helpbindings"
 	"github.com/llehouerou/waves/internal/ui/librarybrowser"
@@ -34,6 +40,7 @@ import (
 	lyricsui "github.com/llehouerou/waves/internal/ui/lyrics"
 	"github.com/llehouerou/waves/internal/ui/queuepanel"
 	"github.com/llehouerou/waves/internal/ui/similarartists"
+	"github.com/llehouerou/waves/internal/ui/smartplaylist"
 	"github.com/llehouerou/waves/internal/ui/textinput"
 )
 
@@ -64,6 +71,8 @@ func (m Model) handleUIAction(msg action.Msg) (tea.Model, tea.Cmd) {
 		return m.handleHelpBindingsAction(msg.Action)
 	case "downloads":
 		return m.handleDownloadsViewAction(msg.Action)
+	case "history":
+		return m.handleHistoryAction(msg.Action)
 	case "download":
 		return m.handleDownloadPopupAction(msg.Action)
 	case "import":
@@ -76,6 +85,14 @@ func (m Model) handleUIAction(msg action.Msg) (tea.Model, tea.Cmd) {
 		return m.handleLyricsAction(msg.Action)
 	case "similarartists":
 		return m.handleSimilarArtistsAction(msg.Action)
+	case equalizerui.Source:
+		return m.handleEqualizerAction(msg.Action)
+	case bookmarksui.Source:
+		return m.handleBookmarksAction(msg.Action)
+	case chaptersui.Source:
+		return m.handleChaptersAction(msg.Action)
+	case smartplaylist.Source:
+		return m.handleSmartPlaylistAction(msg.Action)
 	case "librarybrowser":
 		return m.handleLibraryBrowserAction(msg.Action)
 	}
@@ -89,6 +106,10 @@ func (m Model) handleQueuePanelAction(a action.Action) (tea.Model, tea.Cmd) {
 		cmd := m.PlayTrackAtIndex(act.Index)
 		return m, cmd
 	case queuepanel.QueueChanged:
+		if m.attached() {
+			m.reorderRemoteQueue()
+			return m, nil
+		}
 		m.SaveQueueState()
 		// Clear preloaded track since queue order may have changed
 		m.PlaybackService.Player().ClearPreload()
@@ -96,6 +117,9 @@ func (m Model) handleQueuePanelAction(a action.Action) (tea.Model, tea.Cmd) {
 	case queuepanel.ToggleFavorite:
 		m.handleToggleFavorite(act.TrackIDs)
 		return m, nil
+	case queuepanel.Rate:
+		result := m.rateTracks(act.TrackIDs, m.Keys.Resolve(act.Key), act.Key)
+		return m, result.Cmd
 	case queuepanel.AddToPlaylist:
 		m.handleQueueAddToPlaylist(act.TrackIDs)
 		return m, nil
@@ -140,8 +164,8 @@ func (m *Model) handleGoToSource(act queuepanel.GoToSource) {
 			trackNodeID := sourceutil.FormatID("playlists", "track", sourceutil.FormatInt64(act.TrackID))
 			navigated = m.Navigation.PlaylistNav().FocusByID(trackNodeID)
 		}
-	case navctl.ViewDownloads:
-		// Downloads view doesn't have a source location to navigate to
+	case navctl.ViewDownloads, navctl.ViewHistory:
+		// Downloads and history views don't have a source location to navigate to
 	}
 	if navigated {
 		m.SetFocus(navctl.FocusNavigator)
@@ -421,12 +445,15 @@ func (m Model) handleTextInputResultAction(act textinput.Result) (tea.Model, tea
 		return m, nil
 	}
 
-	ctx, ok := act.Context.(PlaylistInputContext)
-	if !ok {
-		return m, nil
+	switch ctx := act.Context.(type) {
+	case PlaylistInputContext:
+		return m.processPlaylistInput(ctx, act.Text)
+	case BookmarkInputContext:
+		return m.processBookmarkInput(ctx, act.Text)
+	case PlaylistFileContext:
+		return m.processPlaylistFileInput(ctx, act.Text)
 	}
-
-	return m.processPlaylistInput(ctx, act.Text)
+	return m, nil
 }
 
 // handleConfirmAction handles actions from the confirmation popup.
@@ -546,24 +573,14 @@ func (m Model) handleLyricsAction(a action.Action) (tea.Model, tea.Cmd) {
 func (m Model) handleDownloadsViewAction(a action.Action) (tea.Model, tea.Cmd) {
 	switch act := a.(type) {
 	case dlview.DeleteDownload:
-		var client *slskd.Client
-		if m.HasSlskdConfig {
-			client = slskd.NewClient(m.Slskd.URL, m.Slskd.APIKey)
-		}
-		return m, DeleteDownloadCmd(DeleteDownloadParams{
-			Manager:       m.Downloads,
-			ID:            act.ID,
-			SlskdClient:   client,
-			CompletedPath: m.Slskd.CompletedPath,
-		})
+		return m, DeleteDownloadCmd(m.DownloadControl, act.ID)
 
 	case dlview.ClearCompleted:
-		return m, ClearCompletedDownloadsCmd(m.Downloads)
+		return m, ClearCompletedDownloadsCmd(m.DownloadControl)
 
 	case dlview.RefreshRequest:
 		if m.HasSlskdConfig {
-			client := slskd.NewClient(m.Slskd.URL, m.Slskd.APIKey)
-			return m, RefreshDownloadsCmd(m.Downloads, client, m.Slskd.CompletedPath)
+			return m, RefreshDownloadsCmd(m.DownloadControl)
 		}
 		return m, nil
 
@@ -604,7 +621,7 @@ func (m Model) handleDownloadPopupAction(a action.Action) (tea.Model, tea.Cmd) {
 		m.SaveNavigationState()
 
 		// Persist the download to database and refresh downloads view
-		createCmd := CreateDownloadCmd(m.Downloads, DownloadCreatedMsg{
+		createCmd := CreateDownloadCmd(m.DownloadControl, DownloadCreatedMsg{
 			MBReleaseGroupID: act.MBReleaseGroupID,
 			MBReleaseID:      act.MBReleaseID,
 			MBArtistName:     act.MBArtistName,
@@ -643,6 +660,12 @@ func (m Model) handleImportPopupAction(a action.Action) (tea.Model, tea.Cmd) {
 				AlbumName:    act.AlbumName,
 				AllSucceeded: act.AllSucceeded,
 			}))
+
+			// Analyze loudness of the imported album in the background
+			cmds = append(cmds, m.startLoudnessJob([]loudness.Album{{
+				Name:  albumDisplayName(act.ArtistName, act.AlbumName),
+				Paths: act.ImportedPaths,
+			}}))
 		} else if act.AllSucceeded {
 			// No tracks to add but import succeeded - send completion directly
 			cmds = append(cmds, func() tea.Msg {
@@ -681,8 +704,8 @@ func (m Model) processPlaylistInput(ctx PlaylistInputContext, text string) (tea.
 	var navigateToID string
 
 	switch ctx.Mode {
-	case InputNone:
-		// No action
+	case InputNone, InputNewBookmark, InputRenameBookmark, InputImportPlaylist, InputExportPlaylist:
+		// No action, bookmarks and playlist files have their own contexts
 	case InputNewPlaylist:
 		id, err := m.Playlists.Create(ctx.FolderID, text)
 		if err != nil {
@@ -697,7 +720,26 @@ func (m Model) processPlaylistInput(ctx PlaylistInputContext, text string) (tea.
 			return m, nil
 		}
 		navigateToID = "playlists:folder:" + strconv.FormatInt(id, 10)
+	case InputNewStation:
+		streamURL := strings.TrimSpace(text)
+		if !icy.IsURL(streamURL) {
+			m.Popups.ShowError("Station URL must start with http:// or https://")
+			return m, nil
+		}
+		id, err := m.Playlists.AddStation(stationName(streamURL), streamURL)
+		if err != nil {
+			m.Popups.ShowOpError(errmsg.OpStationAdd, err)
+			return m, nil
+		}
+		navigateToID = "playlists:station:" + strconv.FormatInt(id, 10)
 	case InputRename:
+		if ctx.IsStation {
+			if err := m.Playlists.RenameStation(ctx.ItemID, text); err != nil {
+				m.Popups.ShowOpError(errmsg.OpStationRename, err)
+				return m, nil
+			}
+			break
+		}
 		var err error
 		if ctx.IsFolder {
 			err = m.Playlists.RenameFolder(ctx.ItemID, text)
@@ -722,6 +764,15 @@ func (m Model) processPlaylistInput(ctx PlaylistInputContext, text string) (tea.
 	return m, nil
 }
 
+// stationName names a new station after the host of its URL. It can be
+// renamed afterwards.
+func stationName(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return rawURL
+}
+
 // processConfirmResult processes the confirmation dialog result.
 func (m Model) processConfirmResult(context any, selectedOption int) (tea.Model, tea.Cmd) {
 	// Handle library delete context
@@ -734,12 +785,39 @@ func (m Model) processConfirmResult(context any, selectedOption int) (tea.Model,
 		return m.handleFileDeleteConfirm(ctx)
 	}
 
+	// Handle loudness analysis cancel context
+	if _, ok := context.(LoudnessCancelContext); ok {
+		if m.LoudnessJob != nil {
+			m.LoudnessJob.Cancel()
+		}
+		return m, nil
+	}
+
+	// Handle playlist export path style choice
+	if ctx, ok := context.(PlaylistFileContext); ok {
+		return m.handlePlaylistExportConfirm(ctx, selectedOption)
+	}
+
+	// Handle smart playlist freeze context
+	if ctx, ok := context.(FreezeConfirmContext); ok {
+		return m.handleFreezeConfirm(ctx)
+	}
+
 	// Handle playlist delete context
 	ctx, ok := context.(DeleteConfirmContext)
 	if !ok {
 		return m, nil
 	}
 
+	if ctx.IsStation {
+		if err := m.Playlists.DeleteStation(ctx.ItemID); err != nil {
+			m.Popups.ShowOpError(errmsg.OpStationDelete, err)
+			return m, nil
+		}
+		m.refreshPlaylistNavigator(true)
+		return m, nil
+	}
+
 	var err error
 	if ctx.IsFolder {
 		err = m.Playlists.DeleteFolder(ctx.ItemID)
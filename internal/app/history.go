@@ -0,0 +1,133 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/app/navctl"
+	"github.com/llehouerou/waves/internal/errmsg"
+	"github.com/llehouerou/waves/internal/history"
+	"github.com/llehouerou/waves/internal/icy"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/playlist"
+	"github.com/llehouerou/waves/internal/ui/action"
+	histview "github.com/llehouerou/waves/internal/ui/history"
+	"github.com/llehouerou/waves/internal/ui/queuepanel"
+)
+
+// historyLimit is how many plays the history view lists.
+const historyLimit = 1000
+
+// historyState records the plays of the playback service, listed in the
+// history view.
+type historyState struct {
+	store    *history.Store // nil disables the history
+	recorder *history.Recorder
+}
+
+func newHistoryState(store *history.Store) historyState {
+	return historyState{store: store, recorder: history.NewRecorder(store)}
+}
+
+// watchHistory returns a command that waits for a play to be recorded.
+func (m Model) watchHistory() tea.Cmd {
+	if m.history.recorder == nil {
+		return nil
+	}
+	return waitForChannel(m.history.recorder.Recorded(), func(_ history.Play, ok bool) tea.Msg {
+		if !ok {
+			return nil
+		}
+		return HistoryRecordedMsg{}
+	})
+}
+
+// reloadHistory reads the recent plays into the history view.
+func (m *Model) reloadHistory() {
+	if m.history.store == nil {
+		return
+	}
+	plays, err := m.history.store.Recent(historyLimit)
+	if err != nil {
+		m.Popups.ShowOpError(errmsg.OpHistoryLoad, err)
+		return
+	}
+	m.HistoryView.SetPlays(plays)
+}
+
+// handleHistoryRecorded refreshes the history view with the new play.
+func (m Model) handleHistoryRecorded() (tea.Model, tea.Cmd) {
+	if m.Navigation.ViewMode() == navctl.ViewHistory {
+		m.reloadHistory()
+	}
+	return m, m.watchHistory()
+}
+
+// handleHistoryAction handles actions from the history view.
+func (m Model) handleHistoryAction(a action.Action) (tea.Model, tea.Cmd) {
+	switch act := a.(type) {
+	case histview.PlayNow:
+		m.requeuePlays(act.Plays, true)
+	case histview.AddToQueue:
+		m.requeuePlays(act.Plays, false)
+	case histview.Locate:
+		m.locatePlay(act.Play)
+	}
+	return m, nil
+}
+
+// requeuePlays adds played tracks back to the queue, playing the first one
+// if play is true.
+func (m *Model) requeuePlays(plays []history.Play, play bool) {
+	tracks := m.historyTracks(plays)
+	if len(tracks) == 0 {
+		return
+	}
+	first := m.PlaybackService.QueueLen()
+	m.PlaybackService.AddTracks(playback.TracksFromPlaylist(tracks)...)
+
+	m.SaveQueueState()
+	m.Layout.QueuePanel().SyncCursor()
+	// Clear preloaded track since queue contents changed
+	m.PlaybackService.Player().ClearPreload()
+
+	if play && m.PlaybackService.QueueMoveTo(first) != nil {
+		if err := m.PlaybackService.Play(); err != nil {
+			m.Popups.ShowOpError(errmsg.OpPlaybackStart, err)
+		}
+	}
+}
+
+// historyTracks returns the queue tracks of plays. Library tracks are read
+// from the library, other files from their tags, and streams are queued
+// with the title they were played with.
+func (m *Model) historyTracks(plays []history.Play) []playlist.Track {
+	tracks := make([]playlist.Track, 0, len(plays))
+	for _, p := range plays {
+		if p.TrackID != 0 && m.Library != nil {
+			if t, err := m.Library.TrackByID(p.TrackID); err == nil && t != nil {
+				tracks = append(tracks, playlist.FromLibraryTrack(*t))
+				continue
+			}
+		}
+		if icy.IsURL(p.Path) {
+			tracks = append(tracks, playlist.Track{Path: p.Path, Title: p.Title, Artist: p.Artist, Album: p.Album})
+			continue
+		}
+		tracks = append(tracks, playlist.WithDuration(playlist.FromPath(p.Path)))
+	}
+	return tracks
+}
+
+// locatePlay switches to the library view and selects a played track.
+func (m *Model) locatePlay(p history.Play) {
+	if p.TrackID == 0 {
+		m.Popups.ShowError("This track isn't in the library")
+		return
+	}
+	m.Navigation.SetViewMode(navctl.ViewLibrary)
+	m.SetFocus(navctl.FocusNavigator)
+	if !m.goToSourceLibrary(queuepanel.GoToSource{TrackID: p.TrackID, Path: p.Path}) {
+		m.Popups.ShowError("This track is no longer in the library")
+	}
+	m.SaveNavigationState()
+}
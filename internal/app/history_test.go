@@ -0,0 +1,131 @@
+package app
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/llehouerou/waves/internal/app/navctl"
+	"github.com/llehouerou/waves/internal/app/popupctl"
+	"github.com/llehouerou/waves/internal/history"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/player"
+	histview "github.com/llehouerou/waves/internal/ui/history"
+)
+
+// newHistoryTestModel returns a test model with a history store on an
+// in-memory database.
+func newHistoryTestModel(t *testing.T) (*Model, *history.Store) {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec(`
+		CREATE TABLE play_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			track_id INTEGER,
+			path TEXT NOT NULL,
+			title TEXT NOT NULL,
+			artist TEXT NOT NULL,
+			album TEXT NOT NULL,
+			started_at INTEGER NOT NULL,
+			played_ms INTEGER NOT NULL,
+			completed INTEGER NOT NULL
+		)
+	`); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+
+	store := history.NewStore(db)
+	m := newTestModel()
+	m.history = newHistoryState(store)
+	m.HistoryView = histview.New()
+	return m, store
+}
+
+func TestHistory_RecordedPlayRefreshesActiveView(t *testing.T) {
+	m, store := newHistoryTestModel(t)
+	if _, err := store.Add(history.Play{Path: "/a.mp3", Title: "A", StartedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Not shown while another view is active
+	result, _ := m.handleHistoryRecorded()
+	model := result.(Model)
+	if !model.HistoryView.IsEmpty() {
+		t.Error("history view should only reload while active")
+	}
+
+	model.Navigation.SetViewMode(navctl.ViewHistory)
+	result, cmd := model.handleHistoryRecorded()
+	model = result.(Model)
+	if model.HistoryView.IsEmpty() {
+		t.Error("active history view should reload on a new play")
+	}
+	if cmd == nil {
+		t.Error("should keep watching for recorded plays")
+	}
+}
+
+func TestHistory_PlayNowAddsToQueueAndPlays(t *testing.T) {
+	m, _ := newHistoryTestModel(t)
+	m.PlaybackService.AddTracks(playback.Track{Path: "/queued.mp3"})
+
+	result, _ := m.handleHistoryAction(histview.PlayNow{Plays: []history.Play{
+		{Path: "/a.mp3", Title: "A"},
+		{Path: "http://radio.example/stream", Title: "Radio"},
+	}})
+	model := result.(Model)
+
+	tracks := model.PlaybackService.QueueTracks()
+	if len(tracks) != 3 {
+		t.Fatalf("queue length = %d, want 3 (queued track kept)", len(tracks))
+	}
+	if tracks[2].Title != "Radio" {
+		t.Errorf("stream title = %q, want the title it was played with", tracks[2].Title)
+	}
+	if model.PlaybackService.QueueCurrentIndex() != 1 {
+		t.Errorf("QueueCurrentIndex() = %d, want 1", model.PlaybackService.QueueCurrentIndex())
+	}
+	mock, ok := model.PlaybackService.Player().(*player.Mock)
+	if !ok {
+		t.Fatal("expected mock player")
+	}
+	if calls := mock.PlayCalls(); len(calls) != 1 || calls[0] != "/a.mp3" {
+		t.Errorf("PlayCalls() = %v, want [/a.mp3]", calls)
+	}
+}
+
+func TestHistory_AddToQueueDoesNotPlay(t *testing.T) {
+	m, _ := newHistoryTestModel(t)
+
+	result, _ := m.handleHistoryAction(histview.AddToQueue{Plays: []history.Play{{Path: "/a.mp3"}}})
+	model := result.(Model)
+
+	if model.PlaybackService.QueueLen() != 1 {
+		t.Errorf("queue length = %d, want 1", model.PlaybackService.QueueLen())
+	}
+	if !model.PlaybackService.IsStopped() {
+		t.Error("adding to the queue should not start playback")
+	}
+}
+
+func TestHistory_LocateOutsideLibrary(t *testing.T) {
+	m, _ := newHistoryTestModel(t)
+	m.Navigation.SetViewMode(navctl.ViewHistory)
+
+	result, _ := m.handleHistoryAction(histview.Locate{Play: history.Play{Path: "/a.mp3"}})
+	model := result.(Model)
+
+	if !model.Popups.IsVisible(popupctl.Error) {
+		t.Error("locating a file outside the library should show an error")
+	}
+	if model.Navigation.ViewMode() != navctl.ViewHistory {
+		t.Error("should stay in the history view")
+	}
+}
@@ -77,8 +77,8 @@ func (m Model) handleFSequence(key string) (tea.Model, tea.Cmd) {
 		case navctl.ViewPlaylists:
 			m.Input.StartDeepSearchWithItems(m.AllPlaylistSearchItems())
 			return m, nil
-		case navctl.ViewDownloads:
-			// No deep search for downloads view
+		case navctl.ViewDownloads, navctl.ViewHistory:
+			// No deep search for downloads and history views
 		}
 	case keymap.ActionLibrarySources:
 		// Open library sources popup
@@ -128,6 +128,22 @@ func (m Model) handleFSequence(key string) (tea.Model, tea.Cmd) {
 	case keymap.ActionShowLyrics:
 		cmd := m.handleShowLyrics()
 		return m, cmd
+	case keymap.ActionAnalyzeLoudness:
+		if m.Navigation.ViewMode() == navctl.ViewLibrary {
+			cmd := m.handleAnalyzeLoudness(false)
+			return m, cmd
+		}
+	case keymap.ActionEqualizer:
+		cmd := m.handleShowEqualizer()
+		return m, cmd
+	case keymap.ActionToggleVisualizer:
+		cmd := m.ToggleVisualizer()
+		return m, cmd
+	case keymap.ActionAnalyzeLibraryLoudness:
+		if m.Navigation.ViewMode() == navctl.ViewLibrary {
+			cmd := m.handleAnalyzeLoudness(true)
+			return m, cmd
+		}
 	}
 
 	return m, nil
@@ -62,6 +62,12 @@ func (m *Model) ActiveJobCount() int {
 			count++
 		}
 	}
+	if m.LoudnessJob != nil && !m.LoudnessJob.JobBar().Done {
+		count++
+	}
+	if m.WaveformJob != nil && !m.WaveformJob.JobBar().Done {
+		count++
+	}
 	return count
 }
 
@@ -44,12 +44,14 @@ func (m Model) handleLibraryScanProgress(msg LibraryScanProgressMsg) (tea.Model,
 		_ = m.Navigation.AlbumView().Refresh()
 
 		// Show scan report popup with stats
+		var cmd tea.Cmd
 		if msg.Stats != nil {
 			m.Popups.ShowScanReport(msg.Stats)
+			cmd = m.startWaveformScan(msg.Stats.ChangedPaths())
 		}
 
 		m.ResizeComponents()
-		return m, nil
+		return m, cmd
 	}
 	return m, m.waitForLibraryScan()
 }
@@ -57,6 +57,15 @@ type TickMsg struct {
 
 func (TickMsg) playbackMessage() {}
 
+// VisualizerTickMsg is sent for each frame of the visualizer. Gen
+// identifies the frame chain that produced it, like TickMsg.
+type VisualizerTickMsg struct {
+	Gen  int
+	Time time.Time
+}
+
+func (VisualizerTickMsg) playbackMessage() {}
+
 // ScanResultMsg wraps navigator scan results for directory searching.
 type ScanResultMsg navigator.ScanResult
 
@@ -103,6 +112,15 @@ type ServiceTrackChangedMsg struct {
 
 func (ServiceTrackChangedMsg) playbackMessage() {}
 
+// HistoryRecordedMsg is sent when a play was added to the history.
+type HistoryRecordedMsg struct{}
+
+// RatingTagsWrittenMsg is sent when changed ratings were written to the
+// tags of their files.
+type RatingTagsWrittenMsg struct {
+	Err error
+}
+
 // ServiceClosedMsg is sent when the playback service is closed.
 type ServiceClosedMsg struct{}
 
@@ -136,7 +154,7 @@ type LyricsUpdateMsg struct{}
 
 func (LyricsUpdateMsg) playbackMessage() {}
 
-// ServiceModeChangedMsg is sent when repeat/shuffle mode changes.
+// ServiceModeChangedMsg is sent when repeat/shuffle mode or playback speed changes.
 // Currently used to drain the subscription channel; may be used for future features.
 type ServiceModeChangedMsg struct{}
 
@@ -168,18 +186,43 @@ type InputMode = popupctl.InputMode
 
 // InputMode constants for backward compatibility.
 const (
-	InputNone        = popupctl.InputNone
-	InputNewPlaylist = popupctl.InputNewPlaylist
-	InputNewFolder   = popupctl.InputNewFolder
-	InputRename      = popupctl.InputRename
+	InputNone           = popupctl.InputNone
+	InputNewPlaylist    = popupctl.InputNewPlaylist
+	InputNewFolder      = popupctl.InputNewFolder
+	InputRename         = popupctl.InputRename
+	InputNewStation     = popupctl.InputNewStation
+	InputNewBookmark    = popupctl.InputNewBookmark
+	InputRenameBookmark = popupctl.InputRenameBookmark
+	InputImportPlaylist = popupctl.InputImportPlaylist
+	InputExportPlaylist = popupctl.InputExportPlaylist
 )
 
 // PlaylistInputContext stores context for playlist operations.
 type PlaylistInputContext struct {
+	Mode      InputMode
+	ItemID    int64  // For rename: ID of the item being renamed
+	IsFolder  bool   // For rename: whether item is a folder
+	IsStation bool   // For rename: whether item is a radio station
+	FolderID  *int64 // Parent folder ID for creation
+}
+
+// PlaylistFileContext stores context for importing and exporting playlist
+// files, through the path input then the path style choice of exports.
+type PlaylistFileContext struct {
+	Mode       InputMode
+	FolderID   *int64 // For import: folder of the new playlist
+	PlaylistID int64  // For export: playlist exported, unless Queue
+	Queue      bool   // For export: export the queue
+	Name       string // For export: playlist name
+	Path       string // For export: chosen file path
+}
+
+// BookmarkInputContext stores context for naming a bookmark.
+type BookmarkInputContext struct {
 	Mode     InputMode
-	ItemID   int64  // For rename: ID of the item being renamed
-	IsFolder bool   // For rename: whether item is a folder
-	FolderID *int64 // Parent folder ID for creation
+	ID       int64         // For rename: ID of the bookmark
+	Path     string        // For creation: track the bookmark belongs to
+	Position time.Duration // For creation: position of the bookmark
 }
 
 // AddToPlaylistContext stores tracks to add when user selects a playlist.
@@ -189,8 +232,14 @@ type AddToPlaylistContext struct {
 
 // DeleteConfirmContext stores context for delete confirmation.
 type DeleteConfirmContext struct {
-	ItemID   int64
-	IsFolder bool
+	ItemID    int64
+	IsFolder  bool
+	IsStation bool
+}
+
+// FreezeConfirmContext stores context for freezing a smart playlist.
+type FreezeConfirmContext struct {
+	PlaylistID int64
 }
 
 // LibraryDeleteContext stores context for library track deletion.
@@ -207,6 +256,9 @@ type FileDeleteContext struct {
 	IsDir bool
 }
 
+// LoudnessCancelContext marks the confirmation for canceling loudness analysis.
+type LoudnessCancelContext struct{}
+
 // InitStepMsg reports progress during async initialization.
 type InitStepMsg struct {
 	Step string // Description of current step
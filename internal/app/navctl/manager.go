@@ -172,8 +172,8 @@ func (n *Manager) CurrentNavigator() navigator.Node {
 		if sel := n.playlistNav.Selected(); sel != nil {
 			return *sel
 		}
-	case ViewDownloads:
-		// Downloads view doesn't have a navigator
+	case ViewDownloads, ViewHistory:
+		// Downloads and history views don't have a navigator
 		return nil
 	}
 	return nil
@@ -196,8 +196,8 @@ func (n *Manager) UpdateActiveNavigator(msg tea.Msg) tea.Cmd {
 		}
 	case ViewPlaylists:
 		n.playlistNav, cmd = n.playlistNav.Update(msg)
-	case ViewDownloads:
-		// Downloads view is handled separately, not via navigator
+	case ViewDownloads, ViewHistory:
+		// Downloads and history views are handled separately, not via navigator
 	}
 	return cmd
 }
@@ -258,8 +258,8 @@ func (n *Manager) RenderActiveNavigator() string {
 		case LibraryModeMiller:
 			return n.libraryNav.View()
 		}
-	case ViewDownloads:
-		// Downloads view is rendered separately
+	case ViewDownloads, ViewHistory:
+		// Downloads and history views are rendered separately
 		return ""
 	}
 	return ""
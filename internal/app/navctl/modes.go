@@ -13,6 +13,8 @@ const (
 	ViewPlaylists ViewMode = "playlists"
 	// ViewDownloads shows the downloads monitor.
 	ViewDownloads ViewMode = "downloads"
+	// ViewHistory shows the recently played tracks.
+	ViewHistory ViewMode = "history"
 )
 
 // SupportsDeepSearch returns true if the view mode supports deep search (g f).
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/llehouerou/waves/internal/app/navctl"
+	"github.com/llehouerou/waves/internal/daemon"
 	"github.com/llehouerou/waves/internal/playlist"
 	"github.com/llehouerou/waves/internal/state"
 )
@@ -57,25 +58,12 @@ func (m *Model) SaveNavigationState() {
 }
 
 // SaveQueueState persists the current queue state.
+// When attached, the daemon saves its queue.
 func (m *Model) SaveQueueState() {
-	tracks := m.PlaybackService.QueueTracks()
-	queueTracks := make([]state.QueueTrack, len(tracks))
-	for i, t := range tracks {
-		queueTracks[i] = state.QueueTrack{
-			TrackID:     t.ID,
-			Path:        t.Path,
-			Title:       t.Title,
-			Artist:      t.Artist,
-			Album:       t.Album,
-			TrackNumber: t.TrackNumber,
-		}
+	if m.attached() {
+		return
 	}
-	_ = m.StateMgr.SaveQueue(state.QueueState{
-		CurrentIndex: m.PlaybackService.QueueCurrentIndex(),
-		RepeatMode:   int(m.PlaybackService.RepeatMode()),
-		Shuffle:      m.PlaybackService.Shuffle(),
-		Tracks:       queueTracks,
-	})
+	_ = daemon.SaveQueue(m.StateMgr, m.PlaybackService)
 }
 
 // TracksToQueueTracks converts playlist tracks to state queue tracks.
@@ -0,0 +1,165 @@
+// internal/app/playlistfiles.go
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/app/handler"
+	"github.com/llehouerou/waves/internal/app/navctl"
+	"github.com/llehouerou/waves/internal/errmsg"
+	"github.com/llehouerou/waves/internal/keymap"
+	"github.com/llehouerou/waves/internal/playlistfile"
+	"github.com/llehouerou/waves/internal/playlists"
+)
+
+// maxReportedEntries caps the unresolved entries listed after an import.
+const maxReportedEntries = 10
+
+// handlePlaylistFileKeys handles "I" (import a playlist file) in the
+// playlists view and "X" (export to a playlist file) there and in the queue.
+func (m *Model) handlePlaylistFileKeys(key string) handler.Result {
+	action := m.Keys.Resolve(key)
+	if action != keymap.ActionImportPlaylist && action != keymap.ActionExportPlaylist {
+		return handler.NotHandled
+	}
+
+	if action == keymap.ActionExportPlaylist && m.Navigation.IsQueueFocused() {
+		return handler.Handled(m.Popups.ShowTextInput(InputExportPlaylist, "Export Queue (.m3u8, .m3u, .pls or .xspf)",
+			defaultExportPath("queue"), PlaylistFileContext{Mode: InputExportPlaylist, Queue: true, Name: "Queue"}))
+	}
+
+	if m.Navigation.ViewMode() != navctl.ViewPlaylists || !m.Navigation.IsNavigatorFocused() {
+		return handler.NotHandled
+	}
+	current := m.Navigation.PlaylistNav().Current()
+
+	if action == keymap.ActionImportPlaylist {
+		if current.Level() == playlists.LevelPlaylist || current.Level() == playlists.LevelStations {
+			return handler.HandledNoCmd
+		}
+		return handler.Handled(m.Popups.ShowTextInput(InputImportPlaylist, "Import Playlist (M3U, PLS or XSPF file)", "",
+			PlaylistFileContext{Mode: InputImportPlaylist, FolderID: m.getPlaylistParentFolder(current)}))
+	}
+
+	// Export the selected playlist, or the one being browsed
+	var id *int64
+	if selected := m.Navigation.PlaylistNav().Selected(); selected != nil && selected.Level() == playlists.LevelPlaylist {
+		id = selected.PlaylistID()
+	} else if current.Level() == playlists.LevelPlaylist {
+		id = current.PlaylistID()
+	}
+	if id == nil {
+		return handler.HandledNoCmd
+	}
+	pl, err := m.Playlists.Get(*id)
+	if err != nil {
+		m.Popups.ShowOpError(errmsg.OpPlaylistExport, err)
+		return handler.HandledNoCmd
+	}
+	return handler.Handled(m.Popups.ShowTextInput(InputExportPlaylist, "Export Playlist (.m3u8, .m3u, .pls or .xspf)",
+		defaultExportPath(pl.Name), PlaylistFileContext{Mode: InputExportPlaylist, PlaylistID: pl.ID, Name: pl.Name}))
+}
+
+// defaultExportPath suggests an M3U8 file named after the playlist in the
+// home directory.
+func defaultExportPath(name string) string {
+	file := strings.NewReplacer("/", "-", `\`, "-").Replace(name) + ".m3u8"
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return file
+	}
+	return filepath.Join(home, file)
+}
+
+// expandHome replaces a leading ~ with the home directory.
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// processPlaylistFileInput imports the entered file, or asks how the paths
+// of the exported file should be written.
+func (m Model) processPlaylistFileInput(ctx PlaylistFileContext, text string) (tea.Model, tea.Cmd) {
+	path := expandHome(strings.TrimSpace(text))
+	if path == "" {
+		return m, nil
+	}
+
+	if ctx.Mode == InputExportPlaylist {
+		if _, err := playlistfile.FormatFromPath(path); err != nil {
+			m.Popups.ShowOpError(errmsg.OpPlaylistExport, err)
+			return m, nil
+		}
+		ctx.Path = path
+		return m, m.Popups.ShowConfirmWithOptions("Export",
+			fmt.Sprintf("Export %q to %s with", ctx.Name, path),
+			[]string{"Absolute paths", "Relative paths", "Cancel"}, ctx)
+	}
+
+	result, err := playlistfile.Import(m.Playlists, m.Library, path, ctx.FolderID, "")
+	if err != nil && !errors.Is(err, playlistfile.ErrNoTracks) {
+		m.Popups.ShowOpError(errmsg.OpPlaylistImport, err)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	if len(result.Unresolved) > 0 {
+		cmd = m.Popups.ShowConfirmWithOptions("Import", importReport(result), []string{"OK"}, nil)
+	}
+	if result.PlaylistID != 0 {
+		m.Navigation.PlaylistNav().Refresh()
+		m.Navigation.PlaylistNav().NavigateTo("playlists:playlist:" + formatInt64(result.PlaylistID))
+	}
+	return m, cmd
+}
+
+// importReport lists the entries of an import not found in the library.
+func importReport(result playlistfile.ImportResult) string {
+	var sb strings.Builder
+	if result.PlaylistID == 0 {
+		sb.WriteString("No track of the file is in the library.")
+	} else {
+		sb.WriteString(result.Summary() + ".")
+	}
+	fmt.Fprintf(&sb, "\n\nNot found (%d):", len(result.Unresolved))
+	for i, e := range result.Unresolved {
+		if i == maxReportedEntries {
+			fmt.Fprintf(&sb, "\n  … and %d more", len(result.Unresolved)-i)
+			break
+		}
+		sb.WriteString("\n  " + e.String())
+	}
+	return sb.String()
+}
+
+// handlePlaylistExportConfirm writes the export once the path style is
+// chosen: option 0 for absolute paths, 1 for relative paths.
+func (m Model) handlePlaylistExportConfirm(ctx PlaylistFileContext, option int) (tea.Model, tea.Cmd) {
+	relative := option == 1
+
+	var err error
+	if ctx.Queue {
+		tracks := m.PlaybackService.QueueTracks()
+		entries := make([]playlistfile.Entry, len(tracks))
+		for i, t := range tracks {
+			entries[i] = playlistfile.Entry{Location: t.Path, Artist: t.Artist, Title: t.Title, Duration: t.Duration}
+		}
+		err = playlistfile.Export(ctx.Path, ctx.Name, entries, relative)
+	} else {
+		_, err = playlistfile.ExportPlaylist(m.Playlists, ctx.PlaylistID, ctx.Path, relative)
+	}
+	if err != nil {
+		m.Popups.ShowOpError(errmsg.OpPlaylistExport, err)
+	}
+	return m, nil
+}
@@ -7,8 +7,10 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/llehouerou/waves/internal/albumpreset"
+	"github.com/llehouerou/waves/internal/bookmarks"
 	"github.com/llehouerou/waves/internal/download"
 	"github.com/llehouerou/waves/internal/downloads"
+	"github.com/llehouerou/waves/internal/equalizer"
 	"github.com/llehouerou/waves/internal/errmsg"
 	"github.com/llehouerou/waves/internal/export"
 	importpopup "github.com/llehouerou/waves/internal/importer/popup"
@@ -16,11 +18,16 @@ import (
 	"github.com/llehouerou/waves/internal/library"
 	"github.com/llehouerou/waves/internal/lyrics"
 	"github.com/llehouerou/waves/internal/musicbrainz"
+	"github.com/llehouerou/waves/internal/playlists"
 	"github.com/llehouerou/waves/internal/rename"
 	"github.com/llehouerou/waves/internal/retag"
 	"github.com/llehouerou/waves/internal/state"
+	"github.com/llehouerou/waves/internal/tags"
 	"github.com/llehouerou/waves/internal/ui/albumview"
+	bookmarksui "github.com/llehouerou/waves/internal/ui/bookmarks"
+	chaptersui "github.com/llehouerou/waves/internal/ui/chapters"
 	"github.com/llehouerou/waves/internal/ui/confirm"
+	equalizerui "github.com/llehouerou/waves/internal/ui/equalizer"
 	exportui "github.com/llehouerou/waves/internal/ui/export"
 	"github.com/llehouerou/waves/internal/ui/helpbindings"
 	"github.com/llehouerou/waves/internal/ui/lastfmauth"
@@ -29,6 +36,7 @@ import (
 	"github.com/llehouerou/waves/internal/ui/popup"
 	"github.com/llehouerou/waves/internal/ui/scanreport"
 	"github.com/llehouerou/waves/internal/ui/similarartists"
+	"github.com/llehouerou/waves/internal/ui/smartplaylist"
 	"github.com/llehouerou/waves/internal/ui/textinput"
 )
 
@@ -90,7 +98,8 @@ func (p *Manager) IsVisible(t Type) bool {
 	case TextInput:
 		return p.inputMode != InputNone && p.popups[t] != nil
 	case Help, Confirm, LibrarySources, ScanReport, Download, Import,
-		Retag, AlbumGrouping, AlbumSorting, AlbumPresets, LastfmAuth, Export, Lyrics, SimilarArtists:
+		Retag, AlbumGrouping, AlbumSorting, AlbumPresets, LastfmAuth, Export, Lyrics, SimilarArtists, Equalizer, Bookmarks, Chapters,
+		SmartPlaylist:
 		return p.popups[t] != nil
 	}
 	return false
@@ -126,7 +135,8 @@ func (p *Manager) Hide(t Type) {
 		p.inputMode = InputNone
 		delete(p.popups, t)
 	case Help, Confirm, LibrarySources, ScanReport, Download, Import,
-		Retag, AlbumGrouping, AlbumSorting, AlbumPresets, LastfmAuth, Export, Lyrics, SimilarArtists:
+		Retag, AlbumGrouping, AlbumSorting, AlbumPresets, LastfmAuth, Export, Lyrics, SimilarArtists, Equalizer, Bookmarks, Chapters,
+		SmartPlaylist:
 		delete(p.popups, t)
 	}
 }
@@ -308,8 +318,55 @@ func (p *Manager) SimilarArtists() *similarartists.Model {
 	return nil
 }
 
+// ShowEqualizer displays the equalizer popup.
+func (p *Manager) ShowEqualizer(state equalizer.State, presets []equalizer.Preset) tea.Cmd {
+	eq := equalizerui.New(state, presets)
+	return p.Show(Equalizer, eq)
+}
+
+// Equalizer returns the equalizer popup model for direct access.
+func (p *Manager) Equalizer() *equalizerui.Model {
+	if pop := p.popups[Equalizer]; pop != nil {
+		if eq, ok := pop.(*equalizerui.Model); ok {
+			return eq
+		}
+	}
+	return nil
+}
+
+// ShowNewSmartPlaylist displays the rule editor for a new smart playlist.
+func (p *Manager) ShowNewSmartPlaylist(folderID *int64) tea.Cmd {
+	return p.Show(SmartPlaylist, smartplaylist.New(folderID))
+}
+
+// ShowSmartPlaylist displays the rule editor of a smart playlist.
+func (p *Manager) ShowSmartPlaylist(id int64, name string, rules playlists.Rules) tea.Cmd {
+	return p.Show(SmartPlaylist, smartplaylist.NewEdit(id, name, rules))
+}
+
 // --- Accessors ---
 
+// ShowBookmarks displays the bookmarks of a track.
+func (p *Manager) ShowBookmarks(title string, list []bookmarks.Bookmark) tea.Cmd {
+	return p.Show(Bookmarks, bookmarksui.New(title, list))
+}
+
+// Bookmarks returns the bookmarks popup model for direct access.
+func (p *Manager) Bookmarks() *bookmarksui.Model {
+	if pop := p.popups[Bookmarks]; pop != nil {
+		if bm, ok := pop.(*bookmarksui.Model); ok {
+			return bm
+		}
+	}
+	return nil
+}
+
+// ShowChapters displays the chapters of a track, with the cursor on the
+// chapter playing.
+func (p *Manager) ShowChapters(title string, list []tags.Chapter, current int) tea.Cmd {
+	return p.Show(Chapters, chaptersui.New(title, list, current))
+}
+
 // InputMode returns the current input mode.
 func (p *Manager) InputMode() InputMode {
 	return p.inputMode
@@ -22,6 +22,10 @@ const (
 	Export
 	Lyrics
 	SimilarArtists
+	Equalizer
+	Bookmarks
+	Chapters
+	SmartPlaylist
 )
 
 // Priority defines which popup takes precedence (highest priority first).
@@ -35,6 +39,10 @@ var Priority = []Type{
 	AlbumGrouping,
 	AlbumSorting,
 	AlbumPresets,
+	Equalizer,
+	SmartPlaylist,
+	Bookmarks,
+	Chapters,
 	LastfmAuth,
 	Export,
 	Lyrics,
@@ -53,6 +61,10 @@ var RenderOrder = []Type{
 	Lyrics,
 	Export,
 	LastfmAuth,
+	Chapters,
+	Bookmarks,
+	SmartPlaylist,
+	Equalizer,
 	AlbumPresets,
 	AlbumSorting,
 	AlbumGrouping,
@@ -74,6 +86,16 @@ const (
 	InputNewPlaylist
 	// InputNewFolder indicates creating a new folder.
 	InputNewFolder
-	// InputRename indicates renaming a playlist or folder.
+	// InputRename indicates renaming a playlist, folder or station.
 	InputRename
+	// InputNewStation indicates adding a radio station from its URL.
+	InputNewStation
+	// InputNewBookmark indicates naming a new bookmark of the playing track.
+	InputNewBookmark
+	// InputRenameBookmark indicates renaming a bookmark.
+	InputRenameBookmark
+	// InputImportPlaylist indicates entering the path of a playlist file to import.
+	InputImportPlaylist
+	// InputExportPlaylist indicates entering the path a playlist is exported to.
+	InputExportPlaylist
 )
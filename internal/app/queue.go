@@ -68,8 +68,8 @@ func (m *Model) collectTracksFromSelected() ([]playlist.Track, error) {
 		if sel := m.Navigation.PlaylistNav().Selected(); sel != nil {
 			return collectFromPlaylistNode(m.Playlists, *sel)
 		}
-	case navctl.ViewDownloads:
-		// Downloads view doesn't support queue actions
+	case navctl.ViewDownloads, navctl.ViewHistory:
+		// Downloads and history views handle their own queue actions
 		return nil, nil
 	}
 	return nil, nil
@@ -92,6 +92,14 @@ func collectFromPlaylistNode(pls *playlists.Playlists, node playlists.Node) ([]p
 			return []playlist.Track{*t}, nil
 		}
 		return nil, nil
+	case playlists.LevelStation:
+		if st := node.Station(); st != nil {
+			return []playlist.Track{st.Track()}, nil
+		}
+		return nil, nil
+	case playlists.LevelStations:
+		// Stations play one at a time
+		return nil, nil
 	default:
 		return nil, nil
 	}
@@ -128,8 +136,8 @@ func (m *Model) HandleContainerAndPlay() tea.Cmd {
 			return nil
 		}
 		tracks, selectedIdx, err = playlist.CollectFolderFromFile(*selected)
-	case navctl.ViewDownloads:
-		// Not supported for downloads view
+	case navctl.ViewDownloads, navctl.ViewHistory:
+		// Not supported for downloads and history views
 		return nil
 	}
 
@@ -178,7 +186,13 @@ func (m *Model) collectPlaylistFromNode(node playlists.Node) ([]playlist.Track,
 		}
 		tracks, err := m.Playlists.Tracks(*playlistID)
 		return tracks, 0, err
-	case playlists.LevelRoot, playlists.LevelFolder:
+	case playlists.LevelStation:
+		// A station is its own container: streams don't end
+		if st := node.Station(); st != nil {
+			return []playlist.Track{st.Track()}, 0, nil
+		}
+		return nil, 0, nil
+	case playlists.LevelRoot, playlists.LevelFolder, playlists.LevelStations:
 		// Can't play root or folders directly
 		return nil, 0, nil
 	}
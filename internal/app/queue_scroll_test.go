@@ -117,6 +117,11 @@ func openLibraryTestDB(t *testing.T) *sql.DB {
 			original_date TEXT,
 			release_date TEXT,
 			label TEXT,
+			play_count INTEGER NOT NULL DEFAULT 0,
+			skip_count INTEGER NOT NULL DEFAULT 0,
+			last_played_at INTEGER,
+			rating INTEGER NOT NULL DEFAULT 0,
+			album_rating INTEGER NOT NULL DEFAULT 0,
 			added_at INTEGER NOT NULL,
 			updated_at INTEGER NOT NULL
 		);
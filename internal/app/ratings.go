@@ -0,0 +1,171 @@
+package app
+
+import (
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/app/handler"
+	"github.com/llehouerou/waves/internal/app/navctl"
+	"github.com/llehouerou/waves/internal/config"
+	"github.com/llehouerou/waves/internal/errmsg"
+	"github.com/llehouerou/waves/internal/keymap"
+	"github.com/llehouerou/waves/internal/library"
+	"github.com/llehouerou/waves/internal/tags"
+	"github.com/llehouerou/waves/internal/ui/librarybrowser"
+)
+
+// ratingState holds how rating keys change ratings.
+type ratingState struct {
+	step      int  // Half stars added or removed by < and >
+	writeTags bool // Write changed ratings to file tags
+}
+
+// newRatingState creates the rating state from the configuration.
+func newRatingState(cfg config.RatingsConfig) ratingState {
+	step := 2
+	if cfg.HalfStars != nil && *cfg.HalfStars {
+		step = 1
+	}
+	writeTags := cfg.WriteTags == nil || *cfg.WriteTags
+	return ratingState{step: step, writeTags: writeTags}
+}
+
+// apply returns the rating, in half stars, after a rating key is pressed on
+// an item rated current. Digit keys set whole stars, < and > move by a step,
+// snapping to whole stars when half stars are disabled.
+func (s ratingState) apply(action keymap.Action, key string, current int) int {
+	step := max(s.step, 1)
+	switch action { //nolint:exhaustive // only handling rating actions
+	case keymap.ActionRate:
+		stars, err := strconv.Atoi(key)
+		if err != nil {
+			return current
+		}
+		return tags.ClampRating(stars * 2)
+	case keymap.ActionRatingUp:
+		return tags.ClampRating(current - current%step + step)
+	case keymap.ActionRatingDown:
+		if current%step != 0 {
+			return current - current%step
+		}
+		return tags.ClampRating(current - step)
+	}
+	return current
+}
+
+// handleRateKey rates the track or album selected in the library.
+func (m *Model) handleRateKey(action keymap.Action, key string) handler.Result {
+	if m.Navigation.IsBrowserViewActive() {
+		browser := m.Navigation.LibraryBrowser()
+		switch browser.ActiveColumn() { //nolint:exhaustive // artists are not rated
+		case librarybrowser.ColumnTracks:
+			if track := browser.SelectedTrack(); track != nil {
+				return m.rateTracks([]int64{track.ID}, action, key)
+			}
+			return handler.HandledNoCmd
+		case librarybrowser.ColumnAlbums:
+			if album := browser.SelectedAlbum(); album != nil {
+				rating := m.ratings.apply(action, key, album.Rating)
+				return m.rateAlbum(browser.SelectedArtist(), album.Name, rating)
+			}
+			return handler.HandledNoCmd
+		}
+		return handler.NotHandled
+	}
+
+	if m.Navigation.LibrarySubMode() == navctl.LibraryModeAlbum {
+		album := m.Navigation.AlbumView().SelectedAlbum()
+		if album == nil {
+			return handler.HandledNoCmd
+		}
+		rating := m.ratings.apply(action, key, album.Rating)
+		return m.rateAlbum(album.AlbumArtist, album.Album, rating)
+	}
+
+	selected := m.Navigation.LibraryNav().Selected()
+	if selected == nil {
+		return handler.NotHandled
+	}
+	switch selected.Level() { //nolint:exhaustive // artists are not rated
+	case library.LevelTrack:
+		if track := selected.Track(); track != nil {
+			return m.rateTracks([]int64{track.ID}, action, key)
+		}
+		return handler.HandledNoCmd
+	case library.LevelAlbum:
+		rating := m.ratings.apply(action, key, selected.Rating())
+		return m.rateAlbum(selected.Artist(), selected.Album(), rating)
+	}
+	return handler.NotHandled
+}
+
+// rateTracks applies a rating key to library tracks. Tracks outside the
+// library (ID 0) are skipped.
+func (m *Model) rateTracks(trackIDs []int64, action keymap.Action, key string) handler.Result {
+	if m.Library == nil {
+		return handler.HandledNoCmd
+	}
+	var rated []library.Track
+	for _, id := range trackIDs {
+		if id == 0 {
+			continue
+		}
+		track, err := m.Library.TrackByID(id)
+		if err != nil {
+			m.Popups.ShowOpError(errmsg.OpRatingSave, err)
+			return handler.HandledNoCmd
+		}
+		track.Rating = m.ratings.apply(action, key, track.Rating)
+		if err := m.Library.SetTrackRating(id, track.Rating); err != nil {
+			m.Popups.ShowOpError(errmsg.OpRatingSave, err)
+			return handler.HandledNoCmd
+		}
+		rated = append(rated, *track)
+	}
+	return m.ratingsChanged(rated)
+}
+
+// rateAlbum sets the rating of an album, in half stars.
+func (m *Model) rateAlbum(albumArtist, album string, rating int) handler.Result {
+	if m.Library == nil {
+		return handler.HandledNoCmd
+	}
+	if err := m.Library.SetAlbumRating(albumArtist, album, rating); err != nil {
+		m.Popups.ShowOpError(errmsg.OpRatingSave, err)
+		return handler.HandledNoCmd
+	}
+	tracks, err := m.Library.Tracks(albumArtist, album)
+	if err != nil {
+		m.Popups.ShowOpError(errmsg.OpRatingSave, err)
+		return handler.HandledNoCmd
+	}
+	return m.ratingsChanged(tracks)
+}
+
+// ratingsChanged refreshes the views showing ratings after tracks were
+// rated, and writes the new ratings to their files in the background.
+func (m *Model) ratingsChanged(tracks []library.Track) handler.Result {
+	if len(tracks) == 0 {
+		return handler.HandledNoCmd
+	}
+
+	m.refreshLibraryNavigator(true)
+	_ = m.Navigation.AlbumView().Refresh()
+
+	if current := m.PlaybackService.CurrentTrack(); current != nil && m.mprisAdapter != nil {
+		for _, t := range tracks {
+			if t.ID == current.ID {
+				m.mprisAdapter.RatingChanged()
+				break
+			}
+		}
+	}
+
+	if !m.ratings.writeTags {
+		return handler.HandledNoCmd
+	}
+	return handler.Handled(func() tea.Msg {
+		return RatingTagsWrittenMsg{Err: library.WriteRatingTags(tracks)}
+	})
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/llehouerou/waves/internal/config"
+	"github.com/llehouerou/waves/internal/keymap"
+)
+
+func TestRatingState_Apply(t *testing.T) {
+	halfStars, wholeStars := true, false
+	half := newRatingState(config.RatingsConfig{HalfStars: &halfStars})
+	whole := newRatingState(config.RatingsConfig{HalfStars: &wholeStars})
+
+	tests := []struct {
+		name    string
+		state   ratingState
+		action  keymap.Action
+		key     string
+		current int
+		want    int
+	}{
+		{"digit sets stars", half, keymap.ActionRate, "4", 3, 8},
+		{"zero clears", half, keymap.ActionRate, "0", 7, 0},
+		{"up by half star", half, keymap.ActionRatingUp, ">", 7, 8},
+		{"up capped", half, keymap.ActionRatingUp, ">", 10, 10},
+		{"down by half star", half, keymap.ActionRatingDown, "<", 1, 0},
+		{"down floored", half, keymap.ActionRatingDown, "<", 0, 0},
+		{"up by star", whole, keymap.ActionRatingUp, ">", 4, 6},
+		{"up snaps to stars", whole, keymap.ActionRatingUp, ">", 5, 6},
+		{"down snaps to stars", whole, keymap.ActionRatingDown, "<", 5, 4},
+		{"down by star", whole, keymap.ActionRatingDown, "<", 4, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.state.apply(tt.action, tt.key, tt.current); got != tt.want {
+				t.Errorf("apply(%s, %q, %d) = %d, want %d", tt.action, tt.key, tt.current, got, tt.want)
+			}
+		})
+	}
+
+	if !newRatingState(config.RatingsConfig{}).writeTags {
+		t.Error("ratings should be written to tags by default")
+	}
+}
@@ -16,19 +16,22 @@ import (
 	"github.com/llehouerou/waves/internal/export"
 	importpopup "github.com/llehouerou/waves/internal/importer/popup"
 	"github.com/llehouerou/waves/internal/lastfm"
+	"github.com/llehouerou/waves/internal/loudness"
 	"github.com/llehouerou/waves/internal/musicbrainz/workflow"
 	"github.com/llehouerou/waves/internal/navigator"
 	"github.com/llehouerou/waves/internal/retag"
-	"github.com/llehouerou/waves/internal/slskd"
 	"github.com/llehouerou/waves/internal/ui/action"
 	exportui "github.com/llehouerou/waves/internal/ui/export"
 	"github.com/llehouerou/waves/internal/ui/lastfmauth"
 	lyricsui "github.com/llehouerou/waves/internal/ui/lyrics"
 	"github.com/llehouerou/waves/internal/ui/similarartists"
+	"github.com/llehouerou/waves/internal/waveform"
 )
 
 // Update handles messages and returns updated model and commands.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m.syncRemoteQueue()
+
 	switch msg := msg.(type) {
 	// Standard tea messages first
 	case tea.KeyMsg:
@@ -58,6 +61,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case action.Msg:
 		return m.handleUIAction(msg)
 
+	case HistoryRecordedMsg:
+		return m.handleHistoryRecorded()
+
+	case RatingTagsWrittenMsg:
+		if msg.Err != nil {
+			m.Popups.ShowOpError(errmsg.OpRatingTags, msg.Err)
+		}
+		return m, nil
+
 	// Pass-through messages for download popup internal workflows
 	case download.SlskdSearchStartedMsg,
 		download.SlskdSearchPollMsg,
@@ -155,6 +167,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	// Loudness analysis job messages
+	case loudness.ProgressMsg, loudness.CompleteMsg:
+		return m.handleLoudnessMsg(msg)
+
+	// Waveform messages
+	case waveform.LoadedMsg, waveform.ProgressMsg, waveform.CompleteMsg:
+		return m.handleWaveformMsg(msg)
+
 	// Notification messages
 	case NotificationClearMsg:
 		// Remove the specific notification by ID
@@ -239,9 +259,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) handleMouseMsg(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
-	// Handle scroll on player bar for volume control (with debounce)
+	// Handle scroll on player bar for volume control (with debounce),
+	// and clicks on the seek bar
 	if m.isMouseOnPlayerBar(msg) {
-		switch msg.Button { //nolint:exhaustive // only handling scroll
+		switch msg.Button { //nolint:exhaustive // only handling scroll and left click
+		case tea.MouseButtonLeft:
+			if msg.Action == tea.MouseActionPress && m.handleSeekBarClick(msg) {
+				return m, nil
+			}
 		case tea.MouseButtonWheelUp, tea.MouseButtonWheelDown:
 			// Debounce: ignore scroll events within 100ms of the last one
 			now := time.Now()
@@ -291,6 +316,13 @@ func (m Model) handleNavigatorMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 		return m.routeMouseToNavigator(msg)
 	}
 
+	// History view handles its own mouse events (including middle click)
+	if m.Navigation.ViewMode() == navctl.ViewHistory {
+		var cmd tea.Cmd
+		m.HistoryView, cmd = m.HistoryView.Update(msg)
+		return m, cmd
+	}
+
 	// Handle middle click for Miller columns: navigate into container OR play track
 	if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonMiddle {
 		return m.handleNavigatorMiddleClick(msg)
@@ -397,6 +429,7 @@ func (m Model) handleGlobalKeys(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd)
 		func() handler.Result { return m.handlePlaybackKeys(key) },
 		func() handler.Result { return m.handleNavigatorActionKeys(key) },
 		func() handler.Result { return m.handlePlaylistKeys(key) },
+		func() handler.Result { return m.handlePlaylistFileKeys(key) },
 		func() handler.Result { return m.handleLibraryKeys(key) },
 		func() handler.Result { return m.handleFileBrowserKeys(key) },
 		func() handler.Result { return m.handleExportKey(key) },
@@ -412,6 +445,13 @@ func (m Model) handleGlobalKeys(key string, msg tea.KeyMsg) (tea.Model, tea.Cmd)
 		return m, cmd
 	}
 
+	// Delegate unhandled keys to history view when it's active
+	if m.Navigation.ViewMode() == navctl.ViewHistory && m.Navigation.IsNavigatorFocused() {
+		var cmd tea.Cmd
+		m.HistoryView, cmd = m.HistoryView.Update(msg)
+		return m, cmd
+	}
+
 	// Delegate unhandled keys to the active navigator
 	if m.Navigation.IsNavigatorFocused() {
 		cmd := m.Navigation.UpdateActiveNavigator(msg)
@@ -570,16 +610,15 @@ func (m Model) handleDownloadMsgCategory(msg DownloadMessage) (tea.Model, tea.Cm
 	switch msg := msg.(type) {
 	case DownloadCreatedMsg:
 		// Persist the new download and refresh
-		return m, CreateDownloadCmd(m.Downloads, msg)
+		return m, CreateDownloadCmd(m.DownloadControl, msg)
 
 	case DownloadsRefreshMsg:
 		// Periodic refresh trigger
 		if !m.HasSlskdConfig {
 			return m, nil
 		}
-		client := slskd.NewClient(m.Slskd.URL, m.Slskd.APIKey)
 		return m, tea.Batch(
-			RefreshDownloadsCmd(m.Downloads, client, m.Slskd.CompletedPath),
+			RefreshDownloadsCmd(m.DownloadControl),
 			DownloadsRefreshTickCmd(),
 		)
 
@@ -141,7 +141,7 @@ func (m *Model) queueFailedScrobble(trackPath string) {
 
 // handleLastfmRetryPending triggers retry of pending scrobbles.
 func (m *Model) handleLastfmRetryPending() (Model, tea.Cmd) {
-	if !m.isLastfmLinked() {
+	if !m.scrobbles() {
 		return *m, nil
 	}
 
@@ -216,28 +216,10 @@ func (m *Model) buildScrobbleTrack() *lastfm.ScrobbleTrack {
 		return nil
 	}
 
-	track := &lastfm.ScrobbleTrack{
-		Artist:   info.Artist,
-		Track:    info.Title,
-		Album:    info.Album,
-		Duration: m.PlaybackService.Duration(),
-	}
-
+	startedAt := time.Now()
 	if m.ScrobbleState != nil {
-		track.Timestamp = m.ScrobbleState.StartedAt
-	} else {
-		track.Timestamp = time.Now()
-	}
-
-	// Use MusicBrainz recording ID if available
-	if info.MBRecordingID != "" {
-		track.MBRecordingID = info.MBRecordingID
+		startedAt = m.ScrobbleState.StartedAt
 	}
-
-	// Set album artist if different from track artist
-	if info.AlbumArtist != "" && info.AlbumArtist != info.Artist {
-		track.AlbumArtist = info.AlbumArtist
-	}
-
-	return track
+	track := lastfm.NewScrobbleTrack(info, m.PlaybackService.Duration(), startedAt)
+	return &track
 }
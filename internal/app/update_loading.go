@@ -13,7 +13,6 @@ import (
 	"github.com/llehouerou/waves/internal/errmsg"
 	"github.com/llehouerou/waves/internal/library"
 	"github.com/llehouerou/waves/internal/navigator"
-	"github.com/llehouerou/waves/internal/playback"
 	"github.com/llehouerou/waves/internal/playlist"
 	"github.com/llehouerou/waves/internal/playlists"
 	"github.com/llehouerou/waves/internal/ui/albumview"
@@ -88,25 +87,22 @@ func (m Model) handleInitResult(msg InitResult) (tea.Model, tea.Cmd) {
 		m.Navigation.SetPlaylistNav(plsNav)
 	}
 	if queue, ok := msg.Queue.(*playlist.PlayingQueue); ok {
-		// Close the old service to stop its goroutines and clean up subscriptions
-		_ = m.PlaybackService.Close()
-		// Recreate PlaybackService with the restored queue
-		// (the old service had an empty queue created during New())
-		p := m.PlaybackService.Player()
-		m.PlaybackService = playback.New(p, queue)
+		// Recreate PlaybackService with the restored queue, closing the old
+		// one (the old service had an empty queue created during New())
+		m.PlaybackService = m.playback.Replace(queue)
 		m.playbackSub = m.PlaybackService.Subscribe()
 		if m.mprisAdapter != nil {
 			m.mprisAdapter.Resubscribe(m.PlaybackService)
 		}
-		// Re-configure gapless playback preload callback for the new service
-		svc := m.PlaybackService
-		p.SetPreloadFunc(func() string {
-			next := svc.QueuePeekNext()
-			if next == nil {
-				return ""
-			}
-			return next.Path
-		})
+		if m.ctlServer != nil {
+			m.ctlServer.SetService(m.PlaybackService)
+		}
+		if m.mpdServer != nil {
+			m.mpdServer.SetService(m.PlaybackService)
+		}
+		if m.httpServer != nil {
+			m.httpServer.SetService(m.PlaybackService)
+		}
 	}
 	if queuePanel, ok := msg.QueuePanel.(queuepanel.Model); ok {
 		m.Layout.SetQueuePanel(queuePanel)
@@ -133,6 +129,12 @@ func (m Model) handleInitResult(msg InitResult) (tea.Model, tea.Cmd) {
 	// Ensure FTS search index exists (only builds if empty)
 	_ = m.Library.EnsureFTSIndex()
 
+	// Load history if starting on history view
+	if msg.SavedView == navctl.ViewHistory {
+		m.reloadHistory()
+		m.HistoryView.SetFocused(true)
+	}
+
 	// Load downloads if starting on downloads view
 	var downloadsRefreshCmd tea.Cmd
 	if msg.SavedView == navctl.ViewDownloads && m.HasSlskdConfig {
@@ -37,6 +37,10 @@ func (m Model) handlePlaybackMsg(msg PlaybackMessage) (tea.Model, tea.Cmd) {
 	case ServiceErrorMsg:
 		return m.handleServiceError(msg)
 	case ServiceClosedMsg:
+		if m.attached() {
+			// The daemon stopped or was detached from
+			return m, tea.Quit
+		}
 		return m, nil // Service closed, nothing to do
 	case ServiceQueueChangedMsg:
 		// Queue changed - schedule album art update for next tick to ensure
@@ -68,6 +72,9 @@ func (m Model) handlePlaybackMsg(msg PlaybackMessage) (tea.Model, tea.Cmd) {
 		return m, m.WatchServiceEvents()
 	case TrackSkipTimeoutMsg:
 		return m.handleTrackSkipTimeout(msg)
+	case VisualizerTickMsg:
+		cmd := m.handleVisualizerTick(msg)
+		return m, cmd
 	case TickMsg:
 		// Drop ticks from a stale chain: only the current generation may
 		// re-arm, so at most one chain stays alive (issue #28).
@@ -128,7 +135,8 @@ func (m Model) handleServiceStateChanged(msg ServiceStateChangedMsg) (tea.Model,
 // handlePlaybackStarted handles the transition to playing state.
 // fromStopped indicates if we're starting from a stopped state (first play).
 func (m Model) handlePlaybackStarted(fromStopped bool) (tea.Model, tea.Cmd) {
-	cmds := []tea.Cmd{m.ensureTickRunning(), m.WatchServiceEvents()}
+	m.loadBookmarks()
+	cmds := []tea.Cmd{m.ensureTickRunning(), m.ensureVisualizerTick(), m.loadWaveforms(), m.WatchServiceEvents()}
 
 	// When starting from stopped, handle first track setup
 	// (TrackChange is not emitted for first play, only for track changes)
@@ -189,7 +197,8 @@ func (m Model) handleServiceTrackChanged(_ ServiceTrackChangedMsg) (tea.Model, t
 		m.sendNowPlayingNotification(track)
 	}
 
-	cmds := []tea.Cmd{m.WatchServiceEvents()}
+	m.loadBookmarks()
+	cmds := []tea.Cmd{m.WatchServiceEvents(), m.loadWaveforms()}
 
 	// Schedule lyrics update if popup is visible (deferred to ensure track info is ready)
 	if m.Popups.Lyrics() != nil {
@@ -308,29 +317,18 @@ func (m *Model) sendDownloadCompleteNotification(artist, album string) {
 }
 
 // checkScrobbleThreshold checks if the current track has been played long enough to scrobble.
-// Last.fm rules: scrobble after 50% of duration OR 4 minutes, whichever comes first.
-// Track must be at least 30 seconds long.
+// See lastfm.ScrobbleThreshold for the Last.fm rules.
 func (m *Model) checkScrobbleThreshold() tea.Cmd {
-	if m.ScrobbleState == nil || m.ScrobbleState.Scrobbled || !m.isLastfmLinked() {
+	if m.ScrobbleState == nil || m.ScrobbleState.Scrobbled || !m.scrobbles() {
 		return nil
 	}
 
-	position := m.PlaybackService.Position()
-	duration := m.PlaybackService.Duration()
-
-	// Track must be at least 30 seconds
-	if duration < 30*time.Second {
+	threshold, ok := lastfm.ScrobbleThreshold(m.PlaybackService.Duration(), m.PlaybackService.Speed())
+	if !ok {
 		return nil
 	}
 
-	// Scrobble threshold: min(50% of duration, 4 minutes)
-	threshold := duration / 2
-	fourMinutes := 4 * time.Minute
-	if fourMinutes < threshold {
-		threshold = fourMinutes
-	}
-
-	if position >= threshold {
+	if m.PlaybackService.Position() >= threshold {
 		m.ScrobbleState.Scrobbled = true
 		track := m.buildScrobbleTrack()
 		if track != nil {
@@ -42,7 +42,7 @@ func (m Model) View() string {
 	}
 	header := headerbar.Render(string(m.Navigation.ViewMode()), m.Layout.Width(), m.HasSlskdConfig, libSubMode)
 
-	// Render active navigator (special case for empty library and downloads)
+	// Render active navigator (special case for empty library, downloads and history)
 	var navView string
 	switch m.Navigation.ViewMode() {
 	case navctl.ViewLibrary:
@@ -55,6 +55,8 @@ func (m Model) View() string {
 		navView = m.Navigation.RenderActiveNavigator()
 	case navctl.ViewDownloads:
 		navView = m.DownloadsView.View()
+	case navctl.ViewHistory:
+		navView = m.HistoryView.View()
 	}
 
 	// Combine navigator and queue panel if visible
@@ -88,6 +90,12 @@ func (m Model) View() string {
 		for _, job := range m.ExportJobs {
 			jobs = append(jobs, *job.JobBar())
 		}
+		if m.LoudnessJob != nil {
+			jobs = append(jobs, *m.LoudnessJob.JobBar())
+		}
+		if m.WaveformJob != nil {
+			jobs = append(jobs, *m.WaveformJob.JobBar())
+		}
 		jobState := jobbar.State{Jobs: jobs}
 		view += "\n" + jobbar.Render(jobState, m.Layout.Width())
 	}
@@ -273,8 +281,29 @@ func joinColumnsView(left, right string) string {
 
 // renderPlayerBar renders the player bar with radio state.
 func (m Model) renderPlayerBar() string {
+	return playerbar.Render(m.playerBarState(), m.Layout.Width())
+}
+
+// playerBarState builds the state the player bar is rendered from.
+func (m Model) playerBarState() playerbar.State {
 	state := playerbar.NewState(m.PlaybackService.Player(), m.Layout.PlayerDisplayMode())
 	state.RadioEnabled = m.PlaybackService.RepeatMode() == playback.RepeatRadio
+	if sleep := m.PlaybackService.SleepTimer(); sleep.Mode != playback.SleepOff {
+		state.Sleep = true
+		state.SleepLeft = sleep.Remaining
+	}
+	if m.viz.enabled {
+		state.Visualizer = m.viz.analyzer
+	}
+	state.Waveform = m.currentWaveform()
+	if l := m.PlaybackService.Loop(); l.Active() {
+		state.LoopA, state.LoopB = l.A, l.B
+	} else if track := m.PlaybackService.CurrentTrack(); track != nil && m.loopStart.path == track.Path {
+		state.LoopA = m.loopStart.pos
+	}
+	for _, b := range m.currentBookmarks() {
+		state.Bookmarks = append(state.Bookmarks, b.Position)
+	}
 
 	// Set up album art placeholder for expanded mode
 	if state.DisplayMode == playerbar.ModeExpanded && state.TrackPath != "" && m.AlbumArt != nil {
@@ -284,7 +313,7 @@ func (m Model) renderPlayerBar() string {
 		}
 	}
 
-	return playerbar.Render(state, m.Layout.Width())
+	return state
 }
 
 // renderEmptyLibrary renders a helpful message when no library sources are configured.
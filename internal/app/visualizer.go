@@ -0,0 +1,97 @@
+// internal/app/visualizer.go
+package app
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/config"
+	"github.com/llehouerou/waves/internal/ui/playerbar"
+	"github.com/llehouerou/waves/internal/visualizer"
+)
+
+// defaultVisualizerFPS is the refresh rate of models built without a config.
+const defaultVisualizerFPS = 20
+
+// visualizerState holds the spectrum analyzer of the expanded player bar.
+// Its frames run on their own tick chain, separate from the 1s tick, which
+// only lives while the visualizer is on screen and playback is running.
+// Like the 1s chain (issue #28), gen is the only valid generation and
+// running is true while a chain is alive, so at most one chain exists.
+type visualizerState struct {
+	enabled  bool
+	interval time.Duration // Time between frames, caps the refresh rate
+	analyzer *visualizer.Analyzer
+	gen      int
+	running  bool
+	last     time.Time // Time of the last frame
+}
+
+func newVisualizerState(cfg config.VisualizerConfig) visualizerState {
+	return visualizerState{
+		enabled:  cfg.Enabled,
+		interval: time.Second / time.Duration(cfg.FPS),
+		analyzer: visualizer.NewAnalyzer(),
+	}
+}
+
+// visualizerActive returns true if the visualizer is shown and has audio
+// to follow.
+func (m *Model) visualizerActive() bool {
+	return m.viz.enabled &&
+		m.Layout.PlayerDisplayMode() == playerbar.ModeExpanded &&
+		m.PlaybackService.IsPlaying()
+}
+
+// ensureVisualizerTick starts the frame chain if the visualizer is active
+// and no chain is running. The returned command, if any, must be scheduled
+// by the caller.
+func (m *Model) ensureVisualizerTick() tea.Cmd {
+	if m.viz.running || !m.visualizerActive() {
+		return nil
+	}
+	m.viz.running = true
+	m.viz.gen++
+	m.viz.last = time.Time{}
+	return VisualizerTickCmd(m.viz.gen, m.viz.interval)
+}
+
+// handleVisualizerTick analyzes the latest audio and schedules the next
+// frame. The chain ends by itself once the visualizer is hidden or
+// playback stops.
+func (m *Model) handleVisualizerTick(msg VisualizerTickMsg) tea.Cmd {
+	if msg.Gen != m.viz.gen {
+		return nil
+	}
+	if !m.visualizerActive() {
+		m.viz.running = false
+		return nil
+	}
+	dt := m.viz.interval
+	if !m.viz.last.IsZero() {
+		dt = msg.Time.Sub(m.viz.last)
+	}
+	m.viz.last = msg.Time
+
+	p := m.PlaybackService.Player()
+	m.viz.analyzer.Update(p.Tap(), p.OutputSampleRate(), dt)
+	return VisualizerTickCmd(m.viz.gen, m.viz.interval)
+}
+
+// ToggleVisualizer shows or hides the visualizer. Showing it switches the
+// player bar to the expanded view, where it is drawn. It can't be shown
+// while attached, as the audio plays in the daemon.
+func (m *Model) ToggleVisualizer() tea.Cmd {
+	if m.viz.analyzer == nil {
+		m.viz = newVisualizerState(config.VisualizerConfig{FPS: defaultVisualizerFPS})
+	}
+	if !m.viz.enabled && m.unavailableAttached("Spectrum analyzer") {
+		return nil
+	}
+	m.viz.enabled = !m.viz.enabled
+	if m.viz.enabled && m.Layout.PlayerDisplayMode() != playerbar.ModeExpanded {
+		m.TogglePlayerDisplayMode()
+	}
+	return m.ensureVisualizerTick()
+}
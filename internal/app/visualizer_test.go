@@ -0,0 +1,93 @@
+// internal/app/visualizer_test.go
+package app
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/ui/playerbar"
+)
+
+func newVisualizerTestModel(t *testing.T) (*Model, *player.Mock) {
+	t.Helper()
+	m := newTestModel()
+	m.Layout.SetSize(120, 40)
+	m.PlaybackService.AddTracks(playback.Track{Path: "/a.mp3", Title: "A"})
+	mock, ok := m.PlaybackService.Player().(*player.Mock)
+	if !ok {
+		t.Fatal("expected mock player")
+	}
+	mock.SetState(player.Playing)
+	mock.SetOutputSampleRate(48000)
+	return m, mock
+}
+
+func TestVisualizer_ToggleShowsExpandedBar(t *testing.T) {
+	m, _ := newVisualizerTestModel(t)
+
+	if cmd := m.ToggleVisualizer(); cmd == nil {
+		t.Fatal("enabling the visualizer should start its frame chain")
+	}
+	if m.Layout.PlayerDisplayMode() != playerbar.ModeExpanded {
+		t.Error("enabling the visualizer should expand the player bar")
+	}
+	if cmd := m.ensureVisualizerTick(); cmd != nil {
+		t.Error("a second chain must not start while one is running")
+	}
+
+	m.ToggleVisualizer()
+	if m.viz.enabled {
+		t.Error("second toggle should disable the visualizer")
+	}
+}
+
+func TestVisualizer_TickAnalyzesAndEnds(t *testing.T) {
+	m, mock := newVisualizerTestModel(t)
+	m.ToggleVisualizer()
+
+	// 1 kHz tone at full scale on the left channel only
+	frames := make([][2]float64, 24000)
+	for i := range frames {
+		frames[i][0] = math.Sin(2 * math.Pi * 1000 * float64(i) / 48000)
+	}
+	mock.Tap().Write(frames)
+
+	now := time.Now()
+	for i := range 3 {
+		msg := VisualizerTickMsg{Gen: m.viz.gen, Time: now.Add(time.Duration(i) * 50 * time.Millisecond)}
+		if cmd := m.handleVisualizerTick(msg); cmd == nil {
+			t.Fatalf("frame %d: chain should continue while playing", i)
+		}
+	}
+	levels := m.viz.analyzer.Levels()
+	if levels[0].RMS <= 0 || levels[1].RMS != 0 {
+		t.Errorf("levels = %+v, want only the left channel", levels)
+	}
+
+	// Stale frames are dropped
+	if cmd := m.handleVisualizerTick(VisualizerTickMsg{Gen: m.viz.gen - 1, Time: now}); cmd != nil {
+		t.Error("stale frame should not re-arm")
+	}
+
+	// Pausing ends the chain at the next frame, and resuming starts a new one
+	mock.SetState(player.Paused)
+	if cmd := m.handleVisualizerTick(VisualizerTickMsg{Gen: m.viz.gen, Time: now}); cmd != nil {
+		t.Error("frame while paused should end the chain")
+	}
+	if m.viz.running {
+		t.Error("chain should be marked ended")
+	}
+	mock.SetState(player.Playing)
+	if cmd := m.ensureVisualizerTick(); cmd == nil {
+		t.Error("resuming should start a new chain")
+	}
+
+	// Collapsing the player bar ends the chain too
+	m.TogglePlayerDisplayMode()
+	if cmd := m.handleVisualizerTick(VisualizerTickMsg{Gen: m.viz.gen, Time: now}); cmd != nil {
+		t.Error("frame with the compact bar should end the chain")
+	}
+}
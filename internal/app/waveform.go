@@ -0,0 +1,141 @@
+// internal/app/waveform.go
+package app
+
+import (
+	"maps"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/config"
+	"github.com/llehouerou/waves/internal/icy"
+	"github.com/llehouerou/waves/internal/ui/playerbar"
+	"github.com/llehouerou/waves/internal/waveform"
+)
+
+// waveformState holds the waveforms drawn in the seek bar. Only the
+// current and next tracks are kept in memory; they are loaded in the
+// background from the store, which computes them on first use.
+type waveformState struct {
+	enabled bool
+	scan    bool            // Compute waveforms of new files after library scans
+	store   *waveform.Store // nil disables waveforms
+	loaded  map[string]*waveform.Waveform
+	pending map[string]bool // Paths being loaded
+}
+
+func newWaveformState(cfg config.WaveformConfig, store *waveform.Store) waveformState {
+	return waveformState{
+		enabled: *cfg.Enabled,
+		scan:    cfg.Scan,
+		store:   store,
+		loaded:  make(map[string]*waveform.Waveform),
+		pending: make(map[string]bool),
+	}
+}
+
+// currentWaveform returns the waveform of the playing track, nil until it
+// is loaded.
+func (m *Model) currentWaveform() *waveform.Waveform {
+	track := m.PlaybackService.CurrentTrack()
+	if track == nil {
+		return nil
+	}
+	return m.waveforms.loaded[track.Path]
+}
+
+// waveformPaths returns the tracks whose waveforms should be in memory:
+// the current track and the next one. Streams have none.
+func (m *Model) waveformPaths() map[string]bool {
+	paths := make(map[string]bool, 2)
+	if track := m.PlaybackService.CurrentTrack(); track != nil && !icy.IsURL(track.Path) {
+		paths[track.Path] = true
+	}
+	if next := m.PlaybackService.QueuePeekNext(); next != nil && !icy.IsURL(next.Path) {
+		paths[next.Path] = true
+	}
+	return paths
+}
+
+// loadWaveforms drops the waveforms of tracks that are no longer current
+// or next, and loads the missing ones in the background.
+func (m *Model) loadWaveforms() tea.Cmd {
+	if !m.waveforms.enabled || m.waveforms.store == nil {
+		return nil
+	}
+	wanted := m.waveformPaths()
+	maps.DeleteFunc(m.waveforms.loaded, func(path string, _ *waveform.Waveform) bool {
+		return !wanted[path]
+	})
+
+	var cmds []tea.Cmd
+	for path := range wanted {
+		if _, ok := m.waveforms.loaded[path]; ok || m.waveforms.pending[path] {
+			continue
+		}
+		m.waveforms.pending[path] = true
+		cmds = append(cmds, waveform.LoadCmd(m.waveforms.store, path))
+	}
+	return tea.Batch(cmds...)
+}
+
+// handleWaveformMsg routes waveform messages.
+func (m Model) handleWaveformMsg(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case waveform.LoadedMsg:
+		delete(m.waveforms.pending, msg.Path)
+		// Tracks that can't be decoded keep the plain bar
+		if msg.Err == nil && m.waveformPaths()[msg.Path] {
+			m.waveforms.loaded[msg.Path] = msg.Waveform
+		}
+		return m, nil
+
+	case waveform.ProgressMsg:
+		if m.WaveformJob == nil || m.WaveformJob.ID() != msg.JobID {
+			return m, nil
+		}
+		return m, waveform.ContinueCmd(m.WaveformJob)
+
+	case waveform.CompleteMsg:
+		if m.WaveformJob != nil && m.WaveformJob.ID() == msg.JobID {
+			m.WaveformJob = nil
+		}
+		m.ResizeComponents()
+		return m, nil
+	}
+	return m, nil
+}
+
+// startWaveformScan computes the waveforms of scanned files, when enabled
+// in the config. Files are queued on the running job if there is one.
+func (m *Model) startWaveformScan(paths []string) tea.Cmd {
+	if !m.waveforms.enabled || !m.waveforms.scan || m.waveforms.store == nil || len(paths) == 0 {
+		return nil
+	}
+	if m.WaveformJob != nil {
+		m.WaveformJob.Add(paths...)
+		return nil
+	}
+	m.WaveformJob = waveform.NewJob(m.waveforms.store, paths)
+	m.ResizeComponents() // Show job bar
+	return waveform.BatchCmd(m.WaveformJob)
+}
+
+// handleSeekBarClick seeks to the position of the seek bar under the
+// mouse. It returns false if the click is elsewhere on the player bar.
+func (m *Model) handleSeekBarClick(msg tea.MouseMsg) bool {
+	bar, ok := playerbar.LocateSeekBar(m.playerBarState(), m.Layout.Width())
+	if !ok {
+		return false
+	}
+	x, row := msg.X, msg.Y-(m.PlayerBarRow()-1)
+	if !bar.Contains(x, row) {
+		return false
+	}
+	duration := m.PlaybackService.Duration()
+	if duration <= 0 {
+		return false
+	}
+	_ = m.PlaybackService.SeekTo(time.Duration(bar.Ratio(x) * float64(duration)))
+	return true
+}
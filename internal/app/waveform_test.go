@@ -0,0 +1,112 @@
+// internal/app/waveform_test.go
+package app
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/config"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/tags"
+	"github.com/llehouerou/waves/internal/ui/playerbar"
+	"github.com/llehouerou/waves/internal/waveform"
+)
+
+func newWaveformTestModel(t *testing.T) (*Model, *player.Mock) {
+	t.Helper()
+	m := newTestModel()
+	m.Layout.SetSize(120, 40)
+	m.PlaybackService.AddTracks(
+		playback.Track{Path: "/a.mp3", Title: "A"},
+		playback.Track{Path: "/b.mp3", Title: "B"},
+		playback.Track{Path: "/c.mp3", Title: "C"},
+	)
+	_ = m.PlaybackService.QueueMoveTo(0)
+	mock, ok := m.PlaybackService.Player().(*player.Mock)
+	if !ok {
+		t.Fatal("expected mock player")
+	}
+	mock.SetState(player.Playing)
+	enabled := true
+	// The store is never queried: load commands are not run
+	m.waveforms = newWaveformState(config.WaveformConfig{Enabled: &enabled}, waveform.NewStore(nil))
+	return m, mock
+}
+
+func TestWaveforms_LoadCurrentAndNext(t *testing.T) {
+	m, _ := newWaveformTestModel(t)
+
+	if cmd := m.loadWaveforms(); cmd == nil {
+		t.Fatal("expected load commands")
+	}
+	if !m.waveforms.pending["/a.mp3"] || !m.waveforms.pending["/b.mp3"] || len(m.waveforms.pending) != 2 {
+		t.Fatalf("pending = %v, want the current and next tracks", m.waveforms.pending)
+	}
+	if cmd := m.loadWaveforms(); cmd != nil {
+		t.Error("tracks being loaded must not be loaded again")
+	}
+
+	w := &waveform.Waveform{Peak: []float64{1}, RMS: []float64{1}}
+	model, _ := m.handleWaveformMsg(waveform.LoadedMsg{Path: "/a.mp3", Waveform: w})
+	next, ok := model.(Model)
+	if !ok {
+		t.Fatal("expected Model")
+	}
+	if next.currentWaveform() != w {
+		t.Error("loaded waveform should be current")
+	}
+
+	// Moving on drops the waveform of the track left behind
+	_ = next.PlaybackService.QueueMoveTo(1)
+	next.loadWaveforms()
+	if _, ok := next.waveforms.loaded["/a.mp3"]; ok {
+		t.Error("waveform of the previous track should be dropped")
+	}
+	if !next.waveforms.pending["/c.mp3"] {
+		t.Error("waveform of the new next track should load")
+	}
+}
+
+func TestWaveforms_DisabledDoesNotLoad(t *testing.T) {
+	m, _ := newWaveformTestModel(t)
+	m.waveforms.enabled = false
+	if cmd := m.loadWaveforms(); cmd != nil {
+		t.Error("disabled waveforms should not load")
+	}
+	if cmd := m.startWaveformScan([]string{"/a.mp3"}); cmd != nil {
+		t.Error("disabled waveforms should not be computed after scans")
+	}
+}
+
+func TestSeekBarClick_SeeksToColumn(t *testing.T) {
+	m, mock := newWaveformTestModel(t)
+	mock.SetTrackInfo(&tags.FileInfo{Tag: tags.Tag{Path: "/a.mp3", Title: "A"}})
+	mock.SetDuration(4 * time.Minute)
+	mock.SetPosition(time.Minute)
+
+	bar, ok := playerbar.LocateSeekBar(m.playerBarState(), m.Layout.Width())
+	if !ok {
+		t.Fatal("expected a seek bar")
+	}
+	y := m.PlayerBarRow() - 1 + bar.Row
+
+	// Click outside the bar: no seek
+	m.handleMouseMsg(tea.MouseMsg{X: bar.X - 1, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+	if calls := mock.SeekCalls(); len(calls) != 0 {
+		t.Fatalf("click beside the bar seeked: %v", calls)
+	}
+
+	x := bar.X + bar.Width - 1
+	m.handleMouseMsg(tea.MouseMsg{X: x, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+	calls := mock.SeekCalls()
+	if len(calls) != 1 {
+		t.Fatalf("seek calls = %v, want 1", calls)
+	}
+	want := time.Duration(bar.Ratio(x)*float64(4*time.Minute)) - time.Minute
+	if calls[0] != want {
+		t.Errorf("seek delta = %v, want %v", calls[0], want)
+	}
+}
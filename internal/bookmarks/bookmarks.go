@@ -0,0 +1,90 @@
+// Package bookmarks stores named positions within tracks.
+package bookmarks
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Bookmark is a named position within a track.
+type Bookmark struct {
+	ID       int64
+	Path     string
+	Position time.Duration
+	Name     string
+}
+
+// Store keeps bookmarks in the state database, keyed by track path.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore creates a store on the state database.
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// List returns the bookmarks of a track, ordered by position.
+func (s *Store) List(path string) ([]Bookmark, error) {
+	rows, err := s.db.Query(`
+		SELECT id, position_ms, name FROM bookmarks
+		WHERE path = ?
+		ORDER BY position_ms, id
+	`, path)
+	if err != nil {
+		return nil, fmt.Errorf("query bookmarks: %w", err)
+	}
+	defer rows.Close()
+
+	var list []Bookmark
+	for rows.Next() {
+		b := Bookmark{Path: path}
+		var ms int64
+		if err := rows.Scan(&b.ID, &ms, &b.Name); err != nil {
+			return nil, fmt.Errorf("scan bookmark: %w", err)
+		}
+		b.Position = time.Duration(ms) * time.Millisecond
+		list = append(list, b)
+	}
+	return list, rows.Err()
+}
+
+// Add adds a bookmark to a track and returns its ID.
+func (s *Store) Add(path string, pos time.Duration, name string) (int64, error) {
+	res, err := s.db.Exec(`
+		INSERT INTO bookmarks (path, position_ms, name, created_at)
+		VALUES (?, ?, ?, ?)
+	`, path, pos.Milliseconds(), name, time.Now().Unix())
+	if err != nil {
+		return 0, fmt.Errorf("add bookmark: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// Rename renames a bookmark.
+func (s *Store) Rename(id int64, name string) error {
+	if _, err := s.db.Exec(`UPDATE bookmarks SET name = ? WHERE id = ?`, name, id); err != nil {
+		return fmt.Errorf("rename bookmark: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes a bookmark.
+func (s *Store) Delete(id int64) error {
+	if _, err := s.db.Exec(`DELETE FROM bookmarks WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete bookmark: %w", err)
+	}
+	return nil
+}
+
+// FormatPosition formats the position of a bookmark as m:ss or h:mm:ss. It
+// is also the name of bookmarks that weren't given one.
+func FormatPosition(pos time.Duration) string {
+	total := int(pos.Seconds())
+	h, m, sec := total/3600, total/60%60, total%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, sec)
+	}
+	return fmt.Sprintf("%d:%02d", m, sec)
+}
@@ -0,0 +1,64 @@
+package bookmarks
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/llehouerou/waves/internal/state"
+)
+
+// setupTestDB opens a database with the schema of the state package.
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := state.OpenDB(filepath.Join(t.TempDir(), "waves.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestStore_ListOrderedByPosition(t *testing.T) {
+	s := NewStore(setupTestDB(t))
+
+	_, err := s.Add("/a.flac", 90*time.Second, "Solo")
+	require.NoError(t, err)
+	_, err = s.Add("/a.flac", 30*time.Second, "Verse")
+	require.NoError(t, err)
+	_, err = s.Add("/b.flac", 10*time.Second, "Intro")
+	require.NoError(t, err)
+
+	list, err := s.List("/a.flac")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "Verse", list[0].Name)
+	assert.Equal(t, 30*time.Second, list[0].Position)
+	assert.Equal(t, "Solo", list[1].Name)
+	assert.Equal(t, "/a.flac", list[1].Path)
+}
+
+func TestStore_RenameAndDelete(t *testing.T) {
+	s := NewStore(setupTestDB(t))
+	id, err := s.Add("/a.flac", time.Second, "1")
+	require.NoError(t, err)
+
+	require.NoError(t, s.Rename(id, "Chorus"))
+	list, err := s.List("/a.flac")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "Chorus", list[0].Name)
+
+	require.NoError(t, s.Delete(id))
+	list, err = s.List("/a.flac")
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestFormatPosition(t *testing.T) {
+	assert.Equal(t, "0:05", FormatPosition(5*time.Second+400*time.Millisecond))
+	assert.Equal(t, "3:07", FormatPosition(187*time.Second))
+	assert.Equal(t, "1:02:03", FormatPosition(time.Hour+2*time.Minute+3*time.Second))
+}
@@ -4,12 +4,14 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/knadh/koanf/parsers/toml"
 	"github.com/knadh/koanf/providers/file"
 	"github.com/knadh/koanf/v2"
 
 	"github.com/llehouerou/waves/internal/rename"
+	"github.com/llehouerou/waves/internal/resume"
 )
 
 type Config struct {
@@ -37,6 +39,34 @@ type Config struct {
 
 	// Theme customisation
 	Theme ThemeConfig `koanf:"theme"`
+
+	// Loudness normalization
+	ReplayGain ReplayGainConfig `koanf:"replaygain"`
+	Crossfade  CrossfadeConfig  `koanf:"crossfade"`
+
+	// Audio output backend
+	Output OutputConfig `koanf:"output"`
+
+	// Spectrum analyzer and level meters
+	Visualizer VisualizerConfig `koanf:"visualizer"`
+
+	// Waveform seek bar
+	Waveform WaveformConfig `koanf:"waveform"`
+
+	// Remember position in audiobooks and podcasts
+	Resume ResumeConfig `koanf:"resume"`
+
+	// Play counts kept from the play history
+	History HistoryConfig `koanf:"history"`
+
+	// Track and album star ratings
+	Ratings RatingsConfig `koanf:"ratings"`
+
+	// MPD protocol server for MPD clients
+	MPD MPDConfig `koanf:"mpd"`
+
+	// HTTP API for dashboards and home automation
+	HTTP HTTPConfig `koanf:"http"`
 }
 
 // SlskdConfig holds all slskd-related configuration.
@@ -85,6 +115,7 @@ type RadioConfig struct {
 	TopTrackBoost       float64 `koanf:"top_track_boost"`       // Boost multiplier for top tracks (default: 3.0)
 	UserBoost           float64 `koanf:"user_boost"`            // Multiplier for user-scrobbled tracks (default: 1.3)
 	FavoriteBoost       float64 `koanf:"favorite_boost"`        // Multiplier for favorite tracks, replaces user_boost (default: 2.0)
+	RatingBoost         float64 `koanf:"rating_boost"`          // Multiplier for five-star tracks, divisor for one-star tracks (default: 2.0)
 	DecayFactor         float64 `koanf:"decay_factor"`          // Penalty for recently played (default: 0.1)
 	MinSimilarityWeight float64 `koanf:"min_similarity_weight"` // Floor for similarity score (default: 0.1)
 
@@ -129,6 +160,74 @@ type NotificationsConfig struct {
 	Timeout      int32 `koanf:"timeout"`        // ms, 0 = don't expire (default: 5000)
 }
 
+// ReplayGainConfig holds loudness normalization settings.
+type ReplayGainConfig struct {
+	Mode            string  `koanf:"mode"`             // "off", "track", "album", "auto" (default: "off")
+	Preamp          float64 `koanf:"preamp"`           // dB added to the tag gain (default: 0)
+	PreventClipping *bool   `koanf:"prevent_clipping"` // Limit gain using peak tags (default: true)
+}
+
+// CrossfadeConfig holds crossfade settings.
+type CrossfadeConfig struct {
+	Duration float64 `koanf:"duration"` // Seconds of overlap, 0 disables (default: 0, max: 12)
+	Curve    string  `koanf:"curve"`    // "equal-power" or "linear" (default: "equal-power")
+}
+
+// OutputConfig holds audio output settings.
+type OutputConfig struct {
+	Backend         string `koanf:"backend"`          // "speaker", "wav" or "pcm" (default: "speaker")
+	Path            string `koanf:"path"`             // wav: file to write, pcm: file or FIFO, "-" or empty for stdout
+	SampleRate      int    `koanf:"sample_rate"`      // Fixed rate in Hz, 0 follows the source with wav and is 44100 otherwise (default: 0)
+	ResampleQuality int    `koanf:"resample_quality"` // 1 (fastest) to 16 (default: 4)
+}
+
+// VisualizerConfig holds the spectrum analyzer settings of the expanded player bar.
+type VisualizerConfig struct {
+	Enabled bool `koanf:"enabled"` // Shown at startup (default: false)
+	FPS     int  `koanf:"fps"`     // Refresh rate cap, 1-60 (default: 20)
+}
+
+// WaveformConfig holds the waveform seek bar settings.
+type WaveformConfig struct {
+	Enabled *bool `koanf:"enabled"` // Draw the progress bar as a waveform (default: true)
+	Scan    bool  `koanf:"scan"`    // Compute waveforms of new files after library scans (default: false)
+}
+
+// ResumeConfig holds the settings of resuming long tracks where they were left.
+type ResumeConfig struct {
+	Enabled     *bool    `koanf:"enabled"`      // Remember positions (default: true)
+	MinDuration int      `koanf:"min_duration"` // Minutes from which tracks are remembered, -1 disables (default: 20)
+	Genres      []string `koanf:"genres"`       // Genres always remembered (default: ["Audiobook", "Podcast"])
+}
+
+// HistoryConfig holds the settings of counting plays of library tracks.
+// A track counts as played once either threshold is reached, or when it
+// plays to its end.
+type HistoryConfig struct {
+	PlayedPercent int `koanf:"played_percent"` // Percent of the track to play, -1 disables (default: 50)
+	PlayedSeconds int `koanf:"played_seconds"` // Seconds to play, -1 disables (default: 240)
+}
+
+// RatingsConfig holds the settings of rating tracks and albums.
+type RatingsConfig struct {
+	HalfStars *bool `koanf:"half_stars"` // Rate up and down by half stars (default: true)
+	WriteTags *bool `koanf:"write_tags"` // Write ratings to the tags of the files (default: true)
+}
+
+// MPDConfig holds the settings of the MPD protocol server.
+type MPDConfig struct {
+	Enabled  bool   `koanf:"enabled"`  // Serve MPD clients (default: false)
+	Address  string `koanf:"address"`  // host:port to listen on (default: "localhost:6600")
+	Password string `koanf:"password"` // Required from clients if set
+}
+
+// HTTPConfig holds the settings of the HTTP API.
+type HTTPConfig struct {
+	Enabled bool   `koanf:"enabled"` // Serve the API (default: false)
+	Address string `koanf:"address"` // host:port to listen on (default: "localhost:8080")
+	Token   string `koanf:"token"`   // Required from clients; the API is not served without it
+}
+
 // ToRenameConfig converts the config RenameConfig to a rename.Config,
 // applying defaults for nil values.
 func (c RenameConfig) ToRenameConfig() rename.Config {
@@ -288,6 +387,9 @@ func (c *Config) GetRadioConfig() RadioConfig {
 	if cfg.FavoriteBoost <= 0 {
 		cfg.FavoriteBoost = 2.0
 	}
+	if cfg.RatingBoost < 1 {
+		cfg.RatingBoost = 2.0
+	}
 	if cfg.DecayFactor <= 0 || cfg.DecayFactor > 1 {
 		cfg.DecayFactor = 0.1
 	}
@@ -333,3 +435,175 @@ func (c *Config) GetNotificationsConfig() NotificationsConfig {
 
 	return cfg
 }
+
+// GetReplayGainConfig returns the ReplayGain configuration with defaults applied.
+func (c *Config) GetReplayGainConfig() ReplayGainConfig {
+	cfg := c.ReplayGain
+
+	switch strings.ToLower(strings.TrimSpace(cfg.Mode)) {
+	case "track", "album", "auto":
+		cfg.Mode = strings.ToLower(strings.TrimSpace(cfg.Mode))
+	default:
+		cfg.Mode = "off"
+	}
+
+	// Keep preamp within a sane range
+	cfg.Preamp = max(min(cfg.Preamp, 15), -15)
+
+	if cfg.PreventClipping == nil {
+		t := true
+		cfg.PreventClipping = &t
+	}
+
+	return cfg
+}
+
+// GetCrossfadeConfig returns the crossfade configuration with defaults applied.
+func (c *Config) GetCrossfadeConfig() CrossfadeConfig {
+	cfg := c.Crossfade
+
+	cfg.Duration = max(min(cfg.Duration, 12), 0)
+
+	switch strings.ToLower(strings.TrimSpace(cfg.Curve)) {
+	case "linear":
+		cfg.Curve = "linear"
+	default:
+		cfg.Curve = "equal-power"
+	}
+
+	return cfg
+}
+
+// GetOutputConfig returns the audio output configuration with defaults applied.
+func (c *Config) GetOutputConfig() OutputConfig {
+	cfg := c.Output
+
+	switch strings.ToLower(strings.TrimSpace(cfg.Backend)) {
+	case "wav", "pcm":
+		cfg.Backend = strings.ToLower(strings.TrimSpace(cfg.Backend))
+	default:
+		cfg.Backend = "speaker"
+	}
+
+	if cfg.Path != "" && cfg.Path != "-" {
+		cfg.Path = expandPath(cfg.Path)
+	}
+
+	// Keep the rate within what decoders and sound cards handle
+	if cfg.SampleRate != 0 {
+		cfg.SampleRate = max(min(cfg.SampleRate, 384000), 8000)
+	}
+
+	if cfg.ResampleQuality <= 0 {
+		cfg.ResampleQuality = 4
+	}
+	cfg.ResampleQuality = min(cfg.ResampleQuality, 16)
+
+	return cfg
+}
+
+// GetVisualizerConfig returns the visualizer configuration with defaults applied.
+func (c *Config) GetVisualizerConfig() VisualizerConfig {
+	cfg := c.Visualizer
+	if cfg.FPS <= 0 {
+		cfg.FPS = 20
+	}
+	cfg.FPS = min(cfg.FPS, 60)
+	return cfg
+}
+
+// GetWaveformConfig returns the waveform configuration with defaults applied.
+func (c *Config) GetWaveformConfig() WaveformConfig {
+	cfg := c.Waveform
+	if cfg.Enabled == nil {
+		t := true
+		cfg.Enabled = &t
+	}
+	return cfg
+}
+
+// GetResumeConfig returns the resume configuration with defaults applied.
+func (c *Config) GetResumeConfig() ResumeConfig {
+	cfg := c.Resume
+	if cfg.Enabled == nil {
+		t := true
+		cfg.Enabled = &t
+	}
+	switch {
+	case cfg.MinDuration == 0:
+		cfg.MinDuration = 20
+	case cfg.MinDuration < 0:
+		cfg.MinDuration = -1
+	}
+	if cfg.Genres == nil {
+		cfg.Genres = []string{"Audiobook", "Podcast"}
+	}
+	return cfg
+}
+
+// GetHistoryConfig returns the history configuration with defaults applied.
+func (c *Config) GetHistoryConfig() HistoryConfig {
+	cfg := c.History
+	switch {
+	case cfg.PlayedPercent == 0:
+		cfg.PlayedPercent = 50
+	case cfg.PlayedPercent < 0:
+		cfg.PlayedPercent = -1
+	case cfg.PlayedPercent > 100:
+		cfg.PlayedPercent = 100
+	}
+	switch {
+	case cfg.PlayedSeconds == 0:
+		cfg.PlayedSeconds = 240
+	case cfg.PlayedSeconds < 0:
+		cfg.PlayedSeconds = -1
+	}
+	return cfg
+}
+
+// GetRatingsConfig returns the ratings configuration with defaults applied.
+func (c *Config) GetRatingsConfig() RatingsConfig {
+	cfg := c.Ratings
+	if cfg.HalfStars == nil {
+		t := true
+		cfg.HalfStars = &t
+	}
+	if cfg.WriteTags == nil {
+		t := true
+		cfg.WriteTags = &t
+	}
+	return cfg
+}
+
+// GetMPDConfig returns the MPD server configuration with defaults applied.
+func (c *Config) GetMPDConfig() MPDConfig {
+	cfg := c.MPD
+	if cfg.Address == "" {
+		cfg.Address = "localhost:6600"
+	}
+	return cfg
+}
+
+// GetHTTPConfig returns the HTTP API configuration with defaults applied.
+func (c *Config) GetHTTPConfig() HTTPConfig {
+	cfg := c.HTTP
+	if cfg.Address == "" {
+		cfg.Address = "localhost:8080"
+	}
+	return cfg
+}
+
+// ToPolicy converts the config to the policy of resume.Store, applying
+// defaults for unset values.
+func (c ResumeConfig) ToPolicy() resume.Policy {
+	policy := resume.Policy{Genres: c.Genres}
+	if c.MinDuration > 0 {
+		policy.MinDuration = time.Duration(c.MinDuration) * time.Minute
+	}
+	return policy
+}
+
+// WritesToStdout returns true if the audio output is sent to stdout.
+func (c OutputConfig) WritesToStdout() bool {
+	return c.Backend == "pcm" && (c.Path == "" || c.Path == "-")
+}
@@ -4,7 +4,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
+	"time"
 )
 
 func TestExpandPath(t *testing.T) {
@@ -245,6 +247,9 @@ func TestGetRadioConfig_Defaults(t *testing.T) {
 	if radio.FavoriteBoost != 2.0 {
 		t.Errorf("FavoriteBoost = %f, want 2.0", radio.FavoriteBoost)
 	}
+	if radio.RatingBoost != 2.0 {
+		t.Errorf("RatingBoost = %f, want 2.0", radio.RatingBoost)
+	}
 	if radio.DecayFactor != 0.1 {
 		t.Errorf("DecayFactor = %f, want 0.1", radio.DecayFactor)
 	}
@@ -272,6 +277,7 @@ func TestGetRadioConfig_CustomValues(t *testing.T) {
 			TopTrackBoost:        4.0,
 			UserBoost:            1.5,
 			FavoriteBoost:        2.5,
+			RatingBoost:          3.0,
 			DecayFactor:          0.2,
 			MinSimilarityWeight:  0.2,
 			CacheTTLDays:         14,
@@ -313,6 +319,9 @@ func TestGetRadioConfig_CustomValues(t *testing.T) {
 	if radio.FavoriteBoost != 2.5 {
 		t.Errorf("FavoriteBoost = %f, want 2.5", radio.FavoriteBoost)
 	}
+	if radio.RatingBoost != 3.0 {
+		t.Errorf("RatingBoost = %f, want 3.0", radio.RatingBoost)
+	}
 	if radio.DecayFactor != 0.2 {
 		t.Errorf("DecayFactor = %f, want 0.2", radio.DecayFactor)
 	}
@@ -1003,3 +1012,276 @@ func TestLoad_ThemeConfigEmpty(t *testing.T) {
 		t.Errorf("Theme.Accent = %v, want nil", cfg.Theme.Accent)
 	}
 }
+
+func TestGetReplayGainConfig(t *testing.T) {
+	f := false
+	tests := []struct {
+		name         string
+		cfg          ReplayGainConfig
+		wantMode     string
+		wantPreamp   float64
+		wantClipping bool
+	}{
+		{"defaults", ReplayGainConfig{}, "off", 0, true},
+		{"album", ReplayGainConfig{Mode: "Album", Preamp: 3}, "album", 3, true},
+		{"unknown mode", ReplayGainConfig{Mode: "loud"}, "off", 0, true},
+		{"preamp clamped", ReplayGainConfig{Mode: "auto", Preamp: 40}, "auto", 15, true},
+		{"clipping disabled", ReplayGainConfig{Mode: "track", PreventClipping: &f}, "track", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{ReplayGain: tt.cfg}
+			got := c.GetReplayGainConfig()
+			if got.Mode != tt.wantMode {
+				t.Errorf("Mode = %q, want %q", got.Mode, tt.wantMode)
+			}
+			if got.Preamp != tt.wantPreamp {
+				t.Errorf("Preamp = %v, want %v", got.Preamp, tt.wantPreamp)
+			}
+			if *got.PreventClipping != tt.wantClipping {
+				t.Errorf("PreventClipping = %v, want %v", *got.PreventClipping, tt.wantClipping)
+			}
+		})
+	}
+}
+
+func TestGetCrossfadeConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          CrossfadeConfig
+		wantDuration float64
+		wantCurve    string
+	}{
+		{"defaults", CrossfadeConfig{}, 0, "equal-power"},
+		{"linear", CrossfadeConfig{Duration: 4, Curve: "Linear"}, 4, "linear"},
+		{"unknown curve", CrossfadeConfig{Duration: 2, Curve: "s-curve"}, 2, "equal-power"},
+		{"duration clamped", CrossfadeConfig{Duration: 60}, 12, "equal-power"},
+		{"negative duration", CrossfadeConfig{Duration: -1}, 0, "equal-power"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Crossfade: tt.cfg}
+			got := c.GetCrossfadeConfig()
+			if got.Duration != tt.wantDuration {
+				t.Errorf("Duration = %v, want %v", got.Duration, tt.wantDuration)
+			}
+			if got.Curve != tt.wantCurve {
+				t.Errorf("Curve = %q, want %q", got.Curve, tt.wantCurve)
+			}
+		})
+	}
+}
+
+func TestGetOutputConfig(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	tests := []struct {
+		name        string
+		cfg         OutputConfig
+		wantBackend string
+		wantPath    string
+		wantRate    int
+		wantQuality int
+	}{
+		{"defaults", OutputConfig{}, "speaker", "", 0, 4},
+		{"wav", OutputConfig{Backend: " WAV ", Path: "/tmp/out.wav"}, "wav", "/tmp/out.wav", 0, 4},
+		{"pcm stdout", OutputConfig{Backend: "pcm", Path: "-"}, "pcm", "-", 0, 4},
+		{"home expanded", OutputConfig{Backend: "pcm", Path: "~/waves.fifo"}, "pcm", filepath.Join(home, "waves.fifo"), 0, 4},
+		{"unknown backend", OutputConfig{Backend: "pulse"}, "speaker", "", 0, 4},
+		{"fixed rate", OutputConfig{SampleRate: 48000, ResampleQuality: 6}, "speaker", "", 48000, 6},
+		{"limits", OutputConfig{SampleRate: 1000, ResampleQuality: 100}, "speaker", "", 8000, 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Output: tt.cfg}
+			got := c.GetOutputConfig()
+			if got.Backend != tt.wantBackend {
+				t.Errorf("Backend = %q, want %q", got.Backend, tt.wantBackend)
+			}
+			if got.Path != tt.wantPath {
+				t.Errorf("Path = %q, want %q", got.Path, tt.wantPath)
+			}
+			if got.SampleRate != tt.wantRate {
+				t.Errorf("SampleRate = %d, want %d", got.SampleRate, tt.wantRate)
+			}
+			if got.ResampleQuality != tt.wantQuality {
+				t.Errorf("ResampleQuality = %d, want %d", got.ResampleQuality, tt.wantQuality)
+			}
+		})
+	}
+}
+
+func TestGetVisualizerConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     VisualizerConfig
+		wantFPS int
+	}{
+		{"default", VisualizerConfig{}, 20},
+		{"custom", VisualizerConfig{Enabled: true, FPS: 30}, 30},
+		{"capped", VisualizerConfig{FPS: 240}, 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Visualizer: tt.cfg}
+			got := c.GetVisualizerConfig()
+			if got.FPS != tt.wantFPS {
+				t.Errorf("FPS = %d, want %d", got.FPS, tt.wantFPS)
+			}
+			if got.Enabled != tt.cfg.Enabled {
+				t.Errorf("Enabled = %v, want %v", got.Enabled, tt.cfg.Enabled)
+			}
+		})
+	}
+}
+
+func TestGetWaveformConfig(t *testing.T) {
+	f := false
+	tests := []struct {
+		name        string
+		cfg         WaveformConfig
+		wantEnabled bool
+	}{
+		{"default", WaveformConfig{}, true},
+		{"disabled", WaveformConfig{Enabled: &f, Scan: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Waveform: tt.cfg}
+			got := c.GetWaveformConfig()
+			if *got.Enabled != tt.wantEnabled {
+				t.Errorf("Enabled = %v, want %v", *got.Enabled, tt.wantEnabled)
+			}
+			if got.Scan != tt.cfg.Scan {
+				t.Errorf("Scan = %v, want %v", got.Scan, tt.cfg.Scan)
+			}
+		})
+	}
+}
+
+func TestGetResumeConfig(t *testing.T) {
+	f := false
+	tests := []struct {
+		name            string
+		cfg             ResumeConfig
+		wantEnabled     bool
+		wantMinDuration int
+		wantGenres      []string
+	}{
+		{"default", ResumeConfig{}, true, 20, []string{"Audiobook", "Podcast"}},
+		{"custom", ResumeConfig{Enabled: &f, MinDuration: 45, Genres: []string{"Lecture"}}, false, 45, []string{"Lecture"}},
+		{"duration disabled", ResumeConfig{MinDuration: -30, Genres: []string{}}, true, -1, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Resume: tt.cfg}
+			got := c.GetResumeConfig()
+			if *got.Enabled != tt.wantEnabled {
+				t.Errorf("Enabled = %v, want %v", *got.Enabled, tt.wantEnabled)
+			}
+			if got.MinDuration != tt.wantMinDuration {
+				t.Errorf("MinDuration = %d, want %d", got.MinDuration, tt.wantMinDuration)
+			}
+			if !slices.Equal(got.Genres, tt.wantGenres) {
+				t.Errorf("Genres = %v, want %v", got.Genres, tt.wantGenres)
+			}
+		})
+	}
+}
+
+func TestResumeConfig_ToPolicy(t *testing.T) {
+	c := &Config{}
+	policy := c.GetResumeConfig().ToPolicy()
+	if policy.MinDuration != 20*time.Minute {
+		t.Errorf("MinDuration = %v, want 20m", policy.MinDuration)
+	}
+	if !slices.Equal(policy.Genres, []string{"Audiobook", "Podcast"}) {
+		t.Errorf("Genres = %v, want defaults", policy.Genres)
+	}
+
+	c.Resume.MinDuration = -1
+	if policy := c.GetResumeConfig().ToPolicy(); policy.MinDuration != 0 {
+		t.Errorf("MinDuration = %v, want 0 when disabled", policy.MinDuration)
+	}
+}
+
+func TestGetHistoryConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         HistoryConfig
+		wantPercent int
+		wantSeconds int
+	}{
+		{"default", HistoryConfig{}, 50, 240},
+		{"custom", HistoryConfig{PlayedPercent: 80, PlayedSeconds: 60}, 80, 60},
+		{"disabled", HistoryConfig{PlayedPercent: -5, PlayedSeconds: -30}, -1, -1},
+		{"percent capped", HistoryConfig{PlayedPercent: 150}, 100, 240},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{History: tt.cfg}
+			got := c.GetHistoryConfig()
+			if got.PlayedPercent != tt.wantPercent {
+				t.Errorf("PlayedPercent = %d, want %d", got.PlayedPercent, tt.wantPercent)
+			}
+			if got.PlayedSeconds != tt.wantSeconds {
+				t.Errorf("PlayedSeconds = %d, want %d", got.PlayedSeconds, tt.wantSeconds)
+			}
+		})
+	}
+}
+
+func TestGetRatingsConfig(t *testing.T) {
+	c := &Config{}
+	got := c.GetRatingsConfig()
+	if got.HalfStars == nil || !*got.HalfStars {
+		t.Error("HalfStars should default to true")
+	}
+	if got.WriteTags == nil || !*got.WriteTags {
+		t.Error("WriteTags should default to true")
+	}
+
+	f := false
+	c = &Config{Ratings: RatingsConfig{WriteTags: &f}}
+	if got := c.GetRatingsConfig(); *got.WriteTags {
+		t.Error("WriteTags = true, want the configured false")
+	}
+}
+
+func TestGetMPDConfig(t *testing.T) {
+	c := &Config{}
+	got := c.GetMPDConfig()
+	if got.Enabled {
+		t.Error("Enabled should default to false")
+	}
+	if got.Address != "localhost:6600" {
+		t.Errorf("Address = %q, want localhost:6600", got.Address)
+	}
+
+	c = &Config{MPD: MPDConfig{Enabled: true, Address: ":6601", Password: "secret"}}
+	if got := c.GetMPDConfig(); got != c.MPD {
+		t.Errorf("GetMPDConfig() = %+v, want the configured %+v", got, c.MPD)
+	}
+}
+
+func TestGetHTTPConfig(t *testing.T) {
+	c := &Config{}
+	got := c.GetHTTPConfig()
+	if got.Enabled {
+		t.Error("Enabled should default to false")
+	}
+	if got.Address != "localhost:8080" {
+		t.Errorf("Address = %q, want localhost:8080", got.Address)
+	}
+
+	c = &Config{HTTP: HTTPConfig{Enabled: true, Address: ":8081", Token: "secret"}}
+	if got := c.GetHTTPConfig(); got != c.HTTP {
+		t.Errorf("GetHTTPConfig() = %+v, want the configured %+v", got, c.HTTP)
+	}
+}
@@ -0,0 +1,79 @@
+package ctl
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/adrg/xdg"
+)
+
+// SocketPath returns the path of the control socket: $WAVES_SOCKET, or
+// waves/waves.sock in the runtime directory of the user.
+func SocketPath() (string, error) {
+	if path := os.Getenv("WAVES_SOCKET"); path != "" {
+		return path, nil
+	}
+	return xdg.RuntimeFile(filepath.Join("waves", "waves.sock"))
+}
+
+// Client is a connection to the control socket.
+type Client struct {
+	conn net.Conn
+	enc  *json.Encoder
+	dec  *json.Decoder
+}
+
+// Dial connects to the control socket at path.
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		conn: conn,
+		enc:  json.NewEncoder(conn),
+		dec:  json.NewDecoder(bufio.NewReader(conn)),
+	}, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Call sends a request and returns its response. A response reporting an
+// error is returned along with it.
+func (c *Client) Call(req Request) (Response, error) {
+	if err := c.enc.Encode(req); err != nil {
+		return Response{}, err
+	}
+	var resp Response
+	if err := c.dec.Decode(&resp); err != nil {
+		return Response{}, err
+	}
+	if !resp.OK {
+		return resp, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+// Follow streams the playback events to fn until the connection closes or
+// fn returns an error. The connection can't be used for calls afterwards.
+func (c *Client) Follow(fn func(Event) error) error {
+	if _, err := c.Call(Request{Command: CmdFollow}); err != nil {
+		return err
+	}
+	for {
+		var e Event
+		if err := c.dec.Decode(&e); err != nil {
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,465 @@
+package ctl
+
+import (
+	"database/sql"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/llehouerou/waves/internal/library"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/playlist"
+)
+
+// fakeLibrary holds tracks by path.
+type fakeLibrary map[string]library.Track
+
+func (f fakeLibrary) TrackByPath(path string) (*library.Track, error) {
+	t, ok := f[path]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &t, nil
+}
+
+// fakeVolumes records the saved volume.
+type fakeVolumes struct {
+	volume float64
+	saves  int
+}
+
+func (f *fakeVolumes) SaveVolume(volume float64, _ bool) error {
+	f.volume = volume
+	f.saves++
+	return nil
+}
+
+type testServer struct {
+	server  *Server
+	svc     playback.Service
+	player  *player.Mock
+	volumes *fakeVolumes
+	music   string // Folder with album/01.mp3, album/02.mp3 and single.flac
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	dir := t.TempDir()
+	music := filepath.Join(dir, "music")
+	for _, name := range []string{"album/02.mp3", "album/01.mp3", "album/cover.jpg", "single.flac"} {
+		path := filepath.Join(music, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lib := fakeLibrary{
+		filepath.Join(music, "single.flac"): {ID: 7, Path: filepath.Join(music, "single.flac"), Artist: "Blur", Title: "Song 2"},
+	}
+
+	p := player.NewMock()
+	svc := playback.New(p, playlist.NewQueue())
+	volumes := &fakeVolumes{}
+	server, err := Listen(filepath.Join(dir, "run", "waves.sock"), svc, lib, volumes)
+	if err != nil {
+		t.Fatalf("Listen() error: %v", err)
+	}
+	t.Cleanup(func() {
+		server.Close()
+		svc.Close()
+	})
+	return &testServer{server: server, svc: svc, player: p, volumes: volumes, music: music}
+}
+
+func (ts *testServer) dial(t *testing.T) *Client {
+	t.Helper()
+	c, err := Dial(ts.server.Path())
+	if err != nil {
+		t.Fatalf("Dial() error: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func call(t *testing.T, c *Client, req Request) Response {
+	t.Helper()
+	resp, err := c.Call(req)
+	if err != nil {
+		t.Fatalf("Call(%+v) error: %v", req, err)
+	}
+	return resp
+}
+
+func TestServer_QueueAndPlayback(t *testing.T) {
+	ts := newTestServer(t)
+	c := ts.dial(t)
+
+	resp := call(t, c, Request{Command: CmdAdd, Paths: []string{filepath.Join(ts.music, "album")}})
+	if resp.Added != 2 {
+		t.Errorf("Added = %d, want 2", resp.Added)
+	}
+
+	st := call(t, c, Request{Command: CmdPlay}).Status
+	if st.State != "playing" || st.Index != 0 || st.Track == nil || filepath.Base(st.Track.Path) != "01.mp3" {
+		t.Fatalf("status after play = %+v, want playing 01.mp3", st)
+	}
+
+	// Inserted after the playing track
+	call(t, c, Request{Command: CmdInsert, Paths: []string{filepath.Join(ts.music, "single.flac")}})
+	queue := call(t, c, Request{Command: CmdQueue}).Queue
+	want := []string{"01.mp3", "single.flac", "02.mp3"}
+	if len(queue.Tracks) != len(want) || queue.Index != 0 {
+		t.Fatalf("queue = %+v, want %v playing the first", queue, want)
+	}
+	for i, name := range want {
+		if filepath.Base(queue.Tracks[i].Path) != name {
+			t.Errorf("track %d = %s, want %s", i, queue.Tracks[i].Path, name)
+		}
+	}
+	if queue.Tracks[1].ID != 7 || queue.Tracks[1].Title != "Song 2" {
+		t.Errorf("library track = %+v, want its library metadata", queue.Tracks[1])
+	}
+
+	st = call(t, c, Request{Command: CmdNext}).Status
+	if st.Index != 1 || st.Track.Title != "Song 2" {
+		t.Errorf("status after next = %+v, want Song 2", st)
+	}
+	st = call(t, c, Request{Command: CmdPause}).Status
+	if st.State != "paused" {
+		t.Errorf("state after pause = %s, want paused", st.State)
+	}
+	st = call(t, c, Request{Command: CmdPlay}).Status
+	if st.State != "playing" {
+		t.Errorf("state after play = %s, want playing", st.State)
+	}
+	if plays := ts.player.PlayCalls(); len(plays) != 2 {
+		t.Errorf("player played %v, resuming should not restart the track", plays)
+	}
+	st = call(t, c, Request{Command: CmdPrev}).Status
+	if st.Index != 0 {
+		t.Errorf("index after prev = %d, want 0", st.Index)
+	}
+}
+
+func TestServer_SeekAndVolume(t *testing.T) {
+	ts := newTestServer(t)
+	c := ts.dial(t)
+	ts.player.SetDuration(5 * time.Minute)
+	call(t, c, Request{Command: CmdAdd, Paths: []string{filepath.Join(ts.music, "single.flac")}})
+	call(t, c, Request{Command: CmdPlay})
+
+	call(t, c, Request{Command: CmdSeek, Value: 90})
+	call(t, c, Request{Command: CmdSeek, Value: -30, Relative: true})
+	seeks := ts.player.SeekCalls()
+	if len(seeks) != 2 || seeks[1] != -30*time.Second {
+		t.Errorf("seeks = %v, want a seek of -30s last", seeks)
+	}
+
+	ts.player.SetMuted(true)
+	st := call(t, c, Request{Command: CmdVolume, Value: 40}).Status
+	if st.Volume != 40 || st.Muted {
+		t.Errorf("volume = %d muted %v, want 40 unmuted", st.Volume, st.Muted)
+	}
+	st = call(t, c, Request{Command: CmdVolume, Value: 75, Relative: true}).Status
+	if st.Volume != 100 {
+		t.Errorf("volume = %d, want 100 (clamped)", st.Volume)
+	}
+	if ts.volumes.saves != 2 || ts.volumes.volume != 1 {
+		t.Errorf("saved volume %v %d times, want 1 twice", ts.volumes.volume, ts.volumes.saves)
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	ts := newTestServer(t)
+	c := ts.dial(t)
+
+	tests := []Request{
+		{Command: "dance"},
+		{Command: CmdPlay}, // Empty queue
+		{Command: CmdAdd},
+		{Command: CmdAdd, Paths: []string{"album"}},
+		{Command: CmdAdd, Paths: []string{filepath.Join(ts.music, "album", "cover.jpg")}},
+		{Command: CmdAdd, Paths: []string{filepath.Join(ts.music, "missing.mp3")}},
+	}
+	for _, req := range tests {
+		resp, err := c.Call(req)
+		if err == nil || resp.OK || resp.Error == "" {
+			t.Errorf("Call(%+v) = %+v, %v, want an error", req, resp, err)
+		}
+	}
+	// The connection is still usable
+	if st := call(t, c, Request{Command: CmdStatus}).Status; st.QueueLength != 0 {
+		t.Errorf("queue length = %d, want 0", st.QueueLength)
+	}
+}
+
+func paths(tracks []Track) []string {
+	names := make([]string, len(tracks))
+	for i, t := range tracks {
+		names[i] = t.Path
+	}
+	return names
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestServer_QueueEdits(t *testing.T) {
+	ts := newTestServer(t)
+	c := ts.dial(t)
+	call(t, c, Request{Command: CmdReplace, Tracks: []Track{{Path: "/a.mp3"}, {Path: "/b.mp3"}}})
+	index := 1
+	call(t, c, Request{Command: CmdInsert, Index: &index, Tracks: []Track{{Path: "/c.mp3", Title: "C"}}})
+
+	queue := call(t, c, Request{Command: CmdQueue}).Queue
+	if want := []string{"/a.mp3", "/c.mp3", "/b.mp3"}; !equal(paths(queue.Tracks), want) {
+		t.Fatalf("queue = %v, want %v", paths(queue.Tracks), want)
+	}
+	if queue.Tracks[1].Title != "C" {
+		t.Errorf("inserted track = %+v, want it as sent", queue.Tracks[1])
+	}
+
+	if !call(t, c, Request{Command: CmdMove, Indices: []int{2}, Value: -2}).Changed {
+		t.Error("move didn't change the queue")
+	}
+	if call(t, c, Request{Command: CmdMove, Indices: []int{0}, Value: -1}).Changed {
+		t.Error("move out of bounds changed the queue")
+	}
+	if !call(t, c, Request{Command: CmdUndo}).Changed {
+		t.Error("undo didn't change the queue")
+	}
+
+	st := call(t, c, Request{Command: CmdRepeat, Mode: "one"}).Status
+	if st.Repeat != "one" {
+		t.Errorf("repeat = %s, want one", st.Repeat)
+	}
+	if st = call(t, c, Request{Command: CmdShuffle}).Status; !st.Shuffle {
+		t.Error("shuffle not toggled on")
+	}
+	if st = call(t, c, Request{Command: CmdMute}).Status; !st.Muted {
+		t.Error("mute not toggled on")
+	}
+
+	call(t, c, Request{Command: CmdClear})
+	if st = call(t, c, Request{Command: CmdStatus}).Status; st.QueueLength != 0 {
+		t.Errorf("queue length after clear = %d, want 0", st.QueueLength)
+	}
+}
+
+func TestServer_Reorder(t *testing.T) {
+	ts := newTestServer(t)
+	c := ts.dial(t)
+	call(t, c, Request{Command: CmdAdd, Tracks: []Track{
+		{Path: "/a.mp3"}, {Path: "/b.mp3"}, {Path: "/a.mp3"}, {Path: "/c.mp3"}, {Path: "/d.mp3"},
+	}})
+
+	call(t, c, Request{Command: CmdReorder, Paths: []string{"/d.mp3", "/a.mp3", "/x.mp3", "/b.mp3", "/a.mp3"}})
+	queue := call(t, c, Request{Command: CmdQueue}).Queue
+	if want := []string{"/d.mp3", "/a.mp3", "/b.mp3", "/a.mp3"}; !equal(paths(queue.Tracks), want) {
+		t.Errorf("queue = %v, want %v", paths(queue.Tracks), want)
+	}
+}
+
+func TestServer_SnapshotAndShutdown(t *testing.T) {
+	ts := newTestServer(t)
+	c := ts.dial(t)
+	ts.player.SetDuration(5 * time.Minute)
+	call(t, c, Request{Command: CmdAdd, Tracks: []Track{{Path: "/a.mp3"}}})
+	call(t, c, Request{Command: CmdPlay})
+	call(t, c, Request{Command: CmdSleep, Mode: "after", Value: 600})
+
+	snap := call(t, c, Request{Command: CmdSnapshot}).Snapshot
+	if snap.State != "playing" || snap.Queue == nil || len(snap.Queue.Tracks) != 1 || !snap.Seekable {
+		t.Errorf("snapshot = %+v, want /a.mp3 playing", snap)
+	}
+	if snap.Sleep.Mode != "after" || snap.Sleep.After != 600 {
+		t.Errorf("sleep = %+v, want after 600s", snap.Sleep)
+	}
+	if snap = call(t, c, Request{Command: CmdSnapshot, NoQueue: true}).Snapshot; snap.Queue != nil {
+		t.Error("snapshot without the queue has one")
+	}
+	if snap.Daemon {
+		t.Error("snapshot reports a daemon")
+	}
+	if _, err := c.Call(Request{Command: CmdShutdown}); err == nil {
+		t.Error("shutdown without a daemon succeeded")
+	}
+
+	stopped := make(chan struct{})
+	ts.server.OnShutdown(func() { close(stopped) })
+	if !call(t, c, Request{Command: CmdSnapshot}).Snapshot.Daemon {
+		t.Error("snapshot doesn't report the daemon")
+	}
+	call(t, c, Request{Command: CmdShutdown})
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown not called")
+	}
+}
+
+func TestServer_Follow(t *testing.T) {
+	ts := newTestServer(t)
+	follower := ts.dial(t)
+	events := make(chan Event, 16)
+	go func() {
+		_ = follower.Follow(func(e Event) error {
+			events <- e
+			return nil
+		})
+		close(events)
+	}()
+
+	c := ts.dial(t)
+	// Wait for the follower to subscribe
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		call(t, c, Request{Command: CmdAdd, Paths: []string{filepath.Join(ts.music, "single.flac")}})
+		select {
+		case e := <-events:
+			if e.Type != EventQueue || e.Queue == nil || len(e.Queue.Tracks) == 0 {
+				t.Fatalf("event = %+v, want a queue event", e)
+			}
+		case <-time.After(100 * time.Millisecond):
+			if time.Now().After(deadline) {
+				t.Fatal("no event received")
+			}
+			continue
+		}
+		break
+	}
+
+	call(t, c, Request{Command: CmdPlay})
+	e := waitEvent(t, events, EventState)
+	if e.State != "playing" {
+		t.Errorf("state event = %+v, want playing", e)
+	}
+
+	// Followers move to a recreated service
+	svc := playback.New(ts.player, playlist.NewQueue())
+	defer svc.Close()
+	ts.svc.Close()
+	ts.server.SetService(svc)
+	call(t, c, Request{Command: CmdAdd, Paths: []string{filepath.Join(ts.music, "album")}})
+	e = waitEvent(t, events, EventQueue)
+	if len(e.Queue.Tracks) != 2 {
+		t.Errorf("queue event = %+v, want the 2 tracks of the new queue", e)
+	}
+
+	ts.server.Close()
+	for range events {
+	}
+}
+
+func waitEvent(t *testing.T, events <-chan Event, typ string) Event {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				t.Fatalf("events ended before a %s event", typ)
+			}
+			if e.Type == typ {
+				return e
+			}
+		case <-timeout:
+			t.Fatalf("no %s event received", typ)
+		}
+	}
+}
+
+func TestListen_Socket(t *testing.T) {
+	ts := newTestServer(t)
+	path := ts.server.Path()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("socket permissions = %o, want 600", perm)
+	}
+	if info, err := os.Stat(filepath.Dir(path)); err != nil || info.Mode().Perm() != 0o700 {
+		t.Errorf("socket folder = %v, %v, want permissions 700", info, err)
+	}
+
+	if _, err := Listen(path, ts.svc, nil, nil); !errors.Is(err, ErrRunning) {
+		t.Errorf("second Listen() error = %v, want ErrRunning", err)
+	}
+
+	if err := ts.server.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("socket should be removed on Close(), Stat() error = %v", err)
+	}
+}
+
+func TestListen_RefusesSharedFolder(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "run")
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	// Set after creating, the umask doesn't apply
+	if err := os.Chmod(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := playback.New(player.NewMock(), playlist.NewQueue())
+	defer svc.Close()
+	path := filepath.Join(dir, "waves.sock")
+	if _, err := Listen(path, svc, nil, nil); !errors.Is(err, ErrNotPrivate) {
+		t.Fatalf("Listen() error = %v, want ErrNotPrivate", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("socket created in a shared folder, Stat() error = %v", err)
+	}
+}
+
+func TestListen_ReplacesStaleSocket(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "run")
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "waves.sock")
+	// A socket file nobody listens on, as left by a crash
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	ln.Close()
+
+	svc := playback.New(player.NewMock(), playlist.NewQueue())
+	defer svc.Close()
+	server, err := Listen(path, svc, nil, nil)
+	if err != nil {
+		t.Fatalf("Listen() error: %v", err)
+	}
+	defer server.Close()
+
+	c, err := Dial(path)
+	if err != nil {
+		t.Fatalf("Dial() error: %v", err)
+	}
+	defer c.Close()
+	if _, err := c.Call(Request{Command: CmdStatus}); err != nil {
+		t.Errorf("Call() error: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package ctl
+
+import "github.com/llehouerou/waves/internal/downloads"
+
+// manageDownloads runs a download command.
+func (s *Server) manageDownloads(req Request) Response {
+	s.mu.Lock()
+	c := s.downloads
+	s.mu.Unlock()
+	if c == nil {
+		return Response{Error: "downloads are not managed by this waves"}
+	}
+
+	var resp Response
+	var err error
+	switch req.Command {
+	case CmdDownloadCreate:
+		if req.Download == nil {
+			return Response{Error: "no download given"}
+		}
+		resp.ID, err = c.Create(*req.Download)
+	case CmdDownloadDelete:
+		err = c.Delete(req.ID)
+	case CmdDownloadClear:
+		err = c.ClearCompleted()
+	case CmdDownloadSync:
+		err = c.Sync()
+	}
+	if err != nil {
+		return Response{Error: err.Error()}
+	}
+	resp.OK = true
+	return resp
+}
+
+// remoteDownloads manages the downloads in the daemon of a Remote.
+type remoteDownloads struct {
+	r *Remote
+}
+
+// Verify remoteDownloads implements downloads.Control at compile time.
+var _ downloads.Control = remoteDownloads{}
+
+// Downloads returns a control managing the downloads in the daemon, which
+// follows them while no interface is attached.
+func (r *Remote) Downloads() downloads.Control {
+	return remoteDownloads{r: r}
+}
+
+func (d remoteDownloads) Create(download downloads.Download) (int64, error) {
+	resp, err := d.r.do(Request{Command: CmdDownloadCreate, Download: &download})
+	return resp.ID, err
+}
+
+func (d remoteDownloads) Delete(id int64) error {
+	_, err := d.r.do(Request{Command: CmdDownloadDelete, ID: id})
+	return err
+}
+
+func (d remoteDownloads) ClearCompleted() error {
+	_, err := d.r.do(Request{Command: CmdDownloadClear})
+	return err
+}
+
+func (d remoteDownloads) Sync() error {
+	_, err := d.r.do(Request{Command: CmdDownloadSync})
+	return err
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package ctl
+
+import "os"
+
+// private returns true: folders have no Unix permissions to check.
+func private(os.FileInfo) bool {
+	return true
+}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package ctl
+
+import (
+	"os"
+	"syscall"
+)
+
+// private returns true if only the user running waves can enter a folder.
+func private(info os.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(st.Uid) == os.Getuid() && info.Mode().Perm() == 0o700
+}
@@ -0,0 +1,322 @@
+// Package ctl implements the control socket of a running waves, a Unix
+// socket speaking a small JSON protocol, and the client used by "waves ctl".
+//
+// Each request is a JSON object on its own line, answered by a Response
+// line. After a follow request is answered, the server writes an Event line
+// for each playback event until the client disconnects.
+//
+// Besides the commands of "waves ctl", the protocol covers the whole
+// playback service, so that an interface can attach to a daemon: see
+// Remote.
+package ctl
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/llehouerou/waves/internal/downloads"
+	"github.com/llehouerou/waves/internal/equalizer"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/tags"
+)
+
+// Commands understood by the server.
+const (
+	CmdPlay   = "play"
+	CmdPause  = "pause"
+	CmdToggle = "toggle"
+	CmdStop   = "stop"
+	CmdNext   = "next"
+	CmdPrev   = "prev"
+	CmdSeek   = "seek"
+	CmdVolume = "volume"
+	CmdAdd    = "add"
+	CmdInsert = "insert"
+	CmdQueue  = "queue"
+	CmdStatus = "status"
+	CmdFollow = "follow"
+
+	// Used by attached interfaces
+	CmdStart    = "start"
+	CmdPlayPath = "play_path"
+	CmdJump     = "jump"
+	CmdSelect   = "select"
+	CmdAdvance  = "advance"
+	CmdReplace  = "replace"
+	CmdRemove   = "remove"
+	CmdMove     = "move"
+	CmdClear    = "clear"
+	CmdReorder  = "reorder"
+	CmdUndo     = "undo"
+	CmdRedo     = "redo"
+	CmdRepeat   = "repeat"
+	CmdShuffle  = "shuffle"
+	CmdSpeed    = "speed"
+	CmdSleep    = "sleep"
+	CmdLoop     = "loop"
+	CmdChapter  = "chapter"
+	CmdMute     = "mute"
+	CmdSnapshot = "snapshot"
+	CmdShutdown = "shutdown"
+
+	// Player settings, used by attached interfaces
+	CmdEqualizer  = "equalizer"
+	CmdCrossfade  = "crossfade"
+	CmdReplayGain = "replay_gain"
+
+	// Downloads, managed by the daemon for attached interfaces
+	CmdDownloadCreate = "download_create"
+	CmdDownloadDelete = "download_delete"
+	CmdDownloadClear  = "download_clear"
+	CmdDownloadSync   = "download_sync"
+)
+
+// Request is a command sent to the server.
+type Request struct {
+	Command  string   `json:"command"`
+	Paths    []string `json:"paths,omitempty"`    // add, insert, replace: absolute paths of files or folders; play_path: the file; reorder: paths of the queue tracks in their new order
+	Tracks   []Track  `json:"tracks,omitempty"`   // add, insert, replace: tracks to queue as they are, instead of Paths
+	Index    *int     `json:"index,omitempty"`    // jump, select: queue index; insert: index to insert before, after the playing track when nil
+	Indices  []int    `json:"indices,omitempty"`  // remove, move: queue indices
+	Value    float64  `json:"value,omitempty"`    // seek: seconds, volume: percent, move: positions, speed: rate, sleep: seconds, chapter: 1 for the next, -1 for the previous
+	Relative bool     `json:"relative,omitempty"` // seek, volume: Value is added to the current one
+	Mode     string   `json:"mode,omitempty"`     // repeat: mode, the next one when empty; sleep: "off", "after", "end_of_track" or "end_of_album"
+	Enabled  *bool    `json:"enabled,omitempty"`  // shuffle, mute: toggled when nil
+	Loop     *Loop    `json:"loop,omitempty"`     // loop: A-B loop, cleared when nil
+	NoQueue  bool     `json:"no_queue,omitempty"` // snapshot: leave the queue out
+
+	Equalizer  *equalizer.Settings      `json:"equalizer,omitempty"`   // equalizer
+	Crossfade  *player.CrossfadeConfig  `json:"crossfade,omitempty"`   // crossfade
+	ReplayGain *player.ReplayGainConfig `json:"replay_gain,omitempty"` // replay_gain
+
+	Download *downloads.Download `json:"download,omitempty"` // download_create
+	ID       int64               `json:"id,omitempty"`       // download_delete: the download
+}
+
+// Response answers a request.
+type Response struct {
+	OK     bool    `json:"ok"`
+	Error  string  `json:"error,omitempty"`
+	Status *Status `json:"status,omitempty"` // status, and commands changing the playback
+	Queue  *Queue  `json:"queue,omitempty"`  // queue
+	Added  int     `json:"added,omitempty"`  // add, insert: tracks added to the queue
+
+	Changed  bool      `json:"changed,omitempty"`  // move, undo, redo: the queue changed
+	Snapshot *Snapshot `json:"snapshot,omitempty"` // snapshot
+	ID       int64     `json:"id,omitempty"`       // download_create: the new download
+}
+
+// Track is a track of the queue.
+type Track struct {
+	ID          int64   `json:"id,omitempty"` // Library ID, 0 for files outside the library
+	Path        string  `json:"path"`
+	Title       string  `json:"title"`
+	Artist      string  `json:"artist"`
+	Album       string  `json:"album"`
+	TrackNumber int     `json:"track_number,omitempty"`
+	DiscNumber  int     `json:"disc_number,omitempty"`
+	Genre       string  `json:"genre,omitempty"`
+	Year        int     `json:"year,omitempty"`
+	Duration    float64 `json:"duration,omitempty"` // Seconds
+}
+
+// Queue is the playing queue.
+type Queue struct {
+	Tracks []Track `json:"tracks"`
+	Index  int     `json:"index"` // Current track, -1 if none
+}
+
+// Status is the playback state.
+type Status struct {
+	State       string  `json:"state"` // "playing", "paused" or "stopped"
+	Track       *Track  `json:"track,omitempty"`
+	Index       int     `json:"index"`
+	QueueLength int     `json:"queue_length"`
+	Position    float64 `json:"position"` // Seconds
+	Duration    float64 `json:"duration"` // Seconds, 0 for streams
+	Volume      int     `json:"volume"`   // Percent
+	Muted       bool    `json:"muted"`
+	Repeat      string  `json:"repeat"` // "off", "all", "one" or "radio"
+	Shuffle     bool    `json:"shuffle"`
+	Speed       float64 `json:"speed"`
+}
+
+// Snapshot is the whole playback state, which attached interfaces mirror.
+type Snapshot struct {
+	Status
+	Queue            *Queue                  `json:"queue,omitempty"`
+	Seekable         bool                    `json:"seekable"`
+	Sleep            Sleep                   `json:"sleep"`
+	Loop             Loop                    `json:"loop"`
+	Chapters         []tags.Chapter          `json:"chapters,omitempty"`
+	Info             *tags.FileInfo          `json:"info,omitempty"` // Tags of the playing file, or title of the playing stream
+	ReplayGain       player.ReplayGainStatus `json:"replay_gain"`
+	Equalizer        equalizer.Settings      `json:"equalizer"`
+	OutputSampleRate int                     `json:"output_sample_rate,omitempty"`
+	Daemon           bool                    `json:"daemon,omitempty"` // Served by "waves daemon", interfaces can attach
+}
+
+// Sleep is the state of the sleep timer.
+type Sleep struct {
+	Mode      string  `json:"mode"`                // "off", "after", "end_of_track" or "end_of_album"
+	After     float64 `json:"after,omitempty"`     // Seconds the timer was set to
+	Remaining float64 `json:"remaining,omitempty"` // Seconds until playback pauses, 0 when unknown
+	Fading    bool    `json:"fading,omitempty"`
+}
+
+// Loop is an A-B loop, in seconds.
+type Loop struct {
+	A float64 `json:"a"`
+	B float64 `json:"b"`
+}
+
+// Event types streamed by follow.
+const (
+	EventState    = "state"
+	EventTrack    = "track"
+	EventPosition = "position"
+	EventQueue    = "queue"
+	EventMode     = "mode"
+	EventVolume   = "volume"
+	EventError    = "error"
+)
+
+// Event is a playback event, streamed after a follow request.
+type Event struct {
+	Type     string  `json:"type"`
+	State    string  `json:"state,omitempty"`    // state
+	Previous string  `json:"previous,omitempty"` // state: the state before
+	Finished bool    `json:"finished,omitempty"` // state, track: the previous track played to its end
+	Track    *Track  `json:"track,omitempty"`    // track
+	Index    *int    `json:"index,omitempty"`    // track
+	From     *int    `json:"from,omitempty"`     // track: index of the previous track, -1 if none
+	Position float64 `json:"position,omitempty"` // position: seconds, after a seek
+	Queue    *Queue  `json:"queue,omitempty"`    // queue
+	Mode     *Mode   `json:"mode,omitempty"`     // mode
+	Volume   *int    `json:"volume,omitempty"`   // volume: percent
+	Muted    bool    `json:"muted,omitempty"`    // volume
+	Error    string  `json:"error,omitempty"`    // error
+	Path     string  `json:"path,omitempty"`     // error
+}
+
+// Mode holds the playback modes.
+type Mode struct {
+	Repeat  string  `json:"repeat"`
+	Shuffle bool    `json:"shuffle"`
+	Speed   float64 `json:"speed"`
+	Sleep   string  `json:"sleep"` // "off", "after", "end_of_track" or "end_of_album"
+}
+
+// newTrack converts a playback track.
+func newTrack(t playback.Track) Track {
+	return Track{
+		ID:          t.ID,
+		Path:        t.Path,
+		Title:       t.Title,
+		Artist:      t.Artist,
+		Album:       t.Album,
+		TrackNumber: t.TrackNumber,
+		DiscNumber:  t.DiscNumber,
+		Genre:       t.Genre,
+		Year:        t.Year,
+		Duration:    t.Duration.Seconds(),
+	}
+}
+
+// playback converts a track back to a playback track.
+func (t Track) playback() playback.Track {
+	return playback.Track{
+		ID:          t.ID,
+		Path:        t.Path,
+		Title:       t.Title,
+		Artist:      t.Artist,
+		Album:       t.Album,
+		TrackNumber: t.TrackNumber,
+		DiscNumber:  t.DiscNumber,
+		Genre:       t.Genre,
+		Year:        t.Year,
+		Duration:    seconds(t.Duration),
+	}
+}
+
+// seconds converts seconds to a duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// newQueue converts the tracks of a queue.
+func newQueue(tracks []playback.Track, index int) *Queue {
+	q := &Queue{Tracks: make([]Track, len(tracks)), Index: index}
+	for i, t := range tracks {
+		q.Tracks[i] = newTrack(t)
+	}
+	return q
+}
+
+// newEvent converts the event received from a subscription, ok being false
+// for unknown events.
+func newEvent(e any) (Event, bool) {
+	switch e := e.(type) {
+	case playback.StateChange:
+		return Event{Type: EventState, State: name(e.Current), Previous: name(e.Previous), Finished: e.Finished}, true
+	case playback.TrackChange:
+		ev := Event{Type: EventTrack, Index: &e.Index, From: &e.PreviousIndex, Finished: e.Finished}
+		if e.Current != nil {
+			t := newTrack(*e.Current)
+			ev.Track = &t
+		}
+		return ev, true
+	case playback.PositionChange:
+		return Event{Type: EventPosition, Position: e.Position.Seconds()}, true
+	case playback.QueueChange:
+		return Event{Type: EventQueue, Queue: newQueue(e.Tracks, e.Index)}, true
+	case playback.ModeChange:
+		return Event{Type: EventMode, Mode: &Mode{
+			Repeat:  name(e.RepeatMode),
+			Shuffle: e.Shuffle,
+			Speed:   e.Speed,
+			Sleep:   name(e.Sleep),
+		}}, true
+	case playback.VolumeChange:
+		volume := int(math.Round(e.Volume * 100))
+		return Event{Type: EventVolume, Volume: &volume, Muted: e.Muted}, true
+	case playback.ErrorEvent:
+		ev := Event{Type: EventError, Path: e.Path, Error: e.Operation}
+		if e.Err != nil {
+			ev.Error = e.Operation + ": " + e.Err.Error()
+		}
+		return ev, true
+	}
+	return Event{}, false
+}
+
+// repeatModes are the repeat modes by name.
+var repeatModes = map[string]playback.RepeatMode{
+	"off":   playback.RepeatOff,
+	"all":   playback.RepeatAll,
+	"one":   playback.RepeatOne,
+	"radio": playback.RepeatRadio,
+}
+
+// sleepModes are the sleep timer modes by name.
+var sleepModes = map[string]playback.SleepMode{
+	"off":          playback.SleepOff,
+	"after":        playback.SleepAfter,
+	"end_of_track": playback.SleepEndOfTrack,
+	"end_of_album": playback.SleepEndOfAlbum,
+}
+
+// states are the playback states by name.
+var states = map[string]playback.State{
+	"playing": playback.StatePlaying,
+	"paused":  playback.StatePaused,
+	"stopped": playback.StateStopped,
+}
+
+// name returns the name of a state or mode in lower snake case.
+func name(s fmt.Stringer) string {
+	return strings.ReplaceAll(strings.ToLower(s.String()), " ", "_")
+}
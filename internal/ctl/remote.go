@@ -0,0 +1,603 @@
+package ctl
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/playlist"
+	"github.com/llehouerou/waves/internal/tags"
+)
+
+// ErrNotDaemon is returned by Attach when the waves serving the socket is
+// not a daemon.
+var ErrNotDaemon = errors.New("waves is not running as a daemon")
+
+// refreshInterval is how often Remote refreshes the state changing without
+// events: position, sleep timer, stream title.
+const refreshInterval = time.Second
+
+// Remote is the playback service of a daemon, controlled over its socket.
+// Attached interfaces use it in place of a local service.
+//
+// Queries read a snapshot of the daemon state, refreshed after each command,
+// each event and every second, the position being interpolated in between.
+// Events are forwarded from the daemon. Close detaches from the daemon,
+// which keeps playing.
+type Remote struct {
+	callMu sync.Mutex // Serializes calls
+	call   *Client
+	follow *Client
+
+	mu    sync.RWMutex
+	snap  Snapshot
+	at    time.Time              // When snap was taken
+	queue *playlist.PlayingQueue // The queue of snap
+
+	player    *remotePlayer
+	events    playback.Hub
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// Verify Remote implements playback.Service at compile time.
+var _ playback.Service = (*Remote)(nil)
+
+// Attach connects to the daemon serving the socket at path. It returns
+// ErrNotDaemon when a waves with its own interface serves it.
+func Attach(path string) (*Remote, error) {
+	call, err := Dial(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Remote{call: call, queue: playlist.NewQueue(), done: make(chan struct{})}
+	r.player = &remotePlayer{r: r}
+	if err := r.refresh(true); err != nil {
+		call.Close()
+		return nil, err
+	}
+	if !r.snapshot().Daemon {
+		call.Close()
+		return nil, ErrNotDaemon
+	}
+	if r.follow, err = Dial(path); err != nil {
+		call.Close()
+		return nil, err
+	}
+
+	// Follow before starting, so that no event is missed
+	if _, err := r.follow.Call(Request{Command: CmdFollow}); err != nil {
+		r.follow.Close()
+		call.Close()
+		return nil, err
+	}
+	r.wg.Add(2)
+	go r.forward()
+	go r.tick()
+	return r, nil
+}
+
+// forward forwards the daemon events until the connection closes.
+func (r *Remote) forward() {
+	defer r.wg.Done()
+	defer r.shutdown()
+	for {
+		var e Event
+		if err := r.follow.dec.Decode(&e); err != nil {
+			return
+		}
+		r.handleEvent(e)
+	}
+}
+
+// tick refreshes the snapshot periodically.
+func (r *Remote) tick() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			_ = r.refresh(false)
+		}
+	}
+}
+
+// shutdown closes the subscriptions once the daemon is gone or detached.
+func (r *Remote) shutdown() {
+	r.closeOnce.Do(func() {
+		close(r.done)
+		r.events.Close()
+	})
+}
+
+// handleEvent refreshes the snapshot and emits the event.
+func (r *Remote) handleEvent(e Event) {
+	_ = r.refresh(e.Type == EventQueue)
+
+	switch e.Type {
+	case EventState:
+		r.events.SendState(playback.StateChange{
+			Previous: states[e.Previous],
+			Current:  states[e.State],
+			Finished: e.Finished,
+		})
+	case EventTrack:
+		tc := playback.TrackChange{PreviousIndex: -1, Index: -1, Finished: e.Finished}
+		if e.Index != nil {
+			tc.Index = *e.Index
+		}
+		if e.From != nil {
+			tc.PreviousIndex = *e.From
+		}
+		if e.Track != nil {
+			t := e.Track.playback()
+			tc.Current = &t
+		}
+		r.events.SendTrack(tc)
+	case EventPosition:
+		r.events.SendPosition(playback.PositionChange{Position: seconds(e.Position)})
+	case EventQueue:
+		var qc playback.QueueChange
+		if e.Queue != nil {
+			qc.Index = e.Queue.Index
+			for _, t := range e.Queue.Tracks {
+				qc.Tracks = append(qc.Tracks, t.playback())
+			}
+		}
+		r.events.SendQueue(qc)
+	case EventMode:
+		cur := r.snapshot()
+		r.events.SendMode(playback.ModeChange{
+			RepeatMode: repeatModes[cur.Repeat],
+			Shuffle:    cur.Shuffle,
+			Speed:      cur.Speed,
+			Sleep:      sleepModes[cur.Sleep.Mode],
+			Loop:       playback.Loop{A: seconds(cur.Loop.A), B: seconds(cur.Loop.B)},
+		})
+	case EventVolume:
+		cur := r.snapshot()
+		r.events.SendVolume(playback.VolumeChange{Volume: float64(cur.Volume) / 100, Muted: cur.Muted})
+	case EventError:
+		op, msg, _ := strings.Cut(e.Error, ": ")
+		ev := playback.ErrorEvent{Operation: op, Path: e.Path}
+		if msg != "" {
+			ev.Err = errors.New(msg)
+		}
+		r.events.SendError(ev)
+	}
+}
+
+// do sends a request.
+func (r *Remote) do(req Request) (Response, error) {
+	r.callMu.Lock()
+	defer r.callMu.Unlock()
+	return r.call.Call(req)
+}
+
+// command sends a request and refreshes the snapshot, with the queue if
+// the request changes it.
+func (r *Remote) command(req Request, queue bool) (Response, error) {
+	resp, err := r.do(req)
+	_ = r.refresh(queue)
+	return resp, err
+}
+
+// run sends a request that changes the playback, but not the queue.
+func (r *Remote) run(req Request) error {
+	_, err := r.command(req, false)
+	return err
+}
+
+// edit sends a request changing the queue.
+func (r *Remote) edit(req Request) Response {
+	resp, _ := r.command(req, true)
+	return resp
+}
+
+// refresh takes a snapshot of the daemon state, with the queue if
+// withQueue, keeping the last one otherwise.
+func (r *Remote) refresh(withQueue bool) error {
+	resp, err := r.do(Request{Command: CmdSnapshot, NoQueue: !withQueue})
+	if err != nil {
+		return err
+	}
+	snap := *resp.Snapshot
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tracks := r.queue.Tracks()
+	if snap.Queue != nil {
+		tracks = make([]playlist.Track, len(snap.Queue.Tracks))
+		for i, t := range snap.Queue.Tracks {
+			tracks[i] = t.playback().ToPlaylist()
+		}
+		snap.Queue = nil
+	}
+	mirror(r.queue, tracks, snap.Index)
+	r.queue.SetRepeatMode(playlist.RepeatMode(repeatModes[snap.Repeat]))
+	r.queue.SetShuffle(snap.Shuffle)
+	r.snap = snap
+	r.at = time.Now()
+	return nil
+}
+
+// snapshot returns the last snapshot, without the queue.
+func (r *Remote) snapshot() Snapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.snap
+}
+
+// SyncQueue makes q a copy of the daemon queue, for interfaces showing it.
+// Call it from the goroutine using q, which is left alone when already
+// up to date.
+func (r *Remote) SyncQueue(q *playlist.PlayingQueue) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	mirror(q, r.queue.Tracks(), r.queue.CurrentIndex())
+	if q.RepeatMode() != r.queue.RepeatMode() {
+		q.SetRepeatMode(r.queue.RepeatMode())
+	}
+	if q.Shuffle() != r.queue.Shuffle() {
+		q.SetShuffle(r.queue.Shuffle())
+	}
+}
+
+// mirror sets the tracks and current index of q, unless it has them.
+func mirror(q *playlist.PlayingQueue, tracks []playlist.Track, index int) {
+	if q.CurrentIndex() == index && samePaths(q.Tracks(), tracks) {
+		return
+	}
+	q.Clear()
+	q.ClearHistory()
+	q.AddWithoutHistory(tracks...)
+	q.JumpTo(index)
+}
+
+func samePaths(a, b []playlist.Track) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Path != b[i].Path {
+			return false
+		}
+	}
+	return true
+}
+
+// Reorder makes the daemon queue follow paths, the order an interface left
+// its copy of the queue in after moving and removing tracks.
+func (r *Remote) Reorder(paths []string) {
+	r.edit(Request{Command: CmdReorder, Paths: paths})
+}
+
+func (r *Remote) Play() error {
+	return r.run(Request{Command: CmdStart})
+}
+
+func (r *Remote) PlayPath(path string) error {
+	return r.run(Request{Command: CmdPlayPath, Paths: []string{path}})
+}
+
+func (r *Remote) Pause() error {
+	return r.run(Request{Command: CmdPause})
+}
+
+func (r *Remote) Stop() error {
+	return r.run(Request{Command: CmdStop})
+}
+
+func (r *Remote) Toggle() error {
+	return r.run(Request{Command: CmdToggle})
+}
+
+func (r *Remote) Next() error {
+	return r.run(Request{Command: CmdNext})
+}
+
+func (r *Remote) Previous() error {
+	return r.run(Request{Command: CmdPrev})
+}
+
+func (r *Remote) Seek(delta time.Duration) error {
+	return r.run(Request{Command: CmdSeek, Value: delta.Seconds(), Relative: true})
+}
+
+func (r *Remote) SeekTo(position time.Duration) error {
+	return r.run(Request{Command: CmdSeek, Value: position.Seconds()})
+}
+
+func (r *Remote) JumpTo(index int) error {
+	return r.run(Request{Command: CmdJump, Index: &index})
+}
+
+func (r *Remote) QueueAdvance() *playback.Track {
+	if r.run(Request{Command: CmdAdvance}) != nil {
+		return nil
+	}
+	return r.CurrentTrack()
+}
+
+func (r *Remote) QueueMoveTo(index int) *playback.Track {
+	if r.run(Request{Command: CmdSelect, Index: &index}) != nil {
+		return nil
+	}
+	return r.CurrentTrack()
+}
+
+func (r *Remote) AddTracks(tracks ...playback.Track) {
+	if len(tracks) > 0 {
+		r.edit(Request{Command: CmdAdd, Tracks: newTracks(tracks)})
+	}
+}
+
+func (r *Remote) InsertTracks(index int, tracks ...playback.Track) {
+	if len(tracks) > 0 {
+		r.edit(Request{Command: CmdInsert, Index: &index, Tracks: newTracks(tracks)})
+	}
+}
+
+func (r *Remote) ReplaceTracks(tracks ...playback.Track) *playback.Track {
+	if len(tracks) == 0 {
+		r.edit(Request{Command: CmdClear})
+		return nil
+	}
+	r.edit(Request{Command: CmdReplace, Tracks: newTracks(tracks)})
+	first := tracks[0]
+	return &first
+}
+
+func (r *Remote) RemoveTracks(indices ...int) {
+	if len(indices) > 0 {
+		r.edit(Request{Command: CmdRemove, Indices: indices})
+	}
+}
+
+func (r *Remote) MoveTracks(indices []int, delta int) bool {
+	return r.edit(Request{Command: CmdMove, Indices: indices, Value: float64(delta)}).Changed
+}
+
+func (r *Remote) ClearQueue() {
+	r.edit(Request{Command: CmdClear})
+}
+
+func (r *Remote) State() playback.State {
+	return states[r.snapshot().State]
+}
+
+func (r *Remote) IsPlaying() bool {
+	return r.State() == playback.StatePlaying
+}
+
+func (r *Remote) IsStopped() bool {
+	return r.State() == playback.StateStopped
+}
+
+func (r *Remote) IsPaused() bool {
+	return r.State() == playback.StatePaused
+}
+
+// Position returns the position of the last snapshot, plus the time played
+// since when playing.
+func (r *Remote) Position() time.Duration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	pos := seconds(r.snap.Position)
+	if r.snap.State == name(playback.StatePlaying) {
+		pos += time.Duration(float64(time.Since(r.at)) * r.snap.Speed)
+	}
+	if d := seconds(r.snap.Duration); d > 0 {
+		pos = min(pos, d)
+	}
+	return pos
+}
+
+func (r *Remote) Duration() time.Duration {
+	return seconds(r.snapshot().Duration)
+}
+
+func (r *Remote) Seekable() bool {
+	return r.snapshot().Seekable
+}
+
+func (r *Remote) CurrentTrack() *playback.Track {
+	snap := r.snapshot()
+	if snap.Track == nil {
+		return nil
+	}
+	t := snap.Track.playback()
+	return &t
+}
+
+func (r *Remote) TrackInfo() *tags.FileInfo {
+	return r.snapshot().Info
+}
+
+// Player returns a player forwarding to the daemon. See remotePlayer for
+// what it can't do.
+func (r *Remote) Player() player.Interface {
+	return r.player
+}
+
+func (r *Remote) QueueTracks() []playback.Track {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return playback.TracksFromPlaylist(r.queue.Tracks())
+}
+
+func (r *Remote) QueueCurrentIndex() int {
+	return r.snapshot().Index
+}
+
+func (r *Remote) QueueLen() int {
+	return r.snapshot().QueueLength
+}
+
+func (r *Remote) QueueIsEmpty() bool {
+	return r.QueueLen() == 0
+}
+
+func (r *Remote) QueueHasNext() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.queue.HasNext()
+}
+
+func (r *Remote) QueuePeekNext() *playback.Track {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	next := r.queue.PeekNext()
+	if next == nil {
+		return nil
+	}
+	t := playback.TrackFromPlaylist(*next)
+	return &t
+}
+
+// PreloadNext reads the mirrored queue. The daemon pre-loads from its own.
+func (r *Remote) PreloadNext() (path string, inAlbum bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	next := r.queue.PeekNext()
+	if next == nil {
+		return "", false
+	}
+	return next.Path, r.queue.NextInAlbumOrder()
+}
+
+func (r *Remote) Undo() bool {
+	return r.edit(Request{Command: CmdUndo}).Changed
+}
+
+func (r *Remote) Redo() bool {
+	return r.edit(Request{Command: CmdRedo}).Changed
+}
+
+func (r *Remote) RepeatMode() playback.RepeatMode {
+	return repeatModes[r.snapshot().Repeat]
+}
+
+func (r *Remote) SetRepeatMode(mode playback.RepeatMode) {
+	_ = r.run(Request{Command: CmdRepeat, Mode: name(mode)})
+}
+
+func (r *Remote) CycleRepeatMode() playback.RepeatMode {
+	_ = r.run(Request{Command: CmdRepeat})
+	return r.RepeatMode()
+}
+
+func (r *Remote) Shuffle() bool {
+	return r.snapshot().Shuffle
+}
+
+func (r *Remote) SetShuffle(enabled bool) {
+	_ = r.run(Request{Command: CmdShuffle, Enabled: &enabled})
+}
+
+func (r *Remote) ToggleShuffle() bool {
+	_ = r.run(Request{Command: CmdShuffle})
+	return r.Shuffle()
+}
+
+func (r *Remote) Speed() float64 {
+	return r.snapshot().Speed
+}
+
+func (r *Remote) SetSpeed(speed float64) {
+	_ = r.run(Request{Command: CmdSpeed, Value: speed})
+}
+
+func (r *Remote) SetVolume(level float64) {
+	_ = r.run(Request{Command: CmdVolume, Value: level * 100})
+}
+
+func (r *Remote) SetMuted(muted bool) {
+	_ = r.run(Request{Command: CmdMute, Enabled: &muted})
+}
+
+// SleepTimer returns the sleep timer of the last snapshot, counting down
+// the time remaining since.
+func (r *Remote) SleepTimer() playback.SleepTimer {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	sleep := r.snap.Sleep
+	timer := playback.SleepTimer{
+		Mode:      sleepModes[sleep.Mode],
+		After:     seconds(sleep.After),
+		Remaining: seconds(sleep.Remaining),
+		Fading:    sleep.Fading,
+	}
+	if timer.Remaining > 0 && r.snap.State == name(playback.StatePlaying) {
+		timer.Remaining = max(time.Duration(0), timer.Remaining-time.Since(r.at))
+	}
+	return timer
+}
+
+func (r *Remote) SetSleepTimer(mode playback.SleepMode, after time.Duration) {
+	_ = r.run(Request{Command: CmdSleep, Mode: name(mode), Value: after.Seconds()})
+}
+
+func (r *Remote) Loop() playback.Loop {
+	l := r.snapshot().Loop
+	return playback.Loop{A: seconds(l.A), B: seconds(l.B)}
+}
+
+func (r *Remote) SetLoop(l playback.Loop) error {
+	return r.run(Request{Command: CmdLoop, Loop: &Loop{A: l.A.Seconds(), B: l.B.Seconds()}})
+}
+
+func (r *Remote) ClearLoop() {
+	_ = r.run(Request{Command: CmdLoop})
+}
+
+func (r *Remote) Chapters() []tags.Chapter {
+	return r.snapshot().Chapters
+}
+
+func (r *Remote) NextChapter() error {
+	return r.run(Request{Command: CmdChapter, Value: 1})
+}
+
+func (r *Remote) PreviousChapter() error {
+	return r.run(Request{Command: CmdChapter, Value: -1})
+}
+
+// SetResumer does nothing: the daemon keeps the resume positions.
+func (r *Remote) SetResumer(playback.Resumer) {}
+
+func (r *Remote) Subscribe() *playback.Subscription {
+	return r.events.Subscribe()
+}
+
+func (r *Remote) Unsubscribe(sub *playback.Subscription) {
+	r.events.Unsubscribe(sub)
+}
+
+// Close detaches from the daemon, leaving it playing, and closes the
+// subscriptions.
+func (r *Remote) Close() error {
+	err := r.follow.Close()
+	r.callMu.Lock()
+	r.call.Close()
+	r.callMu.Unlock()
+	r.shutdown()
+	r.wg.Wait()
+	return err
+}
+
+// newTracks converts playback tracks.
+func newTracks(tracks []playback.Track) []Track {
+	converted := make([]Track, len(tracks))
+	for i, t := range tracks {
+		converted[i] = newTrack(t)
+	}
+	return converted
+}
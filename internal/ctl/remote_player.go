@@ -0,0 +1,162 @@
+package ctl
+
+import (
+	"sync"
+	"time"
+
+	"github.com/llehouerou/waves/internal/equalizer"
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/tags"
+	"github.com/llehouerou/waves/internal/visualizer"
+)
+
+// remotePlayer is the player of a Remote. Playback controls, volume, speed
+// and the replay gain, crossfade and equalizer settings go to the daemon,
+// and state is read from the snapshot. Its audio isn't available: the tap
+// stays silent.
+type remotePlayer struct {
+	r *Remote
+
+	once sync.Once
+	tap  *visualizer.Ring
+}
+
+// Verify remotePlayer implements player.Interface at compile time.
+var _ player.Interface = (*remotePlayer)(nil)
+
+func (p *remotePlayer) Play(path string) error {
+	return p.r.PlayPath(path)
+}
+
+func (p *remotePlayer) Stop() {
+	_ = p.r.Stop()
+}
+
+func (p *remotePlayer) Pause() {
+	_ = p.r.Pause()
+}
+
+func (p *remotePlayer) Resume() {
+	if p.r.IsPaused() {
+		_ = p.r.Toggle()
+	}
+}
+
+func (p *remotePlayer) Toggle() {
+	_ = p.r.Toggle()
+}
+
+func (p *remotePlayer) State() player.State {
+	switch p.r.snapshot().State {
+	case "playing":
+		return player.Playing
+	case "paused":
+		return player.Paused
+	}
+	return player.Stopped
+}
+
+func (p *remotePlayer) TrackInfo() *tags.FileInfo {
+	return p.r.TrackInfo()
+}
+
+func (p *remotePlayer) Position() time.Duration {
+	return p.r.Position()
+}
+
+func (p *remotePlayer) Duration() time.Duration {
+	return p.r.Duration()
+}
+
+func (p *remotePlayer) Seek(delta time.Duration) {
+	_ = p.r.Seek(delta)
+}
+
+func (p *remotePlayer) Seekable() bool {
+	return p.r.Seekable()
+}
+
+// SetLoop returns false: the service sets loops on the daemon.
+func (p *remotePlayer) SetLoop(_, _ time.Duration) bool {
+	return false
+}
+
+func (p *remotePlayer) SetVolume(level float64) {
+	p.r.SetVolume(level)
+}
+
+func (p *remotePlayer) Volume() float64 {
+	return float64(p.r.snapshot().Volume) / 100
+}
+
+func (p *remotePlayer) SetMuted(muted bool) {
+	p.r.SetMuted(muted)
+}
+
+func (p *remotePlayer) Muted() bool {
+	return p.r.snapshot().Muted
+}
+
+func (p *remotePlayer) SetReplayGain(cfg player.ReplayGainConfig) {
+	_ = p.r.run(Request{Command: CmdReplayGain, ReplayGain: &cfg})
+}
+
+func (p *remotePlayer) ReplayGain() player.ReplayGainStatus {
+	return p.r.snapshot().ReplayGain
+}
+
+// SetAlbumContext does nothing: the daemon sets it from its queue.
+func (p *remotePlayer) SetAlbumContext(bool) {}
+
+func (p *remotePlayer) SetCrossfade(cfg player.CrossfadeConfig) {
+	_ = p.r.run(Request{Command: CmdCrossfade, Crossfade: &cfg})
+}
+
+func (p *remotePlayer) SetEqualizer(s equalizer.Settings) {
+	_ = p.r.run(Request{Command: CmdEqualizer, Equalizer: &s})
+}
+
+func (p *remotePlayer) Equalizer() equalizer.Settings {
+	return p.r.snapshot().Equalizer.Clone()
+}
+
+func (p *remotePlayer) OutputSampleRate() int {
+	return p.r.snapshot().OutputSampleRate
+}
+
+// Tap returns a ring nothing is written to: the audio plays in the daemon.
+func (p *remotePlayer) Tap() *visualizer.Ring {
+	p.once.Do(func() { p.tap = visualizer.NewRing(1) })
+	return p.tap
+}
+
+func (p *remotePlayer) SetSpeed(speed float64) {
+	p.r.SetSpeed(speed)
+}
+
+func (p *remotePlayer) Speed() float64 {
+	return p.r.Speed()
+}
+
+// OnFinished does nothing: the daemon advances the queue.
+func (p *remotePlayer) OnFinished(func()) {}
+
+func (p *remotePlayer) FinishedChan() <-chan struct{} {
+	return nil
+}
+
+// Done is closed when the remote is detached or the daemon stops.
+func (p *remotePlayer) Done() <-chan struct{} {
+	return p.r.done
+}
+
+func (p *remotePlayer) SetPreloadFunc(func() (string, bool)) {}
+
+func (p *remotePlayer) SetPreloadDuration(time.Duration) {}
+
+func (p *remotePlayer) ClearPreload() {}
+
+// Close does nothing: the daemon keeps its audio output.
+func (p *remotePlayer) Close() error {
+	return nil
+}
@@ -0,0 +1,200 @@
+package ctl
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/llehouerou/waves/internal/downloads"
+	"github.com/llehouerou/waves/internal/equalizer"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/playlist"
+)
+
+func attach(t *testing.T, ts *testServer) *Remote {
+	t.Helper()
+	ts.server.OnShutdown(func() {})
+	r, err := Attach(ts.server.Path())
+	if err != nil {
+		t.Fatalf("Attach() error: %v", err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func TestAttach_NotDaemon(t *testing.T) {
+	ts := newTestServer(t)
+	if _, err := Attach(ts.server.Path()); !errors.Is(err, ErrNotDaemon) {
+		t.Errorf("Attach() error = %v, want ErrNotDaemon", err)
+	}
+}
+
+func TestRemote_ControlsDaemon(t *testing.T) {
+	ts := newTestServer(t)
+	ts.player.SetDuration(5 * time.Minute)
+	r := attach(t, ts)
+	sub := r.Subscribe()
+
+	r.AddTracks(playback.Track{Path: "/a.mp3"}, playback.Track{Path: "/b.mp3"})
+	if r.QueueLen() != 2 || r.QueueTracks()[1].Path != "/b.mp3" {
+		t.Fatalf("queue = %v, want the 2 tracks added", r.QueueTracks())
+	}
+	if err := r.JumpTo(1); err != nil {
+		t.Fatalf("JumpTo() error: %v", err)
+	}
+	if err := r.Play(); err != nil {
+		t.Fatalf("Play() error: %v", err)
+	}
+	if !r.IsPlaying() || r.CurrentTrack().Path != "/b.mp3" || !ts.svc.IsPlaying() {
+		t.Errorf("remote playing %v, daemon playing %v; want /b.mp3 playing", r.CurrentTrack(), ts.svc.IsPlaying())
+	}
+	select {
+	case e := <-sub.StateChanged:
+		if e.Previous != playback.StateStopped || e.Current != playback.StatePlaying {
+			t.Errorf("state change = %+v, want stopped to playing", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no state change received")
+	}
+
+	r.Player().SetVolume(0.4)
+	r.SetRepeatMode(playback.RepeatAll)
+	if ts.player.Volume() != 0.4 || r.Player().Volume() != 0.4 {
+		t.Errorf("volume = %v on the daemon, %v on the remote; want 0.4", ts.player.Volume(), r.Player().Volume())
+	}
+	select {
+	case e := <-sub.VolumeChanged:
+		if e.Volume != 0.4 || e.Muted {
+			t.Errorf("volume change = %+v, want 0.4 unmuted", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no volume change received")
+	}
+	if ts.svc.RepeatMode() != playback.RepeatAll || r.QueuePeekNext().Path != "/a.mp3" {
+		t.Errorf("repeat = %v, next = %v; want repeat all back to /a.mp3", ts.svc.RepeatMode(), r.QueuePeekNext())
+	}
+
+	q := playlist.NewQueue()
+	r.SyncQueue(q)
+	if q.Len() != 2 || q.CurrentIndex() != 1 || q.RepeatMode() != playlist.RepeatAll {
+		t.Errorf("synced queue has %d tracks at %d, repeat %v; want 2 at 1, repeat all", q.Len(), q.CurrentIndex(), q.RepeatMode())
+	}
+	r.Reorder([]string{"/b.mp3", "/a.mp3"})
+	if ts.svc.QueueTracks()[0].Path != "/b.mp3" || r.QueueCurrentIndex() != 0 {
+		t.Errorf("daemon queue = %v at %d, want /b.mp3 first and playing", ts.svc.QueueTracks(), r.QueueCurrentIndex())
+	}
+
+	// Detaching leaves the daemon playing
+	r.Close()
+	select {
+	case <-sub.Done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not closed on detach")
+	}
+	if !ts.svc.IsPlaying() {
+		t.Error("daemon stopped on detach")
+	}
+}
+
+func TestRemote_PlayerSettingsGoToDaemon(t *testing.T) {
+	ts := newTestServer(t)
+	r := attach(t, ts)
+	p := r.Player()
+
+	eq := equalizer.Settings{Enabled: true, PreampDB: -3, Bands: []equalizer.Band{{Freq: 1000, GainDB: 4, Q: 1.41}}}
+	p.SetEqualizer(eq)
+	if got := ts.player.Equalizer(); !got.Enabled || got.PreampDB != -3 || len(got.Bands) != 1 || got.Bands[0].GainDB != 4 {
+		t.Errorf("daemon equalizer = %+v, want %+v", got, eq)
+	}
+	if got := p.Equalizer(); !got.Enabled || len(got.Bands) != 1 {
+		t.Errorf("remote equalizer = %+v, want the daemon one", got)
+	}
+
+	p.SetCrossfade(player.CrossfadeConfig{Duration: 3 * time.Second, Curve: player.CrossfadeLinear})
+	if got := ts.player.Crossfade(); got.Duration != 3*time.Second || got.Curve != player.CrossfadeLinear {
+		t.Errorf("daemon crossfade = %+v, want 3s linear", got)
+	}
+
+	p.SetReplayGain(player.ReplayGainConfig{Mode: player.ReplayGainAlbum, PreampDB: 2})
+	if ts.player.ReplayGain().Mode != player.ReplayGainAlbum || p.ReplayGain().Mode != player.ReplayGainAlbum {
+		t.Errorf("replay gain mode = %v on the daemon, %v on the remote; want album", ts.player.ReplayGain().Mode, p.ReplayGain().Mode)
+	}
+}
+
+// fakeDownloads records the downloads managed.
+type fakeDownloads struct {
+	created []downloads.Download
+	deleted []int64
+	cleared bool
+	synced  bool
+}
+
+func (f *fakeDownloads) Create(d downloads.Download) (int64, error) {
+	f.created = append(f.created, d)
+	return int64(len(f.created)), nil
+}
+
+func (f *fakeDownloads) Delete(id int64) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func (f *fakeDownloads) ClearCompleted() error {
+	f.cleared = true
+	return nil
+}
+
+func (f *fakeDownloads) Sync() error {
+	f.synced = true
+	return nil
+}
+
+func TestRemote_DownloadsGoToDaemon(t *testing.T) {
+	ts := newTestServer(t)
+	r := attach(t, ts)
+	if err := r.Downloads().Sync(); err == nil {
+		t.Error("Sync() succeeded without downloads served")
+	}
+
+	dl := &fakeDownloads{}
+	ts.server.SetDownloads(dl)
+	id, err := r.Downloads().Create(downloads.Download{
+		MBAlbumTitle:  "Parklife",
+		SlskdUsername: "user",
+		Files:         []downloads.DownloadFile{{Filename: "01.flac", Size: 1024}},
+	})
+	if err != nil || id != 1 {
+		t.Fatalf("Create() = %d, %v; want 1", id, err)
+	}
+	if got := dl.created[0]; got.MBAlbumTitle != "Parklife" || len(got.Files) != 1 || got.Files[0].Filename != "01.flac" {
+		t.Errorf("daemon created %+v, want the download sent", got)
+	}
+	if err := r.Downloads().Delete(7); err != nil || len(dl.deleted) != 1 || dl.deleted[0] != 7 {
+		t.Errorf("Delete(7) = %v, daemon deleted %v", err, dl.deleted)
+	}
+	if err := r.Downloads().ClearCompleted(); err != nil || !dl.cleared {
+		t.Errorf("ClearCompleted() = %v, daemon cleared %v", err, dl.cleared)
+	}
+	if err := r.Downloads().Sync(); err != nil || !dl.synced {
+		t.Errorf("Sync() = %v, daemon synced %v", err, dl.synced)
+	}
+}
+
+func TestRemote_DaemonStops(t *testing.T) {
+	ts := newTestServer(t)
+	r := attach(t, ts)
+	sub := r.Subscribe()
+
+	ts.server.Close()
+	select {
+	case <-sub.Done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not closed when the daemon stopped")
+	}
+	select {
+	case <-r.Player().Done():
+	default:
+		t.Error("player not done when the daemon stopped")
+	}
+}
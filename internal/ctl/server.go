@@ -0,0 +1,480 @@
+package ctl
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/llehouerou/waves/internal/downloads"
+	"github.com/llehouerou/waves/internal/library"
+	"github.com/llehouerou/waves/internal/playback"
+)
+
+// ErrRunning is returned by Listen when another waves serves the socket.
+var ErrRunning = errors.New("another waves is listening on the control socket")
+
+// ErrNotPrivate is returned by Listen when the folder of the socket exists
+// but other users can enter it.
+var ErrNotPrivate = errors.New("the folder of the control socket is not private")
+
+// maxRequestSize bounds a request line, which can hold many paths.
+const maxRequestSize = 1 << 20
+
+// Library is the part of the library used to fill in added tracks.
+type Library interface {
+	TrackByPath(path string) (*library.Track, error)
+}
+
+// VolumeStore persists volume changes.
+type VolumeStore interface {
+	SaveVolume(volume float64, muted bool) error
+}
+
+// Server serves the control socket.
+//
+// Only the user running waves can connect: the socket is created in a
+// directory only they can enter, and is itself only readable and writable
+// by them.
+type Server struct {
+	ln      net.Listener
+	path    string
+	lib     Library
+	volumes VolumeStore
+	svc     *playback.Holder // Replaced by SetService
+
+	mu        sync.Mutex
+	shutdown  func()            // Stops the daemon, nil when not serving one
+	downloads downloads.Control // nil when the downloads aren't served
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// Listen creates the socket at path and serves it in the background. A
+// socket left by a waves that exited is replaced. The folder of the socket
+// is created private to the user, and must be when it exists. lib and
+// volumes may be nil.
+func Listen(path string, svc playback.Service, lib Library, volumes VolumeStore) (*Server, error) {
+	if err := privateDir(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, ErrRunning
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	// Nobody else can enter the folder until the socket is restricted
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+
+	s := &Server{
+		ln:      ln,
+		path:    path,
+		lib:     lib,
+		volumes: volumes,
+		svc:     playback.NewHolder(svc),
+		conns:   make(map[net.Conn]struct{}),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// privateDir creates dir for the user alone, or checks that only the user
+// can enter it when it exists.
+func privateDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() || !private(info) {
+		return fmt.Errorf("%w: %s must be a folder of yours with permissions 700", ErrNotPrivate, dir)
+	}
+	return nil
+}
+
+// Path returns the path of the socket.
+func (s *Server) Path() string {
+	return s.path
+}
+
+// SetService makes the server control a new playback service. Call this
+// when the service is recreated, followers being moved to the new one.
+func (s *Server) SetService(svc playback.Service) {
+	s.svc.Set(svc)
+}
+
+// OnShutdown makes the shutdown command call fn, in its own goroutine. The
+// daemon sets it, which also lets interfaces attach to it.
+func (s *Server) OnShutdown(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.shutdown = fn
+}
+
+// SetDownloads makes the download commands manage the downloads with c.
+// The daemon sets it, so that attached interfaces leave them to it.
+func (s *Server) SetDownloads(c downloads.Control) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.downloads = c
+}
+
+// Close stops the server, disconnects the clients and removes the socket.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	// Closing a unix listener removes its socket file
+	err := s.ln.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		if !s.track(conn) {
+			conn.Close()
+			return
+		}
+		s.wg.Add(1)
+		go s.handleConn(conn)
+	}
+}
+
+// track registers a connection, returning false once the server is closed.
+func (s *Server) track(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+func (s *Server) untrack(conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.conns, conn)
+}
+
+// handleConn answers the requests of a client until it disconnects or
+// follows the events.
+func (s *Server) handleConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer s.untrack(conn)
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), maxRequestSize)
+	enc := json.NewEncoder(conn)
+	for scanner.Scan() {
+		var req Request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			_ = enc.Encode(Response{Error: "invalid request: " + err.Error()})
+			continue
+		}
+		if req.Command == CmdFollow {
+			if enc.Encode(Response{OK: true}) == nil {
+				s.follow(conn, enc)
+			}
+			return
+		}
+		if err := enc.Encode(s.handle(req)); err != nil {
+			return
+		}
+	}
+}
+
+// handle runs a command.
+func (s *Server) handle(req Request) Response {
+	svc := s.svc.Service()
+	var err error
+	switch req.Command {
+	case CmdPlay:
+		err = play(svc)
+	case CmdPause:
+		err = svc.Pause()
+	case CmdToggle:
+		err = svc.Toggle()
+	case CmdStop:
+		err = svc.Stop()
+	case CmdNext:
+		err = svc.Next()
+	case CmdPrev:
+		err = svc.Previous()
+	case CmdSeek:
+		err = seek(svc, req)
+	case CmdVolume:
+		err = s.setVolume(svc, req)
+	case CmdMute:
+		err = s.mute(svc, req)
+	case CmdAdd, CmdInsert, CmdReplace:
+		return s.addTracks(svc, req)
+	case CmdQueue:
+		return Response{OK: true, Queue: newQueue(svc.QueueTracks(), svc.QueueCurrentIndex())}
+	case CmdSnapshot:
+		return Response{OK: true, Snapshot: s.snapshot(svc, !req.NoQueue)}
+	case CmdShutdown:
+		return s.shutdownDaemon()
+	case CmdDownloadCreate, CmdDownloadDelete, CmdDownloadClear, CmdDownloadSync:
+		return s.manageDownloads(req)
+	case CmdMove, CmdUndo, CmdRedo:
+		return editQueue(svc, req)
+	case CmdStatus:
+	default:
+		err = handleService(svc, req)
+		if errors.Is(err, errUnknown) {
+			return Response{Error: fmt.Sprintf("unknown command %q", req.Command)}
+		}
+	}
+	if err != nil {
+		return Response{Error: err.Error()}
+	}
+	return Response{OK: true, Status: status(svc)}
+}
+
+// play starts the queue when stopped and resumes it when paused.
+func play(svc playback.Service) error {
+	switch svc.State() {
+	case playback.StatePlaying:
+		return nil
+	case playback.StatePaused:
+		return svc.Toggle()
+	case playback.StateStopped:
+	}
+	if svc.QueueCurrentIndex() < 0 && !svc.QueueIsEmpty() {
+		svc.QueueMoveTo(0)
+	}
+	return svc.Play()
+}
+
+func seek(svc playback.Service, req Request) error {
+	d := time.Duration(req.Value * float64(time.Second))
+	if req.Relative {
+		return svc.Seek(d)
+	}
+	return svc.SeekTo(d)
+}
+
+// setVolume sets the volume, unmuting the player like the volume keys.
+func (s *Server) setVolume(svc playback.Service, req Request) error {
+	p := svc.Player()
+	level := req.Value / 100
+	if req.Relative {
+		level += p.Volume()
+	}
+	level = math.Round(max(0, min(1, level))*100) / 100
+
+	svc.SetMuted(false)
+	svc.SetVolume(level)
+	if s.volumes == nil {
+		return nil
+	}
+	return s.volumes.SaveVolume(p.Volume(), p.Muted())
+}
+
+// mute mutes or unmutes the player, or toggles it.
+func (s *Server) mute(svc playback.Service, req Request) error {
+	p := svc.Player()
+	muted := !p.Muted()
+	if req.Enabled != nil {
+		muted = *req.Enabled
+	}
+	svc.SetMuted(muted)
+	if s.volumes == nil {
+		return nil
+	}
+	return s.volumes.SaveVolume(p.Volume(), p.Muted())
+}
+
+// addTracks appends the tracks to the queue, inserts them after the
+// current track or at an index, or replaces the queue with them.
+func (s *Server) addTracks(svc playback.Service, req Request) Response {
+	var tracks []playback.Track
+	if len(req.Tracks) > 0 {
+		tracks = make([]playback.Track, len(req.Tracks))
+		for i, t := range req.Tracks {
+			tracks[i] = t.playback()
+		}
+	} else {
+		var err error
+		if tracks, err = collectTracks(s.lib, req.Paths); err != nil {
+			return Response{Error: err.Error()}
+		}
+	}
+	switch {
+	case req.Command == CmdReplace:
+		svc.ReplaceTracks(tracks...)
+	case req.Command == CmdInsert && req.Index != nil:
+		svc.InsertTracks(*req.Index, tracks...)
+	case req.Command == CmdInsert:
+		svc.InsertTracks(svc.QueueCurrentIndex()+1, tracks...)
+	default:
+		svc.AddTracks(tracks...)
+	}
+	return Response{OK: true, Added: len(tracks)}
+}
+
+// shutdownDaemon stops the daemon served.
+func (s *Server) shutdownDaemon() Response {
+	s.mu.Lock()
+	shutdown := s.shutdown
+	s.mu.Unlock()
+	if shutdown == nil {
+		return Response{Error: "waves is not running as a daemon"}
+	}
+	// The daemon closes the server, which waits for this connection
+	go shutdown()
+	return Response{OK: true}
+}
+
+// snapshot reads the whole playback state, the queue only if withQueue.
+func (s *Server) snapshot(svc playback.Service, withQueue bool) *Snapshot {
+	s.mu.Lock()
+	daemon := s.shutdown != nil
+	s.mu.Unlock()
+
+	p := svc.Player()
+	sleep := svc.SleepTimer()
+	loop := svc.Loop()
+	snap := &Snapshot{
+		Status:   *status(svc),
+		Seekable: svc.Seekable(),
+		Sleep: Sleep{
+			Mode:      name(sleep.Mode),
+			After:     sleep.After.Seconds(),
+			Remaining: sleep.Remaining.Seconds(),
+			Fading:    sleep.Fading,
+		},
+		Loop:             Loop{A: loop.A.Seconds(), B: loop.B.Seconds()},
+		Chapters:         svc.Chapters(),
+		Info:             svc.TrackInfo(),
+		ReplayGain:       p.ReplayGain(),
+		Equalizer:        p.Equalizer(),
+		OutputSampleRate: p.OutputSampleRate(),
+		Daemon:           daemon,
+	}
+	if withQueue {
+		snap.Queue = newQueue(svc.QueueTracks(), svc.QueueCurrentIndex())
+	}
+	return snap
+}
+
+// status reads the playback state.
+func status(svc playback.Service) *Status {
+	p := svc.Player()
+	st := &Status{
+		State:       name(svc.State()),
+		Index:       svc.QueueCurrentIndex(),
+		QueueLength: svc.QueueLen(),
+		Position:    svc.Position().Seconds(),
+		Duration:    svc.Duration().Seconds(),
+		Volume:      int(math.Round(p.Volume() * 100)),
+		Muted:       p.Muted(),
+		Repeat:      name(svc.RepeatMode()),
+		Shuffle:     svc.Shuffle(),
+		Speed:       svc.Speed(),
+	}
+	if t := svc.CurrentTrack(); t != nil {
+		track := newTrack(*t)
+		st.Track = &track
+	}
+	return st
+}
+
+// follow streams the events of the playback service to a client until it
+// disconnects or the server closes.
+func (s *Server) follow(conn net.Conn, enc *json.Encoder) {
+	// Clients send nothing more: a read returns when they disconnect
+	gone := make(chan struct{})
+	go func() {
+		_, _ = conn.Read(make([]byte, 1))
+		close(gone)
+	}()
+
+	svc, replaced := s.svc.Current()
+	for {
+		sub := svc.Subscribe()
+		err := forward(sub, enc, gone, replaced)
+		svc.Unsubscribe(sub)
+		if err != nil {
+			return
+		}
+		// The service was closed: follow the one replacing it
+		select {
+		case <-replaced:
+		case <-gone:
+			return
+		}
+		svc, replaced = s.svc.Current()
+	}
+}
+
+// errGone is returned by forward when the client disconnected.
+var errGone = errors.New("client disconnected")
+
+// forward writes the events of a subscription until it is closed or its
+// service is replaced.
+func forward(sub *playback.Subscription, enc *json.Encoder, gone, replaced <-chan struct{}) error {
+	for {
+		var e any
+		select {
+		case <-gone:
+			return errGone
+		case <-sub.Done:
+			return nil
+		case <-replaced:
+			return nil
+		case e = <-sub.StateChanged:
+		case e = <-sub.TrackChanged:
+		case e = <-sub.PositionChanged:
+		case e = <-sub.QueueChanged:
+		case e = <-sub.ModeChanged:
+		case e = <-sub.VolumeChanged:
+		case e = <-sub.Error:
+		}
+		ev, ok := newEvent(e)
+		if !ok {
+			continue
+		}
+		if err := enc.Encode(ev); err != nil {
+			return err
+		}
+	}
+}
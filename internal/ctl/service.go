@@ -0,0 +1,178 @@
+package ctl
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/player"
+)
+
+// errUnknown is returned by handleService for commands it doesn't know.
+var errUnknown = errors.New("unknown command")
+
+// handleService runs the commands mapping to a method of the playback
+// service, used by attached interfaces.
+func handleService(svc playback.Service, req Request) error {
+	switch req.Command {
+	case CmdStart:
+		return svc.Play()
+	case CmdPlayPath:
+		if len(req.Paths) != 1 {
+			return errors.New("play_path takes one path")
+		}
+		return svc.PlayPath(req.Paths[0])
+	case CmdJump:
+		if req.Index == nil {
+			return errors.New("no index given")
+		}
+		return svc.JumpTo(*req.Index)
+	case CmdSelect:
+		if req.Index == nil {
+			return errors.New("no index given")
+		}
+		if svc.QueueMoveTo(*req.Index) == nil {
+			return playback.ErrInvalidIndex
+		}
+	case CmdAdvance:
+		svc.QueueAdvance()
+	case CmdRemove:
+		svc.RemoveTracks(req.Indices...)
+	case CmdClear:
+		svc.ClearQueue()
+	case CmdReorder:
+		reorder(svc, req.Paths)
+	case CmdRepeat:
+		return setRepeat(svc, req.Mode)
+	case CmdShuffle:
+		if req.Enabled == nil {
+			svc.ToggleShuffle()
+		} else {
+			svc.SetShuffle(*req.Enabled)
+		}
+	case CmdSpeed:
+		if req.Value < player.MinSpeed || req.Value > player.MaxSpeed {
+			return errors.New("speed out of range")
+		}
+		svc.SetSpeed(req.Value)
+	case CmdSleep:
+		mode, ok := sleepModes[req.Mode]
+		if !ok {
+			return fmt.Errorf("unknown sleep mode %q", req.Mode)
+		}
+		svc.SetSleepTimer(mode, seconds(req.Value))
+	case CmdLoop:
+		if req.Loop == nil {
+			svc.ClearLoop()
+			return nil
+		}
+		return svc.SetLoop(playback.Loop{A: seconds(req.Loop.A), B: seconds(req.Loop.B)})
+	case CmdEqualizer:
+		if req.Equalizer == nil {
+			return errors.New("no equalizer settings given")
+		}
+		svc.Player().SetEqualizer(*req.Equalizer)
+	case CmdCrossfade:
+		if req.Crossfade == nil {
+			return errors.New("no crossfade settings given")
+		}
+		svc.Player().SetCrossfade(*req.Crossfade)
+	case CmdReplayGain:
+		if req.ReplayGain == nil {
+			return errors.New("no replay gain settings given")
+		}
+		svc.Player().SetReplayGain(*req.ReplayGain)
+	case CmdChapter:
+		if req.Value < 0 {
+			return svc.PreviousChapter()
+		}
+		return svc.NextChapter()
+	default:
+		return errUnknown
+	}
+	return nil
+}
+
+// editQueue runs the queue edits that can leave the queue unchanged.
+func editQueue(svc playback.Service, req Request) Response {
+	var changed bool
+	switch req.Command {
+	case CmdMove:
+		changed = svc.MoveTracks(req.Indices, int(req.Value))
+	case CmdUndo:
+		changed = svc.Undo()
+	case CmdRedo:
+		changed = svc.Redo()
+	}
+	return Response{OK: true, Changed: changed, Status: status(svc)}
+}
+
+// setRepeat sets the repeat mode, or cycles it when mode is empty.
+func setRepeat(svc playback.Service, mode string) error {
+	if mode == "" {
+		svc.CycleRepeatMode()
+		return nil
+	}
+	m, ok := repeatModes[mode]
+	if !ok {
+		return fmt.Errorf("unknown repeat mode %q", mode)
+	}
+	svc.SetRepeatMode(m)
+	return nil
+}
+
+// reorder removes and moves the queue tracks to follow paths, the order an
+// attached interface left its copy of the queue in. Tracks are matched by
+// path, and paths missing from the queue are ignored.
+func reorder(svc playback.Service, paths []string) {
+	// Remove the tracks left out, matching repeated paths in order
+	left := make(map[string]int, len(paths))
+	for _, path := range paths {
+		left[path]++
+	}
+	var removed []int
+	for i, t := range svc.QueueTracks() {
+		if left[t.Path] > 0 {
+			left[t.Path]--
+			continue
+		}
+		removed = append(removed, i)
+	}
+	if len(removed) > 0 {
+		svc.RemoveTracks(removed...)
+	}
+
+	// Move the tracks kept into their place, one by one
+	for i := 0; ; i++ {
+		tracks := svc.QueueTracks()
+		order := kept(paths, tracks)
+		if i >= len(order) {
+			return
+		}
+		if tracks[i].Path == order[i] {
+			continue
+		}
+		for j := i + 1; j < len(tracks); j++ {
+			if tracks[j].Path == order[i] {
+				svc.MoveTracks([]int{j}, i-j)
+				break
+			}
+		}
+	}
+}
+
+// kept returns the paths found in the tracks, in their order.
+func kept(paths []string, tracks []playback.Track) []string {
+	count := make(map[string]int, len(tracks))
+	for _, t := range tracks {
+		count[t.Path]++
+	}
+	order := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if count[path] > 0 {
+			count[path]--
+			order = append(order, path)
+		}
+	}
+	return order
+}
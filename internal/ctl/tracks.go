@@ -0,0 +1,37 @@
+package ctl
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/llehouerou/waves/internal/library"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/playlist"
+)
+
+// collectTracks returns the tracks of music files and folders, in order.
+// Files in the library take their metadata from it, others from their tags.
+func collectTracks(lib Library, paths []string) ([]playback.Track, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("no path given")
+	}
+	for _, path := range paths {
+		if !filepath.IsAbs(path) {
+			return nil, fmt.Errorf("%s: path is not absolute", path)
+		}
+	}
+
+	var lookup func(string) (*library.Track, error)
+	if lib != nil {
+		lookup = lib.TrackByPath
+	}
+	tracks, err := playlist.CollectFromPaths(paths, lookup)
+	if err != nil {
+		return nil, err
+	}
+	if len(tracks) == 0 {
+		return nil, errors.New("no music files found")
+	}
+	return playback.TracksFromPlaylist(tracks), nil
+}
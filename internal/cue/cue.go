@@ -0,0 +1,306 @@
+// Package cue parses CUE sheets and addresses the tracks of single-file
+// album rips as virtual tracks.
+package cue
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// FramesPerSecond is the CD frame rate used by CUE sheet timestamps.
+const FramesPerSecond = 75
+
+// Frames is a position in CD frames (1/75 s).
+type Frames int64
+
+// Duration converts the position to a duration.
+func (f Frames) Duration() time.Duration {
+	return time.Duration(f) * time.Second / FramesPerSecond
+}
+
+// Samples converts the position to a sample offset at the given rate.
+func (f Frames) Samples(sampleRate int) int {
+	return int(int64(f) * int64(sampleRate) / FramesPerSecond)
+}
+
+// Index is an INDEX point of a track.
+type Index struct {
+	Number int // 0 is the pregap, 1 the start of the track
+	Offset Frames
+}
+
+// Track is a TRACK entry of a sheet.
+type Track struct {
+	Number     int
+	Title      string
+	Performer  string
+	Songwriter string
+	ISRC       string
+	Indexes    []Index
+	Rem        map[string]string // REM comments, keys uppercase
+
+	// End is where the track stops in its file: the start of the next
+	// track, or 0 for the last track of the file (end of file).
+	End Frames
+}
+
+// Start returns INDEX 01, or the first index if there is none.
+func (t *Track) Start() Frames {
+	for _, idx := range t.Indexes {
+		if idx.Number == 1 {
+			return idx.Offset
+		}
+	}
+	if len(t.Indexes) > 0 {
+		return t.Indexes[0].Offset
+	}
+	return 0
+}
+
+// File is a FILE entry of a sheet, with its tracks.
+type File struct {
+	Name   string // As written in the sheet, usually relative to it
+	Type   string // WAVE, MP3, AIFF, ...
+	Tracks []Track
+}
+
+// Sheet is a parsed CUE sheet.
+type Sheet struct {
+	Performer  string
+	Title      string
+	Songwriter string
+	Catalog    string
+	Rem        map[string]string // REM comments, keys uppercase (GENRE, DATE, ...)
+	Files      []File
+}
+
+// Track returns the track with the given number and its file, or nil.
+func (s *Sheet) Track(number int) (*File, *Track) {
+	for i := range s.Files {
+		for j := range s.Files[i].Tracks {
+			if s.Files[i].Tracks[j].Number == number {
+				return &s.Files[i], &s.Files[i].Tracks[j]
+			}
+		}
+	}
+	return nil, nil
+}
+
+// TrackCount returns the number of tracks in the sheet.
+func (s *Sheet) TrackCount() int {
+	n := 0
+	for _, f := range s.Files {
+		n += len(f.Tracks)
+	}
+	return n
+}
+
+// ParseFile parses the CUE sheet at path.
+func ParseFile(path string) (*Sheet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(bytes.NewReader(data))
+}
+
+// Parse parses a CUE sheet. Sheets that are not valid UTF-8 are read as
+// Windows-1252, which is what most rippers write.
+func Parse(r io.Reader) (*Sheet, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+	if !utf8.Valid(data) {
+		if data, err = charmap.Windows1252.NewDecoder().Bytes(data); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet := &Sheet{Rem: make(map[string]string)}
+	var file *File
+	var track *Track
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		fields := splitFields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		cmd := strings.ToUpper(fields[0])
+		args := fields[1:]
+
+		switch cmd {
+		case "FILE":
+			if len(args) < 1 {
+				return nil, fmt.Errorf("cue: line %d: FILE without name", lineNum)
+			}
+			f := File{Name: args[0]}
+			if len(args) > 1 {
+				f.Type = strings.ToUpper(args[1])
+			}
+			sheet.Files = append(sheet.Files, f)
+			file = &sheet.Files[len(sheet.Files)-1]
+			track = nil
+		case "TRACK":
+			if file == nil {
+				return nil, fmt.Errorf("cue: line %d: TRACK before FILE", lineNum)
+			}
+			if len(args) < 1 {
+				return nil, fmt.Errorf("cue: line %d: TRACK without number", lineNum)
+			}
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("cue: line %d: invalid track number %q", lineNum, args[0])
+			}
+			file.Tracks = append(file.Tracks, Track{Number: n, Rem: make(map[string]string)})
+			track = &file.Tracks[len(file.Tracks)-1]
+		case "INDEX":
+			if track == nil {
+				return nil, fmt.Errorf("cue: line %d: INDEX outside of a track", lineNum)
+			}
+			if len(args) < 2 {
+				return nil, fmt.Errorf("cue: line %d: incomplete INDEX", lineNum)
+			}
+			n, err := strconv.Atoi(args[0])
+			if err != nil {
+				return nil, fmt.Errorf("cue: line %d: invalid index number %q", lineNum, args[0])
+			}
+			offset, err := parseTimestamp(args[1])
+			if err != nil {
+				return nil, fmt.Errorf("cue: line %d: %w", lineNum, err)
+			}
+			track.Indexes = append(track.Indexes, Index{Number: n, Offset: offset})
+		case "TITLE", "PERFORMER", "SONGWRITER":
+			if len(args) < 1 {
+				continue
+			}
+			setText(sheet, track, cmd, strings.Join(args, " "))
+		case "ISRC":
+			if track != nil && len(args) > 0 {
+				track.ISRC = args[0]
+			}
+		case "CATALOG":
+			if len(args) > 0 {
+				sheet.Catalog = args[0]
+			}
+		case "REM":
+			if len(args) < 2 {
+				continue
+			}
+			key := strings.ToUpper(args[0])
+			value := strings.Join(args[1:], " ")
+			if track != nil {
+				track.Rem[key] = value
+			} else {
+				sheet.Rem[key] = value
+			}
+		}
+		// PREGAP, POSTGAP, FLAGS and CDTEXTFILE don't matter for playback
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if sheet.TrackCount() == 0 {
+		return nil, errors.New("cue: no tracks")
+	}
+	for i := range sheet.Files {
+		setEnds(sheet.Files[i].Tracks)
+	}
+	return sheet, nil
+}
+
+// setText sets a TITLE, PERFORMER or SONGWRITER of the current track, or
+// of the sheet before the first track.
+func setText(sheet *Sheet, track *Track, cmd, value string) {
+	if track != nil {
+		switch cmd {
+		case "TITLE":
+			track.Title = value
+		case "PERFORMER":
+			track.Performer = value
+		case "SONGWRITER":
+			track.Songwriter = value
+		}
+		return
+	}
+	switch cmd {
+	case "TITLE":
+		sheet.Title = value
+	case "PERFORMER":
+		sheet.Performer = value
+	case "SONGWRITER":
+		sheet.Songwriter = value
+	}
+}
+
+// setEnds makes each track end where the next one starts, so that the
+// pregap of a track is played at the end of the previous one and
+// consecutive tracks join without a gap.
+func setEnds(tracks []Track) {
+	for i := range tracks {
+		if i+1 < len(tracks) {
+			tracks[i].End = tracks[i+1].Start()
+		}
+	}
+}
+
+// parseTimestamp parses an mm:ss:ff timestamp.
+func parseTimestamp(s string) (Frames, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	var v [3]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		v[i] = n
+	}
+	if v[1] >= 60 || v[2] >= FramesPerSecond {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	return Frames((v[0]*60+v[1])*FramesPerSecond + v[2]), nil
+}
+
+// splitFields splits a line on spaces, keeping double quoted strings
+// together and unquoted.
+func splitFields(line string) []string {
+	var fields []string
+	line = strings.TrimSpace(line)
+	for line != "" {
+		if line[0] == '"' {
+			end := strings.IndexByte(line[1:], '"')
+			if end < 0 {
+				// Unterminated quote, take the rest of the line
+				fields = append(fields, line[1:])
+				break
+			}
+			fields = append(fields, line[1:end+1])
+			line = strings.TrimLeft(line[end+2:], " \t")
+			continue
+		}
+		end := strings.IndexAny(line, " \t")
+		if end < 0 {
+			fields = append(fields, line)
+			break
+		}
+		fields = append(fields, line[:end])
+		line = strings.TrimLeft(line[end:], " \t")
+	}
+	return fields
+}
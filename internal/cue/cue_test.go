@@ -0,0 +1,171 @@
+package cue
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const albumSheet = `REM GENRE "Progressive Rock"
+REM DATE 1973
+REM REPLAYGAIN_ALBUM_GAIN -7.20 dB
+PERFORMER "Pink Floyd"
+TITLE "The Dark Side of the Moon"
+FILE "Album.wav" WAVE
+  TRACK 01 AUDIO
+    TITLE "Speak to Me"
+    INDEX 01 00:00:00
+  TRACK 02 AUDIO
+    TITLE "Breathe"
+    PERFORMER "Pink Floyd feat. Someone"
+    ISRC GBN9Y1100001
+    REM REPLAYGAIN_TRACK_GAIN -6.50 dB
+    INDEX 00 01:05:10
+    INDEX 01 01:07:00
+  TRACK 03 AUDIO
+    TITLE "On the Run"
+    INDEX 01 03:50:74
+`
+
+func TestParse_Album(t *testing.T) {
+	sheet, err := Parse(strings.NewReader(albumSheet))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Pink Floyd", sheet.Performer)
+	assert.Equal(t, "The Dark Side of the Moon", sheet.Title)
+	assert.Equal(t, "Progressive Rock", sheet.Rem["GENRE"])
+	assert.Equal(t, "1973", sheet.Rem["DATE"])
+	assert.Equal(t, "-7.20 dB", sheet.Rem["REPLAYGAIN_ALBUM_GAIN"])
+	require.Len(t, sheet.Files, 1)
+	assert.Equal(t, "Album.wav", sheet.Files[0].Name)
+	assert.Equal(t, "WAVE", sheet.Files[0].Type)
+	assert.Equal(t, 3, sheet.TrackCount())
+
+	_, track := sheet.Track(2)
+	require.NotNil(t, track)
+	assert.Equal(t, "Breathe", track.Title)
+	assert.Equal(t, "Pink Floyd feat. Someone", track.Performer)
+	assert.Equal(t, "GBN9Y1100001", track.ISRC)
+	assert.Equal(t, "-6.50 dB", track.Rem["REPLAYGAIN_TRACK_GAIN"])
+	assert.Equal(t, []Index{{0, 65*75 + 10}, {1, 67 * 75}}, track.Indexes)
+	assert.Equal(t, Frames(67*75), track.Start())
+	assert.Equal(t, Frames(230*75+74), track.End, "ends where the next track starts")
+
+	_, first := sheet.Track(1)
+	assert.Equal(t, Frames(67*75), first.End, "the pregap of the next track is played at the end")
+
+	_, last := sheet.Track(3)
+	assert.Equal(t, Frames(0), last.End, "the last track runs to the end of the file")
+
+	file, missing := sheet.Track(4)
+	assert.Nil(t, file)
+	assert.Nil(t, missing)
+}
+
+func TestParse_Windows1252(t *testing.T) {
+	data := "PERFORMER \"Bj\xf6rk\"\nFILE \"a.flac\" WAVE\nTRACK 01 AUDIO\nTITLE \"J\xf3ga\"\nINDEX 01 00:00:00\n"
+	sheet, err := Parse(strings.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "Björk", sheet.Performer)
+	assert.Equal(t, "Jóga", sheet.Files[0].Tracks[0].Title)
+}
+
+func TestParse_BOMAndCRLF(t *testing.T) {
+	data := "\xEF\xBB\xBFTITLE \"Album\"\r\nFILE \"a.flac\" WAVE\r\n  TRACK 01 AUDIO\r\n    INDEX 01 00:00:00\r\n"
+	sheet, err := Parse(strings.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "Album", sheet.Title)
+	assert.Equal(t, 1, sheet.TrackCount())
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"no tracks", "TITLE \"Album\"\n"},
+		{"track before file", "TRACK 01 AUDIO\n"},
+		{"index outside track", "FILE \"a.flac\" WAVE\nINDEX 01 00:00:00\n"},
+		{"bad timestamp", "FILE \"a.flac\" WAVE\nTRACK 01 AUDIO\nINDEX 01 00:00:75\n"},
+		{"bad track number", "FILE \"a.flac\" WAVE\nTRACK x AUDIO\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFrames(t *testing.T) {
+	f := Frames(67*75 + 15)
+	assert.Equal(t, 67*time.Second+200*time.Millisecond, f.Duration())
+	assert.Equal(t, (67*75+15)*588, f.Samples(44100), "CD frames are 588 samples")
+	assert.Equal(t, 67*48000+9600, f.Samples(48000))
+}
+
+func TestSection_Samples(t *testing.T) {
+	s := Section{Start: Frames(75 + 1).Duration(), End: 0}
+	start, end := s.Samples(44100)
+	assert.Equal(t, 76*588, start, "rounded to the sample of the frame")
+	assert.Zero(t, end, "the end of the file")
+
+	// Sections kept in milliseconds
+	s = Section{Start: 1500 * time.Millisecond, End: 3 * time.Second}
+	start, end = s.Samples(48000)
+	assert.Equal(t, 72000, start)
+	assert.Equal(t, 144000, end)
+}
+
+func TestSplitFields(t *testing.T) {
+	assert.Equal(t, []string{"FILE", "My Album.flac", "WAVE"}, splitFields(`  FILE "My Album.flac" WAVE`))
+	assert.Equal(t, []string{"TITLE", "Unterminated"}, splitFields(`TITLE "Unterminated`))
+	assert.Equal(t, []string{"TITLE", ""}, splitFields(`TITLE ""`))
+	assert.Empty(t, splitFields("   "))
+}
+
+func TestTrackPath(t *testing.T) {
+	path := TrackPath("/music/Album/Album.cue", 12)
+	assert.Equal(t, "/music/Album/Album.cue#12", path)
+
+	sheet, n, ok := SplitTrackPath(path)
+	assert.True(t, ok)
+	assert.Equal(t, "/music/Album/Album.cue", sheet)
+	assert.Equal(t, 12, n)
+
+	for _, p := range []string{"/music/a.flac", "/music/#1/a.flac", "/music/a.cue", "/music/a.cue#x", "/music/a.flac#2"} {
+		assert.False(t, IsTrackPath(p), p)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	dir := t.TempDir()
+	sheetPath := filepath.Join(dir, "Album.cue")
+	require.NoError(t, os.WriteFile(sheetPath, []byte(albumSheet), 0o600))
+	// The sheet names the WAV the album was ripped to, it was compressed since
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Album.log"), nil, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Album.flac"), nil, 0o600))
+
+	ref, err := Lookup(TrackPath(sheetPath, 2))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "Album.flac"), ref.AudioPath)
+	assert.Equal(t, "Breathe", ref.Track.Title)
+	assert.Equal(t, "Pink Floyd", ref.Sheet.Performer)
+	assert.Equal(t, Section{
+		AudioPath: ref.AudioPath,
+		Start:     67 * time.Second,
+		End:       Frames(230*75 + 74).Duration(),
+	}, ref.Section(), "INDEX 01 to the start of the next track")
+
+	_, err = Lookup(TrackPath(sheetPath, 9))
+	assert.Error(t, err)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "Album.flac")))
+	_, err = Lookup(TrackPath(sheetPath, 2))
+	assert.ErrorContains(t, err, "audio file not found")
+}
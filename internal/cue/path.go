@@ -0,0 +1,152 @@
+package cue
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ext is the extension of CUE sheet files.
+const Ext = ".cue"
+
+// audioExts are the extensions tried when the file named in a sheet is
+// missing. It matches the formats of tags.IsMusicFile, which this package
+// can't import.
+var audioExts = []string{
+	".flac", ".wav", ".wave", ".wv", ".aif", ".aiff", ".aifc",
+	".mp3", ".m4a", ".m4b", ".mp4", ".ogg", ".oga", ".opus",
+}
+
+// IsSheet returns true if the path has the CUE sheet extension.
+func IsSheet(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), Ext)
+}
+
+// TrackPath returns the virtual path of a track of a sheet, e.g.
+// "/music/Album/Album.cue#3". It is used wherever a file path is expected.
+func TrackPath(sheetPath string, number int) string {
+	return sheetPath + "#" + strconv.Itoa(number)
+}
+
+// SplitTrackPath splits a virtual track path into the sheet path and the
+// track number. ok is false for regular file paths.
+func SplitTrackPath(path string) (sheetPath string, number int, ok bool) {
+	i := strings.LastIndexByte(path, '#')
+	if i < 0 || !IsSheet(path[:i]) {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(path[i+1:])
+	if err != nil || n < 1 {
+		return "", 0, false
+	}
+	return path[:i], n, true
+}
+
+// IsTrackPath returns true if path is a virtual track path.
+func IsTrackPath(path string) bool {
+	_, _, ok := SplitTrackPath(path)
+	return ok
+}
+
+// AudioPath returns the path of the audio file a FILE entry refers to.
+// Sheets often keep the name of the file they were ripped to, so if it
+// doesn't exist a file with the same name and another extension is used
+// (e.g. "Album.wav" that was later compressed to "Album.flac").
+func AudioPath(sheetPath string, f *File) (string, error) {
+	dir := filepath.Dir(sheetPath)
+	// Sheets written on Windows use backslashes
+	name := strings.ReplaceAll(f.Name, `\`, "/")
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	stem := strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, e := range entries {
+		ext := strings.ToLower(filepath.Ext(e.Name()))
+		if e.IsDir() || !slices.Contains(audioExts, ext) {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSuffix(e.Name(), filepath.Ext(e.Name())), stem) {
+			return filepath.Join(dir, e.Name()), nil
+		}
+	}
+	return "", fmt.Errorf("cue: audio file not found: %s", f.Name)
+}
+
+// Ref is a track of a sheet, with its audio file resolved.
+type Ref struct {
+	SheetPath string
+	Sheet     *Sheet
+	File      *File
+	Track     *Track
+	AudioPath string
+}
+
+// Section returns the part of its audio file the track plays.
+func (r *Ref) Section() Section {
+	return Section{
+		AudioPath: r.AudioPath,
+		Start:     r.Track.Start().Duration(),
+		End:       r.Track.End.Duration(),
+	}
+}
+
+// Section is the part of an audio file a virtual track plays. The library
+// keeps the sections of the tracks it scanned, so that playing them doesn't
+// parse their sheet.
+type Section struct {
+	AudioPath string
+	Start     time.Duration
+	End       time.Duration // 0 for the end of the file
+}
+
+// Samples returns the sample offsets of the section at the given rate, end
+// being 0 for the end of the file.
+func (s Section) Samples(sampleRate int) (start, end int) {
+	return samples(s.Start, sampleRate), samples(s.End, sampleRate)
+}
+
+// samples converts a duration to the nearest sample offset.
+func samples(d time.Duration, sampleRate int) int {
+	return int((int64(d)*int64(sampleRate) + int64(time.Second)/2) / int64(time.Second))
+}
+
+// Lookup parses the sheet of a virtual track path and resolves the track.
+func Lookup(path string) (*Ref, error) {
+	sheetPath, number, ok := SplitTrackPath(path)
+	if !ok {
+		return nil, errors.New("cue: not a track path")
+	}
+	sheet, err := ParseFile(sheetPath)
+	if err != nil {
+		return nil, err
+	}
+	file, track := sheet.Track(number)
+	if track == nil {
+		return nil, fmt.Errorf("cue: no track %d in %s", number, filepath.Base(sheetPath))
+	}
+	audioPath, err := AudioPath(sheetPath, file)
+	if err != nil {
+		return nil, err
+	}
+	return &Ref{
+		SheetPath: sheetPath,
+		Sheet:     sheet,
+		File:      file,
+		Track:     track,
+		AudioPath: audioPath,
+	}, nil
+}
@@ -0,0 +1,205 @@
+// Package daemon runs waves without its interface. The daemon owns the
+// playback: it restores and saves the queue, records the history, scrobbles
+// to Last.fm, fills the queue in radio mode, manages the Soulseek downloads
+// and serves the control socket, MPRIS, MPD and the HTTP API. Interfaces
+// attach to it over the control socket (see ctl.Remote), and playback goes
+// on when they detach.
+package daemon
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/llehouerou/waves/internal/config"
+	"github.com/llehouerou/waves/internal/ctl"
+	"github.com/llehouerou/waves/internal/downloads"
+	"github.com/llehouerou/waves/internal/history"
+	"github.com/llehouerou/waves/internal/httpapi"
+	"github.com/llehouerou/waves/internal/lastfm"
+	"github.com/llehouerou/waves/internal/library"
+	"github.com/llehouerou/waves/internal/mpd"
+	"github.com/llehouerou/waves/internal/mpris"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/playlists"
+	"github.com/llehouerou/waves/internal/radio"
+	"github.com/llehouerou/waves/internal/state"
+)
+
+// Daemon is a running waves daemon.
+type Daemon struct {
+	svc       playback.Service
+	stateMgr  *state.Manager
+	recorder  *history.Recorder
+	scrobbler *scrobbler      // nil without Last.fm config
+	radio     *radioFiller    // nil without Last.fm config
+	downloads *downloadSyncer // nil without slskd config
+
+	ctlServer    *ctl.Server
+	mprisAdapter *mpris.Adapter
+	mpdServer    *mpd.Server
+	httpServer   *httpapi.Server
+	errs         []error // Optional servers that failed to start
+
+	saved    sync.WaitGroup // Queue saving
+	shutdown chan struct{}
+	once     sync.Once
+}
+
+// New starts a daemon playing the queue saved in the state. It returns
+// ctl.ErrRunning when another waves serves the control socket.
+func New(cfg *config.Config, stateMgr *state.Manager) (*Daemon, error) {
+	path, err := ctl.SocketPath()
+	if err != nil {
+		return nil, err
+	}
+	lib := library.New(stateMgr.DB())
+	pls := playlists.New(stateMgr.DB(), lib)
+	if err := lib.MigrateSources(cfg.LibrarySources); err != nil {
+		return nil, err
+	}
+
+	p, err := NewPlayer(cfg, stateMgr, lib)
+	if err != nil {
+		return nil, err
+	}
+	pb := NewPlayback(cfg, stateMgr.DB(), lib, p, RestoreQueue(stateMgr, lib))
+	svc := pb.Service
+	ctlServer, err := ctl.Listen(path, svc, lib, stateMgr)
+	if err != nil {
+		_ = svc.Close()
+		_ = p.Close()
+		return nil, err
+	}
+
+	d := &Daemon{
+		svc:       svc,
+		stateMgr:  stateMgr,
+		recorder:  pb.Recorder,
+		ctlServer: ctlServer,
+		shutdown:  make(chan struct{}),
+	}
+	ctlServer.OnShutdown(d.Shutdown)
+
+	if cfg.HasLastfmConfig() {
+		client := lastfm.New(cfg.Lastfm.APIKey, cfg.Lastfm.APISecret)
+		d.scrobbler = newScrobbler(client, stateMgr)
+		d.scrobbler.watch(svc)
+		d.radio = newRadioFiller(radio.New(stateMgr.DB(), client, lib, cfg.GetRadioConfig()), pls.FavoriteTrackIDs)
+		d.radio.watch(svc)
+	}
+
+	// Attached interfaces leave the downloads to the daemon
+	dlCtrl := NewDownloads(cfg, downloads.New(stateMgr.DB()))
+	ctlServer.SetDownloads(dlCtrl)
+	if cfg.HasSlskdConfig() {
+		d.downloads = newDownloadSyncer(dlCtrl)
+		d.downloads.watch(svc)
+	}
+	d.saveQueue()
+
+	// The other servers are optional, as they are for the interface
+	d.mprisAdapter, _ = mpris.New(svc, lib, stateMgr)
+	if mpdCfg := cfg.GetMPDConfig(); mpdCfg.Enabled {
+		if d.mpdServer, err = mpd.Listen(svc, mpd.Config{
+			Address:   mpdCfg.Address,
+			Password:  mpdCfg.Password,
+			Library:   lib,
+			Playlists: pls,
+			Volumes:   stateMgr,
+		}); err != nil {
+			d.errs = append(d.errs, fmt.Errorf("MPD server: %w", err))
+		}
+	}
+	if httpCfg := cfg.GetHTTPConfig(); httpCfg.Enabled {
+		if d.httpServer, err = httpapi.Listen(svc, httpapi.Config{
+			Address:   httpCfg.Address,
+			Token:     httpCfg.Token,
+			Library:   lib,
+			Playlists: pls,
+			Volumes:   stateMgr,
+		}); err != nil {
+			d.errs = append(d.errs, fmt.Errorf("HTTP API: %w", err))
+		}
+	}
+	return d, nil
+}
+
+// saveQueue saves the queue whenever it, the current track or the modes
+// change, until the service is closed.
+func (d *Daemon) saveQueue() {
+	sub := d.svc.Subscribe()
+	d.saved.Add(1)
+	go func() {
+		defer d.saved.Done()
+		for {
+			select {
+			case <-sub.Done:
+				return
+			case <-sub.QueueChanged:
+			case <-sub.TrackChanged:
+			case <-sub.ModeChanged:
+			case <-sub.StateChanged:
+				continue
+			case <-sub.PositionChanged:
+				continue
+			case <-sub.Error:
+				continue
+			}
+			_ = SaveQueue(d.stateMgr, d.svc)
+		}
+	}()
+}
+
+// Errors returns why the enabled servers that aren't running failed to
+// start.
+func (d *Daemon) Errors() []error {
+	return d.errs
+}
+
+// SocketPath returns the path of the control socket interfaces attach to.
+func (d *Daemon) SocketPath() string {
+	return d.ctlServer.Path()
+}
+
+// Shutdown asks the daemon to stop: Done is closed. "waves ctl shutdown"
+// calls it.
+func (d *Daemon) Shutdown() {
+	d.once.Do(func() { close(d.shutdown) })
+}
+
+// Done is closed when the daemon is asked to stop.
+func (d *Daemon) Done() <-chan struct{} {
+	return d.shutdown
+}
+
+// Close stops playback and the servers, saving the queue. The state is
+// left open for the caller to close.
+func (d *Daemon) Close() error {
+	_ = d.svc.Stop()
+	err := d.svc.Player().Close()
+	if d.mprisAdapter != nil {
+		_ = d.mprisAdapter.Close()
+	}
+	_ = d.ctlServer.Close()
+	if d.mpdServer != nil {
+		_ = d.mpdServer.Close()
+	}
+	if d.httpServer != nil {
+		_ = d.httpServer.Close()
+	}
+	_ = SaveQueue(d.stateMgr, d.svc)
+	// Let the play in progress be recorded before the database closes
+	_ = d.svc.Close()
+	d.saved.Wait()
+	d.recorder.Wait()
+	if d.scrobbler != nil {
+		d.scrobbler.wait()
+	}
+	if d.radio != nil {
+		d.radio.wait()
+	}
+	if d.downloads != nil {
+		d.downloads.wait()
+	}
+	return err
+}
@@ -0,0 +1,54 @@
+package daemon
+
+import (
+	"sync"
+	"time"
+
+	"github.com/llehouerou/waves/internal/downloads"
+	"github.com/llehouerou/waves/internal/playback"
+)
+
+// downloadSync is how often the downloads are synced with slskd, as the
+// interface does while showing them.
+const downloadSync = 3 * time.Second
+
+// downloadSyncer keeps the downloads synced with slskd, so that they are
+// followed while no interface is attached.
+type downloadSyncer struct {
+	ctrl     downloads.Control
+	interval time.Duration
+	wg       sync.WaitGroup
+}
+
+func newDownloadSyncer(ctrl downloads.Control) *downloadSyncer {
+	return &downloadSyncer{ctrl: ctrl, interval: downloadSync}
+}
+
+// watch syncs the downloads until the service is closed.
+func (s *downloadSyncer) watch(svc playback.Service) {
+	sub := svc.Subscribe()
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(sub)
+	}()
+}
+
+func (s *downloadSyncer) run(sub *playback.Subscription) {
+	tick := time.NewTicker(s.interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-sub.Done:
+			return
+		case <-tick.C:
+			// slskd may be down for a while, the next sync catches up
+			_ = s.ctrl.Sync()
+		}
+	}
+}
+
+// wait waits until the syncing of closed services is done.
+func (s *downloadSyncer) wait() {
+	s.wg.Wait()
+}
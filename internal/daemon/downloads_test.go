@@ -0,0 +1,54 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/llehouerou/waves/internal/downloads"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/playlist"
+)
+
+// syncCounter signals each sync of the downloads.
+type syncCounter struct {
+	downloads.Control
+	synced chan struct{}
+}
+
+func (c *syncCounter) Sync() error {
+	c.synced <- struct{}{}
+	return nil
+}
+
+func TestDownloadSyncer_SyncsUntilClosed(t *testing.T) {
+	svc := playback.New(player.NewMock(), playlist.NewQueue())
+	ctrl := &syncCounter{synced: make(chan struct{})}
+	s := newDownloadSyncer(ctrl)
+	s.interval = 10 * time.Millisecond
+	s.watch(svc)
+
+	for range 2 {
+		select {
+		case <-ctrl.synced:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the downloads weren't synced")
+		}
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		_ = svc.Close()
+		s.wait()
+		close(closed)
+	}()
+	for {
+		select {
+		case <-closed:
+			return
+		case <-ctrl.synced:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the syncing didn't stop with the service")
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package daemon
+
+import (
+	"sync"
+	"time"
+
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/radio"
+)
+
+// Radio fill timing, that of the interface.
+const (
+	radioCheck   = time.Second
+	radioNearEnd = 15 * time.Second // Fill when the last track has this left
+)
+
+// radioSource picks the tracks added in radio mode, see radio.Radio.
+type radioSource interface {
+	Enable()
+	Disable()
+	SetSeed(artist string)
+	CurrentSeed() string
+	AddToRecentlyPlayed(path, artist string)
+	Fill(seedArtist string, favorites map[int64]bool) radio.FillResult
+}
+
+// radioFiller fills the queue of a playback service in radio mode, as the
+// interface does when it owns the playback.
+type radioFiller struct {
+	radio     radioSource
+	favorites func() (map[int64]bool, error)
+	nearEnd   bool // The near end fill ran for the current track
+	wg        sync.WaitGroup
+}
+
+func newRadioFiller(r radioSource, favorites func() (map[int64]bool, error)) *radioFiller {
+	return &radioFiller{radio: r, favorites: favorites}
+}
+
+// watch fills the queue of a service until it is closed.
+func (f *radioFiller) watch(svc playback.Service) {
+	sub := svc.Subscribe()
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		f.run(svc, sub)
+	}()
+}
+
+func (f *radioFiller) run(svc playback.Service, sub *playback.Subscription) {
+	check := time.NewTicker(radioCheck)
+	defer check.Stop()
+
+	mode := svc.RepeatMode()
+	if mode == playback.RepeatRadio {
+		f.enable(svc)
+	}
+	for {
+		select {
+		case <-sub.Done:
+			return
+		case e := <-sub.ModeChanged:
+			switch {
+			case e.RepeatMode == mode:
+			case e.RepeatMode == playback.RepeatRadio:
+				f.enable(svc)
+				f.fillAtLast(svc)
+			case mode == playback.RepeatRadio:
+				f.radio.Disable()
+			}
+			mode = e.RepeatMode
+		case <-sub.TrackChanged:
+			f.nearEnd = false
+			f.fillAtLast(svc)
+		case e := <-sub.StateChanged:
+			if e.Previous == playback.StateStopped && e.Current == playback.StatePlaying {
+				f.nearEnd = false
+				f.fillAtLast(svc)
+			}
+		case <-check.C:
+			f.fillNearEnd(svc)
+		}
+	}
+}
+
+// enable starts radio mode, seeded with the artist of the current track.
+func (f *radioFiller) enable(svc playback.Service) {
+	f.radio.Enable()
+	if t := svc.CurrentTrack(); t != nil {
+		f.radio.SetSeed(t.Artist)
+	}
+}
+
+// fillAtLast fills the queue when its last track starts, fetching the next
+// tracks before it ends.
+func (f *radioFiller) fillAtLast(svc playback.Service) {
+	if svc.RepeatMode() != playback.RepeatRadio || svc.QueueIsEmpty() {
+		return
+	}
+	if svc.QueueCurrentIndex() >= svc.QueueLen()-1 {
+		f.fill(svc)
+	}
+}
+
+// fillNearEnd fills the queue once per track when the track ends soon with
+// nothing after it, which happens when the queue is edited while playing.
+func (f *radioFiller) fillNearEnd(svc playback.Service) {
+	if svc.RepeatMode() != playback.RepeatRadio || f.nearEnd || svc.QueueHasNext() {
+		return
+	}
+	duration, position := svc.Duration(), svc.Position()
+	if duration <= 0 || position <= 0 || duration-position > radioNearEnd {
+		return
+	}
+	f.nearEnd = true
+	f.fill(svc)
+}
+
+// fill adds the tracks picked from the seed to the queue, then moves the
+// seed to the artist of the last one.
+func (f *radioFiller) fill(svc playback.Service) {
+	seed := f.radio.CurrentSeed()
+	if seed == "" {
+		if t := svc.CurrentTrack(); t != nil {
+			seed = t.Artist
+			f.radio.SetSeed(seed)
+		}
+	}
+	if seed == "" {
+		return
+	}
+
+	favorites, _ := f.favorites()
+	result := f.radio.Fill(seed, favorites)
+	if result.Err != nil || len(result.Tracks) == 0 {
+		return
+	}
+	tracks := make([]playback.Track, len(result.Tracks))
+	for i, t := range result.Tracks {
+		tracks[i] = playback.TrackFromPlaylist(t)
+		f.radio.AddToRecentlyPlayed(t.Path, t.Artist)
+	}
+	svc.AddTracks(tracks...)
+	// The next track may have been preloaded before the queue grew
+	svc.Player().ClearPreload()
+	f.radio.SetSeed(tracks[len(tracks)-1].Artist)
+}
+
+// wait waits until the fills of closed services are done.
+func (f *radioFiller) wait() {
+	f.wg.Wait()
+}
@@ -0,0 +1,110 @@
+package daemon
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/playlist"
+	"github.com/llehouerou/waves/internal/radio"
+)
+
+// fakeRadio picks one track by the seed artist at each fill.
+type fakeRadio struct {
+	mu      sync.Mutex
+	enabled bool
+	seed    string
+	seeds   []string // Seeds filled from
+}
+
+func (r *fakeRadio) Enable() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.enabled = true
+}
+
+func (r *fakeRadio) Disable() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.enabled = false
+}
+
+func (r *fakeRadio) SetSeed(artist string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seed = artist
+}
+
+func (r *fakeRadio) CurrentSeed() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.seed
+}
+
+func (r *fakeRadio) AddToRecentlyPlayed(string, string) {}
+
+func (r *fakeRadio) Fill(seed string, _ map[int64]bool) radio.FillResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seeds = append(r.seeds, seed)
+	return radio.FillResult{Tracks: []playlist.Track{{Path: "/radio/" + seed + ".mp3", Artist: seed + " too"}}}
+}
+
+func (r *fakeRadio) state() (enabled bool, seeds []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.enabled, append([]string(nil), r.seeds...)
+}
+
+// waitFor polls cond until it holds or a few seconds pass.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRadioFiller_FillsLastTrack(t *testing.T) {
+	svc := playback.New(player.NewMock(), playlist.NewQueue())
+	r := &fakeRadio{}
+	f := newRadioFiller(r, func() (map[int64]bool, error) { return nil, nil })
+	f.watch(svc)
+	defer f.wait()
+	defer svc.Close()
+
+	svc.AddTracks(playback.Track{Path: "/a.mp3", Artist: "Blur"})
+	svc.QueueMoveTo(0)
+	if err := svc.Play(); err != nil {
+		t.Fatalf("Play() error: %v", err)
+	}
+	svc.SetRepeatMode(playback.RepeatRadio)
+
+	waitFor(t, "the radio fill", func() bool { return svc.QueueLen() == 2 })
+	if got := svc.QueueTracks()[1].Path; got != "/radio/Blur.mp3" {
+		t.Errorf("added %q, want /radio/Blur.mp3", got)
+	}
+	if enabled, seeds := r.state(); !enabled || len(seeds) != 1 {
+		t.Errorf("radio enabled %v, filled from %v; want enabled, one fill", enabled, seeds)
+	}
+	if r.CurrentSeed() != "Blur too" {
+		t.Errorf("seed = %q, want the artist of the added track", r.CurrentSeed())
+	}
+
+	// The added track is the last one, so starting it fills again
+	if err := svc.Next(); err != nil {
+		t.Fatalf("Next() error: %v", err)
+	}
+	waitFor(t, "the second fill", func() bool { return svc.QueueLen() == 3 })
+
+	svc.SetRepeatMode(playback.RepeatOff)
+	waitFor(t, "radio mode to stop", func() bool {
+		enabled, _ := r.state()
+		return !enabled
+	})
+}
@@ -0,0 +1,128 @@
+package daemon
+
+import (
+	"sync"
+	"time"
+
+	"github.com/llehouerou/waves/internal/lastfm"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/state"
+)
+
+// Scrobbling intervals, those of the interface.
+const (
+	scrobbleCheck = time.Second
+	scrobbleRetry = 5 * time.Minute
+)
+
+// scrobbler scrobbles the tracks played by a playback service to Last.fm,
+// as the interface does when it owns the playback.
+type scrobbler struct {
+	client   *lastfm.Client
+	stateMgr *state.Manager
+	current  lastfm.ScrobbleState // Track being played, no path if none
+	wg       sync.WaitGroup
+}
+
+func newScrobbler(client *lastfm.Client, stateMgr *state.Manager) *scrobbler {
+	return &scrobbler{client: client, stateMgr: stateMgr}
+}
+
+// watch scrobbles the plays of a service until it is closed.
+func (s *scrobbler) watch(svc playback.Service) {
+	sub := svc.Subscribe()
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(svc, sub)
+	}()
+}
+
+func (s *scrobbler) run(svc playback.Service, sub *playback.Subscription) {
+	check := time.NewTicker(scrobbleCheck)
+	defer check.Stop()
+	retry := time.NewTicker(scrobbleRetry)
+	defer retry.Stop()
+
+	s.retry()
+	for {
+		select {
+		case <-sub.Done:
+			return
+		case e := <-sub.TrackChanged:
+			if e.Current != nil {
+				s.start(e.Current.Path)
+			}
+		case e := <-sub.StateChanged:
+			if e.Previous == playback.StateStopped && e.Current == playback.StatePlaying {
+				if t := svc.CurrentTrack(); t != nil {
+					s.start(t.Path)
+				}
+			}
+		case <-check.C:
+			s.check(svc)
+		case <-retry.C:
+			s.retry()
+		}
+	}
+}
+
+// start resets the scrobble state for a new track.
+func (s *scrobbler) start(path string) {
+	s.current = lastfm.ScrobbleState{TrackPath: path, StartedAt: time.Now()}
+}
+
+// check scrobbles the current track once it played long enough, queueing
+// the scrobble for retry when it fails.
+func (s *scrobbler) check(svc playback.Service) {
+	if s.current.TrackPath == "" || s.current.Scrobbled {
+		return
+	}
+	threshold, ok := lastfm.ScrobbleThreshold(svc.Duration(), svc.Speed())
+	if !ok || svc.Position() < threshold {
+		return
+	}
+	s.current.Scrobbled = true
+	info := svc.TrackInfo()
+	if info == nil || !s.linked() {
+		return
+	}
+
+	track := lastfm.NewScrobbleTrack(info, svc.Duration(), s.current.StartedAt)
+	if s.client.Scrobble(track) == nil {
+		return
+	}
+	_ = s.stateMgr.AddPendingScrobble(state.PendingScrobble{
+		Artist:        track.Artist,
+		Track:         track.Track,
+		Album:         track.Album,
+		DurationSecs:  int(track.Duration.Seconds()),
+		Timestamp:     track.Timestamp,
+		MBRecordingID: track.MBRecordingID,
+	})
+}
+
+// retry retries the pending scrobbles.
+func (s *scrobbler) retry() {
+	if !s.linked() {
+		return
+	}
+	lastfm.RetryPendingCmd(lastfm.RetryPendingParams{Client: s.client, StateMgr: s.stateMgr})()
+}
+
+// linked reports whether Last.fm is linked, loading the session saved in
+// the state: it is linked from an attached interface.
+func (s *scrobbler) linked() bool {
+	if s.client.IsAuthenticated() {
+		return true
+	}
+	if sess, err := s.stateMgr.GetLastfmSession(); err == nil && sess != nil {
+		s.client.SetSessionKey(sess.SessionKey)
+	}
+	return s.client.IsAuthenticated()
+}
+
+// wait waits until the scrobbles of closed services are sent.
+func (s *scrobbler) wait() {
+	s.wg.Wait()
+}
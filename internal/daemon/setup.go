@@ -0,0 +1,196 @@
+package daemon
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/llehouerou/waves/internal/config"
+	"github.com/llehouerou/waves/internal/downloads"
+	"github.com/llehouerou/waves/internal/history"
+	"github.com/llehouerou/waves/internal/library"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/playlist"
+	"github.com/llehouerou/waves/internal/resume"
+	"github.com/llehouerou/waves/internal/slskd"
+	"github.com/llehouerou/waves/internal/state"
+)
+
+// NewPlayer creates the player from the config, with the volume and
+// equalizer saved in the state. The CUE sheet tracks of lib play the
+// sections it keeps.
+func NewPlayer(cfg *config.Config, stateMgr state.Interface, lib *library.Library) (*player.Player, error) {
+	outConfig := cfg.GetOutputConfig()
+	out, err := player.NewOutput(player.OutputConfig{
+		Backend: player.ParseOutputBackend(outConfig.Backend),
+		Path:    outConfig.Path,
+	})
+	if err != nil {
+		return nil, err
+	}
+	p := player.NewWithOutput(out)
+	p.SetCueSections(lib.CueSection)
+	p.SetOutputRate(player.OutputRateConfig{
+		Rate:            outConfig.SampleRate,
+		ResampleQuality: outConfig.ResampleQuality,
+	})
+
+	// Load volume from state
+	if volState, err := stateMgr.GetVolume(); err == nil {
+		p.SetVolume(volState.Volume)
+		p.SetMuted(volState.Muted)
+	}
+
+	ApplySettings(p, cfg, stateMgr)
+	return p, nil
+}
+
+// ApplySettings sets the replay gain and crossfade of the config, and the
+// equalizer saved in the state. Interfaces attaching to a daemon apply
+// theirs, so that changes take effect without restarting it.
+func ApplySettings(p player.Interface, cfg *config.Config, stateMgr state.Interface) {
+	// Configure loudness normalization
+	rgConfig := cfg.GetReplayGainConfig()
+	p.SetReplayGain(player.ReplayGainConfig{
+		Mode:            player.ParseReplayGainMode(rgConfig.Mode),
+		PreampDB:        rgConfig.Preamp,
+		PreventClipping: *rgConfig.PreventClipping,
+	})
+
+	// Configure crossfade between tracks
+	cfConfig := cfg.GetCrossfadeConfig()
+	p.SetCrossfade(player.CrossfadeConfig{
+		Duration: time.Duration(cfConfig.Duration * float64(time.Second)),
+		Curve:    player.ParseCrossfadeCurve(cfConfig.Curve),
+	})
+
+	// Load equalizer settings from state
+	if eqState, err := stateMgr.GetEqualizer(); err == nil {
+		p.SetEqualizer(eqState.Active())
+	}
+}
+
+// RestoreQueue returns the queue saved in the state, tracks of the library
+// taking their metadata from it. The queue is empty when none was saved.
+func RestoreQueue(stateMgr state.Interface, lib *library.Library) *playlist.PlayingQueue {
+	queue := playlist.NewQueue()
+	queueState, err := stateMgr.GetQueue()
+	if err != nil || queueState == nil {
+		return queue
+	}
+	for _, t := range queueState.Tracks {
+		track := playlist.Track{
+			ID:          t.TrackID,
+			Path:        t.Path,
+			Title:       t.Title,
+			Artist:      t.Artist,
+			Album:       t.Album,
+			TrackNumber: t.TrackNumber,
+		}
+		// Enrich with library metadata (genre, disc number, year)
+		if t.TrackID > 0 {
+			if lt, err := lib.TrackByID(t.TrackID); err == nil {
+				track = playlist.FromLibraryTrack(*lt)
+			}
+		}
+		queue.AddWithoutHistory(track)
+	}
+	if queueState.CurrentIndex >= 0 && queueState.CurrentIndex < queue.Len() {
+		queue.JumpTo(queueState.CurrentIndex)
+	}
+	queue.SetRepeatMode(playlist.RepeatMode(queueState.RepeatMode))
+	queue.SetShuffle(queueState.Shuffle)
+	queue.SaveToHistory() // Save loaded state as initial history entry
+	return queue
+}
+
+// SaveQueue saves the queue of a playback service in the state.
+func SaveQueue(stateMgr state.Interface, svc playback.Service) error {
+	tracks := svc.QueueTracks()
+	queueTracks := make([]state.QueueTrack, len(tracks))
+	for i, t := range tracks {
+		queueTracks[i] = state.QueueTrack{
+			TrackID:     t.ID,
+			Path:        t.Path,
+			Title:       t.Title,
+			Artist:      t.Artist,
+			Album:       t.Album,
+			TrackNumber: t.TrackNumber,
+		}
+	}
+	return stateMgr.SaveQueue(state.QueueState{
+		CurrentIndex: svc.QueueCurrentIndex(),
+		RepeatMode:   int(svc.RepeatMode()),
+		Shuffle:      svc.Shuffle(),
+		Tracks:       queueTracks,
+	})
+}
+
+// HistoryThreshold returns how much of a track must be played for the play
+// to count.
+func HistoryThreshold(cfg config.HistoryConfig) history.Threshold {
+	var t history.Threshold
+	if cfg.PlayedPercent > 0 {
+		t.Percent = cfg.PlayedPercent
+	}
+	if cfg.PlayedSeconds > 0 {
+		t.Duration = time.Duration(cfg.PlayedSeconds) * time.Second
+	}
+	return t
+}
+
+// NewDownloads creates the control of the downloads of manager, syncing
+// them with slskd when configured.
+func NewDownloads(cfg *config.Config, manager *downloads.Manager) *downloads.Local {
+	var client *slskd.Client
+	if cfg.HasSlskdConfig() {
+		client = slskd.NewClient(cfg.Slskd.URL, cfg.Slskd.APIKey)
+	}
+	return downloads.NewLocal(manager, client, cfg.Slskd.CompletedPath)
+}
+
+// Playback is the playback service of the daemon, or of an interface that
+// isn't attached to one, with what follows it.
+type Playback struct {
+	Service  playback.Service
+	Recorder *history.Recorder // Records plays to the history
+
+	player  player.Interface
+	resumer playback.Resumer // nil when disabled
+}
+
+// NewPlayback creates a service playing queue on p. It resumes audiobooks
+// and podcasts where they were left, records plays to the history, counting
+// them with counter when not nil, and preloads the next track for gapless
+// playback.
+func NewPlayback(cfg *config.Config, db *sql.DB, counter history.Counter, p player.Interface, queue *playlist.PlayingQueue) *Playback {
+	pb := &Playback{
+		Recorder: history.NewRecorder(history.NewStore(db)),
+		player:   p,
+	}
+	if resumeCfg := cfg.GetResumeConfig(); *resumeCfg.Enabled {
+		pb.resumer = resume.NewStore(db, resumeCfg.ToPolicy())
+	}
+	if counter != nil {
+		pb.Recorder.SetCounter(counter, HistoryThreshold(cfg.GetHistoryConfig()))
+	}
+	pb.bind(playback.New(p, queue))
+	return pb
+}
+
+// Replace closes the service and plays queue with a new one, which the
+// resume, history and preload follow. It returns the new service.
+func (pb *Playback) Replace(queue *playlist.PlayingQueue) playback.Service {
+	_ = pb.Service.Close()
+	pb.bind(playback.New(pb.player, queue))
+	return pb.Service
+}
+
+func (pb *Playback) bind(svc playback.Service) {
+	pb.Service = svc
+	if pb.resumer != nil {
+		svc.SetResumer(pb.resumer)
+	}
+	pb.Recorder.Watch(svc)
+	pb.player.SetPreloadFunc(svc.PreloadNext)
+}
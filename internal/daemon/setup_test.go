@@ -0,0 +1,128 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/llehouerou/waves/internal/config"
+	"github.com/llehouerou/waves/internal/equalizer"
+	"github.com/llehouerou/waves/internal/history"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/playlist"
+	"github.com/llehouerou/waves/internal/state"
+)
+
+// savingState keeps the queue saved.
+type savingState struct {
+	*state.Mock
+}
+
+func (s savingState) SaveQueue(q state.QueueState) error {
+	s.SetQueue(&q)
+	return nil
+}
+
+func TestSaveQueue_Restores(t *testing.T) {
+	queue := playlist.NewQueue()
+	svc := playback.New(player.NewMock(), queue)
+	defer svc.Close()
+	svc.AddTracks(
+		playback.Track{Path: "/music/a.mp3", Title: "A", Artist: "Blur", TrackNumber: 1},
+		playback.Track{Path: "/music/b.mp3", Title: "B", Artist: "Blur", TrackNumber: 2},
+	)
+	svc.QueueMoveTo(1)
+	svc.SetRepeatMode(playback.RepeatAll)
+	svc.SetShuffle(true)
+
+	stateMgr := savingState{state.NewMock()}
+	if err := SaveQueue(stateMgr, svc); err != nil {
+		t.Fatalf("SaveQueue() error: %v", err)
+	}
+	restored := RestoreQueue(stateMgr, nil)
+
+	if restored.Len() != 2 || restored.CurrentIndex() != 1 {
+		t.Fatalf("restored %d tracks at %d, want 2 at 1", restored.Len(), restored.CurrentIndex())
+	}
+	if got := restored.Track(1); got.Path != "/music/b.mp3" || got.Title != "B" || got.TrackNumber != 2 {
+		t.Errorf("restored track = %+v, want /music/b.mp3", got)
+	}
+	if restored.RepeatMode() != playlist.RepeatAll || !restored.Shuffle() {
+		t.Errorf("restored repeat %v, shuffle %v; want repeat all, shuffle", restored.RepeatMode(), restored.Shuffle())
+	}
+}
+
+func TestRestoreQueue_NothingSaved(t *testing.T) {
+	if q := RestoreQueue(state.NewMock(), nil); !q.IsEmpty() || q.CurrentIndex() != -1 {
+		t.Errorf("restored %d tracks at %d, want an empty queue", q.Len(), q.CurrentIndex())
+	}
+}
+
+func TestHistoryThreshold(t *testing.T) {
+	got := HistoryThreshold(config.HistoryConfig{PlayedPercent: 50, PlayedSeconds: 240})
+	want := history.Threshold{Percent: 50, Duration: 4 * time.Minute}
+	if got != want {
+		t.Errorf("HistoryThreshold() = %+v, want %+v", got, want)
+	}
+
+	// Disabled thresholds are left out
+	got = HistoryThreshold(config.HistoryConfig{PlayedPercent: -1, PlayedSeconds: 30})
+	want = history.Threshold{Duration: 30 * time.Second}
+	if got != want {
+		t.Errorf("HistoryThreshold() = %+v, want %+v", got, want)
+	}
+}
+
+func TestApplySettings(t *testing.T) {
+	stateMgr := state.NewMock()
+	eq := equalizer.DefaultState()
+	eq.Speakers.Enabled = true
+	eq.Speakers.PreampDB = -4
+	_ = stateMgr.SaveEqualizer(eq)
+	cfg := &config.Config{
+		ReplayGain: config.ReplayGainConfig{Mode: "album"},
+		Crossfade:  config.CrossfadeConfig{Duration: 2.5, Curve: "linear"},
+	}
+
+	p := player.NewMock()
+	ApplySettings(p, cfg, stateMgr)
+	if p.ReplayGain().Mode != player.ReplayGainAlbum {
+		t.Errorf("replay gain mode = %v, want album", p.ReplayGain().Mode)
+	}
+	if got := p.Crossfade(); got.Duration != 2500*time.Millisecond || got.Curve != player.CrossfadeLinear {
+		t.Errorf("crossfade = %+v, want 2.5s linear", got)
+	}
+	if got := p.Equalizer(); !got.Enabled || got.PreampDB != -4 {
+		t.Errorf("equalizer = %+v, want the saved speakers settings", got)
+	}
+}
+
+// preloadPlayer keeps the preload func.
+type preloadPlayer struct {
+	*player.Mock
+	preload func() (string, bool)
+}
+
+func (p *preloadPlayer) SetPreloadFunc(fn func() (string, bool)) {
+	p.preload = fn
+}
+
+func TestPlayback_PreloadFollowsReplacedService(t *testing.T) {
+	p := &preloadPlayer{Mock: player.NewMock()}
+	pb := NewPlayback(&config.Config{}, nil, nil, p, playlist.NewQueue())
+	if got, _ := p.preload(); got != "" {
+		t.Errorf("preload of an empty queue = %q, want none", got)
+	}
+
+	queue := playlist.NewQueue()
+	queue.Add(playlist.Track{Path: "/music/a.mp3"}, playlist.Track{Path: "/music/b.mp3"})
+	queue.JumpTo(0)
+	svc := pb.Replace(queue)
+	defer svc.Close()
+	if svc != pb.Service {
+		t.Error("Replace() didn't return the new service")
+	}
+	if got, _ := p.preload(); got != "/music/b.mp3" {
+		t.Errorf("preload = %q, want the next track of the new queue", got)
+	}
+}
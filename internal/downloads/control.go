@@ -0,0 +1,90 @@
+package downloads
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/llehouerou/waves/internal/slskd"
+)
+
+// ErrNotConfigured is returned when downloads are managed without slskd.
+var ErrNotConfigured = errors.New("slskd not configured")
+
+// Control manages the downloads. Local works on the database and slskd
+// directly, interfaces attached to a daemon use the daemon's over the
+// control socket.
+type Control interface {
+	// Create records a download queued on slskd, returning its ID.
+	Create(download Download) (int64, error)
+	// Delete cancels a download on slskd, then removes its files and record.
+	Delete(id int64) error
+	// ClearCompleted removes the records of the completed downloads.
+	ClearCompleted() error
+	// Sync updates the downloads from slskd and checks the completed files
+	// on disk.
+	Sync() error
+}
+
+// Local manages the downloads of a Manager with slskd.
+type Local struct {
+	manager       *Manager
+	client        *slskd.Client // nil without slskd config
+	completedPath string        // Where slskd puts completed files, "" to skip checks
+
+	syncMu sync.Mutex // Serializes Sync
+}
+
+// Verify Local implements Control at compile time.
+var _ Control = (*Local)(nil)
+
+// NewLocal creates a Local control. client may be nil when slskd isn't
+// configured: downloads can then only be deleted and cleared.
+func NewLocal(manager *Manager, client *slskd.Client, completedPath string) *Local {
+	return &Local{manager: manager, client: client, completedPath: completedPath}
+}
+
+func (l *Local) Create(download Download) (int64, error) {
+	return l.manager.Create(download)
+}
+
+func (l *Local) Delete(id int64) error {
+	download, err := l.manager.Get(id)
+	if err != nil {
+		return err
+	}
+
+	// Cancel downloads on slskd, by filename
+	if l.client != nil && download.SlskdUsername != "" {
+		for _, f := range download.Files {
+			_ = l.client.CancelDownload(download.SlskdUsername, f.Filename)
+		}
+	}
+	if l.completedPath != "" {
+		_ = DeleteFilesFromDisk(l.completedPath, download)
+	}
+	return l.manager.Delete(id)
+}
+
+func (l *Local) ClearCompleted() error {
+	return l.manager.DeleteCompleted()
+}
+
+func (l *Local) Sync() error {
+	if l.client == nil {
+		return ErrNotConfigured
+	}
+	l.syncMu.Lock()
+	defer l.syncMu.Unlock()
+
+	slskdDownloads, err := l.client.GetDownloads()
+	if err != nil {
+		return err
+	}
+	if err := l.manager.UpdateFromSlskd(slskdDownloads); err != nil {
+		return err
+	}
+	if l.completedPath != "" {
+		return l.manager.VerifyOnDisk(l.completedPath)
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+// Package equalizer defines the parametric equalizer settings shared between
+// the audio chain, persistence and UI layers, and implements the filters.
+package equalizer
+
+import (
+	"math"
+	"strings"
+)
+
+// Limits of the editable values.
+const (
+	MinFreq   = 20.0
+	MaxFreq   = 20000.0
+	MinGain   = -15.0
+	MaxGain   = 15.0
+	MinQ      = 0.1
+	MaxQ      = 10.0
+	MinPreamp = -24.0
+	MaxPreamp = 12.0
+	MaxBands  = 16
+)
+
+// BandType selects the filter shape of a band.
+type BandType int
+
+const (
+	Peaking   BandType = iota // Bell around Freq
+	LowShelf                  // Boost or cut below Freq
+	HighShelf                 // Boost or cut above Freq
+)
+
+// BandTypeCount is the total number of band types.
+const BandTypeCount = 3
+
+// String returns the short name of the band type.
+func (t BandType) String() string {
+	switch t {
+	case Peaking:
+		return "peak"
+	case LowShelf:
+		return "low shelf"
+	case HighShelf:
+		return "high shelf"
+	default:
+		return "unknown"
+	}
+}
+
+// Band is a single filter of the equalizer.
+type Band struct {
+	Type   BandType
+	Freq   float64 // Center or corner frequency in Hz
+	GainDB float64
+	Q      float64
+}
+
+// Settings is a complete equalizer configuration.
+type Settings struct {
+	Enabled  bool
+	PreampDB float64 // Applied before the bands, negative values leave headroom for boosts
+	Bands    []Band
+}
+
+// graphicFreqs are the ISO octave centers used by the default bands.
+var graphicFreqs = []float64{31, 62, 125, 250, 500, 1000, 2000, 4000, 8000, 16000}
+
+// graphicQ gives one octave wide bands.
+const graphicQ = 1.41
+
+// graphic returns a 10-band graphic equalizer with the given gains.
+func graphic(gains ...float64) []Band {
+	bands := make([]Band, len(graphicFreqs))
+	for i, f := range graphicFreqs {
+		bands[i] = Band{Type: Peaking, Freq: f, Q: graphicQ}
+		if i < len(gains) {
+			bands[i].GainDB = gains[i]
+		}
+	}
+	return bands
+}
+
+// Default returns a disabled, flat 10-band equalizer.
+func Default() Settings {
+	return Settings{Bands: graphic()}
+}
+
+// Clone returns a copy that does not share the band slice.
+func (s Settings) Clone() Settings {
+	s.Bands = append([]Band(nil), s.Bands...)
+	return s
+}
+
+// Normalize clamps all values to their limits and drops extra bands.
+func (s Settings) Normalize() Settings {
+	s = s.Clone()
+	if len(s.Bands) > MaxBands {
+		s.Bands = s.Bands[:MaxBands]
+	}
+	s.PreampDB = clamp(s.PreampDB, MinPreamp, MaxPreamp)
+	for i := range s.Bands {
+		b := &s.Bands[i]
+		if b.Type < 0 || b.Type >= BandTypeCount {
+			b.Type = Peaking
+		}
+		b.Freq = clamp(b.Freq, MinFreq, MaxFreq)
+		b.GainDB = clamp(b.GainDB, MinGain, MaxGain)
+		if b.Q == 0 {
+			b.Q = graphicQ
+		}
+		b.Q = clamp(b.Q, MinQ, MaxQ)
+	}
+	return s
+}
+
+// IsFlat returns true if the equalizer does not change the signal.
+func (s Settings) IsFlat() bool {
+	if s.PreampDB != 0 {
+		return false
+	}
+	for _, b := range s.Bands {
+		if b.GainDB != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// AutoPreamp returns the preamp that compensates the highest boost of the
+// bands, so that a full scale signal does not clip.
+func (s Settings) AutoPreamp() float64 {
+	peak := 0.0
+	for _, f := range responseFreqs() {
+		peak = max(peak, s.bandResponseDB(f))
+	}
+	// Round to a tenth of a dB, on the side of more headroom
+	return -math.Ceil(peak*10) / 10
+}
+
+// bandResponseDB returns the gain of all bands at freq, without preamp.
+func (s Settings) bandResponseDB(freq float64) float64 {
+	const rate = 48000
+	var db float64
+	for _, b := range s.Bands {
+		db += newBiquad(b, rate).responseDB(freq, rate)
+	}
+	return db
+}
+
+// ResponseDB returns the gain of the equalizer at freq, including preamp.
+func (s Settings) ResponseDB(freq float64) float64 {
+	return s.PreampDB + s.bandResponseDB(freq)
+}
+
+// responseFreqs returns log-spaced frequencies covering the audible range.
+func responseFreqs() []float64 {
+	const steps = 240
+	freqs := make([]float64, steps+1)
+	for i := range freqs {
+		freqs[i] = MinFreq * math.Pow(MaxFreq/MinFreq, float64(i)/steps)
+	}
+	return freqs
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return max(min(v, hi), lo)
+}
+
+// Preset is a named equalizer configuration.
+type Preset struct {
+	ID       int64
+	Name     string
+	Settings Settings
+}
+
+// BuiltinPresets returns the presets installed with the application.
+func BuiltinPresets() []Preset {
+	return []Preset{
+		{Name: "Flat", Settings: Settings{Enabled: true, Bands: graphic()}},
+		{Name: "Bass Boost", Settings: Settings{Enabled: true, PreampDB: -6, Bands: graphic(6, 5, 4, 2, 0, 0, 0, 0, 0, 0)}},
+		{Name: "Treble Boost", Settings: Settings{Enabled: true, PreampDB: -5, Bands: graphic(0, 0, 0, 0, 0, 0, 1, 3, 4, 5)}},
+		{Name: "Vocal", Settings: Settings{Enabled: true, PreampDB: -3, Bands: graphic(-2, -2, -1, 0, 2, 3, 3, 2, 0, -1)}},
+		{Name: "Loudness", Settings: Settings{Enabled: true, PreampDB: -5, Bands: graphic(5, 4, 2, 0, -1, 0, 0, 1, 3, 4)}},
+	}
+}
+
+// Profile identifies an output profile with its own equalizer settings.
+type Profile string
+
+const (
+	ProfileSpeakers   Profile = "speakers"
+	ProfileHeadphones Profile = "headphones"
+)
+
+// ParseProfile parses a profile name. Unknown values return ProfileSpeakers.
+func ParseProfile(s string) Profile {
+	if strings.EqualFold(strings.TrimSpace(s), string(ProfileHeadphones)) {
+		return ProfileHeadphones
+	}
+	return ProfileSpeakers
+}
+
+// State holds the settings of both profiles and which one is active.
+type State struct {
+	Profile    Profile
+	Speakers   Settings
+	Headphones Settings
+}
+
+// DefaultState returns flat, disabled settings with the speakers profile active.
+func DefaultState() State {
+	return State{Profile: ProfileSpeakers, Speakers: Default(), Headphones: Default()}
+}
+
+// Active returns the settings of the active profile.
+func (s State) Active() Settings {
+	if s.Profile == ProfileHeadphones {
+		return s.Headphones
+	}
+	return s.Speakers
+}
+
+// SetActive replaces the settings of the active profile.
+func (s *State) SetActive(settings Settings) {
+	if s.Profile == ProfileHeadphones {
+		s.Headphones = settings
+	} else {
+		s.Speakers = settings
+	}
+}
+
+// Clone returns a copy that does not share band slices.
+func (s State) Clone() State {
+	s.Speakers = s.Speakers.Clone()
+	s.Headphones = s.Headphones.Clone()
+	return s
+}
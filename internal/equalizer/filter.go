@@ -0,0 +1,126 @@
+package equalizer
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// biquad is a second order IIR filter (RBJ audio EQ cookbook), normalized so that a0 = 1.
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+}
+
+// newBiquad computes the coefficients of a band at the given sample rate.
+func newBiquad(b Band, sampleRate float64) biquad {
+	// Keep the frequency below Nyquist at low sample rates
+	freq := min(b.Freq, sampleRate*0.45)
+	q := b.Q
+	if q <= 0 {
+		q = graphicQ
+	}
+
+	a := math.Pow(10, b.GainDB/40)
+	w0 := 2 * math.Pi * freq / sampleRate
+	cosW, sinW := math.Cos(w0), math.Sin(w0)
+	alpha := sinW / (2 * q)
+
+	var b0, b1, b2, a0, a1, a2 float64
+	switch b.Type {
+	case LowShelf:
+		sq := 2 * math.Sqrt(a) * alpha
+		b0 = a * ((a + 1) - (a-1)*cosW + sq)
+		b1 = 2 * a * ((a - 1) - (a+1)*cosW)
+		b2 = a * ((a + 1) - (a-1)*cosW - sq)
+		a0 = (a + 1) + (a-1)*cosW + sq
+		a1 = -2 * ((a - 1) + (a+1)*cosW)
+		a2 = (a + 1) + (a-1)*cosW - sq
+	case HighShelf:
+		sq := 2 * math.Sqrt(a) * alpha
+		b0 = a * ((a + 1) + (a-1)*cosW + sq)
+		b1 = -2 * a * ((a - 1) + (a+1)*cosW)
+		b2 = a * ((a + 1) + (a-1)*cosW - sq)
+		a0 = (a + 1) - (a-1)*cosW + sq
+		a1 = 2 * ((a - 1) - (a+1)*cosW)
+		a2 = (a + 1) - (a-1)*cosW - sq
+	default:
+		b0 = 1 + alpha*a
+		b1 = -2 * cosW
+		b2 = 1 - alpha*a
+		a0 = 1 + alpha/a
+		a1 = -2 * cosW
+		a2 = 1 - alpha/a
+	}
+
+	return biquad{b0: b0 / a0, b1: b1 / a0, b2: b2 / a0, a1: a1 / a0, a2: a2 / a0}
+}
+
+// responseDB returns the magnitude response of the filter at freq.
+func (f biquad) responseDB(freq, sampleRate float64) float64 {
+	z := cmplx.Exp(complex(0, -2*math.Pi*freq/sampleRate)) // z^-1
+	num := complex(f.b0, 0) + complex(f.b1, 0)*z + complex(f.b2, 0)*z*z
+	den := 1 + complex(f.a1, 0)*z + complex(f.a2, 0)*z*z
+	return 20 * math.Log10(cmplx.Abs(num/den))
+}
+
+// biquadState is the delay line of one channel (transposed direct form II).
+type biquadState struct {
+	z1, z2 float64
+}
+
+func (f *biquad) process(s *biquadState, x float64) float64 {
+	y := f.b0*x + s.z1
+	s.z1 = f.b1*x - f.a1*y + s.z2
+	s.z2 = f.b2*x - f.a2*y
+	return y
+}
+
+// Chain applies an equalizer to stereo samples.
+// It is not safe for concurrent use.
+type Chain struct {
+	sampleRate float64
+	enabled    bool
+	preamp     float64 // Linear
+	filters    []biquad
+	state      [][2]biquadState // [band][channel]
+}
+
+// NewChain creates a filter chain for the given sample rate.
+func NewChain(s Settings, sampleRate int) *Chain {
+	c := &Chain{sampleRate: float64(sampleRate)}
+	c.Update(s)
+	return c
+}
+
+// Update replaces the settings. The filter history is kept for the bands
+// that still exist, so changes while playing don't click.
+func (c *Chain) Update(s Settings) {
+	c.enabled = s.Enabled && !s.IsFlat()
+	c.preamp = math.Pow(10, s.PreampDB/20)
+
+	c.filters = c.filters[:0]
+	for _, b := range s.Bands {
+		c.filters = append(c.filters, newBiquad(b, c.sampleRate))
+	}
+	if len(c.state) != len(c.filters) {
+		state := make([][2]biquadState, len(c.filters))
+		copy(state, c.state)
+		c.state = state
+	}
+}
+
+// Process filters samples in place.
+func (c *Chain) Process(samples [][2]float64) {
+	if !c.enabled {
+		return
+	}
+	for i := range samples {
+		l := samples[i][0] * c.preamp
+		r := samples[i][1] * c.preamp
+		for j := range c.filters {
+			f := &c.filters[j]
+			l = f.process(&c.state[j][0], l)
+			r = f.process(&c.state[j][1], r)
+		}
+		samples[i][0], samples[i][1] = l, r
+	}
+}
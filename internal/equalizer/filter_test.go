@@ -0,0 +1,181 @@
+package equalizer
+
+import (
+	"math"
+	"testing"
+)
+
+const testRate = 48000
+
+// sine generates a stereo sine wave of one second.
+func sine(freq, amplitude float64) [][2]float64 {
+	out := make([][2]float64, testRate)
+	for i := range out {
+		v := amplitude * math.Sin(2*math.Pi*freq*float64(i)/testRate)
+		out[i] = [2]float64{v, v}
+	}
+	return out
+}
+
+// gainDB runs a sine through the chain and returns the level change,
+// measured on the second half once the filters have settled.
+func gainDB(t *testing.T, s Settings, freq float64) float64 {
+	t.Helper()
+	in := sine(freq, 0.1)
+	out := make([][2]float64, len(in))
+	copy(out, in)
+
+	c := NewChain(s, testRate)
+	// Process in buffer sized chunks like the audio callback
+	for i := 0; i < len(out); i += 512 {
+		c.Process(out[i:min(i+512, len(out))])
+	}
+
+	var inPeak, outPeak float64
+	for i := len(in) / 2; i < len(in); i++ {
+		inPeak = max(inPeak, math.Abs(in[i][0]))
+		outPeak = max(outPeak, math.Abs(out[i][0]))
+		if out[i][0] != out[i][1] {
+			t.Fatalf("channels differ at %d", i)
+		}
+	}
+	return 20 * math.Log10(outPeak/inPeak)
+}
+
+func peaking(freq, gain, q float64) Settings {
+	return Settings{Enabled: true, Bands: []Band{{Type: Peaking, Freq: freq, GainDB: gain, Q: q}}}
+}
+
+func TestChain_PeakingBand(t *testing.T) {
+	s := peaking(1000, 6, 1.41)
+
+	if got := gainDB(t, s, 1000); math.Abs(got-6) > 0.1 {
+		t.Errorf("gain at center = %.2f dB, want 6", got)
+	}
+	if got := gainDB(t, s, 100); math.Abs(got) > 0.3 {
+		t.Errorf("gain far below = %.2f dB, want ~0", got)
+	}
+	if got := gainDB(t, s, 10000); math.Abs(got) > 0.3 {
+		t.Errorf("gain far above = %.2f dB, want ~0", got)
+	}
+}
+
+func TestChain_Cut(t *testing.T) {
+	if got := gainDB(t, peaking(250, -12, 2), 250); math.Abs(got+12) > 0.1 {
+		t.Errorf("gain at center = %.2f dB, want -12", got)
+	}
+}
+
+func TestChain_Shelves(t *testing.T) {
+	low := Settings{Enabled: true, Bands: []Band{{Type: LowShelf, Freq: 200, GainDB: 8, Q: 0.707}}}
+	if got := gainDB(t, low, 40); math.Abs(got-8) > 0.3 {
+		t.Errorf("low shelf below corner = %.2f dB, want 8", got)
+	}
+	if got := gainDB(t, low, 5000); math.Abs(got) > 0.3 {
+		t.Errorf("low shelf above corner = %.2f dB, want ~0", got)
+	}
+
+	high := Settings{Enabled: true, Bands: []Band{{Type: HighShelf, Freq: 4000, GainDB: -6, Q: 0.707}}}
+	if got := gainDB(t, high, 15000); math.Abs(got+6) > 0.3 {
+		t.Errorf("high shelf above corner = %.2f dB, want -6", got)
+	}
+	if got := gainDB(t, high, 200); math.Abs(got) > 0.3 {
+		t.Errorf("high shelf below corner = %.2f dB, want ~0", got)
+	}
+}
+
+func TestChain_Preamp(t *testing.T) {
+	s := Settings{Enabled: true, PreampDB: -6, Bands: graphic()}
+	if got := gainDB(t, s, 1000); math.Abs(got+6) > 0.01 {
+		t.Errorf("gain = %.2f dB, want -6", got)
+	}
+}
+
+func TestChain_DisabledIsBypassed(t *testing.T) {
+	s := peaking(1000, 6, 1.41)
+	s.Enabled = false
+	if got := gainDB(t, s, 1000); got != 0 {
+		t.Errorf("gain = %v dB, want exactly 0 when disabled", got)
+	}
+}
+
+func TestChain_UpdateKeepsRunning(t *testing.T) {
+	c := NewChain(peaking(1000, 6, 1.41), testRate)
+	buf := sine(1000, 0.1)
+	c.Process(buf[:1000])
+
+	// Editing a band while playing continues smoothly from the same history
+	c.Update(peaking(1000, 3, 1.41))
+	c.Process(buf[1000:])
+	for i, s := range buf[1000:] {
+		if math.Abs(s[0]) > 0.1*math.Pow(10, 6.0/20) {
+			t.Fatalf("sample %d = %v after update, want no overshoot", 1000+i, s[0])
+		}
+	}
+
+	c.Update(Settings{Enabled: true, Bands: graphic(1)})
+	if len(c.state) != len(graphicFreqs) {
+		t.Errorf("state has %d bands, want %d", len(c.state), len(graphicFreqs))
+	}
+}
+
+func TestResponseMatchesChain(t *testing.T) {
+	s := BuiltinPresets()[1].Settings // Bass Boost
+	for _, f := range []float64{62, 250, 1000} {
+		want := s.ResponseDB(f)
+		if got := gainDB(t, s, f); math.Abs(got-want) > 0.2 {
+			t.Errorf("%v Hz: measured %.2f dB, response says %.2f dB", f, got, want)
+		}
+	}
+}
+
+func TestAutoPreamp(t *testing.T) {
+	s := peaking(1000, 6, 1.41)
+	if got := s.AutoPreamp(); got != -6 {
+		t.Errorf("AutoPreamp() = %v, want -6", got)
+	}
+
+	cut := peaking(1000, -6, 1.41)
+	if got := cut.AutoPreamp(); got != 0 {
+		t.Errorf("AutoPreamp() = %v, want 0 for cuts only", got)
+	}
+
+	// Overlapping boosts add up
+	s.Bands = append(s.Bands, Band{Type: Peaking, Freq: 1100, GainDB: 6, Q: 1.41})
+	if got := s.AutoPreamp(); got > -11 {
+		t.Errorf("AutoPreamp() = %v, want below -11 for overlapping boosts", got)
+	}
+
+	// With the auto preamp, a full scale sine at the boosted frequency does not clip
+	s.PreampDB = s.AutoPreamp()
+	if got := gainDB(t, s, 1050); got > 0.01 {
+		t.Errorf("gain with auto preamp = %.2f dB, want <= 0", got)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	s := Settings{
+		PreampDB: -40,
+		Bands: []Band{
+			{Type: BandType(9), Freq: 5, GainDB: 30, Q: 0},
+			{Type: HighShelf, Freq: 30000, GainDB: -30, Q: 50},
+		},
+	}
+	got := s.Normalize()
+
+	if got.PreampDB != MinPreamp {
+		t.Errorf("PreampDB = %v, want %v", got.PreampDB, MinPreamp)
+	}
+	want := []Band{
+		{Type: Peaking, Freq: MinFreq, GainDB: MaxGain, Q: graphicQ},
+		{Type: HighShelf, Freq: MaxFreq, GainDB: MinGain, Q: MaxQ},
+	}
+	for i := range want {
+		if got.Bands[i] != want[i] {
+			t.Errorf("band %d = %+v, want %+v", i, got.Bands[i], want[i])
+		}
+	}
+	if s.Bands[0].Freq != 5 {
+		t.Error("Normalize() modified its receiver")
+	}
+}
@@ -0,0 +1,53 @@
+package equalizer
+
+import "encoding/json"
+
+// bandJSON is the JSON representation of a Band.
+type bandJSON struct {
+	Type int     `json:"type"`
+	Freq float64 `json:"freq"`
+	Gain float64 `json:"gain"`
+	Q    float64 `json:"q"`
+}
+
+// settingsJSON is the JSON representation of Settings.
+type settingsJSON struct {
+	Enabled bool       `json:"enabled"`
+	Preamp  float64    `json:"preamp"`
+	Bands   []bandJSON `json:"bands"`
+}
+
+// ToJSON serializes Settings for database storage.
+func (s Settings) ToJSON() (string, error) {
+	sj := settingsJSON{
+		Enabled: s.Enabled,
+		Preamp:  s.PreampDB,
+		Bands:   make([]bandJSON, len(s.Bands)),
+	}
+	for i, b := range s.Bands {
+		sj.Bands[i] = bandJSON{Type: int(b.Type), Freq: b.Freq, Gain: b.GainDB, Q: b.Q}
+	}
+	data, err := json.Marshal(sj)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// FromJSON deserializes Settings from database storage.
+// Out of range values are clamped.
+func FromJSON(data string) (Settings, error) {
+	var sj settingsJSON
+	if err := json.Unmarshal([]byte(data), &sj); err != nil {
+		return Settings{}, err
+	}
+	s := Settings{
+		Enabled:  sj.Enabled,
+		PreampDB: sj.Preamp,
+		Bands:    make([]Band, len(sj.Bands)),
+	}
+	for i, b := range sj.Bands {
+		s.Bands[i] = Band{Type: BandType(b.Type), Freq: b.Freq, GainDB: b.Gain, Q: b.Q}
+	}
+	return s.Normalize(), nil
+}
@@ -0,0 +1,56 @@
+package equalizer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJSON_RoundTrip(t *testing.T) {
+	for _, p := range BuiltinPresets() {
+		data, err := p.Settings.ToJSON()
+		if err != nil {
+			t.Fatalf("%s: ToJSON() error: %v", p.Name, err)
+		}
+		got, err := FromJSON(data)
+		if err != nil {
+			t.Fatalf("%s: FromJSON() error: %v", p.Name, err)
+		}
+		if !reflect.DeepEqual(got, p.Settings) {
+			t.Errorf("%s: round trip = %+v, want %+v", p.Name, got, p.Settings)
+		}
+	}
+}
+
+func TestFromJSON_ClampsValues(t *testing.T) {
+	got, err := FromJSON(`{"enabled":true,"preamp":50,"bands":[{"type":0,"freq":100,"gain":-99,"q":1}]}`)
+	if err != nil {
+		t.Fatalf("FromJSON() error: %v", err)
+	}
+	if got.PreampDB != MaxPreamp || got.Bands[0].GainDB != MinGain {
+		t.Errorf("got %+v, want clamped values", got)
+	}
+}
+
+func TestFromJSON_Invalid(t *testing.T) {
+	if _, err := FromJSON("not json"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestState_Active(t *testing.T) {
+	s := DefaultState()
+	hp := Settings{Enabled: true, PreampDB: -3}
+
+	s.Profile = ProfileHeadphones
+	s.SetActive(hp)
+	if !reflect.DeepEqual(s.Active(), hp) {
+		t.Errorf("Active() = %+v, want headphones settings", s.Active())
+	}
+	if s.Speakers.Enabled {
+		t.Error("speakers profile should be untouched")
+	}
+
+	if ParseProfile(" Headphones ") != ProfileHeadphones || ParseProfile("hdmi") != ProfileSpeakers {
+		t.Error("ParseProfile() mismatch")
+	}
+}
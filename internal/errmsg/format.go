@@ -36,12 +36,21 @@ const (
 	OpPlaylistAddTrack Op = "add track to playlist"
 	OpPlaylistRemove   Op = "remove track from playlist"
 	OpPlaylistMove     Op = "move playlist item"
+	OpPlaylistRules    Op = "save smart playlist rules"
+	OpPlaylistFreeze   Op = "freeze smart playlist"
+	OpPlaylistImport   Op = "import playlist file"
+	OpPlaylistExport   Op = "export playlist file"
 
 	// Folder operations
 	OpFolderCreate Op = "create folder"
 	OpFolderRename Op = "rename folder"
 	OpFolderDelete Op = "delete folder"
 
+	// Station operations
+	OpStationAdd    Op = "add station"
+	OpStationRename Op = "rename station"
+	OpStationDelete Op = "delete station"
+
 	// Queue operations
 	OpQueueLoad Op = "load queue"
 	OpQueueSave Op = "save queue"
@@ -50,10 +59,15 @@ const (
 	// Playback operations
 	OpPlaybackStart Op = "start playback"
 	OpPlaybackSeek  Op = "seek"
+	OpPlaybackLoop  Op = "set loop"
 
 	// Favorites
 	OpFavoriteToggle Op = "update favorites"
 
+	// Ratings
+	OpRatingSave Op = "save rating"
+	OpRatingTags Op = "write rating tags"
+
 	// File operations
 	OpFileDelete Op = "delete file"
 	OpFileLoad   Op = "load file"
@@ -83,8 +97,30 @@ const (
 	OpTargetRename  Op = "rename export target"
 	OpVolumeDetect  Op = "detect volumes"
 
+	// Loudness operations
+	OpLoudnessAnalyze Op = "analyze loudness"
+
+	// Equalizer operations
+	OpEqualizerSave   Op = "save equalizer settings"
+	OpEqualizerLoad   Op = "load equalizer presets"
+	OpEqualizerPreset Op = "save equalizer preset"
+	OpEqualizerDelete Op = "delete equalizer preset"
+
+	// Bookmark operations
+	OpBookmarkLoad   Op = "load bookmarks"
+	OpBookmarkAdd    Op = "add bookmark"
+	OpBookmarkRename Op = "rename bookmark"
+	OpBookmarkDelete Op = "delete bookmark"
+
+	// History operations
+	OpHistoryLoad Op = "load play history"
+
 	// Notification operations
 	OpNotify Op = "send notification"
+
+	// Server operations
+	OpMPDServe  Op = "start the MPD server"
+	OpHTTPServe Op = "start the HTTP API"
 )
 
 // Format creates a user-friendly error message.
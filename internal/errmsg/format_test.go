@@ -132,16 +132,20 @@ func TestOpConstants(t *testing.T) {
 		OpImportFile, OpImportTags,
 		OpPlaylistCreate, OpPlaylistRename, OpPlaylistDelete,
 		OpPlaylistAddTrack, OpPlaylistRemove, OpPlaylistMove,
+		OpPlaylistRules, OpPlaylistFreeze, OpPlaylistImport, OpPlaylistExport,
 		OpFolderCreate, OpFolderRename, OpFolderDelete,
+		OpStationAdd, OpStationRename, OpStationDelete,
 		OpQueueLoad, OpQueueSave, OpQueueAdd,
-		OpPlaybackStart, OpPlaybackSeek,
+		OpPlaybackStart, OpPlaybackSeek, OpPlaybackLoop,
 		OpFavoriteToggle,
+		OpRatingSave, OpRatingTags,
 		OpFileDelete, OpFileLoad,
 		OpAlbumLoad, OpPresetLoad, OpPresetSave, OpPresetDelete,
 		OpInitialize,
 		OpLastfmAuth, OpLastfmScrobble, OpLastfmNowPlaying,
 		OpRadioFill,
 		OpExportFile, OpExportConvert, OpExportTarget, OpTargetDelete, OpTargetRename, OpVolumeDetect,
+		OpBookmarkLoad, OpBookmarkAdd, OpBookmarkRename, OpBookmarkDelete,
 	}
 
 	testErr := errors.New("test error")
@@ -0,0 +1,77 @@
+// Package history records the tracks that were played, whether they played
+// to their end or were skipped.
+package history
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Play is a track that was played.
+type Play struct {
+	ID        int64
+	TrackID   int64 // Library track ID, 0 for files outside the library
+	Path      string
+	Title     string
+	Artist    string
+	Album     string
+	StartedAt time.Time
+	Played    time.Duration // Time spent playing, pauses excluded
+	Completed bool          // Played to its end rather than skipped or stopped
+}
+
+// Store keeps the play history in the state database.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore creates a store on the state database.
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Add records a play and returns its ID.
+func (s *Store) Add(p Play) (int64, error) {
+	var trackID any
+	if p.TrackID != 0 {
+		trackID = p.TrackID
+	}
+	res, err := s.db.Exec(`
+		INSERT INTO play_history (track_id, path, title, artist, album, started_at, played_ms, completed)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, trackID, p.Path, p.Title, p.Artist, p.Album,
+		p.StartedAt.Unix(), p.Played.Milliseconds(), p.Completed)
+	if err != nil {
+		return 0, fmt.Errorf("add play: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// Recent returns the last plays, most recent first.
+func (s *Store) Recent(limit int) ([]Play, error) {
+	rows, err := s.db.Query(`
+		SELECT id, COALESCE(track_id, 0), path, title, artist, album, started_at, played_ms, completed
+		FROM play_history
+		ORDER BY started_at DESC, id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query play history: %w", err)
+	}
+	defer rows.Close()
+
+	var plays []Play
+	for rows.Next() {
+		var p Play
+		var startedAt, ms int64
+		if err := rows.Scan(&p.ID, &p.TrackID, &p.Path, &p.Title, &p.Artist, &p.Album,
+			&startedAt, &ms, &p.Completed); err != nil {
+			return nil, fmt.Errorf("scan play: %w", err)
+		}
+		p.StartedAt = time.Unix(startedAt, 0)
+		p.Played = time.Duration(ms) * time.Millisecond
+		plays = append(plays, p)
+	}
+	return plays, rows.Err()
+}
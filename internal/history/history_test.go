@@ -0,0 +1,51 @@
+package history
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/llehouerou/waves/internal/state"
+)
+
+// setupTestDB opens a database with the schema of the state package.
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := state.OpenDB(filepath.Join(t.TempDir(), "waves.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestStore_AddAndRecent(t *testing.T) {
+	s := NewStore(setupTestDB(t))
+	start := time.Date(2024, 5, 1, 20, 0, 0, 0, time.Local)
+
+	_, err := s.Add(Play{TrackID: 7, Path: "/a.flac", Title: "A", Artist: "Artist", Album: "Album",
+		StartedAt: start, Played: 3 * time.Minute, Completed: true})
+	require.NoError(t, err)
+	_, err = s.Add(Play{Path: "/b.mp3", Title: "B", StartedAt: start.Add(3 * time.Minute), Played: 20 * time.Second})
+	require.NoError(t, err)
+
+	plays, err := s.Recent(10)
+	require.NoError(t, err)
+	require.Len(t, plays, 2)
+
+	assert.Equal(t, "/b.mp3", plays[0].Path)
+	assert.Zero(t, plays[0].TrackID)
+	assert.False(t, plays[0].Completed)
+	assert.Equal(t, 20*time.Second, plays[0].Played)
+
+	assert.Equal(t, int64(7), plays[1].TrackID)
+	assert.Equal(t, "Album", plays[1].Album)
+	assert.True(t, plays[1].Completed)
+	assert.True(t, plays[1].StartedAt.Equal(start))
+
+	plays, err = s.Recent(1)
+	require.NoError(t, err)
+	assert.Len(t, plays, 1)
+}
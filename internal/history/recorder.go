@@ -0,0 +1,204 @@
+package history
+
+import (
+	"sync"
+	"time"
+
+	"github.com/llehouerou/waves/internal/playback"
+)
+
+// minPlayed is how long a skipped track must have played to be recorded,
+// so that skipping through the queue doesn't fill the history.
+const minPlayed = 5 * time.Second
+
+// Counter keeps the play and skip counts of library tracks.
+type Counter interface {
+	CountPlay(trackID int64, at time.Time) error
+	CountSkip(trackID int64) error
+}
+
+// Threshold is how much of a track must be played for the play to count.
+// A track played to its end always counts.
+type Threshold struct {
+	Percent  int           // Part of the track's duration, 0 to ignore
+	Duration time.Duration // Time played, 0 to ignore
+}
+
+// Reached reports whether a track of the given length played long enough
+// to count.
+func (t Threshold) Reached(played, length time.Duration) bool {
+	if t.Percent > 0 && length > 0 && played*100 >= length*time.Duration(t.Percent) {
+		return true
+	}
+	return t.Duration > 0 && played >= t.Duration
+}
+
+// Recorder writes the plays of playback services to the store, from their
+// event stream.
+type Recorder struct {
+	store     *Store
+	counter   Counter // nil disables play counts
+	threshold Threshold
+	recorded  chan Play
+	wg        sync.WaitGroup
+}
+
+// NewRecorder creates a recorder writing to the store.
+func NewRecorder(store *Store) *Recorder {
+	return &Recorder{
+		store:    store,
+		recorded: make(chan Play, 16),
+	}
+}
+
+// SetCounter makes the recorder count plays and skips of library tracks,
+// plays counting once they reach the threshold. Must be called before Watch.
+func (r *Recorder) SetCounter(c Counter, t Threshold) {
+	r.counter = c
+	r.threshold = t
+}
+
+// Watch records the plays of a service until it is closed.
+func (r *Recorder) Watch(svc playback.Service) {
+	sub := svc.Subscribe()
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.run(&session{svc: svc}, sub)
+	}()
+}
+
+// Recorded returns a channel receiving the plays once they are written.
+func (r *Recorder) Recorded() <-chan Play {
+	return r.recorded
+}
+
+// Wait waits until the plays of closed services are written.
+func (r *Recorder) Wait() {
+	r.wg.Wait()
+}
+
+// session tracks the play in progress on a service.
+type session struct {
+	svc     playback.Service
+	current *Play
+	length  time.Duration // Duration of the current track
+	since   time.Time     // When playing last resumed, zero while not playing
+}
+
+func (r *Recorder) run(s *session, sub *playback.Subscription) {
+	for {
+		select {
+		case e := <-sub.StateChanged:
+			r.handleState(s, e)
+		case e := <-sub.TrackChanged:
+			r.handleTrack(s, e)
+		case <-sub.Done:
+			// Events sent before the service closed are still buffered
+			for {
+				select {
+				case e := <-sub.StateChanged:
+					r.handleState(s, e)
+				case e := <-sub.TrackChanged:
+					r.handleTrack(s, e)
+				default:
+					r.finish(s, false, false)
+					return
+				}
+			}
+		}
+	}
+}
+
+func (r *Recorder) handleState(s *session, e playback.StateChange) {
+	switch e.Current {
+	case playback.StatePlaying:
+		if s.current == nil {
+			s.start(s.svc.CurrentTrack())
+		} else if s.since.IsZero() {
+			s.since = time.Now()
+		}
+	case playback.StatePaused:
+		s.pause()
+	case playback.StateStopped:
+		r.finish(s, e.Finished, false)
+	}
+}
+
+func (r *Recorder) handleTrack(s *session, e playback.TrackChange) {
+	// Starting playback on another track also changes the state, which
+	// may have been handled first
+	if s.current != nil && e.Current != nil && s.current.Path == e.Current.Path {
+		return
+	}
+	// Moving to another track before the end skips the current one
+	r.finish(s, e.Finished, !e.Finished)
+	if s.svc.State() == playback.StatePlaying {
+		s.start(e.Current)
+	}
+}
+
+// start starts timing a play of the track.
+func (s *session) start(t *playback.Track) {
+	if t == nil {
+		return
+	}
+	now := time.Now()
+	s.current = &Play{
+		TrackID:   t.ID,
+		Path:      t.Path,
+		Title:     t.Title,
+		Artist:    t.Artist,
+		Album:     t.Album,
+		StartedAt: now,
+	}
+	s.length = t.Duration
+	s.since = now
+}
+
+// pause adds the time played since playing last resumed.
+func (s *session) pause() {
+	if s.current != nil && !s.since.IsZero() {
+		s.current.Played += time.Since(s.since)
+	}
+	s.since = time.Time{}
+}
+
+// finish records the play in progress, counting it as a skip if skipped
+// is true and it didn't reach the threshold. Recording is best effort: a
+// play that can't be written is lost.
+func (r *Recorder) finish(s *session, completed, skipped bool) {
+	s.pause()
+	p := s.current
+	s.current = nil
+	if p == nil {
+		return
+	}
+	r.count(p, s.length, completed, skipped)
+	if !completed && p.Played < minPlayed {
+		return
+	}
+	p.Completed = completed
+	id, err := r.store.Add(*p)
+	if err != nil {
+		return
+	}
+	p.ID = id
+	select {
+	case r.recorded <- *p:
+	default:
+	}
+}
+
+// count adds the play of a library track to its play or skip count.
+func (r *Recorder) count(p *Play, length time.Duration, completed, skipped bool) {
+	if r.counter == nil || p.TrackID == 0 {
+		return
+	}
+	switch {
+	case completed || r.threshold.Reached(p.Played, length):
+		_ = r.counter.CountPlay(p.TrackID, time.Now())
+	case skipped:
+		_ = r.counter.CountSkip(p.TrackID)
+	}
+}
@@ -0,0 +1,188 @@
+package history
+
+import (
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/playlist"
+)
+
+func newService(paths ...string) (*player.Mock, playback.Service) {
+	p := player.NewMock()
+	q := playlist.NewQueue()
+	for i, path := range paths {
+		q.Add(playlist.Track{ID: int64(i + 1), Path: path, Title: path})
+	}
+	q.JumpTo(0)
+	return p, playback.New(p, q)
+}
+
+func TestRecorder_RecordsCompletedAndSkippedPlays(t *testing.T) {
+	store := NewStore(setupTestDB(t))
+	synctest.Test(t, func(t *testing.T) {
+		p, svc := newService("/a.flac", "/b.flac", "/c.flac")
+		r := NewRecorder(store)
+		r.Watch(svc)
+
+		require.NoError(t, svc.Play())
+		time.Sleep(3 * time.Minute)
+		p.SimulateFinished() // a plays to its end, b starts
+		got := <-r.Recorded()
+		assert.Equal(t, "/a.flac", got.Path)
+		assert.Equal(t, int64(1), got.TrackID)
+		assert.Equal(t, 3*time.Minute, got.Played)
+		assert.True(t, got.Completed)
+
+		time.Sleep(30 * time.Second)
+		require.NoError(t, svc.Pause())
+		time.Sleep(time.Hour) // Paused time isn't counted
+		require.NoError(t, svc.Play())
+		time.Sleep(10 * time.Second)
+		require.NoError(t, svc.Next()) // b is skipped, c starts
+		got = <-r.Recorded()
+		assert.Equal(t, "/b.flac", got.Path)
+		assert.Equal(t, 40*time.Second, got.Played)
+		assert.False(t, got.Completed)
+
+		time.Sleep(time.Minute)
+		require.NoError(t, svc.Stop())
+		got = <-r.Recorded()
+		assert.Equal(t, "/c.flac", got.Path)
+		assert.False(t, got.Completed)
+
+		require.NoError(t, svc.Close())
+		r.Wait()
+	})
+
+	plays, err := store.Recent(10)
+	require.NoError(t, err)
+	require.Len(t, plays, 3)
+	assert.Equal(t, "/c.flac", plays[0].Path)
+}
+
+func TestRecorder_IgnoresQuickSkips(t *testing.T) {
+	store := NewStore(setupTestDB(t))
+	synctest.Test(t, func(t *testing.T) {
+		_, svc := newService("/a.flac", "/b.flac")
+		r := NewRecorder(store)
+		r.Watch(svc)
+
+		require.NoError(t, svc.Play())
+		time.Sleep(2 * time.Second)
+		require.NoError(t, svc.Next())
+		time.Sleep(time.Minute)
+
+		// Closing the service records the play in progress
+		require.NoError(t, svc.Close())
+		r.Wait()
+	})
+
+	plays, err := store.Recent(10)
+	require.NoError(t, err)
+	require.Len(t, plays, 1)
+	assert.Equal(t, "/b.flac", plays[0].Path)
+	assert.Equal(t, time.Minute, plays[0].Played)
+}
+
+func TestRecorder_EndOfQueueCompletes(t *testing.T) {
+	store := NewStore(setupTestDB(t))
+	synctest.Test(t, func(t *testing.T) {
+		p, svc := newService("/a.flac")
+		defer svc.Close()
+		r := NewRecorder(store)
+		r.Watch(svc)
+
+		require.NoError(t, svc.Play())
+		time.Sleep(time.Minute)
+		p.SimulateFinished()
+		got := <-r.Recorded()
+		assert.Equal(t, "/a.flac", got.Path)
+		assert.True(t, got.Completed)
+	})
+}
+
+type fakeCounter struct {
+	mu    sync.Mutex
+	plays []int64
+	skips []int64
+}
+
+func (c *fakeCounter) CountPlay(trackID int64, _ time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.plays = append(c.plays, trackID)
+	return nil
+}
+
+func (c *fakeCounter) CountSkip(trackID int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.skips = append(c.skips, trackID)
+	return nil
+}
+
+func TestRecorder_CountsPlaysAndSkips(t *testing.T) {
+	store := NewStore(setupTestDB(t))
+	counter := &fakeCounter{}
+	synctest.Test(t, func(t *testing.T) {
+		p := player.NewMock()
+		q := playlist.NewQueue()
+		for i, path := range []string{"/a.flac", "/b.flac", "/c.flac", "/d.flac"} {
+			q.Add(playlist.Track{ID: int64(i + 1), Path: path, Duration: 4 * time.Minute})
+		}
+		q.JumpTo(0)
+		svc := playback.New(p, q)
+		r := NewRecorder(store)
+		r.SetCounter(counter, Threshold{Percent: 50})
+		r.Watch(svc)
+
+		require.NoError(t, svc.Play())
+		time.Sleep(3 * time.Minute)
+		require.NoError(t, svc.Next()) // a is past the threshold, counted as played
+		<-r.Recorded()
+
+		time.Sleep(2 * time.Second)
+		require.NoError(t, svc.Next()) // b is skipped
+		time.Sleep(time.Minute)
+		p.SimulateFinished() // c plays to its end, d starts
+		<-r.Recorded()
+
+		time.Sleep(time.Minute)
+		require.NoError(t, svc.Stop()) // d is stopped, neither played nor skipped
+		<-r.Recorded()
+
+		require.NoError(t, svc.Close())
+		r.Wait()
+	})
+
+	assert.Equal(t, []int64{1, 3}, counter.plays)
+	assert.Equal(t, []int64{2}, counter.skips)
+}
+
+func TestThreshold_Reached(t *testing.T) {
+	th := Threshold{Percent: 50, Duration: 4 * time.Minute}
+	tests := []struct {
+		name           string
+		played, length time.Duration
+		want           bool
+	}{
+		{"half played", 2 * time.Minute, 4 * time.Minute, true},
+		{"under half", 119 * time.Second, 4 * time.Minute, false},
+		{"long track past duration", 4 * time.Minute, time.Hour, true},
+		{"unknown length", time.Minute, 0, false},
+		{"unknown length past duration", 5 * time.Minute, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, th.Reached(tt.played, tt.length))
+		})
+	}
+	assert.False(t, Threshold{}.Reached(time.Hour, time.Hour), "an empty threshold is never reached")
+}
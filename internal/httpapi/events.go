@@ -0,0 +1,133 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/llehouerou/waves/internal/playback"
+)
+
+// keepAlive is the interval of the comments keeping idle streams open
+// through proxies.
+const keepAlive = 30 * time.Second
+
+// Server-sent events. A stream starts with a status event, sent again when
+// the playback service is replaced.
+const (
+	eventStatus = "status" // Status
+	eventState  = "state"  // StateEvent
+	eventTrack  = "track"  // TrackEvent
+	eventQueue  = "queue"  // Queue
+	eventMode   = "mode"   // Mode
+)
+
+// StateEvent is sent when the playback state changes.
+type StateEvent struct {
+	State    string `json:"state"`
+	Finished bool   `json:"finished"` // The previous track played to its end
+}
+
+// TrackEvent is sent when the current track changes.
+type TrackEvent struct {
+	Track    *Track `json:"track"` // Nil when there is no current track
+	Index    int    `json:"index"`
+	Finished bool   `json:"finished"` // The previous track played to its end
+}
+
+// errGone is returned by relay when the client disconnected or the server
+// closed.
+var errGone = errors.New("stream closed")
+
+// handleEvents streams the playback events until the client disconnects or
+// the server closes, following the replacements of the service.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	svc, replaced := s.svc.Current()
+	sub := svc.Subscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	for {
+		err := writeEvent(w, rc, eventStatus, status(svc))
+		if err == nil {
+			err = s.relay(w, rc, sub, replaced, r.Context().Done())
+		}
+		svc.Unsubscribe(sub)
+		if err != nil {
+			return
+		}
+		// The service was closed: follow the one replacing it
+		select {
+		case <-replaced:
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			return
+		}
+		svc, replaced = s.svc.Current()
+		sub = svc.Subscribe()
+	}
+}
+
+// relay writes the events of a subscription until it is closed or its
+// service is replaced.
+func (s *Server) relay(w http.ResponseWriter, rc *http.ResponseController, sub *playback.Subscription, replaced, gone <-chan struct{}) error {
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+	for {
+		var err error
+		select {
+		case <-gone:
+			return errGone
+		case <-s.done:
+			return errGone
+		case <-sub.Done:
+			return nil
+		case <-replaced:
+			return nil
+		case <-ticker.C:
+			if _, err = fmt.Fprint(w, ": keep-alive\n\n"); err == nil {
+				err = rc.Flush()
+			}
+		case e := <-sub.StateChanged:
+			err = writeEvent(w, rc, eventState, StateEvent{State: name(e.Current), Finished: e.Finished})
+		case e := <-sub.TrackChanged:
+			ev := TrackEvent{Index: e.Index, Finished: e.Finished}
+			if e.Current != nil {
+				t := newTrack(*e.Current)
+				ev.Track = &t
+			}
+			err = writeEvent(w, rc, eventTrack, ev)
+		case e := <-sub.QueueChanged:
+			err = writeEvent(w, rc, eventQueue, newQueue(e.Tracks, e.Index))
+		case e := <-sub.ModeChanged:
+			err = writeEvent(w, rc, eventMode, Mode{
+				Repeat:  name(e.RepeatMode),
+				Shuffle: e.Shuffle,
+				Speed:   e.Speed,
+				Sleep:   name(e.Sleep),
+			})
+		case <-sub.PositionChanged:
+		case <-sub.Error:
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// writeEvent writes and flushes an event.
+func writeEvent(w http.ResponseWriter, rc *http.ResponseController, event string, data any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b); err != nil {
+		return err
+	}
+	return rc.Flush()
+}
@@ -0,0 +1,239 @@
+// Package httpapi serves a REST API over HTTP to drive waves from dashboards,
+// scripts and home automation.
+//
+// Every request must carry the configured token, as an
+// "Authorization: Bearer TOKEN" header or, for browsers' EventSource which
+// can't set headers, a token query parameter. Responses are JSON; errors are
+// objects with an error field. GET /api/events streams the events of the
+// playback service as server-sent events.
+package httpapi
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/llehouerou/waves/internal/library"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/playlists"
+)
+
+// ErrNoToken is returned by Listen when no token is configured.
+var ErrNoToken = errors.New("a token is required, set token in the [http] section of the config")
+
+// maxBodySize bounds request bodies, which can hold many paths.
+const maxBodySize = 1 << 20
+
+// VolumeStore persists volume changes.
+type VolumeStore interface {
+	SaveVolume(volume float64, muted bool) error
+}
+
+// Config configures a server.
+type Config struct {
+	Address   string // TCP address to listen on, as host:port
+	Token     string // Required from clients
+	Library   *library.Library
+	Playlists *playlists.Playlists
+	Volumes   VolumeStore // May be nil
+}
+
+// Server serves the API.
+type Server struct {
+	mux     *http.ServeMux
+	token   string
+	lib     *library.Library
+	source  *library.Source
+	pls     *playlists.Playlists
+	volumes VolumeStore
+	done    chan struct{} // Closed by Close, ending event streams
+	srv     *http.Server  // Set by Listen
+	ln      net.Listener
+	svc     *playback.Holder // Replaced by SetService
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// New returns a server handling requests without listening, to be served
+// by the caller.
+func New(svc playback.Service, cfg Config) *Server {
+	s := &Server{
+		mux:     http.NewServeMux(),
+		token:   cfg.Token,
+		lib:     cfg.Library,
+		source:  library.NewSource(cfg.Library),
+		pls:     cfg.Playlists,
+		volumes: cfg.Volumes,
+		done:    make(chan struct{}),
+		svc:     playback.NewHolder(svc),
+	}
+	s.routes()
+	return s
+}
+
+// Listen listens on cfg.Address and serves the API in the background.
+func Listen(svc playback.Service, cfg Config) (*Server, error) {
+	if cfg.Token == "" {
+		return nil, ErrNoToken
+	}
+	ln, err := net.Listen("tcp", cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+	s := New(svc, cfg)
+	s.ln = ln
+	s.srv = &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = s.srv.Serve(ln) }()
+	return s, nil
+}
+
+// Addr returns the address the server listens on, nil if it was created
+// by New.
+func (s *Server) Addr() net.Addr {
+	if s.ln == nil {
+		return nil
+	}
+	return s.ln.Addr()
+}
+
+// SetService makes the server control a new playback service. Call this
+// when the service is recreated, event streams being moved to the new one.
+func (s *Server) SetService(svc playback.Service) {
+	s.svc.Set(svc)
+}
+
+// Close ends the event streams and, if it listens, stops the server.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+	s.mu.Unlock()
+
+	if s.srv == nil {
+		return nil
+	}
+	return s.srv.Close()
+}
+
+// ServeHTTP serves a request once its token is checked.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="waves"`)
+		writeError(w, http.StatusUnauthorized, "invalid or missing token")
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	s.mux.ServeHTTP(w, r)
+}
+
+// authorized reports whether a request carries the token. A server without
+// token, which Listen refuses, authorizes nothing.
+func (s *Server) authorized(r *http.Request) bool {
+	if s.token == "" {
+		return false
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		token = r.URL.Query().Get("token")
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("GET /api/status", s.handleStatus)
+	for action, run := range playbackActions {
+		s.mux.HandleFunc("POST /api/playback/"+action, s.playbackAction(run))
+	}
+	s.mux.HandleFunc("POST /api/playback/seek", s.handleSeek)
+	s.mux.HandleFunc("POST /api/playback/volume", s.handleVolume)
+	s.mux.HandleFunc("GET /api/mode", s.handleMode)
+	s.mux.HandleFunc("PATCH /api/mode", s.handleSetMode)
+
+	s.mux.HandleFunc("GET /api/queue", s.handleQueue)
+	s.mux.HandleFunc("POST /api/queue", s.handleQueueAdd)
+	s.mux.HandleFunc("DELETE /api/queue", s.handleQueueClear)
+	s.mux.HandleFunc("DELETE /api/queue/{index}", s.handleQueueRemove)
+	s.mux.HandleFunc("POST /api/queue/{index}/move", s.handleQueueMove)
+	s.mux.HandleFunc("POST /api/queue/{index}/play", s.handleQueuePlay)
+
+	s.mux.HandleFunc("GET /api/library/artists", s.handleArtists)
+	s.mux.HandleFunc("GET /api/library/artists/{artist}/albums", s.handleAlbums)
+	s.mux.HandleFunc("GET /api/library/artists/{artist}/albums/{album}/tracks", s.handleAlbumTracks)
+	s.mux.HandleFunc("GET /api/library/tracks/{id}", s.handleTrack)
+
+	s.mux.HandleFunc("GET /api/playlists", s.handlePlaylists)
+	s.mux.HandleFunc("GET /api/playlists/{id}", s.handlePlaylist)
+	s.mux.HandleFunc("GET /api/playlists/{id}/tracks", s.handlePlaylistTracks)
+	s.mux.HandleFunc("POST /api/playlists/{id}/tracks", s.handlePlaylistAdd)
+	s.mux.HandleFunc("POST /api/playlists/{id}/play", s.handlePlaylistPlay)
+
+	s.mux.HandleFunc("GET /api/favorites", s.handleFavorites)
+	s.mux.HandleFunc("PUT /api/favorites/{id}", s.handleSetFavorite(true))
+	s.mux.HandleFunc("DELETE /api/favorites/{id}", s.handleSetFavorite(false))
+
+	s.mux.HandleFunc("GET /api/events", s.handleEvents)
+}
+
+// apiError is an error answered with its HTTP status.
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func badRequest(message string) error {
+	return &apiError{http.StatusBadRequest, message}
+}
+
+func notFound(message string) error {
+	return &apiError{http.StatusNotFound, message}
+}
+
+// writeJSON answers a value with the OK status.
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// reply answers v, or err with its status: 500 for errors that aren't
+// apiErrors.
+func reply(w http.ResponseWriter, v any, err error) {
+	var apiErr *apiError
+	switch {
+	case err == nil:
+		writeJSON(w, v)
+	case errors.As(err, &apiErr):
+		writeError(w, apiErr.status, apiErr.message)
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// decode reads the JSON body of a request into v.
+func decode(r *http.Request, v any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return badRequest("invalid body: " + err.Error())
+	}
+	return nil
+}
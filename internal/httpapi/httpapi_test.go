@@ -0,0 +1,695 @@
+package httpapi
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/llehouerou/waves/internal/library"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/playlist"
+	"github.com/llehouerou/waves/internal/playlists"
+)
+
+const testToken = "secret"
+
+const testSchema = `
+	CREATE TABLE library_tracks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		path TEXT NOT NULL UNIQUE,
+		mtime INTEGER NOT NULL,
+		artist TEXT NOT NULL,
+		album_artist TEXT NOT NULL,
+		album TEXT NOT NULL,
+		title TEXT NOT NULL,
+		disc_number INTEGER,
+		track_number INTEGER,
+		year INTEGER,
+		genre TEXT,
+		original_date TEXT,
+		release_date TEXT,
+		label TEXT,
+		play_count INTEGER NOT NULL DEFAULT 0,
+		skip_count INTEGER NOT NULL DEFAULT 0,
+		last_played_at INTEGER,
+		rating INTEGER NOT NULL DEFAULT 0,
+		album_rating INTEGER NOT NULL DEFAULT 0,
+		added_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL
+	);
+
+	CREATE TABLE library_sources (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		path TEXT NOT NULL UNIQUE,
+		added_at INTEGER NOT NULL
+	);
+
+	CREATE TABLE playlist_folders (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		parent_id INTEGER REFERENCES playlist_folders(id) ON DELETE CASCADE,
+		name TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		UNIQUE(parent_id, name)
+	);
+
+	CREATE TABLE playlists (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		folder_id INTEGER REFERENCES playlist_folders(id) ON DELETE CASCADE,
+		name TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		last_used_at INTEGER NOT NULL,
+		rules TEXT,
+		UNIQUE(folder_id, name)
+	);
+
+	CREATE TABLE playlist_tracks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		playlist_id INTEGER NOT NULL REFERENCES playlists(id) ON DELETE CASCADE,
+		position INTEGER NOT NULL,
+		library_track_id INTEGER REFERENCES library_tracks(id) ON DELETE CASCADE,
+		UNIQUE(playlist_id, position)
+	);
+
+	INSERT INTO playlists (id, folder_id, name, created_at, last_used_at)
+	VALUES (1, NULL, 'Favorites', 1000, 1000);
+`
+
+type testServer struct {
+	server  *Server
+	http    *httptest.Server
+	svc     playback.Service
+	player  *player.Mock
+	pls     *playlists.Playlists
+	volumes *fakeVolumes
+	music   string
+}
+
+// fakeVolumes records the saved volume.
+type fakeVolumes struct {
+	volume float64
+	saves  int
+}
+
+func (f *fakeVolumes) SaveVolume(volume float64, _ bool) error {
+	f.volume = volume
+	f.saves++
+	return nil
+}
+
+// newTestServer serves a library of three tracks with IDs 1 to 3:
+// Beetlebum and Song 2 by Blur, and DARE by Gorillaz. The music folder also
+// holds Misc/untitled.flac, outside the library.
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	dir := t.TempDir()
+	music := filepath.Join(dir, "music")
+	files := []string{
+		"Blur/Blur/01 Beetlebum.mp3",
+		"Blur/Blur/02 Song 2.mp3",
+		"Gorillaz/Demon Days/06 DARE.mp3",
+		"Misc/untitled.flac",
+	}
+	for _, name := range files {
+		path := filepath.Join(music, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", filepath.Join(dir, "waves.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec(testSchema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	_, err = db.Exec(`
+		INSERT INTO library_tracks (path, mtime, artist, album_artist, album, title, track_number, disc_number, year, genre, album_rating, added_at, updated_at)
+		VALUES
+			(?, 1000, 'Blur', 'Blur', 'Blur', 'Beetlebum', 1, 1, 1997, 'Rock', 8, 1000, 1000),
+			(?, 1000, 'Blur', 'Blur', 'Blur', 'Song 2', 2, 1, 1997, 'Rock', 8, 1000, 1000),
+			(?, 1000, 'Gorillaz feat. Shaun Ryder', 'Gorillaz', 'Demon Days', 'DARE', 6, 1, 2005, 'Alternative', 0, 1000, 1000)
+	`, filepath.Join(music, files[0]), filepath.Join(music, files[1]), filepath.Join(music, files[2]))
+	if err != nil {
+		t.Fatalf("failed to insert tracks: %v", err)
+	}
+
+	lib := library.New(db)
+	pls := playlists.New(db, lib)
+	p := player.NewMock()
+	svc := playback.New(p, playlist.NewQueue())
+	volumes := &fakeVolumes{}
+	server := New(svc, Config{
+		Token:     testToken,
+		Library:   lib,
+		Playlists: pls,
+		Volumes:   volumes,
+	})
+	ts := httptest.NewServer(server)
+	t.Cleanup(func() {
+		server.Close()
+		ts.Close()
+		svc.Close()
+	})
+	return &testServer{server: server, http: ts, svc: svc, player: p, pls: pls, volumes: volumes, music: music}
+}
+
+// do sends an authorized request with a JSON body, if not nil, and
+// decodes the response into out, if not nil. It returns the status.
+func (ts *testServer) do(t *testing.T, method, path string, body, out any) int {
+	t.Helper()
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, ts.http.URL+path, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s error: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	if out != nil && resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: invalid response: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// ok sends a request that must succeed.
+func (ts *testServer) ok(t *testing.T, method, path string, body, out any) {
+	t.Helper()
+	if code := ts.do(t, method, path, body, out); code != http.StatusOK {
+		t.Fatalf("%s %s = %d, want 200", method, path, code)
+	}
+}
+
+func titles(tracks []Track) string {
+	names := make([]string, len(tracks))
+	for i, t := range tracks {
+		names[i] = t.Title
+	}
+	return strings.Join(names, ", ")
+}
+
+func TestServer_Auth(t *testing.T) {
+	ts := newTestServer(t)
+
+	tests := []struct {
+		name   string
+		header string
+		query  string
+		want   int
+	}{
+		{"no token", "", "", http.StatusUnauthorized},
+		{"wrong token", "Bearer wrong", "", http.StatusUnauthorized},
+		{"not a bearer token", testToken, "", http.StatusUnauthorized},
+		{"header", "Bearer " + testToken, "", http.StatusOK},
+		{"query", "", "?token=" + testToken, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, ts.http.URL+"/api/status"+tt.query, http.NoBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+
+	// A server without token authorizes nothing
+	open := httptest.NewServer(New(ts.svc, Config{}))
+	defer open.Close()
+	resp, err := http.Get(open.URL + "/api/status?token=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status without configured token = %d, want 401", resp.StatusCode)
+	}
+}
+
+func TestListen(t *testing.T) {
+	svc := playback.New(player.NewMock(), playlist.NewQueue())
+	defer svc.Close()
+
+	if _, err := Listen(svc, Config{Address: "localhost:0"}); !errors.Is(err, ErrNoToken) {
+		t.Errorf("Listen() without token error = %v, want ErrNoToken", err)
+	}
+
+	s, err := Listen(svc, Config{Address: "localhost:0", Token: testToken})
+	if err != nil {
+		t.Fatalf("Listen() error: %v", err)
+	}
+	req, err := http.NewRequest(http.MethodGet, "http://"+s.Addr().String()+"/api/status", http.NoBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /api/status error: %v", err)
+	}
+	var st Status
+	err = json.NewDecoder(resp.Body).Decode(&st)
+	resp.Body.Close()
+	if err != nil || st.State != "stopped" {
+		t.Errorf("status = %+v, %v, want stopped", st, err)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	if _, err := http.DefaultClient.Do(req); err == nil {
+		t.Error("the server should stop listening on Close()")
+	}
+}
+
+func TestServer_Playback(t *testing.T) {
+	ts := newTestServer(t)
+
+	ts.ok(t, http.MethodPost, "/api/queue", map[string]any{"paths": []string{filepath.Join(ts.music, "Blur")}}, nil)
+
+	var st Status
+	ts.ok(t, http.MethodPost, "/api/playback/play", nil, &st)
+	if st.State != "playing" || st.Index != 0 || st.Track == nil || st.Track.Title != "Beetlebum" {
+		t.Fatalf("status after play = %+v, want playing Beetlebum", st)
+	}
+	ts.ok(t, http.MethodPost, "/api/playback/next", nil, &st)
+	if st.Index != 1 || st.Track.Title != "Song 2" {
+		t.Errorf("status after next = %+v, want Song 2", st)
+	}
+	ts.ok(t, http.MethodPost, "/api/playback/toggle", nil, &st)
+	if st.State != "paused" {
+		t.Errorf("state after toggle = %s, want paused", st.State)
+	}
+	ts.ok(t, http.MethodPost, "/api/playback/play", nil, &st)
+	if st.State != "playing" {
+		t.Errorf("state after play = %s, want playing", st.State)
+	}
+	ts.ok(t, http.MethodPost, "/api/playback/stop", nil, &st)
+	if st.State != "stopped" {
+		t.Errorf("state after stop = %s, want stopped", st.State)
+	}
+
+	// Volume changes unmute the player and are saved
+	ts.player.SetMuted(true)
+	ts.ok(t, http.MethodPost, "/api/playback/volume", map[string]any{"volume": 40}, &st)
+	if st.Volume != 40 || st.Muted {
+		t.Errorf("volume = %d muted %v, want 40 unmuted", st.Volume, st.Muted)
+	}
+	ts.ok(t, http.MethodPost, "/api/playback/volume", map[string]any{"volume": -15, "relative": true}, &st)
+	if st.Volume != 25 {
+		t.Errorf("volume after -15 = %d, want 25", st.Volume)
+	}
+	ts.ok(t, http.MethodPost, "/api/playback/volume", map[string]any{"muted": true}, &st)
+	if st.Volume != 25 || !st.Muted {
+		t.Errorf("volume = %d muted %v, want 25 muted", st.Volume, st.Muted)
+	}
+	if ts.volumes.saves != 3 || ts.volumes.volume != 0.25 {
+		t.Errorf("saved volume %v %d times, want 0.25 3 times", ts.volumes.volume, ts.volumes.saves)
+	}
+
+	if code := ts.do(t, http.MethodPost, "/api/playback/volume", map[string]any{"level": 1}, nil); code != http.StatusBadRequest {
+		t.Errorf("unknown field = %d, want 400", code)
+	}
+	if code := ts.do(t, http.MethodPost, "/api/playback/rewind", nil, nil); code != http.StatusNotFound {
+		t.Errorf("unknown action = %d, want 404", code)
+	}
+}
+
+func TestServer_Mode(t *testing.T) {
+	ts := newTestServer(t)
+
+	var m Mode
+	ts.ok(t, http.MethodPatch, "/api/mode", map[string]any{"repeat": "all", "shuffle": true}, &m)
+	if m.Repeat != "all" || !m.Shuffle || m.Speed != 1 || m.Sleep != "off" {
+		t.Errorf("mode = %+v, want repeat all, shuffle, speed 1", m)
+	}
+	ts.ok(t, http.MethodPatch, "/api/mode", map[string]any{"speed": 1.5}, &m)
+	if m.Repeat != "all" || !m.Shuffle || m.Speed != 1.5 {
+		t.Errorf("mode = %+v, want only the speed changed", m)
+	}
+	ts.ok(t, http.MethodGet, "/api/mode", nil, &m)
+	if ts.svc.RepeatMode() != playback.RepeatAll || m.Speed != 1.5 {
+		t.Errorf("mode = %+v, repeat %v, want the changes", m, ts.svc.RepeatMode())
+	}
+
+	for _, body := range []map[string]any{{"repeat": "twice"}, {"speed": 3}} {
+		if code := ts.do(t, http.MethodPatch, "/api/mode", body, nil); code != http.StatusBadRequest {
+			t.Errorf("PATCH /api/mode %v = %d, want 400", body, code)
+		}
+	}
+}
+
+func TestServer_Queue(t *testing.T) {
+	ts := newTestServer(t)
+
+	var q Queue
+	ts.ok(t, http.MethodPost, "/api/queue", map[string]any{"track_ids": []int64{3, 1}}, &q)
+	if titles(q.Tracks) != "DARE, Beetlebum" || q.Tracks[0].ID != 3 {
+		t.Fatalf("queue = %+v, want DARE and Beetlebum", q)
+	}
+	ts.ok(t, http.MethodPost, "/api/queue/1/play", nil, nil)
+
+	// Files outside the library have their file name as title
+	ts.ok(t, http.MethodPost, "/api/queue", map[string]any{
+		"paths": []string{filepath.Join(ts.music, "Misc", "untitled.flac")},
+		"next":  true,
+	}, &q)
+	if titles(q.Tracks) != "DARE, Beetlebum, untitled.flac" || q.Index != 1 {
+		t.Errorf("queue = %s at %d, want untitled.flac after the playing Beetlebum", titles(q.Tracks), q.Index)
+	}
+
+	ts.ok(t, http.MethodPost, "/api/queue/2/move", map[string]any{"to": 0}, &q)
+	if titles(q.Tracks) != "untitled.flac, DARE, Beetlebum" || q.Index != 2 {
+		t.Errorf("queue after move = %s at %d, want untitled.flac first", titles(q.Tracks), q.Index)
+	}
+	ts.ok(t, http.MethodDelete, "/api/queue/1", nil, &q)
+	if titles(q.Tracks) != "untitled.flac, Beetlebum" {
+		t.Errorf("queue after delete = %s, want DARE removed", titles(q.Tracks))
+	}
+	ts.ok(t, http.MethodGet, "/api/queue", nil, &q)
+	if len(q.Tracks) != 2 || q.Index != 1 {
+		t.Errorf("queue = %+v, want 2 tracks playing the second", q)
+	}
+
+	tests := []struct {
+		method, path string
+		body         any
+		want         int
+	}{
+		{http.MethodPost, "/api/queue", map[string]any{"track_ids": []int64{42}}, http.StatusNotFound},
+		{http.MethodPost, "/api/queue", map[string]any{"paths": []string{"Blur"}}, http.StatusBadRequest},
+		{http.MethodPost, "/api/queue", map[string]any{}, http.StatusBadRequest},
+		{http.MethodPost, "/api/queue", "not an object", http.StatusBadRequest},
+		{http.MethodPost, "/api/queue/0/move", map[string]any{"to": 5}, http.StatusBadRequest},
+		{http.MethodPost, "/api/queue/2/play", nil, http.StatusNotFound},
+		{http.MethodDelete, "/api/queue/x", nil, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if code := ts.do(t, tt.method, tt.path, tt.body, nil); code != tt.want {
+			t.Errorf("%s %s %v = %d, want %d", tt.method, tt.path, tt.body, code, tt.want)
+		}
+	}
+
+	ts.ok(t, http.MethodDelete, "/api/queue", nil, &q)
+	if len(q.Tracks) != 0 || q.Index != -1 {
+		t.Errorf("queue after clear = %+v, want empty", q)
+	}
+}
+
+func TestServer_Library(t *testing.T) {
+	ts := newTestServer(t)
+
+	var artists []Artist
+	ts.ok(t, http.MethodGet, "/api/library/artists", nil, &artists)
+	if len(artists) != 2 || artists[0].Name != "Blur" || artists[1].Name != "Gorillaz" {
+		t.Errorf("artists = %+v, want Blur and Gorillaz", artists)
+	}
+
+	var albums []Album
+	ts.ok(t, http.MethodGet, "/api/library/artists/Blur/albums", nil, &albums)
+	if len(albums) != 1 || albums[0] != (Album{Artist: "Blur", Name: "Blur", Year: 1997, Rating: 8}) {
+		t.Errorf("albums = %+v, want Blur (1997) rated 8", albums)
+	}
+
+	var tracks []Track
+	ts.ok(t, http.MethodGet, "/api/library/artists/Gorillaz/albums/Demon%20Days/tracks", nil, &tracks)
+	if len(tracks) != 1 || tracks[0].Title != "DARE" || tracks[0].Artist != "Gorillaz feat. Shaun Ryder" || tracks[0].AlbumArtist != "Gorillaz" {
+		t.Errorf("tracks = %+v, want DARE", tracks)
+	}
+
+	var track Track
+	ts.ok(t, http.MethodGet, "/api/library/tracks/2", nil, &track)
+	if track.Title != "Song 2" || track.TrackNumber != 2 || track.Year != 1997 {
+		t.Errorf("track 2 = %+v, want Song 2", track)
+	}
+
+	for path, want := range map[string]int{
+		"/api/library/artists/Oasis/albums":                http.StatusNotFound,
+		"/api/library/artists/Blur/albums/Parklife/tracks": http.StatusNotFound,
+		"/api/library/tracks/42":                           http.StatusNotFound,
+		"/api/library/tracks/x":                            http.StatusBadRequest,
+	} {
+		if code := ts.do(t, http.MethodGet, path, nil, nil); code != want {
+			t.Errorf("GET %s = %d, want %d", path, code, want)
+		}
+	}
+}
+
+func TestServer_Playlists(t *testing.T) {
+	ts := newTestServer(t)
+	folder, err := ts.pls.CreateFolder(nil, "Moods")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mix, err := ts.pls.Create(&folder, "Mix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	smart, err := ts.pls.CreateSmart(nil, "Smart", playlists.DefaultRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var all []Playlist
+	ts.ok(t, http.MethodGet, "/api/playlists", nil, &all)
+	want := []Playlist{
+		{ID: playlists.FavoritesPlaylistID, Name: "Favorites"},
+		{ID: smart, Name: "Smart", Smart: true},
+		{ID: mix, Name: "Mix", Folder: "Moods"},
+	}
+	if len(all) != len(want) {
+		t.Fatalf("playlists = %+v, want %+v", all, want)
+	}
+	for i := range want {
+		if all[i] != want[i] {
+			t.Errorf("playlist %d = %+v, want %+v", i, all[i], want[i])
+		}
+	}
+
+	var tracks []Track
+	ts.ok(t, http.MethodPost, "/api/playlists/"+itoa(mix)+"/tracks", map[string]any{"track_ids": []int64{2, 3}}, &tracks)
+	if titles(tracks) != "Song 2, DARE" {
+		t.Errorf("playlist tracks = %s, want Song 2, DARE", titles(tracks))
+	}
+	ts.ok(t, http.MethodGet, "/api/playlists/"+itoa(mix)+"/tracks", nil, &tracks)
+	if titles(tracks) != "Song 2, DARE" {
+		t.Errorf("playlist tracks = %s, want Song 2, DARE", titles(tracks))
+	}
+
+	var st Status
+	ts.ok(t, http.MethodPost, "/api/playlists/"+itoa(mix)+"/play", nil, &st)
+	if st.State != "playing" || st.QueueLength != 2 || st.Track == nil || st.Track.Title != "Song 2" {
+		t.Errorf("status after playing the playlist = %+v, want Song 2 playing", st)
+	}
+
+	tests := []struct {
+		method, path string
+		body         any
+		want         int
+	}{
+		{http.MethodGet, "/api/playlists/42", nil, http.StatusNotFound},
+		{http.MethodPost, "/api/playlists/" + itoa(smart) + "/tracks", map[string]any{"track_ids": []int64{1}}, http.StatusConflict},
+		{http.MethodPost, "/api/playlists/" + itoa(mix) + "/tracks", map[string]any{"track_ids": []int64{42}}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if code := ts.do(t, tt.method, tt.path, tt.body, nil); code != tt.want {
+			t.Errorf("%s %s %v = %d, want %d", tt.method, tt.path, tt.body, code, tt.want)
+		}
+	}
+}
+
+func TestServer_Favorites(t *testing.T) {
+	ts := newTestServer(t)
+
+	var fav favoriteResponse
+	ts.ok(t, http.MethodPut, "/api/favorites/3", nil, &fav)
+	ts.ok(t, http.MethodPut, "/api/favorites/1", nil, &fav)
+	// Already a favorite
+	ts.ok(t, http.MethodPut, "/api/favorites/3", nil, &fav)
+	if fav != (favoriteResponse{ID: 3, Favorite: true}) {
+		t.Errorf("response = %+v, want 3 favorite", fav)
+	}
+
+	var tracks []Track
+	ts.ok(t, http.MethodGet, "/api/favorites", nil, &tracks)
+	if titles(tracks) != "DARE, Beetlebum" {
+		t.Errorf("favorites = %s, want DARE, Beetlebum", titles(tracks))
+	}
+
+	ts.ok(t, http.MethodDelete, "/api/favorites/3", nil, &fav)
+	ts.ok(t, http.MethodDelete, "/api/favorites/2", nil, &fav)
+	if fav != (favoriteResponse{ID: 2, Favorite: false}) {
+		t.Errorf("response = %+v, want 2 not favorite", fav)
+	}
+	ts.ok(t, http.MethodGet, "/api/favorites", nil, &tracks)
+	if titles(tracks) != "Beetlebum" {
+		t.Errorf("favorites = %s, want Beetlebum", titles(tracks))
+	}
+
+	if code := ts.do(t, http.MethodPut, "/api/favorites/42", nil, nil); code != http.StatusNotFound {
+		t.Errorf("PUT of an unknown track = %d, want 404", code)
+	}
+}
+
+// eventStream reads server-sent events.
+type eventStream struct {
+	t      *testing.T
+	events chan [2]string // Event name and data
+}
+
+func (ts *testServer) events(t *testing.T) *eventStream {
+	t.Helper()
+	resp, err := http.Get(ts.http.URL + "/api/events?token=" + testToken)
+	if err != nil {
+		t.Fatalf("GET /api/events error: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("GET /api/events = %d %s, want an event stream", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	s := &eventStream{t: t, events: make(chan [2]string, 100)}
+	go func() {
+		defer close(s.events)
+		scanner := bufio.NewScanner(resp.Body)
+		var event string
+		for scanner.Scan() {
+			line := scanner.Text()
+			if v, ok := strings.CutPrefix(line, "event: "); ok {
+				event = v
+			} else if v, ok := strings.CutPrefix(line, "data: "); ok {
+				s.events <- [2]string{event, v}
+			}
+		}
+	}()
+	return s
+}
+
+// next reads the next event, which must have a name, into data.
+func (s *eventStream) next(name string, data any) {
+	s.t.Helper()
+	select {
+	case ev, ok := <-s.events:
+		if !ok {
+			s.t.Fatalf("stream closed, want a %s event", name)
+		}
+		if ev[0] != name {
+			s.t.Fatalf("event %s %s, want a %s event", ev[0], ev[1], name)
+		}
+		if err := json.Unmarshal([]byte(ev[1]), data); err != nil {
+			s.t.Fatalf("invalid %s event data %s: %v", name, ev[1], err)
+		}
+	case <-time.After(5 * time.Second):
+		s.t.Fatalf("no %s event", name)
+	}
+}
+
+func TestServer_Events(t *testing.T) {
+	ts := newTestServer(t)
+	stream := ts.events(t)
+
+	var st Status
+	stream.next(eventStatus, &st)
+	if st.State != "stopped" || st.QueueLength != 0 {
+		t.Fatalf("first status = %+v, want stopped with an empty queue", st)
+	}
+
+	ts.ok(t, http.MethodPost, "/api/queue", map[string]any{"track_ids": []int64{1, 2}}, nil)
+	var q Queue
+	stream.next(eventQueue, &q)
+	if titles(q.Tracks) != "Beetlebum, Song 2" {
+		t.Errorf("queue event = %+v, want Beetlebum, Song 2", q)
+	}
+
+	ts.ok(t, http.MethodPatch, "/api/mode", map[string]any{"repeat": "all"}, nil)
+	var m Mode
+	stream.next(eventMode, &m)
+	if m.Repeat != "all" {
+		t.Errorf("mode event = %+v, want repeat all", m)
+	}
+
+	ts.ok(t, http.MethodPost, "/api/playback/play", nil, nil)
+	var state StateEvent
+	stream.next(eventState, &state)
+	if state.State != "playing" {
+		t.Errorf("state event = %+v, want playing", state)
+	}
+	ts.ok(t, http.MethodPost, "/api/playback/next", nil, nil)
+	var track TrackEvent
+	stream.next(eventTrack, &track)
+	if track.Index != 1 || track.Track == nil || track.Track.Title != "Song 2" {
+		t.Errorf("track event = %+v, want Song 2", track)
+	}
+
+	// Streams follow a recreated service
+	svc := playback.New(ts.player, playlist.NewQueue())
+	defer svc.Close()
+	ts.svc.Close()
+	ts.server.SetService(svc)
+	stream.next(eventStatus, &st)
+	if st.QueueLength != 0 {
+		t.Errorf("status of the new service = %+v, want an empty queue", st)
+	}
+	svc.AddTracks(playback.Track{Path: "/music/new.mp3", Title: "New"})
+	stream.next(eventQueue, &q)
+	if titles(q.Tracks) != "New" {
+		t.Errorf("queue event of the new service = %+v, want New", q)
+	}
+
+	// Streams end on Close
+	if err := ts.server.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	select {
+	case ev, ok := <-stream.events:
+		if ok {
+			t.Errorf("event %v after Close(), want the stream closed", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("the stream should end on Close()")
+	}
+}
+
+func itoa(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,104 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/llehouerou/waves/internal/library"
+)
+
+// The library is browsed like in the library view: album artists, their
+// albums, then the tracks of an album.
+
+func (s *Server) handleArtists(w http.ResponseWriter, _ *http.Request) {
+	nodes, err := s.source.Children(s.source.Root())
+	if err != nil {
+		reply(w, nil, err)
+		return
+	}
+	artists := make([]Artist, len(nodes))
+	for i, n := range nodes {
+		artists[i] = Artist{Name: n.Artist()}
+	}
+	writeJSON(w, artists)
+}
+
+func (s *Server) handleAlbums(w http.ResponseWriter, r *http.Request) {
+	artist, err := s.child(s.source.Root(), r.PathValue("artist"), library.Node.Artist)
+	if err != nil {
+		reply(w, nil, err)
+		return
+	}
+	nodes, err := s.source.Children(artist)
+	if err != nil {
+		reply(w, nil, err)
+		return
+	}
+	albums := make([]Album, len(nodes))
+	for i, n := range nodes {
+		albums[i] = Album{Artist: n.Artist(), Name: n.Album(), Year: n.Year(), Rating: n.Rating()}
+	}
+	writeJSON(w, albums)
+}
+
+func (s *Server) handleAlbumTracks(w http.ResponseWriter, r *http.Request) {
+	artist, err := s.child(s.source.Root(), r.PathValue("artist"), library.Node.Artist)
+	if err != nil {
+		reply(w, nil, err)
+		return
+	}
+	album, err := s.child(artist, r.PathValue("album"), library.Node.Album)
+	if err != nil {
+		reply(w, nil, err)
+		return
+	}
+	nodes, err := s.source.Children(album)
+	if err != nil {
+		reply(w, nil, err)
+		return
+	}
+	tracks := make([]Track, 0, len(nodes))
+	for _, n := range nodes {
+		if t := n.Track(); t != nil {
+			tracks = append(tracks, newLibraryTrack(t))
+		}
+	}
+	writeJSON(w, tracks)
+}
+
+// child returns the child of a node whose key is name.
+func (s *Server) child(parent library.Node, name string, key func(library.Node) string) (library.Node, error) {
+	nodes, err := s.source.Children(parent)
+	if err != nil {
+		return library.Node{}, err
+	}
+	for _, n := range nodes {
+		if key(n) == name {
+			return n, nil
+		}
+	}
+	return library.Node{}, notFound(name + " not found")
+}
+
+func (s *Server) handleTrack(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		reply(w, nil, err)
+		return
+	}
+	t, err := s.libraryTrack(id)
+	if err != nil {
+		reply(w, nil, err)
+		return
+	}
+	writeJSON(w, newLibraryTrack(t))
+}
+
+// pathID parses the id of the request path.
+func pathID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return 0, badRequest("invalid id " + r.PathValue("id"))
+	}
+	return id, nil
+}
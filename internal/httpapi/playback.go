@@ -0,0 +1,176 @@
+package httpapi
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/player"
+)
+
+// playbackActions are the actions of POST /api/playback/{action}.
+var playbackActions = map[string]func(playback.Service) error{
+	"play":     play,
+	"pause":    playback.Service.Pause,
+	"toggle":   playback.Service.Toggle,
+	"stop":     playback.Service.Stop,
+	"next":     playback.Service.Next,
+	"previous": playback.Service.Previous,
+}
+
+// repeatModes are the repeat modes by name.
+var repeatModes = map[string]playback.RepeatMode{
+	"off":   playback.RepeatOff,
+	"all":   playback.RepeatAll,
+	"one":   playback.RepeatOne,
+	"radio": playback.RepeatRadio,
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, status(s.svc.Service()))
+}
+
+// playbackAction answers the status once an action ran.
+func (s *Server) playbackAction(run func(playback.Service) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		svc := s.svc.Service()
+		if err := run(svc); err != nil {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeJSON(w, status(svc))
+	}
+}
+
+// play starts the queue when stopped and resumes it when paused.
+func play(svc playback.Service) error {
+	switch svc.State() {
+	case playback.StatePlaying:
+		return nil
+	case playback.StatePaused:
+		return svc.Toggle()
+	case playback.StateStopped:
+	}
+	if svc.QueueCurrentIndex() < 0 && !svc.QueueIsEmpty() {
+		svc.QueueMoveTo(0)
+	}
+	return svc.Play()
+}
+
+// playIndex plays the track of the queue at an index.
+func playIndex(svc playback.Service, index int) error {
+	if err := svc.JumpTo(index); err != nil {
+		return err
+	}
+	return play(svc)
+}
+
+type seekRequest struct {
+	Position float64 `json:"position"` // Seconds
+	Relative bool    `json:"relative"` // Position is added to the current one
+}
+
+func (s *Server) handleSeek(w http.ResponseWriter, r *http.Request) {
+	var req seekRequest
+	if err := decode(r, &req); err != nil {
+		reply(w, nil, err)
+		return
+	}
+	svc := s.svc.Service()
+	d := time.Duration(req.Position * float64(time.Second))
+	var err error
+	if req.Relative {
+		err = svc.Seek(d)
+	} else {
+		err = svc.SeekTo(d)
+	}
+	if err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeJSON(w, status(svc))
+}
+
+type volumeRequest struct {
+	Volume   *float64 `json:"volume"`   // Percent
+	Relative bool     `json:"relative"` // Volume is added to the current one
+	Muted    *bool    `json:"muted"`
+}
+
+// handleVolume sets the volume, unmuting the player like the volume keys
+// unless muted is given.
+func (s *Server) handleVolume(w http.ResponseWriter, r *http.Request) {
+	var req volumeRequest
+	if err := decode(r, &req); err != nil {
+		reply(w, nil, err)
+		return
+	}
+	svc := s.svc.Service()
+	p := svc.Player()
+	if req.Volume != nil {
+		setVolume(svc, *req.Volume, req.Relative)
+		svc.SetMuted(false)
+	}
+	if req.Muted != nil {
+		svc.SetMuted(*req.Muted)
+	}
+	if s.volumes != nil {
+		if err := s.volumes.SaveVolume(p.Volume(), p.Muted()); err != nil {
+			reply(w, nil, err)
+			return
+		}
+	}
+	writeJSON(w, status(svc))
+}
+
+func setVolume(svc playback.Service, percent float64, relative bool) {
+	level := percent / 100
+	if relative {
+		level += svc.Player().Volume()
+	}
+	svc.SetVolume(math.Round(max(0, min(1, level))*100) / 100)
+}
+
+func (s *Server) handleMode(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, mode(s.svc.Service()))
+}
+
+type modeRequest struct {
+	Repeat  *string  `json:"repeat"`
+	Shuffle *bool    `json:"shuffle"`
+	Speed   *float64 `json:"speed"`
+}
+
+// handleSetMode changes the modes given, leaving the others.
+func (s *Server) handleSetMode(w http.ResponseWriter, r *http.Request) {
+	var req modeRequest
+	if err := decode(r, &req); err != nil {
+		reply(w, nil, err)
+		return
+	}
+	var repeat playback.RepeatMode
+	if req.Repeat != nil {
+		var ok bool
+		if repeat, ok = repeatModes[*req.Repeat]; !ok {
+			writeError(w, http.StatusBadRequest, "unknown repeat mode "+*req.Repeat)
+			return
+		}
+	}
+	if req.Speed != nil && (*req.Speed < player.MinSpeed || *req.Speed > player.MaxSpeed) {
+		writeError(w, http.StatusBadRequest, "speed out of range")
+		return
+	}
+
+	svc := s.svc.Service()
+	if req.Repeat != nil {
+		svc.SetRepeatMode(repeat)
+	}
+	if req.Shuffle != nil {
+		svc.SetShuffle(*req.Shuffle)
+	}
+	if req.Speed != nil {
+		svc.SetSpeed(*req.Speed)
+	}
+	writeJSON(w, mode(svc))
+}
@@ -0,0 +1,178 @@
+package httpapi
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/playlists"
+)
+
+// handlePlaylists lists the playlists of all folders.
+func (s *Server) handlePlaylists(w http.ResponseWriter, _ *http.Request) {
+	var all []Playlist
+	err := s.walkPlaylists(nil, "", func(pl playlists.Playlist, folder string) {
+		all = append(all, newPlaylist(pl, folder))
+	})
+	reply(w, all, err)
+}
+
+// walkPlaylists calls fn for the playlists of a folder, named path, then
+// for those of its subfolders.
+func (s *Server) walkPlaylists(folderID *int64, path string, fn func(playlists.Playlist, string)) error {
+	pls, err := s.pls.List(folderID)
+	if err != nil {
+		return err
+	}
+	for _, pl := range pls {
+		fn(pl, path)
+	}
+	folders, err := s.pls.Folders(folderID)
+	if err != nil {
+		return err
+	}
+	for _, f := range folders {
+		sub := f.Name
+		if path != "" {
+			sub = path + "/" + f.Name
+		}
+		if err := s.walkPlaylists(&f.ID, sub, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// playlist returns the playlist with the id of the request path.
+func (s *Server) playlist(r *http.Request) (*playlists.Playlist, error) {
+	id, err := pathID(r)
+	if err != nil {
+		return nil, err
+	}
+	pl, err := s.pls.Get(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFound(fmt.Sprintf("no playlist with id %d", id))
+	}
+	return pl, err
+}
+
+func (s *Server) handlePlaylist(w http.ResponseWriter, r *http.Request) {
+	pl, err := s.playlist(r)
+	if err != nil {
+		reply(w, nil, err)
+		return
+	}
+	writeJSON(w, newPlaylist(*pl, ""))
+}
+
+func (s *Server) handlePlaylistTracks(w http.ResponseWriter, r *http.Request) {
+	pl, err := s.playlist(r)
+	if err != nil {
+		reply(w, nil, err)
+		return
+	}
+	tracks, err := s.pls.Tracks(pl.ID)
+	if err != nil {
+		reply(w, nil, err)
+		return
+	}
+	writeJSON(w, newPlaylistTracks(tracks))
+}
+
+type playlistAddRequest struct {
+	TrackIDs []int64 `json:"track_ids"` // Library tracks
+}
+
+func (s *Server) handlePlaylistAdd(w http.ResponseWriter, r *http.Request) {
+	var req playlistAddRequest
+	if err := decode(r, &req); err != nil {
+		reply(w, nil, err)
+		return
+	}
+	pl, err := s.playlist(r)
+	if err != nil {
+		reply(w, nil, err)
+		return
+	}
+	for _, id := range req.TrackIDs {
+		if _, err := s.libraryTrack(id); err != nil {
+			reply(w, nil, err)
+			return
+		}
+	}
+	if err := s.pls.AddTracks(pl.ID, req.TrackIDs); err != nil {
+		if errors.Is(err, playlists.ErrSmartPlaylist) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		reply(w, nil, err)
+		return
+	}
+	tracks, err := s.pls.Tracks(pl.ID)
+	reply(w, newPlaylistTracks(tracks), err)
+}
+
+// handlePlaylistPlay replaces the queue with the tracks of a playlist and
+// plays them from the start.
+func (s *Server) handlePlaylistPlay(w http.ResponseWriter, r *http.Request) {
+	pl, err := s.playlist(r)
+	if err != nil {
+		reply(w, nil, err)
+		return
+	}
+	tracks, err := s.pls.Tracks(pl.ID)
+	if err != nil {
+		reply(w, nil, err)
+		return
+	}
+	if len(tracks) == 0 {
+		writeError(w, http.StatusConflict, "the playlist is empty")
+		return
+	}
+	svc := s.svc.Service()
+	svc.ReplaceTracks(playback.TracksFromPlaylist(tracks)...)
+	if err := playIndex(svc, 0); err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	_ = s.pls.UpdateLastUsed(pl.ID)
+	writeJSON(w, status(svc))
+}
+
+func (s *Server) handleFavorites(w http.ResponseWriter, _ *http.Request) {
+	tracks, err := s.pls.Tracks(playlists.FavoritesPlaylistID)
+	if err != nil {
+		reply(w, nil, err)
+		return
+	}
+	writeJSON(w, newPlaylistTracks(tracks))
+}
+
+// favoriteResponse answers the favorite status of a track.
+type favoriteResponse struct {
+	ID       int64 `json:"id"`
+	Favorite bool  `json:"favorite"`
+}
+
+// handleSetFavorite adds the track of the request path to the favorites, or
+// removes it.
+func (s *Server) handleSetFavorite(favorite bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathID(r)
+		if err != nil {
+			reply(w, nil, err)
+			return
+		}
+		if _, err := s.libraryTrack(id); err != nil {
+			reply(w, nil, err)
+			return
+		}
+		is, err := s.pls.IsFavorite(id)
+		if err == nil && is != favorite {
+			_, err = s.pls.ToggleFavorite(id)
+		}
+		reply(w, favoriteResponse{ID: id, Favorite: favorite}, err)
+	}
+}
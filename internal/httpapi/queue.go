@@ -0,0 +1,145 @@
+package httpapi
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/llehouerou/waves/internal/library"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/playlist"
+)
+
+func (s *Server) handleQueue(w http.ResponseWriter, _ *http.Request) {
+	svc := s.svc.Service()
+	writeJSON(w, newQueue(svc.QueueTracks(), svc.QueueCurrentIndex()))
+}
+
+type queueAddRequest struct {
+	TrackIDs []int64  `json:"track_ids"` // Library tracks
+	Paths    []string `json:"paths"`     // Absolute paths of files or folders
+	Next     bool     `json:"next"`      // Insert after the current track instead of appending
+}
+
+// handleQueueAdd adds library tracks, then files, to the queue.
+func (s *Server) handleQueueAdd(w http.ResponseWriter, r *http.Request) {
+	var req queueAddRequest
+	if err := decode(r, &req); err != nil {
+		reply(w, nil, err)
+		return
+	}
+	tracks, err := s.collectTracks(req.TrackIDs, req.Paths)
+	if err != nil {
+		reply(w, nil, err)
+		return
+	}
+	svc := s.svc.Service()
+	if req.Next {
+		svc.InsertTracks(svc.QueueCurrentIndex()+1, tracks...)
+	} else {
+		svc.AddTracks(tracks...)
+	}
+	writeJSON(w, newQueue(svc.QueueTracks(), svc.QueueCurrentIndex()))
+}
+
+// collectTracks returns the tracks of library IDs and paths.
+func (s *Server) collectTracks(ids []int64, paths []string) ([]playback.Track, error) {
+	var tracks []playlist.Track
+	for _, id := range ids {
+		t, err := s.libraryTrack(id)
+		if err != nil {
+			return nil, err
+		}
+		tracks = append(tracks, playlist.FromLibraryTrack(*t))
+	}
+	for _, path := range paths {
+		if !filepath.IsAbs(path) {
+			return nil, badRequest(fmt.Sprintf("%s: path is not absolute", path))
+		}
+	}
+	fromPaths, err := playlist.CollectFromPaths(paths, s.lib.TrackByPath)
+	if err != nil {
+		return nil, badRequest(err.Error())
+	}
+	tracks = append(tracks, fromPaths...)
+	if len(tracks) == 0 {
+		return nil, badRequest("no tracks found")
+	}
+	return playback.TracksFromPlaylist(tracks), nil
+}
+
+// libraryTrack returns the library track with an ID.
+func (s *Server) libraryTrack(id int64) (*library.Track, error) {
+	t, err := s.lib.TrackByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFound(fmt.Sprintf("no track with id %d", id))
+	}
+	return t, err
+}
+
+func (s *Server) handleQueueClear(w http.ResponseWriter, _ *http.Request) {
+	svc := s.svc.Service()
+	svc.ClearQueue()
+	writeJSON(w, newQueue(svc.QueueTracks(), svc.QueueCurrentIndex()))
+}
+
+// queueIndex parses the index of the request path, checking the queue has
+// a track there.
+func queueIndex(svc playback.Service, r *http.Request) (int, error) {
+	index, err := strconv.Atoi(r.PathValue("index"))
+	if err != nil || index < 0 || index >= svc.QueueLen() {
+		return 0, notFound("no track at index " + r.PathValue("index"))
+	}
+	return index, nil
+}
+
+func (s *Server) handleQueueRemove(w http.ResponseWriter, r *http.Request) {
+	svc := s.svc.Service()
+	index, err := queueIndex(svc, r)
+	if err != nil {
+		reply(w, nil, err)
+		return
+	}
+	svc.RemoveTracks(index)
+	writeJSON(w, newQueue(svc.QueueTracks(), svc.QueueCurrentIndex()))
+}
+
+type queueMoveRequest struct {
+	To int `json:"to"` // New index of the track
+}
+
+func (s *Server) handleQueueMove(w http.ResponseWriter, r *http.Request) {
+	var req queueMoveRequest
+	if err := decode(r, &req); err != nil {
+		reply(w, nil, err)
+		return
+	}
+	svc := s.svc.Service()
+	index, err := queueIndex(svc, r)
+	if err != nil {
+		reply(w, nil, err)
+		return
+	}
+	if !svc.MoveTracks([]int{index}, req.To-index) {
+		writeError(w, http.StatusBadRequest, "index out of range")
+		return
+	}
+	writeJSON(w, newQueue(svc.QueueTracks(), svc.QueueCurrentIndex()))
+}
+
+func (s *Server) handleQueuePlay(w http.ResponseWriter, r *http.Request) {
+	svc := s.svc.Service()
+	index, err := queueIndex(svc, r)
+	if err != nil {
+		reply(w, nil, err)
+		return
+	}
+	if err := playIndex(svc, index); err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeJSON(w, status(svc))
+}
@@ -0,0 +1,161 @@
+package httpapi
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/llehouerou/waves/internal/library"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/playlist"
+	"github.com/llehouerou/waves/internal/playlists"
+)
+
+// Track is a track of the queue, the library or a playlist.
+type Track struct {
+	ID          int64   `json:"id,omitempty"` // Library ID, 0 for files outside the library
+	Path        string  `json:"path"`
+	Title       string  `json:"title"`
+	Artist      string  `json:"artist"`
+	AlbumArtist string  `json:"album_artist,omitempty"` // Library tracks
+	Album       string  `json:"album"`
+	TrackNumber int     `json:"track_number,omitempty"`
+	DiscNumber  int     `json:"disc_number,omitempty"`
+	Year        int     `json:"year,omitempty"`
+	Genre       string  `json:"genre,omitempty"`
+	Duration    float64 `json:"duration,omitempty"` // Seconds, when known
+	Rating      int     `json:"rating,omitempty"`   // Library tracks: half stars, 0 to 10
+}
+
+// Queue is the playing queue.
+type Queue struct {
+	Tracks []Track `json:"tracks"`
+	Index  int     `json:"index"` // Current track, -1 if none
+}
+
+// Status is the playback state.
+type Status struct {
+	State       string  `json:"state"` // "playing", "paused" or "stopped"
+	Track       *Track  `json:"track,omitempty"`
+	Index       int     `json:"index"`
+	QueueLength int     `json:"queue_length"`
+	Position    float64 `json:"position"` // Seconds
+	Duration    float64 `json:"duration"` // Seconds, 0 for streams
+	Volume      int     `json:"volume"`   // Percent
+	Muted       bool    `json:"muted"`
+	Mode
+}
+
+// Mode holds the playback modes.
+type Mode struct {
+	Repeat  string  `json:"repeat"` // "off", "all", "one" or "radio"
+	Shuffle bool    `json:"shuffle"`
+	Speed   float64 `json:"speed"`
+	Sleep   string  `json:"sleep"` // "off", "after", "end_of_track" or "end_of_album"
+}
+
+// Artist is an album artist of the library.
+type Artist struct {
+	Name string `json:"name"`
+}
+
+// Album is an album of the library.
+type Album struct {
+	Artist string `json:"artist"`
+	Name   string `json:"name"`
+	Year   int    `json:"year,omitempty"`
+	Rating int    `json:"rating,omitempty"` // Half stars, 0 to 10
+}
+
+// Playlist is a playlist of the library.
+type Playlist struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	Folder string `json:"folder,omitempty"` // Folders from the top, joined by "/"
+	Smart  bool   `json:"smart,omitempty"`  // Tracks come from rules and can't be edited
+}
+
+func newTrack(t playback.Track) Track {
+	return Track{
+		ID:          t.ID,
+		Path:        t.Path,
+		Title:       t.Title,
+		Artist:      t.Artist,
+		Album:       t.Album,
+		TrackNumber: t.TrackNumber,
+		DiscNumber:  t.DiscNumber,
+		Year:        t.Year,
+		Genre:       t.Genre,
+		Duration:    t.Duration.Seconds(),
+	}
+}
+
+func newLibraryTrack(t *library.Track) Track {
+	return Track{
+		ID:          t.ID,
+		Path:        t.Path,
+		Title:       t.Title,
+		Artist:      t.Artist,
+		AlbumArtist: t.AlbumArtist,
+		Album:       t.Album,
+		TrackNumber: t.TrackNumber,
+		DiscNumber:  t.DiscNumber,
+		Year:        t.Year,
+		Genre:       t.Genre,
+		Rating:      t.Rating,
+	}
+}
+
+func newPlaylistTracks(tracks []playlist.Track) []Track {
+	out := make([]Track, len(tracks))
+	for i, t := range tracks {
+		out[i] = newTrack(playback.TrackFromPlaylist(t))
+	}
+	return out
+}
+
+func newQueue(tracks []playback.Track, index int) Queue {
+	q := Queue{Tracks: make([]Track, len(tracks)), Index: index}
+	for i, t := range tracks {
+		q.Tracks[i] = newTrack(t)
+	}
+	return q
+}
+
+func newPlaylist(pl playlists.Playlist, folder string) Playlist {
+	return Playlist{ID: pl.ID, Name: pl.Name, Folder: folder, Smart: pl.Smart}
+}
+
+// status reads the playback state.
+func status(svc playback.Service) Status {
+	p := svc.Player()
+	st := Status{
+		State:       name(svc.State()),
+		Index:       svc.QueueCurrentIndex(),
+		QueueLength: svc.QueueLen(),
+		Position:    svc.Position().Seconds(),
+		Duration:    svc.Duration().Seconds(),
+		Volume:      int(math.Round(p.Volume() * 100)),
+		Muted:       p.Muted(),
+		Mode:        mode(svc),
+	}
+	if t := svc.CurrentTrack(); t != nil {
+		track := newTrack(*t)
+		st.Track = &track
+	}
+	return st
+}
+
+func mode(svc playback.Service) Mode {
+	return Mode{
+		Repeat:  name(svc.RepeatMode()),
+		Shuffle: svc.Shuffle(),
+		Speed:   svc.Speed(),
+		Sleep:   name(svc.SleepTimer().Mode),
+	}
+}
+
+// name returns the name of a state or mode in lower snake case.
+func name(s fmt.Stringer) string {
+	return strings.ReplaceAll(strings.ToLower(s.String()), " ", "_")
+}
@@ -1,5 +1,7 @@
 package icons
 
+import "strings"
+
 // Style represents the icon style to use.
 type Style string
 
@@ -16,6 +18,7 @@ type Icons struct {
 	Artist       string
 	Album        string
 	Playlist     string
+	Smart        string
 	Shuffle      string
 	RepeatAll    string
 	RepeatOne    string
@@ -29,6 +32,8 @@ type Icons struct {
 	VolumeOff    string
 	VolumeMute   string
 	InLibrary    string
+	Star         string
+	HalfStar     string
 }
 
 var (
@@ -38,6 +43,7 @@ var (
 		Artist:       "\uf007 ", // nf-fa-user
 		Album:        "󰀥 ",      // nf-md-album
 		Playlist:     "󰲸 ",      // nf-md-playlist_music
+		Smart:        "\uf0b0 ", // nf-fa-filter
 		Shuffle:      "󰒟",       // nf-md-shuffle
 		RepeatAll:    "󰑖",       // nf-md-repeat
 		RepeatOne:    "󰑘",       // nf-md-repeat_once
@@ -51,6 +57,8 @@ var (
 		VolumeOff:    "󰝟",       // nf-md-volume_off
 		VolumeMute:   "󰖁",       // nf-md-volume_mute
 		InLibrary:    "󰄬",       // nf-md-check
+		Star:         "\uf005",  // nf-fa-star
+		HalfStar:     "\uf123",  // nf-fa-star_half_o
 	}
 
 	unicodeIcons = Icons{
@@ -59,6 +67,7 @@ var (
 		Artist:       "👤 ",
 		Album:        "💿 ",
 		Playlist:     "📋 ",
+		Smart:        "✨ ",
 		Shuffle:      "🔀",
 		RepeatAll:    "🔁",
 		RepeatOne:    "🔂",
@@ -72,6 +81,8 @@ var (
 		VolumeOff:    "🔇",
 		VolumeMute:   "🔇",
 		InLibrary:    "✓",
+		Star:         "★",
+		HalfStar:     "½",
 	}
 
 	noneIcons = Icons{
@@ -80,6 +91,7 @@ var (
 		Artist:       "",
 		Album:        "",
 		Playlist:     "",
+		Smart:        "",
 		Shuffle:      "[S]",
 		RepeatAll:    "[R]",
 		RepeatOne:    "[1]",
@@ -93,6 +105,8 @@ var (
 		VolumeOff:    "[0]",
 		VolumeMute:   "[X]",
 		InLibrary:    "*",
+		Star:         "*",
+		HalfStar:     "+",
 	}
 
 	// current holds the active icon set
@@ -166,6 +180,14 @@ func FormatPlaylist(name string) string {
 	return current.Playlist + name
 }
 
+// FormatSmartPlaylist formats a smart playlist name with the appropriate icon.
+func FormatSmartPlaylist(name string) string {
+	if current == noneIcons {
+		return name
+	}
+	return current.Smart + name
+}
+
 // Shuffle returns the shuffle icon.
 func Shuffle() string {
 	return current.Shuffle
@@ -222,3 +244,16 @@ func VolumeIcon(level float64, muted bool) string {
 func InLibrary() string {
 	return current.InLibrary
 }
+
+// Rating formats a rating in half stars as stars, e.g. "★★★½".
+// Returns an empty string for unrated items.
+func Rating(halfStars int) string {
+	if halfStars <= 0 {
+		return ""
+	}
+	s := strings.Repeat(current.Star, halfStars/2)
+	if halfStars%2 == 1 {
+		s += current.HalfStar
+	}
+	return s
+}
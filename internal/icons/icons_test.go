@@ -481,3 +481,27 @@ func TestFormatFunctionsWithSpecialCharacters(t *testing.T) {
 
 	Init("none")
 }
+
+func TestRating(t *testing.T) {
+	tests := []struct {
+		style     string
+		halfStars int
+		expected  string
+	}{
+		{"unicode", 0, ""},
+		{"unicode", 1, "½"},
+		{"unicode", 7, "★★★½"},
+		{"unicode", 10, "★★★★★"},
+		{"none", 6, "***"},
+		{"none", 3, "*+"},
+	}
+
+	for _, tt := range tests {
+		Init(tt.style)
+		if got := Rating(tt.halfStars); got != tt.expected {
+			t.Errorf("%s: Rating(%d) = %q, want %q", tt.style, tt.halfStars, got, tt.expected)
+		}
+	}
+
+	Init("none")
+}
@@ -0,0 +1,97 @@
+// Package icy plays HTTP audio streams, such as internet radio stations.
+// It buffers the stream ahead of the decoder, reconnects when the
+// connection drops, and reads the ICY (SHOUTcast/Icecast) metadata that
+// stations interleave with the audio to announce what is playing.
+package icy
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// IsURL returns true if path is an HTTP(S) URL rather than a file.
+func IsURL(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// Codec is the audio format of a stream.
+type Codec int
+
+const (
+	CodecUnknown Codec = iota
+	CodecMP3
+	CodecAAC // Raw AAC in ADTS frames
+	CodecOgg // Vorbis or Opus in an Ogg container
+)
+
+// String returns the name of the codec, empty when unknown.
+func (c Codec) String() string {
+	switch c {
+	case CodecMP3:
+		return "MP3"
+	case CodecAAC:
+		return "AAC"
+	case CodecOgg:
+		return "OGG"
+	case CodecUnknown:
+	}
+	return ""
+}
+
+// detectCodec finds the codec of a stream from its content type, falling
+// back to the extension of its URL for servers that don't send one.
+func detectCodec(contentType, rawURL string) Codec {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "audio/mpeg", "audio/mp3", "audio/mpeg3", "audio/x-mpeg":
+		return CodecMP3
+	case "audio/aac", "audio/aacp", "audio/x-aac", "audio/x-aacp":
+		return CodecAAC
+	case "application/ogg", "audio/ogg", "audio/opus", "audio/vorbis", "audio/x-ogg":
+		return CodecOgg
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return CodecUnknown
+	}
+	switch strings.ToLower(path.Ext(u.Path)) {
+	case ".mp3":
+		return CodecMP3
+	case ".aac", ".aacp":
+		return CodecAAC
+	case ".ogg", ".oga", ".opus":
+		return CodecOgg
+	}
+	return CodecUnknown
+}
+
+// Info describes a stream, from the headers sent by the server.
+type Info struct {
+	Name        string // Station name
+	Genre       string
+	Description string
+	Bitrate     int // In kbit/s, 0 if unknown
+	ContentType string
+	Codec       Codec
+}
+
+// infoFromResponse reads the ICY headers of a stream response.
+func infoFromResponse(resp *http.Response, rawURL string) Info {
+	h := resp.Header
+	bitrate, _ := strconv.Atoi(strings.TrimSpace(strings.Split(h.Get("icy-br"), ",")[0]))
+	contentType := h.Get("Content-Type")
+	return Info{
+		Name:        strings.TrimSpace(h.Get("icy-name")),
+		Genre:       strings.TrimSpace(h.Get("icy-genre")),
+		Description: strings.TrimSpace(h.Get("icy-description")),
+		Bitrate:     bitrate,
+		ContentType: contentType,
+		Codec:       detectCodec(contentType, rawURL),
+	}
+}
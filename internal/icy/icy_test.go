@@ -0,0 +1,303 @@
+package icy
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// icyBody interleaves audio with metadata blocks every interval bytes,
+// announcing titles in turn.
+func icyBody(audio []byte, interval int, titles ...string) []byte {
+	var b bytes.Buffer
+	for i := 0; len(audio) > 0; i++ {
+		n := min(interval, len(audio))
+		b.Write(audio[:n])
+		audio = audio[n:]
+		if n < interval {
+			break
+		}
+		if i >= len(titles) {
+			b.WriteByte(0)
+			continue
+		}
+		meta := []byte("StreamTitle='" + titles[i] + "';")
+		blocks := (len(meta) + 15) / 16
+		b.WriteByte(byte(blocks))
+		b.Write(meta)
+		b.Write(make([]byte, blocks*16-len(meta)))
+	}
+	return b.Bytes()
+}
+
+// audioBytes returns n bytes of recognizable audio.
+func audioBytes(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i % 251)
+	}
+	return b
+}
+
+func shortTimings(t *testing.T) {
+	t.Helper()
+	oldTimeout, oldDelay := prebufferTimeout, reconnectDelay
+	prebufferTimeout, reconnectDelay = 50*time.Millisecond, time.Millisecond
+	t.Cleanup(func() { prebufferTimeout, reconnectDelay = oldTimeout, oldDelay })
+}
+
+func TestStream_StripsMetadataAndUpdatesTitle(t *testing.T) {
+	shortTimings(t)
+	const interval = 1000
+	audio := audioBytes(4500)
+	body := icyBody(audio, interval, "Artist - First", "Artist - Second")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Icy-MetaData") != "1" {
+			t.Error("metadata should be requested")
+		}
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Header().Set("icy-metaint", strconv.Itoa(interval))
+		w.Header().Set("icy-name", "Test Radio")
+		w.Header().Set("icy-br", "128")
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	s, err := Open(srv.URL + "/live")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer s.Close()
+
+	info := s.Info()
+	if info.Name != "Test Radio" || info.Bitrate != 128 || info.Codec != CodecMP3 {
+		t.Errorf("Info() = %+v", info)
+	}
+
+	got, err := io.ReadAll(s)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if !bytes.Equal(got, audio) {
+		t.Fatalf("read %d bytes, want the %d audio bytes without metadata", len(got), len(audio))
+	}
+	if title := s.Title(); title != "Artist - Second" {
+		t.Errorf("Title() = %q, want the last announced title", title)
+	}
+}
+
+func TestStream_Reconnects(t *testing.T) {
+	shortTimings(t)
+	var connections atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// Each connection sends a bit of audio and drops, like a flaky
+		// station; the third one fails outright
+		n := connections.Add(1)
+		if n == 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "audio/aac")
+		w.(http.Flusher).Flush()
+		_, _ = w.Write([]byte{byte(n)})
+	}))
+	defer srv.Close()
+
+	s, err := Open(srv.URL)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer s.Close()
+	if s.Info().Codec != CodecAAC {
+		t.Errorf("Codec = %v, want AAC", s.Info().Codec)
+	}
+
+	buf := make([]byte, 1)
+	for _, want := range []byte{1, 2, 4} {
+		if _, err := io.ReadFull(s, buf); err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		if buf[0] != want {
+			t.Fatalf("read %d, want audio of connection %d", buf[0], want)
+		}
+	}
+}
+
+func TestStream_GivesUp(t *testing.T) {
+	shortTimings(t)
+	var connections atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if connections.Add(1) > 1 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.(http.Flusher).Flush() // No length: a station, not a file
+		_, _ = w.Write([]byte("audio"))
+	}))
+	defer srv.Close()
+
+	s, err := Open(srv.URL)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer s.Close()
+
+	got, err := io.ReadAll(s)
+	if err == nil {
+		t.Fatal("expected an error once reconnecting fails")
+	}
+	if string(got) != "audio" {
+		t.Errorf("read %q, want the buffered audio first", got)
+	}
+	if n := connections.Load(); n != maxReconnects+1 {
+		t.Errorf("connections = %d, want %d", n, maxReconnects+1)
+	}
+}
+
+func TestOpen_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	if _, err := Open(srv.URL); err == nil {
+		t.Error("expected an error for a missing stream")
+	}
+}
+
+func TestStream_CloseUnblocksRead(t *testing.T) {
+	shortTimings(t)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	s, err := Open(srv.URL)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	done := make(chan error)
+	go func() {
+		_, err := s.Read(make([]byte, 1))
+		done <- err
+	}()
+	s.Close()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrClosed) {
+			t.Errorf("Read() error = %v, want ErrClosed", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Read() still blocked after Close()")
+	}
+}
+
+func TestStream_ShoutcastStatusLine(t *testing.T) {
+	shortTimings(t)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = http.ReadRequest(bufio.NewReader(conn))
+		_, _ = io.WriteString(conn, "ICY 200 OK\r\nicy-name:Old Radio\r\ncontent-type:audio/mpeg\r\n\r\naudio")
+	}()
+
+	s, err := Open("http://" + ln.Addr().String() + "/")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer s.Close()
+	if name := s.Info().Name; name != "Old Radio" {
+		t.Errorf("Name = %q", name)
+	}
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(s, buf); err != nil || string(buf) != "audio" {
+		t.Errorf("read %q, %v", buf, err)
+	}
+}
+
+func TestParseMetadata(t *testing.T) {
+	block := []byte("StreamTitle='Guns N' Roses - Don't Cry';StreamUrl='http://x';\x00\x00\x00")
+	fields := parseMetadata(block)
+	if got := fields["StreamTitle"]; got != "Guns N' Roses - Don't Cry" {
+		t.Errorf("StreamTitle = %q", got)
+	}
+	if got := fields["StreamUrl"]; got != "http://x" {
+		t.Errorf("StreamUrl = %q", got)
+	}
+
+	latin1 := parseMetadata([]byte("StreamTitle='Caf\xe9';"))
+	if got := latin1["StreamTitle"]; got != "Café" {
+		t.Errorf("Latin-1 StreamTitle = %q", got)
+	}
+}
+
+func TestSplitTitle(t *testing.T) {
+	tests := []struct {
+		in, artist, title string
+	}{
+		{"Artist - Title", "Artist", "Title"},
+		{"A - B - C", "A", "B - C"},
+		{"Station jingle", "", "Station jingle"},
+		{" - Title", "", "- Title"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		artist, title := SplitTitle(tt.in)
+		if artist != tt.artist || title != tt.title {
+			t.Errorf("SplitTitle(%q) = %q, %q, want %q, %q", tt.in, artist, title, tt.artist, tt.title)
+		}
+	}
+}
+
+func TestDetectCodec(t *testing.T) {
+	tests := []struct {
+		contentType, url string
+		want             Codec
+	}{
+		{"audio/mpeg", "http://x/live", CodecMP3},
+		{"audio/aacp; charset=utf-8", "http://x/live", CodecAAC},
+		{"application/ogg", "http://x/live", CodecOgg},
+		{"", "http://x/radio.opus?session=1", CodecOgg},
+		{"application/octet-stream", "http://x/radio.mp3", CodecMP3},
+		{"text/html", "http://x/", CodecUnknown},
+	}
+	for _, tt := range tests {
+		if got := detectCodec(tt.contentType, tt.url); got != tt.want {
+			t.Errorf("detectCodec(%q, %q) = %v, want %v", tt.contentType, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestIsURL(t *testing.T) {
+	for path, want := range map[string]bool{
+		"http://radio.example/live": true,
+		"HTTPS://radio.example":     true,
+		"/music/http.mp3":           false,
+		"cue:/music/album.cue#3":    false,
+	} {
+		if got := IsURL(path); got != want {
+			t.Errorf("IsURL(%q) = %v", path, got)
+		}
+	}
+}
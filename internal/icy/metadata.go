@@ -0,0 +1,106 @@
+package icy
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// metaReader strips the metadata blocks a server interleaves with the
+// audio when asked to with the Icy-MetaData header. After every interval
+// bytes of audio comes one length byte, then length*16 bytes of metadata.
+type metaReader struct {
+	r        io.Reader
+	interval int // Audio bytes between metadata blocks, 0 without metadata
+	left     int // Audio bytes before the next block
+	onTitle  func(title string)
+}
+
+func newMetaReader(r io.Reader, interval int, onTitle func(string)) *metaReader {
+	return &metaReader{r: r, interval: interval, left: interval, onTitle: onTitle}
+}
+
+// Read reads audio bytes only.
+func (m *metaReader) Read(p []byte) (int, error) {
+	if m.interval <= 0 {
+		return m.r.Read(p)
+	}
+	if m.left == 0 {
+		if err := m.readMetadata(); err != nil {
+			return 0, err
+		}
+		m.left = m.interval
+	}
+	if len(p) > m.left {
+		p = p[:m.left]
+	}
+	n, err := m.r.Read(p)
+	m.left -= n
+	return n, err
+}
+
+// readMetadata reads a metadata block. Servers send empty blocks when
+// nothing changed.
+func (m *metaReader) readMetadata() error {
+	var length [1]byte
+	if _, err := io.ReadFull(m.r, length[:]); err != nil {
+		return err
+	}
+	if length[0] == 0 {
+		return nil
+	}
+	block := make([]byte, int(length[0])*16)
+	if _, err := io.ReadFull(m.r, block); err != nil {
+		return err
+	}
+	if title, ok := parseMetadata(block)["StreamTitle"]; ok && m.onTitle != nil {
+		m.onTitle(title)
+	}
+	return nil
+}
+
+// parseMetadata parses a metadata block such as
+// "StreamTitle='Artist - Title';" padded with zero bytes.
+// Values end at "';" so that titles may contain quotes.
+func parseMetadata(block []byte) map[string]string {
+	s := decodeText(bytes.TrimRight(block, "\x00"))
+	fields := make(map[string]string)
+	for s != "" {
+		key, rest, ok := strings.Cut(s, "='")
+		if !ok {
+			break
+		}
+		value, next, ok := strings.Cut(rest, "';")
+		if !ok {
+			value = strings.TrimSuffix(rest, "'")
+		}
+		fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		s = next
+	}
+	return fields
+}
+
+// decodeText returns metadata as UTF-8. Older servers send Latin-1, which
+// is converted byte by byte.
+func decodeText(b []byte) string {
+	if utf8.Valid(b) {
+		return string(b)
+	}
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// SplitTitle splits a stream title into artist and title, as most
+// stations announce tracks as "Artist - Title". The whole stream title is
+// the title when there is no separator.
+func SplitTitle(streamTitle string) (artist, title string) {
+	artist, title, ok := strings.Cut(streamTitle, " - ")
+	if !ok || strings.TrimSpace(artist) == "" || strings.TrimSpace(title) == "" {
+		return "", strings.TrimSpace(streamTitle)
+	}
+	return strings.TrimSpace(artist), strings.TrimSpace(title)
+}
@@ -0,0 +1,314 @@
+package icy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// bufferSize is how much audio is read ahead of the decoder, about
+	// 30 seconds at 128 kbit/s.
+	bufferSize = 512 << 10
+
+	// prebufferSize is how much audio Open waits for, so playback
+	// doesn't underrun right after it starts.
+	prebufferSize = 32 << 10
+
+	// maxReconnects is how many times in a row reconnecting may fail
+	// before the stream is given up.
+	maxReconnects = 5
+
+	readSize = 16 << 10
+)
+
+// Timings, shortened by tests.
+var (
+	prebufferTimeout = 5 * time.Second
+	reconnectDelay   = time.Second // Multiplied by the attempt number
+)
+
+// ErrClosed is returned by Read after Close.
+var ErrClosed = errors.New("icy: stream closed")
+
+// client is shared by all streams. It has no overall timeout, as streams
+// never end, but gives up on servers that don't answer.
+var client = &http.Client{
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialICY,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 10 * time.Second,
+	},
+}
+
+// Stream is an HTTP audio stream. Read returns the audio without the
+// metadata, from a buffer filled in the background. When the connection
+// drops the stream reconnects, and Read blocks until audio comes again.
+type Stream struct {
+	url    string
+	info   Info
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	buf      bytes.Buffer
+	title    string
+	err      error // Set once the stream ended, returned when buf is drained
+	timedOut bool  // The prebuffer wait is over
+}
+
+// Open connects to a stream and waits until some audio is buffered.
+func Open(rawURL string) (*Stream, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Stream{url: rawURL, ctx: ctx, cancel: cancel}
+	s.cond = sync.NewCond(&s.mu)
+
+	resp, err := s.connect()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	s.info = infoFromResponse(resp, rawURL)
+	go s.run(resp)
+
+	if err := s.prebuffer(); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// URL returns the URL of the stream.
+func (s *Stream) URL() string {
+	return s.url
+}
+
+// Info returns the description of the stream sent by the server.
+func (s *Stream) Info() Info {
+	return s.info
+}
+
+// Title returns the title the station last announced, usually
+// "Artist - Title", empty if it announced none.
+func (s *Stream) Title() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.title
+}
+
+// Read reads buffered audio, waiting for more when the buffer is empty.
+func (s *Stream) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.buf.Len() == 0 && s.err == nil {
+		s.cond.Wait()
+	}
+	if s.buf.Len() > 0 {
+		n, _ := s.buf.Read(p)
+		s.cond.Broadcast() // Room for more
+		return n, nil
+	}
+	return 0, s.err
+}
+
+// Close disconnects. Pending and later reads return ErrClosed. It is safe
+// to call more than once.
+func (s *Stream) Close() error {
+	s.mu.Lock()
+	s.err = ErrClosed
+	s.buf.Reset()
+	s.cond.Broadcast()
+	s.mu.Unlock()
+	s.cancel()
+	return nil
+}
+
+// connect requests the stream with its metadata.
+func (s *Stream) connect() (*http.Response, error) {
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodGet, s.url, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Icy-MetaData", "1")
+	req.Header.Set("User-Agent", "waves")
+
+	resp, err := client.Do(req) //nolint:gosec // URLs are chosen by the user
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("icy: %s: %s", s.url, resp.Status)
+	}
+	return resp, nil
+}
+
+// prebuffer waits until enough audio is buffered, the stream fails, or
+// the wait times out on a slow station.
+func (s *Stream) prebuffer() error {
+	timer := time.AfterFunc(prebufferTimeout, func() {
+		s.mu.Lock()
+		s.timedOut = true
+		s.cond.Broadcast()
+		s.mu.Unlock()
+	})
+	defer timer.Stop()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.buf.Len() < prebufferSize && s.err == nil && !s.timedOut {
+		s.cond.Wait()
+	}
+	if s.buf.Len() == 0 && s.err != nil {
+		return s.err
+	}
+	return nil
+}
+
+// run fills the buffer, reconnecting when the connection drops. It gives
+// up after maxReconnects failures in a row, or when a stream of known
+// length was read to the end, which is a file rather than a station.
+func (s *Stream) run(resp *http.Response) {
+	failures := 0
+	for {
+		n, err := s.fill(resp)
+		resp.Body.Close()
+		if s.ctx.Err() != nil {
+			return
+		}
+		if resp.ContentLength >= 0 && errors.Is(err, io.EOF) {
+			s.fail(io.EOF)
+			return
+		}
+		if n > 0 {
+			failures = 0
+		}
+
+		for {
+			failures++
+			if failures > maxReconnects {
+				s.fail(fmt.Errorf("icy: %s: connection lost: %w", s.url, err))
+				return
+			}
+			select {
+			case <-time.After(time.Duration(failures) * reconnectDelay):
+			case <-s.ctx.Done():
+				return
+			}
+			resp, err = s.connect()
+			if err == nil {
+				break
+			}
+			if s.ctx.Err() != nil {
+				return
+			}
+		}
+	}
+}
+
+// fill copies the audio of a response to the buffer until the connection
+// fails. It returns how many bytes were buffered.
+func (s *Stream) fill(resp *http.Response) (int64, error) {
+	interval, _ := strconv.Atoi(resp.Header.Get("icy-metaint"))
+	r := newMetaReader(resp.Body, interval, s.setTitle)
+
+	var total int64
+	chunk := make([]byte, readSize)
+	for {
+		n, err := r.Read(chunk)
+		if n > 0 {
+			total += int64(n)
+			if !s.write(chunk[:n]) {
+				return total, ErrClosed
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				err = io.EOF // Cut in a metadata block
+			}
+			return total, err
+		}
+	}
+}
+
+// write appends audio to the buffer, waiting while it is full. It returns
+// false once the stream is closed.
+func (s *Stream) write(p []byte) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.buf.Len() >= bufferSize && s.err == nil {
+		s.cond.Wait()
+	}
+	if s.err != nil {
+		return false
+	}
+	s.buf.Write(p)
+	s.cond.Broadcast()
+	return true
+}
+
+func (s *Stream) setTitle(title string) {
+	s.mu.Lock()
+	s.title = title
+	s.mu.Unlock()
+}
+
+// fail ends the stream with err once the buffer is drained.
+func (s *Stream) fail(err error) {
+	s.mu.Lock()
+	if s.err == nil {
+		s.err = err
+	}
+	s.cond.Broadcast()
+	s.mu.Unlock()
+}
+
+// dialICY connects like the default transport, but lets old SHOUTcast
+// servers through: they answer "ICY 200 OK", which is not a valid HTTP
+// status line.
+func dialICY(ctx context.Context, network, addr string) (net.Conn, error) {
+	d := net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}
+	conn, err := d.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return &icyConn{Conn: conn}, nil
+}
+
+// icyConn rewrites an "ICY" status line to "HTTP/1.0".
+type icyConn struct {
+	net.Conn
+	checked bool
+	pending []byte
+}
+
+func (c *icyConn) Read(p []byte) (int, error) {
+	if !c.checked {
+		c.checked = true
+		head := make([]byte, 4)
+		n, err := io.ReadFull(c.Conn, head)
+		if n == 0 {
+			return 0, err
+		}
+		head = head[:n]
+		if string(head) == "ICY " {
+			head = []byte("HTTP/1.0 ")
+		}
+		c.pending = head
+	}
+	if len(c.pending) > 0 {
+		n := copy(p, c.pending)
+		c.pending = c.pending[n:]
+		return n, nil
+	}
+	return c.Conn.Read(p)
+}
@@ -3,6 +3,7 @@
 package importer
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
@@ -236,11 +237,23 @@ func BuildTagData(p BuildTagDataParams) TagData {
 
 // RetagFile writes tags to a file in place without moving it.
 // This is used by the retag feature to update existing library files.
+// Loudness values and ratings are not part of MusicBrainz metadata, so
+// existing ReplayGain and rating tags are kept unless data carries new ones.
 func RetagFile(path string, data TagData) error {
 	// Create a context with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), operationTimeout)
 	defer cancel()
 
+	if data.ReplayGain.IsEmpty() || data.Rating == 0 || data.AlbumRating == 0 {
+		if existing, err := tags.Read(path); err == nil {
+			if data.ReplayGain.IsEmpty() {
+				data.ReplayGain = existing.ReplayGain
+			}
+			data.Rating = cmp.Or(data.Rating, existing.Rating)
+			data.AlbumRating = cmp.Or(data.AlbumRating, existing.AlbumRating)
+		}
+	}
+
 	// Write tags with retry
 	return retryWithBackoff(ctx, "write tags", func() error {
 		return tags.Write(path, &data)
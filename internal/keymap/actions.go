@@ -17,6 +17,7 @@ const (
 	ActionViewFileBrowser Action = "view_file_browser"
 	ActionViewPlaylists   Action = "view_playlists"
 	ActionViewDownloads   Action = "view_downloads"
+	ActionViewHistory     Action = "view_history"
 
 	// Key sequence prefixes
 	ActionFPrefix Action = "f_prefix"
@@ -56,6 +57,26 @@ const (
 	ActionVolumeDown Action = "volume_down"
 	ActionToggleMute Action = "toggle_mute"
 
+	// Speed actions
+	ActionSpeedUp    Action = "speed_up"
+	ActionSpeedDown  Action = "speed_down"
+	ActionSpeedReset Action = "speed_reset"
+
+	// Sleep timer
+	ActionSleepTimer Action = "sleep_timer"
+
+	// A-B loop and bookmarks
+	ActionLoopStart     Action = "loop_start"
+	ActionLoopEnd       Action = "loop_end"
+	ActionLoopClear     Action = "loop_clear"
+	ActionAddBookmark   Action = "add_bookmark"
+	ActionShowBookmarks Action = "show_bookmarks"
+
+	// Chapters
+	ActionNextChapter  Action = "next_chapter"
+	ActionPrevChapter  Action = "prev_chapter"
+	ActionShowChapters Action = "show_chapters"
+
 	// Navigation actions
 	ActionMoveUp    Action = "move_up"
 	ActionMoveDown  Action = "move_down"
@@ -90,11 +111,35 @@ const (
 	ActionRetag           Action = "retag"             // t
 	ActionSimilarArtists  Action = "similar_artists"   // i
 
+	// Rating actions (library and queue)
+	ActionRate       Action = "rate"        // 0-5
+	ActionRatingUp   Action = "rating_up"   // >
+	ActionRatingDown Action = "rating_down" // <
+
 	// Playlist management actions
 	ActionNewPlaylist Action = "new_playlist" // n
 	ActionNewFolder   Action = "new_folder"   // N
 	ActionRename      Action = "rename"       // ctrl+r
 
+	// Smart playlist actions
+	ActionNewSmartPlaylist Action = "new_smart_playlist" // ctrl+n
+	ActionEditRules        Action = "edit_rules"         // E
+	ActionFreezePlaylist   Action = "freeze_playlist"    // ctrl+f
+
+	// Playlist file actions
+	ActionImportPlaylist Action = "import_playlist" // I
+	ActionExportPlaylist Action = "export_playlist" // X
+
 	// Export actions
 	ActionExport Action = "export" // e
+
+	// Loudness analysis actions
+	ActionAnalyzeLoudness        Action = "analyze_loudness"         // f g
+	ActionAnalyzeLibraryLoudness Action = "analyze_library_loudness" // f G
+
+	// Equalizer actions
+	ActionEqualizer Action = "equalizer" // f e
+
+	// Visualizer actions
+	ActionToggleVisualizer Action = "toggle_visualizer" // f v
 )
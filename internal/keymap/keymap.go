@@ -5,7 +5,7 @@ type Binding struct {
 	Action      Action
 	Keys        []string
 	Description string
-	Context     string // "global", "navigator", "queue", "playback", "playlist", "playlist-track", "library", "filebrowser", "albumview", "downloads"
+	Context     string // "global", "navigator", "queue", "playback", "playlist", "playlist-track", "library", "filebrowser", "albumview", "downloads", "history"
 }
 
 // Bindings contains all key bindings - the single source of truth.
@@ -22,6 +22,7 @@ var Bindings = []Binding{
 	{ActionViewFileBrowser, []string{"f2"}, "File browser view", "global"},
 	{ActionViewPlaylists, []string{"f3"}, "Playlists view", "global"},
 	{ActionViewDownloads, []string{"f4"}, "Downloads view", "global"},
+	{ActionViewHistory, []string{"f5"}, "History view", "global"},
 
 	// F-sequence prefix and actions
 	{ActionFPrefix, []string{"f"}, "Function prefix", "global"},
@@ -31,6 +32,10 @@ var Bindings = []Binding{
 	{ActionLibrarySources, []string{"f p"}, "Library sources", "global"},
 	{ActionDownloadSoulseek, []string{"f d"}, "Download from Soulseek", "global"},
 	{ActionLastfmSettings, []string{"f l"}, "Last.fm settings", "global"},
+	{ActionAnalyzeLoudness, []string{"f g"}, "Analyze loudness (selection)", "global"},
+	{ActionAnalyzeLibraryLoudness, []string{"f G"}, "Analyze loudness (whole library)", "global"},
+	{ActionEqualizer, []string{"f e"}, "Equalizer", "global"},
+	{ActionToggleVisualizer, []string{"f v"}, "Toggle spectrum analyzer", "global"},
 
 	// Playback
 	{ActionPlayPause, []string{" "}, "Play/pause", "playback"},
@@ -53,6 +58,26 @@ var Bindings = []Binding{
 	{ActionVolumeDown, []string{"-"}, "Volume -10%", "playback"},
 	{ActionToggleMute, []string{"M"}, "Toggle mute", "playback"},
 
+	// Speed
+	{ActionSpeedDown, []string{"["}, "Speed -0.1x", "playback"},
+	{ActionSpeedUp, []string{"]"}, "Speed +0.1x", "playback"},
+	{ActionSpeedReset, []string{"="}, "Normal speed", "playback"},
+
+	// Sleep timer
+	{ActionSleepTimer, []string{"z"}, "Sleep timer (off/15/30/45/60/90 min/end of track/end of album)", "playback"},
+
+	// A-B loop and bookmarks
+	{ActionLoopStart, []string{"("}, "Set loop start (A)", "playback"},
+	{ActionLoopEnd, []string{")"}, "Set loop end (B)", "playback"},
+	{ActionLoopClear, []string{"|"}, "Clear loop", "playback"},
+	{ActionAddBookmark, []string{"b"}, "Bookmark position", "playback"},
+	{ActionShowBookmarks, []string{"B"}, "Track bookmarks", "playback"},
+
+	// Chapters
+	{ActionPrevChapter, []string{"{"}, "Previous chapter", "playback"},
+	{ActionNextChapter, []string{"}"}, "Next chapter", "playback"},
+	{ActionShowChapters, []string{"C"}, "Chapter list", "playback"},
+
 	// Navigator
 	{ActionMoveLeft, []string{"h", "left"}, "Parent/collapse", "navigator"},
 	{ActionMoveRight, []string{"l", "right"}, "Enter/expand", "navigator"},
@@ -73,6 +98,9 @@ var Bindings = []Binding{
 	{ActionRetag, []string{"t"}, "Retag album", "library"},
 	{ActionExport, []string{"e"}, "Export to USB", "library"},
 	{ActionSimilarArtists, []string{"i"}, "Similar artists", "library"},
+	{ActionRate, []string{"0", "1", "2", "3", "4", "5"}, "Rate track/album (0 clears)", "library"},
+	{ActionRatingUp, []string{">"}, "Raise rating", "library"},
+	{ActionRatingDown, []string{"<"}, "Lower rating", "library"},
 
 	// Album view options (o-sequence)
 	{ActionOPrefix, []string{"o"}, "Options prefix", "albumview"},
@@ -92,14 +120,23 @@ var Bindings = []Binding{
 	{ActionSelect, []string{"enter"}, "Play track", "queue"},
 	{ActionClearSelect, []string{"esc"}, "Clear selection", "queue"},
 	{ActionToggleFavorite, []string{"F"}, "Toggle favorite", "queue"},
+	{ActionRate, []string{"0", "1", "2", "3", "4", "5"}, "Rate tracks (0 clears)", "queue"},
+	{ActionRatingUp, []string{">"}, "Raise rating", "queue"},
+	{ActionRatingDown, []string{"<"}, "Lower rating", "queue"},
 	{ActionAddToPlaylist, []string{"ctrl+a"}, "Add to playlist", "queue"},
 	{ActionLocate, []string{"L"}, "Locate in navigator", "queue"},
 	{ActionExport, []string{"e"}, "Export to USB", "queue"},
+	{ActionExportPlaylist, []string{"X"}, "Export queue to playlist file", "queue"},
 	{ActionJumpStart, []string{"g"}, "First item", "queue"},
 	{ActionJumpEnd, []string{"G"}, "Last item", "queue"},
 	{ActionPageDown, []string{"ctrl+d"}, "Half page down", "queue"},
 	{ActionPageUp, []string{"ctrl+u"}, "Half page up", "queue"},
 
+	// History view
+	{ActionSelect, []string{"enter"}, "Play now (whole day on a day header)", "history"},
+	{ActionAdd, []string{"a"}, "Add to queue", "history"},
+	{ActionLocate, []string{"L"}, "Locate in library", "history"},
+
 	// Queue history (global when queue available)
 	{ActionUndo, []string{"ctrl+z"}, "Undo", "global"},
 	{ActionRedo, []string{"ctrl+shift+z"}, "Redo", "global"},
@@ -109,6 +146,11 @@ var Bindings = []Binding{
 	{ActionNewFolder, []string{"N"}, "New folder", "playlist"},
 	{ActionRename, []string{"ctrl+r"}, "Rename", "playlist"},
 	{ActionDelete, []string{"ctrl+d"}, "Delete", "playlist"},
+	{ActionNewSmartPlaylist, []string{"ctrl+n"}, "New smart playlist", "playlist"},
+	{ActionEditRules, []string{"E"}, "Edit smart playlist rules", "playlist"},
+	{ActionFreezePlaylist, []string{"ctrl+f"}, "Freeze smart playlist", "playlist"},
+	{ActionImportPlaylist, []string{"I"}, "Import playlist file (M3U/PLS/XSPF)", "playlist"},
+	{ActionExportPlaylist, []string{"X"}, "Export playlist to file", "playlist"},
 
 	// Playlist track editing
 	{ActionDelete, []string{"d"}, "Remove track", "playlist-track"},
@@ -131,6 +131,7 @@ func TestBindingsHaveValidContexts(t *testing.T) {
 		"filebrowser":    true,
 		"albumview":      true,
 		"downloads":      true,
+		"history":        true,
 	}
 
 	for i, b := range Bindings {
@@ -1,6 +1,10 @@
 package lastfm
 
-import "time"
+import (
+	"time"
+
+	"github.com/llehouerou/waves/internal/tags"
+)
 
 // ScrobbleTrack contains track metadata for scrobbling.
 type ScrobbleTrack struct {
@@ -21,6 +25,36 @@ type ScrobbleState struct {
 	NowPlayingSent bool      // Whether now playing was sent
 }
 
+// ScrobbleThreshold returns the position a track must be played to before
+// it is scrobbled: 50% of its duration or 4 minutes, whichever comes first.
+// It returns false for tracks shorter than 30 seconds, which aren't
+// scrobbled. Position and duration are in track time; the 4 minutes are
+// listening time, so they cover more or less of the track when the playback
+// speed is not 1.
+func ScrobbleThreshold(duration time.Duration, speed float64) (time.Duration, bool) {
+	if duration < 30*time.Second {
+		return 0, false
+	}
+	return min(duration/2, time.Duration(float64(4*time.Minute)*speed)), true
+}
+
+// NewScrobbleTrack creates the scrobble of a track from its tags.
+func NewScrobbleTrack(info *tags.FileInfo, duration time.Duration, startedAt time.Time) ScrobbleTrack {
+	track := ScrobbleTrack{
+		Artist:        info.Artist,
+		Track:         info.Title,
+		Album:         info.Album,
+		Duration:      duration,
+		Timestamp:     startedAt,
+		MBRecordingID: info.MBRecordingID,
+	}
+	// Set album artist if different from track artist
+	if info.AlbumArtist != "" && info.AlbumArtist != info.Artist {
+		track.AlbumArtist = info.AlbumArtist
+	}
+	return track
+}
+
 // SimilarArtist represents a similar artist from Last.fm.
 type SimilarArtist struct {
 	Name       string
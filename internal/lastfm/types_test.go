@@ -0,0 +1,39 @@
+package lastfm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/llehouerou/waves/internal/tags"
+)
+
+func TestScrobbleThreshold(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		speed    float64
+		want     time.Duration
+		ok       bool
+	}{
+		{20 * time.Second, 1, 0, false},
+		{3 * time.Minute, 1, 90 * time.Second, true},
+		{20 * time.Minute, 1, 4 * time.Minute, true},
+		{20 * time.Minute, 1.5, 6 * time.Minute, true},
+	}
+	for _, tt := range tests {
+		got, ok := ScrobbleThreshold(tt.duration, tt.speed)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ScrobbleThreshold(%v, %v) = %v, %v; want %v, %v", tt.duration, tt.speed, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestNewScrobbleTrack_AlbumArtist(t *testing.T) {
+	info := &tags.FileInfo{Tag: tags.Tag{Artist: "Blur", Title: "Song 2", AlbumArtist: "Blur"}}
+	if track := NewScrobbleTrack(info, time.Minute, time.Now()); track.AlbumArtist != "" {
+		t.Errorf("AlbumArtist = %q, want none when it is the artist", track.AlbumArtist)
+	}
+	info.AlbumArtist = "Various Artists"
+	if track := NewScrobbleTrack(info, time.Minute, time.Now()); track.AlbumArtist != "Various Artists" {
+		t.Errorf("AlbumArtist = %q, want Various Artists", track.AlbumArtist)
+	}
+}
@@ -10,8 +10,12 @@ type AlbumEntry struct {
 	ReleaseDate  string    // Best date from tracks
 	AddedAt      time.Time // When first track was added to library
 	TrackCount   int
-	Genre        string // Most common genre from tracks
-	Label        string // Most common label from tracks
+	Genre        string    // Most common genre from tracks
+	Label        string    // Most common label from tracks
+	PlayCount    int       // Sum of track play counts
+	SkipCount    int       // Sum of track skip counts
+	LastPlayed   time.Time // When a track was last played, zero if never
+	Rating       int       // Album rating in half stars, 0 if unrated
 }
 
 // DatePrecision indicates the granularity of a date string.
@@ -0,0 +1,104 @@
+package library
+
+import (
+	"time"
+
+	"github.com/llehouerou/waves/internal/cue"
+)
+
+// expandCueSheets replaces the audio files that hold a whole album by the
+// virtual tracks of their CUE sheet. Sheets that list one file per track
+// are left alone, their files are regular tracks.
+//
+// A virtual track changes when either its sheet or its audio file does, so
+// it gets the most recent of both modification times.
+func expandCueSheets(files, sheets []fileInfo) []fileInfo {
+	if len(sheets) == 0 {
+		return files
+	}
+
+	mtimes := make(map[string]int64, len(files))
+	for _, f := range files {
+		mtimes[f.path] = f.mtime
+	}
+
+	covered := make(map[string]bool)
+	var virtual []fileInfo
+	for _, s := range sheets {
+		sheet, err := cue.ParseFile(s.path)
+		if err != nil {
+			continue // Not a usable sheet, its audio files stay regular tracks
+		}
+		for i := range sheet.Files {
+			f := &sheet.Files[i]
+			if len(f.Tracks) < 2 {
+				continue
+			}
+			audioPath, err := cue.AudioPath(s.path, f)
+			if err != nil {
+				continue
+			}
+			audioMtime, ok := mtimes[audioPath]
+			// Skip formats we can't play, and files already covered by
+			// another sheet (e.g. a copy of the sheet in another encoding)
+			if !ok || covered[audioPath] {
+				continue
+			}
+			covered[audioPath] = true
+			for _, t := range f.Tracks {
+				virtual = append(virtual, fileInfo{
+					path:   cue.TrackPath(s.path, t.Number),
+					mtime:  max(s.mtime, audioMtime),
+					source: s.source,
+					section: &cue.Section{
+						AudioPath: audioPath,
+						Start:     t.Start().Duration(),
+						End:       t.End.Duration(),
+					},
+				})
+			}
+		}
+	}
+
+	result := make([]fileInfo, 0, len(files)+len(virtual))
+	for _, f := range files {
+		if !covered[f.path] {
+			result = append(result, f)
+		}
+	}
+	return append(result, virtual...)
+}
+
+// trackSection returns the part of its audio file a track plays: section
+// for the CUE sheet tracks found by a scan, the whole file for regular
+// tracks, and the section in the sheet otherwise.
+func trackSection(path string, section *cue.Section) (cue.Section, error) {
+	if section != nil {
+		return *section, nil
+	}
+	if !cue.IsTrackPath(path) {
+		return cue.Section{AudioPath: path}, nil
+	}
+	ref, err := cue.Lookup(path)
+	if err != nil {
+		return cue.Section{}, err
+	}
+	return ref.Section(), nil
+}
+
+// CueSection returns the part of its audio file a CUE sheet track of the
+// library plays, kept when it was scanned. ok is false for tracks outside
+// the library, whose sheet must be read.
+func (l *Library) CueSection(path string) (section cue.Section, ok bool) {
+	var startMs, endMs int64
+	err := l.db.QueryRow(`
+		SELECT source_path, start_ms, end_ms FROM library_tracks
+		WHERE path = ? AND source_path IS NOT NULL
+	`, path).Scan(&section.AudioPath, &startMs, &endMs)
+	if err != nil {
+		return cue.Section{}, false
+	}
+	section.Start = time.Duration(startMs) * time.Millisecond
+	section.End = time.Duration(endMs) * time.Millisecond
+	return section, true
+}
@@ -0,0 +1,169 @@
+package library
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/llehouerou/waves/internal/cue"
+	"github.com/llehouerou/waves/internal/tags"
+)
+
+// writeTestWAV writes a short silent WAV file with tags.
+func writeTestWAV(t *testing.T, path string, tag *tags.Tag) {
+	t.Helper()
+	const dataSize = 8000 * 2
+	b := []byte("RIFF")
+	b = binary.LittleEndian.AppendUint32(b, 36+dataSize)
+	b = append(b, "WAVEfmt "...)
+	b = binary.LittleEndian.AppendUint32(b, 16)
+	b = binary.LittleEndian.AppendUint16(b, 1) // PCM
+	b = binary.LittleEndian.AppendUint16(b, 1) // Mono
+	b = binary.LittleEndian.AppendUint32(b, 8000)
+	b = binary.LittleEndian.AppendUint32(b, 8000*2)
+	b = binary.LittleEndian.AppendUint16(b, 2)
+	b = binary.LittleEndian.AppendUint16(b, 16)
+	b = append(b, "data"...)
+	b = binary.LittleEndian.AppendUint32(b, dataSize)
+	b = append(b, make([]byte, dataSize)...)
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+	if err := tags.Write(path, tag); err != nil {
+		t.Fatalf("tag %s: %v", path, err)
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+const testAlbumSheet = `PERFORMER "Cue Artist"
+TITLE "Cue Album"
+FILE "album.wav" WAVE
+  TRACK 01 AUDIO
+    TITLE "Opening"
+    INDEX 01 00:00:00
+  TRACK 02 AUDIO
+    TITLE "Closing"
+    INDEX 01 00:00:40
+`
+
+func scanTestSource(t *testing.T, lib *Library, src string) {
+	t.Helper()
+	progress := make(chan ScanProgress, 100)
+	go func() {
+		for range progress {
+		}
+	}()
+	if err := lib.Refresh([]string{src}, progress); err != nil {
+		t.Fatalf("Refresh() error: %v", err)
+	}
+}
+
+func TestRefresh_CueSheetCreatesVirtualTracks(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	lib := New(db)
+
+	src := t.TempDir()
+	writeTestWAV(t, filepath.Join(src, "album.wav"), &tags.Tag{Artist: "File Artist", Album: "File Album", Title: "Whole"})
+	sheetPath := filepath.Join(src, "album.cue")
+	writeTestFile(t, sheetPath, testAlbumSheet)
+
+	scanTestSource(t, lib, src)
+
+	tracks, err := lib.Tracks("Cue Artist", "Cue Album")
+	if err != nil {
+		t.Fatalf("Tracks() error: %v", err)
+	}
+	if len(tracks) != 2 {
+		t.Fatalf("got %d tracks, want 2", len(tracks))
+	}
+	for i, want := range []string{"Opening", "Closing"} {
+		if tracks[i].Title != want {
+			t.Errorf("track %d title = %q, want %q", i, tracks[i].Title, want)
+		}
+		if tracks[i].Path != cue.TrackPath(sheetPath, i+1) {
+			t.Errorf("track %d path = %q", i, tracks[i].Path)
+		}
+	}
+
+	// The album file itself is not a track
+	if count, _ := lib.TrackCount(); count != 2 {
+		t.Errorf("TrackCount() = %d, want 2", count)
+	}
+
+	// The sections are kept in milliseconds, to play the tracks without
+	// reading the sheet
+	section, ok := lib.CueSection(tracks[1].Path)
+	want := cue.Section{AudioPath: filepath.Join(src, "album.wav"), Start: 533 * time.Millisecond}
+	if !ok || section != want {
+		t.Errorf("CueSection() = %+v, %v; want %+v", section, ok, want)
+	}
+	if _, ok := lib.CueSection(filepath.Join(src, "other.cue#1")); ok {
+		t.Error("CueSection() found a track outside the library")
+	}
+
+	// Removing the sheet turns the file back into a regular track
+	if err := os.Remove(sheetPath); err != nil {
+		t.Fatal(err)
+	}
+	scanTestSource(t, lib, src)
+	if _, err := lib.TrackByPath(filepath.Join(src, "album.wav")); err != nil {
+		t.Errorf("album file not added back: %v", err)
+	}
+	if count, _ := lib.TrackCount(); count != 1 {
+		t.Errorf("TrackCount() = %d, want 1", count)
+	}
+}
+
+func TestExpandCueSheets_FilePerTrackSheetIgnored(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"01.flac", "02.flac"} {
+		writeTestFile(t, filepath.Join(dir, name), "")
+	}
+	sheetPath := filepath.Join(dir, "album.cue")
+	writeTestFile(t, sheetPath, `FILE "01.flac" WAVE
+  TRACK 01 AUDIO
+    INDEX 01 00:00:00
+FILE "02.flac" WAVE
+  TRACK 02 AUDIO
+    INDEX 01 00:00:00
+`)
+
+	files := []fileInfo{
+		{path: filepath.Join(dir, "01.flac"), mtime: 1},
+		{path: filepath.Join(dir, "02.flac"), mtime: 1},
+	}
+	got := expandCueSheets(files, []fileInfo{{path: sheetPath, mtime: 2}})
+	if len(got) != 2 || got[0].path != files[0].path || got[1].path != files[1].path {
+		t.Errorf("expandCueSheets() = %+v, want the files unchanged", got)
+	}
+}
+
+func TestExpandCueSheets_UsesLatestMtime(t *testing.T) {
+	dir := t.TempDir()
+	audioPath := filepath.Join(dir, "album.wav")
+	writeTestFile(t, audioPath, "")
+	sheetPath := filepath.Join(dir, "album.cue")
+	writeTestFile(t, sheetPath, testAlbumSheet)
+
+	got := expandCueSheets(
+		[]fileInfo{{path: audioPath, mtime: 100, source: dir}},
+		[]fileInfo{{path: sheetPath, mtime: 200, source: dir}},
+	)
+	if len(got) != 2 {
+		t.Fatalf("got %d files, want 2", len(got))
+	}
+	for _, f := range got {
+		if f.mtime != 200 || f.source != dir || !cue.IsTrackPath(f.path) {
+			t.Errorf("unexpected file %+v", f)
+		}
+	}
+}
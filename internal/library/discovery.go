@@ -4,12 +4,15 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/llehouerou/waves/internal/cue"
 	"github.com/llehouerou/waves/internal/tags"
 )
 
 // discoverFiles walks the given source directories and returns all music files found.
+// Single-file album rips with a CUE sheet are returned as one virtual file per track.
 // Returns the list of files and a map of path->source for quick lookup.
 func discoverFiles(sources []string, progress chan<- ScanProgress) (files []fileInfo, discoveredPaths map[string]string) {
+	var sheets []fileInfo
 	for _, src := range sources {
 		_ = filepath.WalkDir(src, func(path string, d os.DirEntry, walkErr error) error {
 			// Skip any walk errors - intentionally continuing to scan other paths
@@ -19,7 +22,8 @@ func discoverFiles(sources []string, progress chan<- ScanProgress) (files []file
 			if d.IsDir() {
 				return nil
 			}
-			if !tags.IsMusicFile(path) {
+			isSheet := cue.IsSheet(path)
+			if !isSheet && !tags.IsMusicFile(path) {
 				return nil
 			}
 
@@ -29,6 +33,11 @@ func discoverFiles(sources []string, progress chan<- ScanProgress) (files []file
 				return nil //nolint:nilerr // intentionally skipping errors
 			}
 
+			if isSheet {
+				sheets = append(sheets, fileInfo{path: path, mtime: info.ModTime().Unix(), source: src})
+				return nil
+			}
+
 			files = append(files, fileInfo{
 				path:   path,
 				mtime:  info.ModTime().Unix(),
@@ -42,6 +51,8 @@ func discoverFiles(sources []string, progress chan<- ScanProgress) (files []file
 		})
 	}
 
+	files = expandCueSheets(files, sheets)
+
 	// Build set of discovered paths for deletion phase (with source info)
 	discoveredPaths = make(map[string]string, len(files)) // path -> source
 	for _, f := range files {
@@ -0,0 +1,144 @@
+package library
+
+import (
+	"errors"
+	"strings"
+)
+
+// Tag is a track field tracks can be filtered and listed by.
+type Tag string
+
+const (
+	TagAny         Tag = "any" // Any of artist, album artist, album, title, genre and path
+	TagArtist      Tag = "artist"
+	TagAlbumArtist Tag = "album_artist"
+	TagAlbum       Tag = "album"
+	TagTitle       Tag = "title"
+	TagGenre       Tag = "genre"
+	TagYear        Tag = "year"
+	TagLabel       Tag = "label"
+	TagTrackNumber Tag = "track_number"
+	TagDiscNumber  Tag = "disc_number"
+	TagPath        Tag = "path"
+)
+
+// ErrUnknownTag is returned for tags tracks can't be filtered or listed by.
+var ErrUnknownTag = errors.New("unknown tag")
+
+// tagExprs are the SQL expressions of tags, as text, empty when unset.
+var tagExprs = map[Tag]string{
+	TagArtist:      "artist",
+	TagAlbumArtist: "album_artist",
+	TagAlbum:       "album",
+	TagTitle:       "title",
+	TagGenre:       "COALESCE(genre, '')",
+	TagYear:        "COALESCE(CAST(NULLIF(year, 0) AS TEXT), '')",
+	TagLabel:       "COALESCE(label, '')",
+	TagTrackNumber: "COALESCE(CAST(NULLIF(track_number, 0) AS TEXT), '')",
+	TagDiscNumber:  "COALESCE(CAST(NULLIF(disc_number, 0) AS TEXT), '')",
+	TagPath:        "path",
+}
+
+// anyTags are the tags TagAny matches.
+var anyTags = []Tag{TagArtist, TagAlbumArtist, TagAlbum, TagTitle, TagGenre, TagPath}
+
+// TagFilter selects the tracks whose tag equals Value or, with Contains,
+// holds it ignoring case.
+type TagFilter struct {
+	Tag      Tag
+	Value    string
+	Contains bool
+}
+
+// FindTracks returns the tracks matching all filters, in album order.
+func (l *Library) FindTracks(filters []TagFilter) ([]Track, error) {
+	where, args, err := filterClause(filters)
+	if err != nil {
+		return nil, err
+	}
+	return l.queryTracks(where+`
+		ORDER BY album_artist COLLATE NOCASE, album COLLATE NOCASE, disc_number, track_number
+	`, args...)
+}
+
+// TagValues returns the distinct combinations of values of tags among the
+// tracks matching filters, sorted. Combinations where the last tag is empty
+// are left out.
+func (l *Library) TagValues(tags []Tag, filters []TagFilter) ([][]string, error) {
+	if len(tags) == 0 {
+		return nil, ErrUnknownTag
+	}
+	exprs := make([]string, len(tags))
+	order := make([]string, len(tags))
+	for i, tag := range tags {
+		expr, ok := tagExprs[tag]
+		if !ok {
+			return nil, ErrUnknownTag
+		}
+		exprs[i] = expr
+		order[i] = expr + " COLLATE NOCASE"
+	}
+	where, args, err := filterClause(filters)
+	if err != nil {
+		return nil, err
+	}
+	if where == "" {
+		where = "WHERE "
+	} else {
+		where += " AND "
+	}
+	where += exprs[len(exprs)-1] + " != ''"
+
+	rows, err := l.db.Query(`
+		SELECT DISTINCT `+strings.Join(exprs, ", ")+`
+		FROM library_tracks `+where+`
+		ORDER BY `+strings.Join(order, ", "), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values [][]string
+	for rows.Next() {
+		row := make([]string, len(tags))
+		dest := make([]any, len(tags))
+		for i := range row {
+			dest[i] = &row[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		values = append(values, row)
+	}
+	return values, rows.Err()
+}
+
+// filterClause returns the WHERE clause of filters, empty without filters.
+func filterClause(filters []TagFilter) (string, []any, error) {
+	if len(filters) == 0 {
+		return "", nil, nil
+	}
+	conds := make([]string, 0, len(filters))
+	var args []any
+	for _, f := range filters {
+		tags := []Tag{f.Tag}
+		if f.Tag == TagAny {
+			tags = anyTags
+		}
+		var alts []string
+		for _, tag := range tags {
+			expr, ok := tagExprs[tag]
+			if !ok {
+				return "", nil, ErrUnknownTag
+			}
+			if f.Contains {
+				alts = append(alts, "instr(lower("+expr+"), lower(?)) > 0")
+			} else {
+				alts = append(alts, expr+" = ?")
+			}
+			args = append(args, f.Value)
+		}
+		conds = append(conds, "("+strings.Join(alts, " OR ")+")")
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args, nil
+}
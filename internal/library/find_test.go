@@ -0,0 +1,99 @@
+package library
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func insertFindTestTracks(t *testing.T, lib *Library) {
+	t.Helper()
+	_, err := lib.db.Exec(`
+		INSERT INTO library_tracks (id, path, mtime, artist, album_artist, album, title, track_number, disc_number, year, genre, added_at, updated_at)
+		VALUES
+			(1, '/music/Blur/Blur/02.mp3', 1000, 'Blur', 'Blur', 'Blur', 'Song 2', 2, 1, 1997, 'Rock', 1000, 1000),
+			(2, '/music/Blur/Blur/01.mp3', 1000, 'Blur', 'Blur', 'Blur', 'Beetlebum', 1, 1, 1997, 'Rock', 1000, 1000),
+			(3, '/music/Gorillaz/Demon Days/06.mp3', 1000, 'Gorillaz feat. Shaun Ryder', 'Gorillaz', 'Demon Days', 'DARE', 6, 1, 2005, 'Alternative', 1000, 1000),
+			(4, '/music/Misc/untitled.mp3', 1000, 'Nobody', 'Nobody', '', 'Untitled', NULL, NULL, NULL, NULL, 1000, 1000)
+	`)
+	if err != nil {
+		t.Fatalf("failed to insert tracks: %v", err)
+	}
+}
+
+func TestFindTracks(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	lib := New(db)
+	insertFindTestTracks(t, lib)
+
+	tests := []struct {
+		name    string
+		filters []TagFilter
+		want    []int64
+	}{
+		{"no filter", nil, []int64{2, 1, 3, 4}},
+		{"exact", []TagFilter{{Tag: TagAlbumArtist, Value: "Blur"}}, []int64{2, 1}},
+		{"exact is case sensitive", []TagFilter{{Tag: TagArtist, Value: "blur"}}, nil},
+		{"contains ignores case", []TagFilter{{Tag: TagArtist, Value: "GORILLAZ", Contains: true}}, []int64{3}},
+		{"year", []TagFilter{{Tag: TagYear, Value: "2005"}}, []int64{3}},
+		{"all filters", []TagFilter{{Tag: TagGenre, Value: "Rock"}, {Tag: TagTitle, Value: "song", Contains: true}}, []int64{1}},
+		{"any", []TagFilter{{Tag: TagAny, Value: "untitled", Contains: true}}, []int64{4}},
+		{"any path", []TagFilter{{Tag: TagAny, Value: "demon days/06", Contains: true}}, []int64{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracks, err := lib.FindTracks(tt.filters)
+			if err != nil {
+				t.Fatalf("FindTracks() error: %v", err)
+			}
+			var ids []int64
+			for _, tr := range tracks {
+				ids = append(ids, tr.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("FindTracks() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+
+	if _, err := lib.FindTracks([]TagFilter{{Tag: "composer", Value: "x"}}); !errors.Is(err, ErrUnknownTag) {
+		t.Errorf("FindTracks() with an unknown tag error = %v, want ErrUnknownTag", err)
+	}
+}
+
+func TestTagValues(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	lib := New(db)
+	insertFindTestTracks(t, lib)
+
+	tests := []struct {
+		name    string
+		tags    []Tag
+		filters []TagFilter
+		want    [][]string
+	}{
+		{"albums without empty ones", []Tag{TagAlbum}, nil, [][]string{{"Blur"}, {"Demon Days"}}},
+		{"filtered", []Tag{TagTitle}, []TagFilter{{Tag: TagAlbum, Value: "Blur"}}, [][]string{{"Beetlebum"}, {"Song 2"}}},
+		{"years", []Tag{TagYear}, nil, [][]string{{"1997"}, {"2005"}}},
+		{"grouped", []Tag{TagAlbumArtist, TagArtist}, nil, [][]string{
+			{"Blur", "Blur"}, {"Gorillaz", "Gorillaz feat. Shaun Ryder"}, {"Nobody", "Nobody"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lib.TagValues(tt.tags, tt.filters)
+			if err != nil {
+				t.Fatalf("TagValues() error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TagValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := lib.TagValues([]Tag{"composer"}, nil); !errors.Is(err, ErrUnknownTag) {
+		t.Errorf("TagValues() with an unknown tag error = %v, want ErrUnknownTag", err)
+	}
+}
@@ -2,6 +2,7 @@ package library
 
 import (
 	"database/sql"
+	"time"
 )
 
 // executor is an interface satisfied by both *sql.DB and *sql.Tx.
@@ -25,15 +26,21 @@ type Track struct {
 	TrackNumber  int
 	Year         int
 	Genre        string
-	OriginalDate string // YYYY-MM-DD, YYYY-MM, or YYYY
-	ReleaseDate  string // YYYY-MM-DD, YYYY-MM, or YYYY
-	Label        string // Record label/publisher
+	OriginalDate string    // YYYY-MM-DD, YYYY-MM, or YYYY
+	ReleaseDate  string    // YYYY-MM-DD, YYYY-MM, or YYYY
+	Label        string    // Record label/publisher
+	PlayCount    int       // Times played past the played threshold
+	SkipCount    int       // Times skipped before the played threshold
+	LastPlayed   time.Time // Zero if never played
+	Rating       int       // Half stars, 0 (unrated) to 10
+	AlbumRating  int       // Rating of the album, in half stars
 }
 
 // Album represents an album in the library.
 type Album struct {
-	Name string
-	Year int
+	Name   string
+	Year   int
+	Rating int // Half stars, 0 (unrated) to 10
 }
 
 // Library manages the music library database.
@@ -22,12 +22,13 @@ const (
 
 // Node represents a node in the library hierarchy.
 type Node struct {
-	level     Level
-	artist    string
-	album     string
-	albumYear int
-	track     *Track
-	name      string
+	level       Level
+	artist      string
+	album       string
+	albumYear   int
+	albumRating int
+	track       *Track
+	name        string
 }
 
 // ID returns a unique identifier for this node.
@@ -97,6 +98,11 @@ func (n Node) Album() string {
 	return n.album
 }
 
+// Year returns the release year of album nodes, 0 if unknown.
+func (n Node) Year() int {
+	return n.albumYear
+}
+
 // Track returns the track data for track nodes, nil otherwise.
 func (n Node) Track() *Track {
 	return n.track
@@ -111,6 +117,20 @@ func (n Node) TrackID() int64 {
 	return 0
 }
 
+// Rating returns the rating of track and album nodes, in half stars.
+// Implements navigator.RatingProvider.
+func (n Node) Rating() int {
+	switch n.level { //nolint:exhaustive // only tracks and albums are rated
+	case LevelAlbum:
+		return n.albumRating
+	case LevelTrack:
+		if n.track != nil {
+			return n.track.Rating
+		}
+	}
+	return 0
+}
+
 // PreviewLines returns track metadata for display in the preview column.
 // Implements navigator.PreviewProvider.
 func (n Node) PreviewLines() []string {
@@ -147,6 +167,10 @@ func (n Node) PreviewLines() []string {
 		lines = append(lines, "  Genre: "+t.Genre)
 	}
 
+	if t.Rating > 0 {
+		lines = append(lines, "  Rating: "+icons.Rating(t.Rating))
+	}
+
 	// Add path with wrapping to show full path
 	lines = append(lines, "", "  Path:")
 	lines = append(lines, wrapPath(t.Path, 40)...)
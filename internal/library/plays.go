@@ -0,0 +1,98 @@
+package library
+
+import (
+	"database/sql"
+	"time"
+
+	dbutil "github.com/llehouerou/waves/internal/db"
+)
+
+// CountPlay adds a play of a track, played last at the given time.
+func (l *Library) CountPlay(id int64, at time.Time) error {
+	_, err := l.db.Exec(`
+		UPDATE library_tracks
+		SET play_count = play_count + 1, last_played_at = ?
+		WHERE id = ?
+	`, at.Unix(), id)
+	return err
+}
+
+// CountSkip adds a skip of a track.
+func (l *Library) CountSkip(id int64) error {
+	_, err := l.db.Exec(`
+		UPDATE library_tracks SET skip_count = skip_count + 1 WHERE id = ?
+	`, id)
+	return err
+}
+
+// MostPlayed returns the most played tracks, most played first.
+func (l *Library) MostPlayed(limit int) ([]Track, error) {
+	return l.queryTracks(`
+		WHERE play_count > 0
+		ORDER BY play_count DESC, last_played_at DESC
+		LIMIT ?
+	`, limit)
+}
+
+// RecentlyPlayed returns the last played tracks, most recent first.
+func (l *Library) RecentlyPlayed(limit int) ([]Track, error) {
+	return l.queryTracks(`
+		WHERE last_played_at IS NOT NULL
+		ORDER BY last_played_at DESC
+		LIMIT ?
+	`, limit)
+}
+
+// NeverPlayed returns tracks that were never played, most recently added
+// first.
+func (l *Library) NeverPlayed(limit int) ([]Track, error) {
+	return l.queryTracks(`
+		WHERE play_count = 0
+		ORDER BY added_at DESC, album_artist COLLATE NOCASE, album COLLATE NOCASE, disc_number, track_number
+		LIMIT ?
+	`, limit)
+}
+
+// queryTracks returns the tracks selected by a WHERE/ORDER BY clause.
+func (l *Library) queryTracks(clause string, args ...any) ([]Track, error) {
+	rows, err := l.db.Query(`
+		SELECT id, path, mtime, artist, album_artist, album, title, disc_number, track_number, year, genre, original_date, release_date, label,
+			play_count, skip_count, last_played_at, rating, album_rating
+		FROM library_tracks
+	`+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tracks []Track
+	for rows.Next() {
+		var t Track
+		var discNum, trackNum, year, lastPlayed sql.NullInt64
+		var genre, originalDate, releaseDate, label sql.NullString
+
+		if err := rows.Scan(&t.ID, &t.Path, &t.Mtime, &t.Artist, &t.AlbumArtist, &t.Album, &t.Title,
+			&discNum, &trackNum, &year, &genre, &originalDate, &releaseDate, &label,
+			&t.PlayCount, &t.SkipCount, &lastPlayed, &t.Rating, &t.AlbumRating); err != nil {
+			return nil, err
+		}
+		t.DiscNumber = int(dbutil.NullInt64Value(discNum))
+		t.TrackNumber = int(dbutil.NullInt64Value(trackNum))
+		t.Year = int(dbutil.NullInt64Value(year))
+		t.Genre = dbutil.NullStringValue(genre)
+		t.OriginalDate = dbutil.NullStringValue(originalDate)
+		t.ReleaseDate = dbutil.NullStringValue(releaseDate)
+		t.Label = dbutil.NullStringValue(label)
+		t.LastPlayed = unixTime(lastPlayed)
+		tracks = append(tracks, t)
+	}
+	return tracks, rows.Err()
+}
+
+// unixTime converts a nullable unix timestamp, NULL being the zero time.
+func unixTime(n sql.NullInt64) time.Time {
+	if !n.Valid {
+		return time.Time{}
+	}
+	return time.Unix(n.Int64, 0)
+}
@@ -0,0 +1,152 @@
+package library
+
+import (
+	"testing"
+	"time"
+)
+
+func insertPlayTestTracks(t *testing.T, lib *Library) {
+	t.Helper()
+	_, err := lib.db.Exec(`
+		INSERT INTO library_tracks (id, path, mtime, artist, album_artist, album, title, track_number, disc_number, added_at, updated_at)
+		VALUES
+			(1, '/music/a/1.mp3', 1000, 'A', 'A', 'First', 'One', 1, 1, 1000, 1000),
+			(2, '/music/a/2.mp3', 1000, 'A', 'A', 'First', 'Two', 2, 1, 1000, 1000),
+			(3, '/music/b/1.mp3', 1000, 'B', 'B', 'Second', 'Three', 1, 1, 2000, 2000)
+	`)
+	if err != nil {
+		t.Fatalf("failed to insert tracks: %v", err)
+	}
+}
+
+func TestCountPlayAndSkip(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	lib := New(db)
+	insertPlayTestTracks(t, lib)
+
+	at := time.Unix(5000, 0)
+	if err := lib.CountPlay(1, time.Unix(4000, 0)); err != nil {
+		t.Fatalf("CountPlay failed: %v", err)
+	}
+	if err := lib.CountPlay(1, at); err != nil {
+		t.Fatalf("CountPlay failed: %v", err)
+	}
+	if err := lib.CountSkip(1); err != nil {
+		t.Fatalf("CountSkip failed: %v", err)
+	}
+
+	track, err := lib.TrackByID(1)
+	if err != nil {
+		t.Fatalf("TrackByID failed: %v", err)
+	}
+	if track.PlayCount != 2 || track.SkipCount != 1 {
+		t.Errorf("counts = %d plays, %d skips, want 2 and 1", track.PlayCount, track.SkipCount)
+	}
+	if !track.LastPlayed.Equal(at) {
+		t.Errorf("LastPlayed = %v, want %v", track.LastPlayed, at)
+	}
+
+	never, err := lib.TrackByID(2)
+	if err != nil {
+		t.Fatalf("TrackByID failed: %v", err)
+	}
+	if never.PlayCount != 0 || !never.LastPlayed.IsZero() {
+		t.Errorf("unplayed track = %d plays, last %v, want none", never.PlayCount, never.LastPlayed)
+	}
+}
+
+func TestPlayQueries(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	lib := New(db)
+	insertPlayTestTracks(t, lib)
+
+	for range 3 {
+		if err := lib.CountPlay(1, time.Unix(4000, 0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := lib.CountPlay(3, time.Unix(6000, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := func(tracks []Track) []int64 {
+		out := make([]int64, len(tracks))
+		for i, tr := range tracks {
+			out[i] = tr.ID
+		}
+		return out
+	}
+	tests := []struct {
+		name  string
+		query func(int) ([]Track, error)
+		want  []int64
+	}{
+		{"most played", lib.MostPlayed, []int64{1, 3}},
+		{"recently played", lib.RecentlyPlayed, []int64{3, 1}},
+		{"never played", lib.NeverPlayed, []int64{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracks, err := tt.query(10)
+			if err != nil {
+				t.Fatalf("query failed: %v", err)
+			}
+			got := ids(tracks)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ids = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("ids = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	limited, err := lib.MostPlayed(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(limited) != 1 {
+		t.Errorf("MostPlayed(1) returned %d tracks", len(limited))
+	}
+}
+
+func TestAllAlbums_PlayStats(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	lib := New(db)
+	insertPlayTestTracks(t, lib)
+
+	if err := lib.CountPlay(1, time.Unix(4000, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := lib.CountPlay(2, time.Unix(4500, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := lib.CountSkip(2); err != nil {
+		t.Fatal(err)
+	}
+
+	albums, err := lib.AllAlbums()
+	if err != nil {
+		t.Fatalf("AllAlbums failed: %v", err)
+	}
+	for _, a := range albums {
+		switch a.Album {
+		case "First":
+			if a.PlayCount != 2 || a.SkipCount != 1 {
+				t.Errorf("First: %d plays, %d skips, want 2 and 1", a.PlayCount, a.SkipCount)
+			}
+			if !a.LastPlayed.Equal(time.Unix(4500, 0)) {
+				t.Errorf("First: LastPlayed = %v, want the latest track play", a.LastPlayed)
+			}
+		case "Second":
+			if a.PlayCount != 0 || !a.LastPlayed.IsZero() {
+				t.Errorf("Second: %d plays, last %v, want never played", a.PlayCount, a.LastPlayed)
+			}
+		}
+	}
+}
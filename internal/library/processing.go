@@ -7,6 +7,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/llehouerou/waves/internal/cue"
 	"github.com/llehouerou/waves/internal/tags"
 )
 
@@ -43,11 +44,12 @@ func (l *Library) processFiles(
 				}
 
 				resultCh <- trackResult{
-					path:   f.path,
-					mtime:  f.mtime,
-					info:   info,
-					source: f.source,
-					isNew:  fileIsNew[f.path],
+					path:    f.path,
+					mtime:   f.mtime,
+					info:    info,
+					source:  f.source,
+					section: f.section,
+					isNew:   fileIsNew[f.path],
 				}
 				processed.Add(1)
 			}
@@ -90,7 +92,7 @@ func (l *Library) processFiles(
 
 	// Collect results and insert into DB (sequential to avoid SQLite issues)
 	for result := range resultCh {
-		_ = l.upsertTrack(result.path, result.mtime, result.info)
+		_ = l.upsertTrack(result.path, result.mtime, result.info, result.section)
 
 		// Record stats
 		relPath := relativePath(result.source, result.path)
@@ -135,16 +137,22 @@ func (l *Library) getExistingTracks(sources []string) (map[string]int64, error)
 
 // upsertTrack inserts or updates a track in the database.
 // Uses file mtime for added_at on new tracks (preserved across copies).
-func (l *Library) upsertTrack(path string, mtime int64, info *tags.Tag) error {
-	return upsertTrackWithExecutor(l.db, path, mtime, info)
+// section is the part of the audio file played by CUE sheet tracks found by
+// a scan, nil otherwise.
+func (l *Library) upsertTrack(path string, mtime int64, info *tags.Tag, section *cue.Section) error {
+	return upsertTrackWithExecutor(l.db, path, mtime, info, section)
 }
 
 // upsertTrackWithExecutor is the internal implementation that accepts an executor.
-func upsertTrackWithExecutor(ex executor, path string, mtime int64, info *tags.Tag) error {
+func upsertTrackWithExecutor(ex executor, path string, mtime int64, info *tags.Tag, section *cue.Section) error {
+	src, err := trackSection(path, section)
+	if err != nil {
+		return err
+	}
 	now := time.Now().Unix()
-	_, err := ex.Exec(`
-		INSERT INTO library_tracks (path, mtime, artist, album_artist, album, title, disc_number, track_number, year, genre, original_date, release_date, label, added_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	_, err = ex.Exec(`
+		INSERT INTO library_tracks (path, mtime, artist, album_artist, album, title, disc_number, track_number, year, genre, original_date, release_date, label, rating, album_rating, source_path, start_ms, end_ms, added_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(path) DO UPDATE SET
 			mtime = excluded.mtime,
 			artist = excluded.artist,
@@ -158,8 +166,15 @@ func upsertTrackWithExecutor(ex executor, path string, mtime int64, info *tags.T
 			original_date = excluded.original_date,
 			release_date = excluded.release_date,
 			label = excluded.label,
+			-- Ratings only set in waves (tag writing disabled) are kept
+			rating = CASE WHEN excluded.rating > 0 THEN excluded.rating ELSE rating END,
+			album_rating = CASE WHEN excluded.album_rating > 0 THEN excluded.album_rating ELSE album_rating END,
+			source_path = excluded.source_path,
+			start_ms = excluded.start_ms,
+			end_ms = excluded.end_ms,
 			updated_at = excluded.updated_at
-	`, path, mtime, info.Artist, info.AlbumArtist, info.Album, info.Title, info.DiscNumber, info.TrackNumber, info.Year(), info.Genre, info.OriginalDate, info.Date, info.Label, mtime, now)
+	`, path, mtime, info.Artist, info.AlbumArtist, info.Album, info.Title, info.DiscNumber, info.TrackNumber, info.Year(), info.Genre, info.OriginalDate, info.Date, info.Label, info.Rating, info.AlbumRating,
+		src.AudioPath, src.Start.Milliseconds(), src.End.Milliseconds(), mtime, now)
 	return err
 }
 
@@ -246,7 +261,7 @@ func (l *Library) AddTracks(paths []string) error {
 	defer tx.Rollback() //nolint:errcheck // rollback after commit is a no-op
 
 	for _, t := range tracks {
-		if err := upsertTrackWithExecutor(tx, t.path, t.mtime, t.info); err != nil {
+		if err := upsertTrackWithExecutor(tx, t.path, t.mtime, t.info, nil); err != nil {
 			return err
 		}
 
@@ -31,7 +31,7 @@ func (l *Library) Artists() ([]string, error) {
 // Albums returns all albums for a given album artist.
 func (l *Library) Albums(albumArtist string) ([]Album, error) {
 	rows, err := l.db.Query(`
-		SELECT album, MAX(year) as year
+		SELECT album, MAX(year) as year, MAX(album_rating) as rating
 		FROM library_tracks
 		WHERE album_artist = ?
 		GROUP BY album
@@ -46,7 +46,7 @@ func (l *Library) Albums(albumArtist string) ([]Album, error) {
 	for rows.Next() {
 		var a Album
 		var year sql.NullInt64
-		if err := rows.Scan(&a.Name, &year); err != nil {
+		if err := rows.Scan(&a.Name, &year, &a.Rating); err != nil {
 			return nil, err
 		}
 		a.Year = int(dbutil.NullInt64Value(year))
@@ -58,7 +58,8 @@ func (l *Library) Albums(albumArtist string) ([]Album, error) {
 // Tracks returns all tracks for a given album artist and album.
 func (l *Library) Tracks(albumArtist, album string) ([]Track, error) {
 	rows, err := l.db.Query(`
-		SELECT id, path, mtime, artist, album_artist, album, title, disc_number, track_number, year, genre, original_date, release_date, label
+		SELECT id, path, mtime, artist, album_artist, album, title, disc_number, track_number, year, genre, original_date, release_date, label,
+			play_count, skip_count, last_played_at, rating, album_rating
 		FROM library_tracks
 		WHERE album_artist = ? AND album = ?
 		ORDER BY disc_number, track_number, title COLLATE NOCASE
@@ -71,11 +72,12 @@ func (l *Library) Tracks(albumArtist, album string) ([]Track, error) {
 	var tracks []Track
 	for rows.Next() {
 		var t Track
-		var discNum, trackNum, year sql.NullInt64
+		var discNum, trackNum, year, lastPlayed sql.NullInt64
 		var genre, originalDate, releaseDate, label sql.NullString
 
 		if err := rows.Scan(&t.ID, &t.Path, &t.Mtime, &t.Artist, &t.AlbumArtist, &t.Album, &t.Title,
-			&discNum, &trackNum, &year, &genre, &originalDate, &releaseDate, &label); err != nil {
+			&discNum, &trackNum, &year, &genre, &originalDate, &releaseDate, &label,
+			&t.PlayCount, &t.SkipCount, &lastPlayed, &t.Rating, &t.AlbumRating); err != nil {
 			return nil, err
 		}
 		t.DiscNumber = int(dbutil.NullInt64Value(discNum))
@@ -85,6 +87,7 @@ func (l *Library) Tracks(albumArtist, album string) ([]Track, error) {
 		t.OriginalDate = dbutil.NullStringValue(originalDate)
 		t.ReleaseDate = dbutil.NullStringValue(releaseDate)
 		t.Label = dbutil.NullStringValue(label)
+		t.LastPlayed = unixTime(lastPlayed)
 		tracks = append(tracks, t)
 	}
 	return tracks, rows.Err()
@@ -100,17 +103,19 @@ func (l *Library) TrackCount() (int, error) {
 // TrackByID returns a track by its ID.
 func (l *Library) TrackByID(id int64) (*Track, error) {
 	row := l.db.QueryRow(`
-		SELECT id, path, mtime, artist, album_artist, album, title, disc_number, track_number, year, genre, original_date, release_date, label
+		SELECT id, path, mtime, artist, album_artist, album, title, disc_number, track_number, year, genre, original_date, release_date, label,
+			play_count, skip_count, last_played_at, rating, album_rating
 		FROM library_tracks
 		WHERE id = ?
 	`, id)
 
 	var t Track
-	var discNum, trackNum, year sql.NullInt64
+	var discNum, trackNum, year, lastPlayed sql.NullInt64
 	var genre, originalDate, releaseDate, label sql.NullString
 
 	err := row.Scan(&t.ID, &t.Path, &t.Mtime, &t.Artist, &t.AlbumArtist, &t.Album, &t.Title,
-		&discNum, &trackNum, &year, &genre, &originalDate, &releaseDate, &label)
+		&discNum, &trackNum, &year, &genre, &originalDate, &releaseDate, &label,
+		&t.PlayCount, &t.SkipCount, &lastPlayed, &t.Rating, &t.AlbumRating)
 	if err != nil {
 		return nil, err
 	}
@@ -121,6 +126,7 @@ func (l *Library) TrackByID(id int64) (*Track, error) {
 	t.OriginalDate = dbutil.NullStringValue(originalDate)
 	t.ReleaseDate = dbutil.NullStringValue(releaseDate)
 	t.Label = dbutil.NullStringValue(label)
+	t.LastPlayed = unixTime(lastPlayed)
 	return &t, nil
 }
 
@@ -129,20 +135,35 @@ func (l *Library) TrackByPath(path string) (*Track, error) {
 	return trackByPathWithExecutor(l.db, path)
 }
 
+// TrackIDByArtistTitle returns the ID of a track by its artist (or album
+// artist) and title, ignoring case. Returns sql.ErrNoRows if none matches.
+func (l *Library) TrackIDByArtistTitle(artist, title string) (int64, error) {
+	var id int64
+	err := l.db.QueryRow(`
+		SELECT id FROM library_tracks
+		WHERE (artist = ? COLLATE NOCASE OR album_artist = ? COLLATE NOCASE) AND title = ? COLLATE NOCASE
+		ORDER BY id
+		LIMIT 1
+	`, artist, artist, title).Scan(&id)
+	return id, err
+}
+
 // trackByPathWithExecutor is the internal implementation that accepts an executor.
 func trackByPathWithExecutor(ex executor, path string) (*Track, error) {
 	row := ex.QueryRow(`
-		SELECT id, path, mtime, artist, album_artist, album, title, disc_number, track_number, year, genre, original_date, release_date, label
+		SELECT id, path, mtime, artist, album_artist, album, title, disc_number, track_number, year, genre, original_date, release_date, label,
+			play_count, skip_count, last_played_at, rating, album_rating
 		FROM library_tracks
 		WHERE path = ?
 	`, path)
 
 	var t Track
-	var discNum, trackNum, year sql.NullInt64
+	var discNum, trackNum, year, lastPlayed sql.NullInt64
 	var genre, originalDate, releaseDate, label sql.NullString
 
 	err := row.Scan(&t.ID, &t.Path, &t.Mtime, &t.Artist, &t.AlbumArtist, &t.Album, &t.Title,
-		&discNum, &trackNum, &year, &genre, &originalDate, &releaseDate, &label)
+		&discNum, &trackNum, &year, &genre, &originalDate, &releaseDate, &label,
+		&t.PlayCount, &t.SkipCount, &lastPlayed, &t.Rating, &t.AlbumRating)
 	if err != nil {
 		return nil, err
 	}
@@ -153,6 +174,7 @@ func trackByPathWithExecutor(ex executor, path string) (*Track, error) {
 	t.OriginalDate = dbutil.NullStringValue(originalDate)
 	t.ReleaseDate = dbutil.NullStringValue(releaseDate)
 	t.Label = dbutil.NullStringValue(label)
+	t.LastPlayed = unixTime(lastPlayed)
 	return &t, nil
 }
 
@@ -183,7 +205,8 @@ func (l *Library) AlbumHasMultipleDiscs(albumArtist, album string) (bool, error)
 // ArtistTracks returns all tracks for an artist, ordered by album year then disc/track number.
 func (l *Library) ArtistTracks(albumArtist string) ([]Track, error) {
 	rows, err := l.db.Query(`
-		SELECT id, path, mtime, artist, album_artist, album, title, disc_number, track_number, year, genre, original_date, release_date, label
+		SELECT id, path, mtime, artist, album_artist, album, title, disc_number, track_number, year, genre, original_date, release_date, label,
+			play_count, skip_count, last_played_at, rating, album_rating
 		FROM library_tracks
 		WHERE album_artist = ?
 		ORDER BY (year IS NULL OR year = 0), year, album COLLATE NOCASE, disc_number, track_number, title COLLATE NOCASE
@@ -196,11 +219,12 @@ func (l *Library) ArtistTracks(albumArtist string) ([]Track, error) {
 	var tracks []Track
 	for rows.Next() {
 		var t Track
-		var discNum, trackNum, year sql.NullInt64
+		var discNum, trackNum, year, lastPlayed sql.NullInt64
 		var genre, originalDate, releaseDate, label sql.NullString
 
 		if err := rows.Scan(&t.ID, &t.Path, &t.Mtime, &t.Artist, &t.AlbumArtist, &t.Album, &t.Title,
-			&discNum, &trackNum, &year, &genre, &originalDate, &releaseDate, &label); err != nil {
+			&discNum, &trackNum, &year, &genre, &originalDate, &releaseDate, &label,
+			&t.PlayCount, &t.SkipCount, &lastPlayed, &t.Rating, &t.AlbumRating); err != nil {
 			return nil, err
 		}
 		t.DiscNumber = int(dbutil.NullInt64Value(discNum))
@@ -210,6 +234,7 @@ func (l *Library) ArtistTracks(albumArtist string) ([]Track, error) {
 		t.OriginalDate = dbutil.NullStringValue(originalDate)
 		t.ReleaseDate = dbutil.NullStringValue(releaseDate)
 		t.Label = dbutil.NullStringValue(label)
+		t.LastPlayed = unixTime(lastPlayed)
 		tracks = append(tracks, t)
 	}
 	return tracks, rows.Err()
@@ -327,7 +352,11 @@ func (l *Library) AllAlbums() ([]AlbumEntry, error) {
 			 WHERE t3.album_artist = t1.album_artist
 			   AND t3.album = t1.album
 			   AND label IS NOT NULL AND label != ''
-			 GROUP BY label ORDER BY COUNT(*) DESC LIMIT 1) as label
+			 GROUP BY label ORDER BY COUNT(*) DESC LIMIT 1) as label,
+			SUM(play_count) as play_count,
+			SUM(skip_count) as skip_count,
+			MAX(last_played_at) as last_played_at,
+			MAX(album_rating) as rating
 		FROM library_tracks t1
 		GROUP BY album_artist, album
 		ORDER BY original_date DESC, release_date DESC, added_at DESC
@@ -341,14 +370,17 @@ func (l *Library) AllAlbums() ([]AlbumEntry, error) {
 	for rows.Next() {
 		var a AlbumEntry
 		var addedAt int64
+		var lastPlayed sql.NullInt64
 		var genre, label sql.NullString
 
-		if err := rows.Scan(&a.AlbumArtist, &a.Album, &a.OriginalDate, &a.ReleaseDate, &addedAt, &a.TrackCount, &genre, &label); err != nil {
+		if err := rows.Scan(&a.AlbumArtist, &a.Album, &a.OriginalDate, &a.ReleaseDate, &addedAt, &a.TrackCount, &genre, &label,
+			&a.PlayCount, &a.SkipCount, &lastPlayed, &a.Rating); err != nil {
 			return nil, err
 		}
 		a.AddedAt = time.Unix(addedAt, 0)
 		a.Genre = dbutil.NullStringValue(genre)
 		a.Label = dbutil.NullStringValue(label)
+		a.LastPlayed = unixTime(lastPlayed)
 		albums = append(albums, a)
 	}
 	return albums, rows.Err()
@@ -328,6 +328,38 @@ func TestTrackByPath(t *testing.T) {
 	}
 }
 
+func TestTrackIDByArtistTitle(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	lib := New(db)
+
+	_, err := db.Exec(`
+		INSERT INTO library_tracks (path, mtime, artist, album_artist, album, title, track_number, disc_number, year, added_at, updated_at)
+		VALUES
+			('/music/a.mp3', 1000, 'Guest', 'The Band', 'Album', 'Intro', 1, 1, 2023, 1000, 1000),
+			('/music/b.mp3', 1000, 'The Band', 'The Band', 'Album', 'Song', 2, 1, 2023, 1000, 1000)
+	`)
+	if err != nil {
+		t.Fatalf("failed to insert tracks: %v", err)
+	}
+
+	id, err := lib.TrackIDByArtistTitle("the band", "SONG")
+	if err != nil || id != 2 {
+		t.Errorf("TrackIDByArtistTitle() = %d, %v, want 2", id, err)
+	}
+
+	// Album artist matches too
+	id, err = lib.TrackIDByArtistTitle("The Band", "Intro")
+	if err != nil || id != 1 {
+		t.Errorf("TrackIDByArtistTitle() = %d, %v, want 1", id, err)
+	}
+
+	_, err = lib.TrackIDByArtistTitle("The Band", "Outro")
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows, got %v", err)
+	}
+}
+
 func TestArtistCount(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -0,0 +1,45 @@
+package library
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/llehouerou/waves/internal/tags"
+)
+
+// SetTrackRating sets the rating of a track, in half stars. 0 clears it.
+func (l *Library) SetTrackRating(id int64, rating int) error {
+	_, err := l.db.Exec(`
+		UPDATE library_tracks SET rating = ? WHERE id = ?
+	`, tags.ClampRating(rating), id)
+	return err
+}
+
+// SetAlbumRating sets the album rating of all tracks of an album, in half
+// stars. 0 clears it.
+func (l *Library) SetAlbumRating(albumArtist, album string, rating int) error {
+	_, err := l.db.Exec(`
+		UPDATE library_tracks SET album_rating = ? WHERE album_artist = ? AND album = ?
+	`, tags.ClampRating(rating), albumArtist, album)
+	return err
+}
+
+// TrackRating returns the rating of a track, in half stars.
+func (l *Library) TrackRating(id int64) (int, error) {
+	var rating int
+	err := l.db.QueryRow(`SELECT rating FROM library_tracks WHERE id = ?`, id).Scan(&rating)
+	return rating, err
+}
+
+// WriteRatingTags writes the track and album ratings of tracks to their
+// files, so that other players see them. All tracks are tried; the errors
+// are joined.
+func WriteRatingTags(tracks []Track) error {
+	var errs []error
+	for _, t := range tracks {
+		if err := tags.WriteRatings(t.Path, t.Rating, t.AlbumRating); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t.Path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,97 @@
+package library
+
+import (
+	"testing"
+
+	"github.com/llehouerou/waves/internal/tags"
+)
+
+func TestSetRatings(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	lib := New(db)
+	insertPlayTestTracks(t, lib)
+
+	if err := lib.SetTrackRating(1, 7); err != nil {
+		t.Fatalf("SetTrackRating failed: %v", err)
+	}
+	if err := lib.SetAlbumRating("A", "First", 12); err != nil {
+		t.Fatalf("SetAlbumRating failed: %v", err)
+	}
+
+	tracks, err := lib.Tracks("A", "First")
+	if err != nil {
+		t.Fatalf("Tracks failed: %v", err)
+	}
+	if tracks[0].Rating != 7 || tracks[1].Rating != 0 {
+		t.Errorf("track ratings = %d, %d, want 7, 0", tracks[0].Rating, tracks[1].Rating)
+	}
+	for _, tr := range tracks {
+		if tr.AlbumRating != 10 {
+			t.Errorf("%s: AlbumRating = %d, want 10 (clamped)", tr.Title, tr.AlbumRating)
+		}
+	}
+
+	rating, err := lib.TrackRating(1)
+	if err != nil || rating != 7 {
+		t.Errorf("TrackRating(1) = %d, %v, want 7", rating, err)
+	}
+
+	albums, err := lib.Albums("A")
+	if err != nil {
+		t.Fatalf("Albums failed: %v", err)
+	}
+	if len(albums) != 1 || albums[0].Rating != 10 {
+		t.Errorf("Albums() = %+v, want First rated 10", albums)
+	}
+
+	all, err := lib.AllAlbums()
+	if err != nil {
+		t.Fatalf("AllAlbums failed: %v", err)
+	}
+	for _, a := range all {
+		want := 0
+		if a.Album == "First" {
+			want = 10
+		}
+		if a.Rating != want {
+			t.Errorf("%s: Rating = %d, want %d", a.Album, a.Rating, want)
+		}
+	}
+}
+
+func TestUpsertTrack_Ratings(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	lib := New(db)
+
+	const path = "/music/a.flac"
+	if err := lib.upsertTrack(path, 1, &tags.Tag{Title: "A", Rating: 6}, nil); err != nil {
+		t.Fatalf("upsertTrack failed: %v", err)
+	}
+	track, err := lib.TrackByPath(path)
+	if err != nil || track.Rating != 6 {
+		t.Fatalf("rating read from tags = %d, %v, want 6", track.Rating, err)
+	}
+
+	// A rating set in waves survives a rescan of a file without rating
+	if err := lib.SetAlbumRating("", "", 8); err != nil {
+		t.Fatal(err)
+	}
+	if err := lib.upsertTrack(path, 2, &tags.Tag{Title: "A"}, nil); err != nil {
+		t.Fatalf("upsertTrack failed: %v", err)
+	}
+	track, _ = lib.TrackByPath(path)
+	if track.Rating != 6 || track.AlbumRating != 8 {
+		t.Errorf("ratings after rescan = %d, %d, want 6, 8", track.Rating, track.AlbumRating)
+	}
+
+	// File ratings win over stored ones
+	if err := lib.upsertTrack(path, 3, &tags.Tag{Title: "A", Rating: 2}, nil); err != nil {
+		t.Fatalf("upsertTrack failed: %v", err)
+	}
+	track, _ = lib.TrackByPath(path)
+	if track.Rating != 2 {
+		t.Errorf("rating after retag = %d, want 2", track.Rating)
+	}
+}
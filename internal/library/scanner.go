@@ -1,8 +1,11 @@
 package library
 
 import (
+	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/llehouerou/waves/internal/cue"
 	"github.com/llehouerou/waves/internal/tags"
 )
 
@@ -29,20 +32,35 @@ type SourceStats struct {
 	Updated []string // relative paths of updated tracks (mtime changed)
 }
 
+// ChangedPaths returns the absolute paths of the tracks added or updated
+// by the scan.
+func (s *ScanStats) ChangedPaths() []string {
+	var paths []string
+	for source, stats := range s.BySource {
+		for _, rel := range slices.Concat(stats.Added, stats.Updated) {
+			paths = append(paths, filepath.Join(source, rel))
+		}
+	}
+	slices.Sort(paths)
+	return paths
+}
+
 // fileInfo holds information about a discovered music file.
 type fileInfo struct {
-	path   string
-	mtime  int64
-	source string // source path this file belongs to
+	path    string
+	mtime   int64
+	source  string       // source path this file belongs to
+	section *cue.Section // CUE sheet tracks: the part of the audio file played
 }
 
 // trackResult holds the result of processing a music file.
 type trackResult struct {
-	path   string
-	mtime  int64
-	info   *tags.Tag
-	source string // source path this file belongs to
-	isNew  bool   // true if new track, false if updated
+	path    string
+	mtime   int64
+	info    *tags.Tag
+	source  string // source path this file belongs to
+	section *cue.Section
+	isNew   bool // true if new track, false if updated
 }
 
 // Refresh performs an incremental scan of the given source directories.
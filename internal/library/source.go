@@ -69,11 +69,12 @@ func (s *Source) albumChildren(artist string) ([]Node, error) {
 			name = fmt.Sprintf("[%d] %s", album.Year, album.Name)
 		}
 		nodes[i] = Node{
-			level:     LevelAlbum,
-			artist:    artist,
-			album:     album.Name,
-			albumYear: album.Year,
-			name:      name,
+			level:       LevelAlbum,
+			artist:      artist,
+			album:       album.Name,
+			albumYear:   album.Year,
+			albumRating: album.Rating,
+			name:        name,
 		}
 	}
 	return nodes, nil
@@ -1,6 +1,7 @@
 package library
 
 import (
+	"slices"
 	"testing"
 )
 
@@ -463,3 +464,16 @@ func TestMigrateSources_EmptyList(t *testing.T) {
 		t.Errorf("expected 0 sources, got %d", len(result))
 	}
 }
+
+func TestScanStats_ChangedPaths(t *testing.T) {
+	stats := &ScanStats{BySource: map[string]*SourceStats{
+		"/music/b": {Added: []string{"x.flac"}, Removed: []string{"gone.flac"}},
+		"/music/a": {Added: []string{"Album/01.mp3"}, Updated: []string{"Album/02.mp3"}},
+	}}
+
+	got := stats.ChangedPaths()
+	want := []string{"/music/a/Album/01.mp3", "/music/a/Album/02.mp3", "/music/b/x.flac"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ChangedPaths() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,72 @@
+package loudness
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/tags"
+)
+
+// Reference levels for gain computation.
+const (
+	ReplayGainReference = -18.0 // LUFS, ReplayGain 2.0
+	bufferSize          = 8192  // Samples decoded per read
+)
+
+// AnalyzeFile decodes an audio file and measures its loudness.
+// Decoding stops early with ctx.Err() if ctx is canceled.
+func AnalyzeFile(ctx context.Context, path string) (Measurement, error) {
+	streamer, format, err := player.Decode(path)
+	if err != nil {
+		return Measurement{}, fmt.Errorf("decode: %w", err)
+	}
+	defer streamer.Close()
+
+	meter := NewMeter(int(format.SampleRate), format.NumChannels)
+	buf := make([][2]float64, bufferSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return Measurement{}, err
+		}
+		n, ok := streamer.Stream(buf)
+		meter.Write(buf[:n])
+		if !ok {
+			break
+		}
+	}
+	if err := streamer.Err(); err != nil {
+		return Measurement{}, fmt.Errorf("decode: %w", err)
+	}
+
+	return meter.Result(), nil
+}
+
+// Gain returns the ReplayGain 2.0 gain in dB that brings the loudness to
+// the -18 LUFS reference.
+func Gain(loudness float64) float64 {
+	return ReplayGainReference - loudness
+}
+
+// ReplayGain builds the tag values for a track from its own measurement
+// and the measurement of its album. Silent measurements leave the
+// corresponding gain unset.
+func ReplayGain(track, album Measurement) tags.ReplayGain {
+	var rg tags.ReplayGain
+	if !track.IsSilent() {
+		rg.TrackGain = Gain(track.Loudness)
+		rg.TrackPeak = track.Peak
+		rg.HasTrackGain = true
+	}
+	if !album.IsSilent() {
+		rg.AlbumGain = Gain(album.Loudness)
+		rg.AlbumPeak = album.Peak
+		rg.HasAlbumGain = true
+	}
+	return rg
+}
+
+// WriteTags stores gain values in a file, leaving its other tags untouched.
+func WriteTags(path string, rg tags.ReplayGain) error {
+	return tags.WriteReplayGain(path, rg)
+}
@@ -0,0 +1,65 @@
+package loudness
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ProgressMsg reports that a file was analyzed.
+type ProgressMsg struct {
+	JobID   string
+	Current int
+	Total   int
+}
+
+// CompleteMsg signals the analysis finished or was canceled.
+type CompleteMsg struct {
+	JobID    string
+	Written  []string // Files whose gain tags were updated
+	Errors   []FileError
+	Canceled bool
+}
+
+// BatchCmd starts the analysis. Each step analyzes one file and returns a
+// ProgressMsg; the handler chains to the next step with ContinueCmd.
+func BatchCmd(job *Job) tea.Cmd {
+	return analyzeNextFile(job)
+}
+
+// ContinueCmd returns a command to analyze the next file.
+func ContinueCmd(job *Job) tea.Cmd {
+	return analyzeNextFile(job)
+}
+
+// analyzeNextFile measures a single file and, once its album is complete,
+// writes the gain tags of the whole album.
+func analyzeNextFile(job *Job) tea.Cmd {
+	return func() tea.Msg {
+		path, ok := job.next()
+		if !ok || job.IsCanceled() {
+			canceled := job.IsCanceled()
+			job.complete()
+			return CompleteMsg{
+				JobID:    job.ID(),
+				Written:  job.Written(),
+				Errors:   job.Errors(),
+				Canceled: canceled && ok,
+			}
+		}
+
+		m, err := AnalyzeFile(job.ctx, path)
+		if job.IsCanceled() {
+			// Don't record a partial measurement, the next step completes the job
+			return job.progressMsg()
+		}
+
+		paths, measured := job.record(m, err)
+		if len(paths) > 0 {
+			album := Combine(measured...)
+			for i, p := range paths {
+				job.recordWrite(p, WriteTags(p, ReplayGain(measured[i], album)))
+			}
+		}
+
+		return job.progressMsg()
+	}
+}
@@ -0,0 +1,178 @@
+package loudness
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/llehouerou/waves/internal/ui/jobbar"
+)
+
+// Album is a group of files analyzed together for album gain.
+type Album struct {
+	Name  string // Display name, e.g. "Artist - Album"
+	Paths []string
+}
+
+// FileError records a file that could not be analyzed or tagged.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+// Job tracks the progress of a loudness analysis.
+// Albums are processed in order, one file per step.
+type Job struct {
+	mu       sync.Mutex
+	bar      *jobbar.Job
+	ctx      context.Context
+	cancel   context.CancelFunc
+	albums   []Album
+	album    int           // Index of the album being analyzed
+	file     int           // Index of the next file in that album
+	measured []Measurement // Measurements of the current album so far
+	pending  []string      // Files of the current album that were measured
+	written  []string
+	errors   []FileError
+}
+
+// NewJob creates a new analysis job.
+func NewJob(albums []Album) *Job {
+	ctx, cancel := context.WithCancel(context.Background())
+	j := &Job{
+		bar: &jobbar.Job{
+			ID: fmt.Sprintf("loudness-%d", time.Now().UnixNano()),
+		},
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	j.Add(albums...)
+	return j
+}
+
+// JobBar returns the jobbar.Job for display.
+func (j *Job) JobBar() *jobbar.Job {
+	return j.bar
+}
+
+// ID returns the job identifier.
+func (j *Job) ID() string {
+	return j.bar.ID
+}
+
+// Add queues more albums on a running job.
+func (j *Job) Add(albums ...Album) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, a := range albums {
+		if len(a.Paths) == 0 {
+			continue
+		}
+		j.albums = append(j.albums, a)
+		j.bar.Total += len(a.Paths)
+	}
+	j.updateLabel()
+}
+
+// updateLabel shows the album being analyzed. Caller must hold mu.
+func (j *Job) updateLabel() {
+	if j.album < len(j.albums) {
+		j.bar.Label = "Analyzing loudness: " + j.albums[j.album].Name
+	}
+}
+
+// Cancel stops the job; the file being analyzed is abandoned and albums
+// that were not finished are left untouched.
+func (j *Job) Cancel() {
+	j.cancel()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.bar.Label = "Canceling loudness analysis"
+}
+
+// IsCanceled returns true if the job was canceled.
+func (j *Job) IsCanceled() bool {
+	return j.ctx.Err() != nil
+}
+
+// Written returns the files whose tags were updated.
+func (j *Job) Written() []string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]string(nil), j.written...)
+}
+
+// Errors returns all failures.
+func (j *Job) Errors() []FileError {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]FileError(nil), j.errors...)
+}
+
+// next returns the file to analyze, or false when all albums are done.
+func (j *Job) next() (string, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.album >= len(j.albums) {
+		return "", false
+	}
+	return j.albums[j.album].Paths[j.file], true
+}
+
+// record stores the measurement of the current file. When the album is
+// complete it returns the measured files with their measurements, ready
+// for tagging. Files that failed are left out.
+func (j *Job) record(m Measurement, err error) (paths []string, measured []Measurement) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	album := j.albums[j.album]
+	path := album.Paths[j.file]
+	if err != nil {
+		j.errors = append(j.errors, FileError{Path: path, Err: err})
+	} else {
+		j.pending = append(j.pending, path)
+		j.measured = append(j.measured, m)
+	}
+	j.file++
+	j.bar.Current++
+
+	if j.file < len(album.Paths) {
+		return nil, nil
+	}
+
+	paths, measured = j.pending, j.measured
+	j.album++
+	j.file = 0
+	j.pending = nil
+	j.measured = nil
+	j.updateLabel()
+	return paths, measured
+}
+
+// recordWrite stores the result of tagging a file.
+func (j *Job) recordWrite(path string, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err != nil {
+		j.errors = append(j.errors, FileError{Path: path, Err: err})
+		return
+	}
+	j.written = append(j.written, path)
+}
+
+// progressMsg reports the current progress.
+func (j *Job) progressMsg() ProgressMsg {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return ProgressMsg{JobID: j.bar.ID, Current: j.bar.Current, Total: j.bar.Total}
+}
+
+// complete marks the job as done.
+func (j *Job) complete() {
+	j.cancel()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.bar.Done = true
+}
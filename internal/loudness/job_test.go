@@ -0,0 +1,150 @@
+package loudness
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/llehouerou/waves/internal/tags"
+)
+
+const testFile = "../player/testdata/vorbis_44100_stereo.ogg"
+
+// copyTestFile copies the Vorbis test file into dir under the given name.
+func copyTestFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Skipf("test file not available: %v", err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// runJob executes the job's command chain until completion.
+func runJob(t *testing.T, job *Job) CompleteMsg {
+	t.Helper()
+	cmd := BatchCmd(job)
+	for range 1000 {
+		switch msg := cmd().(type) {
+		case ProgressMsg:
+			cmd = ContinueCmd(job)
+		case CompleteMsg:
+			return msg
+		default:
+			t.Fatalf("unexpected message %T", msg)
+		}
+	}
+	t.Fatal("job did not complete")
+	return CompleteMsg{}
+}
+
+func TestAnalyzeFile(t *testing.T) {
+	path := copyTestFile(t, t.TempDir(), "a.ogg")
+
+	m, err := AnalyzeFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("AnalyzeFile() error: %v", err)
+	}
+	if m.IsSilent() {
+		t.Fatal("expected a loudness measurement")
+	}
+	if m.Peak <= 0 || m.Peak > 2 {
+		t.Errorf("peak = %v, want a plausible value", m.Peak)
+	}
+}
+
+func TestAnalyzeFile_Canceled(t *testing.T) {
+	path := copyTestFile(t, t.TempDir(), "a.ogg")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := AnalyzeFile(ctx, path); err == nil {
+		t.Error("expected an error for a canceled context")
+	}
+}
+
+func TestJob_WritesAlbumTags(t *testing.T) {
+	dir := t.TempDir()
+	a := copyTestFile(t, dir, "a.ogg")
+	b := copyTestFile(t, dir, "b.ogg")
+	missing := filepath.Join(dir, "missing.ogg")
+
+	job := NewJob([]Album{{Name: "Test", Paths: []string{a, missing, b}}})
+	if job.JobBar().Total != 3 {
+		t.Fatalf("Total = %d, want 3", job.JobBar().Total)
+	}
+
+	msg := runJob(t, job)
+	if msg.Canceled {
+		t.Error("job should not be canceled")
+	}
+	if len(msg.Written) != 2 {
+		t.Errorf("Written = %v, want both readable files", msg.Written)
+	}
+	if len(msg.Errors) != 1 || msg.Errors[0].Path != missing {
+		t.Errorf("Errors = %v, want the missing file", msg.Errors)
+	}
+	if !job.JobBar().Done || job.JobBar().Current != 3 {
+		t.Errorf("job bar = %+v, want done with 3/3", *job.JobBar())
+	}
+
+	ta, err := tags.Read(a)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	tb, err := tags.Read(b)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	if !ta.ReplayGain.HasTrackGain || !ta.ReplayGain.HasAlbumGain {
+		t.Fatalf("gain tags missing: %+v", ta.ReplayGain)
+	}
+	// Identical files: album gain equals track gain
+	if diff := ta.ReplayGain.AlbumGain - ta.ReplayGain.TrackGain; diff > 0.01 || diff < -0.01 {
+		t.Errorf("album gain %v != track gain %v", ta.ReplayGain.AlbumGain, ta.ReplayGain.TrackGain)
+	}
+	if ta.ReplayGain != tb.ReplayGain {
+		t.Errorf("identical files got different gains: %+v vs %+v", ta.ReplayGain, tb.ReplayGain)
+	}
+}
+
+func TestJob_Cancel(t *testing.T) {
+	dir := t.TempDir()
+	a := copyTestFile(t, dir, "a.ogg")
+
+	job := NewJob([]Album{{Name: "Test", Paths: []string{a}}})
+	job.Cancel()
+
+	msg := runJob(t, job)
+	if !msg.Canceled {
+		t.Error("expected a canceled job")
+	}
+	if len(msg.Written) != 0 {
+		t.Errorf("Written = %v, want nothing after cancel", msg.Written)
+	}
+
+	ta, err := tags.Read(a)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	if !ta.ReplayGain.IsEmpty() {
+		t.Errorf("canceled job wrote tags: %+v", ta.ReplayGain)
+	}
+}
+
+func TestJob_Add(t *testing.T) {
+	job := NewJob(nil)
+	job.Add(Album{Name: "Empty"}, Album{Name: "One", Paths: []string{"x.flac"}})
+
+	if job.JobBar().Total != 1 {
+		t.Errorf("Total = %d, want 1 (empty albums are skipped)", job.JobBar().Total)
+	}
+	if job.JobBar().Label != "Analyzing loudness: One" {
+		t.Errorf("Label = %q", job.JobBar().Label)
+	}
+}
@@ -0,0 +1,204 @@
+// Package loudness measures EBU R128 loudness of audio files and writes
+// ReplayGain / R128 gain tags.
+package loudness
+
+import (
+	"math"
+)
+
+// Gating thresholds from ITU-R BS.1770-4.
+const (
+	absoluteGate = -70.0 // LUFS
+	relativeGate = -10.0 // LU below the ungated loudness
+)
+
+// biquad is a second-order IIR filter in direct form I.
+type biquad struct {
+	b0, b1, b2 float64
+	a1, a2     float64
+	x1, x2     float64
+	y1, y2     float64
+}
+
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+// kWeighting returns the two-stage K-weighting filter (high shelf followed by
+// high pass) for the given sample rate. Coefficients are derived from the
+// analog prototypes so that any sample rate gives the BS.1770 response.
+func kWeighting(sampleRate float64) [2]biquad {
+	// Stage 1: high shelf modelling the acoustic effect of the head
+	f0 := 1681.974450955533
+	g := 3.999843853973347
+	q := 0.7071752369554196
+	k := math.Tan(math.Pi * f0 / sampleRate)
+	vh := math.Pow(10, g/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/q + k*k
+	shelf := biquad{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	// Stage 2: RLB high pass
+	f0 = 38.13547087602444
+	q = 0.5003270373238773
+	k = math.Tan(math.Pi * f0 / sampleRate)
+	a0 = 1 + k/q + k*k
+	highPass := biquad{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	return [2]biquad{shelf, highPass}
+}
+
+// Measurement is the result of measuring a signal.
+type Measurement struct {
+	Loudness float64 // Integrated loudness in LUFS, -Inf for silence
+	Peak     float64 // True peak, linear (1.0 = full scale)
+
+	blocks []float64 // Mean square of each gating block, for album pooling
+}
+
+// IsSilent returns true if no block passed the absolute gate.
+func (m Measurement) IsSilent() bool {
+	return math.IsInf(m.Loudness, -1)
+}
+
+// Meter measures integrated loudness (BS.1770-4 / EBU R128) and true peak.
+// Samples are fed with Write; Result returns the measurement so far.
+type Meter struct {
+	channels int
+	filters  [2][2]biquad
+	peak     *truePeakMeter
+
+	// Gating blocks are 400 ms long with 75% overlap, built from 100 ms
+	// sub-blocks. subSums holds the last three complete sub-blocks.
+	subBlockLen int
+	subCount    int
+	subSum      float64
+	subSums     [3]float64
+	subFilled   int
+	blocks      []float64
+}
+
+// NewMeter creates a meter for a signal at the given sample rate.
+// channels is 1 for mono (only the left channel is measured) or 2 for stereo.
+func NewMeter(sampleRate, channels int) *Meter {
+	kw := kWeighting(float64(sampleRate))
+	return &Meter{
+		channels:    max(min(channels, 2), 1),
+		filters:     [2][2]biquad{kw, kw},
+		peak:        newTruePeakMeter(sampleRate),
+		subBlockLen: max(int(math.Round(float64(sampleRate)/10)), 1),
+	}
+}
+
+// Write feeds samples to the meter.
+func (m *Meter) Write(samples [][2]float64) {
+	m.peak.write(samples, m.channels)
+
+	for _, s := range samples {
+		for ch := range m.channels {
+			f := &m.filters[ch]
+			y := f[1].process(f[0].process(s[ch]))
+			m.subSum += y * y
+		}
+		m.subCount++
+		if m.subCount == m.subBlockLen {
+			m.finishSubBlock()
+		}
+	}
+}
+
+// finishSubBlock closes the current 100 ms sub-block and emits a gating block
+// once four sub-blocks are available.
+func (m *Meter) finishSubBlock() {
+	sum := m.subSum
+	if m.subFilled == len(m.subSums) {
+		total := sum + m.subSums[0] + m.subSums[1] + m.subSums[2]
+		m.blocks = append(m.blocks, total/float64(4*m.subBlockLen))
+		copy(m.subSums[:], m.subSums[1:])
+		m.subSums[2] = sum
+	} else {
+		m.subSums[m.subFilled] = sum
+		m.subFilled++
+	}
+	m.subSum = 0
+	m.subCount = 0
+}
+
+// Result returns the measurement of everything written so far.
+// Trailing samples that don't fill a gating block are ignored, as in BS.1770.
+func (m *Meter) Result() Measurement {
+	blocks := make([]float64, len(m.blocks))
+	copy(blocks, m.blocks)
+	return Measurement{
+		Loudness: integratedLoudness(blocks),
+		Peak:     m.peak.value(),
+		blocks:   blocks,
+	}
+}
+
+// Combine pools the gating blocks of several measurements, giving the
+// integrated loudness and peak of the tracks played back to back.
+func Combine(ms ...Measurement) Measurement {
+	var out Measurement
+	for _, m := range ms {
+		out.blocks = append(out.blocks, m.blocks...)
+		out.Peak = max(out.Peak, m.Peak)
+	}
+	out.Loudness = integratedLoudness(out.blocks)
+	return out
+}
+
+// integratedLoudness applies the absolute and relative gates to block energies.
+func integratedLoudness(blocks []float64) float64 {
+	absThreshold := energy(absoluteGate)
+
+	var sum float64
+	var n int
+	for _, b := range blocks {
+		if b > absThreshold {
+			sum += b
+			n++
+		}
+	}
+	if n == 0 {
+		return math.Inf(-1)
+	}
+
+	relThreshold := energy(lufs(sum/float64(n)) + relativeGate)
+	sum, n = 0, 0
+	for _, b := range blocks {
+		if b > absThreshold && b > relThreshold {
+			sum += b
+			n++
+		}
+	}
+	if n == 0 {
+		return math.Inf(-1)
+	}
+	return lufs(sum / float64(n))
+}
+
+// lufs converts a mean square energy to loudness.
+func lufs(e float64) float64 {
+	return -0.691 + 10*math.Log10(e)
+}
+
+// energy converts a loudness to mean square energy.
+func energy(l float64) float64 {
+	return math.Pow(10, (l+0.691)/10)
+}
@@ -0,0 +1,160 @@
+package loudness
+
+import (
+	"math"
+	"testing"
+)
+
+// sine generates a stereo sine wave with the given peak amplitude.
+func sine(sampleRate int, freq, amplitude, phase float64, d float64) [][2]float64 {
+	n := int(float64(sampleRate) * d)
+	out := make([][2]float64, n)
+	for i := range out {
+		v := amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate)+phase)
+		out[i] = [2]float64{v, v}
+	}
+	return out
+}
+
+func measure(sampleRate, channels int, samples [][2]float64) Measurement {
+	m := NewMeter(sampleRate, channels)
+	// Feed in odd-sized chunks to exercise sub-block boundaries
+	for len(samples) > 0 {
+		n := min(len(samples), 1000)
+		m.Write(samples[:n])
+		samples = samples[n:]
+	}
+	return m.Result()
+}
+
+func dbfs(db float64) float64 {
+	return math.Pow(10, db/20)
+}
+
+// EBU Tech 3341 test case 1: stereo 1 kHz sine at -23 dBFS reads -23 LUFS.
+func TestMeter_ReferenceSine(t *testing.T) {
+	for _, rate := range []int{44100, 48000, 96000} {
+		got := measure(rate, 2, sine(rate, 997, dbfs(-23), 0, 20))
+		if math.Abs(got.Loudness-(-23)) > 0.1 {
+			t.Errorf("%d Hz: loudness = %.2f LUFS, want -23.0", rate, got.Loudness)
+		}
+	}
+}
+
+// EBU Tech 3341 test case 2: -33 dBFS reads -33 LUFS.
+func TestMeter_QuieterSine(t *testing.T) {
+	got := measure(48000, 2, sine(48000, 997, dbfs(-33), 0, 20))
+	if math.Abs(got.Loudness-(-33)) > 0.1 {
+		t.Errorf("loudness = %.2f LUFS, want -33.0", got.Loudness)
+	}
+}
+
+func TestMeter_MonoCountsOneChannel(t *testing.T) {
+	stereo := measure(48000, 2, sine(48000, 997, dbfs(-23), 0, 10))
+	mono := measure(48000, 1, sine(48000, 997, dbfs(-23), 0, 10))
+	if diff := stereo.Loudness - mono.Loudness; math.Abs(diff-3.01) > 0.05 {
+		t.Errorf("stereo - mono = %.2f LU, want 3.01", diff)
+	}
+}
+
+func TestMeter_GatingIgnoresSilence(t *testing.T) {
+	signal := sine(48000, 997, dbfs(-23), 0, 10)
+	signal = append(signal, make([][2]float64, 48000*10)...)
+
+	got := measure(48000, 2, signal)
+	if math.Abs(got.Loudness-(-23)) > 0.1 {
+		t.Errorf("loudness = %.2f LUFS, want -23.0 (silence must be gated)", got.Loudness)
+	}
+}
+
+// EBU Tech 3341 test case 3 style: a quiet passage more than 10 LU below
+// the program is removed by the relative gate.
+func TestMeter_RelativeGate(t *testing.T) {
+	signal := sine(48000, 997, dbfs(-36), 0, 10)
+	signal = append(signal, sine(48000, 997, dbfs(-23), 0, 60)...)
+	signal = append(signal, sine(48000, 997, dbfs(-36), 0, 10)...)
+
+	got := measure(48000, 2, signal)
+	if math.Abs(got.Loudness-(-23)) > 0.1 {
+		t.Errorf("loudness = %.2f LUFS, want -23.0", got.Loudness)
+	}
+}
+
+func TestMeter_Silence(t *testing.T) {
+	got := measure(44100, 2, make([][2]float64, 44100*5))
+	if !got.IsSilent() {
+		t.Errorf("loudness = %v, want -Inf", got.Loudness)
+	}
+	if got.Peak != 0 {
+		t.Errorf("peak = %v, want 0", got.Peak)
+	}
+}
+
+func TestMeter_TooShort(t *testing.T) {
+	// Less than one 400 ms gating block
+	got := measure(44100, 2, sine(44100, 997, 0.5, 0, 0.3))
+	if !got.IsSilent() {
+		t.Errorf("loudness = %v, want -Inf for a signal shorter than a block", got.Loudness)
+	}
+}
+
+func TestMeter_TruePeak(t *testing.T) {
+	// A sine at a quarter of the sample rate, shifted by 45 degrees, has
+	// every sample at 0.707 * amplitude while the waveform reaches amplitude.
+	samples := sine(48000, 12000, 0.9, math.Pi/4, 1)
+
+	var samplePeak float64
+	for _, s := range samples {
+		samplePeak = max(samplePeak, math.Abs(s[0]))
+	}
+	if math.Abs(samplePeak-0.9/math.Sqrt2) > 1e-6 {
+		t.Fatalf("sample peak = %v, test signal is wrong", samplePeak)
+	}
+
+	got := measure(48000, 2, samples)
+	if math.Abs(got.Peak-0.9) > 0.9*0.02 {
+		t.Errorf("true peak = %.4f, want 0.9 (sample peak %.4f)", got.Peak, samplePeak)
+	}
+}
+
+func TestMeter_PeakAtHighRate(t *testing.T) {
+	// No oversampling at 192 kHz: the peak is the sample peak
+	got := measure(192000, 2, sine(192000, 1000, 0.5, 0, 1))
+	if math.Abs(got.Peak-0.5) > 1e-3 {
+		t.Errorf("peak = %v, want 0.5", got.Peak)
+	}
+}
+
+func TestCombine(t *testing.T) {
+	loud := measure(48000, 2, sine(48000, 997, dbfs(-20), 0, 10))
+	quiet := measure(48000, 2, sine(48000, 997, dbfs(-26), 0, 10))
+
+	album := Combine(loud, quiet)
+
+	// Equal durations: the album energy is the mean of both energies
+	want := lufs((energy(loud.Loudness) + energy(quiet.Loudness)) / 2)
+	if math.Abs(album.Loudness-want) > 0.01 {
+		t.Errorf("album loudness = %.2f, want %.2f", album.Loudness, want)
+	}
+	if album.Peak != loud.Peak {
+		t.Errorf("album peak = %v, want %v", album.Peak, loud.Peak)
+	}
+}
+
+func TestReplayGain(t *testing.T) {
+	track := Measurement{Loudness: -12, Peak: 0.95}
+	album := Measurement{Loudness: -10, Peak: 1.02}
+
+	rg := ReplayGain(track, album)
+	if !rg.HasTrackGain || rg.TrackGain != -6 || rg.TrackPeak != 0.95 {
+		t.Errorf("track = %v dB / %v (has=%v), want -6 dB / 0.95", rg.TrackGain, rg.TrackPeak, rg.HasTrackGain)
+	}
+	if !rg.HasAlbumGain || rg.AlbumGain != -8 || rg.AlbumPeak != 1.02 {
+		t.Errorf("album = %v dB / %v (has=%v), want -8 dB / 1.02", rg.AlbumGain, rg.AlbumPeak, rg.HasAlbumGain)
+	}
+
+	silent := ReplayGain(Measurement{Loudness: math.Inf(-1)}, album)
+	if silent.HasTrackGain {
+		t.Error("silent track should have no track gain")
+	}
+}
@@ -0,0 +1,133 @@
+package mpd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ACK error codes.
+const (
+	ackNotList    = 1
+	ackArg        = 2
+	ackPassword   = 3
+	ackPermission = 4
+	ackUnknown    = 5
+	ackNoExist    = 50
+	ackSystem     = 52
+	ackExist      = 56
+)
+
+// ackError is a command failure with its ACK code.
+type ackError struct {
+	code int
+	msg  string
+}
+
+func (e *ackError) Error() string {
+	return e.msg
+}
+
+func errArg(format string, args ...any) error {
+	return &ackError{ackArg, fmt.Sprintf(format, args...)}
+}
+
+func errNoExist(format string, args ...any) error {
+	return &ackError{ackNoExist, fmt.Sprintf(format, args...)}
+}
+
+// splitArgs splits a command line into words. Words holding spaces are
+// double quoted, with quotes and backslashes escaped by a backslash.
+func splitArgs(line string) ([]string, error) {
+	var args []string
+	for i := 0; i < len(line); {
+		switch {
+		case line[i] == ' ' || line[i] == '\t':
+			i++
+		case line[i] == '"':
+			var b strings.Builder
+			i++
+			for ; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) {
+					i++
+				}
+				b.WriteByte(line[i])
+			}
+			if i == len(line) {
+				return nil, errArg("Missing closing '\"'")
+			}
+			args = append(args, b.String())
+			i++
+		default:
+			start := i
+			for i < len(line) && line[i] != ' ' && line[i] != '\t' {
+				i++
+			}
+			args = append(args, line[start:i])
+		}
+	}
+	return args, nil
+}
+
+// quote quotes an argument for splitArgs.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func parseInt(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errArg("Integer expected: %s", s)
+	}
+	return n, nil
+}
+
+func parseFloat(s string) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, errArg("Float expected: %s", s)
+	}
+	return f, nil
+}
+
+func parseBool(s string) (bool, error) {
+	switch s {
+	case "0":
+		return false, nil
+	case "1":
+		return true, nil
+	}
+	return false, errArg("Boolean (0/1) expected: %s", s)
+}
+
+// parseRange parses a position or a START:END range of positions within
+// length items, END being excluded and defaulting to length.
+func parseRange(s string, length int) (start, end int, err error) {
+	from, to, isRange := strings.Cut(s, ":")
+	if start, err = parseInt(from); err != nil {
+		return 0, 0, err
+	}
+	end = start + 1
+	if isRange {
+		end = length
+		if to != "" {
+			if end, err = parseInt(to); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+	if start < 0 || end < start {
+		return 0, 0, errArg("Bad range: %s", s)
+	}
+	if start > length || end > length || (!isRange && start == length) {
+		return 0, 0, errArg("Bad song index")
+	}
+	return start, end, nil
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
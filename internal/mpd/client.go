@@ -0,0 +1,274 @@
+package mpd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// maxLineSize bounds a command line.
+const maxLineSize = 1 << 20
+
+// subsystem is a set of idle events.
+type subsystem uint
+
+const (
+	subDatabase subsystem = 1 << iota
+	subStoredPlaylist
+	subPlaylist
+	subPlayer
+	subMixer
+	subOptions
+	subUpdate
+	subOutput
+)
+
+// subsystemNames are the names of the idle events, in bit order.
+var subsystemNames = []string{
+	"database", "stored_playlist", "playlist", "player", "mixer", "options", "update", "output",
+}
+
+// errClose is returned by the close command.
+var errClose = errors.New("close")
+
+// client is a connected MPD client.
+type client struct {
+	s      *Server
+	conn   net.Conn
+	w      *bufio.Writer
+	authed bool
+	roots  []root // Library sources, read once per command
+	done   chan struct{}
+
+	mu      sync.Mutex
+	pending subsystem     // Events raised since the last idle returned
+	wake    chan struct{} // Signaled when events are raised
+}
+
+func newClient(s *Server, conn net.Conn) *client {
+	return &client{
+		s:      s,
+		conn:   conn,
+		w:      bufio.NewWriter(conn),
+		authed: s.password == "",
+		done:   make(chan struct{}),
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// raise records idle events and wakes the client if it is idle.
+func (c *client) raise(events subsystem) {
+	c.mu.Lock()
+	c.pending |= events
+	c.mu.Unlock()
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// take returns and clears the pending events of mask.
+func (c *client) take(mask subsystem) subsystem {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	events := c.pending & mask
+	c.pending &^= events
+	return events
+}
+
+// serve answers the commands of the client until it disconnects.
+func (c *client) serve() {
+	defer c.conn.Close()
+	defer close(c.done)
+
+	lines := make(chan string)
+	go c.read(lines)
+
+	fmt.Fprintf(c.w, "OK MPD %s\n", protocolVersion)
+	for {
+		if c.w.Flush() != nil {
+			return
+		}
+		line, ok := <-lines
+		if !ok {
+			return
+		}
+		var err error
+		switch name, _, _ := strings.Cut(line, " "); name {
+		case "command_list_begin", "command_list_ok_begin":
+			err = c.runList(lines, name == "command_list_ok_begin")
+		case "idle":
+			err = c.idle(line, lines)
+		case "noidle":
+			// Only meaningful while idle
+		default:
+			var ok bool
+			if ok, err = c.exec(line, 0); ok {
+				fmt.Fprintln(c.w, "OK")
+			}
+		}
+		if err != nil {
+			c.w.Flush()
+			return
+		}
+	}
+}
+
+// read sends the lines of the client until it disconnects.
+func (c *client) read(lines chan<- string) {
+	defer close(lines)
+	scanner := bufio.NewScanner(c.conn)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineSize)
+	for scanner.Scan() {
+		select {
+		case lines <- scanner.Text():
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// runList runs the commands sent up to command_list_end, stopping at the
+// first failing one. With ok, each successful command is followed by a
+// list_OK line.
+func (c *client) runList(lines <-chan string, ok bool) error {
+	var list []string
+	for {
+		line, open := <-lines
+		if !open {
+			return errClose
+		}
+		if line == "command_list_end" {
+			break
+		}
+		list = append(list, line)
+	}
+	for i, line := range list {
+		done, err := c.exec(line, i)
+		if err != nil || !done {
+			return err
+		}
+		if ok {
+			fmt.Fprintln(c.w, "list_OK")
+		}
+	}
+	fmt.Fprintln(c.w, "OK")
+	return nil
+}
+
+// exec runs a command, writing an ACK line if it fails. It returns whether
+// it succeeded, and an error when the connection must be closed.
+func (c *client) exec(line string, listNum int) (bool, error) {
+	args, err := splitArgs(line)
+	if err != nil {
+		c.ack(listNum, "", err)
+		return false, nil
+	}
+	if len(args) == 0 {
+		c.ack(listNum, "", &ackError{ackUnknown, "No command given"})
+		return false, nil
+	}
+	name := args[0]
+	cmd, ok := commands[name]
+	if !ok {
+		c.ack(listNum, "", &ackError{ackUnknown, fmt.Sprintf("unknown command %q", name)})
+		return false, nil
+	}
+	if !c.authed && !cmd.public {
+		c.ack(listNum, name, &ackError{ackPermission, fmt.Sprintf("you don't have permission for %q", name)})
+		return false, nil
+	}
+	if n := len(args) - 1; n < cmd.minArgs || (cmd.maxArgs >= 0 && n > cmd.maxArgs) {
+		c.ack(listNum, name, &ackError{ackArg, fmt.Sprintf("wrong number of arguments for %q", name)})
+		return false, nil
+	}
+
+	c.roots = nil
+	if err := cmd.run(c, args[1:]); err != nil {
+		if errors.Is(err, errClose) {
+			return false, err
+		}
+		c.ack(listNum, name, err)
+		return false, nil
+	}
+	return true, nil
+}
+
+// ack writes the failure of a command.
+func (c *client) ack(listNum int, name string, err error) {
+	code := ackSystem
+	var ack *ackError
+	if errors.As(err, &ack) {
+		code = ack.code
+	}
+	msg := strings.ReplaceAll(err.Error(), "\n", " ")
+	fmt.Fprintf(c.w, "ACK [%d@%d] {%s} %s\n", code, listNum, name, msg)
+}
+
+// idle waits for events of the subsystems named in the command line, or
+// any event without names, until the client sends noidle.
+func (c *client) idle(line string, lines <-chan string) error {
+	if !c.authed {
+		c.ack(0, "idle", &ackError{ackPermission, `you don't have permission for "idle"`})
+		return nil
+	}
+	args, err := splitArgs(line)
+	if err != nil {
+		c.ack(0, "idle", err)
+		return nil
+	}
+	var mask subsystem
+	for _, arg := range args[1:] {
+		i := slices.Index(subsystemNames, arg)
+		if i < 0 {
+			c.ack(0, "idle", &ackError{ackArg, fmt.Sprintf("Unrecognized idle event: %s", arg)})
+			return nil
+		}
+		mask |= 1 << i
+	}
+	if mask == 0 {
+		mask = ^subsystem(0)
+	}
+
+	for {
+		if events := c.take(mask); events != 0 {
+			c.writeEvents(events)
+			return nil
+		}
+		if c.w.Flush() != nil {
+			return errClose
+		}
+		select {
+		case <-c.wake:
+		case line, ok := <-lines:
+			if !ok {
+				return errClose
+			}
+			if line != "noidle" {
+				// Only noidle is allowed while idle
+				return errClose
+			}
+			c.writeEvents(c.take(mask))
+			return nil
+		}
+	}
+}
+
+// writeEvents writes the changed lines of events, ending the idle command.
+func (c *client) writeEvents(events subsystem) {
+	for i, name := range subsystemNames {
+		if events&(1<<i) != 0 {
+			c.field("changed", name)
+		}
+	}
+	fmt.Fprintln(c.w, "OK")
+}
+
+// field writes a key: value line.
+func (c *client) field(key string, value any) {
+	fmt.Fprintf(c.w, "%s: %v\n", key, value)
+}
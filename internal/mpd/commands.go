@@ -0,0 +1,485 @@
+package mpd
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/llehouerou/waves/internal/playback"
+)
+
+// command is a protocol command.
+type command struct {
+	run     func(c *client, args []string) error
+	minArgs int
+	maxArgs int  // -1 for any number
+	public  bool // Allowed before the password is given
+}
+
+// commands are the supported commands by name. idle, noidle and command
+// lists are handled by the client loop.
+var commands map[string]command
+
+// init fills commands, which the commands command lists.
+func init() {
+	commands = map[string]command{
+		// Connection
+		"ping":        {run: cmdNothing, public: true},
+		"close":       {run: cmdClose, public: true},
+		"password":    {run: cmdPassword, minArgs: 1, maxArgs: 1, public: true},
+		"commands":    {run: cmdCommands, public: true},
+		"notcommands": {run: cmdNotCommands, public: true},
+		"tagtypes":    {run: cmdTagTypes, maxArgs: -1},
+		"urlhandlers": {run: cmdNothing},
+		"decoders":    {run: cmdNothing},
+		"outputs":     {run: cmdOutputs},
+
+		// Status
+		"status":             {run: cmdStatus},
+		"currentsong":        {run: cmdCurrentSong},
+		"stats":              {run: cmdStats},
+		"replay_gain_status": {run: cmdReplayGainStatus},
+
+		// Playback
+		"play":     {run: cmdPlay, maxArgs: 1},
+		"playid":   {run: cmdPlayID, maxArgs: 1},
+		"pause":    {run: cmdPause, maxArgs: 1},
+		"stop":     {run: cmdStop},
+		"next":     {run: cmdNext},
+		"previous": {run: cmdPrevious},
+		"seek":     {run: cmdSeek, minArgs: 2, maxArgs: 2},
+		"seekid":   {run: cmdSeekID, minArgs: 2, maxArgs: 2},
+		"seekcur":  {run: cmdSeekCur, minArgs: 1, maxArgs: 1},
+		"setvol":   {run: cmdSetVol, minArgs: 1, maxArgs: 1},
+		"volume":   {run: cmdVolume, minArgs: 1, maxArgs: 1},
+		"getvol":   {run: cmdGetVol},
+		"repeat":   {run: cmdRepeat, minArgs: 1, maxArgs: 1},
+		"single":   {run: cmdSingle, minArgs: 1, maxArgs: 1},
+		"random":   {run: cmdRandom, minArgs: 1, maxArgs: 1},
+		"consume":  {run: cmdConsume, minArgs: 1, maxArgs: 1},
+
+		// Queue
+		"playlistinfo":   {run: cmdPlaylistInfo, maxArgs: 1},
+		"playlistid":     {run: cmdPlaylistID, maxArgs: 1},
+		"plchanges":      {run: cmdPlChanges, minArgs: 1, maxArgs: 2},
+		"plchangesposid": {run: cmdPlChangesPosID, minArgs: 1, maxArgs: 2},
+		"add":            {run: cmdAdd, minArgs: 1, maxArgs: 2},
+		"addid":          {run: cmdAddID, minArgs: 1, maxArgs: 2},
+		"delete":         {run: cmdDelete, minArgs: 1, maxArgs: 1},
+		"deleteid":       {run: cmdDeleteID, minArgs: 1, maxArgs: 1},
+		"move":           {run: cmdMove, minArgs: 2, maxArgs: 2},
+		"moveid":         {run: cmdMoveID, minArgs: 2, maxArgs: 2},
+		"clear":          {run: cmdClear},
+
+		// Library
+		"list":      {run: cmdList, minArgs: 1, maxArgs: -1},
+		"find":      {run: cmdFind, minArgs: 1, maxArgs: -1},
+		"search":    {run: cmdSearch, minArgs: 1, maxArgs: -1},
+		"findadd":   {run: cmdFindAdd, minArgs: 1, maxArgs: -1},
+		"searchadd": {run: cmdSearchAdd, minArgs: 1, maxArgs: -1},
+		"count":     {run: cmdCount, minArgs: 1, maxArgs: -1},
+		"lsinfo":    {run: cmdLsInfo, maxArgs: 1},
+
+		// Stored playlists
+		"listplaylists":    {run: cmdListPlaylists},
+		"listplaylist":     {run: cmdListPlaylist, minArgs: 1, maxArgs: 1},
+		"listplaylistinfo": {run: cmdListPlaylistInfo, minArgs: 1, maxArgs: 1},
+		"load":             {run: cmdLoad, minArgs: 1, maxArgs: 2},
+		"playlistadd":      {run: cmdPlaylistAdd, minArgs: 2, maxArgs: 2},
+		"playlistdelete":   {run: cmdPlaylistDelete, minArgs: 2, maxArgs: 2},
+		"playlistmove":     {run: cmdPlaylistMove, minArgs: 3, maxArgs: 3},
+		"playlistclear":    {run: cmdPlaylistClear, minArgs: 1, maxArgs: 1},
+		"rm":               {run: cmdRm, minArgs: 1, maxArgs: 1},
+		"rename":           {run: cmdRename, minArgs: 2, maxArgs: 2},
+		"save":             {run: cmdSave, minArgs: 1, maxArgs: 1},
+	}
+}
+
+func cmdNothing(*client, []string) error {
+	return nil
+}
+
+func cmdClose(*client, []string) error {
+	return errClose
+}
+
+func cmdPassword(c *client, args []string) error {
+	if c.s.password != "" && subtle.ConstantTimeCompare([]byte(args[0]), []byte(c.s.password)) != 1 {
+		return &ackError{ackPassword, "incorrect password"}
+	}
+	c.authed = true
+	return nil
+}
+
+// commandNames returns the names of the commands the client may run or
+// not, sorted.
+func (c *client) commandNames(allowed bool) []string {
+	var names []string
+	for name, cmd := range commands {
+		if (c.authed || cmd.public) == allowed {
+			names = append(names, name)
+		}
+	}
+	if allowed {
+		names = append(names, "idle", "noidle", "command_list_begin", "command_list_ok_begin", "command_list_end")
+	}
+	slices.Sort(names)
+	return names
+}
+
+func cmdCommands(c *client, _ []string) error {
+	for _, name := range c.commandNames(true) {
+		c.field("command", name)
+	}
+	return nil
+}
+
+func cmdNotCommands(c *client, _ []string) error {
+	for _, name := range c.commandNames(false) {
+		c.field("command", name)
+	}
+	return nil
+}
+
+// cmdTagTypes lists the tags of songs. Choosing the tags sent is accepted
+// but ignored.
+func cmdTagTypes(c *client, args []string) error {
+	if len(args) > 0 {
+		return nil
+	}
+	for _, name := range responseTags {
+		c.field("tagtype", name)
+	}
+	return nil
+}
+
+func cmdOutputs(c *client, _ []string) error {
+	c.field("outputid", 0)
+	c.field("outputname", "waves")
+	c.field("plugin", "waves")
+	c.field("outputenabled", 1)
+	return nil
+}
+
+func cmdStatus(c *client, _ []string) error {
+	svc := c.s.svc.Service()
+	tracks, ids, version := c.queue()
+	mode := svc.RepeatMode()
+
+	c.field("volume", volume(svc))
+	c.field("repeat", boolInt(mode == playback.RepeatAll || mode == playback.RepeatOne))
+	c.field("random", boolInt(svc.Shuffle()))
+	c.field("single", boolInt(mode == playback.RepeatOne))
+	c.field("consume", 0)
+	c.field("playlist", version)
+	c.field("playlistlength", len(tracks))
+	c.field("state", stateName(svc.State()))
+
+	if i := svc.QueueCurrentIndex(); i >= 0 && i < len(tracks) {
+		c.field("song", i)
+		c.field("songid", ids[i])
+		if i+1 < len(tracks) && !svc.Shuffle() {
+			c.field("nextsong", i+1)
+			c.field("nextsongid", ids[i+1])
+		}
+	}
+	if !svc.IsStopped() {
+		elapsed, duration := svc.Position(), svc.Duration()
+		c.field("time", fmt.Sprintf("%d:%d",
+			int(elapsed.Round(time.Second).Seconds()), int(duration.Round(time.Second).Seconds())))
+		c.field("elapsed", formatSeconds(elapsed))
+		c.field("duration", formatSeconds(duration))
+	}
+	return nil
+}
+
+func stateName(s playback.State) string {
+	switch s {
+	case playback.StatePlaying:
+		return "play"
+	case playback.StatePaused:
+		return "pause"
+	case playback.StateStopped:
+	}
+	return "stop"
+}
+
+// volume returns the volume in percent, 0 when muted as MPD has no mute.
+func volume(svc playback.Service) int {
+	p := svc.Player()
+	if p.Muted() {
+		return 0
+	}
+	return int(math.Round(p.Volume() * 100))
+}
+
+func cmdCurrentSong(c *client, _ []string) error {
+	svc := c.s.svc.Service()
+	tracks, ids, _ := c.queue()
+	if i := svc.QueueCurrentIndex(); i >= 0 && i < len(tracks) {
+		c.writeQueueSong(tracks[i], i, ids[i])
+	}
+	return nil
+}
+
+func cmdStats(c *client, _ []string) error {
+	lib := c.s.lib
+	artists, err := lib.ArtistCount()
+	if err != nil {
+		return err
+	}
+	albums, err := lib.AlbumCount()
+	if err != nil {
+		return err
+	}
+	songs, err := lib.TrackCount()
+	if err != nil {
+		return err
+	}
+	c.field("artists", artists)
+	c.field("albums", albums)
+	c.field("songs", songs)
+	c.field("uptime", int(time.Since(c.s.started).Seconds()))
+	c.field("playtime", 0)
+	c.field("db_playtime", 0)
+	return nil
+}
+
+func cmdReplayGainStatus(c *client, _ []string) error {
+	// Mode names are those of MPD
+	c.field("replay_gain_mode", c.s.svc.Service().Player().ReplayGain().Mode)
+	return nil
+}
+
+// play starts playing the queue from the current track, or resumes it.
+func play(svc playback.Service) error {
+	switch svc.State() {
+	case playback.StatePlaying:
+		return nil
+	case playback.StatePaused:
+		return svc.Toggle()
+	case playback.StateStopped:
+	}
+	if svc.QueueCurrentIndex() < 0 && !svc.QueueIsEmpty() {
+		svc.QueueMoveTo(0)
+	}
+	return svc.Play()
+}
+
+// playAt plays the queue track at pos.
+func playAt(svc playback.Service, pos int) error {
+	if err := svc.JumpTo(pos); err != nil {
+		return errArg("Bad song index")
+	}
+	if svc.IsStopped() {
+		return svc.Play()
+	}
+	if svc.IsPaused() {
+		return svc.Toggle()
+	}
+	return nil
+}
+
+func cmdPlay(c *client, args []string) error {
+	svc := c.s.svc.Service()
+	if len(args) == 0 {
+		return play(svc)
+	}
+	pos, err := parseInt(args[0])
+	if err != nil {
+		return err
+	}
+	if pos < 0 {
+		return play(svc)
+	}
+	return playAt(svc, pos)
+}
+
+func cmdPlayID(c *client, args []string) error {
+	svc := c.s.svc.Service()
+	if len(args) == 0 {
+		return play(svc)
+	}
+	pos, err := c.position(args[0])
+	if err != nil {
+		return err
+	}
+	return playAt(svc, pos)
+}
+
+func cmdPause(c *client, args []string) error {
+	svc := c.s.svc.Service()
+	if len(args) == 0 {
+		if svc.IsStopped() {
+			return nil
+		}
+		return svc.Toggle()
+	}
+	pause, err := parseBool(args[0])
+	if err != nil {
+		return err
+	}
+	if pause {
+		return svc.Pause()
+	}
+	if svc.IsPaused() {
+		return svc.Toggle()
+	}
+	return nil
+}
+
+func cmdStop(c *client, _ []string) error {
+	return c.s.svc.Service().Stop()
+}
+
+func cmdNext(c *client, _ []string) error {
+	return c.s.svc.Service().Next()
+}
+
+func cmdPrevious(c *client, _ []string) error {
+	return c.s.svc.Service().Previous()
+}
+
+// seekTo seeks to a position in seconds in the queue track at pos, which
+// starts playing if it isn't the current one.
+func seekTo(svc playback.Service, pos int, arg string) error {
+	secs, err := parseFloat(arg)
+	if err != nil {
+		return err
+	}
+	if pos != svc.QueueCurrentIndex() || svc.IsStopped() {
+		if err := playAt(svc, pos); err != nil {
+			return err
+		}
+	}
+	return svc.SeekTo(time.Duration(secs * float64(time.Second)))
+}
+
+func cmdSeek(c *client, args []string) error {
+	pos, err := parseInt(args[0])
+	if err != nil {
+		return err
+	}
+	return seekTo(c.s.svc.Service(), pos, args[1])
+}
+
+func cmdSeekID(c *client, args []string) error {
+	pos, err := c.position(args[0])
+	if err != nil {
+		return err
+	}
+	return seekTo(c.s.svc.Service(), pos, args[1])
+}
+
+// cmdSeekCur seeks in the current track, by an offset when signed.
+func cmdSeekCur(c *client, args []string) error {
+	svc := c.s.svc.Service()
+	if svc.IsStopped() {
+		return &ackError{ackSystem, "Not playing"}
+	}
+	secs, err := parseFloat(args[0])
+	if err != nil {
+		return err
+	}
+	d := time.Duration(secs * float64(time.Second))
+	if strings.HasPrefix(args[0], "+") || strings.HasPrefix(args[0], "-") {
+		return svc.Seek(d)
+	}
+	return svc.SeekTo(d)
+}
+
+// setVolume sets the volume in percent, unmuting the player like the
+// volume keys.
+func (c *client) setVolume(percent int) error {
+	svc := c.s.svc.Service()
+	p := svc.Player()
+	svc.SetMuted(false)
+	svc.SetVolume(float64(max(0, min(100, percent))) / 100)
+	if c.s.volumes == nil {
+		return nil
+	}
+	return c.s.volumes.SaveVolume(p.Volume(), p.Muted())
+}
+
+func cmdSetVol(c *client, args []string) error {
+	percent, err := parseInt(args[0])
+	if err != nil {
+		return err
+	}
+	if percent < 0 || percent > 100 {
+		return errArg("Invalid volume value")
+	}
+	return c.setVolume(percent)
+}
+
+func cmdVolume(c *client, args []string) error {
+	delta, err := parseInt(args[0])
+	if err != nil {
+		return err
+	}
+	return c.setVolume(volume(c.s.svc.Service()) + delta)
+}
+
+func cmdGetVol(c *client, _ []string) error {
+	c.field("volume", volume(c.s.svc.Service()))
+	return nil
+}
+
+// cmdRepeat switches repeat. Single mode is repeating one track, so it
+// implies repeat.
+func cmdRepeat(c *client, args []string) error {
+	on, err := parseBool(args[0])
+	if err != nil {
+		return err
+	}
+	svc := c.s.svc.Service()
+	switch {
+	case !on:
+		svc.SetRepeatMode(playback.RepeatOff)
+	case svc.RepeatMode() != playback.RepeatOne:
+		svc.SetRepeatMode(playback.RepeatAll)
+	}
+	return nil
+}
+
+func cmdSingle(c *client, args []string) error {
+	if args[0] == "oneshot" {
+		return errArg("Single oneshot is not supported")
+	}
+	on, err := parseBool(args[0])
+	if err != nil {
+		return err
+	}
+	svc := c.s.svc.Service()
+	switch {
+	case on:
+		svc.SetRepeatMode(playback.RepeatOne)
+	case svc.RepeatMode() == playback.RepeatOne:
+		svc.SetRepeatMode(playback.RepeatAll)
+	}
+	return nil
+}
+
+func cmdRandom(c *client, args []string) error {
+	on, err := parseBool(args[0])
+	if err != nil {
+		return err
+	}
+	c.s.svc.Service().SetShuffle(on)
+	return nil
+}
+
+// cmdConsume only accepts turning consume off, which waves doesn't have.
+func cmdConsume(_ *client, args []string) error {
+	on, err := parseBool(args[0])
+	if err != nil {
+		return err
+	}
+	if on {
+		return errArg("Consume mode is not supported")
+	}
+	return nil
+}
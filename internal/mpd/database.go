@@ -0,0 +1,428 @@
+package mpd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/llehouerou/waves/internal/library"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/playlist"
+	"github.com/llehouerou/waves/internal/tags"
+)
+
+// tagsByName maps the lower cased MPD names of tags to library tags.
+var tagsByName = map[string]library.Tag{
+	"artist":      library.TagArtist,
+	"albumartist": library.TagAlbumArtist,
+	"album":       library.TagAlbum,
+	"title":       library.TagTitle,
+	"genre":       library.TagGenre,
+	"date":        library.TagYear,
+	"label":       library.TagLabel,
+	"track":       library.TagTrackNumber,
+	"disc":        library.TagDiscNumber,
+	"file":        library.TagPath,
+	"any":         library.TagAny,
+}
+
+// tagKeys are the keys of library tags in responses.
+var tagKeys = map[library.Tag]string{
+	library.TagArtist:      "Artist",
+	library.TagAlbumArtist: "AlbumArtist",
+	library.TagAlbum:       "Album",
+	library.TagTitle:       "Title",
+	library.TagGenre:       "Genre",
+	library.TagYear:        "Date",
+	library.TagLabel:       "Label",
+	library.TagTrackNumber: "Track",
+	library.TagDiscNumber:  "Disc",
+	library.TagPath:        "file",
+}
+
+// responseTags are the tags songs are described with.
+var responseTags = []string{"Artist", "AlbumArtist", "Album", "Title", "Track", "Disc", "Genre", "Date", "Label"}
+
+func lookupTag(name string) (library.Tag, error) {
+	tag, ok := tagsByName[strings.ToLower(name)]
+	if !ok {
+		return "", errArg("Unknown tag type: %s", name)
+	}
+	return tag, nil
+}
+
+// parseFilters parses the filters starting args: tag and value pairs, or
+// expressions like "(artist == 'Blur')". Pairs match values holding theirs
+// with contains, equal ones otherwise. The arguments following the filters
+// are returned.
+func (c *client) parseFilters(args []string, contains bool) ([]library.TagFilter, []string, error) {
+	var filters []library.TagFilter
+	for len(args) > 0 {
+		switch args[0] {
+		case "sort", "window", "group":
+			return filters, args, nil
+		}
+		if strings.HasPrefix(args[0], "(") {
+			exprFilters, err := parseExpression(args[0])
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, f := range exprFilters {
+				if f, err = c.resolveFilter(f); err != nil {
+					return nil, nil, err
+				}
+				filters = append(filters, f)
+			}
+			args = args[1:]
+			continue
+		}
+		if len(args) < 2 {
+			return nil, nil, errArg("Incorrect number of filter arguments")
+		}
+		tag, err := lookupTag(args[0])
+		if err != nil {
+			return nil, nil, err
+		}
+		f, err := c.resolveFilter(library.TagFilter{Tag: tag, Value: args[1], Contains: contains})
+		if err != nil {
+			return nil, nil, err
+		}
+		filters = append(filters, f)
+		args = args[2:]
+	}
+	return filters, nil, nil
+}
+
+// resolveFilter turns the URI of an exact file filter into its path.
+func (c *client) resolveFilter(f library.TagFilter) (library.TagFilter, error) {
+	if f.Tag == library.TagPath && !f.Contains {
+		path, ok := c.path(f.Value)
+		if !ok {
+			return f, errNoExist("No such song")
+		}
+		f.Value = path
+	}
+	return f, nil
+}
+
+// parseExpression parses a filter expression: (TAG == 'VALUE'),
+// (TAG contains 'VALUE'), or expressions joined by AND in parentheses.
+func parseExpression(s string) ([]library.TagFilter, error) {
+	p := &exprParser{s: s}
+	filters, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.i < len(p.s) {
+		return nil, errArg("Unparsed garbage after expression")
+	}
+	return filters, nil
+}
+
+type exprParser struct {
+	s string
+	i int
+}
+
+func (p *exprParser) skipSpace() {
+	for p.i < len(p.s) && p.s[p.i] == ' ' {
+		p.i++
+	}
+}
+
+// next reads a word: an operator, a tag name or AND.
+func (p *exprParser) next() string {
+	p.skipSpace()
+	start := p.i
+	for p.i < len(p.s) && !strings.ContainsRune(" ()'\"", rune(p.s[p.i])) {
+		p.i++
+	}
+	return p.s[start:p.i]
+}
+
+func (p *exprParser) expect(b byte) error {
+	p.skipSpace()
+	if p.i >= len(p.s) || p.s[p.i] != b {
+		return errArg("'%c' expected", b)
+	}
+	p.i++
+	return nil
+}
+
+func (p *exprParser) expr() ([]library.TagFilter, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.i < len(p.s) && p.s[p.i] == '(' {
+		var filters []library.TagFilter
+		for {
+			sub, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			filters = append(filters, sub...)
+			if p.skipSpace(); p.i < len(p.s) && p.s[p.i] == ')' {
+				p.i++
+				return filters, nil
+			}
+			if word := p.next(); word != "AND" {
+				return nil, errArg("Only AND expressions are supported")
+			}
+		}
+	}
+
+	tag, err := lookupTag(p.next())
+	if err != nil {
+		return nil, err
+	}
+	f := library.TagFilter{Tag: tag}
+	switch op := p.next(); op {
+	case "==":
+	case "contains":
+		f.Contains = true
+	default:
+		return nil, errArg("Unsupported operator: %s", op)
+	}
+	if f.Value, err = p.quoted(); err != nil {
+		return nil, err
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	return []library.TagFilter{f}, nil
+}
+
+// quoted reads a value in single or double quotes, backslash escaping.
+func (p *exprParser) quoted() (string, error) {
+	p.skipSpace()
+	if p.i >= len(p.s) || (p.s[p.i] != '\'' && p.s[p.i] != '"') {
+		return "", errArg("Quoted value expected")
+	}
+	q := p.s[p.i]
+	var b strings.Builder
+	for p.i++; p.i < len(p.s); p.i++ {
+		switch p.s[p.i] {
+		case '\\':
+			if p.i+1 < len(p.s) {
+				p.i++
+			}
+		case q:
+			p.i++
+			return b.String(), nil
+		}
+		b.WriteByte(p.s[p.i])
+	}
+	return "", errArg("Closing quote not found")
+}
+
+// cmdList lists the values of a tag, possibly grouped by other tags, each
+// group value being written when it changes.
+func cmdList(c *client, args []string) error {
+	tag, err := lookupTag(args[0])
+	if err != nil || tag == library.TagAny {
+		return errArg("Unknown tag type: %s", args[0])
+	}
+
+	var filters []library.TagFilter
+	rest := args[1:]
+	if len(rest) == 1 && tag == library.TagAlbum {
+		// Old clients give the artist of the albums
+		filters, rest = []library.TagFilter{{Tag: library.TagArtist, Value: rest[0]}}, nil
+	} else if filters, rest, err = c.parseFilters(rest, false); err != nil {
+		return err
+	}
+	var groups []library.Tag
+	for len(rest) >= 2 && rest[0] == "group" {
+		group, err := lookupTag(rest[1])
+		if err != nil {
+			return err
+		}
+		groups = append(groups, group)
+		rest = rest[2:]
+	}
+	if len(rest) > 0 {
+		return errArg("Unexpected argument: %s", rest[0])
+	}
+
+	rows, err := c.s.lib.TagValues(append(groups, tag), filters)
+	if err != nil {
+		return err
+	}
+	var prev []string
+	for _, row := range rows {
+		changed := prev == nil
+		for i, group := range groups {
+			changed = changed || row[i] != prev[i]
+			if changed {
+				c.writeTag(group, row[i])
+			}
+		}
+		c.writeTag(tag, row[len(groups)])
+		prev = row
+	}
+	return nil
+}
+
+func (c *client) writeTag(tag library.Tag, value string) {
+	if tag == library.TagPath {
+		value = c.uri(value)
+	}
+	c.field(tagKeys[tag], value)
+}
+
+// findTracks returns the library tracks matching the filters of args,
+// within the window given after them. Sorting is ignored: tracks are in
+// album order.
+func (c *client) findTracks(args []string, contains bool) ([]library.Track, error) {
+	filters, rest, err := c.parseFilters(args, contains)
+	if err != nil {
+		return nil, err
+	}
+	start, end := 0, -1
+	for len(rest) > 0 {
+		if len(rest) < 2 || (rest[0] != "sort" && rest[0] != "window") {
+			return nil, errArg("Unexpected argument: %s", rest[0])
+		}
+		if rest[0] == "window" {
+			if start, end, err = parseWindow(rest[1]); err != nil {
+				return nil, err
+			}
+		}
+		rest = rest[2:]
+	}
+
+	tracks, err := c.s.lib.FindTracks(filters)
+	if err != nil {
+		return nil, err
+	}
+	if end < 0 || end > len(tracks) {
+		end = len(tracks)
+	}
+	if start > end {
+		return nil, nil
+	}
+	return tracks[start:end], nil
+}
+
+// parseWindow parses the START:END window of find and search, END being -1
+// when left out.
+func parseWindow(s string) (start, end int, err error) {
+	from, to, _ := strings.Cut(s, ":")
+	if start, err = parseInt(from); err != nil {
+		return 0, 0, err
+	}
+	end = -1
+	if to != "" {
+		if end, err = parseInt(to); err != nil {
+			return 0, 0, err
+		}
+	}
+	if start < 0 || (end >= 0 && end < start) {
+		return 0, 0, errArg("Bad window: %s", s)
+	}
+	return start, end, nil
+}
+
+func (c *client) find(args []string, contains bool) error {
+	tracks, err := c.findTracks(args, contains)
+	if err != nil {
+		return err
+	}
+	for _, t := range tracks {
+		c.writeSong(songOfLibrary(t))
+	}
+	return nil
+}
+
+func (c *client) findAdd(args []string, contains bool) error {
+	tracks, err := c.findTracks(args, contains)
+	if err != nil {
+		return err
+	}
+	c.s.svc.Service().AddTracks(playback.TracksFromPlaylist(playlist.FromLibraryTracks(tracks))...)
+	return nil
+}
+
+func cmdFind(c *client, args []string) error {
+	return c.find(args, false)
+}
+
+func cmdSearch(c *client, args []string) error {
+	return c.find(args, true)
+}
+
+func cmdFindAdd(c *client, args []string) error {
+	return c.findAdd(args, false)
+}
+
+func cmdSearchAdd(c *client, args []string) error {
+	return c.findAdd(args, true)
+}
+
+// cmdCount counts the matching songs. The library has no durations, so
+// their play time is unknown.
+func cmdCount(c *client, args []string) error {
+	tracks, err := c.findTracks(args, false)
+	if err != nil {
+		return err
+	}
+	c.field("songs", len(tracks))
+	c.field("playtime", 0)
+	return nil
+}
+
+// cmdLsInfo lists a folder of the library sources, or describes a song.
+func cmdLsInfo(c *client, args []string) error {
+	var uri string
+	if len(args) == 1 {
+		uri = strings.Trim(args[0], "/")
+	}
+	if uri == "" {
+		roots := c.libraryRoots()
+		if len(roots) == 1 {
+			return c.listFolder(roots[0].path)
+		}
+		for _, r := range roots {
+			c.field("directory", r.name)
+		}
+		return nil
+	}
+
+	path, info, err := c.existingPath(uri)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return c.listFolder(path)
+	}
+	c.writeSong(c.songAt(path))
+	return nil
+}
+
+// listFolder writes the folders and music files of a folder.
+func (c *client) listFolder(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return errNoExist("No such directory")
+	}
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		switch {
+		case strings.HasPrefix(e.Name(), "."):
+		case e.IsDir():
+			c.field("directory", c.uri(path))
+		case tags.IsMusicFile(path):
+			c.writeSong(c.songAt(path))
+		}
+	}
+	return nil
+}
+
+// songAt returns the song of a file, with its metadata if it is in the
+// library.
+func (c *client) songAt(path string) song {
+	if t, err := c.s.lib.TrackByPath(path); err == nil {
+		return songOfLibrary(*t)
+	}
+	return song{path: path}
+}
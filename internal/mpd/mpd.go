@@ -0,0 +1,201 @@
+// Package mpd serves a subset of the Music Player Daemon protocol, so MPD
+// clients such as mpc, ncmpcpp or phone apps can drive waves.
+//
+// Clients see the queue, the library and the stored playlists of waves:
+// library paths are shown relative to the library sources, like MPD shows
+// them relative to its music directory, and the idle command is woken by
+// the events of the playback service.
+package mpd
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/llehouerou/waves/internal/library"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/playlists"
+)
+
+// protocolVersion is the MPD protocol version announced to clients.
+const protocolVersion = "0.23.0"
+
+// VolumeStore persists volume changes.
+type VolumeStore interface {
+	SaveVolume(volume float64, muted bool) error
+}
+
+// Config configures a server.
+type Config struct {
+	Address   string // TCP address to listen on, as host:port
+	Password  string // Required from clients before other commands, if set
+	Library   *library.Library
+	Playlists *playlists.Playlists
+	Volumes   VolumeStore // May be nil
+}
+
+// Server serves MPD clients.
+type Server struct {
+	ln       net.Listener
+	lib      *library.Library
+	pls      *playlists.Playlists
+	volumes  VolumeStore
+	password string
+	started  time.Time
+	queue    queueIDs
+	done     chan struct{}
+	svc      *playback.Holder // Replaced by SetService
+
+	mu      sync.Mutex
+	clients map[*client]struct{}
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+// Listen listens on cfg.Address and serves clients in the background.
+func Listen(svc playback.Service, cfg Config) (*Server, error) {
+	ln, err := net.Listen("tcp", cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		ln:       ln,
+		lib:      cfg.Library,
+		pls:      cfg.Playlists,
+		volumes:  cfg.Volumes,
+		password: cfg.Password,
+		started:  time.Now(),
+		done:     make(chan struct{}),
+		svc:      playback.NewHolder(svc),
+		clients:  make(map[*client]struct{}),
+	}
+	_, replaced := s.svc.Current()
+	s.wg.Add(2)
+	go s.serve()
+	go s.watch(svc, svc.Subscribe(), replaced)
+	return s, nil
+}
+
+// Addr returns the address the server listens on.
+func (s *Server) Addr() net.Addr {
+	return s.ln.Addr()
+}
+
+// SetService makes the server control a new playback service. Call this
+// when the service is recreated.
+func (s *Server) SetService(svc playback.Service) {
+	s.svc.Set(svc)
+}
+
+// Close stops the server and disconnects the clients.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+	for c := range s.clients {
+		c.conn.Close()
+	}
+	s.mu.Unlock()
+
+	err := s.ln.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		c := newClient(s, conn)
+		if !s.track(c) {
+			conn.Close()
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer s.untrack(c)
+			c.serve()
+		}()
+	}
+}
+
+// track registers a client, returning false once the server is closed.
+func (s *Server) track(c *client) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.clients[c] = struct{}{}
+	return true
+}
+
+func (s *Server) untrack(c *client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.clients, c)
+}
+
+// notify raises idle events for all clients.
+func (s *Server) notify(events subsystem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.clients {
+		c.raise(events)
+	}
+}
+
+// watch raises the idle events matching the events of the playback
+// service, following its replacements, until the server closes.
+func (s *Server) watch(svc playback.Service, sub *playback.Subscription, replaced <-chan struct{}) {
+	defer s.wg.Done()
+	for {
+		s.forward(sub, replaced)
+		svc.Unsubscribe(sub)
+		select {
+		case <-replaced:
+		case <-s.done:
+			return
+		}
+		svc, replaced = s.svc.Current()
+		sub = svc.Subscribe()
+		// Everything may differ in the new service
+		s.notify(subPlayer | subPlaylist | subOptions)
+	}
+}
+
+// forward raises idle events for the events of a subscription until it is
+// closed, its service is replaced or the server closes.
+func (s *Server) forward(sub *playback.Subscription, replaced <-chan struct{}) {
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-replaced:
+			return
+		case <-sub.Done:
+			return
+		case <-sub.StateChanged:
+			s.notify(subPlayer)
+		case <-sub.TrackChanged:
+			s.notify(subPlayer)
+		case <-sub.PositionChanged:
+			s.notify(subPlayer)
+		case <-sub.QueueChanged:
+			s.notify(subPlaylist)
+		case <-sub.ModeChanged:
+			s.notify(subOptions)
+		case <-sub.VolumeChanged:
+			s.notify(subMixer)
+		case <-sub.Error:
+		}
+	}
+}
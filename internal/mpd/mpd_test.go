@@ -0,0 +1,623 @@
+package mpd
+
+import (
+	"bufio"
+	"database/sql"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/llehouerou/waves/internal/library"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/playlist"
+	"github.com/llehouerou/waves/internal/playlists"
+)
+
+const testSchema = `
+	CREATE TABLE library_tracks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		path TEXT NOT NULL UNIQUE,
+		mtime INTEGER NOT NULL,
+		artist TEXT NOT NULL,
+		album_artist TEXT NOT NULL,
+		album TEXT NOT NULL,
+		title TEXT NOT NULL,
+		disc_number INTEGER,
+		track_number INTEGER,
+		year INTEGER,
+		genre TEXT,
+		original_date TEXT,
+		release_date TEXT,
+		label TEXT,
+		play_count INTEGER NOT NULL DEFAULT 0,
+		skip_count INTEGER NOT NULL DEFAULT 0,
+		last_played_at INTEGER,
+		rating INTEGER NOT NULL DEFAULT 0,
+		album_rating INTEGER NOT NULL DEFAULT 0,
+		added_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL
+	);
+
+	CREATE TABLE library_sources (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		path TEXT NOT NULL UNIQUE,
+		added_at INTEGER NOT NULL
+	);
+
+	CREATE TABLE playlist_folders (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		parent_id INTEGER REFERENCES playlist_folders(id) ON DELETE CASCADE,
+		name TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		UNIQUE(parent_id, name)
+	);
+
+	CREATE TABLE playlists (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		folder_id INTEGER REFERENCES playlist_folders(id) ON DELETE CASCADE,
+		name TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		last_used_at INTEGER NOT NULL,
+		rules TEXT,
+		UNIQUE(folder_id, name)
+	);
+
+	CREATE TABLE playlist_tracks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		playlist_id INTEGER NOT NULL REFERENCES playlists(id) ON DELETE CASCADE,
+		position INTEGER NOT NULL,
+		library_track_id INTEGER REFERENCES library_tracks(id) ON DELETE CASCADE,
+		UNIQUE(playlist_id, position)
+	);
+
+	INSERT INTO playlists (id, folder_id, name, created_at, last_used_at)
+	VALUES (1, NULL, 'Favorites', 1000, 1000);
+`
+
+type testServer struct {
+	server  *Server
+	svc     playback.Service
+	player  *player.Mock
+	pls     *playlists.Playlists
+	volumes *fakeVolumes
+	music   string
+}
+
+// fakeVolumes records the saved volume.
+type fakeVolumes struct {
+	volume float64
+	saves  int
+}
+
+func (f *fakeVolumes) SaveVolume(volume float64, _ bool) error {
+	f.volume = volume
+	f.saves++
+	return nil
+}
+
+// newTestServer serves a library of three tracks by Blur and Gorillaz. The
+// music folder also holds Misc/untitled.flac, outside the library.
+func newTestServer(t *testing.T, password string) *testServer {
+	t.Helper()
+	dir := t.TempDir()
+	music := filepath.Join(dir, "music")
+	files := []string{
+		"Blur/Blur/01 Beetlebum.mp3",
+		"Blur/Blur/02 Song 2.mp3",
+		"Gorillaz/Demon Days/06 DARE.mp3",
+		"Misc/untitled.flac",
+	}
+	for _, name := range files {
+		path := filepath.Join(music, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", filepath.Join(dir, "waves.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec(testSchema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO library_sources (path, added_at) VALUES (?, 1000)`, music); err != nil {
+		t.Fatalf("failed to insert source: %v", err)
+	}
+	_, err = db.Exec(`
+		INSERT INTO library_tracks (path, mtime, artist, album_artist, album, title, track_number, disc_number, year, genre, added_at, updated_at)
+		VALUES
+			(?, 1000, 'Blur', 'Blur', 'Blur', 'Beetlebum', 1, 1, 1997, 'Rock', 1000, 1000),
+			(?, 1000, 'Blur', 'Blur', 'Blur', 'Song 2', 2, 1, 1997, 'Rock', 1000, 1000),
+			(?, 1000, 'Gorillaz feat. Shaun Ryder', 'Gorillaz', 'Demon Days', 'DARE', 6, 1, 2005, 'Alternative', 1000, 1000)
+	`, filepath.Join(music, files[0]), filepath.Join(music, files[1]), filepath.Join(music, files[2]))
+	if err != nil {
+		t.Fatalf("failed to insert tracks: %v", err)
+	}
+
+	lib := library.New(db)
+	pls := playlists.New(db, lib)
+	p := player.NewMock()
+	svc := playback.New(p, playlist.NewQueue())
+	volumes := &fakeVolumes{}
+	server, err := Listen(svc, Config{
+		Address:   "localhost:0",
+		Password:  password,
+		Library:   lib,
+		Playlists: pls,
+		Volumes:   volumes,
+	})
+	if err != nil {
+		t.Fatalf("Listen() error: %v", err)
+	}
+	t.Cleanup(func() {
+		server.Close()
+		svc.Close()
+	})
+	return &testServer{server: server, svc: svc, player: p, pls: pls, volumes: volumes, music: music}
+}
+
+// testClient speaks the protocol to a test server.
+type testClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func (ts *testServer) dial(t *testing.T) *testClient {
+	t.Helper()
+	conn, err := net.Dial("tcp", ts.server.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial() error: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	c := &testClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+	if greeting := c.readLine(); greeting != "OK MPD "+protocolVersion {
+		t.Fatalf("greeting = %q, want OK MPD %s", greeting, protocolVersion)
+	}
+	return c
+}
+
+func (c *testClient) readLine() string {
+	c.t.Helper()
+	_ = c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		c.t.Fatalf("read error: %v", err)
+	}
+	return strings.TrimSuffix(line, "\n")
+}
+
+func (c *testClient) send(lines ...string) {
+	c.t.Helper()
+	for _, line := range lines {
+		if _, err := c.conn.Write([]byte(line + "\n")); err != nil {
+			c.t.Fatalf("write error: %v", err)
+		}
+	}
+}
+
+// response reads the lines of a response, and its ACK line if it failed.
+func (c *testClient) response() (lines []string, ack string) {
+	c.t.Helper()
+	for {
+		line := c.readLine()
+		switch {
+		case line == "OK":
+			return lines, ""
+		case strings.HasPrefix(line, "ACK "):
+			return lines, line
+		}
+		lines = append(lines, line)
+	}
+}
+
+// ok runs a command that must succeed and returns its lines.
+func (c *testClient) ok(line string) []string {
+	c.t.Helper()
+	c.send(line)
+	return c.okResponse()
+}
+
+// okResponse reads the response of a command that must succeed.
+func (c *testClient) okResponse() []string {
+	c.t.Helper()
+	lines, ack := c.response()
+	if ack != "" {
+		c.t.Fatal(ack)
+	}
+	return lines
+}
+
+// ack runs a command that must fail and returns its ACK line.
+func (c *testClient) ack(line string) string {
+	c.t.Helper()
+	c.send(line)
+	lines, ack := c.response()
+	if ack == "" {
+		c.t.Fatalf("%s succeeded with %q, want an error", line, lines)
+	}
+	return ack
+}
+
+// values returns the values of the lines with a key.
+func values(lines []string, key string) []string {
+	var vals []string
+	for _, line := range lines {
+		if k, v, ok := strings.Cut(line, ": "); ok && k == key {
+			vals = append(vals, v)
+		}
+	}
+	return vals
+}
+
+func value(lines []string, key string) string {
+	vals := values(lines, key)
+	if len(vals) == 0 {
+		return ""
+	}
+	return vals[0]
+}
+
+func assertValues(t *testing.T, what string, got []string, want ...string) {
+	t.Helper()
+	if !slices.Equal(got, want) {
+		t.Errorf("%s = %q, want %q", what, got, want)
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"status", []string{"status"}},
+		{`add "Blur/Blur/02 Song 2.mp3"`, []string{"add", "Blur/Blur/02 Song 2.mp3"}},
+		{`find  artist "Say \"Hi\" \\ o/"`, []string{"find", "artist", `Say "Hi" \ o/`}},
+		{`list album ""`, []string{"list", "album", ""}},
+	}
+	for _, tt := range tests {
+		got, err := splitArgs(tt.line)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("splitArgs(%q) = %q, %v, want %q", tt.line, got, err, tt.want)
+		}
+	}
+	if _, err := splitArgs(`add "unterminated`); err == nil {
+		t.Error("splitArgs() with an unterminated quote should fail")
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	ts := newTestServer(t, "")
+	c := ts.dial(t)
+
+	tests := []struct{ line, want string }{
+		{"dance", `ACK [5@0] {} unknown command "dance"`},
+		{"play 1 2", `ACK [2@0] {play} wrong number of arguments for "play"`},
+		{"play x", `ACK [2@0] {play} Integer expected: x`},
+		{"play 3", `ACK [2@0] {play} Bad song index`},
+		{"add Nowhere", `ACK [50@0] {add} No such song`},
+		{"deleteid 42", `ACK [50@0] {deleteid} No such song`},
+		{"list composer", `ACK [2@0] {list} Unknown tag type: composer`},
+		{`find "(artist != 'Blur')"`, `ACK [2@0] {find} Unsupported operator: !=`},
+	}
+	for _, tt := range tests {
+		if got := c.ack(tt.line); got != tt.want {
+			t.Errorf("%s: %s, want %s", tt.line, got, tt.want)
+		}
+	}
+	// The connection is still usable
+	c.ok("ping")
+}
+
+func TestServer_QueueAndPlayback(t *testing.T) {
+	ts := newTestServer(t, "")
+	ts.player.SetDuration(3 * time.Minute)
+	c := ts.dial(t)
+
+	st := c.ok("status")
+	if value(st, "state") != "stop" || value(st, "playlistlength") != "0" {
+		t.Fatalf("initial status = %q, want stopped with an empty queue", st)
+	}
+	version := value(st, "playlist")
+
+	c.ok(`add "Blur/Blur"`)
+	id := value(c.ok(`addid "Misc/untitled.flac"`), "Id")
+	c.ok(`add "Gorillaz/Demon Days/06 DARE.mp3" 0`)
+
+	queue := c.ok("playlistinfo")
+	assertValues(t, "queue files", values(queue, "file"),
+		"Gorillaz/Demon Days/06 DARE.mp3", "Blur/Blur/01 Beetlebum.mp3", "Blur/Blur/02 Song 2.mp3", "Misc/untitled.flac")
+	assertValues(t, "queue positions", values(queue, "Pos"), "0", "1", "2", "3")
+	assertValues(t, "queue titles", values(queue, "Title"), "DARE", "Beetlebum", "Song 2", "untitled.flac")
+	if ids := values(queue, "Id"); ids[3] != id {
+		t.Errorf("ids = %q, want the id %s returned by addid last", ids, id)
+	}
+	if value(c.ok("playlistinfo 1"), "Title") != "Beetlebum" {
+		t.Error("playlistinfo 1 should describe the second track")
+	}
+	assertValues(t, "range", values(c.ok("playlistinfo 1:3"), "Pos"), "1", "2")
+
+	c.ok("play 1")
+	st = c.ok("status")
+	if value(st, "state") != "play" || value(st, "song") != "1" || value(st, "nextsong") != "2" {
+		t.Errorf("status after play 1 = %q, want playing song 1", st)
+	}
+	if value(st, "playlist") == version {
+		t.Error("the playlist version should change with the queue")
+	}
+	if value(st, "duration") != "180.000" {
+		t.Errorf("duration = %q, want 180.000", value(st, "duration"))
+	}
+	if value(c.ok("currentsong"), "Title") != "Beetlebum" {
+		t.Error("currentsong should be Beetlebum")
+	}
+
+	c.ok("pause 1")
+	if value(c.ok("status"), "state") != "pause" {
+		t.Error("state after pause 1 should be pause")
+	}
+	c.ok("pause 0")
+	c.ok("next")
+	if value(c.ok("currentsong"), "Title") != "Song 2" {
+		t.Error("currentsong after next should be Song 2")
+	}
+	c.ok("seekcur 30")
+	c.ok("seekcur -10")
+	seeks := ts.player.SeekCalls()
+	if len(seeks) != 2 || seeks[1] != -10*time.Second {
+		t.Errorf("seeks = %v, want a relative seek of -10s last", seeks)
+	}
+
+	// Song IDs follow the tracks they were given to
+	ids := values(c.ok("playlistinfo"), "Id")
+	c.ok("delete 0")
+	c.ok("move 0 2")
+	c.ok("deleteid " + ids[3])
+	queue = c.ok("playlistinfo")
+	assertValues(t, "files after delete and move", values(queue, "file"),
+		"Blur/Blur/02 Song 2.mp3", "Blur/Blur/01 Beetlebum.mp3")
+	assertValues(t, "ids after delete and move", values(queue, "Id"), ids[2], ids[1])
+	c.ok("moveid " + ids[1] + " 0")
+	assertValues(t, "ids after moveid", values(c.ok("playlistinfo"), "Id"), ids[1], ids[2])
+	if value(c.ok("currentsong"), "Id") != ids[2] {
+		t.Error("the playing track should stay current when moved")
+	}
+
+	c.ok("stop")
+	c.ok("clear")
+	if value(c.ok("status"), "playlistlength") != "0" {
+		t.Error("queue should be empty after clear")
+	}
+}
+
+func TestServer_Options(t *testing.T) {
+	ts := newTestServer(t, "")
+	c := ts.dial(t)
+
+	ts.player.SetMuted(true)
+	c.ok("setvol 40")
+	c.ok("volume +15")
+	st := c.ok("status")
+	if value(st, "volume") != "55" || ts.player.Muted() {
+		t.Errorf("volume = %s muted %v, want 55 unmuted", value(st, "volume"), ts.player.Muted())
+	}
+	if ts.volumes.saves != 2 || ts.volumes.volume != 0.55 {
+		t.Errorf("saved volume %v %d times, want 0.55 twice", ts.volumes.volume, ts.volumes.saves)
+	}
+	c.ack("setvol 101")
+
+	c.ok("repeat 1")
+	c.ok("random 1")
+	st = c.ok("status")
+	if value(st, "repeat") != "1" || value(st, "single") != "0" || value(st, "random") != "1" {
+		t.Errorf("status = %q, want repeat and random", st)
+	}
+	c.ok("single 1")
+	if ts.svc.RepeatMode() != playback.RepeatOne {
+		t.Errorf("repeat mode = %v, want One", ts.svc.RepeatMode())
+	}
+	c.ok("single 0")
+	if ts.svc.RepeatMode() != playback.RepeatAll {
+		t.Errorf("repeat mode = %v, want All", ts.svc.RepeatMode())
+	}
+	c.ok("repeat 0")
+	if ts.svc.RepeatMode() != playback.RepeatOff {
+		t.Errorf("repeat mode = %v, want Off", ts.svc.RepeatMode())
+	}
+	c.ok("consume 0")
+	c.ack("consume 1")
+}
+
+func TestServer_Library(t *testing.T) {
+	ts := newTestServer(t, "")
+	c := ts.dial(t)
+
+	assertValues(t, "albums", values(c.ok("list album"), "Album"), "Blur", "Demon Days")
+	assertValues(t, "albums of Blur", values(c.ok("list album Blur"), "Album"), "Blur")
+	assertValues(t, "filtered titles", values(c.ok(`list title "(album == 'Blur')"`), "Title"), "Beetlebum", "Song 2")
+	assertValues(t, "grouped", c.ok("list album group albumartist"),
+		"AlbumArtist: Blur", "Album: Blur", "AlbumArtist: Gorillaz", "Album: Demon Days")
+	assertValues(t, "dates", values(c.ok("list date"), "Date"), "1997", "2005")
+
+	found := c.ok("find artist Blur")
+	assertValues(t, "find", values(found, "file"), "Blur/Blur/01 Beetlebum.mp3", "Blur/Blur/02 Song 2.mp3")
+	assertValues(t, "song tags", found[:10], "file: Blur/Blur/01 Beetlebum.mp3",
+		"Last-Modified: 1970-01-01T00:16:40Z", "Artist: Blur", "AlbumArtist: Blur", "Album: Blur",
+		"Title: Beetlebum", "Genre: Rock", "Date: 1997", "Track: 1", "Disc: 1")
+	assertValues(t, "find is exact", values(c.ok("find artist blur"), "file"))
+	assertValues(t, "search ignores case", values(c.ok("search any GORILLAZ"), "Title"), "DARE")
+	assertValues(t, "expression", values(c.ok(`find "((albumartist == 'Blur') AND (title contains 'song'))"`), "Title"), "Song 2")
+	assertValues(t, "file", values(c.ok(`find file "Blur/Blur/02 Song 2.mp3"`), "Title"), "Song 2")
+	assertValues(t, "window", values(c.ok("search any b window 1:2"), "Title"), "Song 2")
+	assertValues(t, "count", values(c.ok("count genre Rock"), "songs"), "2")
+
+	assertValues(t, "root", values(c.ok("lsinfo"), "directory"), "Blur", "Gorillaz", "Misc")
+	folder := c.ok(`lsinfo "Blur/Blur"`)
+	assertValues(t, "folder titles", values(folder, "Title"), "Beetlebum", "Song 2")
+	assertValues(t, "outside the library", values(c.ok("lsinfo Misc"), "file"), "Misc/untitled.flac")
+	c.ack("lsinfo Nowhere")
+
+	c.ok("findadd album Blur")
+	c.ok("searchadd title dare")
+	assertValues(t, "queue", values(c.ok("playlistinfo"), "Title"), "Beetlebum", "Song 2", "DARE")
+
+	st := c.ok("stats")
+	if value(st, "songs") != "3" || value(st, "albums") != "2" || value(st, "artists") != "2" {
+		t.Errorf("stats = %q, want 3 songs, 2 albums and 2 artists", st)
+	}
+}
+
+func TestServer_StoredPlaylists(t *testing.T) {
+	ts := newTestServer(t, "")
+	c := ts.dial(t)
+
+	c.ok(`add "Blur/Blur"`)
+	c.ok(`add "Misc/untitled.flac"`)
+	c.ok("save Mix")
+	if got := c.ack("save mix"); !strings.HasPrefix(got, "ACK [56@0]") {
+		t.Errorf("saving an existing playlist: %s, want error 56", got)
+	}
+	assertValues(t, "saved without the file outside the library", values(c.ok("listplaylist Mix"), "file"),
+		"Blur/Blur/01 Beetlebum.mp3", "Blur/Blur/02 Song 2.mp3")
+
+	c.ok(`playlistadd Mix "Gorillaz"`)
+	c.ok("playlistdelete Mix 0")
+	c.ok("playlistmove Mix 1 0")
+	assertValues(t, "edited", values(c.ok("listplaylistinfo Mix"), "Title"), "DARE", "Song 2")
+	if got := c.ack(`playlistadd Mix "Misc/untitled.flac"`); !strings.HasPrefix(got, "ACK [50@0]") {
+		t.Errorf("adding a file outside the library: %s, want error 50", got)
+	}
+
+	// Playlists in folders are listed too, and created by playlistadd
+	folderID, err := ts.pls.CreateFolder(nil, "Old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.pls.Create(&folderID, "Archive"); err != nil {
+		t.Fatal(err)
+	}
+	c.ok(`playlistadd New "Blur/Blur/01 Beetlebum.mp3"`)
+	assertValues(t, "playlists", values(c.ok("listplaylists"), "playlist"), "Favorites", "Mix", "New", "Archive")
+
+	c.ok("clear")
+	c.ok("load Mix")
+	c.ok("load New 0:1")
+	assertValues(t, "loaded", values(c.ok("playlistinfo"), "Title"), "DARE", "Song 2", "Beetlebum")
+
+	c.ok("rename Mix Best")
+	c.ack("listplaylist Mix")
+	c.ok("playlistclear Best")
+	assertValues(t, "cleared", values(c.ok("listplaylist Best"), "file"))
+	c.ok("rm Best")
+	if got := c.ack("rm Favorites"); !strings.HasPrefix(got, "ACK [4@0]") {
+		t.Errorf("removing Favorites: %s, want error 4", got)
+	}
+	if got := c.ack("rm Best"); got != "ACK [50@0] {rm} No such playlist" {
+		t.Errorf("removing a missing playlist: %s", got)
+	}
+}
+
+func TestServer_CommandLists(t *testing.T) {
+	ts := newTestServer(t, "")
+	c := ts.dial(t)
+
+	c.send("command_list_ok_begin", "ping", `add "Blur/Blur"`, "command_list_end")
+	assertValues(t, "list_OK", c.readLines(3), "list_OK", "list_OK", "OK")
+
+	c.send("command_list_begin", "ping", "dance", "ping", "command_list_end")
+	lines, ack := c.response()
+	if len(lines) != 0 || ack != `ACK [5@1] {} unknown command "dance"` {
+		t.Errorf("failing list = %q, %s, want the ACK of its second command", lines, ack)
+	}
+	if value(c.ok("status"), "playlistlength") != "2" {
+		t.Error("commands of a list should run")
+	}
+}
+
+func (c *testClient) readLines(n int) []string {
+	c.t.Helper()
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = c.readLine()
+	}
+	return lines
+}
+
+func TestServer_Idle(t *testing.T) {
+	ts := newTestServer(t, "")
+	idler := ts.dial(t)
+	c := ts.dial(t)
+
+	idler.send("idle playlist")
+	c.ok(`add "Blur/Blur"`)
+	assertValues(t, "changes", idler.okResponse(), "changed: playlist")
+
+	// Events are kept while not idle
+	c.ok("setvol 50")
+	assertValues(t, "mixer changes", idler.ok("idle mixer"), "changed: mixer")
+	c.ok("play")
+	assertValues(t, "player changes", idler.ok("idle player"), "changed: player")
+
+	c.ok("save Mix")
+	assertValues(t, "stored playlist changes", idler.ok("idle stored_playlist"), "changed: stored_playlist")
+
+	idler.send("idle")
+	idler.send("noidle")
+	if lines, ack := idler.response(); ack != "" {
+		t.Errorf("noidle = %q, %s, want OK", lines, ack)
+	}
+	if got := idler.ack("idle nothing"); got != "ACK [2@0] {idle} Unrecognized idle event: nothing" {
+		t.Errorf("idle with an unknown event: %s", got)
+	}
+
+	// Idle clients follow a recreated service
+	svc := playback.New(ts.player, playlist.NewQueue())
+	defer svc.Close()
+	ts.svc.Close()
+	ts.server.SetService(svc)
+	idler.ok("idle playlist")
+	idler.send("idle playlist")
+	c.ok(`add "Gorillaz"`)
+	assertValues(t, "changes of the new service", idler.okResponse(), "changed: playlist")
+	assertValues(t, "new queue", values(c.ok("playlistinfo"), "Title"), "DARE")
+}
+
+func TestServer_Password(t *testing.T) {
+	ts := newTestServer(t, "secret")
+	c := ts.dial(t)
+
+	c.ok("ping")
+	if got := c.ack("status"); got != `ACK [4@0] {status} you don't have permission for "status"` {
+		t.Errorf("status before the password: %s", got)
+	}
+	if got := c.ack("password wrong"); got != "ACK [3@0] {password} incorrect password" {
+		t.Errorf("wrong password: %s", got)
+	}
+	c.ok("password secret")
+	c.ok("status")
+}
+
+func TestServer_Close(t *testing.T) {
+	ts := newTestServer(t, "")
+	c := ts.dial(t)
+	c.send("idle")
+	if err := ts.server.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	_ = c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := c.r.ReadString('\n'); err == nil {
+		t.Error("idle clients should be disconnected on Close()")
+	}
+}
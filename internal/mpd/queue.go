@@ -0,0 +1,266 @@
+package mpd
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/playlist"
+)
+
+// queueIDs gives the tracks of the queue the IDs clients address them by,
+// and versions the queue. IDs are kept while a track stays in the queue.
+type queueIDs struct {
+	mu      sync.Mutex
+	paths   []string
+	ids     []int
+	lastID  int
+	version int
+}
+
+// sync updates the IDs to the queue tracks and returns them with the queue
+// version, which changes with the queue.
+func (q *queueIDs) sync(tracks []playback.Track) ([]int, int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.version == 0 {
+		q.version = 1
+	}
+
+	paths := make([]string, len(tracks))
+	for i, t := range tracks {
+		paths[i] = t.Path
+	}
+	if slices.Equal(paths, q.paths) {
+		return slices.Clone(q.ids), q.version
+	}
+
+	// Tracks keep their ID, duplicates in order
+	old := make(map[string][]int, len(q.paths))
+	for i, path := range q.paths {
+		old[path] = append(old[path], q.ids[i])
+	}
+	ids := make([]int, len(paths))
+	for i, path := range paths {
+		if prev := old[path]; len(prev) > 0 {
+			ids[i], old[path] = prev[0], prev[1:]
+			continue
+		}
+		q.lastID++
+		ids[i] = q.lastID
+	}
+	q.paths, q.ids = paths, ids
+	q.version++
+	return slices.Clone(ids), q.version
+}
+
+// queue returns the queue tracks with their IDs and the queue version.
+func (c *client) queue() ([]playback.Track, []int, int) {
+	tracks := c.s.svc.Service().QueueTracks()
+	ids, version := c.s.queue.sync(tracks)
+	return tracks, ids, version
+}
+
+// position returns the position of the queue track with an ID.
+func (c *client) position(idArg string) (int, error) {
+	id, err := parseInt(idArg)
+	if err != nil {
+		return 0, err
+	}
+	_, ids, _ := c.queue()
+	pos := slices.Index(ids, id)
+	if pos < 0 {
+		return 0, errNoExist("No such song")
+	}
+	return pos, nil
+}
+
+func cmdPlaylistInfo(c *client, args []string) error {
+	tracks, ids, _ := c.queue()
+	start, end := 0, len(tracks)
+	if len(args) == 1 {
+		var err error
+		if start, end, err = parseRange(args[0], len(tracks)); err != nil {
+			return err
+		}
+	}
+	for i := start; i < end; i++ {
+		c.writeQueueSong(tracks[i], i, ids[i])
+	}
+	return nil
+}
+
+func cmdPlaylistID(c *client, args []string) error {
+	tracks, ids, _ := c.queue()
+	if len(args) == 0 {
+		for i, t := range tracks {
+			c.writeQueueSong(t, i, ids[i])
+		}
+		return nil
+	}
+	pos, err := c.position(args[0])
+	if err != nil {
+		return err
+	}
+	c.writeQueueSong(tracks[pos], pos, ids[pos])
+	return nil
+}
+
+// cmdPlChanges lists the songs changed since a queue version. Versions are
+// not kept, so the whole queue is listed unless it didn't change.
+func cmdPlChanges(c *client, args []string) error {
+	since, err := parseInt(args[0])
+	if err != nil {
+		return err
+	}
+	tracks, ids, version := c.queue()
+	if since == version {
+		return nil
+	}
+	for i, t := range tracks {
+		c.writeQueueSong(t, i, ids[i])
+	}
+	return nil
+}
+
+func cmdPlChangesPosID(c *client, args []string) error {
+	since, err := parseInt(args[0])
+	if err != nil {
+		return err
+	}
+	_, ids, version := c.queue()
+	if since == version {
+		return nil
+	}
+	for i, id := range ids {
+		c.field("cpos", i)
+		c.field("Id", id)
+	}
+	return nil
+}
+
+// tracksAt returns the tracks of the file or folder at a URI.
+func (c *client) tracksAt(uri string) ([]playback.Track, error) {
+	path, _, err := c.existingPath(uri)
+	if err != nil {
+		return nil, err
+	}
+	tracks, err := playlist.CollectFromPaths([]string{path}, c.s.lib.TrackByPath)
+	if err != nil {
+		return nil, errNoExist("%v", err)
+	}
+	return playback.TracksFromPlaylist(tracks), nil
+}
+
+func cmdAdd(c *client, args []string) error {
+	tracks, err := c.tracksAt(args[0])
+	if err != nil {
+		return err
+	}
+	svc := c.s.svc.Service()
+	if len(args) == 2 {
+		pos, err := parseInt(args[1])
+		if err != nil || pos < 0 || pos > svc.QueueLen() {
+			return errArg("Bad position")
+		}
+		svc.InsertTracks(pos, tracks...)
+		return nil
+	}
+	svc.AddTracks(tracks...)
+	return nil
+}
+
+func cmdAddID(c *client, args []string) error {
+	path, info, err := c.existingPath(args[0])
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return errArg("Directories can't be added with addid")
+	}
+	if err := cmdAdd(c, args); err != nil {
+		return err
+	}
+
+	tracks, ids, _ := c.queue()
+	pos := len(tracks) - 1
+	if len(args) == 2 {
+		pos, _ = parseInt(args[1])
+	}
+	if pos < 0 || pos >= len(tracks) || tracks[pos].Path != path {
+		return errNoExist("No such song")
+	}
+	c.field("Id", ids[pos])
+	return nil
+}
+
+func cmdDelete(c *client, args []string) error {
+	svc := c.s.svc.Service()
+	start, end, err := parseRange(args[0], svc.QueueLen())
+	if err != nil {
+		return err
+	}
+	indices := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		indices = append(indices, i)
+	}
+	svc.RemoveTracks(indices...)
+	return nil
+}
+
+func cmdDeleteID(c *client, args []string) error {
+	pos, err := c.position(args[0])
+	if err != nil {
+		return err
+	}
+	c.s.svc.Service().RemoveTracks(pos)
+	return nil
+}
+
+func cmdMove(c *client, args []string) error {
+	svc := c.s.svc.Service()
+	start, end, err := parseRange(args[0], svc.QueueLen())
+	if err != nil {
+		return err
+	}
+	to, err := parseInt(args[1])
+	if err != nil {
+		return err
+	}
+	return moveTracks(svc, start, end, to)
+}
+
+func cmdMoveID(c *client, args []string) error {
+	pos, err := c.position(args[0])
+	if err != nil {
+		return err
+	}
+	to, err := parseInt(args[1])
+	if err != nil {
+		return err
+	}
+	return moveTracks(c.s.svc.Service(), pos, pos+1, to)
+}
+
+// moveTracks moves the tracks from start to end so the first is at to.
+func moveTracks(svc playback.Service, start, end, to int) error {
+	if to < 0 || to+end-start > svc.QueueLen() {
+		return errArg("Bad song index")
+	}
+	if to == start {
+		return nil
+	}
+	indices := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		indices = append(indices, i)
+	}
+	if !svc.MoveTracks(indices, to-start) {
+		return errArg("Bad song index")
+	}
+	return nil
+}
+
+func cmdClear(c *client, _ []string) error {
+	c.s.svc.Service().ClearQueue()
+	return nil
+}
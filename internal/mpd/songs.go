@@ -0,0 +1,188 @@
+package mpd
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/llehouerou/waves/internal/library"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/playlist"
+)
+
+// root is a library source as seen by clients. With a single source, its
+// name is empty and its content is at the top; with several, each is a
+// top-level folder named after it.
+type root struct {
+	name string
+	path string
+}
+
+// libraryRoots returns the roots of the library sources.
+func (c *client) libraryRoots() []root {
+	if c.roots != nil {
+		return c.roots
+	}
+	sources, err := c.s.lib.Sources()
+	if err != nil {
+		return nil
+	}
+	c.roots = make([]root, 0, len(sources))
+	for _, src := range sources {
+		r := root{path: filepath.Clean(src)}
+		if len(sources) > 1 {
+			r.name = filepath.Base(r.path)
+		}
+		c.roots = append(c.roots, r)
+	}
+	return c.roots
+}
+
+// uri returns the URI of a path: relative to its library source, or the
+// path itself outside the library.
+func (c *client) uri(path string) string {
+	for _, r := range c.libraryRoots() {
+		rel, err := filepath.Rel(r.path, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+			continue
+		}
+		if rel == "." {
+			rel = ""
+		}
+		return filepath.ToSlash(filepath.Join(r.name, rel))
+	}
+	return path
+}
+
+// path returns the path of a URI. ok is false for URIs of no library
+// source.
+func (c *client) path(uri string) (path string, ok bool) {
+	if filepath.IsAbs(uri) {
+		return filepath.Clean(uri), true
+	}
+	rel := filepath.Clean("/" + uri)[1:] // Can't go up out of the root
+	for _, r := range c.libraryRoots() {
+		if r.name == "" {
+			return filepath.Join(r.path, rel), true
+		}
+		first, rest, _ := strings.Cut(rel, "/")
+		if first == r.name {
+			return filepath.Join(r.path, rest), true
+		}
+	}
+	return "", false
+}
+
+// existingPath returns the path of a URI, failing if there is no file there.
+func (c *client) existingPath(uri string) (string, os.FileInfo, error) {
+	path, ok := c.path(uri)
+	if !ok {
+		return "", nil, errNoExist("No such directory")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", nil, errNoExist("No such song")
+	}
+	return path, info, nil
+}
+
+// song is a track as sent to clients.
+type song struct {
+	path        string
+	artist      string
+	albumArtist string
+	album       string
+	title       string
+	genre       string
+	date        string
+	label       string
+	track       int
+	disc        int
+	duration    time.Duration
+	modified    time.Time
+}
+
+func songOfLibrary(t library.Track) song {
+	var date string
+	if t.Year > 0 {
+		date = strconv.Itoa(t.Year)
+	}
+	return song{
+		path:        t.Path,
+		artist:      t.Artist,
+		albumArtist: t.AlbumArtist,
+		album:       t.Album,
+		title:       t.Title,
+		genre:       t.Genre,
+		date:        date,
+		label:       t.Label,
+		track:       t.TrackNumber,
+		disc:        t.DiscNumber,
+		modified:    time.Unix(t.Mtime, 0),
+	}
+}
+
+func songOfTrack(t playback.Track) song {
+	s := song{
+		path:     t.Path,
+		artist:   t.Artist,
+		album:    t.Album,
+		title:    t.Title,
+		genre:    t.Genre,
+		track:    t.TrackNumber,
+		disc:     t.DiscNumber,
+		duration: t.Duration,
+	}
+	if t.Year > 0 {
+		s.date = strconv.Itoa(t.Year)
+	}
+	return s
+}
+
+func songOfPlaylist(t playlist.Track) song {
+	return songOfTrack(playback.TrackFromPlaylist(t))
+}
+
+// writeSong writes the lines describing a song.
+func (c *client) writeSong(s song) {
+	c.field("file", c.uri(s.path))
+	if !s.modified.IsZero() {
+		c.field("Last-Modified", s.modified.UTC().Format(time.RFC3339))
+	}
+	for _, tag := range []struct{ key, value string }{
+		{"Artist", s.artist},
+		{"AlbumArtist", s.albumArtist},
+		{"Album", s.album},
+		{"Title", s.title},
+		{"Genre", s.genre},
+		{"Date", s.date},
+		{"Label", s.label},
+	} {
+		if tag.value != "" {
+			c.field(tag.key, tag.value)
+		}
+	}
+	if s.track > 0 {
+		c.field("Track", s.track)
+	}
+	if s.disc > 0 {
+		c.field("Disc", s.disc)
+	}
+	if s.duration > 0 {
+		c.field("Time", int(s.duration.Round(time.Second).Seconds()))
+		c.field("duration", formatSeconds(s.duration))
+	}
+}
+
+// writeQueueSong writes a song of the queue with its position and ID.
+func (c *client) writeQueueSong(t playback.Track, pos, id int) {
+	c.writeSong(songOfTrack(t))
+	c.field("Pos", pos)
+	c.field("Id", id)
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
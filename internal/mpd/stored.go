@@ -0,0 +1,279 @@
+package mpd
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/playlists"
+)
+
+// Stored playlists are the playlists of waves, found by name ignoring case
+// whatever their folder. They only hold library tracks.
+
+// errSmart is returned when editing the tracks of a smart playlist.
+var errSmart = &ackError{ackPermission, playlists.ErrSmartPlaylist.Error()}
+
+func (c *client) playlist(name string) (*playlists.Playlist, error) {
+	pl, err := c.s.pls.GetByName(name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errNoExist("No such playlist")
+	}
+	return pl, err
+}
+
+// editablePlaylist returns the playlist with a name if its tracks can be
+// edited.
+func (c *client) editablePlaylist(name string) (*playlists.Playlist, error) {
+	pl, err := c.playlist(name)
+	if err != nil {
+		return nil, err
+	}
+	if pl.Smart {
+		return nil, errSmart
+	}
+	return pl, nil
+}
+
+// checkNewName fails if a playlist has the name.
+func (c *client) checkNewName(name string) error {
+	_, err := c.s.pls.GetByName(name)
+	switch {
+	case err == nil:
+		return &ackError{ackExist, "Playlist already exists"}
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	}
+	return err
+}
+
+func cmdListPlaylists(c *client, _ []string) error {
+	return c.listPlaylists(nil)
+}
+
+// listPlaylists writes the playlists of a folder and its subfolders.
+func (c *client) listPlaylists(folderID *int64) error {
+	pls, err := c.s.pls.List(folderID)
+	if err != nil {
+		return err
+	}
+	for _, pl := range pls {
+		c.field("playlist", pl.Name)
+		c.field("Last-Modified", time.Unix(pl.LastUsedAt, 0).UTC().Format(time.RFC3339))
+	}
+	folders, err := c.s.pls.Folders(folderID)
+	if err != nil {
+		return err
+	}
+	for _, f := range folders {
+		if err := c.listPlaylists(&f.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func cmdListPlaylist(c *client, args []string) error {
+	pl, err := c.playlist(args[0])
+	if err != nil {
+		return err
+	}
+	tracks, err := c.s.pls.Tracks(pl.ID)
+	if err != nil {
+		return err
+	}
+	for _, t := range tracks {
+		c.field("file", c.uri(t.Path))
+	}
+	return nil
+}
+
+func cmdListPlaylistInfo(c *client, args []string) error {
+	pl, err := c.playlist(args[0])
+	if err != nil {
+		return err
+	}
+	tracks, err := c.s.pls.Tracks(pl.ID)
+	if err != nil {
+		return err
+	}
+	for _, t := range tracks {
+		c.writeSong(songOfPlaylist(t))
+	}
+	return nil
+}
+
+func cmdLoad(c *client, args []string) error {
+	pl, err := c.playlist(args[0])
+	if err != nil {
+		return err
+	}
+	tracks, err := c.s.pls.Tracks(pl.ID)
+	if err != nil {
+		return err
+	}
+	if len(args) == 2 {
+		start, end, err := parseRange(args[1], len(tracks))
+		if err != nil {
+			return err
+		}
+		tracks = tracks[start:end]
+	}
+	c.s.svc.Service().AddTracks(playback.TracksFromPlaylist(tracks)...)
+	return nil
+}
+
+// cmdPlaylistAdd adds a file or folder to a playlist, creating it if
+// needed.
+func cmdPlaylistAdd(c *client, args []string) error {
+	tracks, err := c.tracksAt(args[1])
+	if err != nil {
+		return err
+	}
+	ids := make([]int64, 0, len(tracks))
+	for _, t := range tracks {
+		if t.ID == 0 {
+			return errNoExist("%s is not in the library", c.uri(t.Path))
+		}
+		ids = append(ids, t.ID)
+	}
+
+	var id int64
+	pl, err := c.s.pls.GetByName(args[0])
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		if id, err = c.s.pls.Create(nil, args[0]); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	case pl.Smart:
+		return errSmart
+	default:
+		id = pl.ID
+	}
+	if err := c.s.pls.AddTracks(id, ids); err != nil {
+		return err
+	}
+	c.s.notify(subStoredPlaylist)
+	return nil
+}
+
+func cmdPlaylistDelete(c *client, args []string) error {
+	pl, err := c.editablePlaylist(args[0])
+	if err != nil {
+		return err
+	}
+	count, err := c.s.pls.TrackCount(pl.ID)
+	if err != nil {
+		return err
+	}
+	pos, err := parseInt(args[1])
+	if err != nil {
+		return err
+	}
+	if pos < 0 || pos >= count {
+		return errArg("Bad song index")
+	}
+	if err := c.s.pls.RemoveTrack(pl.ID, pos); err != nil {
+		return err
+	}
+	c.s.notify(subStoredPlaylist)
+	return nil
+}
+
+func cmdPlaylistMove(c *client, args []string) error {
+	pl, err := c.editablePlaylist(args[0])
+	if err != nil {
+		return err
+	}
+	count, err := c.s.pls.TrackCount(pl.ID)
+	if err != nil {
+		return err
+	}
+	from, err := parseInt(args[1])
+	if err != nil {
+		return err
+	}
+	to, err := parseInt(args[2])
+	if err != nil {
+		return err
+	}
+	if from < 0 || from >= count || to < 0 || to >= count {
+		return errArg("Bad song index")
+	}
+	if _, err := c.s.pls.MoveIndices(pl.ID, []int{from}, to-from); err != nil {
+		return err
+	}
+	c.s.notify(subStoredPlaylist)
+	return nil
+}
+
+func cmdPlaylistClear(c *client, args []string) error {
+	pl, err := c.editablePlaylist(args[0])
+	if err != nil {
+		return err
+	}
+	if err := c.s.pls.ClearTracks(pl.ID); err != nil {
+		return err
+	}
+	c.s.notify(subStoredPlaylist)
+	return nil
+}
+
+func cmdRm(c *client, args []string) error {
+	pl, err := c.playlist(args[0])
+	if err != nil {
+		return err
+	}
+	if playlists.IsFavorites(pl.ID) {
+		return &ackError{ackPermission, "The Favorites playlist can't be deleted"}
+	}
+	if err := c.s.pls.Delete(pl.ID); err != nil {
+		return err
+	}
+	c.s.notify(subStoredPlaylist)
+	return nil
+}
+
+func cmdRename(c *client, args []string) error {
+	pl, err := c.playlist(args[0])
+	if err != nil {
+		return err
+	}
+	if playlists.IsFavorites(pl.ID) {
+		return &ackError{ackPermission, "The Favorites playlist can't be renamed"}
+	}
+	if err := c.checkNewName(args[1]); err != nil {
+		return err
+	}
+	if err := c.s.pls.Rename(pl.ID, args[1]); err != nil {
+		return err
+	}
+	c.s.notify(subStoredPlaylist)
+	return nil
+}
+
+// cmdSave saves the queue as a new playlist. Tracks outside the library
+// are left out.
+func cmdSave(c *client, args []string) error {
+	if err := c.checkNewName(args[0]); err != nil {
+		return err
+	}
+	var ids []int64
+	for _, t := range c.s.svc.Service().QueueTracks() {
+		if t.ID != 0 {
+			ids = append(ids, t.ID)
+		}
+	}
+	id, err := c.s.pls.Create(nil, args[0])
+	if err != nil {
+		return err
+	}
+	if err := c.s.pls.AddTracks(id, ids); err != nil {
+		return err
+	}
+	c.s.notify(subStoredPlaylist)
+	return nil
+}
@@ -0,0 +1,226 @@
+//go:build linux || freebsd
+
+package mpris
+
+import (
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+	"github.com/quarckster/go-mpris-server/pkg/types"
+)
+
+// The objects of the player are exported by the adapter rather than by
+// go-mpris-server, whose D-Bus properties can't be extended with the
+// TrackList interface. Its event handler still emits the changes of the
+// root and player properties.
+const (
+	objectPath         = dbus.ObjectPath("/org/mpris/MediaPlayer2")
+	rootInterface      = "org.mpris.MediaPlayer2"
+	playerInterface    = "org.mpris.MediaPlayer2.Player"
+	trackListInterface = "org.mpris.MediaPlayer2.TrackList"
+	propertiesChanged  = "org.freedesktop.DBus.Properties.PropertiesChanged"
+)
+
+// property is a D-Bus property. set is nil for read-only properties.
+type property struct {
+	get func() (any, error)
+	set func(dbus.Variant) error
+}
+
+// getter adapts a getter of the adapters to a property getter.
+func getter[T any](get func() (T, error)) func() (any, error) {
+	return func() (any, error) {
+		return get()
+	}
+}
+
+// setter adapts a setter of the adapters to a property setter, checking
+// the type of the value.
+func setter[T any](set func(T) error) func(dbus.Variant) error {
+	return func(v dbus.Variant) error {
+		value, ok := v.Value().(T)
+		if !ok {
+			return fmt.Errorf("invalid value %s", v)
+		}
+		return set(value)
+	}
+}
+
+// properties implements org.freedesktop.DBus.Properties for the interfaces
+// of the player.
+type properties struct {
+	conn   *dbus.Conn
+	ifaces map[string]map[string]property
+}
+
+func newProperties(conn *dbus.Conn, root *rootAdapter, player *playerAdapter, tracks *trackList) *properties {
+	return &properties{
+		conn: conn,
+		ifaces: map[string]map[string]property{
+			rootInterface: {
+				"CanQuit":             {get: getter(root.CanQuit)},
+				"CanRaise":            {get: getter(root.CanRaise)},
+				"HasTrackList":        {get: getter(root.HasTrackList)},
+				"Identity":            {get: getter(root.Identity)},
+				"SupportedUriSchemes": {get: getter(root.SupportedUriSchemes)},
+				"SupportedMimeTypes":  {get: getter(root.SupportedMimeTypes)},
+			},
+			playerInterface: {
+				"PlaybackStatus": {get: getter(player.PlaybackStatus)},
+				"LoopStatus": {
+					get: getter(player.LoopStatus),
+					set: setter(func(s string) error { return player.SetLoopStatus(types.LoopStatus(s)) }),
+				},
+				"Rate":          {get: getter(player.Rate), set: setter(player.SetRate)},
+				"Shuffle":       {get: getter(player.Shuffle), set: setter(player.SetShuffle)},
+				"Metadata":      {get: player.metadataMap},
+				"Volume":        {get: getter(player.Volume), set: setter(player.SetVolume)},
+				"Position":      {get: getter(player.Position)},
+				"MinimumRate":   {get: getter(player.MinimumRate)},
+				"MaximumRate":   {get: getter(player.MaximumRate)},
+				"CanGoNext":     {get: getter(player.CanGoNext)},
+				"CanGoPrevious": {get: getter(player.CanGoPrevious)},
+				"CanPlay":       {get: getter(player.CanPlay)},
+				"CanPause":      {get: getter(player.CanPause)},
+				"CanSeek":       {get: getter(player.CanSeek)},
+				"CanControl":    {get: getter(player.CanControl)},
+			},
+			trackListInterface: {
+				"Tracks":        {get: getter(tracks.Tracks)},
+				"CanEditTracks": {get: getter(tracks.CanEditTracks)},
+			},
+		},
+	}
+}
+
+func (p *properties) lookup(iface, name string) (property, *dbus.Error) {
+	props, ok := p.ifaces[iface]
+	if !ok {
+		return property{}, prop.ErrIfaceNotFound
+	}
+	pr, ok := props[name]
+	if !ok {
+		return property{}, prop.ErrPropNotFound
+	}
+	return pr, nil
+}
+
+// Get implements org.freedesktop.DBus.Properties.Get.
+func (p *properties) Get(iface, name string) (dbus.Variant, *dbus.Error) {
+	pr, dbusErr := p.lookup(iface, name)
+	if dbusErr != nil {
+		return dbus.Variant{}, dbusErr
+	}
+	v, err := pr.get()
+	if err != nil {
+		return dbus.Variant{}, dbus.MakeFailedError(err)
+	}
+	return dbus.MakeVariant(v), nil
+}
+
+// GetAll implements org.freedesktop.DBus.Properties.GetAll.
+func (p *properties) GetAll(iface string) (map[string]dbus.Variant, *dbus.Error) {
+	props, ok := p.ifaces[iface]
+	if !ok {
+		return nil, prop.ErrIfaceNotFound
+	}
+	all := make(map[string]dbus.Variant, len(props))
+	for name, pr := range props {
+		v, err := pr.get()
+		if err != nil {
+			return nil, dbus.MakeFailedError(err)
+		}
+		all[name] = dbus.MakeVariant(v)
+	}
+	return all, nil
+}
+
+// Set implements org.freedesktop.DBus.Properties.Set, telling clients the
+// resulting value.
+func (p *properties) Set(iface, name string, value dbus.Variant) *dbus.Error {
+	pr, dbusErr := p.lookup(iface, name)
+	if dbusErr != nil {
+		return dbusErr
+	}
+	if pr.set == nil {
+		return prop.ErrReadOnly
+	}
+	if err := pr.set(value); err != nil {
+		return dbus.MakeFailedError(err)
+	}
+	v, err := pr.get()
+	if err != nil {
+		return dbus.MakeFailedError(err)
+	}
+	if err := p.emitChanged(iface, map[string]dbus.Variant{name: dbus.MakeVariant(v)}, nil); err != nil {
+		return dbus.MakeFailedError(err)
+	}
+	return nil
+}
+
+// emitChanged emits PropertiesChanged for changed and invalidated
+// properties of an interface.
+func (p *properties) emitChanged(iface string, changed map[string]dbus.Variant, invalidated []string) error {
+	if p.conn == nil {
+		return nil
+	}
+	if invalidated == nil {
+		invalidated = []string{}
+	}
+	return p.conn.Emit(objectPath, propertiesChanged, iface, changed, invalidated)
+}
+
+// dbusError converts an error of the adapters.
+func dbusError(err error) *dbus.Error {
+	if err == nil {
+		return nil
+	}
+	return dbus.MakeFailedError(err)
+}
+
+// export exports the objects of the player on a connection.
+func export(conn *dbus.Conn, root *rootAdapter, player *playerAdapter, tracks *trackList, props *properties) error {
+	tables := map[string]map[string]any{
+		rootInterface: {
+			"Raise": func() *dbus.Error { return dbusError(root.Raise()) },
+			"Quit":  func() *dbus.Error { return dbusError(root.Quit()) },
+		},
+		playerInterface: {
+			"Next":      func() *dbus.Error { return dbusError(player.Next()) },
+			"Previous":  func() *dbus.Error { return dbusError(player.Previous()) },
+			"Pause":     func() *dbus.Error { return dbusError(player.Pause()) },
+			"PlayPause": func() *dbus.Error { return dbusError(player.PlayPause()) },
+			"Stop":      func() *dbus.Error { return dbusError(player.Stop()) },
+			"Play":      func() *dbus.Error { return dbusError(player.Play()) },
+			"Seek": func(offset int64) *dbus.Error {
+				return dbusError(player.Seek(types.Microseconds(offset)))
+			},
+			"SetPosition": func(trackID dbus.ObjectPath, position int64) *dbus.Error {
+				return dbusError(player.SetPosition(string(trackID), types.Microseconds(position)))
+			},
+			"OpenUri": func(uri string) *dbus.Error { return dbusError(player.OpenUri(uri)) },
+		},
+		trackListInterface: {
+			"GetTracksMetadata": tracks.GetTracksMetadata,
+			"AddTrack":          tracks.AddTrack,
+			"RemoveTrack":       tracks.RemoveTrack,
+			"GoTo":              tracks.GoTo,
+		},
+		"org.freedesktop.DBus.Properties": {
+			"Get":    props.Get,
+			"GetAll": props.GetAll,
+			"Set":    props.Set,
+		},
+		"org.freedesktop.DBus.Introspectable": {
+			"Introspect": introspect.Introspectable(introspection).Introspect,
+		},
+	}
+	for iface, table := range tables {
+		if err := conn.ExportMethodTable(table, objectPath, iface); err != nil {
+			return err
+		}
+	}
+	return nil
+}
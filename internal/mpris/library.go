@@ -0,0 +1,16 @@
+package mpris
+
+import "github.com/llehouerou/waves/internal/library"
+
+// Library is the part of the library used by the adapter.
+type Library interface {
+	Ratings
+	// TrackByPath returns the library track of a file, to describe the
+	// files opened by clients.
+	TrackByPath(path string) (*library.Track, error)
+}
+
+// VolumeStore persists volume changes.
+type VolumeStore interface {
+	SaveVolume(volume float64, muted bool) error
+}
@@ -13,7 +13,10 @@ import (
 	"github.com/quarckster/go-mpris-server/pkg/server"
 	"github.com/quarckster/go-mpris-server/pkg/types"
 
+	"github.com/llehouerou/waves/internal/icy"
 	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/tags"
 )
 
 // Adapter connects PlaybackService to MPRIS over D-Bus.
@@ -22,12 +25,18 @@ type Adapter struct {
 	server     *server.Server
 	sub        *playback.Subscription
 	evtHandler *events.EventHandler
+	props      *properties
 	done       chan struct{}
 	loopStop   chan struct{}
 }
 
-// New creates and starts a new MPRIS adapter.
-func New(service playback.Service) (*Adapter, error) {
+// busName is the D-Bus name of the player.
+const busName = "org.mpris.MediaPlayer2.waves"
+
+// New creates and starts a new MPRIS adapter. lib gives the rating of the
+// tracks and describes the files opened by clients, if not nil; volume
+// changes are saved to volumes, if not nil.
+func New(service playback.Service, lib Library, volumes VolumeStore) (*Adapter, error) {
 	a := &Adapter{
 		service: service,
 		done:    make(chan struct{}),
@@ -35,19 +44,37 @@ func New(service playback.Service) (*Adapter, error) {
 
 	// Create adapters that delegate to the service
 	rootAdapter := &rootAdapter{}
-	playerAdapter := &playerAdapter{service: service}
+	playerAdapter := &playerAdapter{service: service, ratings: lib, lib: lib, volumes: volumes}
+	tracks := &trackList{player: playerAdapter}
 
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, err
+	}
+	reply, err := conn.RequestName(busName, dbus.NameFlagReplaceExisting)
+	if err != nil {
+		return nil, err
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		return nil, fmt.Errorf("unable to claim %s", busName)
+	}
+
+	// The server only holds the connection for the event handler and
+	// releases the name on Stop; the objects are exported here.
 	a.server = server.NewServer("waves", rootAdapter, playerAdapter)
+	a.server.Conn = conn
+	a.props = newProperties(conn, rootAdapter, playerAdapter, tracks)
+	if err := export(conn, rootAdapter, playerAdapter, tracks, a.props); err != nil {
+		_ = a.server.Stop()
+		return nil, err
+	}
+	_ = exportSleepTimer(conn, playerAdapter)
+
 	a.evtHandler = events.NewEventHandler(a.server)
 	a.sub = service.Subscribe()
 	a.loopStop = make(chan struct{})
 
-	// Start the server in background
-	go func() {
-		_ = a.server.Listen()
-	}()
-
-	go a.runEventLoop(a.sub, a.loopStop)
+	go a.runEventLoop(service, a.sub, a.loopStop)
 
 	// Emit initial state after a delay so MPRIS clients have time
 	// to subscribe to signals after detecting the new player.
@@ -71,7 +98,13 @@ func (a *Adapter) Resubscribe(service playback.Service) {
 		pa.service = service
 	}
 
-	go a.runEventLoop(a.sub, a.loopStop)
+	go a.runEventLoop(service, a.sub, a.loopStop)
+}
+
+// RatingChanged tells MPRIS clients that the metadata of the playing track
+// changed, after it was rated.
+func (a *Adapter) RatingChanged() {
+	_ = a.evtHandler.Player.OnTitle()
 }
 
 // Close stops the adapter and releases D-Bus resources.
@@ -95,8 +128,30 @@ func (a *Adapter) emitDelayedState() {
 	}
 }
 
+// poll is how often the title of a playing stream is checked, as it has
+// no events.
+const poll = 2 * time.Second
+
+// streamTitle returns the title announced by the playing stream, empty
+// when a file is playing.
+func streamTitle(service playback.Service) string {
+	if service.Seekable() {
+		return ""
+	}
+	info := service.TrackInfo()
+	if info == nil {
+		return ""
+	}
+	return info.Artist + " - " + info.Title
+}
+
 // runEventLoop reads playback events and emits D-Bus PropertiesChanged signals.
-func (a *Adapter) runEventLoop(sub *playback.Subscription, stop <-chan struct{}) {
+func (a *Adapter) runEventLoop(service playback.Service, sub *playback.Subscription, stop <-chan struct{}) {
+	// Stations announce new titles without a track change
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	var lastTitle string
+
 	for {
 		select {
 		case <-a.done:
@@ -117,10 +172,19 @@ func (a *Adapter) runEventLoop(sub *playback.Subscription, stop <-chan struct{})
 			_ = a.evtHandler.Player.OnSeek(types.Microseconds(pc.Position.Microseconds()))
 		case <-sub.ModeChanged:
 			_ = a.evtHandler.Player.OnOptions()
-		case <-sub.QueueChanged:
+			_ = a.evtHandler.Player.OnPlayback() // Includes Rate
+		case <-sub.VolumeChanged:
+			_ = a.evtHandler.Player.OnVolume()
+		case qc := <-sub.QueueChanged:
 			_ = a.evtHandler.Player.OnOptions()
+			_ = emitTrackListReplaced(a.server.Conn, a.props, qc)
 		case <-sub.Error:
 			// Drain error events to prevent buffer buildup
+		case <-ticker.C:
+			if title := streamTitle(service); title != lastTitle {
+				lastTitle = title
+				_ = a.evtHandler.Player.OnTitle()
+			}
 		}
 	}
 }
@@ -145,7 +209,7 @@ func (r *rootAdapter) CanRaise() (bool, error) {
 }
 
 func (r *rootAdapter) HasTrackList() (bool, error) {
-	return false, nil // Track list interface not implemented
+	return true, nil
 }
 
 func (r *rootAdapter) Identity() (string, error) {
@@ -154,7 +218,7 @@ func (r *rootAdapter) Identity() (string, error) {
 
 //nolint:revive // Method name required by interface.
 func (r *rootAdapter) SupportedUriSchemes() ([]string, error) {
-	return []string{"file"}, nil
+	return []string{"file", "http", "https"}, nil
 }
 
 func (r *rootAdapter) SupportedMimeTypes() ([]string, error) {
@@ -164,6 +228,9 @@ func (r *rootAdapter) SupportedMimeTypes() ([]string, error) {
 // playerAdapter implements OrgMprisMediaPlayer2PlayerAdapter and optional interfaces.
 type playerAdapter struct {
 	service playback.Service
+	ratings Ratings     // nil if ratings are not exposed
+	lib     Library     // nil if opened files are not looked up
+	volumes VolumeStore // nil if volume changes are not saved
 }
 
 func (p *playerAdapter) Next() error {
@@ -201,9 +268,21 @@ func (p *playerAdapter) SetPosition(_ string, position types.Microseconds) error
 	return p.service.SeekTo(time.Duration(position) * time.Microsecond)
 }
 
+// OpenUri queues the music files of a file:// URI of a file or folder, or
+// an HTTP stream, playing them when stopped.
+//
 //nolint:revive // Method name required by interface.
-func (p *playerAdapter) OpenUri(_ string) error {
-	return nil // Not supported
+func (p *playerAdapter) OpenUri(uri string) error {
+	tracks, err := p.resolveURI(uri)
+	if err != nil {
+		return err
+	}
+	first := p.service.QueueLen()
+	p.service.AddTracks(tracks...)
+	if p.service.IsStopped() {
+		return playIndex(p.service, first)
+	}
+	return nil
 }
 
 func (p *playerAdapter) PlaybackStatus() (types.PlaybackStatus, error) {
@@ -219,24 +298,57 @@ func (p *playerAdapter) PlaybackStatus() (types.PlaybackStatus, error) {
 }
 
 func (p *playerAdapter) Rate() (float64, error) {
-	return 1.0, nil
+	return p.service.Speed(), nil
 }
 
-func (p *playerAdapter) SetRate(_ float64) error {
-	return nil // Not supported
+// SetRate changes the playback speed. Per the MPRIS spec a rate of 0 pauses.
+func (p *playerAdapter) SetRate(rate float64) error {
+	if rate <= 0 {
+		return p.service.Pause()
+	}
+	p.service.SetSpeed(rate)
+	return nil
 }
 
 func (p *playerAdapter) Metadata() (types.Metadata, error) {
 	track := p.service.CurrentTrack()
 	if track == nil {
-		return types.Metadata{
-			TrackId: "/org/mpris/MediaPlayer2/TrackList/NoTrack",
-		}, nil
+		return types.Metadata{TrackId: noTrack}, nil
+	}
+
+	// The ID is the one of the track list, unless the track is not queued
+	id := currentTrackID(p.service)
+	if id == noTrack {
+		id = dbus.ObjectPath(formatTrackID(track.Path))
+	}
+	if icy.IsURL(track.Path) {
+		meta := streamMetadata(track, p.service.TrackInfo())
+		meta.TrackId = id
+		return meta, nil
+	}
+	return p.trackMetadata(track, id, p.service.Duration()), nil
+}
+
+// metadataMap returns the metadata of the playing track in its D-Bus form.
+func (p *playerAdapter) metadataMap() (any, error) {
+	meta, err := p.Metadata()
+	if err != nil {
+		return nil, err
+	}
+	return meta.MakeMap(), nil
+}
+
+// trackMetadata describes a track of the queue.
+func (p *playerAdapter) trackMetadata(track *playback.Track, id dbus.ObjectPath, length time.Duration) types.Metadata {
+	if icy.IsURL(track.Path) {
+		meta := streamMetadata(track, nil)
+		meta.TrackId = id
+		return meta
 	}
 
 	meta := types.Metadata{
-		TrackId:     dbus.ObjectPath(formatTrackID(track.Path)),
-		Length:      types.Microseconds(p.service.Duration().Microseconds()),
+		TrackId:     id,
+		Length:      types.Microseconds(length.Microseconds()),
 		Title:       track.Title,
 		Artist:      []string{track.Artist},
 		Album:       track.Album,
@@ -257,15 +369,60 @@ func (p *playerAdapter) Metadata() (types.Metadata, error) {
 		meta.ArtUrl = "file://" + artPath
 	}
 
-	return meta, nil
+	if p.ratings != nil && track.ID != 0 {
+		if rating, err := p.ratings.TrackRating(track.ID); err == nil {
+			meta.UserRating = userRating(rating)
+		}
+	}
+
+	return meta
+}
+
+// streamMetadata describes an HTTP stream. The title is the one the
+// station announces, once playing; streams have no length.
+func streamMetadata(track *playback.Track, info *tags.FileInfo) types.Metadata {
+	meta := types.Metadata{
+		TrackId: dbus.ObjectPath(formatTrackID(track.Path)),
+		Title:   track.Title,
+		Album:   track.Album,
+		Url:     track.Path,
+	}
+	if info != nil && info.Path == track.Path {
+		meta.Title = info.Title
+		meta.Album = info.Album
+		if info.Artist != "" {
+			meta.Artist = []string{info.Artist}
+		}
+	}
+	return meta
 }
 
+// Volume returns the volume of the player, 0 when muted.
 func (p *playerAdapter) Volume() (float64, error) {
-	return 1.0, nil // Volume control not exposed via service
+	return volume(p.service), nil
 }
 
-func (p *playerAdapter) SetVolume(_ float64) error {
-	return nil // Not supported
+func volume(service playback.Service) float64 {
+	pl := service.Player()
+	if pl == nil || pl.Muted() {
+		return 0
+	}
+	return pl.Volume()
+}
+
+// SetVolume sets the volume of the player, unmuting it like the volume
+// keys. Per the MPRIS spec volumes are clamped to 0..1.
+func (p *playerAdapter) SetVolume(v float64) error {
+	pl := p.service.Player()
+	if pl == nil {
+		return nil
+	}
+	p.service.SetVolume(max(0, min(1, v)))
+	p.service.SetMuted(false)
+	if p.volumes != nil {
+		return p.volumes.SaveVolume(pl.Volume(), pl.Muted())
+	}
+	return nil
 }
 
 func (p *playerAdapter) Position() (int64, error) {
@@ -273,11 +430,11 @@ func (p *playerAdapter) Position() (int64, error) {
 }
 
 func (p *playerAdapter) MinimumRate() (float64, error) {
-	return 1.0, nil
+	return player.MinSpeed, nil
 }
 
 func (p *playerAdapter) MaximumRate() (float64, error) {
-	return 1.0, nil
+	return player.MaxSpeed, nil
 }
 
 func (p *playerAdapter) CanGoNext() (bool, error) {
@@ -297,7 +454,7 @@ func (p *playerAdapter) CanPause() (bool, error) {
 }
 
 func (p *playerAdapter) CanSeek() (bool, error) {
-	return true, nil
+	return p.service.Seekable(), nil
 }
 
 func (p *playerAdapter) CanControl() (bool, error) {
@@ -18,18 +18,30 @@ import (
 type fakeService struct {
 	track    *playback.Track
 	duration time.Duration
+	speed    float64
+	paused   bool
+	live     bool
+	info     *tags.FileInfo
+	sleep    playback.SleepTimer
 }
 
 func (f *fakeService) CurrentTrack() *playback.Track { return f.track }
 
 func (f *fakeService) Duration() time.Duration { return f.duration }
 
+func (f *fakeService) Seekable() bool { return !f.live }
+
+func (f *fakeService) TrackInfo() *tags.FileInfo { return f.info }
+
 // Unused interface methods — stubs.
 func (f *fakeService) Play() error { return nil }
 
 func (f *fakeService) PlayPath(string) error { return nil }
 
-func (f *fakeService) Pause() error { return nil }
+func (f *fakeService) Pause() error {
+	f.paused = true
+	return nil
+}
 
 func (f *fakeService) Stop() error { return nil }
 
@@ -49,7 +61,10 @@ func (f *fakeService) QueueAdvance() *playback.Track { return nil }
 
 func (f *fakeService) QueueMoveTo(int) *playback.Track { return nil }
 
-func (f *fakeService) AddTracks(...playback.Track) {}
+func (f *fakeService) AddTracks(...playback.Track)         {}
+func (f *fakeService) InsertTracks(int, ...playback.Track) {}
+func (f *fakeService) RemoveTracks(...int)                 {}
+func (f *fakeService) MoveTracks([]int, int) bool          { return false }
 
 func (f *fakeService) ReplaceTracks(...playback.Track) *playback.Track { return nil }
 
@@ -65,8 +80,6 @@ func (f *fakeService) IsPaused() bool { return false }
 
 func (f *fakeService) Position() time.Duration { return 0 }
 
-func (f *fakeService) TrackInfo() *tags.FileInfo { return nil }
-
 func (f *fakeService) Player() player.Interface { return nil }
 
 func (f *fakeService) QueueTracks() []playback.Track { return nil }
@@ -81,6 +94,8 @@ func (f *fakeService) QueueHasNext() bool { return false }
 
 func (f *fakeService) QueuePeekNext() *playback.Track { return nil }
 
+func (f *fakeService) PreloadNext() (string, bool) { return "", false }
+
 func (f *fakeService) Undo() bool { return false }
 
 func (f *fakeService) Redo() bool { return false }
@@ -97,7 +112,29 @@ func (f *fakeService) SetShuffle(bool) {}
 
 func (f *fakeService) ToggleShuffle() bool { return false }
 
-func (f *fakeService) Subscribe() *playback.Subscription { return nil }
+func (f *fakeService) Speed() float64 { return f.speed }
+
+func (f *fakeService) SetSpeed(speed float64) { f.speed = speed }
+
+func (f *fakeService) SetVolume(float64) {}
+
+func (f *fakeService) SetMuted(bool) {}
+
+func (f *fakeService) SleepTimer() playback.SleepTimer { return f.sleep }
+
+func (f *fakeService) SetSleepTimer(mode playback.SleepMode, after time.Duration) {
+	f.sleep = playback.SleepTimer{Mode: mode, After: after, Remaining: after}
+}
+
+func (f *fakeService) Loop() playback.Loop                { return playback.Loop{} }
+func (f *fakeService) SetLoop(playback.Loop) error        { return nil }
+func (f *fakeService) ClearLoop()                         {}
+func (f *fakeService) Chapters() []tags.Chapter           { return nil }
+func (f *fakeService) NextChapter() error                 { return nil }
+func (f *fakeService) PreviousChapter() error             { return nil }
+func (f *fakeService) SetResumer(playback.Resumer)        {}
+func (f *fakeService) Subscribe() *playback.Subscription  { return nil }
+func (f *fakeService) Unsubscribe(*playback.Subscription) {}
 
 func (f *fakeService) Close() error { return nil }
 
@@ -164,6 +201,34 @@ func TestMetadata_WithTrack_SetsExtendedFields(t *testing.T) {
 	}
 }
 
+// fakeRatings is a Ratings stub keyed by track ID.
+type fakeRatings map[int64]int
+
+func (f fakeRatings) TrackRating(id int64) (int, error) { return f[id], nil }
+
+func TestMetadata_WithTrack_SetsUserRating(t *testing.T) {
+	svc := &fakeService{track: &playback.Track{ID: 7, Path: "/music/song.flac"}}
+	adapter := &playerAdapter{service: svc, ratings: fakeRatings{7: 7}}
+
+	meta, err := adapter.Metadata()
+	if err != nil {
+		t.Fatalf("Metadata() error = %v", err)
+	}
+	if meta.UserRating != 0.7 {
+		t.Errorf("UserRating = %v, want 0.7", meta.UserRating)
+	}
+
+	// Files outside the library have no rating
+	svc.track = &playback.Track{Path: "/tmp/song.flac"}
+	meta, err = adapter.Metadata()
+	if err != nil {
+		t.Fatalf("Metadata() error = %v", err)
+	}
+	if meta.UserRating != 0 {
+		t.Errorf("UserRating = %v, want 0", meta.UserRating)
+	}
+}
+
 func TestMetadata_WithTrack_UsesServiceDuration(t *testing.T) {
 	svc := &fakeService{
 		track:    &playback.Track{Path: "/music/song.flac"},
@@ -181,3 +246,102 @@ func TestMetadata_WithTrack_UsesServiceDuration(t *testing.T) {
 		t.Errorf("Length = %d, want %d", meta.Length, want)
 	}
 }
+
+func TestRate_DelegatesToService(t *testing.T) {
+	svc := &fakeService{speed: 1}
+	adapter := &playerAdapter{service: svc}
+
+	if err := adapter.SetRate(1.5); err != nil {
+		t.Fatalf("SetRate() error = %v", err)
+	}
+	rate, _ := adapter.Rate()
+	if rate != 1.5 {
+		t.Errorf("Rate() = %v, want 1.5", rate)
+	}
+
+	minRate, _ := adapter.MinimumRate()
+	maxRate, _ := adapter.MaximumRate()
+	if minRate != player.MinSpeed || maxRate != player.MaxSpeed {
+		t.Errorf("rate range = %v..%v, want %v..%v", minRate, maxRate, player.MinSpeed, player.MaxSpeed)
+	}
+}
+
+func TestSetRate_ZeroPauses(t *testing.T) {
+	svc := &fakeService{speed: 1}
+	adapter := &playerAdapter{service: svc}
+
+	if err := adapter.SetRate(0); err != nil {
+		t.Fatalf("SetRate() error = %v", err)
+	}
+	if !svc.paused {
+		t.Error("SetRate(0) did not pause")
+	}
+	if svc.speed != 1 {
+		t.Errorf("speed = %v, want unchanged 1", svc.speed)
+	}
+}
+
+func TestCanSeek_FollowsService(t *testing.T) {
+	svc := &fakeService{}
+	adapter := &playerAdapter{service: svc}
+
+	if canSeek, _ := adapter.CanSeek(); !canSeek {
+		t.Error("files should be seekable")
+	}
+	svc.live = true
+	if canSeek, _ := adapter.CanSeek(); canSeek {
+		t.Error("streams should not be seekable")
+	}
+}
+
+func TestMetadata_Stream_UsesAnnouncedTitle(t *testing.T) {
+	const url = "http://radio.example/live"
+	svc := &fakeService{
+		track: &playback.Track{Path: url, Title: "My Station"},
+		live:  true,
+	}
+	adapter := &playerAdapter{service: svc}
+
+	meta, err := adapter.Metadata()
+	if err != nil {
+		t.Fatalf("Metadata() error = %v", err)
+	}
+	if meta.Url != url || meta.Title != "My Station" || meta.Length != 0 {
+		t.Errorf("Metadata() = %+v, want the station before it plays", meta)
+	}
+
+	svc.info = &tags.FileInfo{Tag: tags.Tag{Path: url, Artist: "Artist", Title: "Song", Album: "Radio"}}
+	meta, err = adapter.Metadata()
+	if err != nil {
+		t.Fatalf("Metadata() error = %v", err)
+	}
+	if meta.Title != "Song" || len(meta.Artist) != 1 || meta.Artist[0] != "Artist" || meta.Album != "Radio" {
+		t.Errorf("Metadata() = %+v, want the announced title", meta)
+	}
+	if title := streamTitle(svc); title != "Artist - Song" {
+		t.Errorf("streamTitle() = %q", title)
+	}
+}
+
+func TestSleepTimer_SetsServiceTimer(t *testing.T) {
+	svc := &fakeService{}
+	st := &sleepTimer{player: &playerAdapter{service: svc}}
+
+	if err := st.Set(30); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	mode, seconds, err := st.Status()
+	if err != nil || mode != "after" || seconds != 30*60 {
+		t.Errorf("Status() = %q, %d, %v; want after, 1800", mode, seconds, err)
+	}
+
+	_ = st.EndOfAlbum()
+	if mode, _, _ := st.Status(); mode != "album" {
+		t.Errorf("Status() mode = %q after EndOfAlbum, want album", mode)
+	}
+
+	_ = st.Set(0)
+	if mode, _, _ := st.Status(); mode != "off" {
+		t.Errorf("Status() mode = %q after Set(0), want off", mode)
+	}
+}
@@ -0,0 +1,15 @@
+package mpris
+
+import "github.com/llehouerou/waves/internal/tags"
+
+// Ratings looks up the rating of library tracks.
+type Ratings interface {
+	// TrackRating returns the rating of a track, in half stars.
+	TrackRating(id int64) (int, error)
+}
+
+// userRating converts a rating in half stars to the 0 to 1 scale of the
+// xesam:userRating metadata.
+func userRating(halfStars int) float64 {
+	return float64(tags.ClampRating(halfStars)) / tags.MaxRating
+}
@@ -0,0 +1,91 @@
+//go:build linux || freebsd
+
+package mpris
+
+import (
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+
+	"github.com/llehouerou/waves/internal/playback"
+)
+
+// MPRIS has no sleep timer, so it has its own object on the bus name of
+// the player:
+//
+//	dbus-send --session --type=method_call --dest=org.mpris.MediaPlayer2.waves \
+//	  /io/github/llehouerou/Waves io.github.llehouerou.Waves.SleepTimer.Set uint32:30
+const (
+	sleepPath      = dbus.ObjectPath("/io/github/llehouerou/Waves")
+	sleepInterface = "io.github.llehouerou.Waves.SleepTimer"
+)
+
+// sleepTimer exposes the sleep timer of the playback service over D-Bus.
+type sleepTimer struct {
+	player *playerAdapter // Holds the current service, replaced on Resubscribe
+}
+
+// exportSleepTimer exports the sleep timer object on the session bus.
+func exportSleepTimer(conn *dbus.Conn, player *playerAdapter) error {
+	obj := &sleepTimer{player: player}
+	if err := conn.Export(obj, sleepPath, sleepInterface); err != nil {
+		return err
+	}
+	node := &introspect.Node{
+		Name: string(sleepPath),
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			{Name: sleepInterface, Methods: introspect.Methods(obj)},
+		},
+	}
+	return conn.Export(introspect.NewIntrospectable(node), sleepPath, "org.freedesktop.DBus.Introspectable")
+}
+
+// Set pauses playback after the given number of minutes. 0 cancels the timer.
+func (s *sleepTimer) Set(minutes uint32) *dbus.Error {
+	if minutes == 0 {
+		s.player.service.SetSleepTimer(playback.SleepOff, 0)
+		return nil
+	}
+	s.player.service.SetSleepTimer(playback.SleepAfter, time.Duration(minutes)*time.Minute)
+	return nil
+}
+
+// EndOfTrack pauses playback at the end of the current track.
+func (s *sleepTimer) EndOfTrack() *dbus.Error {
+	s.player.service.SetSleepTimer(playback.SleepEndOfTrack, 0)
+	return nil
+}
+
+// EndOfAlbum pauses playback at the end of the current album.
+func (s *sleepTimer) EndOfAlbum() *dbus.Error {
+	s.player.service.SetSleepTimer(playback.SleepEndOfAlbum, 0)
+	return nil
+}
+
+// Cancel cancels the sleep timer.
+func (s *sleepTimer) Cancel() *dbus.Error {
+	s.player.service.SetSleepTimer(playback.SleepOff, 0)
+	return nil
+}
+
+// Status returns the mode of the timer ("off", "after", "track" or
+// "album") and the seconds left, 0 when unknown.
+func (s *sleepTimer) Status() (mode string, seconds int64, err *dbus.Error) {
+	t := s.player.service.SleepTimer()
+	return sleepModeName(t.Mode), int64(t.Remaining.Seconds()), nil
+}
+
+func sleepModeName(mode playback.SleepMode) string {
+	switch mode {
+	case playback.SleepAfter:
+		return "after"
+	case playback.SleepEndOfTrack:
+		return "track"
+	case playback.SleepEndOfAlbum:
+		return "album"
+	case playback.SleepOff:
+	}
+	return "off"
+}
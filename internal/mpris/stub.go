@@ -8,13 +8,16 @@ import "github.com/llehouerou/waves/internal/playback"
 type Adapter struct{}
 
 // New returns a no-op adapter on non-Linux platforms.
-func New(_ playback.Service) (*Adapter, error) {
+func New(_ playback.Service, _ Library, _ VolumeStore) (*Adapter, error) {
 	return &Adapter{}, nil
 }
 
 // Resubscribe is a no-op on non-Linux platforms.
 func (a *Adapter) Resubscribe(_ playback.Service) {}
 
+// RatingChanged is a no-op on non-Linux platforms.
+func (a *Adapter) RatingChanged() {}
+
 // Close is a no-op on non-Linux platforms.
 func (a *Adapter) Close() error {
 	return nil
@@ -0,0 +1,191 @@
+//go:build linux || freebsd
+
+package mpris
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/llehouerou/waves/internal/icy"
+	"github.com/llehouerou/waves/internal/library"
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/playlist"
+)
+
+// noTrack is the ID standing for no track.
+const noTrack = dbus.ObjectPath("/org/mpris/MediaPlayer2/TrackList/NoTrack")
+
+// trackIDs returns the MPRIS IDs of the queue tracks: the hash of their
+// path, numbered from the second time a path is queued.
+func trackIDs(tracks []playback.Track) []dbus.ObjectPath {
+	seen := make(map[string]int, len(tracks))
+	ids := make([]dbus.ObjectPath, len(tracks))
+	for i, t := range tracks {
+		seen[t.Path]++
+		id := formatTrackID(t.Path)
+		if n := seen[t.Path]; n > 1 {
+			id += "_" + strconv.Itoa(n)
+		}
+		ids[i] = dbus.ObjectPath(id)
+	}
+	return ids
+}
+
+// trackList implements org.mpris.MediaPlayer2.TrackList over the queue.
+type trackList struct {
+	player *playerAdapter // Holds the current service, replaced on Resubscribe
+}
+
+// Tracks returns the IDs of the queue tracks.
+func (t *trackList) Tracks() ([]dbus.ObjectPath, error) {
+	return trackIDs(t.player.service.QueueTracks()), nil
+}
+
+func (t *trackList) CanEditTracks() (bool, error) {
+	return true, nil
+}
+
+// index returns the index in the queue of the track with an ID, -1 if
+// there is none.
+func (t *trackList) index(id dbus.ObjectPath) int {
+	for i, trackID := range trackIDs(t.player.service.QueueTracks()) {
+		if trackID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// GetTracksMetadata returns the metadata of the queue tracks with the IDs,
+// skipping unknown ones.
+func (t *trackList) GetTracksMetadata(ids []dbus.ObjectPath) ([]map[string]dbus.Variant, *dbus.Error) {
+	svc := t.player.service
+	tracks := svc.QueueTracks()
+	indices := make(map[dbus.ObjectPath]int, len(tracks))
+	for i, id := range trackIDs(tracks) {
+		indices[id] = i
+	}
+
+	metas := make([]map[string]dbus.Variant, 0, len(ids))
+	for _, id := range ids {
+		i, ok := indices[id]
+		if !ok {
+			continue
+		}
+		length := tracks[i].Duration
+		if i == svc.QueueCurrentIndex() {
+			length = svc.Duration()
+		}
+		meta := t.player.trackMetadata(&tracks[i], id, length)
+		metas = append(metas, meta.MakeMap())
+	}
+	return metas, nil
+}
+
+// AddTrack inserts the tracks of a URI after a track, or at the start for
+// NoTrack, playing the first one if setAsCurrent.
+func (t *trackList) AddTrack(uri string, after dbus.ObjectPath, setAsCurrent bool) *dbus.Error {
+	tracks, err := t.player.resolveURI(uri)
+	if err != nil {
+		return dbus.MakeFailedError(err)
+	}
+	index := 0
+	if after != noTrack {
+		i := t.index(after)
+		if i < 0 {
+			return dbus.MakeFailedError(fmt.Errorf("unknown track %s", after))
+		}
+		index = i + 1
+	}
+	svc := t.player.service
+	svc.InsertTracks(index, tracks...)
+	if setAsCurrent {
+		return dbusError(playIndex(svc, index))
+	}
+	return nil
+}
+
+// RemoveTrack removes a track from the queue. Unknown tracks are ignored.
+func (t *trackList) RemoveTrack(id dbus.ObjectPath) *dbus.Error {
+	if i := t.index(id); i >= 0 {
+		t.player.service.RemoveTracks(i)
+	}
+	return nil
+}
+
+// GoTo plays a track of the queue. Unknown tracks are ignored.
+func (t *trackList) GoTo(id dbus.ObjectPath) *dbus.Error {
+	i := t.index(id)
+	if i < 0 {
+		return nil
+	}
+	return dbusError(playIndex(t.player.service, i))
+}
+
+// playIndex plays the track of the queue at an index.
+func playIndex(svc playback.Service, index int) error {
+	if err := svc.JumpTo(index); err != nil {
+		return err
+	}
+	switch svc.State() {
+	case playback.StatePlaying:
+		return nil
+	case playback.StatePaused:
+		return svc.Toggle()
+	case playback.StateStopped:
+	}
+	return svc.Play()
+}
+
+// resolveURI returns the tracks of a URI: the music files of a file:// URI
+// of a file or folder, or an HTTP stream.
+func (p *playerAdapter) resolveURI(uri string) ([]playback.Track, error) {
+	if icy.IsURL(uri) {
+		return []playback.Track{{Path: uri, Title: uri}}, nil
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "file" {
+		return nil, fmt.Errorf("unsupported URI %s", uri)
+	}
+	var lookup func(string) (*library.Track, error)
+	if p.lib != nil {
+		lookup = p.lib.TrackByPath
+	}
+	tracks, err := playlist.CollectFromPaths([]string{u.Path}, lookup)
+	if err != nil {
+		return nil, err
+	}
+	if len(tracks) == 0 {
+		return nil, errors.New("no music files found")
+	}
+	return playback.TracksFromPlaylist(tracks), nil
+}
+
+// currentTrackID returns the ID of the current track, NoTrack if none.
+func currentTrackID(svc playback.Service) dbus.ObjectPath {
+	ids := trackIDs(svc.QueueTracks())
+	if i := svc.QueueCurrentIndex(); i >= 0 && i < len(ids) {
+		return ids[i]
+	}
+	return noTrack
+}
+
+// emitTrackListReplaced tells clients the queue changed.
+func emitTrackListReplaced(conn *dbus.Conn, props *properties, qc playback.QueueChange) error {
+	ids := trackIDs(qc.Tracks)
+	current := noTrack
+	if qc.Index >= 0 && qc.Index < len(ids) {
+		current = ids[qc.Index]
+	}
+	if err := conn.Emit(objectPath, trackListInterface+".TrackListReplaced", ids, current); err != nil {
+		return err
+	}
+	return props.emitChanged(trackListInterface, map[string]dbus.Variant{}, []string{"Tracks"})
+}
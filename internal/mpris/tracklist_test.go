@@ -0,0 +1,245 @@
+//go:build linux || freebsd
+
+package mpris
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/llehouerou/waves/internal/playback"
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/playlist"
+)
+
+func newTrackList(t *testing.T, paths ...string) (*trackList, *player.Mock) {
+	t.Helper()
+	p := player.NewMock()
+	svc := playback.New(p, playlist.NewQueue())
+	t.Cleanup(func() { _ = svc.Close() })
+	tracks := make([]playback.Track, len(paths))
+	for i, path := range paths {
+		tracks[i] = playback.Track{Path: path, Title: filepath.Base(path)}
+	}
+	svc.AddTracks(tracks...)
+	return &trackList{player: &playerAdapter{service: svc}}, p
+}
+
+func queuePaths(svc playback.Service) []string {
+	var paths []string
+	for _, t := range svc.QueueTracks() {
+		paths = append(paths, t.Path)
+	}
+	return paths
+}
+
+func equalPaths(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTrackIDs_NumbersRepeatedPaths(t *testing.T) {
+	ids := trackIDs([]playback.Track{{Path: "/a.mp3"}, {Path: "/b.mp3"}, {Path: "/a.mp3"}})
+
+	want := []dbus.ObjectPath{
+		dbus.ObjectPath(formatTrackID("/a.mp3")),
+		dbus.ObjectPath(formatTrackID("/b.mp3")),
+		dbus.ObjectPath(formatTrackID("/a.mp3") + "_2"),
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Errorf("ids[%d] = %s, want %s", i, ids[i], want[i])
+		}
+		if !ids[i].IsValid() {
+			t.Errorf("ids[%d] = %s is not a valid object path", i, ids[i])
+		}
+	}
+}
+
+func TestTrackList_GetTracksMetadata(t *testing.T) {
+	tl, _ := newTrackList(t, "/music/a.mp3", "/music/b.mp3")
+	ids, _ := tl.Tracks()
+
+	metas, dbusErr := tl.GetTracksMetadata([]dbus.ObjectPath{ids[1], "/unknown", ids[0]})
+	if dbusErr != nil {
+		t.Fatalf("GetTracksMetadata() error = %v", dbusErr)
+	}
+	if len(metas) != 2 {
+		t.Fatalf("got %d metadata, want 2", len(metas))
+	}
+	if got := metas[0]["mpris:trackid"].Value(); got != ids[1] {
+		t.Errorf("trackid = %v, want %s", got, ids[1])
+	}
+	if got := metas[0]["xesam:url"].Value(); got != "file:///music/b.mp3" {
+		t.Errorf("url = %v, want file:///music/b.mp3", got)
+	}
+}
+
+func TestTrackList_AddTrack(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "c.mp3")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tl, _ := newTrackList(t, "/music/a.mp3", "/music/b.mp3")
+	svc := tl.player.service
+	ids, _ := tl.Tracks()
+
+	if dbusErr := tl.AddTrack("file://"+path, ids[0], false); dbusErr != nil {
+		t.Fatalf("AddTrack() error = %v", dbusErr)
+	}
+	want := []string{"/music/a.mp3", path, "/music/b.mp3"}
+	if got := queuePaths(svc); !equalPaths(got, want) {
+		t.Errorf("queue = %v, want %v", got, want)
+	}
+
+	if dbusErr := tl.AddTrack("https://radio.example.com/stream", noTrack, true); dbusErr != nil {
+		t.Fatalf("AddTrack() error = %v", dbusErr)
+	}
+	if got := svc.QueueTracks()[0].Path; got != "https://radio.example.com/stream" {
+		t.Errorf("first track = %s, want the stream", got)
+	}
+	if svc.QueueCurrentIndex() != 0 || !svc.IsPlaying() {
+		t.Errorf("index = %d, playing = %v; want the stream playing", svc.QueueCurrentIndex(), svc.IsPlaying())
+	}
+
+	if dbusErr := tl.AddTrack("file://"+path, "/unknown", false); dbusErr == nil {
+		t.Error("AddTrack() after an unknown track succeeded")
+	}
+	if dbusErr := tl.AddTrack("ftp://example.com/a.mp3", noTrack, false); dbusErr == nil {
+		t.Error("AddTrack() of an unsupported URI succeeded")
+	}
+}
+
+func TestTrackList_RemoveTrack(t *testing.T) {
+	tl, _ := newTrackList(t, "/music/a.mp3", "/music/b.mp3", "/music/a.mp3")
+	ids, _ := tl.Tracks()
+
+	if dbusErr := tl.RemoveTrack(ids[2]); dbusErr != nil {
+		t.Fatalf("RemoveTrack() error = %v", dbusErr)
+	}
+	if dbusErr := tl.RemoveTrack("/unknown"); dbusErr != nil {
+		t.Fatalf("RemoveTrack() of an unknown track error = %v", dbusErr)
+	}
+	want := []string{"/music/a.mp3", "/music/b.mp3"}
+	if got := queuePaths(tl.player.service); !equalPaths(got, want) {
+		t.Errorf("queue = %v, want %v", got, want)
+	}
+}
+
+func TestTrackList_GoTo(t *testing.T) {
+	tl, _ := newTrackList(t, "/music/a.mp3", "/music/b.mp3")
+	svc := tl.player.service
+	ids, _ := tl.Tracks()
+
+	if dbusErr := tl.GoTo(ids[1]); dbusErr != nil {
+		t.Fatalf("GoTo() error = %v", dbusErr)
+	}
+	if svc.QueueCurrentIndex() != 1 || !svc.IsPlaying() {
+		t.Errorf("index = %d, playing = %v; want 1 playing", svc.QueueCurrentIndex(), svc.IsPlaying())
+	}
+
+	meta, err := tl.player.Metadata()
+	if err != nil {
+		t.Fatalf("Metadata() error = %v", err)
+	}
+	if meta.TrackId != ids[1] {
+		t.Errorf("Metadata().TrackId = %s, want %s", meta.TrackId, ids[1])
+	}
+}
+
+func TestOpenUri_QueuesFolderAndPlays(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"01.mp3", "02.flac", "cover.jpg"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tl, _ := newTrackList(t, "/music/a.mp3")
+	svc := tl.player.service
+
+	if err := tl.player.OpenUri("file://" + dir); err != nil {
+		t.Fatalf("OpenUri() error = %v", err)
+	}
+	want := []string{"/music/a.mp3", filepath.Join(dir, "01.mp3"), filepath.Join(dir, "02.flac")}
+	if got := queuePaths(svc); !equalPaths(got, want) {
+		t.Errorf("queue = %v, want %v", got, want)
+	}
+	if svc.QueueCurrentIndex() != 1 || !svc.IsPlaying() {
+		t.Errorf("index = %d, playing = %v; want the folder playing", svc.QueueCurrentIndex(), svc.IsPlaying())
+	}
+
+	if err := tl.player.OpenUri("file://" + filepath.Join(dir, "missing")); err == nil {
+		t.Error("OpenUri() of a missing file succeeded")
+	}
+}
+
+type fakeVolumes struct {
+	volume float64
+	muted  bool
+}
+
+func (f *fakeVolumes) SaveVolume(volume float64, muted bool) error {
+	f.volume, f.muted = volume, muted
+	return nil
+}
+
+func TestVolume_FollowsPlayer(t *testing.T) {
+	tl, p := newTrackList(t)
+	volumes := &fakeVolumes{}
+	adapter := tl.player
+	adapter.volumes = volumes
+
+	p.SetMuted(true)
+	if v, _ := adapter.Volume(); v != 0 {
+		t.Errorf("Volume() muted = %v, want 0", v)
+	}
+
+	if err := adapter.SetVolume(1.5); err != nil {
+		t.Fatalf("SetVolume() error = %v", err)
+	}
+	if p.Volume() != 1 || p.Muted() {
+		t.Errorf("player volume = %v, muted = %v; want 1 unmuted", p.Volume(), p.Muted())
+	}
+	if volumes.volume != 1 || volumes.muted {
+		t.Errorf("saved volume = %v, muted = %v; want 1 unmuted", volumes.volume, volumes.muted)
+	}
+	if v, _ := adapter.Volume(); v != 1 {
+		t.Errorf("Volume() = %v, want 1", v)
+	}
+}
+
+func TestProperties_TrackList(t *testing.T) {
+	tl, _ := newTrackList(t, "/music/a.mp3")
+	props := newProperties(nil, &rootAdapter{}, tl.player, tl)
+
+	v, dbusErr := props.Get(rootInterface, "HasTrackList")
+	if dbusErr != nil || v.Value() != true {
+		t.Errorf("HasTrackList = %v, %v; want true", v, dbusErr)
+	}
+	v, dbusErr = props.Get(trackListInterface, "Tracks")
+	if dbusErr != nil {
+		t.Fatalf("Get(Tracks) error = %v", dbusErr)
+	}
+	if ids, ok := v.Value().([]dbus.ObjectPath); !ok || len(ids) != 1 {
+		t.Errorf("Tracks = %v, want one track", v)
+	}
+	if dbusErr := props.Set(trackListInterface, "Tracks", dbus.MakeVariant([]dbus.ObjectPath{})); dbusErr == nil {
+		t.Error("Set(Tracks) succeeded on a read-only property")
+	}
+	if dbusErr := props.Set(playerInterface, "Volume", dbus.MakeVariant(0.5)); dbusErr != nil {
+		t.Errorf("Set(Volume) error = %v", dbusErr)
+	}
+	if v, _ := tl.player.Volume(); v != 0.5 {
+		t.Errorf("Volume() = %v, want 0.5", v)
+	}
+}
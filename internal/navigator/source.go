@@ -9,6 +9,7 @@ const (
 	IconArtist
 	IconAlbum
 	IconPlaylist
+	IconSmartPlaylist
 )
 
 // Node represents an item that can be displayed and potentially navigated into.
@@ -42,6 +43,13 @@ type TrackIDProvider interface {
 	TrackID() int64
 }
 
+// RatingProvider is an optional interface for nodes that can be rated.
+// Nodes implementing this show their rating next to their name.
+type RatingProvider interface {
+	// Rating returns the rating in half stars, or 0 if unrated.
+	Rating() int
+}
+
 // Source provides data and navigation logic for the navigator.
 type Source[T Node] interface {
 	// Root returns the root container node.
@@ -94,15 +94,13 @@ func (m Model[T]) renderColumn(
 
 func (m Model[T]) renderColumnItem(node T, idx, cursor, width int, colType columnType) string {
 	name := formatNodeName(node)
-	isFavorite := m.isNodeFavorite(node)
+	badge := m.nodeBadge(node)
+	badgeWidth := runewidth.StringWidth(badge)
 
-	favIcon := icons.Favorite()
-	favIconWidth := runewidth.StringWidth(favIcon)
-
-	// Reserve space for favorite icon if needed
+	// Reserve space for the rating and favorite icon if needed
 	maxNameWidth := width - 2 // 2 for prefix
-	if isFavorite {
-		maxNameWidth -= favIconWidth + 1 // +1 for space before icon
+	if badge != "" {
+		maxNameWidth -= badgeWidth + 1 // +1 for space before badge
 	}
 	name = render.Truncate(name, maxNameWidth)
 
@@ -113,18 +111,17 @@ func (m Model[T]) renderColumnItem(node T, idx, cursor, width int, colType colum
 
 	line := prefix + name
 
-	// Build the full line with padding and optional favorite icon
+	// Build the full line with padding and optional right-aligned badge
 	var fullLine string
-	if !isFavorite {
+	if badge == "" {
 		fullLine = render.Pad(line, width)
 	} else {
-		// Right-align the favorite icon
 		currentWidth := runewidth.StringWidth(line)
-		padding := width - currentWidth - favIconWidth
+		padding := width - currentWidth - badgeWidth
 		if padding > 0 {
-			fullLine = line + strings.Repeat(" ", padding) + favIcon
+			fullLine = line + strings.Repeat(" ", padding) + badge
 		} else {
-			fullLine = render.Pad(line, width-favIconWidth) + favIcon
+			fullLine = render.Pad(line, width-badgeWidth) + badge
 		}
 	}
 
@@ -132,6 +129,21 @@ func (m Model[T]) renderColumnItem(node T, idx, cursor, width int, colType colum
 	return m.styleColumnItem(fullLine, idx, cursor, colType)
 }
 
+// nodeBadge returns the rating and favorite icon shown at the right of a
+// node, or an empty string.
+func (m Model[T]) nodeBadge(node T) string {
+	var parts []string
+	if provider, ok := any(node).(RatingProvider); ok {
+		if stars := icons.Rating(provider.Rating()); stars != "" {
+			parts = append(parts, stars)
+		}
+	}
+	if m.isNodeFavorite(node) {
+		parts = append(parts, icons.Favorite())
+	}
+	return strings.Join(parts, " ")
+}
+
 func (m Model[T]) styleColumnItem(line string, idx, cursor int, colType columnType) string {
 	isCursor := idx == cursor && m.focused
 
@@ -160,6 +172,8 @@ func formatNodeName[T Node](node T) string {
 		return icons.FormatAudio(name)
 	case IconPlaylist:
 		return icons.FormatPlaylist(name)
+	case IconSmartPlaylist:
+		return icons.FormatSmartPlaylist(name)
 	}
 	return name
 }
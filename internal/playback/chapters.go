@@ -0,0 +1,65 @@
+package playback
+
+import (
+	"errors"
+	"time"
+
+	"github.com/llehouerou/waves/internal/tags"
+)
+
+// ErrNoChapter is returned by NextChapter and PreviousChapter when there
+// is no chapter to go to.
+var ErrNoChapter = errors.New("no chapter to go to")
+
+// chapterRestart is how far into a chapter PreviousChapter goes back to
+// its start rather than to the previous chapter, like previous track
+// buttons.
+const chapterRestart = 3 * time.Second
+
+// Chapters returns the chapters of the current track, nil if it has none.
+func (s *serviceImpl) Chapters() []tags.Chapter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.chaptersLocked()
+}
+
+func (s *serviceImpl) chaptersLocked() []tags.Chapter {
+	info := s.player.TrackInfo()
+	if info == nil {
+		return nil
+	}
+	return info.Chapters
+}
+
+// NextChapter seeks to the start of the next chapter.
+// Returns ErrNoChapter in the last chapter or when the track has none.
+func (s *serviceImpl) NextChapter() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chapters := s.chaptersLocked()
+	next := tags.ChapterAt(chapters, s.player.Position()) + 1
+	if next >= len(chapters) {
+		return ErrNoChapter
+	}
+	return s.seekToLocked(chapters[next].Start)
+}
+
+// PreviousChapter seeks to the start of the current chapter, or to the
+// previous chapter when close to the start of the current one.
+// Returns ErrNoChapter when the track has no chapters.
+func (s *serviceImpl) PreviousChapter() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chapters := s.chaptersLocked()
+	pos := s.player.Position()
+	i := tags.ChapterAt(chapters, pos)
+	if i < 0 {
+		return ErrNoChapter
+	}
+	if pos-chapters[i].Start < chapterRestart && i > 0 {
+		i--
+	}
+	return s.seekToLocked(chapters[i].Start)
+}
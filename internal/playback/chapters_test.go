@@ -0,0 +1,87 @@
+package playback
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/playlist"
+	"github.com/llehouerou/waves/internal/tags"
+)
+
+// newChapterService plays a track with three one-minute chapters.
+func newChapterService(t *testing.T) (Service, *player.Mock) {
+	t.Helper()
+	p := player.NewMock()
+	q := playlist.NewQueue()
+	q.Add(playlist.Track{Path: testSvcPathA})
+	q.JumpTo(0)
+	svc := New(p, q)
+	t.Cleanup(func() { svc.Close() })
+	_ = svc.Play()
+	p.SetTrackInfo(&tags.FileInfo{Chapters: []tags.Chapter{
+		{Title: "One", Start: 0, End: time.Minute},
+		{Title: "Two", Start: time.Minute, End: 2 * time.Minute},
+		{Title: "Three", Start: 2 * time.Minute, End: 3 * time.Minute},
+	}})
+	return svc, p
+}
+
+func TestService_NextChapter(t *testing.T) {
+	svc, p := newChapterService(t)
+	p.SetPosition(70 * time.Second)
+
+	if err := svc.NextChapter(); err != nil {
+		t.Fatalf("NextChapter() error = %v", err)
+	}
+	if seeks := p.SeekCalls(); len(seeks) != 1 || seeks[0] != 50*time.Second {
+		t.Errorf("SeekCalls() = %v, want [50s] to chapter three", seeks)
+	}
+
+	p.SetPosition(150 * time.Second)
+	if err := svc.NextChapter(); !errors.Is(err, ErrNoChapter) {
+		t.Errorf("NextChapter() in last chapter error = %v, want ErrNoChapter", err)
+	}
+}
+
+func TestService_PreviousChapter(t *testing.T) {
+	tests := []struct {
+		name string
+		pos  time.Duration
+		want time.Duration // Seek delta
+	}{
+		{"restarts current chapter", 90 * time.Second, -30 * time.Second},
+		{"goes to previous near start", 61 * time.Second, -61 * time.Second},
+		{"first chapter restarts", 2 * time.Second, -2 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, p := newChapterService(t)
+			p.SetPosition(tt.pos)
+
+			if err := svc.PreviousChapter(); err != nil {
+				t.Fatalf("PreviousChapter() error = %v", err)
+			}
+			if seeks := p.SeekCalls(); len(seeks) != 1 || seeks[0] != tt.want {
+				t.Errorf("SeekCalls() = %v, want [%v]", seeks, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_Chapters_NoneWithoutInfo(t *testing.T) {
+	p := player.NewMock()
+	svc := New(p, playlist.NewQueue())
+	defer svc.Close()
+
+	if got := svc.Chapters(); got != nil {
+		t.Errorf("Chapters() = %v, want nil", got)
+	}
+	if err := svc.NextChapter(); !errors.Is(err, ErrNoChapter) {
+		t.Errorf("NextChapter() error = %v, want ErrNoChapter", err)
+	}
+	if err := svc.PreviousChapter(); !errors.Is(err, ErrNoChapter) {
+		t.Errorf("PreviousChapter() error = %v, want ErrNoChapter", err)
+	}
+}
@@ -6,6 +6,7 @@ import "time"
 type StateChange struct {
 	Previous State
 	Current  State
+	Finished bool // Stopped because the last track of the queue played to its end
 }
 
 // TrackChange is emitted when playback starts on a different track.
@@ -29,6 +30,7 @@ type TrackChange struct {
 	Current       *Track
 	PreviousIndex int
 	Index         int
+	Finished      bool // The previous track played to its end rather than being skipped
 }
 
 // QueueChange is emitted when the queue contents change.
@@ -37,10 +39,21 @@ type QueueChange struct {
 	Index  int
 }
 
-// ModeChange is emitted when repeat or shuffle mode changes.
+// ModeChange is emitted when repeat mode, shuffle, playback speed, the
+// sleep timer or the A-B loop changes.
 type ModeChange struct {
 	RepeatMode RepeatMode
 	Shuffle    bool
+	Speed      float64
+	Sleep      SleepMode
+	Loop       Loop
+}
+
+// VolumeChange is emitted when the volume is set or the player is muted
+// or unmuted through the service.
+type VolumeChange struct {
+	Volume float64
+	Muted  bool
 }
 
 // PositionChange is emitted when a seek occurs.
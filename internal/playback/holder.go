@@ -0,0 +1,40 @@
+package playback
+
+import "sync"
+
+// Holder holds the service a server controls. The application recreates
+// the service once the saved queue is restored, and servers started before
+// move their clients to the new one when it is replaced.
+type Holder struct {
+	mu       sync.Mutex
+	svc      Service
+	replaced chan struct{} // Closed when svc is replaced
+}
+
+// NewHolder returns a holder of svc.
+func NewHolder(svc Service) *Holder {
+	return &Holder{svc: svc, replaced: make(chan struct{})}
+}
+
+// Set replaces the service, closing the channel Current returned.
+func (h *Holder) Set(svc Service) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.svc = svc
+	close(h.replaced)
+	h.replaced = make(chan struct{})
+}
+
+// Service returns the service.
+func (h *Holder) Service() Service {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.svc
+}
+
+// Current returns the service and a channel closed when it is replaced.
+func (h *Holder) Current() (Service, <-chan struct{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.svc, h.replaced
+}
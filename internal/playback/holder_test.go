@@ -0,0 +1,37 @@
+package playback
+
+import (
+	"testing"
+
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/playlist"
+)
+
+func TestHolder_Set(t *testing.T) {
+	first := New(player.NewMock(), playlist.NewQueue())
+	defer first.Close()
+	h := NewHolder(first)
+
+	svc, replaced := h.Current()
+	if svc != first || h.Service() != first {
+		t.Fatal("holder should hold the first service")
+	}
+
+	second := New(player.NewMock(), playlist.NewQueue())
+	defer second.Close()
+	h.Set(second)
+	select {
+	case <-replaced:
+	default:
+		t.Fatal("replacing the service should close the channel")
+	}
+	svc, replaced = h.Current()
+	if svc != second || h.Service() != second {
+		t.Error("holder should hold the second service")
+	}
+	select {
+	case <-replaced:
+		t.Error("channel of the current service is closed")
+	default:
+	}
+}
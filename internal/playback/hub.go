@@ -0,0 +1,115 @@
+package playback
+
+import (
+	"slices"
+	"sync"
+)
+
+// Hub sends events to subscriptions. Services use it to implement
+// Subscribe and Unsubscribe. The zero value is ready to use.
+type Hub struct {
+	mu     sync.RWMutex
+	subs   []*Subscription
+	closed bool
+}
+
+// Subscribe creates a new subscription. Subscriptions created once the hub
+// is closed are already closed.
+func (h *Hub) Subscribe() *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sub := newSubscription()
+	if h.closed {
+		sub.close()
+		return sub
+	}
+	h.subs = append(h.subs, sub)
+	return sub
+}
+
+// Unsubscribe stops sending events to a subscription and closes it.
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, other := range h.subs {
+		if other == sub {
+			h.subs = slices.Delete(h.subs, i, i+1)
+			sub.close()
+			return
+		}
+	}
+}
+
+// Close closes all subscriptions.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, sub := range h.subs {
+		sub.close()
+	}
+	h.subs = nil
+	h.closed = true
+}
+
+// SendState sends a state change to all subscriptions.
+func (h *Hub) SendState(e StateChange) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, sub := range h.subs {
+		sub.sendState(e)
+	}
+}
+
+// SendTrack sends a track change to all subscriptions.
+func (h *Hub) SendTrack(e TrackChange) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, sub := range h.subs {
+		sub.sendTrack(e)
+	}
+}
+
+// SendPosition sends a position change to all subscriptions.
+func (h *Hub) SendPosition(e PositionChange) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, sub := range h.subs {
+		sub.sendPosition(e.Position)
+	}
+}
+
+// SendQueue sends a queue change to all subscriptions.
+func (h *Hub) SendQueue(e QueueChange) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, sub := range h.subs {
+		sub.sendQueue(e)
+	}
+}
+
+// SendMode sends a mode change to all subscriptions.
+func (h *Hub) SendMode(e ModeChange) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, sub := range h.subs {
+		sub.sendMode(e)
+	}
+}
+
+// SendVolume sends a volume change to all subscriptions.
+func (h *Hub) SendVolume(e VolumeChange) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, sub := range h.subs {
+		sub.sendVolume(e)
+	}
+}
+
+// SendError sends an error to all subscriptions.
+func (h *Hub) SendError(e ErrorEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, sub := range h.subs {
+		sub.sendError(e)
+	}
+}
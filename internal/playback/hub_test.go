@@ -0,0 +1,55 @@
+package playback
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHub_SendsToSubscriptions(t *testing.T) {
+	var h Hub
+	a, b := h.Subscribe(), h.Subscribe()
+
+	h.SendTrack(TrackChange{Index: 3})
+	h.SendError(ErrorEvent{Operation: "play", Err: errors.New("boom")})
+	for _, sub := range []*Subscription{a, b} {
+		if e := <-sub.TrackChanged; e.Index != 3 {
+			t.Errorf("TrackChanged.Index = %d, want 3", e.Index)
+		}
+		if e := <-sub.Error; e.Operation != "play" {
+			t.Errorf("Error.Operation = %q, want play", e.Operation)
+		}
+	}
+
+	h.Unsubscribe(a)
+	select {
+	case <-a.Done:
+	default:
+		t.Error("unsubscribed subscription is not closed")
+	}
+	h.SendState(StateChange{Current: StatePlaying})
+	select {
+	case <-a.StateChanged:
+		t.Error("unsubscribed subscription received an event")
+	default:
+	}
+	if e := <-b.StateChanged; e.Current != StatePlaying {
+		t.Errorf("StateChanged.Current = %v, want Playing", e.Current)
+	}
+}
+
+func TestHub_Close(t *testing.T) {
+	var h Hub
+	sub := h.Subscribe()
+	h.Close()
+
+	select {
+	case <-sub.Done:
+	default:
+		t.Error("subscription is not closed")
+	}
+	select {
+	case <-h.Subscribe().Done:
+	default:
+		t.Error("subscription created after Close is not closed")
+	}
+}
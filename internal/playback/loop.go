@@ -0,0 +1,122 @@
+package playback
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidLoop is returned by SetLoop when B is not after A.
+var ErrInvalidLoop = errors.New("loop end must be after its start")
+
+// loopTick is how often the service checks the position when the player
+// can't loop the track itself.
+const loopTick = 50 * time.Millisecond
+
+// Loop is an A-B loop: playback seeks back to A when it reaches B.
+type Loop struct {
+	A time.Duration
+	B time.Duration
+}
+
+// Active returns true when the loop is set.
+func (l Loop) Active() bool {
+	return l.B > l.A
+}
+
+// loopState is the A-B loop of the service. Guarded by mu.
+type loopState struct {
+	loop Loop
+	stop chan struct{} // Stops the goroutine seeking back, nil when the player loops
+}
+
+// Loop returns the A-B loop of the current track.
+func (s *serviceImpl) Loop() Loop {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.loop.loop
+}
+
+// SetLoop loops the current track between l.A and l.B, until the track
+// changes. Returns ErrNotSeekable for streams and ErrInvalidLoop when B is
+// not after A.
+func (s *serviceImpl) SetLoop(l Loop) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.player.Seekable() {
+		return ErrNotSeekable
+	}
+	if !l.Active() || l.A < 0 {
+		return ErrInvalidLoop
+	}
+	if d := s.player.Duration(); d > 0 && l.B > d {
+		l.B = d
+	}
+
+	s.stopLoopLocked()
+	s.loop.loop = l
+	if !s.player.SetLoop(l.A, l.B) {
+		s.loop.stop = make(chan struct{})
+		go s.runLoop(s.loop.stop)
+	}
+	s.emitModeChange()
+	return nil
+}
+
+// ClearLoop clears the A-B loop.
+func (s *serviceImpl) ClearLoop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clearLoopLocked()
+}
+
+// runLoop seeks back to A whenever the position passes B, for players that
+// can't loop the track themselves.
+func (s *serviceImpl) runLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(loopTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.loopStep()
+		}
+	}
+}
+
+// loopStep seeks back to A if the position passed B.
+func (s *serviceImpl) loopStep() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l := s.loop.loop
+	if !l.Active() {
+		return
+	}
+	if pos := s.player.Position(); pos >= l.B {
+		s.player.Seek(l.A - pos)
+		s.emitPositionChange()
+	}
+}
+
+// stopLoopLocked stops the goroutine seeking back to A.
+// Must be called while holding mu.
+func (s *serviceImpl) stopLoopLocked() {
+	if s.loop.stop != nil {
+		close(s.loop.stop)
+	}
+	s.loop = loopState{}
+}
+
+// clearLoopLocked clears the A-B loop, in the player too, and notifies
+// subscribers if one was set. Must be called while holding mu.
+func (s *serviceImpl) clearLoopLocked() {
+	if !s.loop.loop.Active() {
+		return
+	}
+	s.stopLoopLocked()
+	s.player.SetLoop(0, 0)
+	s.emitModeChange()
+}
@@ -0,0 +1,93 @@
+package playback
+
+import (
+	"errors"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/playlist"
+)
+
+func TestService_SetLoop_SeeksBackToA(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		p := player.NewMock()
+		q := playlist.NewQueue()
+		q.Add(playlist.Track{Path: testSvcPathA})
+		q.JumpTo(0)
+		svc := New(p, q)
+		defer svc.Close()
+		_ = svc.Play()
+		p.SetDuration(3 * time.Minute)
+		p.SetPosition(20 * time.Second)
+		sub := svc.Subscribe()
+
+		l := Loop{A: 10 * time.Second, B: 30 * time.Second}
+		if err := svc.SetLoop(l); err != nil {
+			t.Fatalf("SetLoop() error = %v", err)
+		}
+		if e := <-sub.ModeChanged; e.Loop != l {
+			t.Errorf("ModeChange.Loop = %+v, want %+v", e.Loop, l)
+		}
+		if a, b := p.Loop(); a != l.A || b != l.B {
+			t.Errorf("player loop = %v-%v, want %v-%v", a, b, l.A, l.B)
+		}
+
+		time.Sleep(loopTick)
+		synctest.Wait()
+		if seeks := p.SeekCalls(); len(seeks) != 0 {
+			t.Fatalf("SeekCalls() = %v before B, want none", seeks)
+		}
+
+		p.SetPosition(30*time.Second + 10*time.Millisecond)
+		time.Sleep(loopTick)
+		synctest.Wait()
+		if seeks := p.SeekCalls(); len(seeks) != 1 || seeks[0] != -20*time.Second-10*time.Millisecond {
+			t.Errorf("SeekCalls() = %v, want back to A", seeks)
+		}
+	})
+}
+
+func TestService_SetLoop_Errors(t *testing.T) {
+	p := player.NewMock()
+	q := playlist.NewQueue()
+	q.Add(playlist.Track{Path: testSvcPathA})
+	q.JumpTo(0)
+	svc := New(p, q)
+	defer svc.Close()
+	_ = svc.Play()
+
+	if err := svc.SetLoop(Loop{A: time.Minute, B: time.Second}); !errors.Is(err, ErrInvalidLoop) {
+		t.Errorf("SetLoop(B before A) error = %v, want ErrInvalidLoop", err)
+	}
+	p.SetSeekable(false)
+	if err := svc.SetLoop(Loop{A: time.Second, B: time.Minute}); !errors.Is(err, ErrNotSeekable) {
+		t.Errorf("SetLoop() on a stream error = %v, want ErrNotSeekable", err)
+	}
+	if svc.Loop().Active() {
+		t.Error("no loop should be set")
+	}
+}
+
+func TestService_Loop_ClearedOnTrackChange(t *testing.T) {
+	p := player.NewMock()
+	q := playlist.NewQueue()
+	q.Add(playlist.Track{Path: testSvcPathA}, playlist.Track{Path: testSvcPathB})
+	q.JumpTo(0)
+	svc := New(p, q)
+	defer svc.Close()
+	_ = svc.Play()
+
+	if err := svc.SetLoop(Loop{A: time.Second, B: 2 * time.Second}); err != nil {
+		t.Fatalf("SetLoop() error = %v", err)
+	}
+	_ = svc.Next()
+
+	if svc.Loop().Active() {
+		t.Errorf("Loop() = %+v after Next, want cleared", svc.Loop())
+	}
+	if _, b := p.Loop(); b != 0 {
+		t.Error("player loop should be cleared")
+	}
+}
@@ -0,0 +1,126 @@
+package playback
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/playlist"
+)
+
+// writeTestWAV writes a 16-bit mono WAV file of the given number of frames.
+func writeTestWAV(t *testing.T, path string, rate, frames int) {
+	t.Helper()
+	data := make([]byte, frames*2)
+	for i := range frames {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(int16(i%64*256)))
+	}
+	h := []byte("RIFF")
+	h = binary.LittleEndian.AppendUint32(h, uint32(36+len(data)))
+	h = append(h, "WAVEfmt "...)
+	h = binary.LittleEndian.AppendUint32(h, 16)
+	h = binary.LittleEndian.AppendUint16(h, 1) // PCM
+	h = binary.LittleEndian.AppendUint16(h, 1) // Mono
+	h = binary.LittleEndian.AppendUint32(h, uint32(rate))
+	h = binary.LittleEndian.AppendUint32(h, uint32(rate*2))
+	h = binary.LittleEndian.AppendUint16(h, 2)
+	h = binary.LittleEndian.AppendUint16(h, 16)
+	h = append(h, "data"...)
+	h = binary.LittleEndian.AppendUint32(h, uint32(len(data)))
+	if err := os.WriteFile(path, append(h, data...), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestService_PlaysQueueThroughPCMOutput plays a whole queue with a real
+// player writing raw PCM to a file, without a sound card.
+func TestService_PlaysQueueThroughPCMOutput(t *testing.T) {
+	dir := t.TempDir()
+	pathA := filepath.Join(dir, "a.wav")
+	pathB := filepath.Join(dir, "b.wav")
+	// Long enough for the player to preload the next track
+	writeTestWAV(t, pathA, 8000, 6000)
+	writeTestWAV(t, pathB, 8000, 2000)
+
+	outPath := filepath.Join(dir, "out.pcm")
+	out, err := player.NewOutput(player.OutputConfig{Backend: player.OutputPCM, Path: outPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := player.NewWithOutput(out)
+	// At the rate of the tracks, so that they aren't resampled
+	p.SetOutputRate(player.OutputRateConfig{Rate: 8000})
+	svc := New(p, playlist.NewQueue())
+	defer svc.Close()
+	p.SetPreloadFunc(svc.PreloadNext)
+
+	svc.AddTracks(Track{Path: pathA}, Track{Path: pathB})
+	if err := svc.JumpTo(0); err != nil {
+		t.Fatalf("JumpTo() error = %v", err)
+	}
+	if err := svc.Play(); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for svc.QueueCurrentIndex() != 1 || !svc.IsStopped() {
+		if time.Now().After(deadline) {
+			t.Fatalf("queue did not finish: index %d, state %v", svc.QueueCurrentIndex(), svc.State())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Both tracks back to back, as 16-bit stereo
+	if want := (6000 + 2000) * 4; len(data) != want {
+		t.Errorf("output size = %d bytes, want %d", len(data), want)
+	}
+}
+
+// TestService_AdvancesAcrossRateChange plays tracks at different rates:
+// the output is reopened between them, so the player stops at the end of
+// the first track and the service starts the next one.
+func TestService_AdvancesAcrossRateChange(t *testing.T) {
+	dir := t.TempDir()
+	pathA := filepath.Join(dir, "a.wav")
+	pathB := filepath.Join(dir, "b.wav")
+	writeTestWAV(t, pathA, 8000, 800)
+	writeTestWAV(t, pathB, 16000, 1600)
+
+	out, err := player.NewOutput(player.OutputConfig{Backend: player.OutputWAV, Path: filepath.Join(dir, "out.wav")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := player.NewWithOutput(out)
+	defer p.Close()
+	svc := New(p, playlist.NewQueue())
+	defer svc.Close()
+
+	svc.AddTracks(Track{Path: pathA}, Track{Path: pathB})
+	if err := svc.JumpTo(0); err != nil {
+		t.Fatalf("JumpTo() error = %v", err)
+	}
+	if err := svc.Play(); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for svc.QueueCurrentIndex() != 1 || !svc.IsStopped() {
+		if time.Now().After(deadline) {
+			t.Fatalf("queue did not finish: index %d, state %v", svc.QueueCurrentIndex(), svc.State())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := p.OutputSampleRate(); got != 16000 {
+		t.Errorf("OutputSampleRate() = %d, want 16000", got)
+	}
+}
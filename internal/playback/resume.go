@@ -0,0 +1,70 @@
+package playback
+
+import (
+	"time"
+
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/tags"
+)
+
+// Resumer remembers where long tracks were left, so that they resume there
+// on the next play. Implemented by resume.Store.
+type Resumer interface {
+	// Save remembers the position a track was left at, if the track is
+	// one whose position is remembered.
+	Save(info *tags.FileInfo, pos time.Duration) error
+	// Forget forgets the position of a track.
+	Forget(path string) error
+	// Position returns where to resume a track, 0 to play it from the start.
+	Position(path string) (time.Duration, error)
+}
+
+// SetResumer sets where positions are remembered, nil to disable resuming.
+func (s *serviceImpl) SetResumer(r Resumer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resumer = r
+}
+
+// saveResumeLocked remembers the position of the track being left.
+// Must be called while holding mu.
+func (s *serviceImpl) saveResumeLocked() {
+	if s.resumer == nil || s.player.State() == player.Stopped || !s.player.Seekable() {
+		return
+	}
+	info := s.player.TrackInfo()
+	if info == nil {
+		return
+	}
+	if err := s.resumer.Save(info, s.player.Position()); err != nil {
+		s.emitError("resume_save", info.Path, err)
+	}
+}
+
+// forgetResumeLocked forgets the position of a track that finished.
+// Must be called while holding mu.
+func (s *serviceImpl) forgetResumeLocked(path string) {
+	if s.resumer == nil {
+		return
+	}
+	if err := s.resumer.Forget(path); err != nil {
+		s.emitError("resume_forget", path, err)
+	}
+}
+
+// resumeLocked seeks a track that just started to where it was left.
+// Must be called while holding mu.
+func (s *serviceImpl) resumeLocked(path string) {
+	if s.resumer == nil || !s.player.Seekable() {
+		return
+	}
+	pos, err := s.resumer.Position(path)
+	if err != nil {
+		s.emitError("resume", path, err)
+		return
+	}
+	if pos > 0 {
+		s.player.Seek(pos - s.player.Position())
+		s.emitPositionChange()
+	}
+}
@@ -0,0 +1,111 @@
+package playback
+
+import (
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/playlist"
+	"github.com/llehouerou/waves/internal/tags"
+)
+
+// fakeResumer remembers every position it is given.
+type fakeResumer struct {
+	positions map[string]time.Duration
+}
+
+func newFakeResumer() *fakeResumer {
+	return &fakeResumer{positions: make(map[string]time.Duration)}
+}
+
+func (r *fakeResumer) Save(info *tags.FileInfo, pos time.Duration) error {
+	r.positions[info.Path] = pos
+	return nil
+}
+
+func (r *fakeResumer) Forget(path string) error {
+	delete(r.positions, path)
+	return nil
+}
+
+func (r *fakeResumer) Position(path string) (time.Duration, error) {
+	return r.positions[path], nil
+}
+
+func trackInfo(path string) *tags.FileInfo {
+	info := &tags.FileInfo{}
+	info.Path = path
+	return info
+}
+
+func TestService_Resume_SavesOnTrackChangeAndResumes(t *testing.T) {
+	p := player.NewMock()
+	q := playlist.NewQueue()
+	q.Add(playlist.Track{Path: testSvcPathA}, playlist.Track{Path: testSvcPathB})
+	q.JumpTo(0)
+	svc := New(p, q)
+	defer svc.Close()
+	r := newFakeResumer()
+	svc.SetResumer(r)
+
+	_ = svc.Play()
+	p.SetTrackInfo(trackInfo(testSvcPathA))
+	p.SetPosition(25 * time.Minute)
+	_ = svc.Next()
+
+	if got := r.positions[testSvcPathA]; got != 25*time.Minute {
+		t.Fatalf("saved position = %v, want 25m", got)
+	}
+
+	p.SetTrackInfo(trackInfo(testSvcPathB))
+	p.SetPosition(0)
+	_ = svc.Previous()
+	seeks := p.SeekCalls()
+	if len(seeks) != 1 || seeks[0] != 25*time.Minute {
+		t.Errorf("SeekCalls() = %v, want resume at 25m", seeks)
+	}
+}
+
+func TestService_Resume_SavesOnStop(t *testing.T) {
+	p := player.NewMock()
+	q := playlist.NewQueue()
+	q.Add(playlist.Track{Path: testSvcPathA})
+	q.JumpTo(0)
+	svc := New(p, q)
+	defer svc.Close()
+	r := newFakeResumer()
+	svc.SetResumer(r)
+
+	_ = svc.Play()
+	p.SetTrackInfo(trackInfo(testSvcPathA))
+	p.SetPosition(90 * time.Second)
+	_ = svc.Stop()
+
+	if got := r.positions[testSvcPathA]; got != 90*time.Second {
+		t.Errorf("saved position = %v, want 90s", got)
+	}
+}
+
+func TestService_Resume_ForgetsFinishedTrack(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		p := player.NewMock()
+		q := playlist.NewQueue()
+		q.Add(playlist.Track{Path: testSvcPathA}, playlist.Track{Path: testSvcPathB})
+		q.JumpTo(0)
+		svc := New(p, q)
+		defer svc.Close()
+		r := newFakeResumer()
+		r.positions[testSvcPathA] = time.Minute
+		svc.SetResumer(r)
+		_ = svc.Play()
+		sub := svc.Subscribe()
+
+		p.SimulateFinished()
+		<-sub.TrackChanged
+
+		if _, ok := r.positions[testSvcPathA]; ok {
+			t.Error("finished track should be forgotten")
+		}
+	})
+}
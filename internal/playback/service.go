@@ -29,7 +29,10 @@ type Service interface {
 
 	// Queue manipulation
 	AddTracks(tracks ...Track)
-	ReplaceTracks(tracks ...Track) *Track // Returns track at index 0 or nil
+	InsertTracks(index int, tracks ...Track) // Insert before index, keeping the current track
+	ReplaceTracks(tracks ...Track) *Track    // Returns track at index 0 or nil
+	RemoveTracks(indices ...int)
+	MoveTracks(indices []int, delta int) bool // Move by delta positions, false if out of bounds
 	ClearQueue()
 
 	// State queries
@@ -39,6 +42,7 @@ type Service interface {
 	IsPaused() bool
 	Position() time.Duration
 	Duration() time.Duration
+	Seekable() bool // False for streams, which have no duration
 	CurrentTrack() *Track
 	TrackInfo() *tags.FileInfo
 	Player() player.Interface // Direct player access (for UI rendering)
@@ -50,6 +54,7 @@ type Service interface {
 	QueueIsEmpty() bool
 	QueueHasNext() bool
 	QueuePeekNext() *Track
+	PreloadNext() (path string, inAlbum bool) // Next track to pre-load, and whether it plays in album order
 
 	// Queue history
 	Undo() bool
@@ -63,8 +68,36 @@ type Service interface {
 	SetShuffle(enabled bool)
 	ToggleShuffle() bool
 
+	// Playback speed (player.MinSpeed to player.MaxSpeed, pitch preserved)
+	Speed() float64
+	SetSpeed(speed float64)
+
+	// Volume, read from the player. Changes made through the service are
+	// emitted as VolumeChange events.
+	SetVolume(level float64)
+	SetMuted(muted bool)
+
+	// Sleep timer: pauses after a duration, or at the end of the current
+	// track or album, fading the volume out over the last SleepFade
+	SleepTimer() SleepTimer
+	SetSleepTimer(mode SleepMode, after time.Duration)
+
+	// A-B loop of the current track, cleared when the track changes
+	Loop() Loop
+	SetLoop(l Loop) error
+	ClearLoop()
+
+	// Chapters of audiobooks and podcast episodes
+	Chapters() []tags.Chapter
+	NextChapter() error
+	PreviousChapter() error
+
+	// Resume positions of long tracks (nil disables)
+	SetResumer(r Resumer)
+
 	// Event subscription
 	Subscribe() *Subscription
+	Unsubscribe(sub *Subscription) // Closes the subscription
 
 	// Lifecycle
 	Close() error
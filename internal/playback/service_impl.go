@@ -3,6 +3,7 @@ package playback
 
 import (
 	"errors"
+	"slices"
 	"sync"
 	"time"
 
@@ -16,6 +17,7 @@ var (
 	ErrEmptyQueue     = errors.New("queue is empty")
 	ErrNoCurrentTrack = errors.New("no current track")
 	ErrInvalidIndex   = errors.New("invalid queue index")
+	ErrNotSeekable    = errors.New("track is not seekable")
 )
 
 // Verify serviceImpl implements Service at compile time.
@@ -34,8 +36,11 @@ type serviceImpl struct {
 	// Used alongside lastPlayedIndex to detect actual track changes.
 	lastPlayedPath string
 
-	subs   []*Subscription
-	subsMu sync.RWMutex
+	sleep   sleepState
+	loop    loopState
+	resumer Resumer // nil when positions aren't remembered
+
+	events Hub
 
 	done   chan struct{}
 	closed bool
@@ -108,6 +113,13 @@ func (s *serviceImpl) Duration() time.Duration {
 	return s.player.Duration()
 }
 
+// Seekable returns true if the current track can seek. Streams can't.
+func (s *serviceImpl) Seekable() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.player.Seekable()
+}
+
 // CurrentTrack returns the current track, or nil if none.
 func (s *serviceImpl) CurrentTrack() *Track {
 	s.mu.RLock()
@@ -187,6 +199,19 @@ func (s *serviceImpl) QueuePeekNext() *Track {
 	return &track
 }
 
+// PreloadNext returns the path of the next track for gapless playback, ""
+// if there is none, and whether it plays as part of an album in order,
+// which selects its gain before it starts.
+func (s *serviceImpl) PreloadNext() (path string, inAlbum bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	next := s.queue.PeekNext()
+	if next == nil {
+		return "", false
+	}
+	return next.Path, s.queue.NextInAlbumOrder()
+}
+
 // AddTracks adds tracks to the end of the queue.
 func (s *serviceImpl) AddTracks(tracks ...Track) {
 	s.mu.Lock()
@@ -196,6 +221,14 @@ func (s *serviceImpl) AddTracks(tracks ...Track) {
 	s.emitQueueChange()
 }
 
+// InsertTracks inserts tracks before index, keeping the current track.
+func (s *serviceImpl) InsertTracks(index int, tracks ...Track) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue.Insert(index, TracksToPlaylist(tracks)...)
+	s.emitQueueChange()
+}
+
 // ReplaceTracks replaces all tracks in the queue.
 // Returns the track at index 0 or nil if empty.
 func (s *serviceImpl) ReplaceTracks(tracks ...Track) *Track {
@@ -211,6 +244,36 @@ func (s *serviceImpl) ReplaceTracks(tracks ...Track) *Track {
 	return &result
 }
 
+// RemoveTracks removes the tracks at the given indices. Removing the
+// current track lets it play to its end, after which playback stops.
+func (s *serviceImpl) RemoveTracks(indices ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sorted := slices.Clone(indices)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+	removed := false
+	// From the end so that the remaining indices stay valid
+	for i := len(sorted) - 1; i >= 0; i-- {
+		removed = s.queue.RemoveAt(sorted[i]) || removed
+	}
+	if removed {
+		s.emitQueueChange()
+	}
+}
+
+// MoveTracks moves the tracks at the given indices by delta positions.
+// Returns false, moving nothing, if a track would go out of bounds.
+func (s *serviceImpl) MoveTracks(indices []int, delta int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, moved := s.queue.MoveIndices(indices, delta); !moved {
+		return false
+	}
+	s.emitQueueChange()
+	return true
+}
+
 // ClearQueue removes all tracks from the queue.
 func (s *serviceImpl) ClearQueue() {
 	s.mu.Lock()
@@ -289,11 +352,12 @@ func (s *serviceImpl) Shuffle() bool {
 
 // Subscribe creates a new event subscription.
 func (s *serviceImpl) Subscribe() *Subscription {
-	s.subsMu.Lock()
-	defer s.subsMu.Unlock()
-	sub := newSubscription()
-	s.subs = append(s.subs, sub)
-	return sub
+	return s.events.Subscribe()
+}
+
+// Unsubscribe stops sending events to a subscription and closes it.
+func (s *serviceImpl) Unsubscribe(sub *Subscription) {
+	s.events.Unsubscribe(sub)
 }
 
 // Close shuts down the service.
@@ -304,16 +368,13 @@ func (s *serviceImpl) Close() error {
 		return nil
 	}
 	s.closed = true
+	s.saveResumeLocked()
+	s.clearSleepLocked()
+	s.stopLoopLocked()
 	close(s.done)
 	s.mu.Unlock()
 
-	s.subsMu.Lock()
-	for _, sub := range s.subs {
-		sub.close()
-	}
-	s.subs = nil
-	s.subsMu.Unlock()
-
+	s.events.Close()
 	return nil
 }
 
@@ -336,12 +397,21 @@ func (s *serviceImpl) handleTrackFinished() {
 
 	prevTrack := s.currentTrackLocked()
 	prevIndex := s.queue.CurrentIndex()
+	sleeps := s.sleepsAfterTrackLocked()
+	s.clearLoopLocked()
+	if prevTrack != nil {
+		s.forgetResumeLocked(prevTrack.Path)
+	}
 
 	nextTrack := s.queue.Next()
 	if nextTrack == nil {
 		// End of queue
 		s.player.Stop()
-		s.emitStateChange(StatePlaying, StateStopped)
+		s.emitState(StateChange{Previous: StatePlaying, Current: StateStopped, Finished: true})
+		if s.sleep.mode != SleepOff {
+			s.clearSleepLocked()
+			s.emitModeChange()
+		}
 		return
 	}
 
@@ -349,15 +419,26 @@ func (s *serviceImpl) handleTrackFinished() {
 	s.lastPlayedIndex = s.queue.CurrentIndex()
 	s.lastPlayedPath = nextTrack.Path
 
-	s.emitTrackChange(prevTrack, prevIndex)
+	s.emitTrackChange(prevTrack, prevIndex, true)
+
+	if sleeps {
+		if err := s.sleepOnTrackLocked(nextTrack.Path); err != nil {
+			s.player.Stop()
+			s.emitStateChange(StatePlaying, StateStopped)
+			s.emitError("play_next", nextTrack.Path, err)
+		}
+		return
+	}
 
 	// If player is still playing, this was a gapless transition.
-	// The player already started the next track, don't call Play() again.
+	// The player already started the next track, don't call Play() again;
+	// its gain was resolved when it was pre-loaded.
 	if s.player.State() == player.Playing {
+		s.resumeLocked(nextTrack.Path)
 		return
 	}
 
-	if err := s.player.Play(nextTrack.Path); err != nil {
+	if err := s.playCurrentLocked(nextTrack.Path); err != nil {
 		s.player.Stop()
 		s.emitStateChange(StatePlaying, StateStopped)
 		s.emitError("play_next", nextTrack.Path, err)
@@ -365,22 +446,24 @@ func (s *serviceImpl) handleTrackFinished() {
 }
 
 // emitStateChange notifies all subscribers of a state change.
-// Must be called while holding mu. Acquires subsMu internally.
+// Must be called while holding mu. Acquires the hub lock internally.
 func (s *serviceImpl) emitStateChange(prev, curr State) {
 	if prev == curr {
 		return
 	}
-	e := StateChange{Previous: prev, Current: curr}
-	s.subsMu.RLock()
-	for _, sub := range s.subs {
-		sub.sendState(e)
-	}
-	s.subsMu.RUnlock()
+	s.emitState(StateChange{Previous: prev, Current: curr})
 }
 
-// emitTrackChange notifies all subscribers of a track change.
-// Must be called while holding mu. Acquires subsMu internally.
-func (s *serviceImpl) emitTrackChange(prevTrack *Track, prevIndex int) {
+// emitState sends a state change event to all subscribers.
+// Must be called while holding mu. Acquires the hub lock internally.
+func (s *serviceImpl) emitState(e StateChange) {
+	s.events.SendState(e)
+}
+
+// emitTrackChange notifies all subscribers of a track change. finished is
+// true when the previous track played to its end.
+// Must be called while holding mu. Acquires the hub lock internally.
+func (s *serviceImpl) emitTrackChange(prevTrack *Track, prevIndex int, finished bool) {
 	curr := s.currentTrackLocked()
 	currIndex := s.queue.CurrentIndex()
 
@@ -402,41 +485,39 @@ func (s *serviceImpl) emitTrackChange(prevTrack *Track, prevIndex int) {
 		Current:       curr,
 		PreviousIndex: prevIndex,
 		Index:         currIndex,
+		Finished:      finished,
 	}
-	s.subsMu.RLock()
-	for _, sub := range s.subs {
-		sub.sendTrack(e)
-	}
-	s.subsMu.RUnlock()
+	s.events.SendTrack(e)
 }
 
 // emitPositionChange notifies all subscribers of a position change.
-// Must be called while holding mu. Acquires subsMu internally.
+// Must be called while holding mu. Acquires the hub lock internally.
 func (s *serviceImpl) emitPositionChange() {
 	pos := s.player.Position()
-	s.subsMu.RLock()
-	for _, sub := range s.subs {
-		sub.sendPosition(pos)
-	}
-	s.subsMu.RUnlock()
+	s.events.SendPosition(PositionChange{Position: pos})
 }
 
 // emitModeChange notifies all subscribers of a mode change.
-// Must be called while holding mu. Acquires subsMu internally.
+// Must be called while holding mu. Acquires the hub lock internally.
 func (s *serviceImpl) emitModeChange() {
 	e := ModeChange{
 		RepeatMode: RepeatMode(s.queue.RepeatMode()),
 		Shuffle:    s.queue.Shuffle(),
+		Speed:      s.player.Speed(),
+		Sleep:      s.sleep.mode,
+		Loop:       s.loop.loop,
 	}
-	s.subsMu.RLock()
-	for _, sub := range s.subs {
-		sub.sendMode(e)
-	}
-	s.subsMu.RUnlock()
+	s.events.SendMode(e)
+}
+
+// emitVolumeChange notifies all subscribers of a volume change.
+// Must be called while holding mu. Acquires the hub lock internally.
+func (s *serviceImpl) emitVolumeChange() {
+	s.events.SendVolume(VolumeChange{Volume: s.player.Volume(), Muted: s.player.Muted()})
 }
 
 // emitQueueChange notifies all subscribers of a queue content change.
-// Must be called while holding mu. Acquires subsMu internally.
+// Must be called while holding mu. Acquires the hub lock internally.
 func (s *serviceImpl) emitQueueChange() {
 	tracks := make([]Track, 0, len(s.queue.Tracks()))
 	for _, t := range s.queue.Tracks() {
@@ -446,26 +527,32 @@ func (s *serviceImpl) emitQueueChange() {
 		Tracks: tracks,
 		Index:  s.queue.CurrentIndex(),
 	}
-	s.subsMu.RLock()
-	for _, sub := range s.subs {
-		sub.sendQueue(e)
-	}
-	s.subsMu.RUnlock()
+	s.events.SendQueue(e)
 }
 
 // emitError notifies all subscribers of an error.
-// Must be called while holding mu. Acquires subsMu internally.
+// Must be called while holding mu. Acquires the hub lock internally.
 func (s *serviceImpl) emitError(operation, path string, err error) {
 	e := ErrorEvent{
 		Operation: operation,
 		Path:      path,
 		Err:       err,
 	}
-	s.subsMu.RLock()
-	for _, sub := range s.subs {
-		sub.sendError(e)
+	s.events.SendError(e)
+}
+
+// playCurrentLocked starts playback of the track at the current queue position,
+// telling the player whether it is part of an album played in order.
+// Must be called while holding mu.
+func (s *serviceImpl) playCurrentLocked(path string) error {
+	s.saveResumeLocked()
+	s.clearLoopLocked()
+	s.player.SetAlbumContext(s.queue.InAlbumOrder(s.queue.CurrentIndex()))
+	if err := s.player.Play(path); err != nil {
+		return err
 	}
-	s.subsMu.RUnlock()
+	s.resumeLocked(path)
+	return nil
 }
 
 // Play starts playback of the current track in the queue.
@@ -501,7 +588,7 @@ func (s *serviceImpl) Play() error {
 	}
 
 	prevState := s.playerStateToState(s.player.State())
-	if err := s.player.Play(track.Path); err != nil {
+	if err := s.playCurrentLocked(track.Path); err != nil {
 		return err
 	}
 
@@ -514,7 +601,7 @@ func (s *serviceImpl) Play() error {
 
 	// Emit TrackChange after state change if track actually changed
 	if trackChanged {
-		s.emitTrackChange(prevTrack, prevIndex)
+		s.emitTrackChange(prevTrack, prevIndex, false)
 	}
 
 	return nil
@@ -527,9 +614,13 @@ func (s *serviceImpl) PlayPath(path string) error {
 	defer s.mu.Unlock()
 
 	prevState := s.playerStateToState(s.player.State())
+	s.saveResumeLocked()
+	s.clearLoopLocked()
+	s.player.SetAlbumContext(false)
 	if err := s.player.Play(path); err != nil {
 		return err
 	}
+	s.resumeLocked(path)
 	currState := s.playerStateToState(s.player.State())
 	s.emitStateChange(prevState, currState)
 	return nil
@@ -561,6 +652,8 @@ func (s *serviceImpl) Stop() error {
 	}
 
 	prevState := s.playerStateToState(s.player.State())
+	s.saveResumeLocked()
+	s.clearLoopLocked()
 	s.player.Stop()
 	currState := s.playerStateToState(s.player.State())
 	s.emitStateChange(prevState, currState)
@@ -588,7 +681,7 @@ func (s *serviceImpl) Toggle() error {
 		if track == nil {
 			return ErrNoCurrentTrack
 		}
-		if err := s.player.Play(track.Path); err != nil {
+		if err := s.playCurrentLocked(track.Path); err != nil {
 			return err
 		}
 	}
@@ -626,10 +719,10 @@ func (s *serviceImpl) Next() error {
 	s.lastPlayedIndex = s.queue.CurrentIndex()
 	s.lastPlayedPath = nextTrack.Path
 
-	s.emitTrackChange(prevTrack, prevIndex)
+	s.emitTrackChange(prevTrack, prevIndex, false)
 
 	if wasActive {
-		if err := s.player.Play(nextTrack.Path); err != nil {
+		if err := s.playCurrentLocked(nextTrack.Path); err != nil {
 			return err
 		}
 	}
@@ -660,10 +753,10 @@ func (s *serviceImpl) Previous() error {
 		s.lastPlayedPath = newTrack.Path
 	}
 
-	s.emitTrackChange(prevTrack, prevIndex)
+	s.emitTrackChange(prevTrack, prevIndex, false)
 
 	if wasActive && newTrack != nil {
-		if err := s.player.Play(newTrack.Path); err != nil {
+		if err := s.playCurrentLocked(newTrack.Path); err != nil {
 			return err
 		}
 	}
@@ -671,18 +764,32 @@ func (s *serviceImpl) Previous() error {
 }
 
 // Seek adjusts the playback position by the given delta.
+// Returns ErrNotSeekable for streams.
 func (s *serviceImpl) Seek(delta time.Duration) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if s.player.State() != player.Stopped && !s.player.Seekable() {
+		return ErrNotSeekable
+	}
 	s.player.Seek(delta)
 	s.emitPositionChange()
 	return nil
 }
 
 // SeekTo seeks to an absolute position.
+// Returns ErrNotSeekable for streams.
 func (s *serviceImpl) SeekTo(position time.Duration) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.seekToLocked(position)
+}
+
+// seekToLocked seeks to an absolute position.
+// Must be called while holding mu.
+func (s *serviceImpl) seekToLocked(position time.Duration) error {
+	if s.player.State() != player.Stopped && !s.player.Seekable() {
+		return ErrNotSeekable
+	}
 	current := s.player.Position()
 	delta := position - current
 	s.player.Seek(delta)
@@ -715,10 +822,10 @@ func (s *serviceImpl) JumpTo(index int) error {
 		s.lastPlayedPath = newTrack.Path
 	}
 
-	s.emitTrackChange(prevTrack, prevIndex)
+	s.emitTrackChange(prevTrack, prevIndex, false)
 
 	if wasActive && newTrack != nil {
-		if err := s.player.Play(newTrack.Path); err != nil {
+		if err := s.playCurrentLocked(newTrack.Path); err != nil {
 			return err
 		}
 	}
@@ -758,3 +865,41 @@ func (s *serviceImpl) ToggleShuffle() bool {
 	s.emitModeChange()
 	return newState
 }
+
+// Speed returns the playback speed, 1 being normal speed.
+func (s *serviceImpl) Speed() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.player.Speed()
+}
+
+// SetSpeed sets the playback speed. Out of range values are clamped.
+func (s *serviceImpl) SetSpeed(speed float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if speed == s.player.Speed() {
+		return
+	}
+	s.player.SetSpeed(speed)
+	s.emitModeChange()
+}
+
+// SetVolume sets the volume of the player. During the fade of the sleep
+// timer, the fade continues from the new volume.
+func (s *serviceImpl) SetVolume(level float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.player.SetVolume(level)
+	if s.sleep.fading {
+		s.sleep.volume = s.player.Volume()
+	}
+	s.emitVolumeChange()
+}
+
+// SetMuted mutes or unmutes the player.
+func (s *serviceImpl) SetMuted(muted bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.player.SetMuted(muted)
+	s.emitVolumeChange()
+}
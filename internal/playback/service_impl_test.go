@@ -207,6 +207,99 @@ func TestService_Subscribe_ReturnsSubscription(t *testing.T) {
 	}
 }
 
+func TestService_Unsubscribe_ClosesSubscription(t *testing.T) {
+	p := player.NewMock()
+	q := playlist.NewQueue()
+	svc := New(p, q)
+	defer svc.Close()
+
+	sub := svc.Subscribe()
+	svc.Unsubscribe(sub)
+
+	select {
+	case <-sub.Done:
+	default:
+		t.Fatal("Done should be closed after Unsubscribe()")
+	}
+
+	svc.AddTracks(Track{Path: testSvcPathA})
+	select {
+	case <-sub.QueueChanged:
+		t.Error("unsubscribed subscription should not receive events")
+	default:
+	}
+}
+
+func TestService_InsertTracks(t *testing.T) {
+	p := player.NewMock()
+	q := playlist.NewQueue()
+	svc := New(p, q)
+	defer svc.Close()
+
+	svc.AddTracks(Track{Path: testSvcPathA}, Track{Path: testSvcPathB})
+	svc.QueueMoveTo(0)
+	sub := svc.Subscribe()
+
+	svc.InsertTracks(1, Track{Path: testSvcPathC})
+
+	tracks := svc.QueueTracks()
+	if len(tracks) != 3 || tracks[1].Path != testSvcPathC {
+		t.Fatalf("QueueTracks() = %v, want %s second", tracks, testSvcPathC)
+	}
+	if svc.QueueCurrentIndex() != 0 {
+		t.Errorf("QueueCurrentIndex() = %d, want 0", svc.QueueCurrentIndex())
+	}
+	select {
+	case e := <-sub.QueueChanged:
+		if len(e.Tracks) != 3 {
+			t.Errorf("QueueChange has %d tracks, want 3", len(e.Tracks))
+		}
+	default:
+		t.Error("InsertTracks() should emit QueueChange")
+	}
+}
+
+func TestService_RemoveAndMoveTracks(t *testing.T) {
+	p := player.NewMock()
+	q := playlist.NewQueue()
+	svc := New(p, q)
+	defer svc.Close()
+
+	svc.AddTracks(Track{Path: testSvcPathA}, Track{Path: testSvcPathB}, Track{Path: testSvcPathC},
+		Track{Path: testSvcPathTrack1}, Track{Path: testSvcPathTrack2})
+	svc.QueueMoveTo(2)
+
+	svc.RemoveTracks(3, 0, 3)
+	assertQueuePaths(t, svc, testSvcPathB, testSvcPathC, testSvcPathTrack2)
+	if svc.QueueCurrentIndex() != 1 {
+		t.Errorf("QueueCurrentIndex() = %d, want 1", svc.QueueCurrentIndex())
+	}
+
+	if !svc.MoveTracks([]int{1}, 1) {
+		t.Fatal("MoveTracks() = false, want true")
+	}
+	assertQueuePaths(t, svc, testSvcPathB, testSvcPathTrack2, testSvcPathC)
+	if svc.QueueCurrentIndex() != 2 {
+		t.Errorf("QueueCurrentIndex() = %d, want 2 (follows the moved track)", svc.QueueCurrentIndex())
+	}
+	if svc.MoveTracks([]int{2}, 1) {
+		t.Error("MoveTracks() past the end = true, want false")
+	}
+}
+
+func assertQueuePaths(t *testing.T, svc Service, want ...string) {
+	t.Helper()
+	tracks := svc.QueueTracks()
+	if len(tracks) != len(want) {
+		t.Fatalf("queue has %d tracks, want %d", len(tracks), len(want))
+	}
+	for i, path := range want {
+		if tracks[i].Path != path {
+			t.Errorf("track %d = %s, want %s", i, tracks[i].Path, path)
+		}
+	}
+}
+
 func TestService_Close_SignalsSubscribers(t *testing.T) {
 	synctest.Test(t, func(t *testing.T) {
 		p := player.NewMock()
@@ -534,6 +627,9 @@ func TestService_Next_AdvancesToNextTrack(t *testing.T) {
 		if e.Index != 1 {
 			t.Errorf("event.Index = %d, want 1", e.Index)
 		}
+		if e.Finished {
+			t.Error("event.Finished = true, want false for a skip")
+		}
 
 		// Verify player.Play was called with new track
 		calls := p.PlayCalls()
@@ -835,6 +931,29 @@ func TestService_SeekTo_SeeksToPosition(t *testing.T) {
 	})
 }
 
+func TestService_Seek_StreamNotSeekable(t *testing.T) {
+	p := player.NewMock()
+	q := playlist.NewQueue()
+	svc := New(p, q)
+	defer svc.Close()
+
+	p.SetState(player.Playing)
+	p.SetSeekable(false)
+
+	if svc.Seekable() {
+		t.Error("Seekable() = true, want false for a stream")
+	}
+	if err := svc.Seek(10 * time.Second); !errors.Is(err, ErrNotSeekable) {
+		t.Errorf("Seek() error = %v, want ErrNotSeekable", err)
+	}
+	if err := svc.SeekTo(time.Minute); !errors.Is(err, ErrNotSeekable) {
+		t.Errorf("SeekTo() error = %v, want ErrNotSeekable", err)
+	}
+	if calls := p.SeekCalls(); len(calls) != 0 {
+		t.Errorf("SeekCalls() = %v, want none", calls)
+	}
+}
+
 func TestService_SetRepeatMode_ChangesMode(t *testing.T) {
 	synctest.Test(t, func(t *testing.T) {
 		p := player.NewMock()
@@ -938,6 +1057,71 @@ func TestService_SetShuffle_ChangesShuffle(t *testing.T) {
 	})
 }
 
+func TestService_SetSpeed_ChangesSpeed(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		p := player.NewMock()
+		q := playlist.NewQueue()
+		svc := New(p, q)
+		defer svc.Close()
+		sub := svc.Subscribe()
+
+		if got := svc.Speed(); got != 1 {
+			t.Fatalf("initial Speed() = %v, want 1", got)
+		}
+
+		svc.SetSpeed(1.5)
+
+		if got := p.Speed(); got != 1.5 {
+			t.Errorf("player Speed() = %v, want 1.5", got)
+		}
+		e := <-sub.ModeChanged
+		if e.Speed != 1.5 {
+			t.Errorf("event.Speed = %v, want 1.5", e.Speed)
+		}
+
+		// Out of range values are clamped
+		svc.SetSpeed(4)
+		if got := svc.Speed(); got != player.MaxSpeed {
+			t.Errorf("Speed() = %v, want %v", got, player.MaxSpeed)
+		}
+		<-sub.ModeChanged
+
+		// Setting the same speed does not emit
+		svc.SetSpeed(player.MaxSpeed)
+		select {
+		case e := <-sub.ModeChanged:
+			t.Errorf("unexpected ModeChanged event: %+v", e)
+		default:
+		}
+	})
+}
+
+func TestService_SetVolume_EmitsVolumeChange(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		p := player.NewMock()
+		q := playlist.NewQueue()
+		svc := New(p, q)
+		defer svc.Close()
+		sub := svc.Subscribe()
+
+		svc.SetVolume(0.3)
+		if got := p.Volume(); got != 0.3 {
+			t.Errorf("player Volume() = %v, want 0.3", got)
+		}
+		if e := <-sub.VolumeChanged; e.Volume != 0.3 || e.Muted {
+			t.Errorf("event = %+v, want 0.3 unmuted", e)
+		}
+
+		svc.SetMuted(true)
+		if !p.Muted() {
+			t.Error("player should be muted")
+		}
+		if e := <-sub.VolumeChanged; e.Volume != 0.3 || !e.Muted {
+			t.Errorf("event = %+v, want 0.3 muted", e)
+		}
+	})
+}
+
 func TestService_ToggleShuffle_TogglesAndReturnsNewState(t *testing.T) {
 	synctest.Test(t, func(t *testing.T) {
 		p := player.NewMock()
@@ -1019,6 +1203,9 @@ func TestService_TrackFinished_AdvancesToNext(t *testing.T) {
 		if e.Previous == nil || e.Previous.Path != testSvcPathTrack1 {
 			t.Errorf("event.Previous.Path = %v, want %s", e.Previous, testSvcPathTrack1)
 		}
+		if !e.Finished {
+			t.Error("event.Finished = false, want true")
+		}
 
 		// With gapless playback, when player is still Playing during transition,
 		// Play() is NOT called again - the next track is already playing via gapless streamer.
@@ -1104,6 +1291,9 @@ func TestService_TrackFinished_AtEnd_Stops(t *testing.T) {
 		if e.Current != StateStopped {
 			t.Errorf("event.Current = %v, want Stopped", e.Current)
 		}
+		if !e.Finished {
+			t.Error("event.Finished = false, want true")
+		}
 
 		// Verify service state is stopped
 		if svc.State() != StateStopped {
@@ -1278,3 +1468,59 @@ func drainQueueChanges(sub *Subscription) {
 		}
 	}
 }
+
+func TestService_Play_SetsAlbumContext(t *testing.T) {
+	p := player.NewMock()
+	q := playlist.NewQueue()
+	q.Add(
+		playlist.Track{Path: "/album/01.flac", Artist: "A", Album: "Album", DiscNumber: 1, TrackNumber: 1},
+		playlist.Track{Path: "/album/02.flac", Artist: "A", Album: "Album", DiscNumber: 1, TrackNumber: 2},
+		playlist.Track{Path: testSvcPathA, Artist: "B", Album: "Other"},
+	)
+	q.JumpTo(0)
+	svc := New(p, q)
+	defer svc.Close()
+
+	if err := svc.Play(); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	if !p.AlbumContext() {
+		t.Error("album played in order should set album context")
+	}
+
+	if err := svc.JumpTo(2); err != nil {
+		t.Fatalf("JumpTo() error = %v", err)
+	}
+	if p.AlbumContext() {
+		t.Error("track outside an album sequence should clear album context")
+	}
+}
+
+func TestService_PreloadNext_ResolvesAlbumContext(t *testing.T) {
+	p := player.NewMock()
+	q := playlist.NewQueue()
+	q.Add(
+		playlist.Track{Path: "/album/01.flac", Artist: "A", Album: "Album", DiscNumber: 1, TrackNumber: 1},
+		playlist.Track{Path: "/album/02.flac", Artist: "A", Album: "Album", DiscNumber: 1, TrackNumber: 2},
+		playlist.Track{Path: testSvcPathA, Artist: "B", Album: "Other"},
+	)
+	q.JumpTo(0)
+	svc := New(p, q)
+	defer svc.Close()
+
+	path, inAlbum := svc.PreloadNext()
+	if path != "/album/02.flac" || !inAlbum {
+		t.Errorf("PreloadNext() = %q, %v, want /album/02.flac, true", path, inAlbum)
+	}
+
+	q.JumpTo(1)
+	path, inAlbum = svc.PreloadNext()
+	if path != testSvcPathA || inAlbum {
+		t.Errorf("PreloadNext() = %q, %v, want %q, false", path, inAlbum, testSvcPathA)
+	}
+
+	q.JumpTo(2)
+	if path, _ = svc.PreloadNext(); path != "" {
+		t.Errorf("PreloadNext() at the end = %q, want none", path)
+	}
+}
@@ -0,0 +1,231 @@
+package playback
+
+import (
+	"time"
+
+	"github.com/llehouerou/waves/internal/player"
+)
+
+// SleepFade is how long the sleep timer fades the volume out before it
+// pauses playback.
+const SleepFade = 45 * time.Second
+
+// sleepTick is how often the sleep timer updates the fade.
+const sleepTick = 250 * time.Millisecond
+
+// SleepMode defines when the sleep timer pauses playback.
+type SleepMode int
+
+const (
+	SleepOff        SleepMode = iota
+	SleepAfter                // After a duration
+	SleepEndOfTrack           // At the end of the current track
+	SleepEndOfAlbum           // At the end of the current album
+)
+
+// String returns the sleep mode name.
+func (m SleepMode) String() string {
+	switch m {
+	case SleepOff:
+		return "Off"
+	case SleepAfter:
+		return "After"
+	case SleepEndOfTrack:
+		return "End of track"
+	case SleepEndOfAlbum:
+		return "End of album"
+	default:
+		return "Unknown"
+	}
+}
+
+// SleepTimer is the state of the sleep timer.
+type SleepTimer struct {
+	Mode      SleepMode
+	After     time.Duration // Duration the timer was set to, for SleepAfter
+	Remaining time.Duration // Until playback pauses, 0 when unknown (streams)
+	Fading    bool          // The volume is fading out
+}
+
+// sleepState is the sleep timer of the service. Guarded by mu.
+type sleepState struct {
+	mode   SleepMode
+	after  time.Duration
+	at     time.Time     // When playback pauses, for SleepAfter
+	volume float64       // Volume before the fade, restored after it
+	fading bool          // The volume is being faded
+	stop   chan struct{} // Stops the timer goroutine
+}
+
+// SleepTimer returns the state of the sleep timer.
+func (s *serviceImpl) SleepTimer() SleepTimer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	remaining, _ := s.sleepRemainingLocked()
+	return SleepTimer{
+		Mode:      s.sleep.mode,
+		After:     s.sleep.after,
+		Remaining: remaining,
+		Fading:    s.sleep.fading,
+	}
+}
+
+// SetSleepTimer sets the sleep timer, replacing the one running. after is
+// the delay for SleepAfter, and ignored by the other modes. SleepOff
+// cancels the timer and restores the volume if it was fading.
+func (s *serviceImpl) SetSleepTimer(mode SleepMode, after time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.clearSleepLocked()
+	if mode == SleepAfter && after <= 0 {
+		mode = SleepOff
+	}
+	if mode != SleepOff {
+		s.sleep.mode = mode
+		if mode == SleepAfter {
+			s.sleep.after = after
+			s.sleep.at = time.Now().Add(after)
+		}
+		s.sleep.stop = make(chan struct{})
+		go s.runSleep(s.sleep.stop)
+	}
+	s.emitModeChange()
+}
+
+// runSleep fades the volume out as the sleep timer nears its end, and
+// pauses when a timer set for a duration is up. Timers set for the end of
+// a track or album are ended by handleTrackFinished.
+func (s *serviceImpl) runSleep(stop <-chan struct{}) {
+	ticker := time.NewTicker(sleepTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.sleepStep()
+		}
+	}
+}
+
+// sleepStep updates the fade, and pauses when the sleep timer is up.
+func (s *serviceImpl) sleepStep() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sleep.mode == SleepOff {
+		return
+	}
+	remaining, final := s.sleepRemainingLocked()
+	if s.sleep.mode == SleepAfter && remaining <= 0 {
+		prevState := s.playerStateToState(s.player.State())
+		if s.player.State() == player.Playing {
+			s.player.Pause()
+		}
+		s.clearSleepLocked()
+		s.emitStateChange(prevState, s.playerStateToState(s.player.State()))
+		s.emitModeChange()
+		return
+	}
+	if s.player.State() != player.Playing {
+		return
+	}
+
+	if !final || remaining > SleepFade {
+		// Skipped to a track further from the end
+		s.restoreSleepVolumeLocked()
+		return
+	}
+	if !s.sleep.fading {
+		s.sleep.volume = s.player.Volume()
+		s.sleep.fading = true
+	}
+	s.player.SetVolume(s.sleep.volume * float64(max(remaining, 0)) / float64(SleepFade))
+}
+
+// sleepRemainingLocked returns the time left until the sleep timer pauses
+// playback, and whether it ends within the current track, so the fade can
+// start. The time left is 0 when unknown, for streams.
+// Must be called while holding mu.
+func (s *serviceImpl) sleepRemainingLocked() (remaining time.Duration, final bool) {
+	switch s.sleep.mode {
+	case SleepOff:
+		return 0, false
+	case SleepAfter:
+		return max(time.Until(s.sleep.at), 0), true
+	case SleepEndOfTrack, SleepEndOfAlbum:
+	}
+
+	if !s.player.Seekable() || s.player.Duration() <= 0 {
+		return 0, false
+	}
+	remaining = s.player.Duration() - s.player.Position()
+	final = true
+	if s.sleep.mode == SleepEndOfAlbum {
+		for _, t := range s.queue.AlbumRest() {
+			remaining += t.Duration
+			final = false
+		}
+	}
+	if speed := s.player.Speed(); speed > 0 {
+		remaining = time.Duration(float64(remaining) / speed)
+	}
+	return max(remaining, 0), final
+}
+
+// sleepsAfterTrackLocked returns true if the sleep timer ends with the
+// current track. Must be called while holding mu, before the queue advances.
+func (s *serviceImpl) sleepsAfterTrackLocked() bool {
+	switch s.sleep.mode {
+	case SleepEndOfTrack:
+		return true
+	case SleepEndOfAlbum:
+		return len(s.queue.AlbumRest()) == 0
+	case SleepOff, SleepAfter:
+	}
+	return false
+}
+
+// sleepOnTrackLocked pauses at the start of the track the queue advanced
+// to, once the track the sleep timer waited for has finished. Resuming
+// plays that track from its start.
+// Must be called while holding mu.
+func (s *serviceImpl) sleepOnTrackLocked(path string) error {
+	// After a gapless transition the player already plays the track
+	if s.player.State() != player.Playing {
+		if err := s.playCurrentLocked(path); err != nil {
+			return err
+		}
+	}
+	s.player.Pause()
+	if pos := s.player.Position(); pos > 0 {
+		s.player.Seek(-pos)
+	}
+	s.resumeLocked(path)
+	s.clearSleepLocked()
+	s.emitStateChange(StatePlaying, StatePaused)
+	s.emitModeChange()
+	return nil
+}
+
+// restoreSleepVolumeLocked restores the volume the fade started from.
+// Must be called while holding mu.
+func (s *serviceImpl) restoreSleepVolumeLocked() {
+	if s.sleep.fading {
+		s.player.SetVolume(s.sleep.volume)
+		s.sleep.fading = false
+	}
+}
+
+// clearSleepLocked stops the sleep timer and restores the volume.
+// Must be called while holding mu.
+func (s *serviceImpl) clearSleepLocked() {
+	s.restoreSleepVolumeLocked()
+	if s.sleep.stop != nil {
+		close(s.sleep.stop)
+	}
+	s.sleep = sleepState{}
+}
@@ -0,0 +1,199 @@
+package playback
+
+import (
+	"math"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/llehouerou/waves/internal/player"
+	"github.com/llehouerou/waves/internal/playlist"
+)
+
+func TestService_SleepAfter_FadesThenPauses(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		p := player.NewMock()
+		q := playlist.NewQueue()
+		q.Add(playlist.Track{Path: testSvcPathA})
+		q.JumpTo(0)
+		svc := New(p, q)
+		defer svc.Close()
+		_ = svc.Play()
+		p.SetVolume(0.8)
+
+		svc.SetSleepTimer(SleepAfter, time.Minute)
+		if got := svc.SleepTimer(); got.Mode != SleepAfter || got.Remaining != time.Minute {
+			t.Fatalf("SleepTimer() = %+v, want a minute left", got)
+		}
+
+		time.Sleep(time.Minute - SleepFade/2)
+		synctest.Wait()
+		if !svc.SleepTimer().Fading {
+			t.Error("timer should be fading in its last seconds")
+		}
+		if v := p.Volume(); math.Abs(v-0.4) > 0.01 {
+			t.Errorf("Volume() = %v halfway through the fade, want 0.4", v)
+		}
+
+		time.Sleep(SleepFade/2 + sleepTick)
+		synctest.Wait()
+		if p.State() != player.Paused {
+			t.Errorf("State() = %v, want Paused", p.State())
+		}
+		if v := p.Volume(); v != 0.8 {
+			t.Errorf("Volume() = %v after the timer, want 0.8 restored", v)
+		}
+		if mode := svc.SleepTimer().Mode; mode != SleepOff {
+			t.Errorf("Mode = %v after the timer, want Off", mode)
+		}
+	})
+}
+
+func TestService_SleepTimer_CancelRestoresVolume(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		p := player.NewMock()
+		q := playlist.NewQueue()
+		q.Add(playlist.Track{Path: testSvcPathA})
+		q.JumpTo(0)
+		svc := New(p, q)
+		defer svc.Close()
+		_ = svc.Play()
+		p.SetVolume(0.5)
+		sub := svc.Subscribe()
+
+		svc.SetSleepTimer(SleepAfter, SleepFade/3)
+		if e := <-sub.ModeChanged; e.Sleep != SleepAfter {
+			t.Errorf("ModeChange.Sleep = %v, want After", e.Sleep)
+		}
+		time.Sleep(sleepTick)
+		synctest.Wait()
+		if p.Volume() >= 0.5 {
+			t.Fatal("volume should be fading")
+		}
+
+		svc.SetSleepTimer(SleepOff, 0)
+		if e := <-sub.ModeChanged; e.Sleep != SleepOff {
+			t.Errorf("ModeChange.Sleep = %v, want Off", e.Sleep)
+		}
+		if v := p.Volume(); v != 0.5 {
+			t.Errorf("Volume() = %v after cancelling, want 0.5 restored", v)
+		}
+		time.Sleep(SleepFade)
+		synctest.Wait()
+		if p.State() != player.Playing {
+			t.Errorf("State() = %v, want Playing after cancelling", p.State())
+		}
+	})
+}
+
+func TestService_SleepEndOfTrack_PausesOnNextTrack(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		p := player.NewMock()
+		q := playlist.NewQueue()
+		q.Add(playlist.Track{Path: testSvcPathA}, playlist.Track{Path: testSvcPathB})
+		q.JumpTo(0)
+		svc := New(p, q)
+		defer svc.Close()
+		_ = svc.Play()
+		p.SetDuration(3 * time.Minute)
+		p.SetPosition(3*time.Minute - SleepFade/3)
+
+		svc.SetSleepTimer(SleepEndOfTrack, 0)
+		if got := svc.SleepTimer().Remaining; got != SleepFade/3 {
+			t.Errorf("Remaining = %v, want %v", got, SleepFade/3)
+		}
+		time.Sleep(sleepTick)
+		synctest.Wait()
+		if v := p.Volume(); math.Abs(v-1.0/3) > 0.01 {
+			t.Errorf("Volume() = %v, want 1/3 with a third of the fade left", v)
+		}
+
+		// Gapless transition: the player plays the next track already
+		p.SetPosition(time.Second)
+		p.SimulateFinished()
+		time.Sleep(sleepTick)
+		synctest.Wait()
+
+		if p.State() != player.Paused {
+			t.Errorf("State() = %v, want Paused", p.State())
+		}
+		if idx := svc.QueueCurrentIndex(); idx != 1 {
+			t.Errorf("QueueCurrentIndex() = %d, want 1", idx)
+		}
+		if seeks := p.SeekCalls(); len(seeks) != 1 || seeks[0] != -time.Second {
+			t.Errorf("SeekCalls() = %v, want back to the start of the track", seeks)
+		}
+		if v := p.Volume(); v != 1 {
+			t.Errorf("Volume() = %v, want 1 restored", v)
+		}
+		if mode := svc.SleepTimer().Mode; mode != SleepOff {
+			t.Errorf("Mode = %v, want Off", mode)
+		}
+	})
+}
+
+func TestService_SleepEndOfAlbum_WaitsForLastTrack(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		p := player.NewMock()
+		q := playlist.NewQueue()
+		q.Add(
+			playlist.Track{Path: "/a/1.flac", Album: "A", TrackNumber: 1},
+			playlist.Track{Path: "/a/2.flac", Album: "A", TrackNumber: 2, Duration: time.Minute},
+			playlist.Track{Path: "/b/1.flac", Album: "B", TrackNumber: 1},
+		)
+		q.JumpTo(0)
+		svc := New(p, q)
+		defer svc.Close()
+		_ = svc.Play()
+		p.SetDuration(2 * time.Minute)
+		p.SetPosition(2*time.Minute - 10*time.Second)
+
+		svc.SetSleepTimer(SleepEndOfAlbum, 0)
+		if got := svc.SleepTimer().Remaining; got != 70*time.Second {
+			t.Errorf("Remaining = %v, want the rest of the album (70s)", got)
+		}
+		time.Sleep(sleepTick)
+		synctest.Wait()
+		if v := p.Volume(); v != 1 {
+			t.Errorf("Volume() = %v, want no fade before the last track", v)
+		}
+
+		p.SimulateFinished()
+		time.Sleep(sleepTick)
+		synctest.Wait()
+		if p.State() != player.Playing || svc.QueueCurrentIndex() != 1 {
+			t.Fatalf("should keep playing the album, state %v index %d", p.State(), svc.QueueCurrentIndex())
+		}
+
+		p.SimulateFinished()
+		time.Sleep(sleepTick)
+		synctest.Wait()
+		if p.State() != player.Paused || svc.QueueCurrentIndex() != 2 {
+			t.Errorf("should pause on the next album, state %v index %d", p.State(), svc.QueueCurrentIndex())
+		}
+	})
+}
+
+func TestService_SleepTimer_ClearedAtEndOfQueue(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		p := player.NewMock()
+		q := playlist.NewQueue()
+		q.Add(playlist.Track{Path: testSvcPathA})
+		q.JumpTo(0)
+		svc := New(p, q)
+		defer svc.Close()
+		_ = svc.Play()
+
+		svc.SetSleepTimer(SleepEndOfTrack, 0)
+		p.SimulateFinished()
+		time.Sleep(sleepTick)
+		synctest.Wait()
+
+		if p.State() != player.Stopped {
+			t.Errorf("State() = %v, want Stopped", p.State())
+		}
+		if mode := svc.SleepTimer().Mode; mode != SleepOff {
+			t.Errorf("Mode = %v, want Off", mode)
+		}
+	})
+}
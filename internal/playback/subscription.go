@@ -11,6 +11,7 @@ type Subscription struct {
 	PositionChanged <-chan PositionChange
 	QueueChanged    <-chan QueueChange
 	ModeChanged     <-chan ModeChange
+	VolumeChanged   <-chan VolumeChange
 	Error           <-chan ErrorEvent
 	Done            <-chan struct{}
 
@@ -20,6 +21,7 @@ type Subscription struct {
 	positionCh chan PositionChange
 	queueCh    chan QueueChange
 	modeCh     chan ModeChange
+	volumeCh   chan VolumeChange
 	errorCh    chan ErrorEvent
 	doneCh     chan struct{}
 }
@@ -32,6 +34,7 @@ func newSubscription() *Subscription {
 		positionCh: make(chan PositionChange, eventBufferSize),
 		queueCh:    make(chan QueueChange, eventBufferSize),
 		modeCh:     make(chan ModeChange, eventBufferSize),
+		volumeCh:   make(chan VolumeChange, eventBufferSize),
 		errorCh:    make(chan ErrorEvent, eventBufferSize),
 		doneCh:     make(chan struct{}),
 	}
@@ -40,6 +43,7 @@ func newSubscription() *Subscription {
 	s.PositionChanged = s.positionCh
 	s.QueueChanged = s.queueCh
 	s.ModeChanged = s.modeCh
+	s.VolumeChanged = s.volumeCh
 	s.Error = s.errorCh
 	s.Done = s.doneCh
 	return s
@@ -91,6 +95,14 @@ func (s *Subscription) sendMode(e ModeChange) {
 	}
 }
 
+// sendVolume sends a volume change event (non-blocking).
+func (s *Subscription) sendVolume(e VolumeChange) {
+	select {
+	case s.volumeCh <- e:
+	default:
+	}
+}
+
 // sendError sends an error event (non-blocking).
 func (s *Subscription) sendError(e ErrorEvent) {
 	select {
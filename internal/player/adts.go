@@ -0,0 +1,87 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/llehouerou/go-faad2"
+)
+
+// adtsDecoder decodes raw AAC in ADTS frames, as sent by radio stations.
+// The stream is read forward only: its length is unknown and it can't seek.
+type adtsDecoder struct {
+	reader   *faad2.ADTSReader
+	closer   io.Closer
+	channels int
+	pcm      []int16
+	position int
+	err      error
+}
+
+// decodeADTS decodes an ADTS stream into a beep streamer.
+func decodeADTS(rc io.ReadCloser) (beep.StreamSeekCloser, beep.Format, error) {
+	reader, err := faad2.OpenADTS(context.Background(), rc)
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+	channels := int(reader.Channels())
+	if channels < 1 || channels > 2 {
+		reader.Close(context.Background())
+		return nil, beep.Format{}, errors.New("aac: unsupported channel count")
+	}
+
+	format := beep.Format{
+		SampleRate:  beep.SampleRate(reader.SampleRate()),
+		NumChannels: 2, // Always output stereo
+		Precision:   2,
+	}
+	return &adtsDecoder{reader: reader, closer: rc, channels: channels}, format, nil
+}
+
+// Stream reads audio samples into the provided buffer.
+func (d *adtsDecoder) Stream(samples [][2]float64) (n int, ok bool) {
+	if d.err != nil {
+		return 0, false
+	}
+
+	if want := len(samples) * d.channels; cap(d.pcm) < want {
+		d.pcm = make([]int16, want)
+	}
+	pcm := d.pcm[:len(samples)*d.channels]
+	read, err := d.reader.Read(context.Background(), pcm)
+	for i := range read / d.channels {
+		if d.channels == 2 {
+			samples[i][0] = float64(pcm[2*i]) / 32768.0
+			samples[i][1] = float64(pcm[2*i+1]) / 32768.0
+		} else {
+			samples[i][0] = float64(pcm[i]) / 32768.0
+			samples[i][1] = samples[i][0]
+		}
+		n++
+	}
+	d.position += n
+	if err != nil && !errors.Is(err, io.EOF) {
+		d.err = err
+	}
+	return n, n > 0
+}
+
+// Err returns any error that occurred during streaming.
+func (d *adtsDecoder) Err() error { return d.err }
+
+// Len returns 0: the length of a stream is unknown.
+func (d *adtsDecoder) Len() int { return 0 }
+
+// Position returns the number of samples decoded.
+func (d *adtsDecoder) Position() int { return d.position }
+
+// Seek fails: streams are read forward only.
+func (d *adtsDecoder) Seek(int) error { return errNotSeekable }
+
+// Close releases the decoder and closes the stream.
+func (d *adtsDecoder) Close() error {
+	d.reader.Close(context.Background())
+	return d.closer.Close()
+}
@@ -0,0 +1,161 @@
+package player
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/gopxl/beep/v2"
+)
+
+// aiffHeader holds what is needed from the chunks of an AIFF file.
+type aiffHeader struct {
+	channels    int
+	frames      int64
+	bits        int
+	sampleRate  int
+	compression string // Always "NONE" for plain AIFF
+	dataStart   int64
+	dataSize    int64
+}
+
+// decodeAIFF decodes an AIFF or uncompressed AIFF-C file.
+func decodeAIFF(rc io.ReadSeekCloser) (beep.StreamSeekCloser, beep.Format, error) {
+	h, err := readAIFFHeader(rc)
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+
+	layout := pcmLayout{
+		channels:  h.channels,
+		width:     (h.bits + 7) / 8,
+		bigEndian: true,
+	}
+	switch h.compression {
+	case "NONE", "twos":
+	case "sowt":
+		layout.bigEndian = false
+	case "fl32", "FL32":
+		layout.float, layout.width = true, 4
+	case "fl64", "FL64":
+		layout.float, layout.width = true, 8
+	default:
+		return nil, beep.Format{}, fmt.Errorf("aiff: unsupported compression: %q", h.compression)
+	}
+
+	frameSize := layout.frameSize()
+	if frameSize == 0 {
+		return nil, beep.Format{}, errors.New("aiff: invalid sample size")
+	}
+	frames, err := framesIn(rc, h.dataStart, h.dataSize, frameSize)
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+	frames = min(frames, int(h.frames))
+	d, err := newPCMDecoder(rc, layout, h.dataStart, frames)
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+	return d, pcmFormat(h.sampleRate, layout), nil
+}
+
+// readAIFFHeader reads the COMM chunk and locates the SSND chunk.
+func readAIFFHeader(r io.ReadSeeker) (*aiffHeader, error) {
+	var form [12]byte
+	if _, err := io.ReadFull(r, form[:]); err != nil {
+		return nil, fmt.Errorf("aiff: read header: %w", err)
+	}
+	kind := string(form[8:12])
+	if string(form[0:4]) != "FORM" || (kind != "AIFF" && kind != "AIFC") {
+		return nil, errors.New("aiff: not an AIFF file")
+	}
+
+	h := &aiffHeader{compression: "NONE"}
+	var gotComm, gotData bool
+	offset := int64(12)
+	for !gotComm || !gotData {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			if !gotComm {
+				return nil, errors.New("aiff: no COMM chunk")
+			}
+			return nil, errors.New("aiff: no SSND chunk")
+		}
+		id := string(chunk[0:4])
+		size := int64(binary.BigEndian.Uint32(chunk[4:8]))
+		offset += 8
+
+		switch id {
+		case "COMM":
+			if err := h.parseComm(r, size, kind == "AIFC"); err != nil {
+				return nil, err
+			}
+			gotComm = true
+		case "SSND":
+			var ssnd [8]byte
+			if _, err := io.ReadFull(r, ssnd[:]); err != nil {
+				return nil, fmt.Errorf("aiff: read SSND chunk: %w", err)
+			}
+			// The data can be preceded by padding, for block alignment
+			pad := int64(binary.BigEndian.Uint32(ssnd[0:4]))
+			h.dataStart = offset + 8 + pad
+			h.dataSize = -1
+			if size >= 8+pad {
+				h.dataSize = size - 8 - pad
+			}
+			gotData = true
+		}
+
+		// Chunks are padded to an even size
+		offset += size + size&1
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// parseComm reads a COMM chunk of the given size.
+func (h *aiffHeader) parseComm(r io.Reader, size int64, aifc bool) error {
+	if size < 18 || (aifc && size < 22) {
+		return errors.New("aiff: invalid COMM chunk")
+	}
+	var buf [22]byte
+	n := 18
+	if aifc {
+		n = 22
+	}
+	if _, err := io.ReadFull(r, buf[:n]); err != nil {
+		return fmt.Errorf("aiff: read COMM chunk: %w", err)
+	}
+
+	h.channels = int(binary.BigEndian.Uint16(buf[0:2]))
+	h.frames = int64(binary.BigEndian.Uint32(buf[2:6]))
+	h.bits = int(binary.BigEndian.Uint16(buf[6:8]))
+	h.sampleRate = int(math.Round(extendedToFloat(buf[8:18])))
+	if aifc {
+		h.compression = string(buf[18:22])
+	}
+	if h.channels == 0 || h.sampleRate <= 0 {
+		return errors.New("aiff: invalid COMM chunk")
+	}
+	return nil
+}
+
+// extendedToFloat converts an 80-bit IEEE 754 extended precision number,
+// which AIFF uses for the sample rate.
+func extendedToFloat(b []byte) float64 {
+	exp := int(binary.BigEndian.Uint16(b[0:2]))
+	mantissa := binary.BigEndian.Uint64(b[2:10])
+	sign := 1.0
+	if exp&0x8000 != 0 {
+		sign = -1
+		exp &= 0x7FFF
+	}
+	if exp == 0 && mantissa == 0 {
+		return 0
+	}
+	return sign * math.Ldexp(float64(mantissa), exp-16383-63)
+}
@@ -1,24 +1,22 @@
 package player
 
-import (
-	"time"
-
-	"github.com/gopxl/beep/v2/speaker"
-)
+import "time"
 
 // Stop stops playback and releases resources.
 func (p *Player) Stop() {
-	if p.state == Stopped {
+	// A track that ended by itself is stopped but still holds resources
+	if p.State() == Stopped && p.current == nil {
 		return
 	}
 
-	// Stop monitor loop
+	// Stop monitor loop, and wait for it to leave the track alone
 	if p.monitorDone != nil {
 		close(p.monitorDone)
 		p.monitorDone = nil
 	}
+	p.monitor.Wait()
 
-	speaker.Clear()
+	p.out.Clear()
 
 	// Clean up current track
 	if p.current != nil {
@@ -32,9 +30,17 @@ func (p *Player) Stop() {
 		p.next = nil
 	}
 
+	// Clean up a track that was fading out
+	if p.fading != nil {
+		p.fading.Close()
+		p.fading = nil
+	}
+
 	p.gapless = nil
+	p.tempo = nil
 	p.ctrl = nil
-	p.state = Stopped
+	p.nextNeedsGap = false
+	p.setState(Stopped)
 
 	// Close done channel to unblock any waiters (safe to close already-closed channel
 	// is NOT safe in Go, so we use a select to check if it's already closed)
@@ -48,29 +54,29 @@ func (p *Player) Stop() {
 
 // Pause pauses playback.
 func (p *Player) Pause() {
-	if p.state != Playing || p.ctrl == nil {
+	if p.State() != Playing || p.ctrl == nil {
 		return
 	}
-	speaker.Lock()
+	p.out.Lock()
 	p.ctrl.Paused = true
-	speaker.Unlock()
-	p.state = Paused
+	p.out.Unlock()
+	p.setState(Paused)
 }
 
 // Resume resumes paused playback.
 func (p *Player) Resume() {
-	if p.state != Paused || p.ctrl == nil {
+	if p.State() != Paused || p.ctrl == nil {
 		return
 	}
-	speaker.Lock()
+	p.out.Lock()
 	p.ctrl.Paused = false
-	speaker.Unlock()
-	p.state = Playing
+	p.out.Unlock()
+	p.setState(Playing)
 }
 
 // Toggle toggles between playing and paused states.
 func (p *Player) Toggle() {
-	switch p.state {
+	switch p.State() {
 	case Playing:
 		p.Pause()
 	case Paused:
@@ -93,7 +99,7 @@ func (p *Player) Position() time.Duration {
 // Seek moves the playback position by the given delta.
 // Non-blocking: sends to a channel, dropping old requests if one is pending.
 func (p *Player) Seek(delta time.Duration) {
-	if p.current == nil || p.current.streamer == nil || p.state == Stopped {
+	if p.current == nil || p.current.streamer == nil || p.State() == Stopped || !p.Seekable() {
 		return
 	}
 
@@ -124,7 +130,7 @@ func (p *Player) seekLoop() {
 // doSeek performs the actual seek operation.
 func (p *Player) doSeek(delta time.Duration) {
 	// Quick check without lock - if already stopped, skip entirely
-	if p.current == nil || p.current.streamer == nil || p.state == Stopped || p.volume == nil {
+	if p.current == nil || p.current.streamer == nil || p.State() == Stopped || p.volume == nil {
 		return
 	}
 
@@ -148,10 +154,10 @@ func (p *Player) doSeek(delta time.Duration) {
 	}
 
 	// Now acquire lock for the actual seek
-	speaker.Lock()
+	p.out.Lock()
 	// Re-check under lock in case Stop() was called
-	if p.current == nil || p.current.streamer == nil || p.state == Stopped || p.volume == nil {
-		speaker.Unlock()
+	if p.current == nil || p.current.streamer == nil || p.State() == Stopped || p.volume == nil {
+		p.out.Unlock()
 		return
 	}
 
@@ -161,19 +167,22 @@ func (p *Player) doSeek(delta time.Duration) {
 	// Mute, seek, then unmute to avoid audio artifacts
 	p.volume.Silent = true
 	_ = p.current.streamer.Seek(newPos)
-	speaker.Unlock()
+	if p.tempo != nil {
+		p.tempo.reset()
+	}
+	p.out.Unlock()
 
 	// Brief pause to let buffer clear before unmuting
 	time.Sleep(100 * time.Millisecond)
 
 	// Re-check state after sleep - track may have stopped or changed
-	if p.volume == nil || p.state == Stopped {
+	if p.volume == nil || p.State() == Stopped {
 		return
 	}
 
-	speaker.Lock()
+	p.out.Lock()
 	if p.volume != nil {
 		p.volume.Silent = false
 	}
-	speaker.Unlock()
+	p.out.Unlock()
 }
@@ -0,0 +1,121 @@
+package player
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/llehouerou/waves/internal/tags"
+)
+
+// minPreloadMargin is how long before a crossfade starts the next track is opened.
+const minPreloadMargin = 2 * time.Second
+
+// CrossfadeCurve selects the shape of the fade between two tracks.
+type CrossfadeCurve int
+
+const (
+	CrossfadeEqualPower CrossfadeCurve = iota // Constant perceived loudness through the fade
+	CrossfadeLinear                           // Straight gain ramps, dips slightly in the middle
+)
+
+// String returns the curve name as used in the config file.
+func (c CrossfadeCurve) String() string {
+	switch c {
+	case CrossfadeEqualPower:
+		return "equal-power"
+	case CrossfadeLinear:
+		return "linear"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseCrossfadeCurve parses a curve name. Unknown values return CrossfadeEqualPower.
+func ParseCrossfadeCurve(s string) CrossfadeCurve {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "linear":
+		return CrossfadeLinear
+	default:
+		return CrossfadeEqualPower
+	}
+}
+
+// gains returns the gain of the outgoing and incoming tracks at position t
+// of the fade, from 0 (start) to 1 (end).
+func (c CrossfadeCurve) gains(t float64) (out, in float64) {
+	if c == CrossfadeLinear {
+		return 1 - t, t
+	}
+	return math.Cos(t * math.Pi / 2), math.Sin(t * math.Pi / 2)
+}
+
+// CrossfadeConfig configures the overlap between consecutive tracks.
+// A zero Duration disables crossfading.
+type CrossfadeConfig struct {
+	Duration time.Duration
+	Curve    CrossfadeCurve
+}
+
+// crossfadeSettings holds the crossfade configuration of a Player.
+type crossfadeSettings struct {
+	mu  sync.RWMutex
+	cfg CrossfadeConfig
+}
+
+// SetCrossfade configures crossfading. The change applies from the next
+// track transition on.
+func (p *Player) SetCrossfade(cfg CrossfadeConfig) {
+	p.crossfade.mu.Lock()
+	defer p.crossfade.mu.Unlock()
+	p.crossfade.cfg = cfg
+}
+
+// Crossfade returns the crossfade configuration.
+func (p *Player) Crossfade() CrossfadeConfig {
+	p.crossfade.mu.RLock()
+	defer p.crossfade.mu.RUnlock()
+	return p.crossfade.cfg
+}
+
+// crossfadeSamples returns the fade length between prev and next in
+// output samples. Consecutive tracks of the same album are never faded,
+// so gapless albums stay gapless. The fade is sped up along with playback
+// so that it lasts the configured time.
+func (p *Player) crossfadeSamples(prev, next *tags.FileInfo) (int, CrossfadeCurve) {
+	cfg := p.Crossfade()
+	if cfg.Duration <= 0 || followsInAlbum(prev, next) {
+		return 0, cfg.Curve
+	}
+	return p.sampleRate.N(scaleBySpeed(cfg.Duration, p.Speed())), cfg.Curve
+}
+
+// preloadLead returns how long before the end of a track, in track time,
+// the next one is opened. It is extended so that the next track is ready
+// when a crossfade has to start, and when playing faster than normal.
+func (p *Player) preloadLead() time.Duration {
+	lead := p.preloadAt
+	if d := p.Crossfade().Duration; d > 0 {
+		lead = max(lead, d+minPreloadMargin)
+	}
+	return scaleBySpeed(lead, max(p.Speed(), 1))
+}
+
+// remainingSamples returns how many output samples are left in the current
+// track. Called from the audio callback through the gapless streamer.
+func (p *Player) remainingSamples() int {
+	t := p.current
+	if t == nil || t.streamer == nil {
+		return 0
+	}
+	if t.loop != nil && t.loop.active() {
+		// A looping track doesn't end
+		return t.streamer.Len()
+	}
+	left := t.streamer.Len() - t.streamer.Position()
+	if t.format.SampleRate == p.sampleRate {
+		return left
+	}
+	return p.sampleRate.N(t.format.SampleRate.D(left))
+}
@@ -0,0 +1,92 @@
+package player
+
+import (
+	"errors"
+
+	"github.com/gopxl/beep/v2"
+
+	"github.com/llehouerou/waves/internal/cue"
+)
+
+// cueSection returns the section of its audio file a CUE sheet track plays.
+func (p *Player) cueSection(path string) (cue.Section, error) {
+	if p.cueSections != nil {
+		if section, ok := p.cueSections(path); ok {
+			return section, nil
+		}
+	}
+	ref, err := cue.Lookup(path)
+	if err != nil {
+		return cue.Section{}, err
+	}
+	return ref.Section(), nil
+}
+
+// rangeStreamer plays a section of a stream as a track of its own, with
+// positions relative to the start of the section. It is used for the
+// tracks of CUE sheets, which share one audio file.
+type rangeStreamer struct {
+	s     beep.StreamSeekCloser
+	start int // First sample of the section in s
+	len   int
+	pos   int // Relative to start
+}
+
+// newRangeStreamer creates a streamer for samples [start, end) of s and
+// seeks to its beginning. end <= 0 means the end of s.
+func newRangeStreamer(s beep.StreamSeekCloser, start, end int) (*rangeStreamer, error) {
+	if end <= 0 || end > s.Len() {
+		end = s.Len()
+	}
+	if start < 0 || start >= end {
+		return nil, errors.New("track starts after the end of the file")
+	}
+	if err := s.Seek(start); err != nil {
+		return nil, err
+	}
+	return &rangeStreamer{s: s, start: start, len: end - start}, nil
+}
+
+// Stream implements beep.Streamer.
+func (r *rangeStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	remaining := r.len - r.pos
+	if remaining <= 0 {
+		return 0, false
+	}
+	if len(samples) > remaining {
+		samples = samples[:remaining]
+	}
+	n, ok = r.s.Stream(samples)
+	r.pos += n
+	return n, ok
+}
+
+// Err implements beep.Streamer.
+func (r *rangeStreamer) Err() error {
+	return r.s.Err()
+}
+
+// Len returns the number of samples of the section.
+func (r *rangeStreamer) Len() int {
+	return r.len
+}
+
+// Position returns the position within the section.
+func (r *rangeStreamer) Position() int {
+	return r.pos
+}
+
+// Seek seeks within the section.
+func (r *rangeStreamer) Seek(p int) error {
+	p = max(min(p, r.len), 0)
+	if err := r.s.Seek(r.start + p); err != nil {
+		return err
+	}
+	r.pos = p
+	return nil
+}
+
+// Close closes the underlying stream.
+func (r *rangeStreamer) Close() error {
+	return r.s.Close()
+}
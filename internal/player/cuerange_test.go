@@ -0,0 +1,121 @@
+package player
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/llehouerou/waves/internal/cue"
+)
+
+// rampWAV returns a mono 16-bit WAV whose i-th sample is i/32768.
+func rampWAV(n int) []byte {
+	samples := make([]byte, 0, 2*n)
+	for i := range n {
+		samples = binary.LittleEndian.AppendUint16(samples, uint16(i))
+	}
+	return wavFile(wavFmt(wavFormatPCM, 1, 8000, 16), samples)
+}
+
+func TestRangeStreamer_PlaysSection(t *testing.T) {
+	d, _ := openPCMTestFile(t, "album.wav", rampWAV(100))
+	r, err := newRangeStreamer(d, 20, 50)
+	require.NoError(t, err)
+
+	assert.Equal(t, 30, r.Len())
+	assert.Equal(t, 0, r.Position())
+
+	out := drain(r)
+	require.Len(t, out, 30)
+	assert.InDelta(t, 20/32768.0, out[0][0], 1e-12)
+	assert.InDelta(t, 49/32768.0, out[29][0], 1e-12)
+	assert.Equal(t, 30, r.Position())
+}
+
+func TestRangeStreamer_SeekIsRelative(t *testing.T) {
+	d, _ := openPCMTestFile(t, "album.wav", rampWAV(100))
+	r, err := newRangeStreamer(d, 20, 50)
+	require.NoError(t, err)
+
+	require.NoError(t, r.Seek(10))
+	buf := make([][2]float64, 1)
+	_, ok := r.Stream(buf)
+	require.True(t, ok)
+	assert.InDelta(t, 30/32768.0, buf[0][0], 1e-12)
+
+	require.NoError(t, r.Seek(1000))
+	assert.Equal(t, 30, r.Position(), "seeking past the end stops at the end of the section")
+	_, ok = r.Stream(buf)
+	assert.False(t, ok)
+}
+
+func TestRangeStreamer_OpenEndAndInvalidStart(t *testing.T) {
+	d, _ := openPCMTestFile(t, "album.wav", rampWAV(100))
+	r, err := newRangeStreamer(d, 60, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 40, r.Len(), "the last track runs to the end of the file")
+
+	_, err = newRangeStreamer(d, 100, 0)
+	assert.Error(t, err)
+}
+
+func TestRangeStreamer_ConsecutiveSectionsJoin(t *testing.T) {
+	data := rampWAV(100)
+	first, _ := openPCMTestFile(t, "album.wav", data)
+	second, _ := openPCMTestFile(t, "album.wav", data)
+
+	a, err := newRangeStreamer(first, 0, 37)
+	require.NoError(t, err)
+	b, err := newRangeStreamer(second, 37, 0)
+	require.NoError(t, err)
+
+	out := drain(beep.Seq(a, b))
+	require.Len(t, out, 100)
+	for i, s := range out {
+		require.InDelta(t, float64(i)/32768, s[0], 1e-12, "sample %d", i)
+	}
+}
+
+func TestDecode_CueTrack(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "album.wav"), rampWAV(16000), 0o600))
+	// 75 frames per second: track 2 starts at 1s, sample 8000
+	sheet := "FILE \"album.wav\" WAVE\n" +
+		"  TRACK 01 AUDIO\n    INDEX 01 00:00:00\n" +
+		"  TRACK 02 AUDIO\n    INDEX 01 00:01:00\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "album.cue"), []byte(sheet), 0o600))
+
+	s, format, err := Decode(filepath.Join(dir, "album.cue") + "#2")
+	require.NoError(t, err)
+	defer s.Close()
+
+	assert.Equal(t, beep.SampleRate(8000), format.SampleRate)
+	assert.Equal(t, 8000, s.Len())
+	out := drain(s)
+	require.Len(t, out, 8000)
+	assert.InDelta(t, 8000/32768.0, out[0][0], 1e-12)
+}
+
+func TestPlayer_PlaysCueSectionOfLibrary(t *testing.T) {
+	audio := writeRateTestWAV(t, "album.wav", 8000, 800)
+	outPath := filepath.Join(t.TempDir(), "out.wav")
+	out, err := NewOutput(OutputConfig{Backend: OutputWAV, Path: outPath})
+	require.NoError(t, err)
+	p := NewWithOutput(out)
+	// The sheet is gone, the section kept by the library is played
+	p.SetCueSections(func(path string) (cue.Section, bool) {
+		return cue.Section{AudioPath: audio, Start: 25 * time.Millisecond, End: 75 * time.Millisecond}, true
+	})
+
+	playToEnd(t, p, filepath.Join(t.TempDir(), "album.cue#2"))
+	require.NoError(t, p.Close())
+
+	_, frames := wavRate(t, outPath)
+	assert.Equal(t, 400, frames)
+}
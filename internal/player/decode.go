@@ -0,0 +1,98 @@
+package player
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/gopxl/beep/v2/flac"
+
+	"github.com/llehouerou/waves/internal/cue"
+)
+
+// isSupportedExt returns true if the player can decode files with this extension.
+func isSupportedExt(ext string) bool {
+	switch ext {
+	case extMP3, extFLAC, extOPUS, extOGG, extOGA, extM4A, extM4B, extMP4,
+		extWAV, extWAVE, extAIF, extAIFF, extAIFC, extWV:
+		return true
+	default:
+		return false
+	}
+}
+
+// decodeFile picks the decoder for ext and decodes f.
+// For M4A/MP4 files the codec name is also returned.
+func decodeFile(f *os.File, ext string) (streamer beep.StreamSeekCloser, format beep.Format, m4aCodec string, err error) {
+	switch ext {
+	case extMP3:
+		// go-mp3 v1.2.0+ handles LAME/Xing gapless info automatically
+		streamer, format, err = decodeGoMP3(f)
+	case extFLAC:
+		if err := skipID3v2(f); err != nil {
+			return nil, beep.Format{}, "", err
+		}
+		streamer, format, err = flac.Decode(f)
+	case extOPUS, extOGG, extOGA:
+		streamer, format, err = decodeOgg(f)
+	case extM4A, extM4B, extMP4:
+		streamer, format, m4aCodec, err = decodeM4A(f)
+	case extWAV, extWAVE:
+		streamer, format, err = decodeWAV(f)
+	case extAIF, extAIFF, extAIFC:
+		streamer, format, err = decodeAIFF(f)
+	case extWV:
+		streamer, format, err = decodeWavPack(f)
+	default:
+		err = fmt.Errorf("unsupported format: %s", ext)
+	}
+	return streamer, format, m4aCodec, err
+}
+
+// Decode opens and decodes an audio file without playing it.
+// The returned streamer yields samples at the file's native sample rate;
+// closing it also closes the file. CUE sheet track paths decode the section
+// of the audio file they refer to.
+func Decode(path string) (beep.StreamSeekCloser, beep.Format, error) {
+	if cue.IsTrackPath(path) {
+		return decodeCueTrack(path)
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if !isSupportedExt(ext) {
+		return nil, beep.Format{}, fmt.Errorf("unsupported format: %s", ext)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+
+	streamer, format, _, err := decodeFile(f, ext)
+	if err != nil {
+		f.Close()
+		return nil, beep.Format{}, err
+	}
+	return streamer, format, nil
+}
+
+// decodeCueTrack decodes the section of the audio file of a CUE sheet track.
+func decodeCueTrack(path string) (beep.StreamSeekCloser, beep.Format, error) {
+	ref, err := cue.Lookup(path)
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+	streamer, format, err := Decode(ref.AudioPath)
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+	start, end := ref.Section().Samples(int(format.SampleRate))
+	section, err := newRangeStreamer(streamer, start, end)
+	if err != nil {
+		streamer.Close()
+		return nil, beep.Format{}, fmt.Errorf("cue track %d: %w", ref.Track.Number, err)
+	}
+	return section, format, nil
+}
@@ -0,0 +1,66 @@
+package player
+
+import (
+	"sync"
+
+	"github.com/gopxl/beep/v2"
+
+	"github.com/llehouerou/waves/internal/equalizer"
+)
+
+// eqStreamer runs samples through the equalizer filters.
+// The chain is read in the audio callback, so it must be updated under the output lock.
+type eqStreamer struct {
+	streamer beep.Streamer
+	chain    *equalizer.Chain
+}
+
+// Stream implements beep.Streamer.
+func (e *eqStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = e.streamer.Stream(samples)
+	e.chain.Process(samples[:n])
+	return n, ok
+}
+
+// Err implements beep.Streamer.
+func (e *eqStreamer) Err() error {
+	return e.streamer.Err()
+}
+
+// eqSettings holds the equalizer configuration of a Player.
+type eqSettings struct {
+	mu       sync.RWMutex
+	settings equalizer.Settings
+}
+
+// newEQStreamer wraps a streamer running at the output sample rate.
+func (p *Player) newEQStreamer(s beep.Streamer) *eqStreamer {
+	return &eqStreamer{
+		streamer: s,
+		chain:    equalizer.NewChain(p.Equalizer(), int(p.sampleRate)),
+	}
+}
+
+// SetEqualizer configures the equalizer.
+// The new settings apply immediately, which allows previewing edits live.
+func (p *Player) SetEqualizer(s equalizer.Settings) {
+	s = s.Clone()
+	p.eq.mu.Lock()
+	p.eq.settings = s
+	p.eq.mu.Unlock()
+
+	p.out.Lock()
+	defer p.out.Unlock()
+	for _, t := range []*trackState{p.current, p.next, p.fading} {
+		if t != nil && t.eq != nil {
+			t.eq.chain.Update(s)
+		}
+	}
+}
+
+// Equalizer returns the equalizer configuration.
+func (p *Player) Equalizer() equalizer.Settings {
+	p.eq.mu.RLock()
+	defer p.eq.mu.RUnlock()
+	return p.eq.settings.Clone()
+}
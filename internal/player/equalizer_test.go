@@ -0,0 +1,49 @@
+package player
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/llehouerou/waves/internal/equalizer"
+)
+
+func TestEQStreamer(t *testing.T) {
+	s := equalizer.Default()
+	s.Enabled = true
+	s.PreampDB = -6.0206 // Half amplitude
+	e := &eqStreamer{
+		streamer: &mockStreamer{samples: 4, sampleVal: 0.5},
+		chain:    equalizer.NewChain(s, 44100),
+	}
+
+	buf := make([][2]float64, 8)
+	n, ok := e.Stream(buf)
+
+	assert.True(t, ok)
+	assert.Equal(t, 4, n)
+	for i := range n {
+		assert.InDelta(t, 0.25, buf[i][0], 1e-4)
+	}
+	assert.Zero(t, buf[4][0], "samples past n must be untouched")
+}
+
+func TestSetEqualizer_UpdatesOpenTracks(t *testing.T) {
+	p := &Player{out: speakerOutput{}}
+	flat := equalizer.Default()
+	track := &trackState{eq: &eqStreamer{
+		streamer: &mockStreamer{samples: 4, sampleVal: 0.5},
+		chain:    equalizer.NewChain(flat, 44100),
+	}}
+	p.current = track
+
+	s := equalizer.Default()
+	s.Enabled = true
+	s.PreampDB = -6.0206
+	p.SetEqualizer(s)
+
+	buf := make([][2]float64, 4)
+	_, _ = track.eq.Stream(buf)
+	assert.InDelta(t, 0.25, buf[0][0], 1e-4, "settings apply to the playing track")
+	assert.Equal(t, s, p.Equalizer())
+}
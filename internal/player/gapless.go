@@ -9,11 +9,27 @@ import (
 var _ beep.Streamer = (*gaplessStreamer)(nil)
 
 // gaplessStreamer wraps a streamer and allows seamless transition to a next streamer.
+// When a crossfade is set for the next streamer, the transition starts that many
+// samples before the end of the current one and both are mixed meanwhile.
 type gaplessStreamer struct {
 	mu       sync.Mutex
 	current  beep.Streamer
 	next     beep.Streamer
-	onSwitch func() // Called when transitioning to next
+	onSwitch func(crossfade bool) // Called when transitioning to next
+	onFaded  func()               // Called when the outgoing streamer is no longer used
+
+	// Crossfade into next. remaining reports the samples left in current;
+	// without it the tracks are joined back to back.
+	nextFade  int
+	nextCurve CrossfadeCurve
+	remaining func() int
+
+	// Running crossfade
+	fadeOut   beep.Streamer
+	fadeCurve CrossfadeCurve
+	fadePos   int
+	fadeLen   int
+	fadeBuf   [][2]float64
 }
 
 // Stream implements beep.Streamer.
@@ -21,6 +37,13 @@ func (g *gaplessStreamer) Stream(samples [][2]float64) (n int, ok bool) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	n = g.streamCrossfade(samples)
+	if n == len(samples) {
+		return n, true
+	}
+	samples = samples[n:]
+	faded := n
+
 	n, ok = g.current.Stream(samples)
 
 	// If current didn't fill the buffer, check if it's exhausted
@@ -34,10 +57,11 @@ func (g *gaplessStreamer) Stream(samples [][2]float64) (n int, ok bool) {
 	// If current is exhausted and we have a next, switch to it
 	if !ok && g.next != nil {
 		if g.onSwitch != nil {
-			g.onSwitch()
+			g.onSwitch(false)
 		}
 		g.current = g.next
 		g.next = nil
+		g.nextFade = 0
 
 		// Fill remaining buffer from next track
 		if n < len(samples) {
@@ -49,7 +73,101 @@ func (g *gaplessStreamer) Stream(samples [][2]float64) (n int, ok bool) {
 		}
 	}
 
-	return n, ok
+	return faded + n, ok || faded > 0
+}
+
+// streamCrossfade fills samples while a crossfade is due or running and
+// returns how many were written. It returns 0 when no fade is involved.
+func (g *gaplessStreamer) streamCrossfade(samples [][2]float64) int {
+	n := 0
+	if g.fadeOut == nil {
+		start, due := g.crossfadeStart()
+		if !due || start >= len(samples) {
+			return 0
+		}
+		// Play current up to the start of the fade
+		if start > 0 {
+			n, _ = g.current.Stream(samples[:start])
+		}
+		g.beginCrossfade()
+	}
+
+	for n < len(samples) && g.fadeOut != nil {
+		k := min(len(samples)-n, g.fadeLen-g.fadePos, len(g.fadeBuf))
+		in := samples[n : n+k]
+		out := g.fadeBuf[:k]
+
+		fillStream(g.current, in)
+		fillStream(g.fadeOut, out)
+		for i := range k {
+			t := float64(g.fadePos+i) / float64(g.fadeLen)
+			gOut, gIn := g.fadeCurve.gains(t)
+			in[i][0] = in[i][0]*gIn + out[i][0]*gOut
+			in[i][1] = in[i][1]*gIn + out[i][1]*gOut
+		}
+
+		g.fadePos += k
+		n += k
+		if g.fadePos >= g.fadeLen {
+			g.endCrossfade()
+		}
+	}
+	return n
+}
+
+// crossfadeStart returns in how many samples the crossfade into next
+// should start, and false if no crossfade is set up.
+func (g *gaplessStreamer) crossfadeStart() (int, bool) {
+	if g.next == nil || g.nextFade <= 0 || g.remaining == nil {
+		return 0, false
+	}
+	left := g.remaining()
+	if left <= 0 {
+		// Let the back to back transition handle the end of the track
+		return 0, false
+	}
+	return max(left-g.nextFade, 0), true
+}
+
+// beginCrossfade makes next the current streamer and keeps the old one
+// playing until the fade is over. Caller must hold mu.
+func (g *gaplessStreamer) beginCrossfade() {
+	// A fade started late (e.g. after a seek) only covers what is left
+	g.fadeLen = max(min(g.nextFade, g.remaining()), 1)
+	g.fadeCurve = g.nextCurve
+	g.fadePos = 0
+	if g.fadeBuf == nil {
+		g.fadeBuf = make([][2]float64, 512)
+	}
+
+	if g.onSwitch != nil {
+		g.onSwitch(true)
+	}
+	g.fadeOut = g.current
+	g.current = g.next
+	g.next = nil
+	g.nextFade = 0
+}
+
+// endCrossfade drops the outgoing streamer. Caller must hold mu.
+func (g *gaplessStreamer) endCrossfade() {
+	g.fadeOut = nil
+	if g.onFaded != nil {
+		g.onFaded()
+	}
+}
+
+// fillStream fills samples from s, with silence once s is exhausted.
+func fillStream(s beep.Streamer, samples [][2]float64) {
+	n := 0
+	for n < len(samples) {
+		k, ok := s.Stream(samples[n:])
+		n += k
+		if !ok || k == 0 {
+			break
+		}
+	}
+	clear(samples[n:])
 }
 
 // Err implements beep.Streamer.
@@ -64,8 +182,16 @@ func (g *gaplessStreamer) Err() error {
 
 // SetNext sets the next streamer to transition to.
 func (g *gaplessStreamer) SetNext(s beep.Streamer) {
+	g.SetNextCrossfade(s, 0, CrossfadeEqualPower)
+}
+
+// SetNextCrossfade sets the next streamer and fades into it over the given
+// number of samples.
+func (g *gaplessStreamer) SetNextCrossfade(s beep.Streamer, samples int, curve CrossfadeCurve) {
 	g.mu.Lock()
 	g.next = s
+	g.nextFade = samples
+	g.nextCurve = curve
 	g.mu.Unlock()
 }
 
@@ -73,6 +199,7 @@ func (g *gaplessStreamer) SetNext(s beep.Streamer) {
 func (g *gaplessStreamer) ClearNext() {
 	g.mu.Lock()
 	g.next = nil
+	g.nextFade = 0
 	g.mu.Unlock()
 }
 
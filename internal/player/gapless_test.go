@@ -35,7 +35,7 @@ func TestGaplessStreamer_BasicTransition(t *testing.T) {
 	transitioned := false
 	g := &gaplessStreamer{
 		current:  current,
-		onSwitch: func() { transitioned = true },
+		onSwitch: func(bool) { transitioned = true },
 	}
 	g.SetNext(next)
 
@@ -89,3 +89,97 @@ func TestGaplessStreamer_SetNextDuringPlayback(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, 20, n) // 10 from current + 10 from next
 }
+
+func TestGaplessStreamer_Crossfade(t *testing.T) {
+	current := &mockStreamer{samples: 20, sampleVal: 1.0}
+	next := &mockStreamer{samples: 20, sampleVal: 2.0}
+
+	var switches []bool
+	faded := false
+	g := &gaplessStreamer{
+		current:   current,
+		onSwitch:  func(crossfade bool) { switches = append(switches, crossfade) },
+		onFaded:   func() { faded = true },
+		remaining: func() int { return current.samples - current.produced },
+	}
+	g.SetNextCrossfade(next, 10, CrossfadeLinear)
+
+	buf := make([][2]float64, 40)
+	n, ok := g.Stream(buf)
+
+	assert.True(t, ok)
+	assert.Equal(t, 30, n) // 10 from current alone, 10 mixed, 10 from next alone
+	assert.Equal(t, []bool{true}, switches)
+	assert.True(t, faded)
+
+	for i := range 10 {
+		assert.Equal(t, 1.0, buf[i][0], "sample %d should be from current", i)
+	}
+	for i := range 10 {
+		tt := float64(i) / 10
+		assert.InDelta(t, 1.0*(1-tt)+2.0*tt, buf[10+i][0], 1e-9, "sample %d should be mixed", 10+i)
+	}
+	for i := 20; i < 30; i++ {
+		assert.Equal(t, 2.0, buf[i][0], "sample %d should be from next", i)
+	}
+}
+
+func TestGaplessStreamer_CrossfadeAcrossBuffers(t *testing.T) {
+	current := &mockStreamer{samples: 1000, sampleVal: 1.0}
+	next := &mockStreamer{samples: 1000, sampleVal: 1.0}
+
+	g := &gaplessStreamer{
+		current:   current,
+		onSwitch:  func(bool) {},
+		remaining: func() int { return current.samples - current.produced },
+	}
+	g.SetNextCrossfade(next, 600, CrossfadeEqualPower)
+
+	// On two constant signals the mix is cos + sin, between 1 and sqrt(2)
+	total := 0
+	buf := make([][2]float64, 128)
+	for {
+		n, ok := g.Stream(buf)
+		for i := range buf[:n] {
+			assert.GreaterOrEqual(t, buf[i][0], 1.0-1e-9)
+			assert.LessOrEqual(t, buf[i][0], 1.4143)
+		}
+		total += n
+		if !ok {
+			break
+		}
+	}
+	assert.Equal(t, 1400, total)
+	assert.Nil(t, g.fadeOut)
+}
+
+func TestGaplessStreamer_NoCrossfadeWithoutRemaining(t *testing.T) {
+	current := &mockStreamer{samples: 10, sampleVal: 1.0}
+	next := &mockStreamer{samples: 10, sampleVal: 2.0}
+
+	var switches []bool
+	g := &gaplessStreamer{
+		current:  current,
+		onSwitch: func(crossfade bool) { switches = append(switches, crossfade) },
+	}
+	g.SetNextCrossfade(next, 5, CrossfadeLinear)
+
+	buf := make([][2]float64, 25)
+	n, ok := g.Stream(buf)
+
+	assert.True(t, ok)
+	assert.Equal(t, 20, n)
+	assert.Equal(t, []bool{false}, switches)
+}
+
+func TestCrossfadeCurve_Gains(t *testing.T) {
+	out, in := CrossfadeEqualPower.gains(0.5)
+	assert.InDelta(t, 1.0, out*out+in*in, 1e-9, "equal power keeps constant power")
+
+	out, in = CrossfadeLinear.gains(0.25)
+	assert.InDelta(t, 0.75, out, 1e-9)
+	assert.InDelta(t, 0.25, in, 1e-9)
+
+	assert.Equal(t, CrossfadeLinear, ParseCrossfadeCurve(" Linear "))
+	assert.Equal(t, CrossfadeEqualPower, ParseCrossfadeCurve("whatever"))
+}
@@ -4,7 +4,9 @@ package player
 import (
 	"time"
 
+	"github.com/llehouerou/waves/internal/equalizer"
 	"github.com/llehouerou/waves/internal/tags"
+	"github.com/llehouerou/waves/internal/visualizer"
 )
 
 // Interface defines the player contract for dependency injection and testing.
@@ -19,6 +21,12 @@ type Interface interface {
 	Position() time.Duration
 	Duration() time.Duration
 	Seek(delta time.Duration)
+	Seekable() bool // False for streams, which have no duration
+
+	// A-B loop of the current track, cleared when the track changes.
+	// b <= a clears the loop. Returns false when the player can't loop the
+	// track itself, and the caller has to seek back instead.
+	SetLoop(a, b time.Duration) bool
 
 	// Volume control
 	SetVolume(level float64) // 0.0 to 1.0
@@ -26,14 +34,39 @@ type Interface interface {
 	SetMuted(muted bool)
 	Muted() bool
 
+	// Loudness normalization
+	SetReplayGain(cfg ReplayGainConfig)
+	ReplayGain() ReplayGainStatus
+	SetAlbumContext(inAlbum bool)
+
+	// Crossfade between tracks
+	SetCrossfade(cfg CrossfadeConfig)
+
+	// Equalizer
+	SetEqualizer(s equalizer.Settings)
+	Equalizer() equalizer.Settings
+
+	// Rate the output runs at, 0 before the first track
+	OutputSampleRate() int
+
+	// Last audio sent to the output, for visualization
+	Tap() *visualizer.Ring
+
+	// Playback speed, pitch preserving (MinSpeed to MaxSpeed)
+	SetSpeed(speed float64)
+	Speed() float64
+
 	OnFinished(fn func())
 	FinishedChan() <-chan struct{}
 	Done() <-chan struct{}
 
 	// Gapless playback
-	SetPreloadFunc(fn func() string)
+	SetPreloadFunc(fn func() (path string, inAlbum bool))
 	SetPreloadDuration(d time.Duration)
 	ClearPreload()
+
+	// Close stops playback and releases the audio output
+	Close() error
 }
 
 // Verify Player implements Interface at compile time.
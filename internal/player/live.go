@@ -0,0 +1,191 @@
+package player
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+
+	"github.com/llehouerou/waves/internal/icy"
+	"github.com/llehouerou/waves/internal/tags"
+)
+
+// liveBuffer is how much decoded audio a live stream keeps ahead of the
+// output.
+const liveBuffer = 2 * time.Second
+
+var errNotSeekable = errors.New("stream is not seekable")
+
+// openStream connects to an HTTP stream, such as a radio station. Streams
+// have no duration and can't seek; their title follows what the station
+// announces.
+func (p *Player) openStream(url string, start bool) (*trackState, error) {
+	s, err := icy.Open(url)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder, format, codec, err := decodeLive(s)
+	if err != nil {
+		s.Close()
+		return nil, fmt.Errorf("%s: %w", url, err)
+	}
+
+	if start {
+		if err := p.openOutput(format.SampleRate); err != nil {
+			decoder.Close()
+			return nil, err
+		}
+	}
+	live := newLiveStreamer(decoder, s, format.SampleRate)
+	resampled := p.resample(live, format.SampleRate)
+	eq := p.newEQStreamer(resampled)
+
+	info := &tags.FileInfo{}
+	info.Path = url
+	info.SampleRate = int(format.SampleRate)
+	info.BitDepth = format.Precision * 8
+	info.Format = codec
+
+	return &trackState{
+		streamer:  live,
+		resampled: resampled,
+		eq:        eq,
+		gain:      &gainStreamer{streamer: eq, scale: 1},
+		format:    format,
+		trackInfo: info,
+		stream:    s,
+	}, nil
+}
+
+// decodeLive picks the decoder of a stream from its codec.
+func decodeLive(s *icy.Stream) (beep.StreamSeekCloser, beep.Format, string, error) {
+	switch s.Info().Codec {
+	case icy.CodecMP3:
+		streamer, format, err := decodeGoMP3(s)
+		return streamer, format, "MP3", err
+	case icy.CodecAAC:
+		streamer, format, err := decodeADTS(s)
+		return streamer, format, "AAC", err
+	case icy.CodecOgg:
+		return decodeOggStream(s)
+	case icy.CodecUnknown:
+	}
+	return nil, beep.Format{}, "", fmt.Errorf("unsupported stream format %q", s.Info().ContentType)
+}
+
+// liveInfo returns the track info of a stream with the title the station
+// last announced. The station name stands in for the album.
+func liveInfo(base *tags.FileInfo, s *icy.Stream) *tags.FileInfo {
+	info := *base
+	info.Artist, info.Title = icy.SplitTitle(s.Title())
+	info.Album = s.Info().Name
+	if info.Title == "" {
+		info.Title = info.Album
+	}
+	if info.Title == "" {
+		info.Title = s.URL()
+	}
+	return &info
+}
+
+// liveStreamer decodes a network stream ahead of the output, so that the
+// audio callback never waits on the network. When the network falls
+// behind it plays silence until audio comes again.
+type liveStreamer struct {
+	src    beep.StreamSeekCloser // Decoder reading the stream
+	stream *icy.Stream
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	ready    [][2]float64 // Decoded samples waiting for the output
+	max      int
+	position int
+	done     bool // The decoder ended
+	closed   bool
+	err      error
+}
+
+func newLiveStreamer(src beep.StreamSeekCloser, s *icy.Stream, rate beep.SampleRate) *liveStreamer {
+	l := &liveStreamer{src: src, stream: s, max: rate.N(liveBuffer)}
+	l.cond = sync.NewCond(&l.mu)
+	go l.decode()
+	return l
+}
+
+// decode fills the buffer until the stream ends or is closed.
+func (l *liveStreamer) decode() {
+	defer l.src.Close()
+	chunk := make([][2]float64, 4096)
+	for {
+		n, ok := l.src.Stream(chunk)
+
+		l.mu.Lock()
+		for len(l.ready) >= l.max && !l.closed {
+			l.cond.Wait()
+		}
+		if l.closed {
+			l.mu.Unlock()
+			return
+		}
+		l.ready = append(l.ready, chunk[:n]...)
+		if !ok {
+			l.done = true
+			l.err = l.src.Err()
+			l.mu.Unlock()
+			return
+		}
+		l.mu.Unlock()
+	}
+}
+
+// Stream plays decoded samples, or silence while the buffer is empty.
+func (l *liveStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n = copy(samples, l.ready)
+	l.ready = l.ready[n:]
+	l.cond.Broadcast() // Room for more
+	if n < len(samples) {
+		if l.done {
+			l.position += n
+			return n, n > 0
+		}
+		clear(samples[n:])
+		n = len(samples)
+	}
+	l.position += n
+	return n, true
+}
+
+// Err returns the error that ended the stream, if any.
+func (l *liveStreamer) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// Len returns 0: the length of a stream is unknown.
+func (l *liveStreamer) Len() int { return 0 }
+
+// Position returns how many samples were played.
+func (l *liveStreamer) Position() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.position
+}
+
+// Seek fails: streams can't seek.
+func (l *liveStreamer) Seek(int) error { return errNotSeekable }
+
+// Close disconnects. The decoder is closed once it stops reading.
+func (l *liveStreamer) Close() error {
+	l.mu.Lock()
+	l.closed = true
+	l.cond.Broadcast()
+	l.mu.Unlock()
+	return l.stream.Close()
+}
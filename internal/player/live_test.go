@@ -0,0 +1,97 @@
+package player
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveICY serves audio as an ICY stream announcing title after the
+// first metadata interval.
+func serveICY(t *testing.T, audio []byte, contentType, title string) string {
+	t.Helper()
+	const interval = 4096
+	var body bytes.Buffer
+	for i := 0; len(audio) > 0; i++ {
+		n := min(interval, len(audio))
+		body.Write(audio[:n])
+		audio = audio[n:]
+		if n < interval {
+			break
+		}
+		if i > 0 {
+			body.WriteByte(0)
+			continue
+		}
+		meta := []byte("StreamTitle='" + title + "';")
+		blocks := (len(meta) + 15) / 16
+		body.WriteByte(byte(blocks))
+		body.Write(meta)
+		body.Write(make([]byte, blocks*16-len(meta)))
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("icy-name", "Test Radio")
+		w.Header().Set("icy-metaint", strconv.Itoa(interval))
+		w.Header().Set("Content-Length", strconv.Itoa(body.Len()))
+		_, _ = w.Write(body.Bytes())
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL + "/live"
+}
+
+func TestPlayer_PlaysStream(t *testing.T) {
+	audio, err := os.ReadFile("testdata/vorbis_44100_stereo.ogg")
+	require.NoError(t, err)
+	url := serveICY(t, audio, "application/ogg", "Artist - Live Title")
+
+	outPath := filepath.Join(t.TempDir(), "out.wav")
+	out, err := NewOutput(OutputConfig{Backend: OutputWAV, Path: outPath})
+	require.NoError(t, err)
+	p := NewWithOutput(out)
+
+	require.NoError(t, p.Play(url))
+	assert.False(t, p.Seekable(), "streams can't seek")
+	assert.Zero(t, p.Duration(), "streams have no duration")
+
+	info := p.TrackInfo()
+	require.NotNil(t, info)
+	assert.Equal(t, "Artist", info.Artist)
+	assert.Equal(t, "Live Title", info.Title)
+	assert.Equal(t, "Test Radio", info.Album)
+	assert.Equal(t, "VORBIS", info.Format)
+	assert.Equal(t, url, info.Path)
+
+	p.Seek(time.Second) // Ignored, instead of skipping to the end
+	select {
+	case <-p.FinishedChan():
+		t.Fatal("seeking a stream must not finish it")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	select {
+	case <-p.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not finish")
+	}
+	require.NoError(t, p.Close())
+
+	rate, frames := wavRate(t, outPath)
+	assert.Equal(t, 44100, rate)
+	assert.Positive(t, frames, "the stream was played")
+}
+
+func TestPlayer_StreamErrors(t *testing.T) {
+	p := NewWithOutput(nil)
+	url := serveICY(t, []byte("<html></html>"), "text/html", "")
+	assert.Error(t, p.Play(url), "pages that aren't audio can't play")
+}
@@ -0,0 +1,75 @@
+package player
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+)
+
+// loopStreamer repeats a section [a, b) of a track. When the stream
+// reaches b it seeks back to a within the same read, so the loop is
+// sample accurate. Loop points are in samples of the track; b == 0 means
+// no loop.
+type loopStreamer struct {
+	beep.StreamSeekCloser
+	a, b atomic.Int64
+}
+
+func newLoopStreamer(s beep.StreamSeekCloser) *loopStreamer {
+	return &loopStreamer{StreamSeekCloser: s}
+}
+
+// set sets the loop points. b <= a clears the loop.
+func (l *loopStreamer) set(a, b int) {
+	if b <= a {
+		a, b = 0, 0
+	}
+	b = min(b, l.Len())
+	l.a.Store(int64(a))
+	l.b.Store(int64(b))
+}
+
+// active returns true when a loop is set.
+func (l *loopStreamer) active() bool {
+	return l.b.Load() > 0
+}
+
+// Stream implements beep.Streamer.
+func (l *loopStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	a, b := int(l.a.Load()), int(l.b.Load())
+	if b <= 0 {
+		return l.StreamSeekCloser.Stream(samples)
+	}
+	for n < len(samples) {
+		pos := l.Position()
+		if pos >= b {
+			if err := l.Seek(a); err != nil {
+				return n, n > 0
+			}
+			pos = a
+		}
+		chunk := samples[n:min(len(samples), n+b-pos)]
+		k, sok := l.StreamSeekCloser.Stream(chunk)
+		n += k
+		if !sok || k == 0 {
+			return n, n > 0
+		}
+	}
+	return n, true
+}
+
+// SetLoop repeats the section of the current track between a and b. b <= a
+// clears the loop. Returns false when there is no track to loop, or for
+// streams, which can't seek.
+func (p *Player) SetLoop(a, b time.Duration) bool {
+	t := p.current
+	if t == nil || t.loop == nil {
+		return false
+	}
+	rate := t.format.SampleRate
+	p.out.Lock()
+	t.loop.set(rate.N(a), rate.N(b))
+	p.out.Unlock()
+	return true
+}
@@ -0,0 +1,55 @@
+package player
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoopStreamer_RepeatsSection(t *testing.T) {
+	d, _ := openPCMTestFile(t, "track.wav", rampWAV(100))
+	l := newLoopStreamer(d)
+	l.set(10, 20)
+	require.NoError(t, l.Seek(15))
+
+	buf := make([][2]float64, 30)
+	n, ok := l.Stream(buf)
+	require.True(t, ok)
+	require.Equal(t, 30, n)
+	// 15..19, then 10..19 twice, then 10..14
+	want := []int{15, 16, 17, 18, 19}
+	for range 2 {
+		for i := 10; i < 20; i++ {
+			want = append(want, i)
+		}
+	}
+	want = append(want, 10, 11, 12, 13, 14)
+	for i, s := range buf {
+		require.InDelta(t, float64(want[i])/32768, s[0], 1e-12, "sample %d", i)
+	}
+}
+
+func TestLoopStreamer_SeekPastEndJumpsBack(t *testing.T) {
+	d, _ := openPCMTestFile(t, "track.wav", rampWAV(100))
+	l := newLoopStreamer(d)
+	l.set(10, 20)
+	require.NoError(t, l.Seek(50))
+
+	buf := make([][2]float64, 1)
+	_, ok := l.Stream(buf)
+	require.True(t, ok)
+	assert.InDelta(t, 10/32768.0, buf[0][0], 1e-12)
+}
+
+func TestLoopStreamer_ClearedPlaysToEnd(t *testing.T) {
+	d, _ := openPCMTestFile(t, "track.wav", rampWAV(100))
+	l := newLoopStreamer(d)
+	l.set(10, 20)
+	assert.True(t, l.active())
+	l.set(20, 10)
+	assert.False(t, l.active(), "b before a clears the loop")
+
+	out := drain(l)
+	assert.Len(t, out, 100)
+}
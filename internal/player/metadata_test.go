@@ -39,7 +39,7 @@ func TestIsMusicFile_Opus(t *testing.T) {
 		{"song.OGA", true},
 		{"song.mp3", true},
 		{"song.flac", true},
-		{"song.wav", false},
+		{"song.wav", true},
 		{"song.txt", false},
 	}
 
@@ -5,7 +5,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/llehouerou/waves/internal/equalizer"
 	"github.com/llehouerou/waves/internal/tags"
+	"github.com/llehouerou/waves/internal/visualizer"
 )
 
 // Mock is a test double for Player.
@@ -14,14 +16,24 @@ type Mock struct {
 	state       State
 	position    time.Duration
 	duration    time.Duration
+	unseekable  bool
 	trackInfo   *tags.FileInfo
 	playErr     error
 	playCalls   []string
 	seekCalls   []time.Duration
+	loopA       time.Duration
+	loopB       time.Duration
 	finishedCh  chan struct{}
 	done        chan struct{}
 	volumeLevel float64
 	muted       bool
+	replayGain  ReplayGainConfig
+	inAlbum     bool
+	crossfade   CrossfadeConfig
+	eq          equalizer.Settings
+	speed       float64
+	outputRate  int
+	tap         *visualizer.Ring
 }
 
 // NewMock creates a new mock player for testing.
@@ -31,6 +43,7 @@ func NewMock() *Mock {
 		volumeLevel: 1.0,
 		finishedCh:  make(chan struct{}, 1),
 		done:        make(chan struct{}),
+		tap:         visualizer.NewRing(visualizer.RingSize),
 	}
 }
 
@@ -42,6 +55,7 @@ func (m *Mock) Play(path string) error {
 		return m.playErr
 	}
 	m.state = Playing
+	m.loopA, m.loopB = 0, 0
 	return nil
 }
 
@@ -51,6 +65,29 @@ func (m *Mock) Stop() {
 	m.state = Stopped
 }
 
+func (m *Mock) OutputSampleRate() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.outputRate
+}
+
+// SetOutputSampleRate sets the rate returned by OutputSampleRate.
+func (m *Mock) SetOutputSampleRate(rate int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.outputRate = rate
+}
+
+// Tap returns a ring that tests can write audio to.
+func (m *Mock) Tap() *visualizer.Ring {
+	return m.tap
+}
+
+func (m *Mock) Close() error {
+	m.Stop()
+	return nil
+}
+
 func (m *Mock) Pause() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -110,6 +147,12 @@ func (m *Mock) Seek(d time.Duration) {
 	m.seekCalls = append(m.seekCalls, d)
 }
 
+func (m *Mock) Seekable() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return !m.unseekable
+}
+
 func (m *Mock) OnFinished(_ func()) {}
 
 func (m *Mock) FinishedChan() <-chan struct{} {
@@ -120,7 +163,7 @@ func (m *Mock) Done() <-chan struct{} {
 	return m.done
 }
 
-func (m *Mock) SetPreloadFunc(_ func() string) {}
+func (m *Mock) SetPreloadFunc(_ func() (string, bool)) {}
 
 func (m *Mock) SetPreloadDuration(_ time.Duration) {}
 
@@ -156,8 +199,75 @@ func (m *Mock) Muted() bool {
 	return m.muted
 }
 
+func (m *Mock) SetReplayGain(cfg ReplayGainConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.replayGain = cfg
+}
+
+func (m *Mock) ReplayGain() ReplayGainStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return ReplayGainStatus{Mode: m.replayGain.Mode}
+}
+
+func (m *Mock) SetAlbumContext(inAlbum bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inAlbum = inAlbum
+}
+
+func (m *Mock) SetCrossfade(cfg CrossfadeConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.crossfade = cfg
+}
+
+func (m *Mock) SetEqualizer(s equalizer.Settings) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.eq = s.Clone()
+}
+
+func (m *Mock) Equalizer() equalizer.Settings {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.eq.Clone()
+}
+
+func (m *Mock) SetSpeed(speed float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.speed = clampSpeed(speed)
+}
+
+func (m *Mock) Speed() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return clampSpeed(m.speed)
+}
+
+// SetLoop records the loop points. The mock doesn't play audio, so it
+// leaves the looping to the caller.
+func (m *Mock) SetLoop(a, b time.Duration) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if b <= a {
+		a, b = 0, 0
+	}
+	m.loopA, m.loopB = a, b
+	return false
+}
+
 // Test helpers
 
+// Loop returns the loop points last set with SetLoop.
+func (m *Mock) Loop() (a, b time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.loopA, m.loopB
+}
+
 func (m *Mock) SetState(s State) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -198,12 +308,32 @@ func (m *Mock) SetDuration(d time.Duration) {
 	m.duration = d
 }
 
+func (m *Mock) SetSeekable(seekable bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.unseekable = !seekable
+}
+
 func (m *Mock) SetPosition(d time.Duration) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.position = d
 }
 
+// AlbumContext returns the last value passed to SetAlbumContext.
+func (m *Mock) AlbumContext() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.inAlbum
+}
+
+// Crossfade returns the last value passed to SetCrossfade.
+func (m *Mock) Crossfade() CrossfadeConfig {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.crossfade
+}
+
 // SimulateFinished simulates a track finishing.
 func (m *Mock) SimulateFinished() {
 	select {
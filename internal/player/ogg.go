@@ -9,23 +9,69 @@ import (
 
 // decodeOgg decodes an Ogg stream (Opus or Vorbis) into a beep streamer.
 func decodeOgg(rc io.ReadSeekCloser) (beep.StreamSeekCloser, beep.Format, error) {
-	// Read first page to get the identification packet
-	hdr, err := parseOggPageHeader(rc)
+	codec, err := readOggHeaders(rc)
 	if err != nil {
 		return nil, beep.Format{}, err
 	}
-	packets, partial, err := readOggPageBody(rc, hdr)
+
+	// Record where audio data starts (after headers)
+	dataStart, err := rc.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+
+	// Create OggReader
+	ogg, err := NewOggReader(rc, codec.SampleRate(), codec.PreSkip())
 	if err != nil {
 		return nil, beep.Format{}, err
 	}
+	ogg.SetDataStart(dataStart)
+	if err := ogg.ScanLastGranule(); err != nil {
+		return nil, beep.Format{}, err
+	}
+	// Seek back to audio start
+	if _, err := rc.Seek(dataStart, io.SeekStart); err != nil {
+		return nil, beep.Format{}, err
+	}
+
+	format := beep.Format{
+		SampleRate:  beep.SampleRate(codec.SampleRate()),
+		NumChannels: codec.Channels(),
+		Precision:   2,
+	}
+
+	decoder := &oggDecoder{
+		ogg:       ogg,
+		codec:     codec,
+		closer:    rc,
+		pcmBuffer: make([]float32, 8192*codec.Channels()),
+		totalLen:  ogg.Duration(),
+	}
+	decoder.pcmPos = len(decoder.pcmBuffer) // empty buffer triggers refill
+
+	return decoder, format, nil
+}
+
+// readOggHeaders reads the header pages of an Ogg stream and returns the
+// codec, ready to decode the audio pages that follow.
+func readOggHeaders(r io.Reader) (OggCodec, error) {
+	// Read first page to get the identification packet
+	hdr, err := parseOggPageHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	packets, partial, err := readOggPageBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
 	if len(packets) == 0 {
-		return nil, beep.Format{}, errors.New("ogg: no packets in first page")
+		return nil, errors.New("ogg: no packets in first page")
 	}
 
 	// Detect codec from first packet
 	codec, err := detectOggCodec(packets[0])
 	if err != nil {
-		return nil, beep.Format{}, err
+		return nil, err
 	}
 
 	// Feed header packets until codec is ready
@@ -33,20 +79,20 @@ func decodeOgg(rc io.ReadSeekCloser) (beep.StreamSeekCloser, beep.Format, error)
 	for {
 		complete, err := codec.AddHeaderPacket(nil) // Check if already complete
 		if err != nil {
-			return nil, beep.Format{}, err
+			return nil, err
 		}
 		if complete {
-			break
+			return codec, nil
 		}
 
 		// Read more pages for headers
-		hdr, err := parseOggPageHeader(rc)
+		hdr, err := parseOggPageHeader(r)
 		if err != nil {
-			return nil, beep.Format{}, err
+			return nil, err
 		}
-		pagePackets, newPartial, err := readOggPageBody(rc, hdr)
+		pagePackets, newPartial, err := readOggPageBody(r, hdr)
 		if err != nil {
-			return nil, beep.Format{}, err
+			return nil, err
 		}
 
 		// Join partial from previous page with first packet/partial of this page
@@ -64,7 +110,7 @@ func decodeOgg(rc io.ReadSeekCloser) (beep.StreamSeekCloser, beep.Format, error)
 		for _, pkt := range pagePackets {
 			complete, err = codec.AddHeaderPacket(pkt)
 			if err != nil {
-				return nil, beep.Format{}, err
+				return nil, err
 			}
 			if complete {
 				break
@@ -74,43 +120,55 @@ func decodeOgg(rc io.ReadSeekCloser) (beep.StreamSeekCloser, beep.Format, error)
 		// Track new partial for next iteration
 		partial = newPartial
 	}
+}
 
-	// Record where audio data starts (after headers)
-	dataStart, err := rc.Seek(0, io.SeekCurrent)
+// decodeOggStream decodes an Ogg stream that can only be read forward,
+// such as a radio station. Its length is unknown and it can't seek.
+func decodeOggStream(rc io.ReadCloser) (beep.StreamSeekCloser, beep.Format, string, error) {
+	r := &forwardReader{r: rc}
+	codec, err := readOggHeaders(r)
 	if err != nil {
-		return nil, beep.Format{}, err
+		return nil, beep.Format{}, "", err
 	}
 
-	// Create OggReader
-	ogg, err := NewOggReader(rc, codec.SampleRate(), codec.PreSkip())
-	if err != nil {
-		return nil, beep.Format{}, err
+	name := "VORBIS"
+	if _, ok := codec.(*opusCodec); ok {
+		name = "OPUS"
 	}
-	ogg.SetDataStart(dataStart)
-	if err := ogg.ScanLastGranule(); err != nil {
-		return nil, beep.Format{}, err
-	}
-	// Seek back to audio start
-	if _, err := rc.Seek(dataStart, io.SeekStart); err != nil {
-		return nil, beep.Format{}, err
-	}
-
 	format := beep.Format{
 		SampleRate:  beep.SampleRate(codec.SampleRate()),
 		NumChannels: codec.Channels(),
 		Precision:   2,
 	}
-
 	decoder := &oggDecoder{
-		ogg:       ogg,
+		ogg:       &OggReader{r: r, sampleRate: codec.SampleRate(), preSkip: codec.PreSkip()},
 		codec:     codec,
 		closer:    rc,
 		pcmBuffer: make([]float32, 8192*codec.Channels()),
-		totalLen:  ogg.Duration(),
 	}
 	decoder.pcmPos = len(decoder.pcmBuffer) // empty buffer triggers refill
 
-	return decoder, format, nil
+	return decoder, format, name, nil
+}
+
+// forwardReader lets OggReader read a stream that can't seek. It only
+// reports its offset, which OggReader records for each page.
+type forwardReader struct {
+	r      io.Reader
+	offset int64
+}
+
+func (f *forwardReader) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	f.offset += int64(n)
+	return n, err
+}
+
+func (f *forwardReader) Seek(offset int64, whence int) (int64, error) {
+	if offset != 0 || whence != io.SeekCurrent {
+		return 0, errNotSeekable
+	}
+	return f.offset, nil
 }
 
 // oggDecoder implements beep.StreamSeekCloser for Ogg streams.
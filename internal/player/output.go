@@ -0,0 +1,120 @@
+package player
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/gopxl/beep/v2/speaker"
+)
+
+// Output is where the player sends its audio.
+//
+// The player opens the output when the first track starts, and resamples
+// tracks that don't match its rate (see OutputRateConfig). Streamers passed
+// to Play are read from the audio callback, so the player changes them only
+// while holding the output lock.
+type Output interface {
+	// Init opens the output at the given sample rate. If CanChangeRate is
+	// true, it may be called again between tracks to switch to another rate.
+	Init(sr beep.SampleRate) error
+	// CanChangeRate returns true if the output can be reopened at another rate.
+	CanChangeRate() bool
+	// Play starts playing a streamer, mixed with the ones already playing.
+	Play(s beep.Streamer)
+	// Clear stops all playing streamers.
+	Clear()
+	// Lock and Unlock guard the streamers against the audio callback.
+	Lock()
+	Unlock()
+	// Close flushes and releases the output.
+	Close() error
+}
+
+// OutputBackend selects the kind of output.
+type OutputBackend int
+
+const (
+	OutputSpeaker OutputBackend = iota // Sound card
+	OutputWAV                          // WAV file
+	OutputPCM                          // Raw PCM to stdout, a file or a FIFO
+)
+
+// String returns the backend name as used in the config file.
+func (b OutputBackend) String() string {
+	switch b {
+	case OutputSpeaker:
+		return "speaker"
+	case OutputWAV:
+		return "wav"
+	case OutputPCM:
+		return "pcm"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseOutputBackend parses a backend name. Unknown values return OutputSpeaker.
+func ParseOutputBackend(s string) OutputBackend {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "wav":
+		return OutputWAV
+	case "pcm":
+		return OutputPCM
+	default:
+		return OutputSpeaker
+	}
+}
+
+// OutputConfig configures the output of a Player.
+type OutputConfig struct {
+	Backend OutputBackend
+	// Path is the file written by the WAV backend, and the file or FIFO
+	// written by the PCM backend ("" or "-" for stdout).
+	Path string
+}
+
+// NewOutput creates the output described by cfg.
+func NewOutput(cfg OutputConfig) (Output, error) {
+	switch cfg.Backend {
+	case OutputWAV:
+		if cfg.Path == "" {
+			return nil, errors.New("output: the wav backend needs a path")
+		}
+		enc, err := newWAVEncoder(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		return newSinkOutput(enc), nil
+	case OutputPCM:
+		if cfg.Path == "" || cfg.Path == "-" {
+			return newSinkOutput(&rawEncoder{}), nil
+		}
+		return newSinkOutput(&rawEncoder{path: cfg.Path}), nil
+	default:
+		return speakerOutput{}, nil
+	}
+}
+
+// speakerOutput plays through the sound card with beep's speaker.
+// The speaker is global and its audio context can only be created once per
+// process, so it can't follow the source rate: it runs at the configured
+// rate, or DefaultOutputRate.
+type speakerOutput struct{}
+
+func (speakerOutput) Init(sr beep.SampleRate) error {
+	return speaker.Init(sr, sr.N(time.Second/10))
+}
+
+func (speakerOutput) CanChangeRate() bool { return false }
+
+func (speakerOutput) Play(s beep.Streamer) { speaker.Play(s) }
+func (speakerOutput) Clear()               { speaker.Clear() }
+func (speakerOutput) Lock()                { speaker.Lock() }
+func (speakerOutput) Unlock()              { speaker.Unlock() }
+
+func (speakerOutput) Close() error {
+	speaker.Close()
+	return nil
+}
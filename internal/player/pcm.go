@@ -0,0 +1,201 @@
+package player
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/gopxl/beep/v2"
+)
+
+// pcmLayout describes how uncompressed samples are stored.
+type pcmLayout struct {
+	channels  int
+	width     int // Bytes per sample, including padding bits
+	float     bool
+	bigEndian bool
+	unsigned  bool // 8-bit WAV samples are unsigned
+}
+
+// frameSize returns the number of bytes of one sample frame.
+func (l pcmLayout) frameSize() int {
+	return l.channels * l.width
+}
+
+// validate checks that the layout can be decoded.
+func (l pcmLayout) validate() error {
+	if l.channels < 1 {
+		return errors.New("pcm: no channels")
+	}
+	if l.float {
+		if l.width != 4 && l.width != 8 {
+			return fmt.Errorf("pcm: unsupported float width: %d bytes", l.width)
+		}
+		return nil
+	}
+	if l.width < 1 || l.width > 4 {
+		return fmt.Errorf("pcm: unsupported sample width: %d bytes", l.width)
+	}
+	return nil
+}
+
+// sample decodes the sample at the start of b into [-1, 1].
+func (l pcmLayout) sample(b []byte) float64 {
+	if l.float {
+		if l.width == 4 {
+			var bits uint32
+			if l.bigEndian {
+				bits = binary.BigEndian.Uint32(b)
+			} else {
+				bits = binary.LittleEndian.Uint32(b)
+			}
+			return float64(math.Float32frombits(bits))
+		}
+		var bits uint64
+		if l.bigEndian {
+			bits = binary.BigEndian.Uint64(b)
+		} else {
+			bits = binary.LittleEndian.Uint64(b)
+		}
+		return math.Float64frombits(bits)
+	}
+
+	// Gather the bytes most significant first, into the top of an int32,
+	// so that the sign comes for free and any width scales the same.
+	var v uint32
+	for i := range l.width {
+		j := i
+		if !l.bigEndian {
+			j = l.width - 1 - i
+		}
+		v |= uint32(b[j]) << (24 - 8*i)
+	}
+	if l.unsigned {
+		v ^= 1 << 31
+	}
+	return float64(int32(v)) / (1 << 31) //nolint:gosec // reinterpreting the sign bit
+}
+
+// pcmDecoder streams uncompressed samples stored in a single contiguous
+// region of a file. WAV and AIFF only differ in how they describe it.
+type pcmDecoder struct {
+	rc        io.ReadSeekCloser
+	layout    pcmLayout
+	dataStart int64
+	frames    int
+	pos       int
+	buf       []byte
+	err       error
+}
+
+// newPCMDecoder creates a decoder for frames sample frames starting at dataStart.
+func newPCMDecoder(rc io.ReadSeekCloser, layout pcmLayout, dataStart int64, frames int) (*pcmDecoder, error) {
+	if err := layout.validate(); err != nil {
+		return nil, err
+	}
+	if _, err := rc.Seek(dataStart, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return &pcmDecoder{
+		rc:        rc,
+		layout:    layout,
+		dataStart: dataStart,
+		frames:    frames,
+	}, nil
+}
+
+// Stream implements beep.Streamer. Only the first two channels are played,
+// mono is sent to both.
+func (d *pcmDecoder) Stream(samples [][2]float64) (n int, ok bool) {
+	if d.err != nil {
+		return 0, false
+	}
+	want := min(len(samples), d.frames-d.pos)
+	if want <= 0 {
+		return 0, false
+	}
+
+	frameSize := d.layout.frameSize()
+	if len(d.buf) < want*frameSize {
+		d.buf = make([]byte, want*frameSize)
+	}
+	read, err := io.ReadFull(d.rc, d.buf[:want*frameSize])
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		d.err = err
+		return 0, false
+	}
+
+	n = read / frameSize
+	right := 0
+	if d.layout.channels > 1 {
+		right = d.layout.width
+	}
+	for i := range n {
+		frame := d.buf[i*frameSize:]
+		samples[i][0] = d.layout.sample(frame)
+		samples[i][1] = d.layout.sample(frame[right:])
+	}
+	d.pos += n
+	if n < want {
+		// The file is shorter than its header says
+		d.frames = d.pos
+	}
+	return n, n > 0
+}
+
+// Err implements beep.Streamer.
+func (d *pcmDecoder) Err() error {
+	return d.err
+}
+
+// Len returns the total number of sample frames.
+func (d *pcmDecoder) Len() int {
+	return d.frames
+}
+
+// Position returns the current sample frame.
+func (d *pcmDecoder) Position() int {
+	return d.pos
+}
+
+// Seek moves to the given sample frame.
+func (d *pcmDecoder) Seek(p int) error {
+	p = max(min(p, d.frames), 0)
+	offset := d.dataStart + int64(p)*int64(d.layout.frameSize())
+	if _, err := d.rc.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	d.pos = p
+	d.err = nil
+	return nil
+}
+
+// Close closes the underlying file.
+func (d *pcmDecoder) Close() error {
+	return d.rc.Close()
+}
+
+// pcmFormat returns the beep format of a PCM stream.
+func pcmFormat(rate int, layout pcmLayout) beep.Format {
+	return beep.Format{
+		SampleRate:  beep.SampleRate(rate),
+		NumChannels: min(layout.channels, 2),
+		Precision:   layout.width,
+	}
+}
+
+// framesIn returns how many whole frames fit between offset and the end of
+// the file, or size if that is smaller. Headers of files that were still
+// being written often claim more data than there is.
+func framesIn(rc io.Seeker, offset, size int64, frameSize int) (int, error) {
+	end, err := rc.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	if size < 0 || offset+size > end {
+		size = max(end-offset, 0)
+	}
+	return int(size / int64(frameSize)), nil
+}
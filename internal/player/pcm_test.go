@@ -0,0 +1,197 @@
+package player
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chunk builds a RIFF (little-endian) or IFF (big-endian) chunk, with padding.
+func chunk(order binary.AppendByteOrder, id string, data []byte) []byte {
+	out := append([]byte(id), order.AppendUint32(nil, uint32(len(data)))...)
+	out = append(out, data...)
+	if len(data)&1 != 0 {
+		out = append(out, 0)
+	}
+	return out
+}
+
+// wavFile builds a WAV file. fmtData is the content of the fmt chunk.
+func wavFile(fmtData, samples []byte, extra ...[]byte) []byte {
+	body := []byte("WAVE")
+	for _, e := range extra {
+		body = append(body, e...)
+	}
+	body = append(body, chunk(binary.LittleEndian, "fmt ", fmtData)...)
+	body = append(body, chunk(binary.LittleEndian, "data", samples)...)
+	return chunk(binary.LittleEndian, "RIFF", body)
+}
+
+func wavFmt(format uint16, channels, rate, bits int) []byte {
+	align := channels * bits / 8
+	b := binary.LittleEndian.AppendUint16(nil, format)
+	b = binary.LittleEndian.AppendUint16(b, uint16(channels))
+	b = binary.LittleEndian.AppendUint32(b, uint32(rate))
+	b = binary.LittleEndian.AppendUint32(b, uint32(rate*align))
+	b = binary.LittleEndian.AppendUint16(b, uint16(align))
+	return binary.LittleEndian.AppendUint16(b, uint16(bits))
+}
+
+// aiffFile builds an AIFF or AIFF-C file. compression is empty for AIFF.
+func aiffFile(channels, frames, bits int, compression string, samples []byte) []byte {
+	comm := binary.BigEndian.AppendUint16(nil, uint16(channels))
+	comm = binary.BigEndian.AppendUint32(comm, uint32(frames))
+	comm = binary.BigEndian.AppendUint16(comm, uint16(bits))
+	// 44100 as an 80-bit extended float
+	comm = append(comm, 0x40, 0x0E, 0xAC, 0x44, 0, 0, 0, 0, 0, 0)
+	kind := "AIFF"
+	if compression != "" {
+		kind = "AIFC"
+		comm = append(comm, compression...)
+		comm = append(comm, 0, 0) // Empty name
+	}
+	ssnd := append(make([]byte, 8), samples...)
+	body := append([]byte(kind), chunk(binary.BigEndian, "COMM", comm)...)
+	body = append(body, chunk(binary.BigEndian, "SSND", ssnd)...)
+	return chunk(binary.BigEndian, "FORM", body)
+}
+
+func openPCMTestFile(t *testing.T, name string, data []byte) (*pcmDecoder, int) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	s, format, _, err := decodeFile(f, filepath.Ext(name))
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	return s.(*pcmDecoder), int(format.SampleRate)
+}
+
+func TestDecodeWAV_Encodings(t *testing.T) {
+	// Stereo frames: (0.5, -0.25) then (-1, 0)
+	pcm16 := []byte{0x00, 0x40, 0x00, 0xE0, 0x00, 0x80, 0x00, 0x00}
+	pcm24 := []byte{0, 0x00, 0x40, 0, 0x00, 0xE0, 0, 0x00, 0x80, 0, 0x00, 0x00}
+	pcm8 := []byte{0xC0, 0x60, 0x00, 0x80}
+	var float32s []byte
+	for _, v := range []float32{0.5, -0.25, -1, 0} {
+		float32s = binary.LittleEndian.AppendUint32(float32s, math.Float32bits(v))
+	}
+	extensible := append(wavFmt(wavFormatExtensible, 2, 44100, 24), 22, 0, 24, 0, 3, 0, 0, 0)
+	extensible = append(extensible, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x80, 0x00, 0x00, 0xAA, 0x00, 0x38, 0x9B, 0x71)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"16-bit", wavFile(wavFmt(wavFormatPCM, 2, 44100, 16), pcm16)},
+		{"8-bit", wavFile(wavFmt(wavFormatPCM, 2, 44100, 8), pcm8)},
+		{"24-bit extensible", wavFile(extensible, pcm24)},
+		{"32-bit float", wavFile(wavFmt(wavFormatFloat, 2, 44100, 32), float32s)},
+		{"extra chunks", wavFile(wavFmt(wavFormatPCM, 2, 44100, 16), pcm16,
+			chunk(binary.LittleEndian, "LIST", []byte("INFOodd")),
+			chunk(binary.LittleEndian, "id3 ", []byte("ID3")))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, rate := openPCMTestFile(t, "test.wav", tt.data)
+			assert.Equal(t, 44100, rate)
+			assert.Equal(t, 2, d.Len())
+
+			out := drain(d)
+			require.Len(t, out, 2)
+			assert.Equal(t, [2]float64{0.5, -0.25}, out[0])
+			assert.Equal(t, [2]float64{-1, 0}, out[1])
+		})
+	}
+}
+
+func TestDecodeWAV_DataSizeLargerThanFile(t *testing.T) {
+	data := wavFile(wavFmt(wavFormatPCM, 1, 8000, 16), []byte{0x00, 0x40, 0x00, 0xC0})
+	// Claim more data than there is, like a recording that was cut short
+	binary.LittleEndian.PutUint32(data[len(data)-8:], 0xFFFFFFFF)
+
+	d, _ := openPCMTestFile(t, "short.wav", data)
+
+	assert.Equal(t, 2, d.Len())
+	out := drain(d)
+	require.Len(t, out, 2)
+	assert.Equal(t, [2]float64{0.5, 0.5}, out[0], "mono is sent to both channels")
+	assert.Equal(t, [2]float64{-0.5, -0.5}, out[1])
+}
+
+func TestDecodeWAV_RejectsCompressed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "adpcm.wav")
+	require.NoError(t, os.WriteFile(path, wavFile(wavFmt(0x0002, 1, 8000, 4), []byte{0}), 0o600))
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	_, _, err = decodeWAV(f)
+	assert.ErrorContains(t, err, "unsupported encoding")
+}
+
+func TestDecodeAIFF_Encodings(t *testing.T) {
+	// Stereo frames: (0.5, -0.25) then (-1, 0)
+	be16 := []byte{0x40, 0x00, 0xE0, 0x00, 0x80, 0x00, 0x00, 0x00}
+	le16 := []byte{0x00, 0x40, 0x00, 0xE0, 0x00, 0x80, 0x00, 0x00}
+	var fl32 []byte
+	for _, v := range []float32{0.5, -0.25, -1, 0} {
+		fl32 = binary.BigEndian.AppendUint32(fl32, math.Float32bits(v))
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"AIFF 16-bit", aiffFile(2, 2, 16, "", be16)},
+		{"AIFC none", aiffFile(2, 2, 16, "NONE", be16)},
+		{"AIFC little-endian", aiffFile(2, 2, 16, "sowt", le16)},
+		{"AIFC float", aiffFile(2, 2, 32, "fl32", fl32)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, rate := openPCMTestFile(t, "test.aiff", tt.data)
+			assert.Equal(t, 44100, rate)
+			assert.Equal(t, 2, d.Len())
+
+			out := drain(d)
+			require.Len(t, out, 2)
+			assert.Equal(t, [2]float64{0.5, -0.25}, out[0])
+			assert.Equal(t, [2]float64{-1, 0}, out[1])
+		})
+	}
+}
+
+func TestPCMDecoder_Seek(t *testing.T) {
+	samples := make([]byte, 0, 200)
+	for i := range 100 {
+		samples = binary.BigEndian.AppendUint16(samples, uint16(i*256))
+	}
+	d, _ := openPCMTestFile(t, "seek.aif", aiffFile(1, 100, 16, "", samples))
+
+	require.NoError(t, d.Seek(60))
+	assert.Equal(t, 60, d.Position())
+	buf := make([][2]float64, 10)
+	n, ok := d.Stream(buf)
+	assert.True(t, ok)
+	assert.Equal(t, 10, n)
+	assert.InDelta(t, 60*256/32768.0, buf[0][0], 1e-12)
+	assert.Equal(t, 70, d.Position())
+
+	require.NoError(t, d.Seek(500))
+	assert.Equal(t, 100, d.Position(), "seeking past the end stops at the end")
+	_, ok = d.Stream(buf)
+	assert.False(t, ok)
+}
+
+func TestExtendedToFloat(t *testing.T) {
+	assert.InDelta(t, 44100.0, extendedToFloat([]byte{0x40, 0x0E, 0xAC, 0x44, 0, 0, 0, 0, 0, 0}), 0)
+	assert.InDelta(t, 48000.0, extendedToFloat([]byte{0x40, 0x0E, 0xBB, 0x80, 0, 0, 0, 0, 0, 0}), 0)
+	assert.InDelta(t, 0.0, extendedToFloat(make([]byte, 10)), 0)
+}
@@ -8,7 +8,10 @@ import (
 	"github.com/gopxl/beep/v2"
 	"github.com/gopxl/beep/v2/effects"
 
+	"github.com/llehouerou/waves/internal/cue"
+	"github.com/llehouerou/waves/internal/icy"
 	"github.com/llehouerou/waves/internal/tags"
+	"github.com/llehouerou/waves/internal/visualizer"
 )
 
 // State represents the player's playback state.
@@ -27,16 +30,30 @@ const (
 	extOGG  = ".ogg"
 	extOGA  = ".oga"
 	extM4A  = ".m4a"
+	extM4B  = ".m4b" // Audiobook
 	extMP4  = ".mp4"
+	extWAV  = ".wav"
+	extWAVE = ".wave"
+	extAIF  = ".aif"
+	extAIFF = ".aiff"
+	extAIFC = ".aifc"
+	extWV   = ".wv"
 )
 
 // trackState bundles all resources for a single track.
 type trackState struct {
 	file      *os.File
 	streamer  beep.StreamSeekCloser
-	resampled beep.Streamer // Resampled to speaker rate (may equal streamer)
+	loop      *loopStreamer // A-B loop stage, wraps the decoder; nil for streams
+	resampled beep.Streamer // Resampled to output rate (may equal streamer)
+	eq        *eqStreamer   // Equalizer stage, wraps resampled
+	gain      *gainStreamer // ReplayGain stage, wraps eq
 	format    beep.Format
 	trackInfo *tags.FileInfo
+	stream    *icy.Stream // Set for HTTP streams, which can't seek
+
+	replayGain   ReplayGainStatus // Gain applied by the gain stage
+	albumContext bool             // Whether gain was resolved in album context
 }
 
 // Close releases all resources for this track.
@@ -51,18 +68,34 @@ func (t *trackState) Close() {
 
 // Player handles audio playback.
 type Player struct {
-	state  State
-	ctrl   *beep.Ctrl
-	volume *effects.Volume
+	out        Output
+	sampleRate beep.SampleRate  // Rate of the output, zero until it is opened
+	tap        *visualizer.Ring // Last audio sent to the output
+
+	stateMu sync.RWMutex // Protects state, also set when a track ends
+	state   State
+	ctrl    *beep.Ctrl
+	volume  *effects.Volume
 
 	volMu       sync.RWMutex // Protects volumeLevel and muted
 	volumeLevel float64      // 0.0 to 1.0
 	muted       bool
 
+	gainMu       sync.RWMutex // Protects replayGain and albumContext
+	replayGain   ReplayGainConfig
+	albumContext bool // Current track is part of an album played in order
+
+	crossfade crossfadeSettings
+	eq        eqSettings
+	speed     speedSettings
+	rate      rateSettings
+
 	// Dual track state for gapless playback
 	current *trackState
 	next    *trackState
+	fading  *trackState // Previous track while it fades out
 	gapless *gaplessStreamer
+	tempo   *tempoStreamer // Speed stage, wraps gapless
 
 	// Channels
 	done       chan struct{}
@@ -70,20 +103,27 @@ type Player struct {
 	onFinished func()
 	seekChan   chan time.Duration
 
+	// Sections of the CUE sheet tracks of the library, nil to read sheets
+	cueSections func(path string) (cue.Section, bool)
+
 	// Pre-loading
-	preloadAt   time.Duration // How early to pre-load (default 3s)
-	preloadFn   func() string // Callback to get next track path
-	monitorDone chan struct{} // Stops the monitor loop
+	preloadAt    time.Duration         // How early to pre-load (default 3s)
+	preloadFn    func() (string, bool) // Next track path, and whether it plays in album order
+	monitorDone  chan struct{}         // Stops the monitor loop
+	monitor      sync.WaitGroup        // Running monitor loop
+	nextNeedsGap bool                  // Next track is at another output rate, not pre-loaded
 }
 
-var (
-	speakerInitialized bool
-	speakerSampleRate  beep.SampleRate
-)
-
-// New creates a new Player.
+// New creates a new Player that plays through the sound card.
 func New() *Player {
+	return NewWithOutput(speakerOutput{})
+}
+
+// NewWithOutput creates a new Player that plays through out.
+func NewWithOutput(out Output) *Player {
 	p := &Player{
+		out:         out,
+		tap:         visualizer.NewRing(visualizer.RingSize),
 		state:       Stopped,
 		volumeLevel: 1.0, // Full volume by default
 		done:        make(chan struct{}),
@@ -106,16 +146,36 @@ func (p *Player) Done() <-chan struct{} {
 }
 
 // State returns the current playback state.
-func (p *Player) State() State { return p.state }
+func (p *Player) State() State {
+	p.stateMu.RLock()
+	defer p.stateMu.RUnlock()
+	return p.state
+}
+
+// setState sets the playback state.
+func (p *Player) setState(s State) {
+	p.stateMu.Lock()
+	p.state = s
+	p.stateMu.Unlock()
+}
 
 // TrackInfo returns metadata about the currently playing track.
 func (p *Player) TrackInfo() *tags.FileInfo {
 	if p.current == nil {
 		return nil
 	}
+	if p.current.stream != nil {
+		return liveInfo(p.current.trackInfo, p.current.stream)
+	}
 	return p.current.trackInfo
 }
 
+// Seekable returns true if the current track can seek. Streams can't, and
+// have no duration.
+func (p *Player) Seekable() bool {
+	return p.current != nil && p.current.stream == nil
+}
+
 // Duration returns the total duration of the current track.
 func (p *Player) Duration() time.Duration {
 	if p.current == nil || p.current.trackInfo == nil {
@@ -129,22 +189,39 @@ func (p *Player) OnFinished(fn func()) {
 	p.onFinished = fn
 }
 
-// SetPreloadFunc sets the callback to get the next track path for pre-loading.
-func (p *Player) SetPreloadFunc(fn func() string) {
+// SetPreloadFunc sets the callback to get the next track path for
+// pre-loading, "" for none, and whether the track plays as part of an album
+// in order, which selects album gain in auto mode.
+func (p *Player) SetPreloadFunc(fn func() (path string, inAlbum bool)) {
 	p.preloadFn = fn
 }
 
+// SetCueSections makes CUE sheet tracks play the section fn returns for
+// them, so that their sheet isn't parsed. Tracks for which fn returns false
+// are looked up in their sheet.
+func (p *Player) SetCueSections(fn func(path string) (cue.Section, bool)) {
+	p.cueSections = fn
+}
+
 // SetPreloadDuration sets how early to pre-load the next track.
+// It is extended as needed when crossfading is enabled.
 func (p *Player) SetPreloadDuration(d time.Duration) {
 	p.preloadAt = d
 }
 
+// Close stops playback and closes the output.
+func (p *Player) Close() error {
+	p.Stop()
+	return p.out.Close()
+}
+
 // clearNextTrack closes and clears the pre-loaded next track.
 func (p *Player) clearNextTrack() {
 	if p.next != nil {
 		go p.next.Close()
 		p.next = nil
 	}
+	p.nextNeedsGap = false
 	if p.gapless != nil {
 		p.gapless.ClearNext()
 	}
@@ -0,0 +1,105 @@
+package player
+
+import (
+	"sync"
+
+	"github.com/gopxl/beep/v2"
+)
+
+const (
+	// DefaultResampleQuality is good enough for real time resampling at a low CPU cost.
+	DefaultResampleQuality = 4
+	// MaxResampleQuality is the highest resampler quality. Values above 6
+	// cost a lot of CPU for little gain.
+	MaxResampleQuality = 16
+	// DefaultOutputRate is the rate of outputs that can't follow the source
+	// when no rate is set, that of CDs which most music is at.
+	DefaultOutputRate = 44100
+)
+
+// OutputRateConfig selects the sample rate of the output.
+type OutputRateConfig struct {
+	// Rate is a fixed output rate in Hz. 0 follows the rate of the source:
+	// the output is reopened between tracks when the rate changes. Outputs
+	// that can't be reopened run at DefaultOutputRate instead.
+	Rate int
+	// ResampleQuality is the quality of the resampler used when a track
+	// doesn't match the output rate, from 1 to MaxResampleQuality.
+	ResampleQuality int
+}
+
+// rateSettings holds the output rate configuration of a Player.
+type rateSettings struct {
+	mu  sync.RWMutex
+	cfg OutputRateConfig
+}
+
+// SetOutputRate configures the output rate. The change applies from the
+// next track played on.
+func (p *Player) SetOutputRate(cfg OutputRateConfig) {
+	cfg.Rate = max(cfg.Rate, 0)
+	if cfg.ResampleQuality <= 0 {
+		cfg.ResampleQuality = DefaultResampleQuality
+	}
+	cfg.ResampleQuality = min(cfg.ResampleQuality, MaxResampleQuality)
+
+	p.rate.mu.Lock()
+	defer p.rate.mu.Unlock()
+	p.rate.cfg = cfg
+}
+
+// OutputRate returns the output rate configuration.
+func (p *Player) OutputRate() OutputRateConfig {
+	p.rate.mu.RLock()
+	defer p.rate.mu.RUnlock()
+	cfg := p.rate.cfg
+	if cfg.ResampleQuality == 0 {
+		cfg.ResampleQuality = DefaultResampleQuality
+	}
+	return cfg
+}
+
+// OutputSampleRate returns the rate the output runs at, 0 before the first
+// track is played.
+func (p *Player) OutputSampleRate() int {
+	return int(p.sampleRate)
+}
+
+// outputRateFor returns the rate the output should run at for a source rate.
+func (p *Player) outputRateFor(source beep.SampleRate) beep.SampleRate {
+	if rate := p.OutputRate().Rate; rate > 0 {
+		return beep.SampleRate(rate)
+	}
+	if !p.out.CanChangeRate() {
+		return DefaultOutputRate
+	}
+	return source
+}
+
+// followsSourceRate returns true if the output is reopened when the source
+// rate changes, which takes a gap between tracks.
+func (p *Player) followsSourceRate() bool {
+	return p.OutputRate().Rate == 0 && p.out.CanChangeRate()
+}
+
+// openOutput opens the output, or reopens it at a new rate when following
+// the source. It must only be called while nothing is playing.
+func (p *Player) openOutput(source beep.SampleRate) error {
+	rate := p.outputRateFor(source)
+	if p.sampleRate == rate || (p.sampleRate != 0 && !p.out.CanChangeRate()) {
+		return nil
+	}
+	if err := p.out.Init(rate); err != nil {
+		return err
+	}
+	p.sampleRate = rate
+	return nil
+}
+
+// resample converts a track to the output rate.
+func (p *Player) resample(s beep.Streamer, from beep.SampleRate) beep.Streamer {
+	if from == p.sampleRate {
+		return s
+	}
+	return beep.Resample(p.OutputRate().ResampleQuality, from, p.sampleRate, s)
+}
@@ -0,0 +1,129 @@
+package player
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeRateTestWAV writes a mono 16-bit WAV file of the given length.
+func writeRateTestWAV(t *testing.T, name string, rate, frames int) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	samples := make([]byte, frames*2)
+	for i := range frames {
+		binary.LittleEndian.PutUint16(samples[i*2:], uint16(int16(8192)))
+	}
+	require.NoError(t, os.WriteFile(path, wavFile(wavFmt(wavFormatPCM, 1, rate, 16), samples), 0o600))
+	return path
+}
+
+// playToEnd plays a track and waits until it has ended.
+func playToEnd(t *testing.T, p *Player, path string) {
+	t.Helper()
+	require.NoError(t, p.Play(path))
+	select {
+	case <-p.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("track did not finish")
+	}
+}
+
+// wavRate reads the sample rate and the number of frames of a WAV file
+// written by the sink.
+func wavRate(t *testing.T, path string) (rate, frames int) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(data), wavHeaderSize)
+	rate = int(binary.LittleEndian.Uint32(data[24:]))
+	frames = int(binary.LittleEndian.Uint32(data[40:])) / sinkBytesPerFrame
+	return rate, frames
+}
+
+func TestSetOutputRate_Defaults(t *testing.T) {
+	p := &Player{}
+	assert.Equal(t, DefaultResampleQuality, p.OutputRate().ResampleQuality, "zero value uses the default quality")
+
+	p.SetOutputRate(OutputRateConfig{Rate: -1, ResampleQuality: 99})
+	assert.Equal(t, OutputRateConfig{Rate: 0, ResampleQuality: MaxResampleQuality}, p.OutputRate())
+}
+
+func TestPlayer_FollowsSourceRate(t *testing.T) {
+	trackA := writeRateTestWAV(t, "a.wav", 8000, 800)
+	trackB := writeRateTestWAV(t, "b.wav", 16000, 1600)
+	outPath := filepath.Join(t.TempDir(), "out.wav")
+	out, err := NewOutput(OutputConfig{Backend: OutputWAV, Path: outPath})
+	require.NoError(t, err)
+	p := NewWithOutput(out)
+
+	playToEnd(t, p, trackA)
+	assert.Equal(t, Stopped, p.State(), "a track that ends stops the player")
+	assert.Equal(t, 8000, p.OutputSampleRate())
+
+	playToEnd(t, p, trackB)
+	assert.Equal(t, 16000, p.OutputSampleRate(), "the output is reopened at the new rate")
+	require.NoError(t, p.Close())
+
+	rate, frames := wavRate(t, outPath)
+	assert.Equal(t, 8000, rate)
+	assert.Equal(t, 800, frames)
+	rate, frames = wavRate(t, filepath.Join(filepath.Dir(outPath), "out-2.wav"))
+	assert.Equal(t, 16000, rate)
+	assert.Equal(t, 1600, frames)
+}
+
+func TestPlayer_FixedOutputRate(t *testing.T) {
+	track := writeRateTestWAV(t, "a.wav", 8000, 800)
+	outPath := filepath.Join(t.TempDir(), "out.wav")
+	out, err := NewOutput(OutputConfig{Backend: OutputWAV, Path: outPath})
+	require.NoError(t, err)
+	p := NewWithOutput(out)
+	p.SetOutputRate(OutputRateConfig{Rate: 16000, ResampleQuality: 6})
+
+	playToEnd(t, p, track)
+	assert.Equal(t, 16000, p.OutputSampleRate())
+	require.NoError(t, p.Close())
+
+	rate, frames := wavRate(t, outPath)
+	assert.Equal(t, 16000, rate)
+	assert.InDelta(t, 1600, frames, 8, "the track is resampled to the output rate")
+}
+
+func TestPlayer_KeepsRateOfFixedOutput(t *testing.T) {
+	trackA := writeRateTestWAV(t, "a.wav", 8000, 400)
+	trackB := writeRateTestWAV(t, "b.wav", 16000, 800)
+	out, err := NewOutput(OutputConfig{Backend: OutputPCM, Path: filepath.Join(t.TempDir(), "out.pcm")})
+	require.NoError(t, err)
+	p := NewWithOutput(out)
+	defer p.Close()
+
+	playToEnd(t, p, trackA)
+	assert.Equal(t, DefaultOutputRate, p.OutputSampleRate(), "raw PCM can't follow the source, it runs at the default rate")
+	playToEnd(t, p, trackB)
+	assert.Equal(t, DefaultOutputRate, p.OutputSampleRate())
+}
+
+func TestPreloadNext_RateChangeIsNotGapless(t *testing.T) {
+	trackA := writeRateTestWAV(t, "a.wav", 8000, 8000)
+	trackB := writeRateTestWAV(t, "b.wav", 16000, 1600)
+	out, err := NewOutput(OutputConfig{Backend: OutputWAV, Path: filepath.Join(t.TempDir(), "out.wav")})
+	require.NoError(t, err)
+	p := NewWithOutput(out)
+	defer p.Close()
+	p.SetPreloadFunc(func() (string, bool) { return trackB, false })
+
+	require.NoError(t, p.Play(trackA))
+	p.preloadNext()
+
+	assert.False(t, p.shouldPreload(), "the track isn't pre-loaded again")
+	p.out.Lock()
+	defer p.out.Unlock()
+	assert.Nil(t, p.next, "the output has to be reopened, the next track isn't kept")
+	assert.Nil(t, p.gapless.next)
+}
@@ -0,0 +1,208 @@
+package player
+
+import (
+	"math"
+	"strings"
+
+	"github.com/gopxl/beep/v2"
+
+	"github.com/llehouerou/waves/internal/tags"
+)
+
+// ReplayGainMode selects which ReplayGain value is applied to tracks.
+type ReplayGainMode int
+
+const (
+	ReplayGainOff   ReplayGainMode = iota
+	ReplayGainTrack                // Always use track gain
+	ReplayGainAlbum                // Use album gain, falling back to track gain
+	ReplayGainAuto                 // Album gain when an album plays in order, track gain otherwise
+)
+
+// String returns the mode name as used in the config file.
+func (m ReplayGainMode) String() string {
+	switch m {
+	case ReplayGainOff:
+		return "off"
+	case ReplayGainTrack:
+		return "track"
+	case ReplayGainAlbum:
+		return "album"
+	case ReplayGainAuto:
+		return "auto"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseReplayGainMode parses a mode name. Unknown values return ReplayGainOff.
+func ParseReplayGainMode(s string) ReplayGainMode {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "track":
+		return ReplayGainTrack
+	case "album":
+		return ReplayGainAlbum
+	case "auto":
+		return ReplayGainAuto
+	default:
+		return ReplayGainOff
+	}
+}
+
+// ReplayGainConfig configures the loudness normalization stage.
+type ReplayGainConfig struct {
+	Mode            ReplayGainMode
+	PreampDB        float64 // Added to the tag gain
+	PreventClipping bool    // Lower the gain so that peak * gain <= 1.0
+}
+
+// ReplayGainStatus describes the gain applied to the current track.
+type ReplayGainStatus struct {
+	Mode    ReplayGainMode // Configured mode
+	Applied ReplayGainMode // Track or Album, Off if the track has no usable tag
+	GainDB  float64        // Effective gain including preamp and clipping prevention
+}
+
+// gainStreamer scales samples by a fixed linear factor.
+// The scale is read in the audio callback, so it must be updated under the output lock.
+type gainStreamer struct {
+	streamer beep.Streamer
+	scale    float64
+}
+
+// Stream implements beep.Streamer.
+func (g *gainStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = g.streamer.Stream(samples)
+	if g.scale == 1 {
+		return n, ok
+	}
+	for i := range samples[:n] {
+		samples[i][0] *= g.scale
+		samples[i][1] *= g.scale
+	}
+	return n, ok
+}
+
+// Err implements beep.Streamer.
+func (g *gainStreamer) Err() error {
+	return g.streamer.Err()
+}
+
+// resolveReplayGain computes the gain for a track.
+// albumContext tells whether the track is played as part of an album in order,
+// which selects album gain in auto mode.
+func resolveReplayGain(rg tags.ReplayGain, cfg ReplayGainConfig, albumContext bool) (status ReplayGainStatus, scale float64) {
+	status = ReplayGainStatus{Mode: cfg.Mode}
+
+	want := cfg.Mode
+	if want == ReplayGainAuto {
+		want = ReplayGainTrack
+		if albumContext {
+			want = ReplayGainAlbum
+		}
+	}
+
+	var gain, peak float64
+	switch {
+	case want == ReplayGainOff:
+		return status, 1
+	case want == ReplayGainAlbum && rg.HasAlbumGain:
+		status.Applied = ReplayGainAlbum
+		gain, peak = rg.AlbumGain, rg.AlbumPeak
+	case rg.HasTrackGain:
+		status.Applied = ReplayGainTrack
+		gain, peak = rg.TrackGain, rg.TrackPeak
+	case rg.HasAlbumGain:
+		// Only album gain is tagged; better than nothing in track mode
+		status.Applied = ReplayGainAlbum
+		gain, peak = rg.AlbumGain, rg.AlbumPeak
+	default:
+		return status, 1
+	}
+
+	db := gain + cfg.PreampDB
+	scale = math.Pow(10, db/20)
+	if cfg.PreventClipping && peak > 0 && scale*peak > 1 {
+		scale = 1 / peak
+		db = 20 * math.Log10(scale)
+	}
+	status.GainDB = db
+	return status, scale
+}
+
+// followsInAlbum returns true if next is the track after prev on the same album.
+func followsInAlbum(prev, next *tags.FileInfo) bool {
+	if prev == nil || next == nil || prev.Album == "" {
+		return false
+	}
+	if prev.Album != next.Album || prev.AlbumArtist != next.AlbumArtist {
+		return false
+	}
+	if next.DiscNumber == prev.DiscNumber {
+		return next.TrackNumber == prev.TrackNumber+1
+	}
+	return next.DiscNumber == prev.DiscNumber+1 && next.TrackNumber == 1
+}
+
+// applyReplayGain sets the gain of a track that is not yet streaming.
+func (p *Player) applyReplayGain(t *trackState, albumContext bool) {
+	p.gainMu.RLock()
+	cfg := p.replayGain
+	p.gainMu.RUnlock()
+
+	t.replayGain, t.gain.scale = resolveReplayGain(t.trackInfo.ReplayGain, cfg, albumContext)
+	t.albumContext = albumContext
+}
+
+// reapplyReplayGain recomputes the gain of the current and pre-loaded tracks.
+func (p *Player) reapplyReplayGain() {
+	p.out.Lock()
+	defer p.out.Unlock()
+	if p.current != nil {
+		p.applyReplayGain(p.current, p.current.albumContext)
+	}
+	if p.next != nil {
+		p.applyReplayGain(p.next, p.next.albumContext)
+	}
+}
+
+// albumContextValue returns the album context hint set by SetAlbumContext.
+func (p *Player) albumContextValue() bool {
+	p.gainMu.RLock()
+	defer p.gainMu.RUnlock()
+	return p.albumContext
+}
+
+// SetReplayGain configures loudness normalization.
+// The new settings apply immediately to the current track.
+func (p *Player) SetReplayGain(cfg ReplayGainConfig) {
+	p.gainMu.Lock()
+	p.replayGain = cfg
+	p.gainMu.Unlock()
+	p.reapplyReplayGain()
+}
+
+// ReplayGain returns the gain applied to the current track.
+func (p *Player) ReplayGain() ReplayGainStatus {
+	p.out.Lock()
+	if t := p.current; t != nil {
+		status := t.replayGain
+		p.out.Unlock()
+		return status
+	}
+	p.out.Unlock()
+
+	p.gainMu.RLock()
+	defer p.gainMu.RUnlock()
+	return ReplayGainStatus{Mode: p.replayGain.Mode}
+}
+
+// SetAlbumContext tells the player whether the track started by the next
+// Play is played as part of an album in order, which selects album gain in
+// auto mode. Pre-loaded tracks get theirs from the preload func, so that
+// the gain of a playing track never changes.
+func (p *Player) SetAlbumContext(inAlbum bool) {
+	p.gainMu.Lock()
+	defer p.gainMu.Unlock()
+	p.albumContext = inAlbum
+}
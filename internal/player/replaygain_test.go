@@ -0,0 +1,127 @@
+package player
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/llehouerou/waves/internal/tags"
+)
+
+func TestParseReplayGainMode(t *testing.T) {
+	tests := []struct {
+		input string
+		want  ReplayGainMode
+	}{
+		{"off", ReplayGainOff},
+		{"track", ReplayGainTrack},
+		{"Album", ReplayGainAlbum},
+		{" auto ", ReplayGainAuto},
+		{"", ReplayGainOff},
+		{"loud", ReplayGainOff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseReplayGainMode(tt.input))
+		})
+	}
+}
+
+func TestResolveReplayGain(t *testing.T) {
+	rg := tags.ReplayGain{
+		TrackGain: -8, TrackPeak: 0.5, HasTrackGain: true,
+		AlbumGain: -6, AlbumPeak: 0.9, HasAlbumGain: true,
+	}
+
+	tests := []struct {
+		name         string
+		rg           tags.ReplayGain
+		cfg          ReplayGainConfig
+		albumContext bool
+		wantApplied  ReplayGainMode
+		wantDB       float64
+	}{
+		{"off", rg, ReplayGainConfig{Mode: ReplayGainOff}, true, ReplayGainOff, 0},
+		{"track", rg, ReplayGainConfig{Mode: ReplayGainTrack}, true, ReplayGainTrack, -8},
+		{"album", rg, ReplayGainConfig{Mode: ReplayGainAlbum}, false, ReplayGainAlbum, -6},
+		{"auto in album", rg, ReplayGainConfig{Mode: ReplayGainAuto}, true, ReplayGainAlbum, -6},
+		{"auto outside album", rg, ReplayGainConfig{Mode: ReplayGainAuto}, false, ReplayGainTrack, -8},
+		{"preamp", rg, ReplayGainConfig{Mode: ReplayGainTrack, PreampDB: 3}, false, ReplayGainTrack, -5},
+		{
+			"album falls back to track",
+			tags.ReplayGain{TrackGain: -4, HasTrackGain: true},
+			ReplayGainConfig{Mode: ReplayGainAlbum}, false, ReplayGainTrack, -4,
+		},
+		{"no tags", tags.ReplayGain{}, ReplayGainConfig{Mode: ReplayGainTrack}, false, ReplayGainOff, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, scale := resolveReplayGain(tt.rg, tt.cfg, tt.albumContext)
+			assert.Equal(t, tt.cfg.Mode, status.Mode)
+			assert.Equal(t, tt.wantApplied, status.Applied)
+			assert.InDelta(t, tt.wantDB, status.GainDB, 1e-9)
+			assert.InDelta(t, math.Pow(10, tt.wantDB/20), scale, 1e-9)
+		})
+	}
+}
+
+func TestResolveReplayGain_PreventClipping(t *testing.T) {
+	rg := tags.ReplayGain{TrackGain: 6, TrackPeak: 0.8, HasTrackGain: true}
+
+	_, scale := resolveReplayGain(rg, ReplayGainConfig{Mode: ReplayGainTrack}, false)
+	assert.Greater(t, scale*rg.TrackPeak, 1.0, "without prevention the peak clips")
+
+	status, scale := resolveReplayGain(rg, ReplayGainConfig{Mode: ReplayGainTrack, PreventClipping: true}, false)
+	assert.InDelta(t, 1.0, scale*rg.TrackPeak, 1e-9)
+	assert.InDelta(t, 20*math.Log10(1/0.8), status.GainDB, 1e-9)
+}
+
+func TestGainStreamer(t *testing.T) {
+	g := &gainStreamer{streamer: &mockStreamer{samples: 4, sampleVal: 0.5}, scale: 0.5}
+
+	buf := make([][2]float64, 8)
+	n, ok := g.Stream(buf)
+
+	assert.True(t, ok)
+	assert.Equal(t, 4, n)
+	for i := range n {
+		assert.InDelta(t, 0.25, buf[i][0], 1e-12)
+		assert.InDelta(t, 0.25, buf[i][1], 1e-12)
+	}
+	assert.Zero(t, buf[4][0], "samples past n must be untouched")
+}
+
+func TestFollowsInAlbum(t *testing.T) {
+	track := func(album string, disc, num int) *tags.FileInfo {
+		return &tags.FileInfo{Tag: tags.Tag{Album: album, AlbumArtist: "A", DiscNumber: disc, TrackNumber: num}}
+	}
+
+	assert.True(t, followsInAlbum(track("X", 1, 3), track("X", 1, 4)))
+	assert.True(t, followsInAlbum(track("X", 1, 12), track("X", 2, 1)))
+	assert.False(t, followsInAlbum(track("X", 1, 3), track("X", 1, 5)))
+	assert.False(t, followsInAlbum(track("X", 1, 3), track("Y", 1, 4)))
+	assert.False(t, followsInAlbum(nil, track("X", 1, 1)))
+}
+
+func TestSetAlbumContext_KeepsGainOfPlayingTrack(t *testing.T) {
+	p := &Player{out: speakerOutput{}}
+	p.replayGain = ReplayGainConfig{Mode: ReplayGainAuto}
+	track := &trackState{
+		trackInfo: &tags.FileInfo{Tag: tags.Tag{ReplayGain: tags.ReplayGain{
+			TrackGain: -3, AlbumGain: -6, HasTrackGain: true, HasAlbumGain: true,
+		}}},
+		gain: &gainStreamer{scale: 1},
+	}
+	p.applyReplayGain(track, false)
+	p.current = track
+
+	// The next Play is in album context, the playing track keeps its gain
+	p.SetAlbumContext(true)
+	got := p.ReplayGain()
+	assert.Equal(t, ReplayGainTrack, got.Applied)
+	assert.InDelta(t, -3, got.GainDB, 1e-9)
+	assert.True(t, p.albumContextValue())
+}
@@ -0,0 +1,349 @@
+package player
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+)
+
+const (
+	sinkChannels      = 2
+	sinkBytesPerFrame = sinkChannels * 2 // 16-bit stereo
+	sinkBufferTime    = time.Second / 10
+	wavHeaderSize     = 44
+)
+
+// pcmEncoder writes the audio of a sink as 16-bit little-endian stereo.
+// All its methods are called from the sink goroutine.
+type pcmEncoder interface {
+	// open prepares the destination. It may block, e.g. until a FIFO has
+	// a reader. Encoders that can change rate are opened again with the
+	// new rate.
+	open(sr beep.SampleRate) error
+	write(p []byte) error
+	close() error
+	canChangeRate() bool
+}
+
+// sinkOutput mixes the player stream in software and hands it to an
+// encoder instead of a sound card. Like a sound card it consumes audio in
+// real time, so positions, preloading and crossfades behave the same.
+// Nothing is written while no track is loaded, so a rendered file holds
+// what was played, pauses included.
+type sinkOutput struct {
+	mu    sync.Mutex // Protects mixer, the output lock
+	mixer beep.Mixer
+	enc   pcmEncoder
+
+	runMu  sync.Mutex // Protects run and closed
+	run    *sinkRun   // Running goroutine, nil until Init
+	closed bool
+}
+
+// sinkRun is a sink goroutine consuming audio at one rate.
+type sinkRun struct {
+	stop  chan struct{}
+	ready chan struct{} // Closed once the encoder is open
+	done  chan struct{} // Closed when the goroutine has exited
+	err   error         // Encoder error, set before done is closed
+}
+
+func newSinkOutput(enc pcmEncoder) *sinkOutput {
+	s := &sinkOutput{enc: enc}
+	// Don't pad the end of the last track with silence
+	s.mixer.KeepAlive(false)
+	return s
+}
+
+// Init starts consuming audio at the given rate, replacing the previous
+// run when the encoder can change rate.
+func (s *sinkOutput) Init(sr beep.SampleRate) error {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	if s.closed {
+		return errors.New("output: closed")
+	}
+	if s.run != nil {
+		if !s.enc.canChangeRate() {
+			return errors.New("output: the sample rate can't be changed")
+		}
+		if err := s.run.finish(false); err != nil {
+			return err
+		}
+	}
+	s.run = &sinkRun{
+		stop:  make(chan struct{}),
+		ready: make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go s.loop(s.run, sr)
+	return nil
+}
+
+func (s *sinkOutput) CanChangeRate() bool { return s.enc.canChangeRate() }
+
+func (s *sinkOutput) Play(st beep.Streamer) {
+	s.mu.Lock()
+	s.mixer.Add(st)
+	s.mu.Unlock()
+}
+
+func (s *sinkOutput) Clear() {
+	s.mu.Lock()
+	s.mixer.Clear()
+	s.mu.Unlock()
+}
+
+func (s *sinkOutput) Lock()   { s.mu.Lock() }
+func (s *sinkOutput) Unlock() { s.mu.Unlock() }
+
+// Close stops the sink and finalizes what was written. A sink still
+// waiting for a FIFO reader is abandoned.
+func (s *sinkOutput) Close() error {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.run == nil {
+		return s.enc.close()
+	}
+	return s.run.finish(true)
+}
+
+// finish stops the run. With closeEnc the encoder is closed, otherwise it
+// is left open to be opened again at another rate. Runs still opening the
+// encoder are abandoned.
+func (r *sinkRun) finish(closeEnc bool) error {
+	if closeEnc {
+		close(r.stop)
+	} else {
+		r.stop <- struct{}{}
+	}
+	select {
+	case <-r.ready:
+	default:
+		return nil
+	}
+	<-r.done
+	return r.err
+}
+
+// loop pulls audio from the mixer one buffer at a time, at the pace of the
+// sample rate, until the run is stopped. A closed stop channel closes the
+// encoder, a value leaves it open.
+func (s *sinkOutput) loop(r *sinkRun, sr beep.SampleRate) {
+	defer close(r.done)
+
+	if err := s.enc.open(sr); err != nil {
+		r.err = err
+		close(r.ready)
+		s.drainUntilStopped(r, sr)
+		return
+	}
+	close(r.ready)
+
+	samples := make([][2]float64, sr.N(sinkBufferTime))
+	buf := make([]byte, len(samples)*sinkBytesPerFrame)
+	writing := true
+	next := time.Now()
+	for {
+		select {
+		case _, ok := <-r.stop:
+			if !ok {
+				if err := s.enc.close(); err != nil && r.err == nil {
+					r.err = err
+				}
+			}
+			return
+		case <-time.After(time.Until(next)):
+		}
+
+		if n := s.pull(samples); n > 0 && writing {
+			if err := s.enc.write(encodePCM16(buf, samples[:n])); err != nil {
+				// The reader went away, keep playing without writing
+				r.err = err
+				writing = false
+			}
+		}
+
+		next = next.Add(sinkBufferTime)
+		if now := time.Now(); now.Sub(next) > time.Second {
+			// Fell far behind (e.g. a blocked pipe), don't rush to catch up
+			next = now
+		}
+	}
+}
+
+// drainUntilStopped consumes audio without writing it, so playback keeps
+// going when the destination can't be opened.
+func (s *sinkOutput) drainUntilStopped(r *sinkRun, sr beep.SampleRate) {
+	samples := make([][2]float64, sr.N(sinkBufferTime))
+	ticker := time.NewTicker(sinkBufferTime)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			s.pull(samples)
+		}
+	}
+}
+
+// pull streams one buffer from the mixer. It returns 0 when nothing is
+// playing.
+func (s *sinkOutput) pull(samples [][2]float64) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mixer.Len() == 0 {
+		return 0
+	}
+	n, _ := s.mixer.Stream(samples)
+	return n
+}
+
+// encodePCM16 converts samples to 16-bit little-endian stereo into buf.
+func encodePCM16(buf []byte, samples [][2]float64) []byte {
+	buf = buf[:len(samples)*sinkBytesPerFrame]
+	for i, frame := range samples {
+		for c, v := range frame {
+			v = math.Max(-1, math.Min(1, v))
+			binary.LittleEndian.PutUint16(buf[i*sinkBytesPerFrame+c*2:], uint16(int16(math.Round(v*math.MaxInt16))))
+		}
+	}
+	return buf
+}
+
+// rawEncoder writes headerless PCM to a file or FIFO, or to stdout when
+// path is empty.
+type rawEncoder struct {
+	path string
+	w    io.WriteCloser
+}
+
+func (e *rawEncoder) open(beep.SampleRate) error {
+	if e.path == "" {
+		e.w = os.Stdout
+		return nil
+	}
+	// Opening a FIFO blocks until there is a reader
+	f, err := os.OpenFile(e.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	e.w = f
+	return nil
+}
+
+func (e *rawEncoder) write(p []byte) error {
+	_, err := e.w.Write(p)
+	return err
+}
+
+// canChangeRate is false: readers of raw PCM are told the format up front.
+func (e *rawEncoder) canChangeRate() bool { return false }
+
+func (e *rawEncoder) close() error {
+	if e.w == nil || e.w == os.Stdout {
+		return nil
+	}
+	return e.w.Close()
+}
+
+// wavEncoder writes a 16-bit stereo WAV file. The sizes in the header are
+// filled in when the file is done. When the rate changes the file is
+// finished and the audio goes on in a new one, numbered after the first
+// (e.g. "render.wav", "render-2.wav").
+type wavEncoder struct {
+	path    string
+	f       *os.File
+	part    int   // Number of the current file, from 1
+	started bool  // The header of the current file was written
+	size    int64 // Bytes of sample data written to the current file
+}
+
+// newWAVEncoder creates the file right away, so that a bad path is
+// reported at startup.
+func newWAVEncoder(path string) (*wavEncoder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &wavEncoder{path: path, f: f, part: 1}, nil
+}
+
+func (e *wavEncoder) open(sr beep.SampleRate) error {
+	if e.started {
+		if err := e.close(); err != nil {
+			return err
+		}
+		e.part++
+		ext := filepath.Ext(e.path)
+		f, err := os.Create(fmt.Sprintf("%s-%d%s", strings.TrimSuffix(e.path, ext), e.part, ext))
+		if err != nil {
+			return err
+		}
+		e.f, e.size = f, 0
+	}
+	e.started = true
+
+	rate := uint32(sr)
+	h := make([]byte, 0, wavHeaderSize)
+	h = append(h, "RIFF"...)
+	h = binary.LittleEndian.AppendUint32(h, 0) // Filled in on close
+	h = append(h, "WAVEfmt "...)
+	h = binary.LittleEndian.AppendUint32(h, 16)
+	h = binary.LittleEndian.AppendUint16(h, wavFormatPCM)
+	h = binary.LittleEndian.AppendUint16(h, sinkChannels)
+	h = binary.LittleEndian.AppendUint32(h, rate)
+	h = binary.LittleEndian.AppendUint32(h, rate*sinkBytesPerFrame)
+	h = binary.LittleEndian.AppendUint16(h, sinkBytesPerFrame)
+	h = binary.LittleEndian.AppendUint16(h, 16)
+	h = append(h, "data"...)
+	h = binary.LittleEndian.AppendUint32(h, 0) // Filled in on close
+	_, err := e.f.Write(h)
+	return err
+}
+
+func (e *wavEncoder) write(p []byte) error {
+	n, err := e.f.Write(p)
+	e.size += int64(n)
+	return err
+}
+
+// close patches the header sizes of the current file. Files over 4 GiB
+// keep the maximum size, which readers take as "up to the end of the file".
+func (e *wavEncoder) close() error {
+	var err error
+	if e.started {
+		riff := uint32(math.MaxUint32)
+		data := uint32(math.MaxUint32)
+		if e.size <= math.MaxUint32-(wavHeaderSize-8) {
+			data = uint32(e.size)
+			riff = data + wavHeaderSize - 8
+		}
+		if _, werr := e.f.WriteAt(binary.LittleEndian.AppendUint32(nil, riff), 4); werr != nil {
+			err = werr
+		}
+		if _, werr := e.f.WriteAt(binary.LittleEndian.AppendUint32(nil, data), wavHeaderSize-4); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	if cerr := e.f.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (e *wavEncoder) canChangeRate() bool { return true }
@@ -0,0 +1,140 @@
+package player
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOutputBackend(t *testing.T) {
+	assert.Equal(t, OutputWAV, ParseOutputBackend(" WAV"))
+	assert.Equal(t, OutputPCM, ParseOutputBackend("pcm"))
+	assert.Equal(t, OutputSpeaker, ParseOutputBackend("speaker"))
+	assert.Equal(t, OutputSpeaker, ParseOutputBackend("pulse"))
+	assert.Equal(t, "wav", OutputWAV.String())
+}
+
+func TestNewOutput_WAVNeedsPath(t *testing.T) {
+	_, err := NewOutput(OutputConfig{Backend: OutputWAV})
+	assert.Error(t, err)
+}
+
+func TestEncodePCM16(t *testing.T) {
+	buf := make([]byte, 12)
+	out := encodePCM16(buf, [][2]float64{{0.5, -0.5}, {1.5, -2}, {0, 1}})
+
+	samples := make([]int16, len(out)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(out[i*2:]))
+	}
+	assert.Equal(t, []int16{16384, -16384, 32767, -32767, 0, 32767}, samples, "values are clipped")
+}
+
+// waitDrained waits until the sink has nothing left to play.
+func waitDrained(t *testing.T, s *sinkOutput) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		s.Lock()
+		defer s.Unlock()
+		return s.mixer.Len() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestSinkOutput_WAV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.wav")
+	out, err := NewOutput(OutputConfig{Backend: OutputWAV, Path: path})
+	require.NoError(t, err)
+	s, ok := out.(*sinkOutput)
+	require.True(t, ok)
+
+	require.NoError(t, s.Init(8000))
+	s.Play(&mockStreamer{samples: 1000, sampleVal: 0.5})
+	waitDrained(t, s)
+	require.NoError(t, s.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Len(t, data, wavHeaderSize+1000*sinkBytesPerFrame, "no padding after the end")
+	assert.Equal(t, uint32(len(data)-8), binary.LittleEndian.Uint32(data[4:]))
+
+	d, rate := openPCMTestFile(t, "out.wav", data)
+	assert.Equal(t, 8000, rate)
+	got := drain(d)
+	require.Len(t, got, 1000)
+	assert.InDelta(t, 0.5, got[0][0], 1e-4)
+	assert.InDelta(t, 0.5, got[999][1], 1e-4)
+}
+
+func TestSinkOutput_RawPCM(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.pcm")
+	out, err := NewOutput(OutputConfig{Backend: OutputPCM, Path: path})
+	require.NoError(t, err)
+	s, ok := out.(*sinkOutput)
+	require.True(t, ok)
+
+	require.NoError(t, s.Init(8000))
+	s.Play(&mockStreamer{samples: 100, sampleVal: -0.25})
+	waitDrained(t, s)
+	require.NoError(t, s.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Len(t, data, 100*sinkBytesPerFrame, "no header")
+	assert.Equal(t, int16(-8192), int16(binary.LittleEndian.Uint16(data)))
+}
+
+func TestSinkOutput_CloseWithoutPlaying(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.wav")
+	out, err := NewOutput(OutputConfig{Backend: OutputWAV, Path: path})
+	require.NoError(t, err)
+
+	require.NoError(t, out.Close())
+	require.NoError(t, out.Close(), "closing twice is fine")
+	assert.Error(t, out.Init(44100))
+}
+
+func TestPlayer_PlaysThroughSink(t *testing.T) {
+	dir := t.TempDir()
+	samples := make([]byte, 0, 4000)
+	for i := range 2000 {
+		samples = binary.LittleEndian.AppendUint16(samples, uint16(int16(i%100*100)))
+	}
+	track := filepath.Join(dir, "track.wav")
+	require.NoError(t, os.WriteFile(track, wavFile(wavFmt(wavFormatPCM, 1, 8000, 16), samples), 0o600))
+
+	outPath := filepath.Join(dir, "out.wav")
+	out, err := NewOutput(OutputConfig{Backend: OutputWAV, Path: outPath})
+	require.NoError(t, err)
+	p := NewWithOutput(out)
+
+	require.NoError(t, p.Play(track))
+	assert.Equal(t, Playing, p.State())
+	select {
+	case <-p.FinishedChan():
+	case <-time.After(5 * time.Second):
+		t.Fatal("track did not finish")
+	}
+	require.NoError(t, p.Close())
+
+	data, err := os.ReadFile(outPath)
+	require.NoError(t, err)
+	d, rate := openPCMTestFile(t, "out.wav", data)
+	assert.Equal(t, 8000, rate, "the output runs at the rate of the first track")
+	got := drain(d)
+	require.Len(t, got, 2000)
+	for _, i := range []int{0, 42, 1999} {
+		want := float64(int16(i%100*100)) / 32768
+		assert.InDelta(t, want, got[i][0], 1e-4)
+		assert.InDelta(t, want, got[i][1], 1e-4)
+	}
+	// The visualizer tap saw the same audio
+	assert.Equal(t, uint64(2000), p.Tap().Written())
+	last := make([][2]float32, 1)
+	require.Equal(t, 1, p.Tap().Read(last))
+	assert.InDelta(t, float64(int16(1999%100*100))/32768, last[0][0], 1e-4)
+}
@@ -10,65 +10,70 @@ import (
 
 	"github.com/gopxl/beep/v2"
 	"github.com/gopxl/beep/v2/effects"
-	"github.com/gopxl/beep/v2/flac"
-	"github.com/gopxl/beep/v2/speaker"
 
+	"github.com/llehouerou/waves/internal/cue"
+	"github.com/llehouerou/waves/internal/icy"
 	"github.com/llehouerou/waves/internal/tags"
 )
 
 // openTrack opens and decodes an audio file, returning a trackState.
-func (p *Player) openTrack(path string) (*trackState, error) {
-	ext := strings.ToLower(filepath.Ext(path))
-	if ext != extMP3 && ext != extFLAC && ext != extOPUS && ext != extOGG && ext != extOGA && ext != extM4A && ext != extMP4 {
+// CUE sheet track paths open the section of the audio file they refer to,
+// and URLs open HTTP streams.
+// start is true when playback starts with the track: the output is then
+// opened, or reopened at the rate of the track when following the source.
+func (p *Player) openTrack(path string, start bool) (*trackState, error) {
+	if icy.IsURL(path) {
+		return p.openStream(path, start)
+	}
+
+	filePath := path
+	var cueTrack *cue.Section
+	if cue.IsTrackPath(path) {
+		section, err := p.cueSection(path)
+		if err != nil {
+			return nil, err
+		}
+		cueTrack = &section
+		filePath = section.AudioPath
+	}
+
+	ext := strings.ToLower(filepath.Ext(filePath))
+	if !isSupportedExt(ext) {
 		return nil, fmt.Errorf("unsupported format: %s", ext)
 	}
 
-	f, err := os.Open(path)
+	f, err := os.Open(filePath)
 	if err != nil {
 		return nil, err
 	}
 
-	var streamer beep.StreamSeekCloser
-	var format beep.Format
-	var m4aCodec string
-
-	switch ext {
-	case extMP3:
-		// go-mp3 v1.2.0+ handles LAME/Xing gapless info automatically
-		streamer, format, err = decodeGoMP3(f)
-	case extFLAC:
-		if err := skipID3v2(f); err != nil {
-			f.Close()
-			return nil, err
-		}
-		streamer, format, err = flac.Decode(f)
-	case extOPUS, extOGG, extOGA:
-		streamer, format, err = decodeOgg(f)
-	case extM4A, extMP4:
-		streamer, format, m4aCodec, err = decodeM4A(f)
-	}
+	streamer, format, m4aCodec, err := decodeFile(f, ext)
 	if err != nil {
 		f.Close()
 		return nil, err
 	}
 
-	// Initialize speaker on first track
-	if !speakerInitialized {
-		speakerSampleRate = format.SampleRate
-		err = speaker.Init(speakerSampleRate, speakerSampleRate.N(time.Second/10))
+	if cueTrack != nil {
+		start, end := cueTrack.Samples(int(format.SampleRate))
+		section, err := newRangeStreamer(streamer, start, end)
 		if err != nil {
 			streamer.Close()
 			f.Close()
-			return nil, err
+			return nil, fmt.Errorf("cue track %s: %w", filepath.Base(path), err)
 		}
-		speakerInitialized = true
+		streamer = section
 	}
+	loop := newLoopStreamer(streamer)
+	streamer = loop
 
-	// Resample if needed
-	var resampled beep.Streamer = streamer
-	if format.SampleRate != speakerSampleRate {
-		resampled = beep.Resample(4, format.SampleRate, speakerSampleRate, streamer)
+	if start {
+		if err := p.openOutput(format.SampleRate); err != nil {
+			streamer.Close()
+			f.Close()
+			return nil, err
+		}
 	}
+	resampled := p.resample(streamer, format.SampleRate)
 
 	// Build track info
 	tagInfo, _ := tags.Read(path)
@@ -82,27 +87,41 @@ func (p *Player) openTrack(path string) (*trackState, error) {
 	info.Duration = format.SampleRate.D(streamer.Len())
 	info.SampleRate = int(format.SampleRate)
 	info.BitDepth = format.Precision * 8
+	if cueTrack == nil {
+		info.Chapters, _ = tags.ReadChapters(filePath, info.Duration)
+	}
 	switch ext {
 	case extMP3:
 		info.Format = "MP3"
 	case extOPUS:
 		info.Format = "OPUS"
 	case extOGG, extOGA:
-		if IsOpusCodec(path) {
+		if IsOpusCodec(filePath) {
 			info.Format = "OPUS"
 		} else {
 			info.Format = "VORBIS"
 		}
-	case extM4A, extMP4:
+	case extM4A, extM4B, extMP4:
 		info.Format = m4aCodec
+	case extWAV, extWAVE:
+		info.Format = "WAV"
+	case extAIF, extAIFF, extAIFC:
+		info.Format = "AIFF"
+	case extWV:
+		info.Format = "WAVPACK"
 	default:
 		info.Format = "FLAC"
 	}
 
+	eq := p.newEQStreamer(resampled)
+
 	return &trackState{
 		file:      f,
 		streamer:  streamer,
+		loop:      loop,
 		resampled: resampled,
+		eq:        eq,
+		gain:      &gainStreamer{streamer: eq, scale: 1},
 		format:    format,
 		trackInfo: info,
 	}, nil
@@ -112,7 +131,7 @@ func (p *Player) openTrack(path string) (*trackState, error) {
 func (p *Player) Play(path string) error {
 	p.Stop()
 
-	// Small delay to let any pending Beep callback complete after speaker.Clear()
+	// Small delay to let any pending Beep callback complete after the output is cleared
 	time.Sleep(10 * time.Millisecond)
 
 	// Drain any stale finish signal from previous track
@@ -121,20 +140,25 @@ func (p *Player) Play(path string) error {
 	default:
 	}
 
-	track, err := p.openTrack(path)
+	track, err := p.openTrack(path, true)
 	if err != nil {
 		return err
 	}
+	p.applyReplayGain(track, p.albumContextValue())
 
 	p.current = track
 	p.clearNextTrack()
 
 	p.gapless = &gaplessStreamer{
-		current:  track.resampled,
-		onSwitch: p.handleGaplessTransition,
+		current:   track.gain,
+		onSwitch:  p.handleGaplessTransition,
+		onFaded:   p.handleCrossfadeEnd,
+		remaining: p.remainingSamples,
 	}
 
-	p.ctrl = &beep.Ctrl{Streamer: p.gapless, Paused: false}
+	p.tempo = newTempoStreamer(p.gapless, p.sampleRate, p.Speed())
+
+	p.ctrl = &beep.Ctrl{Streamer: p.tempo, Paused: false}
 	p.volume = &effects.Volume{
 		Streamer: p.ctrl,
 		Base:     2,
@@ -142,16 +166,18 @@ func (p *Player) Play(path string) error {
 		Silent:   p.muted,
 	}
 
-	p.state = Playing
+	p.setState(Playing)
 	p.done = make(chan struct{})
 
 	if p.monitorDone != nil {
 		close(p.monitorDone)
 	}
 	p.monitorDone = make(chan struct{})
-	go p.monitorLoop()
+	p.monitor.Add(1)
+	go p.monitorLoop(p.monitorDone)
 
-	speaker.Play(beep.Seq(p.volume, beep.Callback(func() {
+	p.out.Play(beep.Seq(tapStreamer{p.volume, p.tap}, beep.Callback(func() {
+		p.setState(Stopped)
 		close(p.done)
 		select {
 		case p.finishedCh <- struct{}{}:
@@ -165,8 +191,10 @@ func (p *Player) Play(path string) error {
 	return nil
 }
 
-// monitorLoop periodically checks if pre-loading should start.
-func (p *Player) monitorLoop() {
+// monitorLoop periodically checks if pre-loading should start, until done
+// is closed. done is passed in as Stop clears the field.
+func (p *Player) monitorLoop(done <-chan struct{}) {
+	defer p.monitor.Done()
 	ticker := time.NewTicker(500 * time.Millisecond)
 	defer ticker.Stop()
 
@@ -176,48 +204,73 @@ func (p *Player) monitorLoop() {
 			if p.shouldPreload() {
 				p.preloadNext()
 			}
-		case <-p.monitorDone:
+		case <-done:
 			return
 		}
 	}
 }
 
 // shouldPreload returns true if we should start pre-loading the next track.
+// It locks the output, which streams the track and switches to the next one.
 func (p *Player) shouldPreload() bool {
-	if p.current == nil || p.next != nil || p.preloadFn == nil {
+	p.out.Lock()
+	defer p.out.Unlock()
+	if p.current == nil || p.next != nil || p.nextNeedsGap || p.preloadFn == nil {
 		return false
 	}
-	if p.state != Playing {
+	if p.State() != Playing {
 		return false
 	}
 	remaining := p.Duration() - p.Position()
-	return remaining <= p.preloadAt && remaining > 0
+	return remaining <= p.preloadLead() && remaining > 0
 }
 
 // preloadNext loads the next track in the background.
 func (p *Player) preloadNext() {
-	path := p.preloadFn()
+	path, inAlbum := p.preloadFn()
 	if path == "" {
 		return
 	}
 
-	track, err := p.openTrack(path)
+	track, err := p.openTrack(path, false)
 	if err != nil {
 		return // Silent failure - fall back to non-gapless
 	}
 
-	speaker.Lock()
+	// Switching the output to another rate needs a gap: the track is opened
+	// again when the current one ends, and isn't pre-loaded until then.
+	if p.followsSourceRate() && track.format.SampleRate != p.sampleRate {
+		track.Close()
+		p.out.Lock()
+		p.nextNeedsGap = true
+		p.out.Unlock()
+		return
+	}
+
+	var prev *tags.FileInfo
+	if cur := p.current; cur != nil {
+		prev = cur.trackInfo
+	}
+	p.applyReplayGain(track, inAlbum)
+	fade, curve := p.crossfadeSamples(prev, track.trackInfo)
+
+	p.out.Lock()
 	p.next = track
 	if p.gapless != nil {
-		p.gapless.SetNext(track.resampled)
+		p.gapless.SetNextCrossfade(track.gain, fade, curve)
 	}
-	speaker.Unlock()
+	p.out.Unlock()
 }
 
 // handleGaplessTransition is called when the gapless streamer transitions.
-func (p *Player) handleGaplessTransition() {
+// On a crossfade the transition happens when the next track becomes audible,
+// and the previous track stays open until it has faded out.
+func (p *Player) handleGaplessTransition(crossfade bool) {
 	old := p.current
-	if old != nil {
+	if crossfade {
+		p.handleCrossfadeEnd()
+		p.fading = old
+	} else if old != nil {
 		go old.Close()
 	}
 
@@ -230,10 +283,18 @@ func (p *Player) handleGaplessTransition() {
 	}
 }
 
+// handleCrossfadeEnd releases the track that faded out.
+func (p *Player) handleCrossfadeEnd() {
+	if p.fading != nil {
+		go p.fading.Close()
+		p.fading = nil
+	}
+}
+
 // ClearPreload removes the pre-loaded next track.
 func (p *Player) ClearPreload() {
-	speaker.Lock()
-	defer speaker.Unlock()
+	p.out.Lock()
+	defer p.out.Unlock()
 	p.clearNextTrack()
 }
 
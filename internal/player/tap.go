@@ -0,0 +1,26 @@
+package player
+
+import (
+	"github.com/gopxl/beep/v2"
+
+	"github.com/llehouerou/waves/internal/visualizer"
+)
+
+// tapStreamer copies the audio sent to the output into a ring, for the
+// visualizer. The ring never blocks, so the audio callback isn't slowed
+// down by readers.
+type tapStreamer struct {
+	beep.Streamer
+	ring *visualizer.Ring
+}
+
+func (t tapStreamer) Stream(samples [][2]float64) (int, bool) {
+	n, ok := t.Streamer.Stream(samples)
+	t.ring.Write(samples[:n])
+	return n, ok
+}
+
+// Tap returns the ring holding the last audio sent to the output.
+func (p *Player) Tap() *visualizer.Ring {
+	return p.tap
+}
@@ -0,0 +1,254 @@
+package player
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+)
+
+// Limits of the playback speed.
+const (
+	MinSpeed = 0.5
+	MaxSpeed = 2.0
+)
+
+// Time-stretch parameters. Windows overlap by half, and each one may move
+// by up to tempoSearch to line up with the waveform of the previous one.
+const (
+	tempoFrame    = 40 * time.Millisecond
+	tempoSearch   = 12 * time.Millisecond
+	tempoCorrStep = 4 // Only every n-th sample is used to compare windows
+)
+
+var _ beep.Streamer = (*tempoStreamer)(nil)
+
+// tempoStreamer changes the playback speed without changing the pitch,
+// using WSOLA (waveform similarity overlap-add). At speed 1 the samples
+// are passed through untouched.
+// The speed is read in the audio callback, so it must be set under the output lock.
+type tempoStreamer struct {
+	streamer beep.Streamer
+	speed    float64
+
+	frame  int // Window length
+	hop    int // Output samples per window
+	search int
+	window []float64
+
+	active  bool         // Stretching, as opposed to passing through
+	drained bool         // Source exhausted and everything returned
+	in      [][2]float64 // Buffered input
+	ended   bool         // Source exhausted, `in` holds the rest of it
+	pos     float64      // Input position of the next window
+	prev    int          // Start of the previous window in `in`, -1 before the first one
+	tail    [][2]float64 // Fading half of the previous window
+	out     [][2]float64 // Samples ready to be returned
+	outPos  int
+}
+
+// newTempoStreamer wraps a streamer running at the given sample rate.
+func newTempoStreamer(s beep.Streamer, rate beep.SampleRate, speed float64) *tempoStreamer {
+	frame := max(rate.N(tempoFrame)&^1, 2*tempoCorrStep)
+	window := make([]float64, frame)
+	for i := range window {
+		// Periodic Hann: windows half a frame apart sum to exactly 1
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(frame))
+	}
+	return &tempoStreamer{
+		streamer: s,
+		speed:    clampSpeed(speed),
+		frame:    frame,
+		hop:      frame / 2,
+		search:   rate.N(tempoSearch),
+		window:   window,
+		tail:     make([][2]float64, frame/2),
+		prev:     -1,
+	}
+}
+
+// Stream implements beep.Streamer.
+func (t *tempoStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	for n < len(samples) {
+		if t.outPos < len(t.out) {
+			k := copy(samples[n:], t.out[t.outPos:])
+			t.outPos += k
+			n += k
+			continue
+		}
+		if t.speed == 1 && !t.active {
+			k, ok := t.streamer.Stream(samples[n:])
+			return n + k, ok || n+k > 0
+		}
+		if t.drained {
+			return n, n > 0
+		}
+		t.process()
+	}
+	return n, true
+}
+
+// Err implements beep.Streamer.
+func (t *tempoStreamer) Err() error {
+	return t.streamer.Err()
+}
+
+// reset drops the buffered audio, e.g. after a seek.
+func (t *tempoStreamer) reset() {
+	t.active = false
+	t.drained = false
+	t.in = t.in[:0]
+	t.out = t.out[:0]
+	t.outPos = 0
+}
+
+// process produces the next block of output into out.
+func (t *tempoStreamer) process() {
+	if !t.active {
+		t.in = t.in[:0]
+		t.ended = false
+		t.pos = 0
+		t.prev = -1
+		clear(t.tail)
+		t.active = true
+	}
+	if t.speed == 1 {
+		t.leave()
+		return
+	}
+
+	nominal := int(t.pos + 0.5)
+	if !t.fill(nominal + t.search + t.frame) {
+		// Play the last few milliseconds as they are
+		t.leave()
+		t.drained = true
+		return
+	}
+
+	start := t.bestStart(nominal)
+	t.out = t.out[:0]
+	t.outPos = 0
+	for i := range t.hop {
+		w := t.window[i]
+		if t.prev < 0 {
+			w = 1 // Nothing to fade from yet
+		}
+		s := t.in[start+i]
+		t.out = append(t.out, [2]float64{t.tail[i][0] + s[0]*w, t.tail[i][1] + s[1]*w})
+	}
+	for i := range t.hop {
+		w := t.window[t.hop+i]
+		s := t.in[start+t.hop+i]
+		t.tail[i] = [2]float64{s[0] * w, s[1] * w}
+	}
+	t.prev = start
+	t.pos += float64(t.hop) * t.speed
+	t.discard()
+}
+
+// bestStart returns the window start near nominal whose beginning best
+// matches how the previous window would have continued.
+func (t *tempoStreamer) bestStart(nominal int) int {
+	if t.prev < 0 {
+		return nominal
+	}
+	target := t.in[t.prev+t.hop : t.prev+t.frame]
+	best, bestScore := nominal, math.Inf(-1)
+	for c := max(nominal-t.search, 0); c <= nominal+t.search; c++ {
+		var corr, energy float64
+		for i := 0; i < t.hop; i += tempoCorrStep {
+			a := target[i][0] + target[i][1]
+			b := t.in[c+i][0] + t.in[c+i][1]
+			corr += a * b
+			energy += b * b
+		}
+		if score := corr / math.Sqrt(energy+1e-9); score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	return best
+}
+
+// leave stops stretching and queues the input that follows the previous
+// window unchanged. The transition is seamless, as it is the very signal
+// the fading half of that window was taken from.
+func (t *tempoStreamer) leave() {
+	from := 0
+	if t.prev >= 0 {
+		from = t.prev + t.hop
+	}
+	t.out = append(t.out[:0], t.in[min(from, len(t.in)):]...)
+	t.outPos = 0
+	t.in = t.in[:0]
+	t.active = false
+}
+
+// fill reads from the source until `in` holds need samples. It returns
+// false if the source is exhausted before.
+func (t *tempoStreamer) fill(need int) bool {
+	for len(t.in) < need && !t.ended {
+		l := len(t.in)
+		t.in = slices.Grow(t.in, need-l)[:need]
+		k, ok := t.streamer.Stream(t.in[l:])
+		t.in = t.in[:l+k]
+		if !ok || k == 0 {
+			t.ended = true
+		}
+	}
+	return len(t.in) >= need
+}
+
+// discard drops the input that no later window can use.
+func (t *tempoStreamer) discard() {
+	drop := min(int(t.pos+0.5)-t.search, t.prev)
+	if drop <= 0 {
+		return
+	}
+	n := copy(t.in, t.in[drop:])
+	t.in = t.in[:n]
+	t.pos -= float64(drop)
+	t.prev -= drop
+}
+
+// clampSpeed limits speed to the supported range. Zero means normal speed.
+func clampSpeed(speed float64) float64 {
+	if speed == 0 || math.IsNaN(speed) {
+		return 1
+	}
+	return max(min(speed, MaxSpeed), MinSpeed)
+}
+
+// scaleBySpeed converts a duration of listening time into track time.
+func scaleBySpeed(d time.Duration, speed float64) time.Duration {
+	return time.Duration(float64(d) * speed)
+}
+
+// speedSettings holds the playback speed of a Player.
+type speedSettings struct {
+	mu    sync.RWMutex
+	speed float64
+}
+
+// SetSpeed sets the playback speed, from MinSpeed to MaxSpeed.
+// The pitch is preserved. Position and Duration stay in track time.
+func (p *Player) SetSpeed(speed float64) {
+	speed = clampSpeed(speed)
+	p.speed.mu.Lock()
+	p.speed.speed = speed
+	p.speed.mu.Unlock()
+
+	p.out.Lock()
+	defer p.out.Unlock()
+	if p.tempo != nil {
+		p.tempo.speed = speed
+	}
+}
+
+// Speed returns the playback speed, 1 being normal speed.
+func (p *Player) Speed() float64 {
+	p.speed.mu.RLock()
+	defer p.speed.mu.RUnlock()
+	return clampSpeed(p.speed.speed)
+}
@@ -0,0 +1,129 @@
+package player
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// sineStreamer produces a sine wave of the given length.
+type sineStreamer struct {
+	freq, rate float64
+	samples    int
+	produced   int
+}
+
+func (s *sineStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	for n < len(samples) && s.produced < s.samples {
+		v := 0.5 * math.Sin(2*math.Pi*s.freq*float64(s.produced)/s.rate)
+		samples[n] = [2]float64{v, v}
+		n++
+		s.produced++
+	}
+	return n, n > 0
+}
+
+func (s *sineStreamer) Err() error { return nil }
+
+// drain reads a streamer until it is exhausted.
+func drain(s beep.Streamer) [][2]float64 {
+	var out [][2]float64
+	buf := make([][2]float64, 1000)
+	for {
+		n, ok := s.Stream(buf)
+		out = append(out, buf[:n]...)
+		if !ok {
+			return out
+		}
+	}
+}
+
+// zeroCrossingFreq estimates the frequency of a sine from its zero crossings.
+func zeroCrossingFreq(samples [][2]float64, rate float64) float64 {
+	crossings := 0
+	for i := 1; i < len(samples); i++ {
+		if (samples[i-1][0] < 0) != (samples[i][0] < 0) {
+			crossings++
+		}
+	}
+	return float64(crossings) / 2 / (float64(len(samples)) / rate)
+}
+
+func TestTempoStreamer_PassthroughAtNormalSpeed(t *testing.T) {
+	src := &mockStreamer{samples: 1500, sampleVal: 0.3}
+	ts := newTempoStreamer(src, 44100, 1)
+
+	out := drain(ts)
+
+	assert.Len(t, out, 1500)
+	for i := range out {
+		assert.Equal(t, 0.3, out[i][0])
+	}
+}
+
+func TestTempoStreamer_KeepsPitch(t *testing.T) {
+	const rate, freq = 44100.0, 440.0
+	for _, speed := range []float64{0.5, 0.8, 1.5, 2} {
+		src := &sineStreamer{freq: freq, rate: rate, samples: 2 * rate}
+		ts := newTempoStreamer(src, beep.SampleRate(rate), speed)
+
+		out := drain(ts)
+
+		want := 2 * rate / speed
+		// The end of the track, shorter than a window, plays unchanged
+		assert.InDelta(t, want, float64(len(out)), rate/10, "speed %v: length", speed)
+		// Skip the edges, where the first and last windows are
+		mid := out[len(out)/10 : len(out)*9/10]
+		assert.InDelta(t, freq, zeroCrossingFreq(mid, rate), freq*0.02, "speed %v: pitch", speed)
+	}
+}
+
+func TestTempoStreamer_ChangeSpeedIsSeamless(t *testing.T) {
+	src := &mockStreamer{samples: 20000, sampleVal: 0.5}
+	ts := newTempoStreamer(src, 44100, 1)
+	buf := make([][2]float64, 700)
+
+	var out [][2]float64
+	for _, speed := range []float64{1, 1.5, 0.7, 1, 2} {
+		ts.speed = speed
+		for range 5 {
+			n, _ := ts.Stream(buf)
+			out = append(out, buf[:n]...)
+		}
+	}
+	out = append(out, drain(ts)...)
+
+	// Overlapping windows sum to one, so a constant signal stays constant
+	for i := range out {
+		if math.Abs(out[i][0]-0.5) > 1e-9 {
+			t.Fatalf("sample %d = %v, want 0.5", i, out[i][0])
+		}
+	}
+}
+
+func TestTempoStreamer_ResetDropsBufferedAudio(t *testing.T) {
+	src := &mockStreamer{samples: 10000, sampleVal: 0.5}
+	ts := newTempoStreamer(src, 44100, 1.5)
+	buf := make([][2]float64, 500)
+	_, _ = ts.Stream(buf)
+
+	ts.reset()
+	src.sampleVal = 0.25 // As if the source was seeked elsewhere
+
+	n, ok := ts.Stream(buf)
+	assert.True(t, ok)
+	assert.Equal(t, 500, n)
+	assert.InDelta(t, 0.25, buf[0][0], 1e-9, "no audio from before the reset")
+}
+
+func TestSetSpeed_Clamps(t *testing.T) {
+	p := &Player{out: speakerOutput{}}
+	assert.InDelta(t, 1.0, p.Speed(), 0, "zero value is normal speed")
+
+	p.SetSpeed(3)
+	assert.InDelta(t, MaxSpeed, p.Speed(), 0)
+	p.SetSpeed(0.1)
+	assert.InDelta(t, MinSpeed, p.Speed(), 0)
+}
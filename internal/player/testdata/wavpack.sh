@@ -0,0 +1,22 @@
+#!/usr/bin/env bash
+set -euo pipefail
+
+# Makes the WavPack test files with the reference encoder (wavpack 5) from
+# a second of generated audio. -m stores the MD5 of the audio, which the
+# tests compare the decoded samples with.
+
+cd "$(dirname "$0")"
+tmp=$(mktemp -d)
+trap 'rm -rf "$tmp"' EXIT
+
+# A sine with noise on the left, another sine on the right
+signal="aevalsrc=0.6*sin(2*PI*440*t)+0.2*random(0)-0.1|0.5*sin(2*PI*660*t):d=1:s=44100"
+ffmpeg -loglevel error -f lavfi -i "$signal" -c:a pcm_s16le "$tmp/s16.wav"
+ffmpeg -loglevel error -f lavfi -i "$signal" -c:a pcm_s24le "$tmp/s24.wav"
+ffmpeg -loglevel error -f lavfi -i "$signal" -ac 1 -c:a pcm_s16le "$tmp/mono.wav"
+
+wavpack -q -y -m -j1 "$tmp/s16.wav" -o wavpack_16bit_stereo.wv
+wavpack -q -y -m -f "$tmp/s16.wav" -o wavpack_16bit_stereo_fast.wv
+wavpack -q -y -m -hh "$tmp/s24.wav" -o wavpack_24bit_stereo.wv
+wavpack -q -y -m "$tmp/mono.wav" -o wavpack_16bit_mono.wv
+wavpack -q -y -m -b320 "$tmp/s16.wav" -o wavpack_hybrid.wv
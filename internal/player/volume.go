@@ -1,10 +1,6 @@
 package player
 
-import (
-	"math"
-
-	"github.com/gopxl/beep/v2/speaker"
-)
+import "math"
 
 // SetVolume sets the volume level (0.0 to 1.0).
 // If muted, only stores the level without applying it.
@@ -24,9 +20,9 @@ func (p *Player) SetVolume(level float64) {
 
 	// Apply if not muted and volume effect exists
 	if !muted && p.volume != nil {
-		speaker.Lock()
+		p.out.Lock()
 		p.volume.Volume = p.levelToVolume(level)
-		speaker.Unlock()
+		p.out.Unlock()
 	}
 }
 
@@ -45,9 +41,9 @@ func (p *Player) SetMuted(muted bool) {
 	p.volMu.Unlock()
 
 	if p.volume != nil {
-		speaker.Lock()
+		p.out.Lock()
 		p.volume.Silent = muted
-		speaker.Unlock()
+		p.out.Unlock()
 	}
 }
 
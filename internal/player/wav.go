@@ -0,0 +1,153 @@
+package player
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gopxl/beep/v2"
+)
+
+// WAV format tags, also the first two bytes of WAVE_FORMAT_EXTENSIBLE sub-formats.
+const (
+	wavFormatPCM        = 0x0001
+	wavFormatFloat      = 0x0003
+	wavFormatExtensible = 0xFFFE
+)
+
+// wavHeader holds what is needed from the chunks of a WAV file.
+type wavHeader struct {
+	format     uint16
+	channels   int
+	sampleRate int
+	blockAlign int
+	bits       int
+	dataStart  int64
+	dataSize   int64 // -1 if unknown
+}
+
+// decodeWAV decodes a RIFF/RF64 WAVE file with PCM or IEEE float samples.
+func decodeWAV(rc io.ReadSeekCloser) (beep.StreamSeekCloser, beep.Format, error) {
+	h, err := readWAVHeader(rc)
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+
+	layout := pcmLayout{
+		channels: h.channels,
+		width:    h.blockAlign / h.channels,
+		float:    h.format == wavFormatFloat,
+		unsigned: h.bits <= 8,
+	}
+	frames, err := framesIn(rc, h.dataStart, h.dataSize, h.blockAlign)
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+	d, err := newPCMDecoder(rc, layout, h.dataStart, frames)
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+	return d, pcmFormat(h.sampleRate, layout), nil
+}
+
+// readWAVHeader walks the chunks up to the audio data.
+func readWAVHeader(r io.ReadSeeker) (*wavHeader, error) {
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return nil, fmt.Errorf("wav: read header: %w", err)
+	}
+	rf64 := string(riff[0:4]) == "RF64"
+	if (string(riff[0:4]) != "RIFF" && !rf64) || string(riff[8:12]) != "WAVE" {
+		return nil, errors.New("wav: not a RIFF WAVE file")
+	}
+
+	h := &wavHeader{dataSize: -1}
+	var gotFmt bool
+	var ds64Size int64 = -1
+	offset := int64(12)
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return nil, errors.New("wav: no data chunk")
+		}
+		id := string(chunk[0:4])
+		size := int64(binary.LittleEndian.Uint32(chunk[4:8]))
+		offset += 8
+
+		switch id {
+		case "fmt ":
+			if err := h.parseFmt(r, size); err != nil {
+				return nil, err
+			}
+			gotFmt = true
+		case "ds64":
+			var ds64 [16]byte
+			if size < 16 {
+				return nil, errors.New("wav: invalid ds64 chunk")
+			}
+			if _, err := io.ReadFull(r, ds64[:]); err != nil {
+				return nil, fmt.Errorf("wav: read ds64 chunk: %w", err)
+			}
+			ds64Size = int64(binary.LittleEndian.Uint64(ds64[8:16])) //nolint:gosec // sizes fit in int64
+		case "data":
+			if !gotFmt {
+				return nil, errors.New("wav: data chunk before fmt chunk")
+			}
+			h.dataStart = offset
+			switch {
+			case rf64 && size == 0xFFFFFFFF:
+				h.dataSize = ds64Size
+			case size != 0xFFFFFFFF && size != 0:
+				// Zero or all ones are written by encoders that stream their output
+				h.dataSize = size
+			}
+			return h, nil
+		}
+
+		// Chunks are padded to an even size
+		offset += size + size&1
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseFmt reads a "fmt " chunk of the given size.
+func (h *wavHeader) parseFmt(r io.Reader, size int64) error {
+	if size < 16 {
+		return errors.New("wav: invalid fmt chunk")
+	}
+	buf := make([]byte, min(size, 40))
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return fmt.Errorf("wav: read fmt chunk: %w", err)
+	}
+
+	h.format = binary.LittleEndian.Uint16(buf[0:2])
+	h.channels = int(binary.LittleEndian.Uint16(buf[2:4]))
+	h.sampleRate = int(binary.LittleEndian.Uint32(buf[4:8]))
+	h.blockAlign = int(binary.LittleEndian.Uint16(buf[12:14]))
+	h.bits = int(binary.LittleEndian.Uint16(buf[14:16]))
+	if h.format == wavFormatExtensible {
+		if len(buf) < 40 {
+			return errors.New("wav: invalid extensible fmt chunk")
+		}
+		// The sub-format GUID starts with the actual format tag
+		h.format = binary.LittleEndian.Uint16(buf[24:26])
+	}
+
+	if h.format != wavFormatPCM && h.format != wavFormatFloat {
+		return fmt.Errorf("wav: unsupported encoding: 0x%04x", h.format)
+	}
+	if h.channels == 0 || h.sampleRate == 0 {
+		return errors.New("wav: invalid fmt chunk")
+	}
+	if h.blockAlign == 0 || h.blockAlign%h.channels != 0 {
+		// Some writers leave it out, derive it from the sample size
+		h.blockAlign = h.channels * ((h.bits + 7) / 8)
+	}
+	if h.blockAlign == 0 {
+		return errors.New("wav: invalid sample size")
+	}
+	return nil
+}
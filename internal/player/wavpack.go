@@ -0,0 +1,299 @@
+package player
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/gopxl/beep/v2"
+)
+
+// WavPack block header flags.
+const (
+	wvMono         = 0x00000004
+	wvHybrid       = 0x00000008
+	wvJointStereo  = 0x00000010
+	wvFloatData    = 0x00000080
+	wvInitialBlock = 0x00000800
+	wvFalseStereo  = 0x40000000 // Mono coded stereo, both channels are identical
+	wvDSD          = 0x80000000
+)
+
+const (
+	wvHeaderSize = 32
+	// wvMaxBlockSamples bounds the memory used by a corrupted header.
+	wvMaxBlockSamples = 1 << 20
+)
+
+// wvSampleRates maps the sample rate index of the flags. 0 means the rate
+// is stored in a SAMPLE_RATE sub-block.
+var wvSampleRates = [16]int{
+	6000, 8000, 9600, 11025, 12000, 16000, 22050, 24000,
+	32000, 44100, 48000, 64000, 88200, 96000, 192000, 0,
+}
+
+// wvBlockHeader is the 32 byte header of a WavPack block.
+type wvBlockHeader struct {
+	size         int64 // Whole block, header included
+	version      uint16
+	blockIndex   int64
+	totalSamples int64 // -1 if unknown
+	samples      int
+	flags        uint32
+	crc          uint32
+}
+
+// parseWVHeader parses a block header.
+func parseWVHeader(b []byte) (wvBlockHeader, error) {
+	if len(b) < wvHeaderSize || string(b[0:4]) != "wvpk" {
+		return wvBlockHeader{}, errors.New("wavpack: invalid block header")
+	}
+	h := wvBlockHeader{
+		size:       int64(binary.LittleEndian.Uint32(b[4:8])) + 8,
+		version:    binary.LittleEndian.Uint16(b[8:10]),
+		blockIndex: int64(b[10])<<32 | int64(binary.LittleEndian.Uint32(b[16:20])),
+		samples:    int(binary.LittleEndian.Uint32(b[20:24])),
+		flags:      binary.LittleEndian.Uint32(b[24:28]),
+		crc:        binary.LittleEndian.Uint32(b[28:32]),
+	}
+	h.totalSamples = -1
+	if total := binary.LittleEndian.Uint32(b[12:16]); total != 0xFFFFFFFF {
+		h.totalSamples = int64(b[11])<<32 | int64(total)
+	}
+	if h.size < wvHeaderSize {
+		return wvBlockHeader{}, errors.New("wavpack: invalid block size")
+	}
+	if h.version < 0x402 || h.version > 0x410 {
+		return wvBlockHeader{}, fmt.Errorf("wavpack: unsupported version 0x%x", h.version)
+	}
+	if h.samples > wvMaxBlockSamples {
+		return wvBlockHeader{}, errors.New("wavpack: block too large")
+	}
+	return h, nil
+}
+
+// bytesPerSample returns the size of the original samples.
+func (h wvBlockHeader) bytesPerSample() int {
+	return int(h.flags&3) + 1
+}
+
+// shift returns how many low bits of the samples are always zero.
+func (h wvBlockHeader) shift() int {
+	return int(h.flags>>13) & 0x1F
+}
+
+// sampleRate returns the sample rate of the flags, or 0 if it is stored
+// in the block.
+func (h wvBlockHeader) sampleRate() int {
+	return wvSampleRates[h.flags>>23&0xF]
+}
+
+// wvIndexEntry locates the first block of a group of channels.
+type wvIndexEntry struct {
+	offset  int64
+	start   int // First sample, relative to the beginning of the file
+	samples int
+}
+
+// wavpackDecoder decodes lossless WavPack files. Only the first block of
+// each frame is decoded, which holds the first two channels.
+type wavpackDecoder struct {
+	rc     io.ReadSeekCloser
+	index  []wvIndexEntry
+	length int
+	pos    int
+
+	block    int          // Index entry of the decoded samples, -1 if none
+	samples  [][2]float64 // Decoded samples of the block
+	blockBuf []byte
+	err      error
+}
+
+// decodeWavPack decodes a WavPack file.
+func decodeWavPack(rc io.ReadSeekCloser) (beep.StreamSeekCloser, beep.Format, error) {
+	index, first, err := indexWavPack(rc)
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+	if first.flags&wvHybrid != 0 {
+		return nil, beep.Format{}, errors.New("wavpack: lossy (hybrid) files are not supported")
+	}
+	if first.flags&wvDSD != 0 {
+		return nil, beep.Format{}, errors.New("wavpack: DSD audio is not supported")
+	}
+
+	d := &wavpackDecoder{rc: rc, index: index, block: -1}
+	last := index[len(index)-1]
+	d.length = last.start + last.samples
+
+	rate := first.sampleRate()
+	if rate == 0 {
+		b, err := d.readBlock(0)
+		if err != nil {
+			return nil, beep.Format{}, err
+		}
+		rate = b.sampleRate
+		if rate == 0 {
+			return nil, beep.Format{}, errors.New("wavpack: unknown sample rate")
+		}
+	}
+
+	channels := 2
+	if first.flags&wvMono != 0 {
+		channels = 1
+	}
+	format := beep.Format{
+		SampleRate:  beep.SampleRate(rate),
+		NumChannels: channels,
+		Precision:   first.bytesPerSample(),
+	}
+	return d, format, nil
+}
+
+// indexWavPack reads the header of every block, so that seeking is cheap.
+// It returns the index and the header of the first block.
+func indexWavPack(r io.ReadSeeker) ([]wvIndexEntry, wvBlockHeader, error) {
+	var index []wvIndexEntry
+	var first wvBlockHeader
+	var buf [wvHeaderSize]byte
+	firstSample := int64(-1)
+	offset := int64(0)
+	for {
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return nil, first, err
+		}
+		if _, err := io.ReadFull(r, buf[:]); err != nil || string(buf[0:4]) != "wvpk" {
+			// End of the blocks, an APEv2 tag may follow
+			break
+		}
+		h, err := parseWVHeader(buf[:])
+		if err != nil {
+			if len(index) == 0 {
+				return nil, first, err
+			}
+			break
+		}
+		if h.flags&wvInitialBlock != 0 && h.samples > 0 {
+			if firstSample < 0 {
+				firstSample = h.blockIndex
+				first = h
+			}
+			index = append(index, wvIndexEntry{
+				offset:  offset,
+				start:   int(h.blockIndex - firstSample),
+				samples: h.samples,
+			})
+		}
+		offset += h.size
+	}
+	if len(index) == 0 {
+		return nil, first, errors.New("wavpack: no audio blocks")
+	}
+	return index, first, nil
+}
+
+// readBlock reads and parses the i-th indexed block.
+func (d *wavpackDecoder) readBlock(i int) (*wvBlock, error) {
+	if _, err := d.rc.Seek(d.index[i].offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	var head [wvHeaderSize]byte
+	if _, err := io.ReadFull(d.rc, head[:]); err != nil {
+		return nil, err
+	}
+	h, err := parseWVHeader(head[:])
+	if err != nil {
+		return nil, err
+	}
+	bodySize := int(h.size - wvHeaderSize)
+	if cap(d.blockBuf) < bodySize {
+		d.blockBuf = make([]byte, bodySize)
+	}
+	body := d.blockBuf[:bodySize]
+	if _, err := io.ReadFull(d.rc, body); err != nil {
+		return nil, fmt.Errorf("wavpack: read block: %w", err)
+	}
+	return parseWVBlock(h, body)
+}
+
+// decodeBlock decodes the i-th indexed block into d.samples.
+func (d *wavpackDecoder) decodeBlock(i int) error {
+	b, err := d.readBlock(i)
+	if err != nil {
+		return err
+	}
+	if b.hdr.flags&wvHybrid != 0 {
+		return errors.New("wavpack: lossy (hybrid) blocks are not supported")
+	}
+	if cap(d.samples) < b.hdr.samples {
+		d.samples = make([][2]float64, b.hdr.samples)
+	}
+	d.samples = d.samples[:b.hdr.samples]
+	if err := b.decode(d.samples); err != nil {
+		return err
+	}
+	d.block = i
+	return nil
+}
+
+// Stream implements beep.Streamer.
+func (d *wavpackDecoder) Stream(samples [][2]float64) (n int, ok bool) {
+	if d.err != nil {
+		return 0, false
+	}
+	for n < len(samples) && d.pos < d.length {
+		i := d.blockAt(d.pos)
+		if i != d.block {
+			if err := d.decodeBlock(i); err != nil {
+				d.err = err
+				break
+			}
+		}
+		entry := d.index[i]
+		if d.pos < entry.start {
+			// A gap between blocks, only in damaged files
+			d.pos = entry.start
+		}
+		k := copy(samples[n:], d.samples[d.pos-entry.start:])
+		n += k
+		d.pos += k
+	}
+	return n, n > 0
+}
+
+// blockAt returns the index entry holding sample p.
+func (d *wavpackDecoder) blockAt(p int) int {
+	i := sort.Search(len(d.index), func(i int) bool {
+		return d.index[i].start+d.index[i].samples > p
+	})
+	return min(i, len(d.index)-1)
+}
+
+// Err implements beep.Streamer.
+func (d *wavpackDecoder) Err() error {
+	return d.err
+}
+
+// Len returns the total number of samples.
+func (d *wavpackDecoder) Len() int {
+	return d.length
+}
+
+// Position returns the current sample position.
+func (d *wavpackDecoder) Position() int {
+	return d.pos
+}
+
+// Seek seeks to the given sample position.
+func (d *wavpackDecoder) Seek(p int) error {
+	d.pos = max(min(p, d.length), 0)
+	d.err = nil
+	return nil
+}
+
+// Close closes the underlying file.
+func (d *wavpackDecoder) Close() error {
+	return d.rc.Close()
+}
@@ -0,0 +1,632 @@
+package player
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// WavPack metadata sub-block IDs.
+const (
+	wvIDDecorrTerms   = 0x02
+	wvIDDecorrWeights = 0x03
+	wvIDDecorrSamples = 0x04
+	wvIDEntropyVars   = 0x05
+	wvIDFloatInfo     = 0x08
+	wvIDInt32Info     = 0x09
+	wvIDBitstream     = 0x0A
+	wvIDSampleRate    = 0x27
+
+	wvIDMask     = 0x3F
+	wvIDOddSize  = 0x40 // Last byte of the data is padding
+	wvIDLargeLen = 0x80 // 24-bit length
+)
+
+const wvMaxTerms = 16
+
+// wvDecorr is one decorrelation pass.
+type wvDecorr struct {
+	term, delta        int32
+	weightA, weightB   int32
+	samplesA, samplesB [8]int32
+}
+
+// wvBlock holds the state needed to decode the samples of one block.
+type wvBlock struct {
+	hdr     wvBlockHeader
+	stereo  bool // Two channels are coded, as opposed to mono or false stereo
+	decorrs []wvDecorr
+	words   wvWords
+	stream  wvBitReader
+
+	// Integer post-processing (INT32_INFO)
+	extraBits, shift int
+	and, or          uint32
+
+	// Float conversion (FLOAT_INFO)
+	isFloat              bool
+	floatShift, floatExp int
+	sampleRate           int // From the optional SAMPLE_RATE sub-block
+}
+
+// wvSubBlocks calls fn for each metadata sub-block of a block body.
+func wvSubBlocks(body []byte, fn func(id byte, data []byte) error) error {
+	for len(body) > 0 {
+		if len(body) < 2 {
+			return errors.New("wavpack: truncated sub-block")
+		}
+		id := body[0]
+		size := int(body[1])
+		head := 2
+		if id&wvIDLargeLen != 0 {
+			if len(body) < 4 {
+				return errors.New("wavpack: truncated sub-block")
+			}
+			size |= int(body[2])<<8 | int(body[3])<<16
+			head = 4
+		}
+		size *= 2 // Sizes are in 16-bit words
+		if len(body) < head+size {
+			return errors.New("wavpack: truncated sub-block")
+		}
+		data := body[head : head+size]
+		if id&wvIDOddSize != 0 && size > 0 {
+			data = data[:size-1]
+		}
+		if err := fn(id&wvIDMask, data); err != nil {
+			return err
+		}
+		body = body[head+size:]
+	}
+	return nil
+}
+
+// parseWVBlock reads the metadata of a block. body is the block after its header.
+func parseWVBlock(hdr wvBlockHeader, body []byte) (*wvBlock, error) {
+	b := &wvBlock{
+		hdr:    hdr,
+		stereo: hdr.flags&(wvMono|wvFalseStereo) == 0,
+	}
+	var gotBits bool
+	err := wvSubBlocks(body, func(id byte, data []byte) error {
+		var err error
+		switch id {
+		case wvIDDecorrTerms:
+			err = b.readTerms(data)
+		case wvIDDecorrWeights:
+			err = b.readWeights(data)
+		case wvIDDecorrSamples:
+			err = b.readSamples(data)
+		case wvIDEntropyVars:
+			err = b.readEntropy(data)
+		case wvIDInt32Info:
+			err = b.readInt32Info(data)
+		case wvIDFloatInfo:
+			if len(data) != 4 {
+				return errors.New("wavpack: invalid float info")
+			}
+			b.isFloat = true
+			b.floatShift, b.floatExp = int(data[1]), int(data[2])
+			if b.floatShift > 31 {
+				return errors.New("wavpack: invalid float shift")
+			}
+		case wvIDSampleRate:
+			if len(data) == 3 {
+				b.sampleRate = int(data[0]) | int(data[1])<<8 | int(data[2])<<16
+			}
+		case wvIDBitstream:
+			b.stream = wvBitReader{data: data}
+			gotBits = true
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if hdr.samples > 0 && !gotBits {
+		return nil, errors.New("wavpack: no audio data in block")
+	}
+	if hdr.flags&wvFloatData != 0 && !b.isFloat {
+		return nil, errors.New("wavpack: missing float info")
+	}
+	return b, nil
+}
+
+func (b *wvBlock) readTerms(data []byte) error {
+	if len(data) > wvMaxTerms {
+		return errors.New("wavpack: too many decorrelation terms")
+	}
+	// Terms are stored in the reverse order of application
+	b.decorrs = make([]wvDecorr, len(data))
+	for i, v := range data {
+		d := &b.decorrs[len(data)-1-i]
+		d.term = int32(v&0x1F) - 5
+		d.delta = int32(v >> 5)
+		if d.term == 0 || d.term < -3 || (d.term > 8 && d.term < 17) || d.term > 18 {
+			return fmt.Errorf("wavpack: invalid decorrelation term: %d", d.term)
+		}
+		if d.term < 0 && !b.stereo {
+			return errors.New("wavpack: cross-channel term in mono block")
+		}
+	}
+	return nil
+}
+
+func (b *wvBlock) readWeights(data []byte) error {
+	perTerm := 1
+	if b.stereo {
+		perTerm = 2
+	}
+	n := len(data) / perTerm
+	if n > len(b.decorrs) {
+		return errors.New("wavpack: too many decorrelation weights")
+	}
+	for i := range n {
+		d := &b.decorrs[len(b.decorrs)-1-i]
+		d.weightA = restoreWeight(int8(data[i*perTerm])) //nolint:gosec // signed byte
+		if b.stereo {
+			d.weightB = restoreWeight(int8(data[i*perTerm+1])) //nolint:gosec // signed byte
+		}
+	}
+	return nil
+}
+
+// restoreWeight expands a weight stored on 8 bits.
+func restoreWeight(v int8) int32 {
+	w := int32(v) * 8
+	if w > 0 {
+		w += (w + 64) >> 7
+	}
+	return w
+}
+
+func (b *wvBlock) readSamples(data []byte) error {
+	next := func() int32 {
+		if len(data) < 2 {
+			return 0
+		}
+		v := wvExp2(int16(binary.LittleEndian.Uint16(data))) //nolint:gosec // signed log value
+		data = data[2:]
+		return v
+	}
+	for i := len(b.decorrs) - 1; i >= 0 && len(data) > 0; i-- {
+		d := &b.decorrs[i]
+		switch {
+		case d.term > 8:
+			d.samplesA[0], d.samplesA[1] = next(), next()
+			if b.stereo {
+				d.samplesB[0], d.samplesB[1] = next(), next()
+			}
+		case d.term < 0:
+			d.samplesA[0], d.samplesB[0] = next(), next()
+		default:
+			for j := range d.term {
+				d.samplesA[j] = next()
+				if b.stereo {
+					d.samplesB[j] = next()
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (b *wvBlock) readEntropy(data []byte) error {
+	channels := 1
+	if b.stereo {
+		channels = 2
+	}
+	if len(data) != 6*channels {
+		return errors.New("wavpack: invalid entropy variables")
+	}
+	for c := range channels {
+		for i := range 3 {
+			v := int16(binary.LittleEndian.Uint16(data[(c*3+i)*2:])) //nolint:gosec // signed log value
+			b.words.ch[c].median[i] = uint32(wvExp2(v))              //nolint:gosec // medians are positive
+		}
+	}
+	return nil
+}
+
+func (b *wvBlock) readInt32Info(data []byte) error {
+	if len(data) != 4 {
+		return errors.New("wavpack: invalid int32 info")
+	}
+	sentBits, zeros, ones, dups := data[0], data[1], data[2], data[3]
+	switch {
+	case sentBits > 30:
+		return errors.New("wavpack: invalid int32 info")
+	case sentBits > 0:
+		// The low bits are in a correction stream we don't read
+		b.extraBits = int(sentBits)
+	case zeros > 0:
+		b.shift = int(zeros)
+	case ones > 0:
+		b.and, b.or, b.shift = 1, 1, int(ones)
+	case dups > 0:
+		b.and, b.shift = 1, int(dups)
+	}
+	if b.shift > 31 {
+		return errors.New("wavpack: invalid int32 shift")
+	}
+	return nil
+}
+
+// decode decodes the samples of the block into out, scaled to [-1, 1].
+func (b *wvBlock) decode(out [][2]float64) error {
+	crc := uint32(0xFFFFFFFF)
+	pos := 0
+	for i := range out {
+		l, ok := b.words.value(&b.stream, 0)
+		if !ok {
+			return errors.New("wavpack: truncated block")
+		}
+		if !b.stereo {
+			s := b.decorrMono(l, pos)
+			crc = crc*3 + uint32(s) //nolint:gosec // CRC arithmetic
+			v := b.convert(s)
+			out[i] = [2]float64{v, v}
+			pos = (pos + 1) & 7
+			continue
+		}
+
+		r, ok := b.words.value(&b.stream, 1)
+		if !ok {
+			return errors.New("wavpack: truncated block")
+		}
+		l, r = b.decorrStereo(l, r, pos)
+		if b.hdr.flags&wvJointStereo != 0 {
+			r -= l >> 1
+			l += r
+		}
+		crc = (crc*3+uint32(l))*3 + uint32(r) //nolint:gosec // CRC arithmetic
+		out[i] = [2]float64{b.convert(l), b.convert(r)}
+		pos = (pos + 1) & 7
+	}
+	if crc != b.hdr.crc {
+		return errors.New("wavpack: CRC mismatch")
+	}
+	return nil
+}
+
+// applyWeight returns the prediction of a decorrelation pass.
+func applyWeight(weight, sample int32) int32 {
+	return int32((int64(weight)*int64(sample) + 512) >> 10) //nolint:gosec // wraps like the reference decoder
+}
+
+// updateWeight adapts weight towards reducing the residual.
+func updateWeight(weight *int32, delta, sample, residual int32) {
+	if sample == 0 || residual == 0 {
+		return
+	}
+	if (sample ^ residual) < 0 {
+		*weight -= delta
+	} else {
+		*weight += delta
+	}
+}
+
+// updateWeightClip is updateWeight for cross-channel passes, which keep
+// their weights within ±1024.
+func updateWeightClip(weight *int32, delta, sample, residual int32) {
+	updateWeight(weight, delta, sample, residual)
+	*weight = max(min(*weight, 1024), -1024)
+}
+
+// predictFrom returns the input of a positive decorrelation pass from its
+// history, and the history slot the output goes to.
+func predictFrom(term int32, samples *[8]int32, pos int) (input int32, slot int) {
+	if term > 8 {
+		s0, s1 := samples[0], samples[1]
+		samples[1] = s0
+		if term&1 != 0 {
+			return 2*s0 - s1, 0
+		}
+		return (3*s0 - s1) >> 1, 0
+	}
+	return samples[pos], (pos + int(term)) & 7
+}
+
+// decorrMono reverses the decorrelation passes of a mono sample.
+func (b *wvBlock) decorrMono(s int32, pos int) int32 {
+	for i := range b.decorrs {
+		d := &b.decorrs[i]
+		a, slot := predictFrom(d.term, &d.samplesA, pos)
+		out := s + applyWeight(d.weightA, a)
+		updateWeight(&d.weightA, d.delta, a, s)
+		d.samplesA[slot] = out
+		s = out
+	}
+	return s
+}
+
+// decorrStereo reverses the decorrelation passes of a stereo sample.
+func (b *wvBlock) decorrStereo(l, r int32, pos int) (int32, int32) {
+	for i := range b.decorrs {
+		d := &b.decorrs[i]
+		switch {
+		case d.term > 0:
+			a, slot := predictFrom(d.term, &d.samplesA, pos)
+			bb, _ := predictFrom(d.term, &d.samplesB, pos)
+			l2 := l + applyWeight(d.weightA, a)
+			r2 := r + applyWeight(d.weightB, bb)
+			updateWeight(&d.weightA, d.delta, a, l)
+			updateWeight(&d.weightB, d.delta, bb, r)
+			d.samplesA[slot], d.samplesB[slot] = l2, r2
+			l, r = l2, r2
+		case d.term == -1:
+			l2 := l + applyWeight(d.weightA, d.samplesA[0])
+			updateWeightClip(&d.weightA, d.delta, d.samplesA[0], l)
+			l = l2
+			r2 := r + applyWeight(d.weightB, l2)
+			updateWeightClip(&d.weightB, d.delta, l2, r)
+			r = r2
+			d.samplesA[0] = r
+		default: // -2 and -3
+			r2 := r + applyWeight(d.weightB, d.samplesB[0])
+			updateWeightClip(&d.weightB, d.delta, d.samplesB[0], r)
+			r = r2
+			if d.term == -3 {
+				r2 = d.samplesA[0]
+				d.samplesA[0] = r
+			}
+			l2 := l + applyWeight(d.weightA, r2)
+			updateWeightClip(&d.weightA, d.delta, r2, l)
+			l = l2
+			d.samplesB[0] = l
+		}
+	}
+	return l, r
+}
+
+// convert turns a decoded sample into [-1, 1].
+func (b *wvBlock) convert(s int32) float64 {
+	if b.isFloat {
+		return float64(b.toFloat(s))
+	}
+	v := uint32(s) << b.extraBits //nolint:gosec // bit manipulation
+	bit := (v & b.and) | b.or
+	v = ((v+bit)<<b.shift - bit) << b.hdr.shift()
+	return float64(int32(v)) / float64(uint32(1)<<(b.hdr.bytesPerSample()*8-1)) //nolint:gosec // bit manipulation
+}
+
+// toFloat rebuilds a float sample from its integer mantissa.
+func (b *wvBlock) toFloat(s int32) float32 {
+	if s == 0 {
+		return 0
+	}
+	v := uint32(s) << b.floatShift //nolint:gosec // bit manipulation
+	sign := uint32(0)
+	if int32(v) < 0 { //nolint:gosec // bit manipulation
+		sign = 1
+		v = -v
+	}
+	exp := uint32(b.floatExp) //nolint:gosec // from a byte
+	switch {
+	case v >= 0x1000000:
+		// Infinity or NaN, whose payload is in the correction stream
+		v, exp = 0, 255
+	case exp > 0:
+		shift := uint32(23 - (31 - bits.LeadingZeros32(v)))
+		if exp <= shift {
+			exp--
+			shift = exp
+		}
+		exp -= shift
+		v <<= shift
+	}
+	return math.Float32frombits(sign<<31 | exp<<23 | v&0x7FFFFF)
+}
+
+// wvExp2Table holds the fraction part of 2^x in 1/256 steps.
+var wvExp2Table = func() (t [256]uint32) {
+	for i := range t {
+		t[i] = uint32(math.Round(256 * (math.Exp2(float64(i)/256) - 1)))
+	}
+	return t
+}()
+
+// wvExp2 decodes a value stored in WavPack's 8.8 fixed point log format.
+func wvExp2(v int16) int32 {
+	val := int32(v)
+	neg := val < 0
+	if neg {
+		val = -val
+	}
+	res := int32(wvExp2Table[val&0xFF] | 0x100) //nolint:gosec // at most 0x1FF
+	val >>= 8
+	if val > 31 {
+		return math.MinInt32
+	}
+	if val > 9 {
+		res <<= val - 9
+	} else {
+		res >>= 9 - val
+	}
+	if neg {
+		return -res
+	}
+	return res
+}
+
+// wvChannel holds the adaptive entropy coder state of one channel.
+type wvChannel struct {
+	median [3]uint32
+}
+
+// getMed returns the step of the n-th median.
+func (c *wvChannel) getMed(n int) uint32 {
+	return c.median[n]>>4 + 1
+}
+
+func (c *wvChannel) incMed(n int) {
+	div := uint32(128 >> n)
+	c.median[n] += (c.median[n] + div) / div * 5
+}
+
+func (c *wvChannel) decMed(n int) {
+	div := uint32(128 >> n)
+	c.median[n] -= (c.median[n] + div - 2) / div * 2
+}
+
+// wvWords decodes the residuals of the lossless entropy coder.
+type wvWords struct {
+	ch        [2]wvChannel
+	zero, one bool   // The ones count of the next value is zero, or at least one
+	zeroes    uint32 // Remaining values of a run of zeros
+}
+
+// value decodes the next residual of channel c.
+func (w *wvWords) value(br *wvBitReader, c int) (int32, bool) {
+	ch := &w.ch[c]
+
+	// Runs of silence are coded as a count, while the medians are tiny
+	if w.ch[0].median[0] < 2 && w.ch[1].median[0] < 2 && !w.zero && !w.one {
+		if w.zeroes > 0 {
+			w.zeroes--
+			if w.zeroes > 0 {
+				return 0, true
+			}
+		} else {
+			t, ok := br.eliasGamma()
+			if !ok {
+				return 0, false
+			}
+			w.zeroes = t
+			if w.zeroes > 0 {
+				w.ch[0].median = [3]uint32{}
+				w.ch[1].median = [3]uint32{}
+				return 0, true
+			}
+		}
+	}
+
+	var t uint32
+	if w.zero {
+		w.zero = false
+	} else {
+		t = br.unary()
+		if t == 16 {
+			t2, ok := br.eliasGamma()
+			if !ok {
+				return 0, false
+			}
+			t += t2
+		}
+		if br.overrun() {
+			return 0, false
+		}
+		if w.one {
+			w.one = t&1 != 0
+			t = t>>1 + 1
+		} else {
+			w.one = t&1 != 0
+			t >>= 1
+		}
+		w.zero = !w.one
+	}
+
+	var base, add uint32
+	switch t {
+	case 0:
+		add = ch.getMed(0) - 1
+		ch.decMed(0)
+	case 1:
+		base = ch.getMed(0)
+		add = ch.getMed(1) - 1
+		ch.incMed(0)
+		ch.decMed(1)
+	case 2:
+		base = ch.getMed(0) + ch.getMed(1)
+		add = ch.getMed(2) - 1
+		ch.incMed(0)
+		ch.incMed(1)
+		ch.decMed(2)
+	default:
+		base = ch.getMed(0) + ch.getMed(1) + ch.getMed(2)*(t-2)
+		add = ch.getMed(2) - 1
+		ch.incMed(0)
+		ch.incMed(1)
+		ch.incMed(2)
+	}
+	if add >= 0x2000000 {
+		return 0, false
+	}
+	v := base + br.tail(add)
+	sign := br.bit()
+	if br.overrun() {
+		return 0, false
+	}
+	if sign != 0 {
+		return ^int32(v), true //nolint:gosec // sign and magnitude
+	}
+	return int32(v), true //nolint:gosec // sign and magnitude
+}
+
+// wvBitReader reads a WavPack bitstream, least significant bit first.
+type wvBitReader struct {
+	data []byte
+	pos  int // In bits
+}
+
+// overrun returns true if more bits were read than there are.
+func (r *wvBitReader) overrun() bool {
+	return r.pos > len(r.data)*8
+}
+
+func (r *wvBitReader) bit() uint32 {
+	i, shift := r.pos>>3, r.pos&7
+	r.pos++
+	if i >= len(r.data) {
+		return 0
+	}
+	return uint32(r.data[i]>>shift) & 1
+}
+
+// bits reads n bits, the first one being the least significant.
+func (r *wvBitReader) bits(n int) uint32 {
+	var v uint32
+	for i := range n {
+		v |= r.bit() << i
+	}
+	return v
+}
+
+// unary counts the ones before the next zero, up to 33.
+func (r *wvBitReader) unary() uint32 {
+	var n uint32
+	for n < 33 && r.bit() != 0 {
+		n++
+	}
+	return n
+}
+
+// eliasGamma reads a count coded as the unary length of its bits
+// followed by the bits below the leading one.
+func (r *wvBitReader) eliasGamma() (uint32, bool) {
+	t := r.unary()
+	if t >= 2 {
+		if t >= 32 {
+			return 0, false
+		}
+		t = r.bits(int(t-1)) | 1<<(t-1)
+	}
+	return t, !r.overrun()
+}
+
+// tail reads a value from 0 to k, where values below the next power of two
+// take one bit less.
+func (r *wvBitReader) tail(k uint32) uint32 {
+	if k < 1 {
+		return 0
+	}
+	p := 31 - bits.LeadingZeros32(k)
+	e := uint32(1)<<(p+1) - k - 1
+	v := r.bits(p)
+	if v >= e {
+		v = v<<1 - e + r.bit()
+	}
+	return v
+}
@@ -0,0 +1,568 @@
+package player
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wvBitWriter writes a bitstream, least significant bit first.
+type wvBitWriter struct {
+	data []byte
+	n    int
+}
+
+func (w *wvBitWriter) bit(b uint32) {
+	if w.n&7 == 0 {
+		w.data = append(w.data, 0)
+	}
+	w.data[len(w.data)-1] |= byte(b&1) << (w.n & 7)
+	w.n++
+}
+
+func (w *wvBitWriter) bits(v uint32, n int) {
+	for i := range n {
+		w.bit(v >> i)
+	}
+}
+
+func (w *wvBitWriter) unary(n uint32) {
+	for range n {
+		w.bit(1)
+	}
+	w.bit(0)
+}
+
+func (w *wvBitWriter) eliasGamma(n uint32) {
+	if n < 2 {
+		w.unary(n)
+		return
+	}
+	p := bits.Len32(n)
+	w.unary(uint32(p))
+	w.bits(n, p-1)
+}
+
+func (w *wvBitWriter) tail(v, k uint32) {
+	if k < 1 {
+		return
+	}
+	p := 31 - bits.LeadingZeros32(k)
+	e := uint32(1)<<(p+1) - k - 1
+	if v < e {
+		w.bits(v, p)
+		return
+	}
+	v += e
+	w.bits(v>>1, p)
+	w.bit(v & 1)
+}
+
+// wvTestEncoder produces lossless WavPack blocks by running the decoder
+// state machine backwards. It is not a real encoder, it only picks the
+// parameters it is given, but it exercises every part of the decoder.
+type wvTestEncoder struct {
+	flags   uint32
+	terms   []int32 // In the order they are stored
+	delta   int32
+	medians int16 // Initial value of all medians, in log format
+}
+
+// wvTestMedians is the encoder's copy of the adaptive medians of one
+// channel, kept apart from the decoder so that a mistake in one shows up as
+// a mismatch instead of cancelling out.
+type wvTestMedians [3]uint32
+
+// wvTestMedianDiv holds the adaptation rate of each median.
+var wvTestMedianDiv = [3]uint32{128, 64, 32}
+
+// step returns the width of the n-th median band.
+func (m *wvTestMedians) step(n int) uint32 { return m[n]/16 + 1 }
+
+func (m *wvTestMedians) inc(n int) { m[n] += (m[n] + wvTestMedianDiv[n]) / wvTestMedianDiv[n] * 5 }
+
+func (m *wvTestMedians) dec(n int) { m[n] -= (m[n] + wvTestMedianDiv[n] - 2) / wvTestMedianDiv[n] * 2 }
+
+// wvOnes returns how the entropy coder splits magnitude v, updating the
+// medians when update is set.
+func wvOnes(m *wvTestMedians, v uint32, update bool) (ones, base, add uint32) {
+	saved := *m
+	switch {
+	case v < m.step(0):
+		add = m.step(0) - 1
+		m.dec(0)
+	case v-m.step(0) < m.step(1):
+		ones, base, add = 1, m.step(0), m.step(1)-1
+		m.inc(0)
+		m.dec(1)
+	case v-m.step(0)-m.step(1) < m.step(2):
+		ones, base, add = 2, m.step(0)+m.step(1), m.step(2)-1
+		m.inc(0)
+		m.inc(1)
+		m.dec(2)
+	default:
+		base = m.step(0) + m.step(1)
+		ones = 2 + (v-base)/m.step(2)
+		base += (ones - 2) * m.step(2)
+		add = m.step(2) - 1
+		m.inc(0)
+		m.inc(1)
+		m.inc(2)
+	}
+	if !update {
+		*m = saved
+	}
+	return ones, base, add
+}
+
+// encodeWords codes interleaved residuals.
+func (e *wvTestEncoder) encodeWords(vals []int32, channels int) []byte {
+	var w wvBitWriter
+	var med [2]wvTestMedians
+	var zero, one bool
+	var zeroes uint32
+	// The stored medians are whole powers of two, 2^(v/256-1)
+	initial := uint32(math.Exp2(float64(e.medians)/256 - 1))
+	for c := range channels {
+		med[c] = wvTestMedians{initial, initial, initial}
+	}
+	magnitude := func(v int32) uint32 {
+		if v < 0 {
+			return uint32(^v)
+		}
+		return uint32(v)
+	}
+
+	for i := 0; i < len(vals); i++ {
+		if med[0][0] < 2 && med[1][0] < 2 && !zero && !one {
+			if zeroes > 0 {
+				zeroes--
+				if zeroes > 0 {
+					continue
+				}
+			} else {
+				run := uint32(0)
+				for i+int(run) < len(vals) && vals[i+int(run)] == 0 {
+					run++
+				}
+				w.eliasGamma(run)
+				zeroes = run
+				if run > 0 {
+					med = [2]wvTestMedians{}
+					continue
+				}
+			}
+		}
+
+		m := magnitude(vals[i])
+		ones, base, add := wvOnes(&med[i%channels], m, true)
+		if zero {
+			zero = false
+		} else {
+			next := uint32(0)
+			if i+1 < len(vals) {
+				if n, _, _ := wvOnes(&med[(i+1)%channels], magnitude(vals[i+1]), false); n > 0 {
+					next = 1
+				}
+			}
+			k := ones
+			if one {
+				k--
+			}
+			t := 2*k + next
+			if t >= 16 {
+				w.unary(16)
+				w.eliasGamma(t - 16)
+			} else {
+				w.unary(t)
+			}
+			one = next == 1
+			zero = !one
+		}
+		w.tail(m-base, add)
+		if vals[i] < 0 {
+			w.bit(1)
+		} else {
+			w.bit(0)
+		}
+	}
+	return w.data
+}
+
+// decorrelate undoes the decoder passes, turning samples into residuals.
+func (e *wvTestEncoder) decorrelate(l, r []int32) {
+	stereo := r != nil
+	passes := make([]wvDecorr, len(e.terms))
+	for i := range passes {
+		passes[i] = wvDecorr{term: e.terms[len(e.terms)-1-i], delta: e.delta}
+	}
+	for n := range l {
+		pos := n & 7
+		for i := len(passes) - 1; i >= 0; i-- {
+			d := &passes[i]
+			switch {
+			case d.term > 0:
+				a, slot := predictFrom(d.term, &d.samplesA, pos)
+				out := l[n]
+				l[n] -= applyWeight(d.weightA, a)
+				updateWeight(&d.weightA, d.delta, a, l[n])
+				d.samplesA[slot] = out
+				if stereo {
+					b, _ := predictFrom(d.term, &d.samplesB, pos)
+					out := r[n]
+					r[n] -= applyWeight(d.weightB, b)
+					updateWeight(&d.weightB, d.delta, b, r[n])
+					d.samplesB[slot] = out
+				}
+			case d.term == -1:
+				outL, outR := l[n], r[n]
+				l[n] -= applyWeight(d.weightA, d.samplesA[0])
+				updateWeightClip(&d.weightA, d.delta, d.samplesA[0], l[n])
+				r[n] -= applyWeight(d.weightB, outL)
+				updateWeightClip(&d.weightB, d.delta, outL, r[n])
+				d.samplesA[0] = outR
+			default:
+				outL, outR := l[n], r[n]
+				r[n] -= applyWeight(d.weightB, d.samplesB[0])
+				updateWeightClip(&d.weightB, d.delta, d.samplesB[0], r[n])
+				fromR := outR
+				if d.term == -3 {
+					fromR = d.samplesA[0]
+					d.samplesA[0] = outR
+				}
+				l[n] -= applyWeight(d.weightA, fromR)
+				updateWeightClip(&d.weightA, d.delta, fromR, l[n])
+				d.samplesB[0] = outL
+			}
+		}
+	}
+}
+
+// block encodes one block. r is nil for mono.
+func (e *wvTestEncoder) block(index, total int, l, r []int32) []byte {
+	crc := uint32(0xFFFFFFFF)
+	for n := range l {
+		if r == nil {
+			crc = crc*3 + uint32(l[n])
+		} else {
+			crc = (crc*3+uint32(l[n]))*3 + uint32(r[n])
+		}
+	}
+
+	l = append([]int32(nil), l...)
+	channels := 1
+	if r != nil {
+		channels = 2
+		r = append([]int32(nil), r...)
+		if e.flags&wvJointStereo != 0 {
+			for n := range l {
+				l[n] -= r[n]
+				r[n] += l[n] >> 1
+			}
+		}
+	}
+	e.decorrelate(l, r)
+
+	vals := l
+	if r != nil {
+		vals = make([]int32, 0, 2*len(l))
+		for n := range l {
+			vals = append(vals, l[n], r[n])
+		}
+	}
+
+	var body []byte
+	sub := func(id byte, data []byte) {
+		if len(data)&1 != 0 {
+			id |= wvIDOddSize
+			data = append(data, 0)
+		}
+		words := len(data) / 2
+		if words > 255 {
+			body = append(body, id|wvIDLargeLen, byte(words), byte(words>>8), byte(words>>16))
+		} else {
+			body = append(body, id, byte(words))
+		}
+		body = append(body, data...)
+	}
+	terms := make([]byte, len(e.terms))
+	for i, t := range e.terms {
+		terms[i] = byte(t+5) | byte(e.delta)<<5
+	}
+	sub(wvIDDecorrTerms, terms)
+	sub(wvIDDecorrWeights, make([]byte, len(e.terms)*channels))
+	entropy := make([]byte, 0, 12)
+	for range 3 * channels {
+		entropy = binary.LittleEndian.AppendUint16(entropy, uint16(e.medians))
+	}
+	sub(wvIDEntropyVars, entropy)
+	sub(wvIDBitstream, e.encodeWords(vals, channels))
+
+	flags := e.flags | wvInitialBlock | 0x1000 // Final block
+	if r == nil {
+		flags |= wvMono
+	}
+	head := make([]byte, 0, wvHeaderSize)
+	head = append(head, "wvpk"...)
+	head = binary.LittleEndian.AppendUint32(head, uint32(wvHeaderSize+len(body)-8))
+	head = binary.LittleEndian.AppendUint16(head, 0x410)
+	head = append(head, 0, 0)
+	head = binary.LittleEndian.AppendUint32(head, uint32(total))
+	head = binary.LittleEndian.AppendUint32(head, uint32(index))
+	head = binary.LittleEndian.AppendUint32(head, uint32(len(l)))
+	head = binary.LittleEndian.AppendUint32(head, flags)
+	head = binary.LittleEndian.AppendUint32(head, crc)
+	return append(head, body...)
+}
+
+// file encodes samples in blocks of blockSize. r is nil for mono.
+func (e *wvTestEncoder) file(l, r []int32, blockSize int) []byte {
+	var out []byte
+	for start := 0; start < len(l); start += blockSize {
+		end := min(start+blockSize, len(l))
+		var rb []int32
+		if r != nil {
+			rb = r[start:end]
+		}
+		out = append(out, e.block(start, len(l), l[start:end], rb)...)
+	}
+	return out
+}
+
+// testSignal returns a signal with silence, a sine and full scale noise,
+// so that runs of zeros and large residuals are both coded.
+func testSignal(n, fullScale int, phase float64) []int32 {
+	s := make([]int32, n)
+	seed := uint32(12345 + phase*1000)
+	for i := n / 5; i < n; i++ {
+		v := 0.6 * math.Sin(2*math.Pi*440*float64(i)/44100+phase)
+		if i > n*4/5 {
+			seed = seed*1664525 + 1013904223
+			v = float64(seed)/(1<<31) - 1
+		}
+		s[i] = int32(math.Round(v * float64(fullScale-1)))
+	}
+	return s
+}
+
+func decodeWavPackBytes(t *testing.T, data []byte) *wavpackDecoder {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.wv")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	s, _, err := decodeWavPack(f)
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	return s.(*wavpackDecoder)
+}
+
+func TestDecodeWavPack_Stereo(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags uint32
+		terms []int32
+	}{
+		{"no decorrelation", 0, nil},
+		{"joint stereo", wvJointStereo, []int32{18, 17, 2, 1}},
+		{"cross channel", wvJointStereo, []int32{-1, -2, -3, 3, 17}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, r := testSignal(8000, 1<<15, 0), testSignal(8000, 1<<15, 1)
+			enc := &wvTestEncoder{flags: tt.flags | 1 | 9<<23, terms: tt.terms, delta: 2, medians: 0x0800}
+			d := decodeWavPackBytes(t, enc.file(l, r, 3000))
+
+			assert.Equal(t, 8000, d.Len())
+			out := drain(d)
+			require.NoError(t, d.Err())
+			require.Len(t, out, 8000)
+			for i := range out {
+				require.InDelta(t, float64(l[i])/32768, out[i][0], 1e-12, "left sample %d", i)
+				require.InDelta(t, float64(r[i])/32768, out[i][1], 1e-12, "right sample %d", i)
+			}
+		})
+	}
+}
+
+func TestDecodeWavPack_Mono24Bit(t *testing.T) {
+	s := testSignal(5000, 1<<23, 0)
+	enc := &wvTestEncoder{flags: 2 | 10<<23, terms: []int32{17, 2}, delta: 2, medians: 0x0C00}
+	path := filepath.Join(t.TempDir(), "mono.wv")
+	require.NoError(t, os.WriteFile(path, enc.file(s, nil, 5000), 0o600))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	d, format, err := decodeWavPack(f)
+	require.NoError(t, err)
+	defer d.Close()
+
+	assert.Equal(t, 48000, int(format.SampleRate))
+	assert.Equal(t, 1, format.NumChannels)
+	assert.Equal(t, 3, format.Precision)
+	out := drain(d)
+	require.Len(t, out, 5000)
+	for i := range out {
+		want := float64(s[i]) / (1 << 23)
+		require.InDelta(t, want, out[i][0], 1e-12, "sample %d", i)
+		require.InDelta(t, want, out[i][1], 1e-12, "sample %d", i)
+	}
+}
+
+func TestDecodeWavPack_Seek(t *testing.T) {
+	l := testSignal(10000, 1<<15, 0)
+	enc := &wvTestEncoder{flags: 1 | 9<<23, terms: []int32{18, 2}, delta: 2, medians: 0x0800}
+	d := decodeWavPackBytes(t, enc.file(l, l, 1024))
+
+	for _, p := range []int{7000, 1023, 1024, 0, 9999} {
+		require.NoError(t, d.Seek(p))
+		assert.Equal(t, p, d.Position())
+		buf := make([][2]float64, 1)
+		n, ok := d.Stream(buf)
+		require.True(t, ok)
+		require.Equal(t, 1, n)
+		assert.InDelta(t, float64(l[p])/32768, buf[0][0], 1e-12, "sample after seeking to %d", p)
+	}
+}
+
+func TestDecodeWavPack_CorruptBlockFails(t *testing.T) {
+	l := testSignal(2000, 1<<15, 0)
+	enc := &wvTestEncoder{flags: 1 | 9<<23, delta: 2, medians: 0x0800}
+	data := enc.file(l, l, 2000)
+	data[28] ^= 0xFF // CRC
+
+	d := decodeWavPackBytes(t, data)
+	n, ok := d.Stream(make([][2]float64, 100))
+	assert.False(t, ok)
+	assert.Zero(t, n)
+	assert.ErrorContains(t, d.Err(), "CRC")
+}
+
+func TestDecodeWavPack_RejectsHybrid(t *testing.T) {
+	l := testSignal(100, 1<<15, 0)
+	enc := &wvTestEncoder{flags: 1 | 9<<23 | wvHybrid, delta: 2, medians: 0x0800}
+	path := filepath.Join(t.TempDir(), "lossy.wv")
+	require.NoError(t, os.WriteFile(path, enc.file(l, l, 100), 0o600))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	_, _, err = decodeWavPack(f)
+	assert.ErrorContains(t, err, "hybrid")
+}
+
+// wvIDMD5 holds the MD5 of the audio, stored by "wavpack -m".
+const wvIDMD5 = 0x26
+
+// wvStoredMD5 returns the MD5 sub-block of a WavPack file.
+func wvStoredMD5(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var sum []byte
+	for len(data) >= wvHeaderSize && string(data[:4]) == "wvpk" {
+		h, err := parseWVHeader(data)
+		require.NoError(t, err)
+		require.LessOrEqual(t, h.size, int64(len(data)))
+		err = wvSubBlocks(data[wvHeaderSize:h.size], func(id byte, b []byte) error {
+			if id == wvIDMD5 {
+				sum = b
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		data = data[h.size:]
+	}
+	require.Len(t, sum, md5.Size, "no MD5 stored, make the file with wavpack -m")
+	return sum
+}
+
+// The files are made by the reference encoder with testdata/wavpack.sh, so
+// that the decoder isn't only checked against its own reading of the format.
+func TestDecodeWavPack_ReferenceFiles(t *testing.T) {
+	tests := []struct {
+		file      string
+		channels  int
+		precision int
+	}{
+		{"wavpack_16bit_stereo.wv", 2, 2},
+		{"wavpack_16bit_stereo_fast.wv", 2, 2},
+		{"wavpack_24bit_stereo.wv", 2, 3},
+		{"wavpack_16bit_mono.wv", 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join("testdata", tt.file)
+			data, err := os.ReadFile(path)
+			require.NoError(t, err, "make the files with testdata/wavpack.sh and commit them")
+
+			f, err := os.Open(path)
+			require.NoError(t, err)
+			d, format, err := decodeWavPack(f)
+			require.NoError(t, err)
+			defer d.Close()
+			assert.Equal(t, 44100, int(format.SampleRate))
+			assert.Equal(t, tt.channels, format.NumChannels)
+			assert.Equal(t, tt.precision, format.Precision)
+
+			out := drain(d)
+			require.NoError(t, d.Err())
+			assert.Len(t, out, 44100)
+
+			// Hash the samples as the encoder did, little endian and interleaved
+			scale := float64(int64(1) << (8*tt.precision - 1))
+			h := md5.New()
+			buf := make([]byte, 4)
+			for _, frame := range out {
+				for c := range tt.channels {
+					v := int32(math.Round(frame[c] * scale))
+					binary.LittleEndian.PutUint32(buf, uint32(v)) //nolint:gosec // two's complement bytes
+					h.Write(buf[:tt.precision])
+				}
+			}
+			assert.Equal(t, wvStoredMD5(t, data), h.Sum(nil), "decoded audio differs from the encoded audio")
+		})
+	}
+}
+
+func TestDecodeWavPack_RejectsReferenceHybrid(t *testing.T) {
+	path := filepath.Join("testdata", "wavpack_hybrid.wv")
+	f, err := os.Open(path)
+	require.NoError(t, err, "make the files with testdata/wavpack.sh and commit them")
+	defer f.Close()
+	_, _, err = decodeWavPack(f)
+	assert.ErrorContains(t, err, "hybrid")
+}
+
+func TestWvExp2(t *testing.T) {
+	assert.Equal(t, int32(0), wvExp2(0))
+	assert.Equal(t, int32(128), wvExp2(0x0800))
+	assert.Equal(t, int32(-128), wvExp2(-0x0800))
+	assert.Equal(t, int32(1<<15), wvExp2(0x1000))
+	// Values are the bit count of the result in 8.8 fixed point: 2^(9.5-1) = 362.04
+	assert.Equal(t, int32(362), wvExp2(0x0980))
+}
+
+func TestParseWVHeader_SkipsTrailingTag(t *testing.T) {
+	l := testSignal(500, 1<<15, 0)
+	enc := &wvTestEncoder{flags: 1 | 9<<23, delta: 2, medians: 0x0800}
+	data := enc.file(l, l, 500)
+	data = append(data, bytes.Repeat([]byte("APETAGEX"), 8)...)
+
+	d := decodeWavPackBytes(t, data)
+	assert.Equal(t, 500, d.Len())
+	assert.Len(t, drain(d), 500)
+}
+
+func TestWvBlock_ToFloat(t *testing.T) {
+	b := &wvBlock{isFloat: true, floatExp: 127}
+	assert.InDelta(t, 0.5, b.toFloat(1<<22), 0)
+	assert.InDelta(t, -0.75, b.toFloat(-(3 << 21)), 0)
+	assert.InDelta(t, 0, b.toFloat(0), 0)
+}
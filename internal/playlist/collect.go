@@ -1,8 +1,11 @@
 package playlist
 
 import (
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"time"
 
@@ -244,6 +247,56 @@ func CollectFolderFromFile(node navigator.FileNode) ([]Track, int, error) {
 	return tracks, selectedIdx, nil
 }
 
+// CollectFromPaths returns the tracks of music files and folders, in order,
+// folders being walked recursively. Files lookup finds take their metadata
+// from the library, others from their tags. lookup may be nil.
+func CollectFromPaths(paths []string, lookup func(path string) (*library.Track, error)) ([]Track, error) {
+	var tracks []Track
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() && !tags.IsMusicFile(path) {
+			return nil, fmt.Errorf("%s: not a music file", path)
+		}
+
+		files := []string{path}
+		if info.IsDir() {
+			if files, err = musicFiles(path); err != nil {
+				return nil, err
+			}
+		}
+		for _, file := range files {
+			if lookup != nil {
+				if t, err := lookup(file); err == nil {
+					tracks = append(tracks, FromLibraryTrack(*t))
+					continue
+				}
+			}
+			tracks = append(tracks, FromPath(file))
+		}
+	}
+	return tracks, nil
+}
+
+// musicFiles returns the music files under dir, sorted by path.
+func musicFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Skip unreadable folders
+			return nil //nolint:nilerr // intentionally skipping errors
+		}
+		if !d.IsDir() && tags.IsMusicFile(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	slices.Sort(files)
+	return files, err
+}
+
 // WithDuration reads the duration for a track (expensive - decodes audio).
 func WithDuration(t Track) Track {
 	info, err := tags.ReadWithAudio(t.Path)
@@ -1,6 +1,9 @@
 package playlist
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 // Track represents a single track in a playlist.
 type Track struct {
@@ -33,6 +36,12 @@ func (p *Playlist) Add(tracks ...Track) {
 	p.tracks = append(p.tracks, tracks...)
 }
 
+// Insert inserts tracks before the given index, clamped to the playlist.
+func (p *Playlist) Insert(index int, tracks ...Track) {
+	index = max(0, min(index, len(p.tracks)))
+	p.tracks = slices.Insert(p.tracks, index, tracks...)
+}
+
 // Remove removes the track at the given index.
 // Returns false if index is out of bounds.
 func (p *Playlist) Remove(index int) bool {
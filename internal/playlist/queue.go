@@ -1,6 +1,9 @@
 package playlist
 
-import "math/rand/v2"
+import (
+	"math/rand/v2"
+	"path/filepath"
+)
 
 // RepeatMode defines the repeat behavior for the queue.
 type RepeatMode int
@@ -132,32 +135,107 @@ func (q *PlayingQueue) HasNext() bool {
 // PeekNext returns the next track without advancing the queue.
 // Returns nil if there is no next track.
 func (q *PlayingQueue) PeekNext() *Track {
-	if q.playlist.Len() == 0 || q.currentIndex < 0 {
+	i := q.peekNextIndex()
+	if i < 0 {
 		return nil
 	}
+	return q.playlist.Track(i)
+}
+
+// NextInAlbumOrder returns true if the track PeekNext returns is played as
+// part of an album in track order, see InAlbumOrder.
+func (q *PlayingQueue) NextInAlbumOrder() bool {
+	i := q.peekNextIndex()
+	return i >= 0 && q.InAlbumOrder(i)
+}
+
+// peekNextIndex returns the index of the next track, -1 if there is none.
+func (q *PlayingQueue) peekNextIndex() int {
+	if q.playlist.Len() == 0 || q.currentIndex < 0 {
+		return -1
+	}
 
 	// Repeat One: next is current track
 	if q.repeatMode == RepeatOne {
-		return q.Current()
+		return q.currentIndex
 	}
 
 	// Shuffle: can't predict next (would need to pick randomly)
-	// Return nil to disable gapless in shuffle mode
+	// Return -1 to disable gapless in shuffle mode
 	if q.shuffle {
-		return nil
+		return -1
 	}
 
 	// Normal next
 	if q.currentIndex < q.playlist.Len()-1 {
-		return q.playlist.Track(q.currentIndex + 1)
+		return q.currentIndex + 1
 	}
 
 	// At end with repeat all
 	if q.repeatMode == RepeatAll {
-		return q.playlist.Track(0)
+		return 0
 	}
 
-	return nil
+	return -1
+}
+
+// InAlbumOrder returns true if the track at index is played as part of an
+// album in track order: shuffle is off and a neighbouring queue entry is the
+// previous or next track of the same album.
+func (q *PlayingQueue) InAlbumOrder(index int) bool {
+	if q.shuffle {
+		return false
+	}
+	t := q.playlist.Track(index)
+	if t == nil || t.Album == "" {
+		return false
+	}
+	if prev := q.playlist.Track(index - 1); prev != nil && followsInAlbum(*prev, *t) {
+		return true
+	}
+	if next := q.playlist.Track(index + 1); next != nil && followsInAlbum(*t, *next) {
+		return true
+	}
+	return false
+}
+
+// AlbumRest returns the tracks queued after the current one that continue
+// its album, in the order they play. It is empty when the current track is
+// the last of its album, and when shuffling.
+func (q *PlayingQueue) AlbumRest() []Track {
+	cur := q.Current()
+	if cur == nil || cur.Album == "" || q.shuffle || q.repeatMode == RepeatOne {
+		return nil
+	}
+	var rest []Track
+	for i := q.currentIndex + 1; i < q.playlist.Len(); i++ {
+		t := q.playlist.Track(i)
+		if !sameAlbum(*cur, *t) {
+			break
+		}
+		rest = append(rest, *t)
+	}
+	return rest
+}
+
+// sameAlbum returns true if both tracks are from the same album.
+// Artist or folder must match to tell apart albums sharing a title.
+func sameAlbum(a, b Track) bool {
+	if a.Album != b.Album {
+		return false
+	}
+	return a.Artist == b.Artist || filepath.Dir(a.Path) == filepath.Dir(b.Path)
+}
+
+// followsInAlbum returns true if next is the track after prev on the same album.
+func followsInAlbum(prev, next Track) bool {
+	if !sameAlbum(prev, next) {
+		return false
+	}
+	if next.DiscNumber == prev.DiscNumber {
+		return next.TrackNumber == prev.TrackNumber+1
+	}
+	return next.DiscNumber == prev.DiscNumber+1 && next.TrackNumber == 1
 }
 
 // JumpTo sets the current index to the specified position.
@@ -176,6 +254,20 @@ func (q *PlayingQueue) Add(tracks ...Track) {
 	q.playlist.Add(tracks...)
 }
 
+// Insert inserts tracks before the given index without changing the
+// playing track.
+func (q *PlayingQueue) Insert(index int, tracks ...Track) {
+	if len(tracks) == 0 {
+		return
+	}
+	q.history.Push(q.playlist.Tracks())
+	index = max(0, min(index, q.playlist.Len()))
+	q.playlist.Insert(index, tracks...)
+	if q.currentIndex >= index {
+		q.currentIndex += len(tracks)
+	}
+}
+
 // AddAndPlay appends tracks and jumps to the first added track.
 // Returns the track to play.
 func (q *PlayingQueue) AddAndPlay(tracks ...Track) *Track {
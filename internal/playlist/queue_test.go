@@ -50,6 +50,31 @@ func TestQueue_AddAndPlay(t *testing.T) {
 	}
 }
 
+func TestQueue_Insert(t *testing.T) {
+	q := NewQueue()
+	q.Add(Track{Path: "/a.mp3"}, Track{Path: "/b.mp3"}, Track{Path: "/c.mp3"})
+	q.JumpTo(1)
+
+	q.Insert(2, Track{Path: "/next1.mp3"}, Track{Path: "/next2.mp3"})
+	q.Insert(0, Track{Path: "/first.mp3"})
+	q.Insert(99, Track{Path: "/last.mp3"})
+
+	want := []string{"/first.mp3", "/a.mp3", "/b.mp3", "/next1.mp3", "/next2.mp3", "/c.mp3", "/last.mp3"}
+	tracks := q.Tracks()
+	if len(tracks) != len(want) {
+		t.Fatalf("Len() = %d, want %d", len(tracks), len(want))
+	}
+	for i, path := range want {
+		if tracks[i].Path != path {
+			t.Errorf("track %d = %s, want %s", i, tracks[i].Path, path)
+		}
+	}
+	// The playing track stays current
+	if q.Current() == nil || q.Current().Path != "/b.mp3" {
+		t.Errorf("Current() = %v, want /b.mp3", q.Current())
+	}
+}
+
 func TestQueue_AddAndPlay_Empty(t *testing.T) {
 	q := NewQueue()
 
@@ -477,3 +502,130 @@ func TestQueue_MoveIndices(t *testing.T) {
 		}
 	})
 }
+
+func TestQueue_InAlbumOrder(t *testing.T) {
+	album := func(n int) Track {
+		return Track{Path: "/music/a/" + string(rune('0'+n)) + ".flac", Artist: "A", Album: "Album", DiscNumber: 1, TrackNumber: n}
+	}
+	other := Track{Path: "/music/b/1.flac", Artist: "B", Album: "Other", DiscNumber: 1, TrackNumber: 1}
+
+	t.Run("album in order", func(t *testing.T) {
+		q := NewQueue()
+		q.Add(album(1), album(2), album(3))
+		for i := range 3 {
+			if !q.InAlbumOrder(i) {
+				t.Errorf("InAlbumOrder(%d) = false, want true", i)
+			}
+		}
+	})
+
+	t.Run("mixed tracks", func(t *testing.T) {
+		q := NewQueue()
+		q.Add(album(1), other, album(3))
+		for i := range 3 {
+			if q.InAlbumOrder(i) {
+				t.Errorf("InAlbumOrder(%d) = true, want false", i)
+			}
+		}
+	})
+
+	t.Run("out of order", func(t *testing.T) {
+		q := NewQueue()
+		q.Add(album(3), album(1))
+		if q.InAlbumOrder(0) || q.InAlbumOrder(1) {
+			t.Error("tracks out of order should not be in album order")
+		}
+	})
+
+	t.Run("disc change", func(t *testing.T) {
+		last := album(9)
+		first := Track{Path: "/music/a/cd2/1.flac", Artist: "A", Album: "Album", DiscNumber: 2, TrackNumber: 1}
+		q := NewQueue()
+		q.Add(last, first)
+		if !q.InAlbumOrder(1) {
+			t.Error("first track of next disc should be in album order")
+		}
+	})
+
+	t.Run("shuffle", func(t *testing.T) {
+		q := NewQueue()
+		q.Add(album(1), album(2))
+		q.SetShuffle(true)
+		if q.InAlbumOrder(0) {
+			t.Error("shuffled queue should not be in album order")
+		}
+	})
+
+	t.Run("invalid index", func(t *testing.T) {
+		q := NewQueue()
+		if q.InAlbumOrder(0) {
+			t.Error("empty queue should not be in album order")
+		}
+	})
+}
+
+func TestQueue_NextInAlbumOrder(t *testing.T) {
+	album := func(n int) Track {
+		return Track{Path: "/music/a/" + string(rune('0'+n)) + ".flac", Artist: "A", Album: "Album", DiscNumber: 1, TrackNumber: n}
+	}
+	other := Track{Path: "/music/b/1.flac", Artist: "B", Album: "Other", DiscNumber: 1, TrackNumber: 1}
+
+	q := NewQueue()
+	q.Add(album(1), album(2), other)
+	q.JumpTo(0)
+	if !q.NextInAlbumOrder() {
+		t.Error("next track continuing the album should be in album order")
+	}
+	q.JumpTo(1)
+	if q.NextInAlbumOrder() {
+		t.Error("next track of another album should not be in album order")
+	}
+	q.JumpTo(2)
+	if q.NextInAlbumOrder() {
+		t.Error("no next track should not be in album order")
+	}
+}
+
+func TestQueue_AlbumRest(t *testing.T) {
+	album := func(n int) Track {
+		return Track{Path: "/music/a/" + string(rune('0'+n)) + ".flac", Artist: "A", Album: "Album", TrackNumber: n}
+	}
+	other := Track{Path: "/music/b/1.flac", Artist: "B", Album: "Other", TrackNumber: 1}
+
+	t.Run("rest of the album", func(t *testing.T) {
+		q := NewQueue()
+		q.Add(album(1), album(2), album(3), other)
+		q.JumpTo(1)
+		rest := q.AlbumRest()
+		if len(rest) != 1 || rest[0].Path != album(3).Path {
+			t.Errorf("AlbumRest() = %v, want track 3", rest)
+		}
+	})
+
+	t.Run("last track of the album", func(t *testing.T) {
+		q := NewQueue()
+		q.Add(album(1), other)
+		q.JumpTo(0)
+		if rest := q.AlbumRest(); len(rest) != 0 {
+			t.Errorf("AlbumRest() = %v, want empty", rest)
+		}
+	})
+
+	t.Run("shuffle", func(t *testing.T) {
+		q := NewQueue()
+		q.Add(album(1), album(2))
+		q.JumpTo(0)
+		q.SetShuffle(true)
+		if rest := q.AlbumRest(); len(rest) != 0 {
+			t.Errorf("AlbumRest() = %v, want empty when shuffling", rest)
+		}
+	})
+
+	t.Run("no current track", func(t *testing.T) {
+		q := NewQueue()
+		q.Add(album(1), album(2))
+		if rest := q.AlbumRest(); len(rest) != 0 {
+			t.Errorf("AlbumRest() = %v, want empty", rest)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package playlistfile
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/llehouerou/waves/internal/playlists"
+)
+
+// ErrNoTracks is returned when no entry of an imported file is in the library.
+var ErrNoTracks = errors.New("no entry matches a library track")
+
+// ImportResult is the outcome of a playlist file import.
+type ImportResult struct {
+	PlaylistID int64
+	Name       string
+	Total      int // Entries in the file
+	Imported   int
+	Unresolved []Entry
+}
+
+// Summary describes the import in one line.
+func (r ImportResult) Summary() string {
+	return fmt.Sprintf("Imported %d of %d tracks into %q", r.Imported, r.Total, r.Name)
+}
+
+// Import reads a playlist file and creates a playlist in folderID holding
+// its entries found in the library. The playlist is named after the title
+// of the file, or name when not empty. When no entry is found, no playlist
+// is created and ErrNoTracks is returned along with the unresolved entries.
+func Import(pls *playlists.Playlists, lib Library, path string, folderID *int64, name string) (ImportResult, error) {
+	file, err := Read(path)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	if name == "" {
+		name = file.Title
+	}
+
+	resolved, err := Resolve(lib, file.Entries, filepath.Dir(path))
+	if err != nil {
+		return ImportResult{}, err
+	}
+	result := ImportResult{
+		Name:       name,
+		Total:      len(file.Entries),
+		Imported:   len(resolved.TrackIDs),
+		Unresolved: resolved.Unresolved,
+	}
+	if result.Imported == 0 {
+		return result, ErrNoTracks
+	}
+
+	id, err := pls.Create(folderID, name)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	if err := pls.AddTracks(id, resolved.TrackIDs); err != nil {
+		return ImportResult{}, err
+	}
+	result.PlaylistID = id
+	return result, nil
+}
+
+// ExportPlaylist writes the tracks of a playlist to a file, in the format
+// of its extension. Returns the number of tracks written.
+func ExportPlaylist(pls *playlists.Playlists, id int64, path string, relative bool) (int, error) {
+	pl, err := pls.Get(id)
+	if err != nil {
+		return 0, err
+	}
+	tracks, err := pls.Tracks(id)
+	if err != nil {
+		return 0, err
+	}
+	if err := Export(path, pl.Name, EntriesFromTracks(tracks), relative); err != nil {
+		return 0, err
+	}
+	return len(tracks), nil
+}
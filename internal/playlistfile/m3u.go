@@ -0,0 +1,97 @@
+package playlistfile
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func parseM3U(data []byte) *File {
+	file := &File{}
+	var info Entry // From the #EXTINF line before the location
+
+	for line := range strings.Lines(string(data)) {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#EXTINF:"):
+			info = parseExtinf(strings.TrimPrefix(line, "#EXTINF:"))
+		case strings.HasPrefix(line, "#PLAYLIST:"):
+			file.Title = strings.TrimSpace(strings.TrimPrefix(line, "#PLAYLIST:"))
+		case strings.HasPrefix(line, "#"):
+			// #EXTM3U header and other directives
+		default:
+			info.Location = locationFromURI(line)
+			file.Entries = append(file.Entries, info)
+			info = Entry{}
+		}
+	}
+	return file
+}
+
+// parseExtinf parses "123,Artist - Title", where the duration may be
+// followed by attributes such as tvg-name="...".
+func parseExtinf(s string) Entry {
+	var e Entry
+	inQuotes := false
+	comma := -1
+	for i, r := range s {
+		if r == '"' {
+			inQuotes = !inQuotes
+		}
+		if r == ',' && !inQuotes {
+			comma = i
+			break
+		}
+	}
+	if comma < 0 {
+		return e
+	}
+
+	duration, _, _ := strings.Cut(s[:comma], " ")
+	if secs, err := strconv.Atoi(strings.TrimSpace(duration)); err == nil && secs > 0 {
+		e.Duration = time.Duration(secs) * time.Second
+	}
+	e.Artist, e.Title = splitDisplay(s[comma+1:])
+	return e
+}
+
+func writeM3U(w *bufio.Writer, title string, entries []Entry) error {
+	if _, err := w.WriteString("#EXTM3U\n"); err != nil {
+		return err
+	}
+	if title != "" {
+		if _, err := fmt.Fprintf(w, "#PLAYLIST:%s\n", oneLine(title)); err != nil {
+			return err
+		}
+	}
+	for _, e := range entries {
+		if _, err := fmt.Fprintf(w, "#EXTINF:%d,%s\n%s\n", seconds(e.Duration), oneLine(e.displayName()), e.Location); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// displayName is the "Artist - Title" of M3U and PLS files.
+func (e Entry) displayName() string {
+	if e.Artist == "" {
+		return e.Title
+	}
+	return e.Artist + " - " + e.Title
+}
+
+// seconds returns a duration in whole seconds, or -1 if unknown.
+func seconds(d time.Duration) int {
+	if d <= 0 {
+		return -1
+	}
+	return int(d.Round(time.Second) / time.Second)
+}
+
+// oneLine replaces line breaks, which would end an M3U or PLS line.
+func oneLine(s string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+}
@@ -0,0 +1,202 @@
+// Package playlistfile reads and writes M3U/M3U8, PLS and XSPF playlist files
+// and resolves their entries to library tracks.
+package playlistfile
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/llehouerou/waves/internal/playlist"
+)
+
+// Format is a playlist file format.
+type Format string
+
+const (
+	FormatM3U  Format = "m3u"
+	FormatM3U8 Format = "m3u8"
+	FormatPLS  Format = "pls"
+	FormatXSPF Format = "xspf"
+)
+
+// ErrUnknownFormat is returned for files whose extension is not a supported
+// playlist format.
+var ErrUnknownFormat = errors.New("unknown playlist format, expected .m3u, .m3u8, .pls or .xspf")
+
+// FormatFromPath returns the format of a playlist file from its extension.
+func FormatFromPath(path string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))); f {
+	case FormatM3U, FormatM3U8, FormatPLS, FormatXSPF:
+		return f, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+// Entry is a track of a playlist file.
+type Entry struct {
+	Location string // Path or URL, as written in the file
+	Artist   string
+	Title    string
+	Duration time.Duration // 0 if unknown
+}
+
+// String describes the entry for reports, by artist and title when known.
+func (e Entry) String() string {
+	switch {
+	case e.Artist != "" && e.Title != "":
+		return e.Artist + " - " + e.Title
+	case e.Title != "":
+		return e.Title
+	}
+	return e.Location
+}
+
+// File is the content of a playlist file.
+type File struct {
+	Title   string // Empty if the file has none
+	Entries []Entry
+}
+
+// Read reads a playlist file in the format of its extension. The title
+// defaults to the file name.
+func Read(path string) (*File, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	file, err := Parse(f, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	if file.Title == "" {
+		file.Title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return file, nil
+}
+
+// Parse reads a playlist in the given format.
+func Parse(r io.Reader, format Format) (*File, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	switch format {
+	case FormatM3U, FormatM3U8:
+		if !utf8.Valid(data) {
+			// Legacy .m3u files are Latin-1
+			data = latin1ToUTF8(data)
+		}
+		return parseM3U(data), nil
+	case FormatPLS:
+		return parsePLS(data)
+	case FormatXSPF:
+		return parseXSPF(data)
+	}
+	return nil, ErrUnknownFormat
+}
+
+// Write writes entries as a playlist in the given format. Locations are
+// written as they are.
+func Write(w io.Writer, format Format, title string, entries []Entry) error {
+	bw := bufio.NewWriter(w)
+	var err error
+	switch format {
+	case FormatM3U, FormatM3U8:
+		err = writeM3U(bw, title, entries)
+	case FormatPLS:
+		err = writePLS(bw, entries)
+	case FormatXSPF:
+		err = writeXSPF(bw, title, entries)
+	default:
+		return ErrUnknownFormat
+	}
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Export writes entries to a playlist file in the format of its extension.
+// With relative set, the paths of the entries are written relative to the
+// directory of the file.
+func Export(path, title string, entries []Entry, relative bool) error {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+
+	if relative {
+		dir, err := filepath.Abs(filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		rel := make([]Entry, len(entries))
+		for i, e := range entries {
+			if filepath.IsAbs(e.Location) {
+				if r, err := filepath.Rel(dir, e.Location); err == nil {
+					e.Location = r
+				}
+			}
+			rel[i] = e
+		}
+		entries = rel
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := Write(f, format, title, entries); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// EntriesFromTracks returns the entries of playlist tracks.
+func EntriesFromTracks(tracks []playlist.Track) []Entry {
+	entries := make([]Entry, len(tracks))
+	for i, t := range tracks {
+		entries[i] = Entry{Location: t.Path, Artist: t.Artist, Title: t.Title, Duration: t.Duration}
+	}
+	return entries
+}
+
+// isURL reports whether a location is a URL rather than a path.
+func isURL(location string) bool {
+	i := strings.Index(location, "://")
+	return i > 1 && !strings.ContainsAny(location[:i], `/\`)
+}
+
+// splitDisplay splits "Artist - Title" as written by most players.
+func splitDisplay(s string) (artist, title string) {
+	s = strings.TrimSpace(s)
+	if a, t, ok := strings.Cut(s, " - "); ok {
+		return strings.TrimSpace(a), strings.TrimSpace(t)
+	}
+	return "", s
+}
+
+func latin1ToUTF8(data []byte) []byte {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return []byte(string(runes))
+}
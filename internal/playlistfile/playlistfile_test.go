@@ -0,0 +1,243 @@
+package playlistfile
+
+import (
+	"bytes"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/llehouerou/waves/internal/library"
+)
+
+func TestFormatFromPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want Format
+		ok   bool
+	}{
+		{"/tmp/mix.m3u", FormatM3U, true},
+		{"Mix.M3U8", FormatM3U8, true},
+		{"radio.pls", FormatPLS, true},
+		{"list.xspf", FormatXSPF, true},
+		{"notes.txt", "", false},
+	}
+	for _, tt := range tests {
+		got, err := FormatFromPath(tt.path)
+		if got != tt.want || (err == nil) != tt.ok {
+			t.Errorf("FormatFromPath(%q) = %q, %v, want %q", tt.path, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseM3U(t *testing.T) {
+	data := "\xef\xbb\xbf#EXTM3U\r\n" +
+		"#PLAYLIST:Road Trip\r\n" +
+		"#EXTINF:215,Miles Davis - So What\r\n" +
+		"Jazz/Kind of Blue/01 So What.flac\r\n" +
+		"\r\n" +
+		"# a comment\r\n" +
+		"/music/Radiohead/OK Computer/01 Airbag.mp3\r\n" +
+		`#EXTINF:-1 tvg-name="A, B",Station` + "\r\n" +
+		"http://radio.example/stream\r\n"
+
+	file, err := Parse(strings.NewReader(data), FormatM3U8)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	want := []Entry{
+		{Location: "Jazz/Kind of Blue/01 So What.flac", Artist: "Miles Davis", Title: "So What", Duration: 215 * time.Second},
+		{Location: "/music/Radiohead/OK Computer/01 Airbag.mp3"},
+		{Location: "http://radio.example/stream", Title: "Station"},
+	}
+	if file.Title != "Road Trip" {
+		t.Errorf("Title = %q, want Road Trip", file.Title)
+	}
+	assertEntries(t, file.Entries, want)
+}
+
+func TestParseM3U_Latin1(t *testing.T) {
+	file, err := Parse(bytes.NewReader([]byte("/music/Bj\xf6rk/Joga.mp3\n")), FormatM3U)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	assertEntries(t, file.Entries, []Entry{{Location: "/music/Björk/Joga.mp3"}})
+}
+
+func TestParsePLS(t *testing.T) {
+	data := "[playlist]\n" +
+		"File2=file:///music/b%20c.ogg\n" +
+		"Title1=Blur - Beetlebum\n" +
+		"File1=/music/a.mp3\n" +
+		"Length1=305\n" +
+		"NumberOfEntries=2\n" +
+		"Version=2\n"
+
+	file, err := Parse(strings.NewReader(data), FormatPLS)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	assertEntries(t, file.Entries, []Entry{
+		{Location: "/music/a.mp3", Artist: "Blur", Title: "Beetlebum", Duration: 305 * time.Second},
+		{Location: "/music/b c.ogg"},
+	})
+
+	if _, err := Parse(strings.NewReader("File1=/a.mp3\n"), FormatPLS); err == nil {
+		t.Error("Parse() should reject files without a [playlist] section")
+	}
+}
+
+func TestParseXSPF(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<playlist version="1" xmlns="http://xspf.org/ns/0/">
+  <title>Jazz</title>
+  <trackList>
+    <track>
+      <location>file:///music/Miles%20Davis/So%20What.flac</location>
+      <creator>Miles Davis</creator>
+      <title>So What</title>
+      <duration>545000</duration>
+    </track>
+    <track><location>Coltrane/Naima%20(live).mp3</location></track>
+    <track><title>No location</title></track>
+  </trackList>
+</playlist>`
+
+	file, err := Parse(strings.NewReader(data), FormatXSPF)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if file.Title != "Jazz" {
+		t.Errorf("Title = %q, want Jazz", file.Title)
+	}
+	assertEntries(t, file.Entries, []Entry{
+		{Location: "/music/Miles Davis/So What.flac", Artist: "Miles Davis", Title: "So What", Duration: 545 * time.Second},
+		{Location: "Coltrane/Naima (live).mp3"},
+	})
+}
+
+func TestWrite_Roundtrip(t *testing.T) {
+	entries := []Entry{
+		{Location: "/music/Miles Davis/So What.flac", Artist: "Miles Davis", Title: "So What", Duration: 545 * time.Second},
+		{Location: "Coltrane/Naima #1.mp3", Title: "Naima"},
+		{Location: "http://radio.example/stream?id=1"},
+	}
+
+	for _, format := range []Format{FormatM3U, FormatM3U8, FormatPLS, FormatXSPF} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, format, "Mix", entries); err != nil {
+				t.Fatalf("Write() error: %v", err)
+			}
+			file, err := Parse(&buf, format)
+			if err != nil {
+				t.Fatalf("Parse() error: %v\n%s", err, buf.String())
+			}
+			assertEntries(t, file.Entries, entries)
+		})
+	}
+}
+
+func TestExport_RelativePaths(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "lists", "mix.m3u8")
+	if err := os.Mkdir(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	entries := []Entry{
+		{Location: filepath.Join(dir, "music", "a.flac"), Title: "A"},
+		{Location: "http://radio.example/stream"},
+	}
+
+	if err := Export(path, "Mix", entries, true); err != nil {
+		t.Fatalf("Export() error: %v", err)
+	}
+	file, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	assertEntries(t, file.Entries, []Entry{
+		{Location: filepath.Join("..", "music", "a.flac"), Title: "A"},
+		{Location: "http://radio.example/stream"},
+	})
+	if file.Title != "Mix" {
+		t.Errorf("Title = %q, want Mix", file.Title)
+	}
+}
+
+// fakeLibrary is a library of tracks by path.
+type fakeLibrary struct {
+	sources []string
+	tracks  map[string]library.Track
+}
+
+func (f fakeLibrary) TrackByPath(path string) (*library.Track, error) {
+	t, ok := f.tracks[path]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &t, nil
+}
+
+func (f fakeLibrary) TrackIDByArtistTitle(artist, title string) (int64, error) {
+	for _, t := range f.tracks {
+		if strings.EqualFold(t.Artist, artist) && strings.EqualFold(t.Title, title) {
+			return t.ID, nil
+		}
+	}
+	return 0, sql.ErrNoRows
+}
+
+func (f fakeLibrary) Sources() ([]string, error) {
+	return f.sources, nil
+}
+
+func TestResolve(t *testing.T) {
+	lib := fakeLibrary{
+		sources: []string{"/data/audio", "/music"},
+		tracks: map[string]library.Track{
+			"/music/Jazz/Kind of Blue/01 So What.flac": {ID: 1},
+			"/lists/Blur/Beetlebum.mp3":                {ID: 2},
+			"/music/Rock/OK Computer/01 Airbag.mp3":    {ID: 3},
+			"/music/Coltrane/Naima.mp3":                {ID: 4, Artist: "John Coltrane", Title: "Naima"},
+		},
+	}
+	entries := []Entry{
+		{Location: "/music/Jazz/Kind of Blue/01 So What.flac"},          // Absolute path
+		{Location: "Blur/Beetlebum.mp3"},                                // Relative to the playlist
+		{Location: `C:\Users\me\Music\Rock\OK Computer\01 Airbag.mp3`},  // Under a source
+		{Location: "/sdcard/Naima.mp3", Title: "john coltrane - NAIMA"}, // Artist and title
+		{Location: "/sdcard/Unknown/track.mp3", Artist: "Nobody", Title: "Nothing"},
+		{Location: "http://radio.example/stream"},
+	}
+
+	result, err := Resolve(lib, entries, "/lists")
+	if err != nil {
+		t.Fatalf("Resolve() error: %v", err)
+	}
+	want := []int64{1, 2, 3, 4}
+	if len(result.TrackIDs) != len(want) {
+		t.Fatalf("TrackIDs = %v, want %v", result.TrackIDs, want)
+	}
+	for i := range want {
+		if result.TrackIDs[i] != want[i] {
+			t.Errorf("TrackIDs = %v, want %v", result.TrackIDs, want)
+			break
+		}
+	}
+	assertEntries(t, result.Unresolved, entries[4:])
+}
+
+func assertEntries(t *testing.T, got, want []Entry) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("entries = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
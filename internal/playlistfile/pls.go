@@ -0,0 +1,90 @@
+package playlistfile
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errNotPLS = errors.New("not a PLS playlist, missing [playlist] section")
+
+func parsePLS(data []byte) (*File, error) {
+	entries := make(map[int]*Entry)
+	found := false
+
+	for line := range strings.Lines(string(data)) {
+		line = strings.TrimSpace(line)
+		if strings.EqualFold(line, "[playlist]") {
+			found = true
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+		var field string
+		for _, f := range []string{"file", "title", "length"} {
+			if strings.HasPrefix(key, f) {
+				field = f
+				break
+			}
+		}
+		n, err := strconv.Atoi(strings.TrimPrefix(key, field))
+		if field == "" || err != nil {
+			// NumberOfEntries, Version
+			continue
+		}
+		e := entries[n]
+		if e == nil {
+			e = &Entry{}
+			entries[n] = e
+		}
+		switch field {
+		case "file":
+			e.Location = locationFromURI(value)
+		case "title":
+			e.Artist, e.Title = splitDisplay(value)
+		case "length":
+			if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+				e.Duration = time.Duration(secs) * time.Second
+			}
+		}
+	}
+	if !found {
+		return nil, errNotPLS
+	}
+
+	file := &File{}
+	numbers := make([]int, 0, len(entries))
+	for n := range entries {
+		numbers = append(numbers, n)
+	}
+	slices.Sort(numbers)
+	for _, n := range numbers {
+		if e := entries[n]; e.Location != "" {
+			file.Entries = append(file.Entries, *e)
+		}
+	}
+	return file, nil
+}
+
+func writePLS(w *bufio.Writer, entries []Entry) error {
+	if _, err := w.WriteString("[playlist]\n"); err != nil {
+		return err
+	}
+	for i, e := range entries {
+		n := i + 1
+		if _, err := fmt.Fprintf(w, "File%d=%s\nTitle%d=%s\nLength%d=%d\n",
+			n, e.Location, n, oneLine(e.displayName()), n, seconds(e.Duration)); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "NumberOfEntries=%d\nVersion=2\n", len(entries))
+	return err
+}
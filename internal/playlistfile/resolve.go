@@ -0,0 +1,113 @@
+package playlistfile
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/llehouerou/waves/internal/library"
+)
+
+// Library is the part of the library entries are resolved against.
+type Library interface {
+	TrackByPath(path string) (*library.Track, error)
+	TrackIDByArtistTitle(artist, title string) (int64, error)
+	Sources() ([]string, error)
+}
+
+// Result is the outcome of resolving the entries of a playlist file.
+type Result struct {
+	TrackIDs   []int64 // Library tracks, in playlist order
+	Unresolved []Entry
+}
+
+// Resolve matches entries to library tracks, trying in order:
+//   - the path, relative paths being relative to baseDir
+//   - the end of the path under each library source, for playlists written
+//     on other devices (e.g. Music/Artist/Album/01.flac)
+//   - the artist and title
+func Resolve(lib Library, entries []Entry, baseDir string) (Result, error) {
+	sources, err := lib.Sources()
+	if err != nil {
+		return Result{}, err
+	}
+
+	var result Result
+	for _, e := range entries {
+		id, err := resolve(lib, sources, e, baseDir)
+		if err != nil {
+			return Result{}, err
+		}
+		if id == 0 {
+			result.Unresolved = append(result.Unresolved, e)
+			continue
+		}
+		result.TrackIDs = append(result.TrackIDs, id)
+	}
+	return result, nil
+}
+
+// resolve returns the library track ID of an entry, or 0 if none matches.
+func resolve(lib Library, sources []string, e Entry, baseDir string) (int64, error) {
+	if e.Location != "" && !isURL(e.Location) {
+		// Playlists written on Windows use backslashes
+		location := filepath.FromSlash(strings.ReplaceAll(e.Location, `\`, "/"))
+		path := location
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		if id, err := trackIDByPath(lib, filepath.Clean(path)); id != 0 || err != nil {
+			return id, err
+		}
+
+		parts := pathParts(location)
+		// At least a folder and the file name, unless that's all there is
+		minParts := min(2, len(parts))
+		for n := len(parts); n >= minParts && n > 0; n-- {
+			suffix := filepath.Join(parts[len(parts)-n:]...)
+			for _, source := range sources {
+				if id, err := trackIDByPath(lib, filepath.Join(source, suffix)); id != 0 || err != nil {
+					return id, err
+				}
+			}
+		}
+	}
+
+	artist, title := e.Artist, e.Title
+	if artist == "" {
+		artist, title = splitDisplay(title)
+	}
+	if artist == "" || title == "" {
+		return 0, nil
+	}
+	id, err := lib.TrackIDByArtistTitle(artist, title)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return id, err
+}
+
+func trackIDByPath(lib Library, path string) (int64, error) {
+	t, err := lib.TrackByPath(path)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return t.ID, nil
+}
+
+// pathParts splits a path into its names, dropping the root, drive letters
+// and "." or ".." elements.
+func pathParts(path string) []string {
+	var parts []string
+	for _, p := range strings.Split(filepath.ToSlash(path), "/") {
+		if p == "" || p == "." || p == ".." || strings.HasSuffix(p, ":") {
+			continue
+		}
+		parts = append(parts, p)
+	}
+	return parts
+}
@@ -0,0 +1,104 @@
+package playlistfile
+
+import (
+	"bufio"
+	"encoding/xml"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const xspfNamespace = "http://xspf.org/ns/0/"
+
+type xspfPlaylist struct {
+	XMLName xml.Name    `xml:"playlist"`
+	Version string      `xml:"version,attr"`
+	Xmlns   string      `xml:"xmlns,attr,omitempty"`
+	Title   string      `xml:"title,omitempty"`
+	Tracks  []xspfTrack `xml:"trackList>track"`
+}
+
+type xspfTrack struct {
+	Location string `xml:"location"`
+	Creator  string `xml:"creator,omitempty"`
+	Title    string `xml:"title,omitempty"`
+	Duration int64  `xml:"duration,omitempty"` // Milliseconds
+}
+
+func parseXSPF(data []byte) (*File, error) {
+	var pl xspfPlaylist
+	if err := xml.Unmarshal(data, &pl); err != nil {
+		return nil, err
+	}
+
+	file := &File{Title: strings.TrimSpace(pl.Title)}
+	for _, t := range pl.Tracks {
+		location := strings.TrimSpace(t.Location)
+		if location == "" {
+			continue
+		}
+		if !isURL(location) {
+			// Relative URI
+			if p, err := url.PathUnescape(location); err == nil {
+				location = p
+			}
+		}
+		file.Entries = append(file.Entries, Entry{
+			Location: locationFromURI(location),
+			Artist:   strings.TrimSpace(t.Creator),
+			Title:    strings.TrimSpace(t.Title),
+			Duration: time.Duration(t.Duration) * time.Millisecond,
+		})
+	}
+	return file, nil
+}
+
+func writeXSPF(w *bufio.Writer, title string, entries []Entry) error {
+	pl := xspfPlaylist{Version: "1", Xmlns: xspfNamespace, Title: title}
+	for _, e := range entries {
+		pl.Tracks = append(pl.Tracks, xspfTrack{
+			Location: uriFromLocation(e.Location),
+			Creator:  e.Artist,
+			Title:    e.Title,
+			Duration: max(e.Duration.Milliseconds(), 0),
+		})
+	}
+
+	if _, err := w.WriteString(xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(pl); err != nil {
+		return err
+	}
+	_, err := w.WriteString("\n")
+	return err
+}
+
+// locationFromURI returns the path of file:// URIs, and other locations
+// unchanged.
+func locationFromURI(location string) string {
+	if !strings.HasPrefix(strings.ToLower(location), "file:") {
+		return location
+	}
+	u, err := url.Parse(location)
+	if err != nil || u.Path == "" {
+		return location
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+// uriFromLocation returns the XSPF location of a path: a file:// URI for
+// absolute paths and an escaped relative URI otherwise.
+func uriFromLocation(location string) string {
+	if isURL(location) {
+		return location
+	}
+	u := url.URL{Path: filepath.ToSlash(location)}
+	if filepath.IsAbs(location) {
+		u.Scheme = "file"
+	}
+	return u.String()
+}
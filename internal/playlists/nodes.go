@@ -15,6 +15,8 @@ const (
 	LevelFolder
 	LevelPlaylist
 	LevelTrack
+	LevelStations // Section listing the radio stations
+	LevelStation
 )
 
 // Node represents a node in the playlist hierarchy.
@@ -25,7 +27,9 @@ type Node struct {
 	position           int // Position in playlist (for tracks)
 	track              *playlist.Track
 	name               string
-	containingFolderID *int64 // Folder containing this playlist/track
+	containingFolderID *int64   // Folder containing this playlist/track
+	station            *Station // Station data for station nodes
+	smart              bool     // Smart playlist, or track of one
 }
 
 // ID returns a unique identifier for this node.
@@ -48,6 +52,13 @@ func (n Node) ID() string {
 			return sourceutil.FormatID("playlists", "track", sourceutil.FormatInt64(*n.playlistID), sourceutil.FormatInt(n.position))
 		}
 		return ""
+	case LevelStations:
+		return sourceutil.FormatID("playlists", "stations")
+	case LevelStation:
+		if n.station != nil {
+			return sourceutil.FormatID("playlists", "station", sourceutil.FormatInt64(n.station.ID))
+		}
+		return ""
 	}
 	return ""
 }
@@ -59,17 +70,20 @@ func (n Node) DisplayName() string {
 
 // IsContainer returns true if this node can be navigated into.
 func (n Node) IsContainer() bool {
-	return n.level != LevelTrack
+	return n.level != LevelTrack && n.level != LevelStation
 }
 
 // IconType returns the icon type for this node.
 func (n Node) IconType() navigator.IconType {
 	switch n.level {
-	case LevelRoot, LevelFolder:
+	case LevelRoot, LevelFolder, LevelStations:
 		return navigator.IconFolder
 	case LevelPlaylist:
+		if n.smart {
+			return navigator.IconSmartPlaylist
+		}
 		return navigator.IconPlaylist
-	case LevelTrack:
+	case LevelTrack, LevelStation:
 		return navigator.IconAudio
 	default:
 		return navigator.IconFolder
@@ -101,6 +115,16 @@ func (n Node) Track() *playlist.Track {
 	return n.track
 }
 
+// IsSmart returns true for smart playlists and their tracks.
+func (n Node) IsSmart() bool {
+	return n.smart
+}
+
+// Station returns the station data for station nodes, nil otherwise.
+func (n Node) Station() *Station {
+	return n.station
+}
+
 // TrackID returns the library track ID for track nodes, 0 otherwise.
 // Implements navigator.TrackIDProvider.
 func (n Node) TrackID() int64 {
@@ -115,7 +139,7 @@ func (n Node) TrackID() int64 {
 // For playlists/tracks, this returns the containing folder's ID.
 func (n Node) ParentFolderID() *int64 {
 	switch n.level {
-	case LevelRoot:
+	case LevelRoot, LevelStations, LevelStation:
 		return nil
 	case LevelFolder:
 		return n.folderID
@@ -140,11 +164,14 @@ func (n NodeItem) DisplayText() string {
 	switch n.Node.level {
 	case LevelRoot:
 		return n.Node.DisplayName()
-	case LevelFolder:
+	case LevelFolder, LevelStations:
 		return icons.FormatDir(n.Node.DisplayName())
 	case LevelPlaylist:
+		if n.Node.smart {
+			return icons.FormatSmartPlaylist(n.Node.DisplayName())
+		}
 		return icons.FormatPlaylist(n.Node.DisplayName())
-	case LevelTrack:
+	case LevelTrack, LevelStation:
 		return icons.FormatAudio(n.Node.DisplayName())
 	}
 	return n.Node.DisplayName()
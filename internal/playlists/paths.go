@@ -14,6 +14,8 @@ func (s *Source) DisplayPath(node Node) string {
 	switch node.level {
 	case LevelRoot:
 		return ""
+	case LevelStations, LevelStation:
+		return icons.FormatDir(StationsName)
 	case LevelFolder:
 		if node.folderID == nil {
 			return ""
@@ -27,7 +29,7 @@ func (s *Source) DisplayPath(node Node) string {
 		if err != nil {
 			return ""
 		}
-		playlistName := icons.FormatPlaylist(pl.Name)
+		playlistName := formatPlaylistName(pl)
 		if pl.FolderID != nil {
 			path := s.buildFolderPathWithIcons(*pl.FolderID)
 			return sourceutil.BuildPath(path, playlistName)
@@ -41,7 +43,7 @@ func (s *Source) DisplayPath(node Node) string {
 		if err != nil {
 			return ""
 		}
-		playlistName := icons.FormatPlaylist(pl.Name)
+		playlistName := formatPlaylistName(pl)
 		if pl.FolderID != nil {
 			path := s.buildFolderPathWithIcons(*pl.FolderID)
 			return sourceutil.BuildPath(path, playlistName)
@@ -70,3 +72,11 @@ func (s *Source) buildFolderPathWithIcons(folderID int64) string {
 
 	return strings.Join(parts, " > ")
 }
+
+// formatPlaylistName formats a playlist name with the icon of its kind.
+func formatPlaylistName(pl *Playlist) string {
+	if pl.Smart {
+		return icons.FormatSmartPlaylist(pl.Name)
+	}
+	return icons.FormatPlaylist(pl.Name)
+}
@@ -34,6 +34,7 @@ type Playlist struct {
 	Name       string
 	CreatedAt  int64
 	LastUsedAt int64
+	Smart      bool // Tracks come from rules, see Rules
 }
 
 // Playlists provides database operations for playlists.
@@ -81,14 +82,14 @@ func (p *Playlists) List(folderID *int64) ([]Playlist, error) {
 
 	if folderID == nil {
 		rows, err = p.db.Query(`
-			SELECT id, folder_id, name, created_at, last_used_at
+			SELECT id, folder_id, name, created_at, last_used_at, rules IS NOT NULL
 			FROM playlists
 			WHERE folder_id IS NULL
 			ORDER BY CASE WHEN id = ? THEN 0 ELSE 1 END, name COLLATE NOCASE
 		`, FavoritesPlaylistID)
 	} else {
 		rows, err = p.db.Query(`
-			SELECT id, folder_id, name, created_at, last_used_at
+			SELECT id, folder_id, name, created_at, last_used_at, rules IS NOT NULL
 			FROM playlists
 			WHERE folder_id = ?
 			ORDER BY CASE WHEN id = ? THEN 0 ELSE 1 END, name COLLATE NOCASE
@@ -103,7 +104,7 @@ func (p *Playlists) List(folderID *int64) ([]Playlist, error) {
 	for rows.Next() {
 		var pl Playlist
 		var folderID sql.NullInt64
-		if err := rows.Scan(&pl.ID, &folderID, &pl.Name, &pl.CreatedAt, &pl.LastUsedAt); err != nil {
+		if err := rows.Scan(&pl.ID, &folderID, &pl.Name, &pl.CreatedAt, &pl.LastUsedAt, &pl.Smart); err != nil {
 			return nil, err
 		}
 		pl.FolderID = dbutil.NullInt64ToPtr(folderID)
@@ -115,20 +116,33 @@ func (p *Playlists) List(folderID *int64) ([]Playlist, error) {
 // Get returns a playlist by its ID.
 func (p *Playlists) Get(id int64) (*Playlist, error) {
 	row := p.db.QueryRow(`
-		SELECT id, folder_id, name, created_at, last_used_at
+		SELECT id, folder_id, name, created_at, last_used_at, rules IS NOT NULL
 		FROM playlists
 		WHERE id = ?
 	`, id)
 
 	var pl Playlist
 	var folderID sql.NullInt64
-	if err := row.Scan(&pl.ID, &folderID, &pl.Name, &pl.CreatedAt, &pl.LastUsedAt); err != nil {
+	if err := row.Scan(&pl.ID, &folderID, &pl.Name, &pl.CreatedAt, &pl.LastUsedAt, &pl.Smart); err != nil {
 		return nil, err
 	}
 	pl.FolderID = dbutil.NullInt64ToPtr(folderID)
 	return &pl, nil
 }
 
+// GetByName returns the playlist with the given name, ignoring case. When
+// several playlists share the name, the first created is returned.
+func (p *Playlists) GetByName(name string) (*Playlist, error) {
+	var id int64
+	err := p.db.QueryRow(`
+		SELECT id FROM playlists WHERE name = ? COLLATE NOCASE ORDER BY id LIMIT 1
+	`, name).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+	return p.Get(id)
+}
+
 // UpdateLastUsed updates the last_used_at timestamp for a playlist.
 func (p *Playlists) UpdateLastUsed(id int64) error {
 	now := time.Now().Unix()
@@ -3,77 +3,22 @@ package playlists
 
 import (
 	"database/sql"
+	"errors"
+	"path/filepath"
 	"testing"
 
-	_ "modernc.org/sqlite"
-
 	"github.com/llehouerou/waves/internal/library"
+	"github.com/llehouerou/waves/internal/state"
 )
 
-// setupTestDB creates an in-memory SQLite database with the schema initialized.
-// Uses shared cache to ensure all connections see the same database.
+// setupTestDB opens a database with the schema of the state package.
 func setupTestDB(t *testing.T) *sql.DB {
 	t.Helper()
 
-	db, err := sql.Open("sqlite", "file::memory:?cache=shared")
-	if err != nil {
-		t.Fatalf("failed to open db: %v", err)
-	}
-
-	// Configure SQLite
-	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
-		db.Close()
-		t.Fatalf("failed to set pragma: %v", err)
-	}
-
-	// Create required tables
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS library_tracks (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			path TEXT NOT NULL UNIQUE,
-			mtime INTEGER NOT NULL,
-			artist TEXT NOT NULL,
-			album_artist TEXT NOT NULL,
-			album TEXT NOT NULL,
-			title TEXT NOT NULL,
-			disc_number INTEGER,
-			track_number INTEGER,
-			year INTEGER,
-			genre TEXT,
-			added_at INTEGER NOT NULL,
-			updated_at INTEGER NOT NULL
-		);
-
-		CREATE TABLE IF NOT EXISTS playlist_folders (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			parent_id INTEGER REFERENCES playlist_folders(id) ON DELETE CASCADE,
-			name TEXT NOT NULL,
-			created_at INTEGER NOT NULL,
-			UNIQUE(parent_id, name)
-		);
-
-		CREATE TABLE IF NOT EXISTS playlists (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			folder_id INTEGER REFERENCES playlist_folders(id) ON DELETE CASCADE,
-			name TEXT NOT NULL,
-			created_at INTEGER NOT NULL,
-			last_used_at INTEGER NOT NULL,
-			UNIQUE(folder_id, name)
-		);
-
-		CREATE TABLE IF NOT EXISTS playlist_tracks (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			playlist_id INTEGER NOT NULL REFERENCES playlists(id) ON DELETE CASCADE,
-			position INTEGER NOT NULL,
-			library_track_id INTEGER REFERENCES library_tracks(id) ON DELETE CASCADE,
-			UNIQUE(playlist_id, position)
-		);
-	`)
+	db, err := state.OpenDB(filepath.Join(t.TempDir(), "waves.db"))
 	if err != nil {
-		db.Close()
-		t.Fatalf("failed to create tables: %v", err)
+		t.Fatalf("failed to open test database: %v", err)
 	}
-
 	return db
 }
 
@@ -179,6 +124,28 @@ func TestPlaylist_Rename(t *testing.T) {
 	}
 }
 
+func TestPlaylist_GetByName(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	p := New(db, library.New(db))
+
+	id, _ := p.Create(nil, "Road Trip")
+	_, _ = p.Create(nil, "road trip")
+
+	pl, err := p.GetByName("ROAD TRIP")
+	if err != nil {
+		t.Fatalf("GetByName failed: %v", err)
+	}
+	if pl.ID != id {
+		t.Errorf("ID = %d, want %d", pl.ID, id)
+	}
+
+	if _, err := p.GetByName("Missing"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows, got %v", err)
+	}
+}
+
 func TestPlaylist_Delete(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -221,15 +188,14 @@ func TestPlaylist_List(t *testing.T) {
 		t.Fatalf("List failed: %v", err)
 	}
 
-	// Note: Favorites playlist is created by default in real schema,
-	// but not in our test setup
-	if len(playlists) != 2 {
-		t.Errorf("expected 2 playlists, got %d", len(playlists))
+	// The Favorites playlist is created with the schema
+	if len(playlists) != 3 {
+		t.Fatalf("expected 3 playlists, got %d", len(playlists))
 	}
 
 	// Should be sorted by name
-	if playlists[0].Name != "Playlist A" {
-		t.Errorf("first playlist = %q, want %q", playlists[0].Name, "Playlist A")
+	if playlists[0].Name != "Favorites" || playlists[1].Name != "Playlist A" {
+		t.Errorf("playlists = %q, %q, want %q, %q", playlists[0].Name, playlists[1].Name, "Favorites", "Playlist A")
 	}
 }
 
@@ -243,10 +209,10 @@ func TestPlaylist_ListInFolder(t *testing.T) {
 	_, _ = p.Create(nil, "Root Playlist")
 	_, _ = p.Create(&folderID, "Folder Playlist")
 
-	// List root playlists
+	// List root playlists, Favorites included
 	rootPlaylists, _ := p.List(nil)
-	if len(rootPlaylists) != 1 {
-		t.Errorf("expected 1 root playlist, got %d", len(rootPlaylists))
+	if len(rootPlaylists) != 2 {
+		t.Errorf("expected 2 root playlists, got %d", len(rootPlaylists))
 	}
 
 	// List folder playlists
@@ -942,8 +908,9 @@ func TestSearch_AllForAddToPlaylist(t *testing.T) {
 		t.Fatalf("AllForAddToPlaylist failed: %v", err)
 	}
 
-	if len(items) != 2 {
-		t.Errorf("expected 2 items, got %d", len(items))
+	// Favorites and the 2 playlists
+	if len(items) != 3 {
+		t.Errorf("expected 3 items, got %d", len(items))
 	}
 }
 
@@ -971,3 +938,96 @@ func TestSearch_AllForAddToPlaylist_WithFolders(t *testing.T) {
 		}
 	}
 }
+
+// Station tests
+
+func TestStations_CRUD(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	p := New(db, library.New(db))
+
+	id, err := p.AddStation("Zeta FM", "http://zeta.example/live")
+	if err != nil {
+		t.Fatalf("AddStation failed: %v", err)
+	}
+	_, _ = p.AddStation("alpha radio", "https://alpha.example/stream.mp3")
+
+	if _, err := p.AddStation("Dup", "http://zeta.example/live"); err == nil {
+		t.Error("expected error when adding a station URL twice")
+	}
+
+	if err := p.RenameStation(id, "Beta FM"); err != nil {
+		t.Fatalf("RenameStation failed: %v", err)
+	}
+	station, err := p.StationByID(id)
+	if err != nil {
+		t.Fatalf("StationByID failed: %v", err)
+	}
+	if station.Name != "Beta FM" || station.URL != "http://zeta.example/live" {
+		t.Errorf("station = %+v", station)
+	}
+
+	stations, _ := p.Stations()
+	if len(stations) != 2 || stations[0].Name != "alpha radio" || stations[1].Name != "Beta FM" {
+		t.Errorf("Stations() = %+v, want sorted by name", stations)
+	}
+
+	track := station.Track()
+	if track.Path != station.URL || track.Title != "Beta FM" {
+		t.Errorf("Track() = %+v, want the stream URL titled with the station name", track)
+	}
+
+	if err := p.DeleteStation(id); err != nil {
+		t.Fatalf("DeleteStation failed: %v", err)
+	}
+	if _, err := p.StationByID(id); err == nil {
+		t.Error("expected error when getting deleted station")
+	}
+}
+
+func TestSource_Stations(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	p := New(db, library.New(db))
+	src := NewSource(p)
+	id, _ := p.AddStation("Radio", "http://radio.example/live")
+	_, _ = p.Create(nil, "Playlist")
+
+	root, err := src.Children(src.Root())
+	if err != nil {
+		t.Fatalf("Children failed: %v", err)
+	}
+	// Stations, then Favorites and the playlist
+	if len(root) != 3 || root[0].Level() != LevelStations || !root[0].IsContainer() {
+		t.Fatalf("root children = %+v, want the stations section first", root)
+	}
+
+	stations, err := src.Children(root[0])
+	if err != nil {
+		t.Fatalf("Children failed: %v", err)
+	}
+	if len(stations) != 1 || stations[0].Level() != LevelStation || stations[0].IsContainer() {
+		t.Fatalf("station children = %+v", stations)
+	}
+	node := stations[0]
+	if node.Station() == nil || node.Station().ID != id {
+		t.Errorf("Station() = %+v", node.Station())
+	}
+
+	// Nodes round-trip through their IDs
+	for _, n := range []Node{root[0], node} {
+		got, ok := src.NodeFromID(n.ID())
+		if !ok || got.ID() != n.ID() || got.DisplayName() != n.DisplayName() {
+			t.Errorf("NodeFromID(%q) = %+v, %v", n.ID(), got, ok)
+		}
+	}
+
+	if parent := src.Parent(node); parent == nil || parent.Level() != LevelStations {
+		t.Errorf("Parent(station) = %+v, want the stations section", parent)
+	}
+	if parent := src.Parent(root[0]); parent == nil || parent.Level() != LevelRoot {
+		t.Errorf("Parent(stations) = %+v, want root", parent)
+	}
+}
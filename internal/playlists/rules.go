@@ -0,0 +1,452 @@
+package playlists
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Field is a library track field smart playlist rules match on.
+type Field string
+
+const (
+	FieldArtist      Field = "artist"
+	FieldAlbumArtist Field = "album_artist"
+	FieldAlbum       Field = "album"
+	FieldTitle       Field = "title"
+	FieldGenre       Field = "genre"
+	FieldLabel       Field = "label"
+	FieldFormat      Field = "format" // File extension, e.g. "flac"
+	FieldYear        Field = "year"
+	FieldPlayCount   Field = "play_count"
+	FieldSkipCount   Field = "skip_count"
+	FieldRating      Field = "rating"       // Stars, halves allowed
+	FieldAlbumRating Field = "album_rating" // Stars, halves allowed
+	FieldAdded       Field = "added"        // Days since the track was added
+	FieldLastPlayed  Field = "last_played"  // Days since the track was last played
+)
+
+// Fields lists the rule fields in the order the rule editor cycles through.
+var Fields = []Field{
+	FieldArtist, FieldAlbumArtist, FieldAlbum, FieldTitle, FieldGenre, FieldLabel, FieldFormat,
+	FieldYear, FieldPlayCount, FieldSkipCount, FieldRating, FieldAlbumRating, FieldAdded, FieldLastPlayed,
+}
+
+// String returns the field name for display, e.g. "album artist".
+func (f Field) String() string {
+	return strings.ReplaceAll(string(f), "_", " ")
+}
+
+// FieldKind is the type of values a field holds.
+type FieldKind int
+
+const (
+	KindText FieldKind = iota
+	KindNumber
+	KindDate
+)
+
+// Kind returns the type of values of the field.
+func (f Field) Kind() FieldKind {
+	switch f {
+	case FieldYear, FieldPlayCount, FieldSkipCount, FieldRating, FieldAlbumRating:
+		return KindNumber
+	case FieldAdded, FieldLastPlayed:
+		return KindDate
+	case FieldArtist, FieldAlbumArtist, FieldAlbum, FieldTitle, FieldGenre, FieldLabel, FieldFormat:
+	}
+	return KindText
+}
+
+// Ops returns the operators rules on the field can use.
+func (f Field) Ops() []Op {
+	switch f.Kind() {
+	case KindNumber:
+		return []Op{OpIs, OpIsNot, OpAtLeast, OpAtMost, OpBetween}
+	case KindDate:
+		return []Op{OpInLast, OpNotInLast}
+	case KindText:
+	}
+	if f == FieldFormat {
+		return []Op{OpIs, OpIsNot}
+	}
+	return []Op{OpContains, OpNotContains, OpIs, OpIsNot}
+}
+
+// Op is a rule operator.
+type Op string
+
+const (
+	OpIs          Op = "is"
+	OpIsNot       Op = "is_not"
+	OpContains    Op = "contains"
+	OpNotContains Op = "not_contains"
+	OpAtLeast     Op = "at_least"
+	OpAtMost      Op = "at_most"
+	OpBetween     Op = "between"     // Value to To, both included
+	OpInLast      Op = "in_last"     // Within the last Value days
+	OpNotInLast   Op = "not_in_last" // Not within the last Value days
+)
+
+// String returns the operator for display, e.g. "at least".
+func (o Op) String() string {
+	switch o {
+	case OpInLast:
+		return "in last (days)"
+	case OpNotInLast:
+		return "not in last (days)"
+	case OpIs, OpIsNot, OpContains, OpNotContains, OpAtLeast, OpAtMost, OpBetween:
+	}
+	return strings.ReplaceAll(string(o), "_", " ")
+}
+
+// Rule matches library tracks on a single field.
+type Rule struct {
+	Field Field  `json:"field"`
+	Op    Op     `json:"op"`
+	Value string `json:"value"`
+	To    string `json:"to,omitempty"` // Upper bound of OpBetween
+}
+
+// Match is how the rules of a group combine.
+type Match string
+
+const (
+	MatchAll Match = "all" // AND
+	MatchAny Match = "any" // OR
+)
+
+// Group combines rules and nested groups with AND or OR.
+type Group struct {
+	Match  Match   `json:"match"`
+	Rules  []Rule  `json:"rules,omitempty"`
+	Groups []Group `json:"groups,omitempty"`
+}
+
+// SortField is the order of the tracks of a smart playlist.
+type SortField string
+
+const (
+	SortAlbum      SortField = "album" // Album artist, album, disc and track
+	SortArtist     SortField = "artist"
+	SortTitle      SortField = "title"
+	SortYear       SortField = "year"
+	SortAdded      SortField = "added"
+	SortPlayCount  SortField = "play_count"
+	SortLastPlayed SortField = "last_played"
+	SortRating     SortField = "rating"
+	SortRandom     SortField = "random"
+)
+
+// SortFields lists the sort fields in the order the rule editor cycles through.
+var SortFields = []SortField{
+	SortAlbum, SortArtist, SortTitle, SortYear, SortAdded, SortPlayCount, SortLastPlayed, SortRating, SortRandom,
+}
+
+// String returns the sort field for display, e.g. "play count".
+func (s SortField) String() string {
+	return strings.ReplaceAll(string(s), "_", " ")
+}
+
+// Sort is a sort field and direction.
+type Sort struct {
+	Field SortField `json:"field"`
+	Desc  bool      `json:"desc,omitempty"`
+}
+
+// Rules define the tracks of a smart playlist: the library tracks matching
+// the root group, in the sort order, up to the limit.
+type Rules struct {
+	Group
+	Sort  Sort `json:"sort"`
+	Limit int  `json:"limit,omitempty"` // 0 for no limit
+}
+
+// DefaultRules returns the rules of a new smart playlist: a single empty
+// artist rule, in album order.
+func DefaultRules() Rules {
+	return Rules{
+		Group: Group{Match: MatchAll, Rules: []Rule{{Field: FieldArtist, Op: OpContains}}},
+		Sort:  Sort{Field: SortAlbum},
+	}
+}
+
+// ToJSON serializes the rules for database storage.
+func (r Rules) ToJSON() (string, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// RulesFromJSON deserializes rules from database storage.
+func RulesFromJSON(data string) (Rules, error) {
+	var r Rules
+	if err := json.Unmarshal([]byte(data), &r); err != nil {
+		return Rules{}, err
+	}
+	return r, nil
+}
+
+// Validate checks that the rules can be evaluated.
+func (r Rules) Validate() error {
+	_, _, err := r.query(time.Now())
+	return err
+}
+
+// trackColumns are the library track columns read into playlist tracks.
+const trackColumns = `id, path, title, artist, album, track_number, disc_number, genre, year`
+
+// query returns the SQL query selecting the tracks matching the rules, and
+// its arguments. now is the reference of date rules.
+func (r Rules) query(now time.Time) (string, []any, error) {
+	where, args, err := r.where(now)
+	if err != nil {
+		return "", nil, err
+	}
+	if where == "" {
+		where = "1"
+	}
+	orderBy, err := r.Sort.orderBy()
+	if err != nil {
+		return "", nil, err
+	}
+	if r.Limit < 0 {
+		return "", nil, errors.New("limit must not be negative")
+	}
+
+	q := "SELECT " + trackColumns + " FROM library_tracks WHERE " + where + " ORDER BY " + orderBy
+	if r.Limit > 0 {
+		q += " LIMIT ?"
+		args = append(args, r.Limit)
+	}
+	return q, args, nil
+}
+
+// where returns the SQL condition of a group, "" when it has no rules.
+func (g Group) where(now time.Time) (string, []any, error) {
+	sep := " AND "
+	switch g.Match {
+	case MatchAll, "":
+	case MatchAny:
+		sep = " OR "
+	default:
+		return "", nil, fmt.Errorf("unknown match %q", g.Match)
+	}
+
+	var parts []string
+	var args []any
+	for _, rule := range g.Rules {
+		cond, a, err := rule.where(now)
+		if err != nil {
+			return "", nil, err
+		}
+		parts = append(parts, cond)
+		args = append(args, a...)
+	}
+	for _, sub := range g.Groups {
+		cond, a, err := sub.where(now)
+		if err != nil {
+			return "", nil, err
+		}
+		if cond == "" {
+			continue
+		}
+		parts = append(parts, cond)
+		args = append(args, a...)
+	}
+
+	if len(parts) == 0 {
+		return "", nil, nil
+	}
+	return "(" + strings.Join(parts, sep) + ")", args, nil
+}
+
+// where returns the SQL condition of a rule.
+func (r Rule) where(now time.Time) (string, []any, error) {
+	switch r.Field.Kind() {
+	case KindText:
+		return r.textWhere()
+	case KindNumber:
+		return r.numberWhere()
+	case KindDate:
+		return r.dateWhere(now)
+	}
+	return "", nil, fmt.Errorf("unknown field %q", r.Field)
+}
+
+// textColumns maps text fields to their column expressions.
+var textColumns = map[Field]string{
+	FieldArtist:      "artist",
+	FieldAlbumArtist: "album_artist",
+	FieldAlbum:       "album",
+	FieldTitle:       "title",
+	FieldGenre:       "COALESCE(genre, '')",
+	FieldLabel:       "COALESCE(label, '')",
+}
+
+func (r Rule) textWhere() (string, []any, error) {
+	value := strings.TrimSpace(r.Value)
+	if value == "" {
+		return "", nil, fmt.Errorf("%s: value missing", r.Field)
+	}
+
+	if r.Field == FieldFormat {
+		pattern := "%." + escapeLike(strings.TrimPrefix(value, "."))
+		switch r.Op { //nolint:exhaustive // other operators are rejected
+		case OpIs:
+			return `COALESCE(source_path, path) LIKE ? ESCAPE '\'`, []any{pattern}, nil
+		case OpIsNot:
+			return `COALESCE(source_path, path) NOT LIKE ? ESCAPE '\'`, []any{pattern}, nil
+		}
+		return "", nil, fmt.Errorf("%s: unsupported operator %q", r.Field, r.Op)
+	}
+
+	col, ok := textColumns[r.Field]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown field %q", r.Field)
+	}
+	switch r.Op { //nolint:exhaustive // other operators are rejected
+	case OpIs:
+		return col + " = ? COLLATE NOCASE", []any{value}, nil
+	case OpIsNot:
+		return col + " <> ? COLLATE NOCASE", []any{value}, nil
+	case OpContains:
+		return col + ` LIKE ? ESCAPE '\'`, []any{"%" + escapeLike(value) + "%"}, nil
+	case OpNotContains:
+		return col + ` NOT LIKE ? ESCAPE '\'`, []any{"%" + escapeLike(value) + "%"}, nil
+	}
+	return "", nil, fmt.Errorf("%s: unsupported operator %q", r.Field, r.Op)
+}
+
+// numberColumns maps number fields to their column expressions.
+var numberColumns = map[Field]string{
+	FieldYear:        "COALESCE(year, 0)",
+	FieldPlayCount:   "play_count",
+	FieldSkipCount:   "skip_count",
+	FieldRating:      "rating",
+	FieldAlbumRating: "album_rating",
+}
+
+func (r Rule) numberWhere() (string, []any, error) {
+	col, ok := numberColumns[r.Field]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown field %q", r.Field)
+	}
+	value, err := r.number(r.Value)
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch r.Op { //nolint:exhaustive // other operators are rejected
+	case OpIs:
+		return col + " = ?", []any{value}, nil
+	case OpIsNot:
+		return col + " <> ?", []any{value}, nil
+	case OpAtLeast:
+		return col + " >= ?", []any{value}, nil
+	case OpAtMost:
+		return col + " <= ?", []any{value}, nil
+	case OpBetween:
+		to, err := r.number(r.To)
+		if err != nil {
+			return "", nil, err
+		}
+		return col + " BETWEEN ? AND ?", []any{min(value, to), max(value, to)}, nil
+	}
+	return "", nil, fmt.Errorf("%s: unsupported operator %q", r.Field, r.Op)
+}
+
+// number parses a number rule value. Ratings are given in stars and stored
+// in half stars.
+func (r Rule) number(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("%s: value missing", r.Field)
+	}
+	if r.Field == FieldRating || r.Field == FieldAlbumRating {
+		stars, err := strconv.ParseFloat(s, 64)
+		if err != nil || stars < 0 || stars > 5 {
+			return 0, fmt.Errorf("%s: %q is not a number of stars (0-5)", r.Field, s)
+		}
+		return int(math.Round(stars * 2)), nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %q is not a number", r.Field, s)
+	}
+	return n, nil
+}
+
+func (r Rule) dateWhere(now time.Time) (string, []any, error) {
+	days, err := r.number(r.Value)
+	if err != nil {
+		return "", nil, err
+	}
+	since := now.AddDate(0, 0, -days).Unix()
+
+	switch r.Field { //nolint:exhaustive // only date fields
+	case FieldAdded:
+		switch r.Op { //nolint:exhaustive // other operators are rejected
+		case OpInLast:
+			return "added_at >= ?", []any{since}, nil
+		case OpNotInLast:
+			return "added_at < ?", []any{since}, nil
+		}
+	case FieldLastPlayed:
+		switch r.Op { //nolint:exhaustive // other operators are rejected
+		case OpInLast:
+			return "last_played_at >= ?", []any{since}, nil
+		case OpNotInLast:
+			return "(last_played_at IS NULL OR last_played_at < ?)", []any{since}, nil
+		}
+	}
+	return "", nil, fmt.Errorf("%s: unsupported operator %q", r.Field, r.Op)
+}
+
+// albumOrder is the natural order of tracks, used to break ties.
+const albumOrder = "album_artist COLLATE NOCASE, album COLLATE NOCASE, disc_number, track_number, title COLLATE NOCASE"
+
+// sortColumns maps sort fields to their column expressions.
+var sortColumns = map[SortField]string{
+	SortArtist:     "artist COLLATE NOCASE",
+	SortTitle:      "title COLLATE NOCASE",
+	SortYear:       "year",
+	SortAdded:      "added_at",
+	SortPlayCount:  "play_count",
+	SortLastPlayed: "last_played_at",
+	SortRating:     "rating",
+}
+
+// orderBy returns the SQL ORDER BY expression of the sort.
+func (s Sort) orderBy() (string, error) {
+	dir := ""
+	if s.Desc {
+		dir = " DESC"
+	}
+	switch s.Field {
+	case SortAlbum, "":
+		if !s.Desc {
+			return albumOrder, nil
+		}
+		return "album_artist COLLATE NOCASE DESC, album COLLATE NOCASE DESC, disc_number DESC, track_number DESC", nil
+	case SortRandom:
+		return "RANDOM()", nil
+	case SortArtist, SortTitle, SortYear, SortAdded, SortPlayCount, SortLastPlayed, SortRating:
+	}
+	col, ok := sortColumns[s.Field]
+	if !ok {
+		return "", fmt.Errorf("unknown sort field %q", s.Field)
+	}
+	return col + dir + ", " + albumOrder, nil
+}
+
+// escapeLike escapes the LIKE wildcards of s, using \ as escape character.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,133 @@
+package playlists
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRules_JSONRoundtrip(t *testing.T) {
+	rules := Rules{
+		Group: Group{
+			Match: MatchAll,
+			Rules: []Rule{{Field: FieldGenre, Op: OpIs, Value: "Jazz"}},
+			Groups: []Group{{
+				Match: MatchAny,
+				Rules: []Rule{
+					{Field: FieldYear, Op: OpBetween, Value: "1955", To: "1965"},
+					{Field: FieldRating, Op: OpAtLeast, Value: "4.5"},
+				},
+			}},
+		},
+		Sort:  Sort{Field: SortRating, Desc: true},
+		Limit: 50,
+	}
+
+	data, err := rules.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() error: %v", err)
+	}
+	got, err := RulesFromJSON(data)
+	if err != nil {
+		t.Fatalf("RulesFromJSON() error: %v", err)
+	}
+
+	gotData, _ := got.ToJSON()
+	if gotData != data {
+		t.Errorf("roundtrip = %s, want %s", gotData, data)
+	}
+}
+
+func TestRules_Query(t *testing.T) {
+	now := time.Unix(100*86400, 0)
+	rules := Rules{
+		Group: Group{
+			Match: MatchAll,
+			Rules: []Rule{{Field: FieldArtist, Op: OpContains, Value: "50%"}},
+			Groups: []Group{{
+				Match: MatchAny,
+				Rules: []Rule{
+					{Field: FieldYear, Op: OpBetween, Value: "1999", To: "1990"},
+					{Field: FieldAdded, Op: OpInLast, Value: "30"},
+				},
+			}},
+		},
+		Sort:  Sort{Field: SortPlayCount, Desc: true},
+		Limit: 10,
+	}
+
+	query, args, err := rules.query(now)
+	if err != nil {
+		t.Fatalf("query() error: %v", err)
+	}
+
+	wantWhere := `WHERE (artist LIKE ? ESCAPE '\' AND (COALESCE(year, 0) BETWEEN ? AND ? OR added_at >= ?))`
+	if !strings.Contains(query, wantWhere) {
+		t.Errorf("query = %q, want it to contain %q", query, wantWhere)
+	}
+	if !strings.Contains(query, "ORDER BY play_count DESC, ") || !strings.HasSuffix(query, "LIMIT ?") {
+		t.Errorf("query = %q, want play count order and a limit", query)
+	}
+
+	wantArgs := []any{`%50\%%`, 1990, 1999, int64(70 * 86400), 10}
+	if len(args) != len(wantArgs) {
+		t.Fatalf("args = %v, want %v", args, wantArgs)
+	}
+	for i := range wantArgs {
+		if args[i] != wantArgs[i] {
+			t.Errorf("args[%d] = %v (%T), want %v", i, args[i], args[i], wantArgs[i])
+		}
+	}
+}
+
+func TestRules_QueryWithoutRules(t *testing.T) {
+	query, args, err := Rules{}.query(time.Now())
+	if err != nil {
+		t.Fatalf("query() error: %v", err)
+	}
+	if !strings.Contains(query, "WHERE 1 ORDER BY album_artist") || len(args) != 0 {
+		t.Errorf("query = %q, %v, want every track in album order", query, args)
+	}
+}
+
+func TestRules_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		ok   bool
+	}{
+		{"text", Rule{Field: FieldGenre, Op: OpIs, Value: "Rock"}, true},
+		{"format", Rule{Field: FieldFormat, Op: OpIs, Value: ".flac"}, true},
+		{"half stars", Rule{Field: FieldRating, Op: OpAtLeast, Value: "3.5"}, true},
+		{"empty value", Rule{Field: FieldArtist, Op: OpContains, Value: " "}, false},
+		{"not a number", Rule{Field: FieldYear, Op: OpIs, Value: "nineties"}, false},
+		{"too many stars", Rule{Field: FieldRating, Op: OpIs, Value: "6"}, false},
+		{"range without end", Rule{Field: FieldYear, Op: OpBetween, Value: "1990"}, false},
+		{"wrong operator", Rule{Field: FieldAdded, Op: OpContains, Value: "3"}, false},
+		{"unknown field", Rule{Field: "bitrate", Op: OpIs, Value: "320"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := Rules{Group: Group{Rules: []Rule{tt.rule}}}
+			if err := rules.Validate(); (err == nil) != tt.ok {
+				t.Errorf("Validate() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+
+	if err := (Rules{Sort: Sort{Field: "bpm"}}).Validate(); err == nil {
+		t.Error("Validate() should reject unknown sort fields")
+	}
+}
+
+func TestField_Ops(t *testing.T) {
+	for _, f := range Fields {
+		for _, op := range f.Ops() {
+			rule := Rule{Field: f, Op: op, Value: "1", To: "2"}
+			if _, _, err := rule.where(time.Now()); err != nil {
+				t.Errorf("%s %s: %v", f, op, err)
+			}
+		}
+	}
+}
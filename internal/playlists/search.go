@@ -113,11 +113,12 @@ func (d DeepSearchItem) NodeID() string {
 	return fmt.Sprintf("playlists:track:%d:%d", d.PlaylistID, d.TrackPosition)
 }
 
-// AllForAddToPlaylist returns all playlists for the add-to-playlist picker, sorted by most recently used.
+// AllForAddToPlaylist returns all static playlists for the add-to-playlist picker, sorted by most recently used.
 func (p *Playlists) AllForAddToPlaylist() ([]SearchItem, error) {
 	rows, err := p.db.Query(`
 		SELECT id, name, folder_id, last_used_at
 		FROM playlists
+		WHERE rules IS NULL
 		ORDER BY last_used_at DESC
 	`)
 	if err != nil {
@@ -0,0 +1,81 @@
+package playlists
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrSmartPlaylist is returned when editing the tracks of a smart playlist,
+// whose tracks come from its rules.
+var ErrSmartPlaylist = errors.New("smart playlists are edited through their rules")
+
+// CreateSmart creates a smart playlist with the given rules.
+func (p *Playlists) CreateSmart(folderID *int64, name string, rules Rules) (int64, error) {
+	data, err := rules.ToJSON()
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().Unix()
+	result, err := p.db.Exec(`
+		INSERT INTO playlists (folder_id, name, created_at, last_used_at, rules)
+		VALUES (?, ?, ?, ?, ?)
+	`, folderID, name, now, now, data)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// SetRules replaces the rules of a smart playlist.
+func (p *Playlists) SetRules(id int64, rules Rules) error {
+	data, err := rules.ToJSON()
+	if err != nil {
+		return err
+	}
+	_, err = p.db.Exec(`UPDATE playlists SET rules = ? WHERE id = ? AND rules IS NOT NULL`, data, id)
+	return err
+}
+
+// SmartRules returns the rules of a playlist. ok is false for static
+// playlists.
+func (p *Playlists) SmartRules(id int64) (rules Rules, ok bool, err error) {
+	var data sql.NullString
+	if err := p.db.QueryRow(`SELECT rules FROM playlists WHERE id = ?`, id).Scan(&data); err != nil {
+		return Rules{}, false, err
+	}
+	if !data.Valid {
+		return Rules{}, false, nil
+	}
+	rules, err = RulesFromJSON(data.String)
+	if err != nil {
+		return Rules{}, false, err
+	}
+	return rules, true, nil
+}
+
+// Freeze turns a smart playlist into a static playlist holding the tracks
+// its rules currently match.
+func (p *Playlists) Freeze(id int64) error {
+	rules, ok, err := p.SmartRules(id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	tracks, err := p.SmartTracks(rules)
+	if err != nil {
+		return err
+	}
+
+	trackIDs := make([]int64, len(tracks))
+	for i, t := range tracks {
+		trackIDs[i] = t.ID
+	}
+	if err := p.SetTracks(id, trackIDs); err != nil {
+		return err
+	}
+	_, err = p.db.Exec(`UPDATE playlists SET rules = NULL WHERE id = ?`, id)
+	return err
+}
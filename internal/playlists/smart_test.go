@@ -0,0 +1,208 @@
+package playlists
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/llehouerou/waves/internal/library"
+)
+
+// insertSmartTestTracks inserts tracks with varied genres, years and ratings.
+func insertSmartTestTracks(t *testing.T, db *sql.DB) {
+	t.Helper()
+	tracks := []struct {
+		path   string
+		artist string
+		title  string
+		genre  string
+		year   int
+		rating int
+		plays  int
+	}{
+		{"/music/a.flac", "Miles Davis", "So What", "Jazz", 1959, 10, 12},
+		{"/music/b.mp3", "John Coltrane", "Naima", "Jazz", 1960, 7, 3},
+		{"/music/c.flac", "Radiohead", "Airbag", "Rock", 1997, 8, 20},
+		{"/music/d.ogg", "Blur", "Beetlebum", "Rock", 1997, 0, 0},
+	}
+	for _, tr := range tracks {
+		if _, err := db.Exec(`
+			INSERT INTO library_tracks (path, mtime, artist, album_artist, album, title, year, genre, rating, play_count, added_at, updated_at)
+			VALUES (?, 1000, ?, ?, 'Album', ?, ?, ?, ?, ?, 1000, 1000)
+		`, tr.path, tr.artist, tr.artist, tr.title, tr.year, tr.genre, tr.rating, tr.plays); err != nil {
+			t.Fatalf("failed to insert track: %v", err)
+		}
+	}
+}
+
+func trackTitles(t *testing.T, p *Playlists, id int64) []string {
+	t.Helper()
+	tracks, err := p.Tracks(id)
+	if err != nil {
+		t.Fatalf("Tracks() error: %v", err)
+	}
+	titles := make([]string, len(tracks))
+	for i, tr := range tracks {
+		titles[i] = tr.Title
+	}
+	return titles
+}
+
+func assertTitles(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("titles = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("titles = %v, want %v", got, want)
+			return
+		}
+	}
+}
+
+func TestSmartPlaylist_Tracks(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	insertSmartTestTracks(t, db)
+	p := New(db, library.New(db))
+
+	// Jazz, or rock rated 4 stars or more, best rated first
+	rules := Rules{
+		Group: Group{
+			Match: MatchAny,
+			Rules: []Rule{{Field: FieldGenre, Op: OpIs, Value: "jazz"}},
+			Groups: []Group{{
+				Match: MatchAll,
+				Rules: []Rule{
+					{Field: FieldGenre, Op: OpIs, Value: "Rock"},
+					{Field: FieldRating, Op: OpAtLeast, Value: "4"},
+				},
+			}},
+		},
+		Sort: Sort{Field: SortRating, Desc: true},
+	}
+	id, err := p.CreateSmart(nil, "Best", rules)
+	if err != nil {
+		t.Fatalf("CreateSmart() error: %v", err)
+	}
+	assertTitles(t, trackTitles(t, p, id), "So What", "Airbag", "Naima")
+
+	// Rules are evaluated each time the playlist is opened
+	if _, err := db.Exec(`UPDATE library_tracks SET rating = 10 WHERE title = 'Beetlebum'`); err != nil {
+		t.Fatal(err)
+	}
+	// Ties fall back to album order
+	assertTitles(t, trackTitles(t, p, id), "Beetlebum", "So What", "Airbag", "Naima")
+
+	rules.Limit = 1
+	rules.Sort = Sort{Field: SortPlayCount, Desc: true}
+	if err := p.SetRules(id, rules); err != nil {
+		t.Fatalf("SetRules() error: %v", err)
+	}
+	assertTitles(t, trackTitles(t, p, id), "Airbag")
+
+	pl, err := p.Get(id)
+	if err != nil || !pl.Smart {
+		t.Errorf("Get() = %+v, %v, want a smart playlist", pl, err)
+	}
+}
+
+func TestSmartPlaylist_Format(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	insertSmartTestTracks(t, db)
+	// A track of a CUE sheet, matched by its audio file
+	if _, err := db.Exec(`
+		INSERT INTO library_tracks (path, mtime, artist, album_artist, album, title, source_path, start_ms, end_ms, added_at, updated_at)
+		VALUES ('/music/e.cue#2', 1000, 'Pink Floyd', 'Pink Floyd', 'Album', 'Breathe', '/music/e.flac', 67000, 230987, 1000, 1000)
+	`); err != nil {
+		t.Fatalf("failed to insert track: %v", err)
+	}
+	p := New(db, library.New(db))
+
+	tracks, err := p.SmartTracks(Rules{Group: Group{Rules: []Rule{{Field: FieldFormat, Op: OpIs, Value: "FLAC"}}}})
+	if err != nil {
+		t.Fatalf("SmartTracks() error: %v", err)
+	}
+	if len(tracks) != 3 {
+		t.Errorf("flac tracks = %d, want 3", len(tracks))
+	}
+}
+
+func TestSmartPlaylist_Freeze(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	insertSmartTestTracks(t, db)
+	p := New(db, library.New(db))
+
+	id, err := p.CreateSmart(nil, "Rock", Rules{Group: Group{Rules: []Rule{{Field: FieldGenre, Op: OpIs, Value: "Rock"}}}})
+	if err != nil {
+		t.Fatalf("CreateSmart() error: %v", err)
+	}
+
+	// Smart playlists can't be picked to add tracks to
+	items, err := p.AllForAddToPlaylist()
+	if err != nil {
+		t.Fatalf("AllForAddToPlaylist() error: %v", err)
+	}
+	for _, item := range items {
+		if item.ID == id {
+			t.Error("AllForAddToPlaylist() should not list smart playlists")
+		}
+	}
+
+	if err := p.Freeze(id); err != nil {
+		t.Fatalf("Freeze() error: %v", err)
+	}
+	if _, smart, err := p.SmartRules(id); err != nil || smart {
+		t.Errorf("SmartRules() = %v, %v, want a static playlist", smart, err)
+	}
+
+	// The snapshot doesn't follow the library anymore
+	if _, err := db.Exec(`UPDATE library_tracks SET genre = 'Rock' WHERE title = 'Naima'`); err != nil {
+		t.Fatal(err)
+	}
+	assertTitles(t, trackTitles(t, p, id), "Beetlebum", "Airbag")
+}
+
+func TestSmartPlaylist_Nodes(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	insertSmartTestTracks(t, db)
+	p := New(db, library.New(db))
+	src := NewSource(p)
+
+	id, err := p.CreateSmart(nil, "Jazz", Rules{Group: Group{Rules: []Rule{{Field: FieldGenre, Op: OpIs, Value: "Jazz"}}}})
+	if err != nil {
+		t.Fatalf("CreateSmart() error: %v", err)
+	}
+
+	node, ok := src.NodeFromID(fmt.Sprintf("playlists:playlist:%d", id))
+	if !ok || !node.IsSmart() {
+		t.Fatalf("NodeFromID() = %+v, %v, want a smart playlist node", node, ok)
+	}
+	children, err := src.Children(node)
+	if err != nil || len(children) != 2 {
+		t.Fatalf("Children() = %d nodes, %v, want 2", len(children), err)
+	}
+	if !children[0].IsSmart() {
+		t.Error("tracks of a smart playlist should be marked smart")
+	}
+}
+
+func TestSmartPlaylist_AddTracks(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	insertSmartTestTracks(t, db)
+	p := New(db, library.New(db))
+
+	id, err := p.CreateSmart(nil, "All", Rules{})
+	if err != nil {
+		t.Fatalf("CreateSmart() error: %v", err)
+	}
+	if err := p.AddTracks(id, []int64{1}); !errors.Is(err, ErrSmartPlaylist) {
+		t.Errorf("AddTracks() = %v, want ErrSmartPlaylist", err)
+	}
+}
@@ -35,13 +35,45 @@ func (s *Source) Children(parent Node) ([]Node, error) {
 		return s.folderChildren(parent.folderID)
 	case LevelPlaylist:
 		return s.playlistChildren(parent.playlistID)
-	case LevelTrack:
+	case LevelStations:
+		return s.stationChildren()
+	case LevelTrack, LevelStation:
 		return nil, nil
 	}
 	return nil, nil
 }
 
-// rootChildren returns folders and playlists at the root level.
+// Stations returns the node of the section listing the stations.
+func (s *Source) Stations() Node {
+	return Node{
+		level: LevelStations,
+		name:  StationsName,
+	}
+}
+
+// stationChildren returns the saved stations.
+func (s *Source) stationChildren() ([]Node, error) {
+	stations, err := s.playlists.Stations()
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]Node, len(stations))
+	for i := range stations {
+		nodes[i] = stationNode(&stations[i])
+	}
+	return nodes, nil
+}
+
+func stationNode(station *Station) Node {
+	return Node{
+		level:   LevelStation,
+		station: station,
+		name:    station.Name,
+	}
+}
+
+// rootChildren returns the stations section, then folders and playlists
+// at the root level.
 func (s *Source) rootChildren() ([]Node, error) {
 	// Get root-level folders
 	folders, err := s.playlists.Folders(nil)
@@ -55,7 +87,8 @@ func (s *Source) rootChildren() ([]Node, error) {
 		return nil, err
 	}
 
-	nodes := make([]Node, 0, len(folders)+len(pls))
+	nodes := make([]Node, 0, 1+len(folders)+len(pls))
+	nodes = append(nodes, s.Stations())
 
 	for _, f := range folders {
 		id := f.ID
@@ -73,6 +106,7 @@ func (s *Source) rootChildren() ([]Node, error) {
 			playlistID:         &id,
 			name:               pl.Name,
 			containingFolderID: nil, // Root level
+			smart:              pl.Smart,
 		})
 	}
 
@@ -115,6 +149,7 @@ func (s *Source) folderChildren(folderID *int64) ([]Node, error) {
 			playlistID:         &id,
 			name:               pl.Name,
 			containingFolderID: folderID,
+			smart:              pl.Smart,
 		})
 	}
 
@@ -134,8 +169,10 @@ func (s *Source) playlistChildren(playlistID *int64) ([]Node, error) {
 
 	// Get the playlist's containing folder for context
 	var containingFolderID *int64
+	var smart bool
 	if pl, err := s.playlists.Get(*playlistID); err == nil {
 		containingFolderID = pl.FolderID
+		smart = pl.Smart
 	}
 
 	nodes := make([]Node, len(tracks))
@@ -155,6 +192,7 @@ func (s *Source) playlistChildren(playlistID *int64) ([]Node, error) {
 			track:              track,
 			name:               name,
 			containingFolderID: containingFolderID,
+			smart:              smart,
 		}
 	}
 	return nodes, nil
@@ -165,6 +203,12 @@ func (s *Source) Parent(node Node) *Node {
 	switch node.level {
 	case LevelRoot:
 		return nil
+	case LevelStations:
+		root := s.Root()
+		return &root
+	case LevelStation:
+		stations := s.Stations()
+		return &stations
 	case LevelFolder:
 		if node.folderID == nil {
 			return nil
@@ -221,6 +265,7 @@ func (s *Source) Parent(node Node) *Node {
 			level:      LevelPlaylist,
 			playlistID: node.playlistID,
 			name:       pl.Name,
+			smart:      pl.Smart,
 		}
 	}
 	return nil
@@ -236,6 +281,21 @@ func (s *Source) NodeFromID(id string) (Node, bool) {
 	switch parts[0] {
 	case "root":
 		return s.Root(), true
+	case "stations":
+		return s.Stations(), true
+	case "station":
+		if len(parts) < 2 {
+			return Node{}, false
+		}
+		stationID, ok := sourceutil.ParseInt64(parts[1])
+		if !ok {
+			return Node{}, false
+		}
+		station, err := s.playlists.StationByID(stationID)
+		if err != nil {
+			return Node{}, false
+		}
+		return stationNode(station), true
 	case "folder":
 		if len(parts) < 2 {
 			return Node{}, false
@@ -269,6 +329,7 @@ func (s *Source) NodeFromID(id string) (Node, bool) {
 			level:      LevelPlaylist,
 			playlistID: &playlistID,
 			name:       pl.Name,
+			smart:      pl.Smart,
 		}, true
 	case "track":
 		if len(parts) < 3 {
@@ -294,12 +355,17 @@ func (s *Source) NodeFromID(id string) (Node, bool) {
 		if track.TrackNumber > 0 {
 			name = fmt.Sprintf("%02d. %s", track.TrackNumber, name)
 		}
+		pl, err := s.playlists.Get(playlistID)
+		if err != nil {
+			return Node{}, false
+		}
 		return Node{
 			level:      LevelTrack,
 			playlistID: &playlistID,
 			position:   position,
 			track:      track,
 			name:       name,
+			smart:      pl.Smart,
 		}, true
 	}
 	return Node{}, false
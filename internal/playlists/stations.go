@@ -0,0 +1,86 @@
+package playlists
+
+import (
+	"time"
+
+	"github.com/llehouerou/waves/internal/playlist"
+)
+
+// StationsName is the display name of the section listing stations.
+const StationsName = "Stations"
+
+// Station is an internet radio station, played from its stream URL.
+type Station struct {
+	ID        int64
+	Name      string
+	URL       string
+	CreatedAt int64
+}
+
+// Track returns the station as a track to queue. The stream URL is its
+// path.
+func (s Station) Track() playlist.Track {
+	return playlist.Track{Path: s.URL, Title: s.Name}
+}
+
+// AddStation saves a station.
+func (p *Playlists) AddStation(name, url string) (int64, error) {
+	result, err := p.db.Exec(`
+		INSERT INTO stations (name, url, created_at)
+		VALUES (?, ?, ?)
+	`, name, url, time.Now().Unix())
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// RenameStation renames a station.
+func (p *Playlists) RenameStation(id int64, name string) error {
+	_, err := p.db.Exec(`UPDATE stations SET name = ? WHERE id = ?`, name, id)
+	return err
+}
+
+// DeleteStation deletes a station.
+func (p *Playlists) DeleteStation(id int64) error {
+	_, err := p.db.Exec(`DELETE FROM stations WHERE id = ?`, id)
+	return err
+}
+
+// Stations returns all stations sorted by name.
+func (p *Playlists) Stations() ([]Station, error) {
+	rows, err := p.db.Query(`
+		SELECT id, name, url, created_at
+		FROM stations
+		ORDER BY name COLLATE NOCASE
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stations []Station
+	for rows.Next() {
+		var s Station
+		if err := rows.Scan(&s.ID, &s.Name, &s.URL, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		stations = append(stations, s)
+	}
+	return stations, rows.Err()
+}
+
+// StationByID returns a station by its ID.
+func (p *Playlists) StationByID(id int64) (*Station, error) {
+	row := p.db.QueryRow(`
+		SELECT id, name, url, created_at
+		FROM stations
+		WHERE id = ?
+	`, id)
+
+	var s Station
+	if err := row.Scan(&s.ID, &s.Name, &s.URL, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -2,14 +2,25 @@ package playlists
 
 import (
 	"database/sql"
+	"errors"
+	"time"
 
 	dbutil "github.com/llehouerou/waves/internal/db"
 	"github.com/llehouerou/waves/internal/playlist"
 )
 
 // Tracks returns all tracks in a playlist, joined with library data.
+// The tracks of smart playlists are the library tracks matching their rules.
 // Returns playlist.Track instances ready for playback.
 func (p *Playlists) Tracks(playlistID int64) ([]playlist.Track, error) {
+	rules, smart, err := p.SmartRules(playlistID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if smart {
+		return p.SmartTracks(rules)
+	}
+
 	rows, err := p.db.Query(`
 		SELECT pt.library_track_id, lt.path, lt.title, lt.artist, lt.album,
 			lt.track_number, lt.disc_number, lt.genre, lt.year
@@ -22,7 +33,25 @@ func (p *Playlists) Tracks(playlistID int64) ([]playlist.Track, error) {
 		return nil, err
 	}
 	defer rows.Close()
+	return scanTracks(rows)
+}
 
+// SmartTracks returns the library tracks matching smart playlist rules.
+func (p *Playlists) SmartTracks(rules Rules) ([]playlist.Track, error) {
+	query, args, err := rules.query(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanTracks(rows)
+}
+
+// scanTracks reads playlist tracks from rows of trackColumns.
+func scanTracks(rows *sql.Rows) ([]playlist.Track, error) {
 	var tracks []playlist.Track
 	for rows.Next() {
 		var t playlist.Track
@@ -51,10 +80,14 @@ func (p *Playlists) TrackCount(playlistID int64) (int, error) {
 }
 
 // AddTracks adds tracks to a playlist by their library track IDs.
+// Returns ErrSmartPlaylist for smart playlists.
 func (p *Playlists) AddTracks(playlistID int64, trackIDs []int64) error {
 	if len(trackIDs) == 0 {
 		return nil
 	}
+	if _, smart, err := p.SmartRules(playlistID); err == nil && smart {
+		return ErrSmartPlaylist
+	}
 
 	// Get current max position
 	var maxPos sql.NullInt64
@@ -16,6 +16,8 @@ type Candidate struct {
 	Rank            int     // Rank in top tracks
 	UserScrobbled   bool    // Whether user has scrobbled this track
 	UserPlaycount   int     // User's scrobble count for this track
+	UserDataMissing bool    // Whether Last.fm had no scrobbles of the artist for the user
+	LocalPlaycount  int     // Play count from the library
 	IsFavorite      bool    // Whether track is in user's Favorites playlist
 	RecentlyPlayed  bool    // Whether track was recently played in session
 	Score           float64 // Final calculated score
@@ -3,6 +3,7 @@ package radio
 
 import (
 	"database/sql"
+	"math"
 	"slices"
 	"sync"
 
@@ -326,6 +327,8 @@ func (r *Radio) buildCandidatePool(matchedArtists []MatchedArtist, recentlyPlaye
 		// Build lookup maps for fast matching
 		topTrackMap := buildTopTrackMap(ad.topTracks)
 		userTrackMap := buildUserTrackMap(ad.userTracks)
+		// Without Last.fm user data, local play counts stand in for scrobbles
+		userDataMissing := len(ad.userTracks) == 0
 
 		// Create candidates
 		for i := range libraryTracks {
@@ -333,6 +336,8 @@ func (r *Radio) buildCandidatePool(matchedArtists []MatchedArtist, recentlyPlaye
 			candidate := Candidate{
 				LibraryTrack:    *lt,
 				SimilarityScore: ad.artist.LastfmArtist.MatchScore,
+				UserDataMissing: userDataMissing,
+				LocalPlaycount:  lt.PlayCount,
 			}
 
 			// Check top tracks for global playcount
@@ -481,14 +486,22 @@ func (r *Radio) calculateScore(c Candidate) float64 {
 		topTrackBoost = 1.0 + (r.config.TopTrackBoost / float64(c.Rank))
 	}
 
-	// Preference boost: favorites take priority over scrobbles
+	// Preference boost: favorites take priority over scrobbles, or local
+	// plays when Last.fm has no user data
 	preferenceBoost := 1.0
 	if c.IsFavorite {
 		preferenceBoost = r.config.FavoriteBoost
-	} else if c.UserScrobbled {
+	} else if c.UserScrobbled || (c.UserDataMissing && c.LocalPlaycount > 0) {
 		preferenceBoost = r.config.UserBoost
 	}
 
+	// Rating weight: five stars multiply by rating_boost and one star divides
+	// by it. Three stars and unrated tracks are neutral.
+	ratingWeight := 1.0
+	if c.LibraryTrack.Rating > 0 && r.config.RatingBoost > 0 {
+		ratingWeight = math.Pow(r.config.RatingBoost, float64(c.LibraryTrack.Rating-6)/4)
+	}
+
 	// Decay penalty for recently played
 	decayPenalty := 1.0
 	if c.RecentlyPlayed {
@@ -501,7 +514,7 @@ func (r *Radio) calculateScore(c Candidate) float64 {
 		similarityWeight = r.config.MinSimilarityWeight
 	}
 
-	return baseScore * topTrackBoost * preferenceBoost * decayPenalty * similarityWeight
+	return baseScore * topTrackBoost * preferenceBoost * ratingWeight * decayPenalty * similarityWeight
 }
 
 // countArtists returns a map of artist name to occurrence count.
@@ -485,6 +485,91 @@ func TestCalculateScore_FavoriteBoost(t *testing.T) {
 	}
 }
 
+func TestCalculateScore_RatingWeight(t *testing.T) {
+	r := &Radio{
+		config: config.RadioConfig{
+			TopTrackBoost:       3.0,
+			UserBoost:           1.3,
+			FavoriteBoost:       2.0,
+			RatingBoost:         2.0,
+			DecayFactor:         0.1,
+			MinSimilarityWeight: 0.1,
+		},
+	}
+
+	score := func(rating int) float64 {
+		return r.calculateScore(Candidate{
+			LibraryTrack:    library.Track{Rating: rating},
+			GlobalPlaycount: 5000000,
+			SimilarityScore: 1.0,
+		})
+	}
+
+	unrated := score(0)
+	if got := score(6); got != unrated {
+		t.Errorf("three stars score = %f, want neutral %f", got, unrated)
+	}
+	if got := score(10); got != unrated*2 {
+		t.Errorf("five stars score = %f, want %f", got, unrated*2)
+	}
+	if got := score(2); got != unrated/2 {
+		t.Errorf("one star score = %f, want %f", got, unrated/2)
+	}
+	if score(9) <= score(8) {
+		t.Error("higher ratings should score higher")
+	}
+}
+
+func TestCalculateScore_LocalPlaysWithoutUserData(t *testing.T) {
+	r := &Radio{
+		config: config.RadioConfig{
+			TopTrackBoost:       3.0,
+			UserBoost:           1.3,
+			FavoriteBoost:       2.0,
+			DecayFactor:         0.1,
+			MinSimilarityWeight: 0.1,
+		},
+	}
+
+	scrobbled := Candidate{
+		GlobalPlaycount: 5000000,
+		UserScrobbled:   true,
+		SimilarityScore: 1.0,
+	}
+
+	// No Last.fm user data: local plays boost like scrobbles
+	localPlayed := Candidate{
+		GlobalPlaycount: 5000000,
+		UserDataMissing: true,
+		LocalPlaycount:  3,
+		SimilarityScore: 1.0,
+	}
+
+	// Last.fm user data available: the track wasn't scrobbled
+	notScrobbled := Candidate{
+		GlobalPlaycount: 5000000,
+		LocalPlaycount:  3,
+		SimilarityScore: 1.0,
+	}
+
+	neverPlayed := Candidate{
+		GlobalPlaycount: 5000000,
+		UserDataMissing: true,
+		SimilarityScore: 1.0,
+	}
+
+	scoreScrobbled := r.calculateScore(scrobbled)
+	if score := r.calculateScore(localPlayed); score != scoreScrobbled {
+		t.Errorf("locally played score (%f) should equal scrobbled score (%f)", score, scoreScrobbled)
+	}
+	if score := r.calculateScore(notScrobbled); score >= scoreScrobbled {
+		t.Errorf("local plays should be ignored when Last.fm has user data, score %f", score)
+	}
+	if score := r.calculateScore(neverPlayed); score >= scoreScrobbled {
+		t.Errorf("never played score (%f) should be < scrobbled score (%f)", score, scoreScrobbled)
+	}
+}
+
 func TestCalculateScore_DecayPenalty(t *testing.T) {
 	r := &Radio{
 		config: config.RadioConfig{
@@ -0,0 +1,98 @@
+// Package resume remembers where long tracks, like audiobooks and podcast
+// episodes, were left so that they resume there on the next play.
+package resume
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/llehouerou/waves/internal/tags"
+)
+
+const (
+	// minPosition is how far into a track it must be left to be remembered.
+	minPosition = 10 * time.Second
+	// endMargin is how close to the end a track is considered finished.
+	endMargin = 30 * time.Second
+)
+
+// Policy decides which tracks are remembered.
+type Policy struct {
+	MinDuration time.Duration // Tracks at least this long, 0 disables
+	Genres      []string      // Tracks of these genres, whatever their length
+}
+
+// Remembers returns true if the position of a track is remembered.
+func (p Policy) Remembers(info *tags.FileInfo) bool {
+	if info == nil {
+		return false
+	}
+	if p.MinDuration > 0 && info.Duration >= p.MinDuration {
+		return true
+	}
+	for _, g := range p.Genres {
+		if g != "" && strings.EqualFold(strings.TrimSpace(info.Genre), g) {
+			return true
+		}
+	}
+	return false
+}
+
+// Store keeps resume positions in the state database, keyed by track path.
+type Store struct {
+	db     *sql.DB
+	policy Policy
+}
+
+// NewStore creates a store on the state database, remembering the tracks
+// the policy selects.
+func NewStore(db *sql.DB, policy Policy) *Store {
+	return &Store{db: db, policy: policy}
+}
+
+// Save remembers the position a track was left at. Positions near the
+// start or the end forget the track instead, so that it plays from the
+// start next time.
+func (s *Store) Save(info *tags.FileInfo, pos time.Duration) error {
+	if !s.policy.Remembers(info) {
+		return nil
+	}
+	if pos < minPosition || (info.Duration > 0 && pos >= info.Duration-endMargin) {
+		return s.Forget(info.Path)
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO resume_positions (path, position_ms, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(path) DO UPDATE SET
+			position_ms = excluded.position_ms,
+			updated_at = excluded.updated_at
+	`, info.Path, pos.Milliseconds(), time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("save resume position: %w", err)
+	}
+	return nil
+}
+
+// Forget forgets the position of a track, typically once it finished.
+func (s *Store) Forget(path string) error {
+	if _, err := s.db.Exec(`DELETE FROM resume_positions WHERE path = ?`, path); err != nil {
+		return fmt.Errorf("forget resume position: %w", err)
+	}
+	return nil
+}
+
+// Position returns where to resume a track, 0 if it isn't remembered.
+func (s *Store) Position(path string) (time.Duration, error) {
+	var ms int64
+	err := s.db.QueryRow(`SELECT position_ms FROM resume_positions WHERE path = ?`, path).Scan(&ms)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("query resume position: %w", err)
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
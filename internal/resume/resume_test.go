@@ -0,0 +1,72 @@
+package resume
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/llehouerou/waves/internal/state"
+	"github.com/llehouerou/waves/internal/tags"
+)
+
+// setupTestDB opens a database with the schema of the state package.
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := state.OpenDB(filepath.Join(t.TempDir(), "waves.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func info(path, genre string, duration time.Duration) *tags.FileInfo {
+	fi := &tags.FileInfo{}
+	fi.Path = path
+	fi.Genre = genre
+	fi.Duration = duration
+	return fi
+}
+
+func TestPolicy_Remembers(t *testing.T) {
+	p := Policy{MinDuration: 20 * time.Minute, Genres: []string{"Audiobook", "Podcast"}}
+
+	assert.True(t, p.Remembers(info("/book.m4b", "", time.Hour)))
+	assert.True(t, p.Remembers(info("/ep.mp3", " podcast ", 5*time.Minute)))
+	assert.False(t, p.Remembers(info("/song.flac", "Rock", 4*time.Minute)))
+	assert.False(t, p.Remembers(nil))
+	assert.False(t, Policy{}.Remembers(info("/book.m4b", "", time.Hour)))
+}
+
+func TestStore_SaveAndPosition(t *testing.T) {
+	s := NewStore(setupTestDB(t), Policy{MinDuration: 20 * time.Minute})
+	book := info("/book.m4b", "", time.Hour)
+
+	require.NoError(t, s.Save(book, 12*time.Minute))
+	require.NoError(t, s.Save(book, 25*time.Minute))
+	pos, err := s.Position(book.Path)
+	require.NoError(t, err)
+	assert.Equal(t, 25*time.Minute, pos)
+
+	// Short tracks aren't remembered.
+	song := info("/song.flac", "", 3*time.Minute)
+	require.NoError(t, s.Save(song, time.Minute))
+	pos, err = s.Position(song.Path)
+	require.NoError(t, err)
+	assert.Zero(t, pos)
+}
+
+func TestStore_SaveNearEdgesForgets(t *testing.T) {
+	s := NewStore(setupTestDB(t), Policy{MinDuration: 20 * time.Minute})
+	book := info("/book.m4b", "", time.Hour)
+
+	for _, edge := range []time.Duration{5 * time.Second, time.Hour - 10*time.Second} {
+		require.NoError(t, s.Save(book, 30*time.Minute))
+		require.NoError(t, s.Save(book, edge))
+		pos, err := s.Position(book.Path)
+		require.NoError(t, err)
+		assert.Zero(t, pos, "saving at %v should forget the position", edge)
+	}
+}
@@ -0,0 +1,148 @@
+package state
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/llehouerou/waves/internal/equalizer"
+)
+
+// GetEqualizer returns the saved equalizer profiles.
+func (m *Manager) GetEqualizer() (equalizer.State, error) {
+	return getEqualizer(m.db)
+}
+
+// SaveEqualizer persists the equalizer profiles.
+func (m *Manager) SaveEqualizer(s equalizer.State) error {
+	return saveEqualizer(m.db, s)
+}
+
+// ListEqualizerPresets returns all saved equalizer presets.
+func (m *Manager) ListEqualizerPresets() ([]equalizer.Preset, error) {
+	return listEqualizerPresets(m.db)
+}
+
+// SaveEqualizerPreset saves an equalizer preset, replacing one with the same name.
+func (m *Manager) SaveEqualizerPreset(name string, settings equalizer.Settings) (int64, error) {
+	return saveEqualizerPreset(m.db, name, settings)
+}
+
+// DeleteEqualizerPreset deletes an equalizer preset by ID.
+func (m *Manager) DeleteEqualizerPreset(id int64) error {
+	_, err := m.db.Exec("DELETE FROM equalizer_presets WHERE id = ?", id)
+	return err
+}
+
+func getEqualizer(db *sql.DB) (equalizer.State, error) {
+	var profile, speakersJSON, headphonesJSON string
+	err := db.QueryRow(`
+		SELECT profile, speakers, headphones FROM equalizer_state WHERE id = 1
+	`).Scan(&profile, &speakersJSON, &headphonesJSON)
+	if err == sql.ErrNoRows {
+		return equalizer.DefaultState(), nil
+	}
+	if err != nil {
+		return equalizer.State{}, err
+	}
+
+	s := equalizer.DefaultState()
+	s.Profile = equalizer.ParseProfile(profile)
+	if speakers, err := equalizer.FromJSON(speakersJSON); err == nil {
+		s.Speakers = speakers
+	}
+	if headphones, err := equalizer.FromJSON(headphonesJSON); err == nil {
+		s.Headphones = headphones
+	}
+	return s, nil
+}
+
+func saveEqualizer(db *sql.DB, s equalizer.State) error {
+	speakersJSON, err := s.Speakers.ToJSON()
+	if err != nil {
+		return err
+	}
+	headphonesJSON, err := s.Headphones.ToJSON()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO equalizer_state (id, profile, speakers, headphones)
+		VALUES (1, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			profile = excluded.profile,
+			speakers = excluded.speakers,
+			headphones = excluded.headphones
+	`, string(s.Profile), speakersJSON, headphonesJSON)
+	return err
+}
+
+func listEqualizerPresets(db *sql.DB) ([]equalizer.Preset, error) {
+	rows, err := db.Query(`
+		SELECT id, name, settings
+		FROM equalizer_presets
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var presets []equalizer.Preset
+	for rows.Next() {
+		var p equalizer.Preset
+		var settingsJSON string
+		if err := rows.Scan(&p.ID, &p.Name, &settingsJSON); err != nil {
+			return nil, err
+		}
+
+		settings, err := equalizer.FromJSON(settingsJSON)
+		if err != nil {
+			// Skip invalid presets
+			continue
+		}
+		p.Settings = settings
+
+		presets = append(presets, p)
+	}
+
+	return presets, rows.Err()
+}
+
+func saveEqualizerPreset(db *sql.DB, name string, settings equalizer.Settings) (int64, error) {
+	settingsJSON, err := settings.ToJSON()
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().Unix()
+	_, err = db.Exec(`
+		INSERT INTO equalizer_presets (name, settings, created_at, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			settings = excluded.settings,
+			updated_at = excluded.updated_at
+	`, name, settingsJSON, now, now)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = db.QueryRow("SELECT id FROM equalizer_presets WHERE name = ?", name).Scan(&id)
+	return id, err
+}
+
+// seedEqualizerPresets inserts the built-in presets that don't exist yet.
+func seedEqualizerPresets(db *sql.DB) {
+	now := time.Now().Unix()
+	for _, p := range equalizer.BuiltinPresets() {
+		settingsJSON, err := p.Settings.ToJSON()
+		if err != nil {
+			continue
+		}
+		_, _ = db.Exec(`
+			INSERT OR IGNORE INTO equalizer_presets (name, settings, created_at, updated_at)
+			VALUES (?, ?, ?, ?)
+		`, p.Name, settingsJSON, now, now)
+	}
+}
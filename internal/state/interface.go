@@ -5,6 +5,7 @@ import (
 	"database/sql"
 
 	"github.com/llehouerou/waves/internal/albumpreset"
+	"github.com/llehouerou/waves/internal/equalizer"
 )
 
 // Interface defines the state manager contract for dependency injection and testing.
@@ -19,6 +20,11 @@ type Interface interface {
 	ListAlbumPresets() ([]albumpreset.Preset, error)
 	SaveAlbumPreset(name string, settings albumpreset.Settings) (int64, error)
 	DeleteAlbumPreset(id int64) error
+	GetEqualizer() (equalizer.State, error)
+	SaveEqualizer(s equalizer.State) error
+	ListEqualizerPresets() ([]equalizer.Preset, error)
+	SaveEqualizerPreset(name string, settings equalizer.Settings) (int64, error)
+	DeleteEqualizerPreset(id int64) error
 	Close() error
 }
 
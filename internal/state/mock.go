@@ -5,6 +5,7 @@ import (
 	"database/sql"
 
 	"github.com/llehouerou/waves/internal/albumpreset"
+	"github.com/llehouerou/waves/internal/equalizer"
 )
 
 // Mock is a test double for Manager.
@@ -13,6 +14,8 @@ type Mock struct {
 	queueState  *QueueState
 	volumeState *VolumeState
 	presets     []albumpreset.Preset
+	eqState     *equalizer.State
+	eqPresets   []equalizer.Preset
 	closed      bool
 }
 
@@ -74,6 +77,45 @@ func (m *Mock) DeleteAlbumPreset(id int64) error {
 	return nil
 }
 
+func (m *Mock) GetEqualizer() (equalizer.State, error) {
+	if m.eqState == nil {
+		return equalizer.DefaultState(), nil
+	}
+	return m.eqState.Clone(), nil
+}
+
+func (m *Mock) SaveEqualizer(s equalizer.State) error {
+	s = s.Clone()
+	m.eqState = &s
+	return nil
+}
+
+func (m *Mock) ListEqualizerPresets() ([]equalizer.Preset, error) {
+	return m.eqPresets, nil
+}
+
+func (m *Mock) SaveEqualizerPreset(name string, settings equalizer.Settings) (int64, error) {
+	for i, p := range m.eqPresets {
+		if p.Name == name {
+			m.eqPresets[i].Settings = settings
+			return p.ID, nil
+		}
+	}
+	id := int64(len(m.eqPresets) + 1)
+	m.eqPresets = append(m.eqPresets, equalizer.Preset{ID: id, Name: name, Settings: settings})
+	return id, nil
+}
+
+func (m *Mock) DeleteEqualizerPreset(id int64) error {
+	for i, p := range m.eqPresets {
+		if p.ID == id {
+			m.eqPresets = append(m.eqPresets[:i], m.eqPresets[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
 // Test helpers
 
 func (m *Mock) SetNavigation(state *NavigationState) { m.navState = state }
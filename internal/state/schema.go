@@ -419,5 +419,123 @@ func initSchema(db *sql.DB) error {
 		)
 	`)
 
+	// Migration: create equalizer tables if not exists
+	_, _ = db.Exec(`
+		CREATE TABLE IF NOT EXISTS equalizer_presets (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE,
+			settings TEXT NOT NULL,
+			created_at INTEGER NOT NULL,
+			updated_at INTEGER NOT NULL
+		)
+	`)
+	_, _ = db.Exec(`
+		CREATE TABLE IF NOT EXISTS equalizer_state (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			profile TEXT NOT NULL DEFAULT 'speakers',
+			speakers TEXT NOT NULL,
+			headphones TEXT NOT NULL
+		)
+	`)
+	seedEqualizerPresets(db)
+
+	// Migration: create waveforms cache table if not exists
+	_, _ = db.Exec(`
+		CREATE TABLE IF NOT EXISTS waveforms (
+			path TEXT PRIMARY KEY,
+			mtime INTEGER NOT NULL,
+			data BLOB NOT NULL
+		)
+	`)
+
+	// Migration: create radio stations table if not exists
+	_, _ = db.Exec(`
+		CREATE TABLE IF NOT EXISTS stations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			url TEXT NOT NULL UNIQUE,
+			created_at INTEGER NOT NULL
+		)
+	`)
+
+	// Migration: create track bookmarks table if not exists
+	_, _ = db.Exec(`
+		CREATE TABLE IF NOT EXISTS bookmarks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			path TEXT NOT NULL,
+			position_ms INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			created_at INTEGER NOT NULL
+		)
+	`)
+	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_bookmarks_path ON bookmarks(path)`)
+
+	// Migration: create resume positions table if not exists
+	_, _ = db.Exec(`
+		CREATE TABLE IF NOT EXISTS resume_positions (
+			path TEXT PRIMARY KEY,
+			position_ms INTEGER NOT NULL,
+			updated_at INTEGER NOT NULL
+		)
+	`)
+
+	// Migration: create play history table if not exists
+	_, _ = db.Exec(`
+		CREATE TABLE IF NOT EXISTS play_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			track_id INTEGER,
+			path TEXT NOT NULL,
+			title TEXT NOT NULL,
+			artist TEXT NOT NULL,
+			album TEXT NOT NULL,
+			started_at INTEGER NOT NULL,
+			played_ms INTEGER NOT NULL,
+			completed INTEGER NOT NULL
+		)
+	`)
+	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_play_history_started_at ON play_history(started_at)`)
+
+	// Migration: add play statistics to library tracks
+	_, _ = db.Exec(`ALTER TABLE library_tracks ADD COLUMN play_count INTEGER NOT NULL DEFAULT 0`)
+	_, _ = db.Exec(`ALTER TABLE library_tracks ADD COLUMN skip_count INTEGER NOT NULL DEFAULT 0`)
+	_, _ = db.Exec(`ALTER TABLE library_tracks ADD COLUMN last_played_at INTEGER`)
+
+	// Migration: add track and album ratings, in half stars
+	_, _ = db.Exec(`ALTER TABLE library_tracks ADD COLUMN rating INTEGER NOT NULL DEFAULT 0`)
+	_, _ = db.Exec(`ALTER TABLE library_tracks ADD COLUMN album_rating INTEGER NOT NULL DEFAULT 0`)
+
+	// Migration: add smart playlist rules (JSON, NULL for static playlists)
+	_, _ = db.Exec(`ALTER TABLE playlists ADD COLUMN rules TEXT`)
+
+	// Migration: add the audio file of tracks and, for CUE sheet tracks, the
+	// section of it they play (end 0 for the end of the file). CUE tracks
+	// scanned before are rescanned to fill them in.
+	_, _ = db.Exec(`ALTER TABLE library_tracks ADD COLUMN source_path TEXT`)
+	_, _ = db.Exec(`ALTER TABLE library_tracks ADD COLUMN start_ms INTEGER NOT NULL DEFAULT 0`)
+	_, _ = db.Exec(`ALTER TABLE library_tracks ADD COLUMN end_ms INTEGER NOT NULL DEFAULT 0`)
+	_, _ = db.Exec(`UPDATE library_tracks SET source_path = path WHERE source_path IS NULL AND path NOT LIKE '%.cue#%'`)
+	_, _ = db.Exec(`UPDATE library_tracks SET mtime = 0 WHERE source_path IS NULL`)
+
+	// Insert play statistics presets (only if they don't exist)
+	now = time.Now().Unix()
+	_, _ = db.Exec(`
+		INSERT OR IGNORE INTO album_view_presets (name, group_fields, sort_criteria, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+	`,
+		"Most played",
+		`{"groupFields":[7],"groupSortOrder":0,"groupDateField":0,"sortCriteria":[{"field":7,"order":0}]}`,
+		`[{"field":7,"order":0}]`,
+		now, now,
+	)
+	_, _ = db.Exec(`
+		INSERT OR IGNORE INTO album_view_presets (name, group_fields, sort_criteria, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+	`,
+		"Recently played",
+		`{"groupFields":[8],"groupSortOrder":0,"groupDateField":0,"sortCriteria":[{"field":8,"order":0}]}`,
+		`[{"field":8,"order":0}]`,
+		now, now,
+	)
+
 	return nil
 }
@@ -35,7 +35,17 @@ func Open() (*Manager, error) {
 		return nil, err
 	}
 
-	db, err := sql.Open("sqlite", dbPath)
+	db, err := OpenDB(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{db: db}, nil
+}
+
+// OpenDB opens the database at path, configured and migrated like the one
+// of Open. Tests use it to work on the real schema.
+func OpenDB(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
 	if err != nil {
 		return nil, err
 	}
@@ -58,8 +68,7 @@ func Open() (*Manager, error) {
 		db.Close()
 		return nil, err
 	}
-
-	return &Manager{db: db}, nil
+	return db, nil
 }
 
 func (m *Manager) Close() error {
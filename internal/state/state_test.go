@@ -2,11 +2,14 @@ package state
 
 import (
 	"database/sql"
+	"reflect"
 	"testing"
 	"testing/synctest"
 	"time"
 
 	_ "modernc.org/sqlite"
+
+	"github.com/llehouerou/waves/internal/equalizer"
 )
 
 // setupTestDB creates an in-memory SQLite database with the schema initialized.
@@ -805,3 +808,99 @@ func TestManager_Close_NoPending(t *testing.T) {
 		}
 	})
 }
+
+func TestGetEqualizer_Empty(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	m := &Manager{db: db}
+
+	s, err := m.GetEqualizer()
+	if err != nil {
+		t.Fatalf("GetEqualizer failed: %v", err)
+	}
+	if s.Profile != equalizer.ProfileSpeakers || s.Speakers.Enabled || len(s.Speakers.Bands) == 0 {
+		t.Errorf("expected default state, got %+v", s)
+	}
+}
+
+func TestSaveAndGetEqualizer(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	m := &Manager{db: db}
+
+	s := equalizer.DefaultState()
+	s.Profile = equalizer.ProfileHeadphones
+	s.Headphones.Enabled = true
+	s.Headphones.PreampDB = -4
+	s.Headphones.Bands[0].GainDB = 5
+
+	if err := m.SaveEqualizer(s); err != nil {
+		t.Fatalf("SaveEqualizer failed: %v", err)
+	}
+	// Saving again updates the single row
+	if err := m.SaveEqualizer(s); err != nil {
+		t.Fatalf("SaveEqualizer (update) failed: %v", err)
+	}
+
+	got, err := m.GetEqualizer()
+	if err != nil {
+		t.Fatalf("GetEqualizer failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, s) {
+		t.Errorf("got %+v, want %+v", got, s)
+	}
+}
+
+func TestEqualizerPresets(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	m := &Manager{db: db}
+
+	presets, err := m.ListEqualizerPresets()
+	if err != nil {
+		t.Fatalf("ListEqualizerPresets failed: %v", err)
+	}
+	if len(presets) != len(equalizer.BuiltinPresets()) {
+		t.Fatalf("got %d presets, want the %d built-in ones", len(presets), len(equalizer.BuiltinPresets()))
+	}
+
+	settings := equalizer.Default()
+	settings.Enabled = true
+	settings.Bands[3].GainDB = -2
+	id, err := m.SaveEqualizerPreset("Mine", settings)
+	if err != nil {
+		t.Fatalf("SaveEqualizerPreset failed: %v", err)
+	}
+
+	// Same name replaces the settings and keeps the ID
+	settings.Bands[3].GainDB = -3
+	id2, err := m.SaveEqualizerPreset("Mine", settings)
+	if err != nil {
+		t.Fatalf("SaveEqualizerPreset (update) failed: %v", err)
+	}
+	if id2 != id {
+		t.Errorf("update returned id %d, want %d", id2, id)
+	}
+
+	presets, _ = m.ListEqualizerPresets()
+	var found *equalizer.Preset
+	for i := range presets {
+		if presets[i].Name == "Mine" {
+			found = &presets[i]
+		}
+	}
+	if found == nil || found.Settings.Bands[3].GainDB != -3 {
+		t.Fatalf("saved preset not found or not updated: %+v", found)
+	}
+
+	if err := m.DeleteEqualizerPreset(id); err != nil {
+		t.Fatalf("DeleteEqualizerPreset failed: %v", err)
+	}
+	presets, _ = m.ListEqualizerPresets()
+	if len(presets) != len(equalizer.BuiltinPresets()) {
+		t.Errorf("got %d presets after delete, want %d", len(presets), len(equalizer.BuiltinPresets()))
+	}
+}
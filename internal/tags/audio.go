@@ -13,13 +13,19 @@ import (
 	"github.com/gopxl/beep/v2/flac"
 	"github.com/llehouerou/go-m4a"
 	"github.com/llehouerou/go-mp3"
+
+	"github.com/llehouerou/waves/internal/cue"
 )
 
 // ReadAudioInfo reads audio stream properties (duration, format, sample rate).
 // This uses lighter-weight methods than full decoding where possible.
 func ReadAudioInfo(path string) (*AudioInfo, error) {
+	if cue.IsTrackPath(path) {
+		return readCueAudioInfo(path)
+	}
+
 	ext := strings.ToLower(filepath.Ext(path))
-	if ext != ExtMP3 && ext != ExtFLAC && ext != ExtOPUS && ext != ExtOGG && ext != ExtOGA && ext != ExtM4A && ext != ExtMP4 {
+	if !isSupportedExt(ext) {
 		return nil, fmt.Errorf("unsupported format: %s", ext)
 	}
 
@@ -36,8 +42,14 @@ func ReadAudioInfo(path string) (*AudioInfo, error) {
 		return readFLACStreamInfo(path)
 	case ExtOPUS, ExtOGG, ExtOGA:
 		return readOggAudioInfo(f)
-	case ExtM4A, ExtMP4:
+	case ExtM4A, ExtM4B, ExtMP4:
 		return readM4AAudioInfo(f)
+	case ExtWAV, ExtWAVE:
+		return readWAVAudioInfo(f)
+	case ExtAIF, ExtAIFF, ExtAIFC:
+		return readAIFFAudioInfo(f)
+	case ExtWV:
+		return readWavPackAudioInfo(f)
 	}
 
 	return nil, fmt.Errorf("unsupported format: %s", ext)
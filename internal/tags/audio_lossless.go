@@ -0,0 +1,253 @@
+package tags
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"time"
+)
+
+// framesDuration converts a number of sample frames to a duration.
+func framesDuration(frames int64, sampleRate int) time.Duration {
+	if sampleRate <= 0 || frames <= 0 {
+		return 0
+	}
+	return time.Duration(float64(frames) / float64(sampleRate) * float64(time.Second))
+}
+
+// readWAVAudioInfo extracts audio info from the fmt and data chunks of a
+// WAV (or RF64) file.
+func readWAVAudioInfo(f *os.File) (*AudioInfo, error) {
+	var head [12]byte
+	if _, err := io.ReadFull(f, head[:]); err != nil {
+		return nil, err
+	}
+	if (string(head[0:4]) != "RIFF" && string(head[0:4]) != "RF64") || string(head[8:12]) != "WAVE" {
+		return nil, errors.New("wav: not a RIFF/WAVE file")
+	}
+
+	var (
+		sampleRate, bits, blockAlign int
+		haveFmt                      bool
+		dataSize64                   int64 = -1
+	)
+	offset := int64(12)
+	for {
+		var ch [8]byte
+		if _, err := f.ReadAt(ch[:], offset); err != nil {
+			return nil, errors.New("wav: no data chunk")
+		}
+		id := string(ch[0:4])
+		size := int64(binary.LittleEndian.Uint32(ch[4:8]))
+		body := offset + 8
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, errors.New("wav: fmt chunk too short")
+			}
+			buf := make([]byte, min(size, 40))
+			if _, err := f.ReadAt(buf, body); err != nil {
+				return nil, err
+			}
+			format := binary.LittleEndian.Uint16(buf[0:2])
+			sampleRate = int(binary.LittleEndian.Uint32(buf[4:8]))
+			blockAlign = int(binary.LittleEndian.Uint16(buf[12:14]))
+			bits = int(binary.LittleEndian.Uint16(buf[14:16]))
+			// WAVE_FORMAT_EXTENSIBLE stores the number of meaningful bits
+			if format == 0xFFFE && len(buf) >= 20 {
+				if valid := int(binary.LittleEndian.Uint16(buf[18:20])); valid > 0 {
+					bits = valid
+				}
+			}
+			haveFmt = true
+		case "ds64":
+			// RF64 stores the real data size here
+			var b [16]byte
+			if _, err := f.ReadAt(b[:], body); err == nil {
+				dataSize64 = int64(binary.LittleEndian.Uint64(b[8:16])) //nolint:gosec // sizes fit in int64
+			}
+		case "data":
+			if !haveFmt {
+				return nil, errors.New("wav: data chunk before fmt chunk")
+			}
+			if dataSize64 >= 0 {
+				size = dataSize64
+			}
+			fi, err := f.Stat()
+			if err != nil {
+				return nil, err
+			}
+			// Files still being written may claim more data than there is
+			size = min(size, max(fi.Size()-body, 0))
+			var frames int64
+			if blockAlign > 0 {
+				frames = size / int64(blockAlign)
+			}
+			return &AudioInfo{
+				Duration:   framesDuration(frames, sampleRate),
+				Format:     "WAV",
+				SampleRate: sampleRate,
+				BitDepth:   bits,
+			}, nil
+		}
+		offset = body + size + size&1
+	}
+}
+
+// readAIFFAudioInfo extracts audio info from the COMM chunk of an AIFF or
+// AIFF-C file.
+func readAIFFAudioInfo(f *os.File) (*AudioInfo, error) {
+	var head [12]byte
+	if _, err := io.ReadFull(f, head[:]); err != nil {
+		return nil, err
+	}
+	if string(head[0:4]) != "FORM" || (string(head[8:12]) != "AIFF" && string(head[8:12]) != "AIFC") {
+		return nil, errors.New("aiff: not a FORM/AIFF file")
+	}
+
+	offset := int64(12)
+	for {
+		var ch [8]byte
+		if _, err := f.ReadAt(ch[:], offset); err != nil {
+			return nil, errors.New("aiff: no COMM chunk")
+		}
+		size := int64(binary.BigEndian.Uint32(ch[4:8]))
+		if string(ch[0:4]) == "COMM" {
+			var comm [18]byte
+			if _, err := f.ReadAt(comm[:], offset+8); err != nil {
+				return nil, errors.New("aiff: COMM chunk too short")
+			}
+			frames := int64(binary.BigEndian.Uint32(comm[2:6]))
+			sampleRate := int(extendedToFloat(comm[8:18]))
+			return &AudioInfo{
+				Duration:   framesDuration(frames, sampleRate),
+				Format:     "AIFF",
+				SampleRate: sampleRate,
+				BitDepth:   int(binary.BigEndian.Uint16(comm[6:8])),
+			}, nil
+		}
+		offset += 8 + size + size&1
+	}
+}
+
+// extendedToFloat converts an 80-bit IEEE 754 extended float, used for the
+// AIFF sample rate.
+func extendedToFloat(b []byte) float64 {
+	exp := int(binary.BigEndian.Uint16(b[0:2]))
+	mant := binary.BigEndian.Uint64(b[2:10])
+	if exp&0x7FFF == 0 && mant == 0 {
+		return 0
+	}
+	v := math.Ldexp(float64(mant), exp&0x7FFF-16383-63)
+	if exp&0x8000 != 0 {
+		v = -v
+	}
+	return v
+}
+
+// WavPack header flags and sub-blocks used to describe the stream.
+const (
+	wvFlagFloat      = 0x80
+	wvFlagInitial    = 0x800
+	wvIDSampleRate   = 0x27
+	wvIDOddSize      = 0x40
+	wvIDLargeSize    = 0x80
+	wvCustomRateIdx  = 15
+	wvBlockHeaderLen = 32
+)
+
+var wvSampleRates = [15]int{
+	6000, 8000, 9600, 11025, 12000, 16000, 22050, 24000,
+	32000, 44100, 48000, 64000, 88200, 96000, 192000,
+}
+
+// readWavPackAudioInfo extracts audio info from the first block header of
+// a WavPack file.
+func readWavPackAudioInfo(f *os.File) (*AudioInfo, error) {
+	var h [wvBlockHeaderLen]byte
+	if _, err := io.ReadFull(f, h[:]); err != nil {
+		return nil, err
+	}
+	if string(h[0:4]) != "wvpk" {
+		return nil, errors.New("wavpack: invalid block header")
+	}
+	blockSize := int64(binary.LittleEndian.Uint32(h[4:8])) + 8
+	flags := binary.LittleEndian.Uint32(h[24:28])
+
+	bits := int(flags&3+1)*8 - int(flags>>13&0x1F)
+	if flags&wvFlagFloat != 0 {
+		bits = 32
+	}
+
+	sampleRate := 0
+	if idx := flags >> 23 & 0xF; idx != wvCustomRateIdx {
+		sampleRate = wvSampleRates[idx]
+	} else {
+		body := make([]byte, max(blockSize-wvBlockHeaderLen, 0))
+		if _, err := io.ReadFull(f, body); err != nil {
+			return nil, err
+		}
+		sampleRate = wvCustomSampleRate(body)
+	}
+
+	var frames int64
+	if total := binary.LittleEndian.Uint32(h[12:16]); total != 0xFFFFFFFF {
+		frames = int64(h[11])<<32 | int64(total)
+	} else {
+		frames = wvCountSamples(f)
+	}
+
+	return &AudioInfo{
+		Duration:   framesDuration(frames, sampleRate),
+		Format:     "WAVPACK",
+		SampleRate: sampleRate,
+		BitDepth:   bits,
+	}, nil
+}
+
+// wvCustomSampleRate finds the SAMPLE_RATE sub-block of a block body.
+func wvCustomSampleRate(body []byte) int {
+	for len(body) >= 2 {
+		id := body[0]
+		size := int(body[1]) * 2
+		head := 2
+		if id&wvIDLargeSize != 0 {
+			if len(body) < 4 {
+				return 0
+			}
+			size = (int(body[1]) | int(body[2])<<8 | int(body[3])<<16) * 2
+			head = 4
+		}
+		if head+size > len(body) {
+			return 0
+		}
+		data := body[head : head+size]
+		if id&wvIDOddSize != 0 && size > 0 {
+			data = data[:size-1]
+		}
+		if id&0x3F == wvIDSampleRate && len(data) >= 3 {
+			return int(data[0]) | int(data[1])<<8 | int(data[2])<<16
+		}
+		body = body[head+size:]
+	}
+	return 0
+}
+
+// wvCountSamples adds up the samples of every block, for files whose
+// header doesn't store the total (e.g. encoded from a pipe).
+func wvCountSamples(f *os.File) int64 {
+	var total int64
+	var h [wvBlockHeaderLen]byte
+	for offset := int64(0); ; {
+		if _, err := f.ReadAt(h[:], offset); err != nil || string(h[0:4]) != "wvpk" {
+			return total
+		}
+		if binary.LittleEndian.Uint32(h[24:28])&wvFlagInitial != 0 {
+			total += int64(binary.LittleEndian.Uint32(h[20:24]))
+		}
+		offset += int64(binary.LittleEndian.Uint32(h[4:8])) + 8
+	}
+}
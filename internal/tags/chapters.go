@@ -0,0 +1,117 @@
+package tags
+
+import (
+	"cmp"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bogem/id3v2/v2"
+)
+
+// Chapter is a chapter of an audiobook or podcast episode.
+type Chapter struct {
+	Title string
+	Start time.Duration
+	End   time.Duration
+}
+
+// ChapterAt returns the index of the chapter playing at pos, or -1 if pos
+// is before the first chapter.
+func ChapterAt(chapters []Chapter, pos time.Duration) int {
+	idx := -1
+	for i, c := range chapters {
+		if c.Start > pos {
+			break
+		}
+		idx = i
+	}
+	return idx
+}
+
+// ReadChapters reads the chapter list of an MP3 (ID3v2 CHAP frames) or an
+// M4A/M4B/MP4 (QuickTime chapter track or Nero chpl atom). The end of the
+// last chapter is set to duration. Other formats have no chapters.
+func ReadChapters(path string, duration time.Duration) ([]Chapter, error) {
+	var chapters []Chapter
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ExtMP3:
+		chapters, err = readMP3Chapters(path)
+	case ExtM4A, ExtM4B, ExtMP4:
+		chapters, err = readMP4ChaptersFile(path)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return finishChapters(chapters, duration), nil
+}
+
+// readMP3Chapters reads the ID3v2 CHAP frames of an MP3 file.
+func readMP3Chapters(path string) ([]Chapter, error) {
+	id3tag, err := id3v2.Open(path, id3v2.Options{Parse: true, ParseFrames: []string{"CHAP"}})
+	if err != nil {
+		return nil, err
+	}
+	defer id3tag.Close()
+
+	var chapters []Chapter
+	for _, f := range id3tag.GetFrames("CHAP") {
+		cf, ok := f.(id3v2.ChapterFrame)
+		if !ok {
+			continue
+		}
+		c := Chapter{Start: cf.StartTime, End: cf.EndTime}
+		if cf.Title != nil {
+			c.Title = cf.Title.Text
+		}
+		chapters = append(chapters, c)
+	}
+	return chapters, nil
+}
+
+// readMP4ChaptersFile reads the chapters of an M4A/M4B/MP4 file.
+func readMP4ChaptersFile(path string) ([]Chapter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return readMP4Chapters(f, st.Size())
+}
+
+// finishChapters sorts chapters by start, names untitled ones, and ends
+// each chapter where the next one starts.
+func finishChapters(chapters []Chapter, duration time.Duration) []Chapter {
+	if len(chapters) == 0 {
+		return nil
+	}
+	slices.SortStableFunc(chapters, func(a, b Chapter) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+	for i := range chapters {
+		c := &chapters[i]
+		c.Title = strings.TrimSpace(c.Title)
+		if c.Title == "" {
+			c.Title = "Chapter " + strconv.Itoa(i+1)
+		}
+		switch {
+		case i+1 < len(chapters):
+			c.End = chapters[i+1].Start
+		case duration > 0:
+			c.End = duration
+		case c.End < c.Start:
+			c.End = c.Start
+		}
+	}
+	return chapters
+}
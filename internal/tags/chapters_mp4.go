@@ -0,0 +1,453 @@
+package tags
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"slices"
+	"time"
+	"unicode/utf16"
+)
+
+// maxMP4BoxRead bounds the boxes read into memory while looking for
+// chapters. Chapter tables are tiny; anything bigger is a corrupt file.
+const maxMP4BoxRead = 16 << 20
+
+// neroTimescale is the unit of chpl timestamps (100ns).
+const neroTimescale = 10_000_000
+
+var errMP4Truncated = errors.New("truncated mp4 box")
+
+// mp4Box is a box of an MP4 file, located by its payload.
+type mp4Box struct {
+	typ  string
+	off  int64 // Payload offset in the file
+	size int64 // Payload size
+}
+
+// mp4Track holds what chapter reading needs from a trak box.
+type mp4Track struct {
+	id        uint32
+	handler   string   // hdlr type: "soun", "text", ...
+	chapters  []uint32 // IDs from tref/chap: the chapter track of this one
+	timescale uint32
+	stbl      []mp4Box // Sample table boxes
+}
+
+// readMP4Chapters reads the chapters of an MP4 file, preferring the
+// QuickTime chapter track over the Nero chpl atom.
+func readMP4Chapters(r io.ReaderAt, size int64) ([]Chapter, error) {
+	top, err := mp4Boxes(r, 0, size)
+	if err != nil {
+		return nil, err
+	}
+	moov, ok := findMP4Box(top, "moov")
+	if !ok {
+		return nil, errors.New("no moov box")
+	}
+	boxes, err := mp4Boxes(r, moov.off, moov.off+moov.size)
+	if err != nil {
+		return nil, err
+	}
+
+	var tracks []mp4Track
+	for _, b := range boxes {
+		if b.typ != "trak" {
+			continue
+		}
+		t, err := readMP4Track(r, b)
+		if err != nil {
+			return nil, err
+		}
+		tracks = append(tracks, t)
+	}
+	if t, ok := findChapterTrack(tracks); ok {
+		chapters, err := readTextTrackChapters(r, t)
+		if err == nil && len(chapters) > 0 {
+			return chapters, nil
+		}
+	}
+
+	if udta, ok := findMP4Box(boxes, "udta"); ok {
+		children, err := mp4Boxes(r, udta.off, udta.off+udta.size)
+		if err != nil {
+			return nil, err
+		}
+		if chpl, ok := findMP4Box(children, "chpl"); ok {
+			data, err := readMP4Box(r, chpl)
+			if err != nil {
+				return nil, err
+			}
+			return parseNeroChapters(data), nil
+		}
+	}
+	return nil, nil
+}
+
+// mp4Boxes lists the boxes between off and end.
+func mp4Boxes(r io.ReaderAt, off, end int64) ([]mp4Box, error) {
+	var boxes []mp4Box
+	var hdr [16]byte
+	for off+8 <= end {
+		if _, err := r.ReadAt(hdr[:8], off); err != nil {
+			return nil, err
+		}
+		size := int64(binary.BigEndian.Uint32(hdr[:4]))
+		hlen := int64(8)
+		switch size {
+		case 0: // Extends to the end
+			size = end - off
+		case 1: // 64-bit size follows the type
+			if _, err := r.ReadAt(hdr[8:16], off+8); err != nil {
+				return nil, err
+			}
+			size = int64(binary.BigEndian.Uint64(hdr[8:16])) //nolint:gosec // checked below
+			hlen = 16
+		}
+		if size < hlen || size > end-off {
+			return nil, errMP4Truncated
+		}
+		boxes = append(boxes, mp4Box{typ: string(hdr[4:8]), off: off + hlen, size: size - hlen})
+		off += size
+	}
+	return boxes, nil
+}
+
+// findMP4Box returns the first box of a type.
+func findMP4Box(boxes []mp4Box, typ string) (mp4Box, bool) {
+	for _, b := range boxes {
+		if b.typ == typ {
+			return b, true
+		}
+	}
+	return mp4Box{}, false
+}
+
+// readMP4Box reads the payload of a box.
+func readMP4Box(r io.ReaderAt, b mp4Box) ([]byte, error) {
+	if b.size > maxMP4BoxRead {
+		return nil, errors.New("mp4 box too large")
+	}
+	data := make([]byte, b.size)
+	if _, err := r.ReadAt(data, b.off); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// mp4Path returns the box at the end of a path of box types below parent.
+func mp4Path(r io.ReaderAt, parent mp4Box, path ...string) (mp4Box, bool, error) {
+	b := parent
+	for _, typ := range path {
+		children, err := mp4Boxes(r, b.off, b.off+b.size)
+		if err != nil {
+			return mp4Box{}, false, err
+		}
+		var ok bool
+		if b, ok = findMP4Box(children, typ); !ok {
+			return mp4Box{}, false, nil
+		}
+	}
+	return b, true, nil
+}
+
+// readMP4Track reads the ID, handler, chapter reference, timescale and
+// sample table boxes of a trak box.
+func readMP4Track(r io.ReaderAt, trak mp4Box) (mp4Track, error) {
+	var t mp4Track
+
+	if tkhd, ok, err := mp4Path(r, trak, "tkhd"); err != nil {
+		return t, err
+	} else if ok {
+		data, err := readMP4Box(r, tkhd)
+		if err != nil {
+			return t, err
+		}
+		// Version 1 has 64-bit creation and modification times.
+		off := 12
+		if len(data) > 0 && data[0] == 1 {
+			off = 20
+		}
+		if len(data) >= off+4 {
+			t.id = binary.BigEndian.Uint32(data[off:])
+		}
+	}
+
+	if chap, ok, err := mp4Path(r, trak, "tref", "chap"); err != nil {
+		return t, err
+	} else if ok {
+		data, err := readMP4Box(r, chap)
+		if err != nil {
+			return t, err
+		}
+		for i := 0; i+4 <= len(data); i += 4 {
+			t.chapters = append(t.chapters, binary.BigEndian.Uint32(data[i:]))
+		}
+	}
+
+	if hdlr, ok, err := mp4Path(r, trak, "mdia", "hdlr"); err != nil {
+		return t, err
+	} else if ok {
+		data, err := readMP4Box(r, hdlr)
+		if err != nil {
+			return t, err
+		}
+		if len(data) >= 12 {
+			t.handler = string(data[8:12])
+		}
+	}
+
+	if mdhd, ok, err := mp4Path(r, trak, "mdia", "mdhd"); err != nil {
+		return t, err
+	} else if ok {
+		data, err := readMP4Box(r, mdhd)
+		if err != nil {
+			return t, err
+		}
+		off := 12
+		if len(data) > 0 && data[0] == 1 {
+			off = 20
+		}
+		if len(data) >= off+4 {
+			t.timescale = binary.BigEndian.Uint32(data[off:])
+		}
+	}
+
+	if stbl, ok, err := mp4Path(r, trak, "mdia", "minf", "stbl"); err != nil {
+		return t, err
+	} else if ok {
+		boxes, err := mp4Boxes(r, stbl.off, stbl.off+stbl.size)
+		if err != nil {
+			return t, err
+		}
+		t.stbl = boxes
+	}
+	return t, nil
+}
+
+// findChapterTrack returns the text track another track names as its
+// chapter track.
+func findChapterTrack(tracks []mp4Track) (mp4Track, bool) {
+	for _, t := range tracks {
+		for _, id := range t.chapters {
+			for _, c := range tracks {
+				if c.id == id && (c.handler == "text" || c.handler == "sbtl") {
+					return c, true
+				}
+			}
+		}
+	}
+	return mp4Track{}, false
+}
+
+// readTextTrackChapters reads the chapters of a QuickTime chapter track:
+// one text sample per chapter, starting when the sample does.
+func readTextTrackChapters(r io.ReaderAt, t mp4Track) ([]Chapter, error) {
+	if t.timescale == 0 {
+		return nil, errors.New("chapter track has no timescale")
+	}
+	table := func(typ string) ([]byte, error) {
+		b, ok := findMP4Box(t.stbl, typ)
+		if !ok {
+			return nil, nil
+		}
+		return readMP4Box(r, b)
+	}
+	stts, err := table("stts")
+	if err != nil {
+		return nil, err
+	}
+	stsz, err := table("stsz")
+	if err != nil {
+		return nil, err
+	}
+	stsc, err := table("stsc")
+	if err != nil {
+		return nil, err
+	}
+	chunks, err := readChunkOffsets(table)
+	if err != nil {
+		return nil, err
+	}
+
+	starts := sampleStarts(stts)
+	sizes := sampleSizes(stsz)
+	offsets := sampleOffsets(stsc, chunks, sizes)
+	n := min(len(starts), len(sizes), len(offsets))
+
+	chapters := make([]Chapter, 0, n)
+	for i := range n {
+		title, err := readTextSample(r, offsets[i], sizes[i])
+		if err != nil {
+			return nil, err
+		}
+		chapters = append(chapters, Chapter{
+			Title: title,
+			Start: mp4Time(starts[i], uint64(t.timescale)),
+		})
+	}
+	return chapters, nil
+}
+
+// mp4Time converts a time in a timescale to a duration.
+func mp4Time(t, timescale uint64) time.Duration {
+	sec, rem := t/timescale, t%timescale
+	return time.Duration(sec)*time.Second + time.Duration(rem*uint64(time.Second)/timescale) //nolint:gosec // bounded by the file duration
+}
+
+// readChunkOffsets reads the chunk offsets from stco, or co64 for large
+// files.
+func readChunkOffsets(table func(string) ([]byte, error)) ([]int64, error) {
+	stco, err := table("stco")
+	if err != nil {
+		return nil, err
+	}
+	if stco != nil {
+		return fullBoxEntries(stco, 4, func(e []byte) int64 {
+			return int64(binary.BigEndian.Uint32(e))
+		}), nil
+	}
+	co64, err := table("co64")
+	if err != nil {
+		return nil, err
+	}
+	return fullBoxEntries(co64, 8, func(e []byte) int64 {
+		return int64(binary.BigEndian.Uint64(e)) //nolint:gosec // offsets fit in a file
+	}), nil
+}
+
+// fullBoxEntries decodes the entries of a table box: version, flags and an
+// entry count followed by fixed-size entries.
+func fullBoxEntries[T any](data []byte, size int, decode func([]byte) T) []T {
+	if len(data) < 8 {
+		return nil
+	}
+	count := int(binary.BigEndian.Uint32(data[4:8]))
+	data = data[8:]
+	count = min(count, len(data)/size)
+	entries := make([]T, 0, count)
+	for i := range count {
+		entries = append(entries, decode(data[i*size:]))
+	}
+	return entries
+}
+
+// sampleStarts returns the start time of each sample, in the track
+// timescale, from an stts box.
+func sampleStarts(stts []byte) []uint64 {
+	type run struct{ count, delta uint32 }
+	runs := fullBoxEntries(stts, 8, func(e []byte) run {
+		return run{binary.BigEndian.Uint32(e), binary.BigEndian.Uint32(e[4:])}
+	})
+	var starts []uint64
+	var t uint64
+	for _, r := range runs {
+		for range min(r.count, maxMP4BoxRead) {
+			starts = append(starts, t)
+			t += uint64(r.delta)
+		}
+	}
+	return starts
+}
+
+// sampleSizes returns the size of each sample from an stsz box.
+func sampleSizes(stsz []byte) []int64 {
+	if len(stsz) < 12 {
+		return nil
+	}
+	uniform := int64(binary.BigEndian.Uint32(stsz[4:8]))
+	count := int(binary.BigEndian.Uint32(stsz[8:12]))
+	if uniform == 0 {
+		// Same layout as other tables once the uniform size is skipped.
+		return fullBoxEntries(stsz[4:], 4, func(e []byte) int64 {
+			return int64(binary.BigEndian.Uint32(e))
+		})
+	}
+	return slices.Repeat([]int64{uniform}, min(count, maxMP4BoxRead))
+}
+
+// sampleOffsets returns the file offset of each sample from the
+// sample-to-chunk table, the chunk offsets and the sample sizes.
+func sampleOffsets(stsc []byte, chunks []int64, sizes []int64) []int64 {
+	type entry struct{ firstChunk, perChunk uint32 }
+	entries := fullBoxEntries(stsc, 12, func(e []byte) entry {
+		return entry{binary.BigEndian.Uint32(e), binary.BigEndian.Uint32(e[4:])}
+	})
+	offsets := make([]int64, 0, len(sizes))
+	for i, off := range chunks {
+		chunk := uint32(i + 1) //nolint:gosec // chunk counts fit
+		var per uint32
+		for _, e := range entries {
+			if e.firstChunk <= chunk {
+				per = e.perChunk
+			}
+		}
+		for range per {
+			if len(offsets) == len(sizes) {
+				return offsets
+			}
+			offsets = append(offsets, off)
+			off += sizes[len(offsets)-1]
+		}
+	}
+	return offsets
+}
+
+// readTextSample reads a QuickTime text sample: a 16-bit length followed
+// by UTF-8, or UTF-16 with a byte order mark.
+func readTextSample(r io.ReaderAt, off, size int64) (string, error) {
+	if size < 2 || size > maxMP4BoxRead {
+		return "", nil
+	}
+	data := make([]byte, size)
+	if _, err := r.ReadAt(data, off); err != nil {
+		return "", err
+	}
+	n := min(int(binary.BigEndian.Uint16(data)), len(data)-2)
+	text := data[2 : 2+n]
+	if len(text) >= 2 && text[0] == 0xFE && text[1] == 0xFF {
+		u := make([]uint16, 0, len(text)/2)
+		for i := 2; i+1 < len(text); i += 2 {
+			u = append(u, binary.BigEndian.Uint16(text[i:]))
+		}
+		return string(utf16.Decode(u)), nil
+	}
+	return string(text), nil
+}
+
+// parseNeroChapters parses a Nero chpl box: version, flags, a reserved
+// word in version 1, a chapter count, then for each chapter a start in
+// 100ns units and a length-prefixed title.
+func parseNeroChapters(data []byte) []Chapter {
+	if len(data) < 5 {
+		return nil
+	}
+	off := 4
+	if data[0] == 1 {
+		off += 4
+	}
+	if len(data) <= off {
+		return nil
+	}
+	count := int(data[off])
+	off++
+
+	chapters := make([]Chapter, 0, count)
+	for range count {
+		if len(data) < off+9 {
+			break
+		}
+		start := binary.BigEndian.Uint64(data[off:])
+		n := int(data[off+8])
+		off += 9
+		if len(data) < off+n {
+			break
+		}
+		chapters = append(chapters, Chapter{
+			Title: string(data[off : off+n]),
+			Start: mp4Time(start, neroTimescale),
+		})
+		off += n
+	}
+	return chapters
+}
@@ -0,0 +1,220 @@
+package tags
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bogem/id3v2/v2"
+)
+
+// box builds an MP4 box from its type and children or payload.
+func box(typ string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	out := binary.BigEndian.AppendUint32(nil, uint32(8+len(body))) //nolint:gosec // test boxes are small
+	out = append(out, typ...)
+	return append(out, body...)
+}
+
+// u32s encodes big-endian 32-bit words.
+func u32s(words ...uint32) []byte {
+	var out []byte
+	for _, w := range words {
+		out = binary.BigEndian.AppendUint32(out, w)
+	}
+	return out
+}
+
+// textSample encodes a QuickTime text sample.
+func textSample(s string) []byte {
+	out := binary.BigEndian.AppendUint16(nil, uint16(len(s))) //nolint:gosec // test titles are short
+	return append(out, s...)
+}
+
+// chplBox builds a version 1 Nero chapter box.
+func chplBox(titles []string, starts []time.Duration) []byte {
+	payload := []byte{1, 0, 0, 0, 0, 0, 0, 0, byte(len(titles))}
+	for i, title := range titles {
+		payload = binary.BigEndian.AppendUint64(payload, uint64(starts[i]/100)) //nolint:gosec // positive test values
+		payload = append(payload, byte(len(title)))
+		payload = append(payload, title...)
+	}
+	return box("chpl", payload)
+}
+
+// buildM4B builds an MP4 file with an audio track pointing to a text
+// chapter track (1s timescale ticks in ms), and a Nero chpl box.
+func buildM4B(titles []string, durationsMs []uint32, nero []string) []byte {
+	ftyp := box("ftyp", []byte("M4B "), u32s(0))
+
+	var samples []byte
+	var sizes []uint32
+	for _, title := range titles {
+		s := textSample(title)
+		samples = append(samples, s...)
+		sizes = append(sizes, uint32(len(s))) //nolint:gosec // test samples are small
+	}
+	mdat := box("mdat", samples)
+	dataOff := uint32(len(ftyp) + 8) //nolint:gosec // test files are small
+
+	var stts []uint32
+	for _, d := range durationsMs {
+		stts = append(stts, 1, d)
+	}
+	tkhd := func(id uint32) []byte { return box("tkhd", u32s(0, 0, 0, id, 0)) }
+	hdlr := func(typ string) []byte { return box("hdlr", u32s(0, 0), []byte(typ), u32s(0, 0, 0)) }
+
+	audio := box("trak",
+		tkhd(1),
+		box("tref", box("chap", u32s(2))),
+		box("mdia", box("mdhd", u32s(0, 0, 0, 44100, 0)), hdlr("soun")),
+	)
+	text := box("trak",
+		tkhd(2),
+		box("mdia",
+			box("mdhd", u32s(0, 0, 0, 1000, 0)),
+			hdlr("text"),
+			box("minf", box("stbl",
+				box("stts", u32s(0, uint32(len(durationsMs))), u32s(stts...)), //nolint:gosec // test
+				box("stsz", u32s(0, 0, uint32(len(sizes))), u32s(sizes...)),   //nolint:gosec // test
+				box("stsc", u32s(0, 1, 1, uint32(len(sizes)), 1)),             //nolint:gosec // test
+				box("stco", u32s(0, 1, dataOff)),
+			)),
+		),
+	)
+	moov := [][]byte{audio, text}
+	if nero != nil {
+		starts := make([]time.Duration, len(nero))
+		for i := range starts {
+			starts[i] = time.Duration(i) * time.Minute
+		}
+		moov = append(moov, box("udta", chplBox(nero, starts)))
+	}
+	return bytes.Join([][]byte{ftyp, mdat, box("moov", moov...)}, nil)
+}
+
+func TestReadMP4Chapters_TextTrack(t *testing.T) {
+	data := buildM4B(
+		[]string{"Opening", "The Journey", "Epilogue"},
+		[]uint32{90_000, 600_500, 30_000},
+		[]string{"Nero only"},
+	)
+
+	got, err := readMP4Chapters(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("readMP4Chapters() error = %v", err)
+	}
+	want := []Chapter{
+		{Title: "Opening", Start: 0},
+		{Title: "The Journey", Start: 90 * time.Second},
+		{Title: "Epilogue", Start: 690*time.Second + 500*time.Millisecond},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("readMP4Chapters() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("chapter %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestReadMP4Chapters_NeroFallback(t *testing.T) {
+	data := buildM4B(nil, nil, []string{"Part One", "Part Two"})
+
+	got, err := readMP4Chapters(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("readMP4Chapters() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Title != "Part One" || got[1].Title != "Part Two" || got[1].Start != time.Minute {
+		t.Errorf("readMP4Chapters() = %+v, want Nero chapters", got)
+	}
+}
+
+func TestReadChapters_M4BFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book.m4b")
+	data := buildM4B([]string{"One", ""}, []uint32{60_000, 60_000}, nil)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadChapters(path, 2*time.Minute)
+	if err != nil {
+		t.Fatalf("ReadChapters() error = %v", err)
+	}
+	want := []Chapter{
+		{Title: "One", Start: 0, End: time.Minute},
+		{Title: "Chapter 2", Start: time.Minute, End: 2 * time.Minute},
+	}
+	if len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("ReadChapters() = %+v, want %+v", got, want)
+	}
+}
+
+func TestReadChapters_MP3(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "episode.mp3")
+	createMinimalMP3(t, path)
+
+	tag, err := id3v2.Open(path, id3v2.Options{Parse: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Added out of order: chapters are sorted by start.
+	for _, c := range []Chapter{
+		{Title: "News", Start: 5 * time.Minute, End: 9 * time.Minute},
+		{Title: "Intro", Start: 0, End: 5 * time.Minute},
+	} {
+		tag.AddChapterFrame(id3v2.ChapterFrame{
+			ElementID:   c.Title,
+			StartTime:   c.Start,
+			EndTime:     c.End,
+			StartOffset: id3v2.IgnoredOffset,
+			EndOffset:   id3v2.IgnoredOffset,
+			Title:       &id3v2.TextFrame{Encoding: id3v2.EncodingUTF8, Text: c.Title},
+		})
+	}
+	if err := tag.Save(); err != nil {
+		t.Fatal(err)
+	}
+	tag.Close()
+
+	got, err := ReadChapters(path, 0)
+	if err != nil {
+		t.Fatalf("ReadChapters() error = %v", err)
+	}
+	want := []Chapter{
+		{Title: "Intro", Start: 0, End: 5 * time.Minute},
+		{Title: "News", Start: 5 * time.Minute, End: 9 * time.Minute},
+	}
+	if len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("ReadChapters() = %+v, want %+v", got, want)
+	}
+}
+
+func TestReadChapters_NoChapters(t *testing.T) {
+	got, err := ReadChapters("/music/song.flac", time.Minute)
+	if err != nil || got != nil {
+		t.Errorf("ReadChapters(flac) = %v, %v, want nil", got, err)
+	}
+}
+
+func TestChapterAt(t *testing.T) {
+	chapters := []Chapter{{Start: 10 * time.Second}, {Start: time.Minute}}
+	tests := []struct {
+		pos  time.Duration
+		want int
+	}{
+		{0, -1},
+		{10 * time.Second, 0},
+		{59 * time.Second, 0},
+		{time.Hour, 1},
+	}
+	for _, tt := range tests {
+		if got := ChapterAt(chapters, tt.pos); got != tt.want {
+			t.Errorf("ChapterAt(%v) = %d, want %d", tt.pos, got, tt.want)
+		}
+	}
+}
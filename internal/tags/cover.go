@@ -6,6 +6,9 @@ import (
 	"strings"
 
 	"github.com/dhowden/tag"
+	"go.senan.xyz/taglib"
+
+	"github.com/llehouerou/waves/internal/cue"
 )
 
 // Common cover art filenames to look for in album folders.
@@ -38,6 +41,18 @@ func ExtractCoverArt(path string) (data []byte, mimeType string, err error) {
 
 // ExtractEmbeddedArt reads embedded cover art from an audio file's metadata.
 func ExtractEmbeddedArt(path string) (data []byte, mimeType string, err error) {
+	if cue.IsTrackPath(path) {
+		ref, err := cue.Lookup(path)
+		if err != nil {
+			return nil, "", err
+		}
+		path = ref.AudioPath
+	}
+
+	if isTaglibOnlyExt(strings.ToLower(filepath.Ext(path))) {
+		return extractEmbeddedArtWithTaglib(path)
+	}
+
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, "", err
@@ -57,6 +72,19 @@ func ExtractEmbeddedArt(path string) (data []byte, mimeType string, err error) {
 	return pic.Data, pic.MIMEType, nil
 }
 
+// extractEmbeddedArtWithTaglib reads embedded cover art using TagLib, for
+// formats dhowden/tag doesn't support.
+func extractEmbeddedArtWithTaglib(path string) (data []byte, mimeType string, err error) {
+	data, err = taglib.ReadImage(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) == 0 {
+		return nil, "", nil
+	}
+	return data, detectMimeType(data), nil
+}
+
 // findFolderArt looks for common cover art files in the given directory.
 func findFolderArt(dir string) (data []byte, mimeType string, err error) {
 	for _, filename := range coverArtFilenames {
@@ -0,0 +1,239 @@
+package tags
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bogem/id3v2/v2"
+	"go.senan.xyz/taglib"
+
+	"github.com/llehouerou/waves/internal/cue"
+)
+
+// MaxRating is the highest rating: five stars, counted in half stars.
+const MaxRating = 10
+
+// Rating tag keys (Vorbis comments, TXXX descriptions and MP4 freeform atoms).
+// RATING, RATE and ALBUMRATING are percentages, FMPS_RATING is a fraction
+// between 0 and 1.
+const (
+	keyRating      = "RATING"
+	keyFMPSRating  = "FMPS_RATING"
+	keyMP4Rating   = "RATE"
+	keyAlbumRating = "ALBUMRATING"
+)
+
+// popmEmail identifies the POPM frame waves writes. Most players read the
+// rating of the Windows Media Player frame.
+const popmEmail = "Windows Media Player 9 Series"
+
+// popmRatings maps ratings in half stars to POPM values, following the
+// scale Windows Media Player and MediaMonkey use.
+var popmRatings = [MaxRating + 1]uint8{0, 13, 1, 54, 64, 118, 128, 186, 196, 242, 255}
+
+// ClampRating returns r bounded to the 0 (unrated) to MaxRating range.
+func ClampRating(r int) int {
+	return max(0, min(r, MaxRating))
+}
+
+// ratingFromPOPM converts a POPM value (1-255, 0 unknown) to half stars,
+// picking the closest value of the scale.
+func ratingFromPOPM(v uint8) int {
+	if v == 0 {
+		return 0
+	}
+	best := 1
+	for r := 2; r <= MaxRating; r++ {
+		if absDiff(popmRatings[r], v) < absDiff(popmRatings[best], v) {
+			best = r
+		}
+	}
+	return best
+}
+
+// absDiff returns the distance between two POPM values.
+func absDiff(a, b uint8) int {
+	return int(math.Abs(float64(a) - float64(b)))
+}
+
+// parseRating parses a RATING value. Most taggers write a percentage, some
+// a number of stars.
+func parseRating(s string) int {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || v <= 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0
+	}
+	if v <= 5 {
+		return ClampRating(int(math.Round(v * 2)))
+	}
+	return ClampRating(int(math.Round(v / 10)))
+}
+
+// parseFMPSRating parses an FMPS_RATING value, a fraction between 0 and 1.
+func parseFMPSRating(s string) int {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || v <= 0 || math.IsNaN(v) {
+		return 0
+	}
+	return ClampRating(int(math.Round(v * MaxRating)))
+}
+
+// formatRating formats a rating as a percentage, e.g. "70".
+func formatRating(r int) string {
+	return strconv.Itoa(ClampRating(r) * 100 / MaxRating)
+}
+
+// formatFMPSRating formats a rating as a fraction, e.g. "0.7".
+func formatFMPSRating(r int) string {
+	return strconv.FormatFloat(float64(ClampRating(r))/MaxRating, 'f', -1, 64)
+}
+
+// readRatings reads the track and album ratings using a key lookup function.
+func readRatings(get func(key string) string) (rating, albumRating int) {
+	if r := parseFMPSRating(get(keyFMPSRating)); r > 0 {
+		rating = r
+	} else if r := parseRating(get(keyRating)); r > 0 {
+		rating = r
+	} else {
+		rating = parseRating(get(keyMP4Rating))
+	}
+	return rating, parseRating(get(keyAlbumRating))
+}
+
+// readPOPMRating returns the rating of the POPM frames of an MP3 file,
+// preferring the frame waves writes.
+func readPOPMRating(id3tag *id3v2.Tag) int {
+	rating := 0
+	for _, frame := range id3tag.GetFrames("POPM") {
+		popm, ok := frame.(id3v2.PopularimeterFrame)
+		if !ok || popm.Rating == 0 {
+			continue
+		}
+		if popm.Email == popmEmail {
+			return ratingFromPOPM(popm.Rating)
+		}
+		if rating == 0 {
+			rating = ratingFromPOPM(popm.Rating)
+		}
+	}
+	return rating
+}
+
+// ratingTagValues returns the rating tags to write for the ratings set.
+// MP4 files store the track rating in a RATE atom, other formats in RATING
+// and FMPS_RATING.
+func ratingTagValues(t *Tag, mp4 bool) []tagValue {
+	var out []tagValue
+	if t.Rating > 0 {
+		if mp4 {
+			out = append(out, tagValue{keyMP4Rating, formatRating(t.Rating)})
+		} else {
+			out = append(out,
+				tagValue{keyRating, formatRating(t.Rating)},
+				tagValue{keyFMPSRating, formatFMPSRating(t.Rating)},
+			)
+		}
+	}
+	if t.AlbumRating > 0 {
+		out = append(out, tagValue{keyAlbumRating, formatRating(t.AlbumRating)})
+	}
+	return out
+}
+
+// WriteRatings writes the track and album ratings of a music file, in half
+// stars, leaving its other tags untouched. A zero rating removes the tag.
+func WriteRatings(path string, rating, albumRating int) error {
+	if cue.IsTrackPath(path) {
+		return errCueTrackReadOnly
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("file not found: %w", err)
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if !isSupportedExt(ext) {
+		return fmt.Errorf("unsupported file format: %s", ext)
+	}
+	if ext == ExtMP3 {
+		return writeMP3Ratings(path, rating, albumRating)
+	}
+
+	t := &Tag{Rating: rating, AlbumRating: albumRating}
+	mp4 := ext == ExtM4A || ext == ExtM4B || ext == ExtMP4
+
+	// Empty values remove the tags of unset ratings
+	props := map[string][]string{keyAlbumRating: nil}
+	if mp4 {
+		props[keyMP4Rating] = nil
+	} else {
+		props[keyRating] = nil
+		props[keyFMPSRating] = nil
+	}
+	for _, v := range ratingTagValues(t, mp4) {
+		props[v.key] = []string{v.value}
+	}
+	if err := taglib.WriteTags(path, props, 0); err != nil {
+		return fmt.Errorf("write tags: %w", err)
+	}
+	return nil
+}
+
+// writeMP3Ratings replaces the POPM frame and album rating of an MP3 file.
+// POPM frames of other players are kept.
+func writeMP3Ratings(path string, rating, albumRating int) error {
+	tag, err := id3v2.Open(path, id3v2.Options{Parse: true})
+	if errors.Is(err, id3v2.ErrUnsupportedVersion) {
+		return errors.New("unsupported ID3v2 version")
+	}
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	defer tag.Close()
+
+	counter := big.NewInt(0)
+	popms := tag.GetFrames("POPM")
+	tag.DeleteFrames("POPM")
+	for _, frame := range popms {
+		popm, ok := frame.(id3v2.PopularimeterFrame)
+		if !ok {
+			continue
+		}
+		if popm.Email == popmEmail {
+			if popm.Counter != nil {
+				counter = popm.Counter
+			}
+			continue
+		}
+		tag.AddFrame("POPM", popm)
+	}
+	if rating > 0 {
+		tag.AddFrame("POPM", id3v2.PopularimeterFrame{
+			Email:   popmEmail,
+			Rating:  popmRatings[ClampRating(rating)],
+			Counter: counter,
+		})
+	}
+
+	txxx := tag.GetFrames("TXXX")
+	tag.DeleteFrames("TXXX")
+	for _, frame := range txxx {
+		if udtf, ok := frame.(id3v2.UserDefinedTextFrame); ok && strings.EqualFold(udtf.Description, keyAlbumRating) {
+			continue
+		}
+		tag.AddFrame("TXXX", frame)
+	}
+	if albumRating > 0 {
+		addTXXXFrame(tag, keyAlbumRating, formatRating(albumRating))
+	}
+
+	if err := tag.Save(); err != nil {
+		return fmt.Errorf("save tags: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package tags
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/bogem/id3v2/v2"
+)
+
+func TestRatingFromPOPM(t *testing.T) {
+	tests := []struct {
+		popm uint8
+		want int
+	}{
+		{0, 0},
+		{1, 2},
+		{13, 1},
+		{64, 4},
+		{128, 6},
+		{196, 8},
+		{255, 10},
+		{100, 5}, // closest to 118
+		{8, 1},   // closer to half a star than to one star
+	}
+
+	for _, tt := range tests {
+		if got := ratingFromPOPM(tt.popm); got != tt.want {
+			t.Errorf("ratingFromPOPM(%d) = %d, want %d", tt.popm, got, tt.want)
+		}
+	}
+
+	// Writing then reading a rating gives it back
+	for r := range MaxRating + 1 {
+		if got := ratingFromPOPM(popmRatings[r]); got != r {
+			t.Errorf("ratingFromPOPM(popmRatings[%d]) = %d", r, got)
+		}
+	}
+}
+
+func TestParseRating(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"80", 8},
+		{"100", 10},
+		{"55", 6},
+		{"3", 6}, // stars
+		{"4.5", 9},
+		{"0", 0},
+		{"", 0},
+		{"great", 0},
+		{"250", 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := parseRating(tt.input); got != tt.want {
+				t.Errorf("parseRating(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadRatings(t *testing.T) {
+	tests := []struct {
+		name      string
+		tags      map[string]string
+		wantTrack int
+		wantAlbum int
+	}{
+		{"none", nil, 0, 0},
+		{"percent", map[string]string{keyRating: "60", keyAlbumRating: "90"}, 6, 9},
+		{"fmps preferred", map[string]string{keyFMPSRating: "0.7", keyRating: "20"}, 7, 0},
+		{"mp4 rate", map[string]string{keyMP4Rating: "100"}, 10, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			track, album := readRatings(func(key string) string { return tt.tags[key] })
+			if track != tt.wantTrack || album != tt.wantAlbum {
+				t.Errorf("readRatings() = %d, %d, want %d, %d", track, album, tt.wantTrack, tt.wantAlbum)
+			}
+		})
+	}
+}
+
+func TestRatingTagValues(t *testing.T) {
+	got := ratingTagValues(&Tag{Rating: 7, AlbumRating: 10}, false)
+	want := []tagValue{
+		{keyRating, "70"},
+		{keyFMPSRating, "0.7"},
+		{keyAlbumRating, "100"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ratingTagValues() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ratingTagValues()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	mp4 := ratingTagValues(&Tag{Rating: 4}, true)
+	if len(mp4) != 1 || mp4[0] != (tagValue{keyMP4Rating, "40"}) {
+		t.Errorf("ratingTagValues(mp4) = %v", mp4)
+	}
+	if unrated := ratingTagValues(&Tag{}, false); len(unrated) != 0 {
+		t.Errorf("unrated tag should write no rating, got %v", unrated)
+	}
+}
+
+func TestWriteRatings_MP3_Roundtrip(t *testing.T) {
+	path := createTestMP3(t, t.TempDir(), &Tag{Title: "Song"})
+
+	if err := Write(path, &Tag{Title: "Song", Rating: 7, AlbumRating: 8}); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	result, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	assertEqual(t, "Rating", result.Rating, 7)
+	assertEqual(t, "AlbumRating", result.AlbumRating, 8)
+}
+
+func TestWriteRatings_MP3(t *testing.T) {
+	path := createTestMP3(t, t.TempDir(), &Tag{Title: "Song", Artist: "Artist"})
+
+	// Another player's rating is kept
+	tag, err := id3v2.Open(path, id3v2.Options{Parse: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag.AddFrame("POPM", id3v2.PopularimeterFrame{Email: "other@example.com", Rating: 1, Counter: big.NewInt(3)})
+	if err := tag.Save(); err != nil {
+		t.Fatal(err)
+	}
+	tag.Close()
+
+	if err := WriteRatings(path, 9, 6); err != nil {
+		t.Fatalf("WriteRatings() error: %v", err)
+	}
+	result, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	assertEqual(t, "Title", result.Title, "Song")
+	assertEqual(t, "Artist", result.Artist, "Artist")
+	assertEqual(t, "Rating", result.Rating, 9)
+	assertEqual(t, "AlbumRating", result.AlbumRating, 6)
+
+	// Clearing waves' rating falls back to the other player's
+	if err := WriteRatings(path, 0, 0); err != nil {
+		t.Fatalf("WriteRatings() error: %v", err)
+	}
+	result, err = Read(path)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	assertEqual(t, "Rating", result.Rating, 2)
+	assertEqual(t, "AlbumRating", result.AlbumRating, 0)
+}
+
+func TestWriteRatings_FLAC(t *testing.T) {
+	path := createTestFLAC(t, t.TempDir(), &Tag{Title: "Song"})
+
+	if err := WriteRatings(path, 5, 10); err != nil {
+		t.Fatalf("WriteRatings() error: %v", err)
+	}
+	result, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	assertEqual(t, "Title", result.Title, "Song")
+	assertEqual(t, "Rating", result.Rating, 5)
+	assertEqual(t, "AlbumRating", result.AlbumRating, 10)
+}
+
+func TestWriteRatings_CueTrack(t *testing.T) {
+	if err := WriteRatings("/music/album.cue#2", 6, 0); err == nil {
+		t.Error("WriteRatings() on a CUE track should fail")
+	}
+}
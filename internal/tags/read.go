@@ -7,11 +7,17 @@ import (
 	"strings"
 
 	"github.com/dhowden/tag"
+
+	"github.com/llehouerou/waves/internal/cue"
 )
 
 // Read reads tag metadata from a music file.
 // It returns only tag metadata, not audio stream properties.
 func Read(path string) (*Tag, error) {
+	if cue.IsTrackPath(path) {
+		return readCueTrack(path)
+	}
+
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
@@ -25,7 +31,7 @@ func Read(path string) (*Tag, error) {
 		case ExtMP3:
 			// dhowden/tag has issues with some UTF-16 encoded ID3 tags
 			return readMP3WithID3v2Fallback(path)
-		case ExtM4A, ExtMP4:
+		case ExtM4A, ExtM4B, ExtMP4:
 			// dhowden/tag can't parse some M4A files (e.g., ffmpeg-created)
 			return readM4AWithTaglib(path)
 		case ExtFLAC:
@@ -34,6 +40,9 @@ func Read(path string) (*Tag, error) {
 		case ExtOPUS, ExtOGG, ExtOGA:
 			// dhowden/tag can fail on some Ogg files
 			return readOggWithTaglib(path)
+		case ExtWAV, ExtWAVE, ExtAIF, ExtAIFF, ExtAIFC, ExtWV:
+			// dhowden/tag doesn't know RIFF, IFF and WavPack containers
+			return readLosslessWithTaglib(path)
 		}
 		return nil, err
 	}
@@ -74,8 +83,10 @@ func Read(path string) (*Tag, error) {
 		readFLACExtendedTags(path, t)
 	case ExtOPUS, ExtOGG, ExtOGA:
 		readOggExtendedTags(path, t)
-	case ExtM4A, ExtMP4:
+	case ExtM4A, ExtM4B, ExtMP4:
 		readM4AExtendedTags(path, t)
+	case ExtWAV, ExtWAVE, ExtAIF, ExtAIFF, ExtAIFC, ExtWV:
+		readLosslessExtendedTags(path, t)
 	}
 
 	t.Sanitize()
@@ -98,9 +109,13 @@ func ReadWithAudio(path string) (*FileInfo, error) {
 		return nil, err
 	}
 
+	// Chapters are optional: a broken chapter list doesn't hide the file.
+	chapters, _ := ReadChapters(path, audio.Duration)
+
 	return &FileInfo{
 		Tag:       *t,
 		AudioInfo: *audio,
+		Chapters:  chapters,
 	}, nil
 }
 
@@ -0,0 +1,121 @@
+package tags
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/llehouerou/waves/internal/cue"
+)
+
+// errCueTrackReadOnly is returned when writing tags to a CUE sheet track.
+var errCueTrackReadOnly = errors.New("cannot write tags of a CUE sheet track, edit the sheet instead")
+
+// readCueTrack reads the metadata of a virtual CUE sheet track. The sheet
+// provides the track fields, the tags of the audio file fill in the
+// release-level ones (label, MusicBrainz release, ...).
+func readCueTrack(path string) (*Tag, error) {
+	ref, err := cue.Lookup(path)
+	if err != nil {
+		return nil, err
+	}
+	return cueTrackTag(path, ref), nil
+}
+
+// cueTrackTag builds the tag of a resolved CUE sheet track.
+func cueTrackTag(path string, ref *cue.Ref) *Tag {
+	t := &Tag{}
+	if fileTag, err := Read(ref.AudioPath); err == nil {
+		*t = *fileTag
+	}
+	sheet, track := ref.Sheet, ref.Track
+
+	t.Path = path
+	t.Title = track.Title
+	if t.Title == "" {
+		t.Title = "Track " + strconv.Itoa(track.Number)
+	}
+	if sheet.Performer != "" {
+		t.AlbumArtist = sheet.Performer
+	}
+	t.Artist = firstNonEmpty(track.Performer, sheet.Performer, t.Artist)
+	if t.AlbumArtist == "" {
+		t.AlbumArtist = t.Artist
+	}
+	t.Album = firstNonEmpty(sheet.Title, t.Album)
+	t.Genre = firstNonEmpty(sheet.Rem["GENRE"], t.Genre)
+	t.Date = firstNonEmpty(sheet.Rem["DATE"], t.Date)
+	t.TrackNumber = track.Number
+	t.TotalTracks = sheet.TrackCount()
+	if n, err := strconv.Atoi(sheet.Rem["DISCNUMBER"]); err == nil {
+		t.DiscNumber = n
+	}
+	if n, err := strconv.Atoi(sheet.Rem["TOTALDISCS"]); err == nil {
+		t.TotalDiscs = n
+	}
+	t.Barcode = firstNonEmpty(sheet.Catalog, t.Barcode)
+
+	// Track-level IDs of the file describe the whole file, not this track
+	t.ISRC = track.ISRC
+	t.MBRecordingID = ""
+	t.MBTrackID = ""
+
+	t.ReplayGain = cueReplayGain(sheet, track, t.ReplayGain)
+
+	// The rating of the audio file is the rating of the whole album
+	if t.AlbumRating == 0 {
+		t.AlbumRating = t.Rating
+	}
+	t.Rating = 0
+
+	t.Sanitize()
+	return t
+}
+
+// cueReplayGain reads the gains of a CUE sheet track. The gains of the
+// audio file cover the whole album, so its track gain is an album gain.
+func cueReplayGain(sheet *cue.Sheet, track *cue.Track, file ReplayGain) ReplayGain {
+	rg := readReplayGain(func(key string) string {
+		if v, ok := track.Rem[key]; ok {
+			return v
+		}
+		return sheet.Rem[key]
+	})
+	if !rg.HasAlbumGain {
+		switch {
+		case file.HasAlbumGain:
+			rg.AlbumGain, rg.AlbumPeak, rg.HasAlbumGain = file.AlbumGain, file.AlbumPeak, true
+		case file.HasTrackGain:
+			rg.AlbumGain, rg.AlbumPeak, rg.HasAlbumGain = file.TrackGain, file.TrackPeak, true
+		}
+	}
+	return rg
+}
+
+// readCueAudioInfo reads the audio properties of a CUE sheet track: those
+// of its file, with the duration of the track.
+func readCueAudioInfo(path string) (*AudioInfo, error) {
+	ref, err := cue.Lookup(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := ReadAudioInfo(ref.AudioPath)
+	if err != nil {
+		return nil, err
+	}
+	end := info.Duration
+	if ref.Track.End > 0 {
+		end = min(ref.Track.End.Duration(), end)
+	}
+	info.Duration = max(end-ref.Track.Start().Duration(), 0)
+	return info, nil
+}
+
+// firstNonEmpty returns the first non-empty string.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
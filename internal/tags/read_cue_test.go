@@ -0,0 +1,128 @@
+package tags
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/llehouerou/waves/internal/cue"
+)
+
+// createTestCueAlbum creates a one second WAV album rip with file tags,
+// and a CUE sheet splitting it in two tracks at 0.4s.
+func createTestCueAlbum(t *testing.T, dir string) string {
+	t.Helper()
+	fileTags := fullTestTags()
+	fileTags.Title = "Whole Album"
+	fileTags.ReplayGain = ReplayGain{TrackGain: -8, TrackPeak: 0.9, HasTrackGain: true}
+	createTestWAV(t, dir, fileTags)
+
+	sheet := `REM GENRE Jazz
+REM DATE 1959
+PERFORMER "Sheet Artist"
+TITLE "Sheet Album"
+FILE "test.wav" WAVE
+  TRACK 01 AUDIO
+    TITLE "First"
+    INDEX 01 00:00:00
+  TRACK 02 AUDIO
+    TITLE "Second"
+    PERFORMER "Guest"
+    ISRC USRC17607839
+    REM REPLAYGAIN_TRACK_GAIN -5.50 dB
+    INDEX 01 00:00:30
+`
+	path := filepath.Join(dir, "test.cue")
+	if err := os.WriteFile(path, []byte(sheet), 0o600); err != nil {
+		t.Fatalf("create cue sheet: %v", err)
+	}
+	return path
+}
+
+func TestRead_CueTrack(t *testing.T) {
+	sheetPath := createTestCueAlbum(t, t.TempDir())
+	path := cue.TrackPath(sheetPath, 2)
+
+	result, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+
+	assertEqual(t, "Path", result.Path, path)
+	assertEqual(t, "Title", result.Title, "Second")
+	assertEqual(t, "Artist", result.Artist, "Guest")
+	assertEqual(t, "AlbumArtist", result.AlbumArtist, "Sheet Artist")
+	assertEqual(t, "Album", result.Album, "Sheet Album")
+	assertEqual(t, "Genre", result.Genre, "Jazz")
+	assertEqual(t, "Date", result.Date, "1959")
+	assertEqual(t, "TrackNumber", result.TrackNumber, 2)
+	assertEqual(t, "TotalTracks", result.TotalTracks, 2)
+	assertEqual(t, "ISRC", result.ISRC, "USRC17607839")
+
+	// Release-level tags come from the audio file, track-level ones don't
+	assertEqual(t, "Label", result.Label, "Test Label")
+	assertEqual(t, "MBReleaseID", result.MBReleaseID, "release-uuid-1234")
+	assertEqual(t, "MBRecordingID", result.MBRecordingID, "")
+
+	// The gain of the file is the gain of the whole album
+	want := ReplayGain{TrackGain: -5.5, HasTrackGain: true, AlbumGain: -8, AlbumPeak: 0.9, HasAlbumGain: true}
+	assertEqual(t, "ReplayGain", result.ReplayGain, want)
+}
+
+func TestRead_CueTrack_ArtistFallsBackToSheet(t *testing.T) {
+	sheetPath := createTestCueAlbum(t, t.TempDir())
+
+	result, err := Read(cue.TrackPath(sheetPath, 1))
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	assertEqual(t, "Title", result.Title, "First")
+	assertEqual(t, "Artist", result.Artist, "Sheet Artist")
+	assertEqual(t, "ISRC", result.ISRC, "")
+}
+
+func TestReadAudioInfo_CueTrack(t *testing.T) {
+	sheetPath := createTestCueAlbum(t, t.TempDir())
+
+	tests := []struct {
+		number int
+		want   time.Duration
+	}{
+		{1, 400 * time.Millisecond},
+		{2, 600 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		info, err := ReadAudioInfo(cue.TrackPath(sheetPath, tt.number))
+		if err != nil {
+			t.Fatalf("ReadAudioInfo() error: %v", err)
+		}
+		assertEqual(t, "Duration", info.Duration, tt.want)
+		assertEqual(t, "Format", info.Format, "WAV")
+	}
+}
+
+func TestWrite_CueTrackIsReadOnly(t *testing.T) {
+	sheetPath := createTestCueAlbum(t, t.TempDir())
+
+	err := Write(cue.TrackPath(sheetPath, 1), &Tag{Title: "New"})
+	if !errors.Is(err, errCueTrackReadOnly) {
+		t.Errorf("Write() error = %v, want %v", err, errCueTrackReadOnly)
+	}
+}
+
+func TestExtractEmbeddedArt_CueTrack(t *testing.T) {
+	dir := t.TempDir()
+	sheetPath := createTestCueAlbum(t, dir)
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x02\x00\x00\x00\x90wS\xde")
+	if err := Write(filepath.Join(dir, "test.wav"), &Tag{Title: "Album", CoverArt: png}); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+
+	data, _, err := ExtractEmbeddedArt(cue.TrackPath(sheetPath, 2))
+	if err != nil {
+		t.Fatalf("ExtractEmbeddedArt() error: %v", err)
+	}
+	assertEqual(t, "art size", len(data), len(png))
+}
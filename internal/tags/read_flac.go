@@ -97,6 +97,9 @@ func readFLACExtendedTags(path string, t *Tag) {
 	t.MBRecordingID = comments["MUSICBRAINZ_TRACKID"]
 	t.MBTrackID = comments["MUSICBRAINZ_RELEASETRACKID"]
 
+	t.ReplayGain = readReplayGain(func(key string) string { return comments[key] })
+	t.Rating, t.AlbumRating = readRatings(func(key string) string { return comments[key] })
+
 	// Track/disc totals (dhowden/tag may not return these)
 	if t.TotalTracks == 0 {
 		if n, err := strconv.Atoi(comments["TOTALTRACKS"]); err == nil {
@@ -0,0 +1,96 @@
+package tags
+
+import (
+	"path/filepath"
+
+	"go.senan.xyz/taglib"
+)
+
+// readLosslessWithTaglib reads the ID3v2 chunk of a WAV or AIFF file, or
+// the APEv2 tag of a WavPack file, using TagLib.
+func readLosslessWithTaglib(path string) (*Tag, error) {
+	rawTags, err := taglib.ReadTags(path)
+	if err != nil {
+		return nil, err
+	}
+	tags := taglibTags(rawTags)
+
+	title := tags.get(taglib.Title)
+	if title == "" {
+		title = filepath.Base(path)
+	}
+
+	artist := tags.get(taglib.Artist)
+	albumArtist := tags.get(taglib.AlbumArtist)
+	if albumArtist == "" {
+		albumArtist = artist
+	}
+
+	track, totalTracks := tags.parseNumberPair(taglib.TrackNumber)
+	disc, totalDiscs := tags.parseNumberPair(taglib.DiscNumber)
+
+	t := &Tag{
+		Path:        path,
+		Title:       title,
+		Artist:      artist,
+		AlbumArtist: albumArtist,
+		Album:       tags.get(taglib.Album),
+		Genre:       tags.get(taglib.Genre),
+		TrackNumber: track,
+		TotalTracks: totalTracks,
+		DiscNumber:  disc,
+		TotalDiscs:  totalDiscs,
+	}
+
+	// Read extended tags
+	readLosslessExtendedTags(path, t)
+
+	t.Sanitize()
+	return t, nil
+}
+
+// readLosslessExtendedTags reads extended tags from a WAV, AIFF or WavPack
+// file using TagLib, which maps ID3v2 frames and APEv2 items to the same
+// property names.
+func readLosslessExtendedTags(path string, t *Tag) {
+	rawTags, err := taglib.ReadTags(path)
+	if err != nil {
+		return
+	}
+	tags := taglibTags(rawTags)
+
+	t.Date = tags.get(taglib.Date)
+	t.OriginalDate = tags.get(taglib.OriginalDate)
+	if t.OriginalDate == "" {
+		t.OriginalDate = tags.get(originalYear)
+	}
+
+	t.ArtistSortName = tags.get(taglib.ArtistSort)
+	t.Label = tags.get(taglib.Label)
+	t.CatalogNumber = tags.get(taglib.CatalogNumber)
+	t.Barcode = tags.get(taglib.Barcode)
+	t.Media = tags.get(taglib.Media)
+	t.ReleaseStatus = tags.get(taglib.ReleaseStatus)
+	t.ReleaseType = tags.get(taglib.ReleaseType)
+	t.Script = tags.get(taglib.Script)
+	t.Country = tags.get(taglib.ReleaseCountry)
+	t.ISRC = tags.get(taglib.ISRC)
+
+	// MusicBrainz IDs
+	t.MBArtistID = tags.get(taglib.MusicBrainzArtistID)
+	t.MBReleaseID = tags.get(taglib.MusicBrainzAlbumID)
+	t.MBReleaseGroupID = tags.get(taglib.MusicBrainzReleaseGroupID)
+	t.MBRecordingID = tags.get(taglib.MusicBrainzTrackID) // Recording ID uses MUSICBRAINZ_TRACKID
+	t.MBTrackID = tags.get(taglib.MusicBrainzReleaseTrackID)
+
+	t.ReplayGain = readReplayGain(func(key string) string { return tags.get(key) })
+	t.Rating, t.AlbumRating = readRatings(func(key string) string { return tags.get(key) })
+
+	// Some taggers write totals as separate fields
+	if t.TotalTracks == 0 {
+		t.TotalTracks = tags.getInt(totalTracks)
+	}
+	if t.TotalDiscs == 0 {
+		t.TotalDiscs = tags.getInt(totalDiscs)
+	}
+}
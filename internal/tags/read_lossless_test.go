@@ -0,0 +1,224 @@
+package tags
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Test file creation helpers for WAV, AIFF and WavPack. These formats are
+// simple enough to build in memory, so no ffmpeg is needed.
+
+// lePCMSilence returns one second of 16-bit stereo silence at 44.1kHz.
+func lePCMSilence() []byte {
+	return make([]byte, 44100*4)
+}
+
+func riffChunk(order binary.ByteOrder, id string, data []byte) []byte {
+	out := make([]byte, 8, 8+len(data)+1)
+	copy(out, id)
+	order.PutUint32(out[4:], uint32(len(data))) //nolint:gosec // test data is small
+	out = append(out, data...)
+	if len(data)&1 != 0 {
+		out = append(out, 0)
+	}
+	return out
+}
+
+// createTestWAV creates a one second 16-bit stereo WAV file.
+func createTestWAV(t *testing.T, dir string, tags *Tag) string {
+	t.Helper()
+	path := filepath.Join(dir, "test.wav")
+
+	fmtChunk := make([]byte, 16)
+	binary.LittleEndian.PutUint16(fmtChunk[0:], 1) // PCM
+	binary.LittleEndian.PutUint16(fmtChunk[2:], 2)
+	binary.LittleEndian.PutUint32(fmtChunk[4:], 44100)
+	binary.LittleEndian.PutUint32(fmtChunk[8:], 44100*4)
+	binary.LittleEndian.PutUint16(fmtChunk[12:], 4)
+	binary.LittleEndian.PutUint16(fmtChunk[14:], 16)
+
+	body := []byte("WAVE")
+	body = append(body, riffChunk(binary.LittleEndian, "fmt ", fmtChunk)...)
+	body = append(body, riffChunk(binary.LittleEndian, "data", lePCMSilence())...)
+	writeTestFile(t, path, riffChunk(binary.LittleEndian, "RIFF", body), tags)
+	return path
+}
+
+// createTestAIFF creates a one second 24-bit stereo AIFF file.
+func createTestAIFF(t *testing.T, dir string, tags *Tag) string {
+	t.Helper()
+	path := filepath.Join(dir, "test.aiff")
+
+	comm := make([]byte, 8, 18)
+	binary.BigEndian.PutUint16(comm[0:], 2)
+	binary.BigEndian.PutUint32(comm[2:], 44100)
+	binary.BigEndian.PutUint16(comm[6:], 24)
+	comm = append(comm, 0x40, 0x0E, 0xAC, 0x44, 0, 0, 0, 0, 0, 0) // 44100
+
+	ssnd := make([]byte, 8+44100*6)
+	body := []byte("AIFF")
+	body = append(body, riffChunk(binary.BigEndian, "COMM", comm)...)
+	body = append(body, riffChunk(binary.BigEndian, "SSND", ssnd)...)
+	writeTestFile(t, path, riffChunk(binary.BigEndian, "FORM", body), tags)
+	return path
+}
+
+// createTestWavPack creates a one second 16-bit stereo WavPack file made of
+// a single block of silence.
+func createTestWavPack(t *testing.T, dir string, tags *Tag) string {
+	t.Helper()
+	path := filepath.Join(dir, "test.wv")
+
+	const samples = 44100
+	// Flags: 16-bit, initial and final block, 44.1kHz
+	flags := uint32(1) | 0x800 | 0x1000 | 9<<23
+	// An empty decorrelation terms sub-block and entropy variables
+	subBlocks := []byte{0x02, 0x00, 0x05, 0x03, 0, 0, 0, 0, 0, 0}
+	header := make([]byte, 32)
+	copy(header, "wvpk")
+	binary.LittleEndian.PutUint32(header[4:], uint32(24+len(subBlocks)))
+	binary.LittleEndian.PutUint16(header[8:], 0x410)
+	binary.LittleEndian.PutUint32(header[12:], samples)
+	binary.LittleEndian.PutUint32(header[20:], samples)
+	binary.LittleEndian.PutUint32(header[24:], flags)
+	writeTestFile(t, path, append(header, subBlocks...), tags)
+	return path
+}
+
+func writeTestFile(t *testing.T, path string, data []byte, tags *Tag) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("create %s: %v", filepath.Base(path), err)
+	}
+	if tags != nil {
+		if err := Write(path, tags); err != nil {
+			t.Fatalf("write tags to %s: %v", filepath.Base(path), err)
+		}
+	}
+}
+
+var losslessTestFiles = []struct {
+	name   string
+	create func(t *testing.T, dir string, tags *Tag) string
+	info   AudioInfo
+}{
+	{"WAV", createTestWAV, AudioInfo{Duration: time.Second, Format: "WAV", SampleRate: 44100, BitDepth: 16}},
+	{"AIFF", createTestAIFF, AudioInfo{Duration: time.Second, Format: "AIFF", SampleRate: 44100, BitDepth: 24}},
+	{"WavPack", createTestWavPack, AudioInfo{Duration: time.Second, Format: "WAVPACK", SampleRate: 44100, BitDepth: 16}},
+}
+
+func TestWrite_Lossless_Roundtrip(t *testing.T) {
+	for _, tt := range losslessTestFiles {
+		t.Run(tt.name, func(t *testing.T) {
+			path := tt.create(t, t.TempDir(), nil)
+
+			original := fullTestTags()
+			original.ReplayGain = ReplayGain{TrackGain: -7.5, TrackPeak: 0.98, HasTrackGain: true}
+			if err := Write(path, original); err != nil {
+				t.Fatalf("Write() error: %v", err)
+			}
+
+			result, err := Read(path)
+			if err != nil {
+				t.Fatalf("Read() error: %v", err)
+			}
+
+			verifyTagsMatch(t, result, original)
+			assertEqual(t, "ReplayGain", result.ReplayGain, original.ReplayGain)
+		})
+	}
+}
+
+func TestWrite_Lossless_ClearsPreviousTags(t *testing.T) {
+	for _, tt := range losslessTestFiles {
+		t.Run(tt.name, func(t *testing.T) {
+			path := tt.create(t, t.TempDir(), fullTestTags())
+
+			if err := Write(path, &Tag{Title: "New Title"}); err != nil {
+				t.Fatalf("Write() error: %v", err)
+			}
+
+			result, err := Read(path)
+			if err != nil {
+				t.Fatalf("Read() error: %v", err)
+			}
+			assertEqual(t, "Title", result.Title, "New Title")
+			assertEqual(t, "Album", result.Album, "")
+			assertEqual(t, "TrackNumber", result.TrackNumber, 0)
+			assertEqual(t, "MBReleaseID", result.MBReleaseID, "")
+		})
+	}
+}
+
+func TestRead_Lossless_TitleFallbackToFilename(t *testing.T) {
+	for _, tt := range losslessTestFiles {
+		t.Run(tt.name, func(t *testing.T) {
+			path := tt.create(t, t.TempDir(), nil)
+
+			result, err := Read(path)
+			if err != nil {
+				t.Fatalf("Read() error: %v", err)
+			}
+			assertEqual(t, "Title", result.Title, filepath.Base(path))
+		})
+	}
+}
+
+func TestReadAudioInfo_Lossless(t *testing.T) {
+	for _, tt := range losslessTestFiles {
+		t.Run(tt.name, func(t *testing.T) {
+			// Tags must not get in the way of the header parsing
+			path := tt.create(t, t.TempDir(), fullTestTags())
+
+			info, err := ReadAudioInfo(path)
+			if err != nil {
+				t.Fatalf("ReadAudioInfo() error: %v", err)
+			}
+			assertEqual(t, "AudioInfo", *info, tt.info)
+		})
+	}
+}
+
+func TestExtractEmbeddedArt_Lossless(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x02\x00\x00\x00\x90wS\xde")
+	for _, tt := range losslessTestFiles {
+		t.Run(tt.name, func(t *testing.T) {
+			path := tt.create(t, t.TempDir(), &Tag{Title: "With Art", CoverArt: png})
+
+			data, mimeType, err := ExtractEmbeddedArt(path)
+			if err != nil {
+				t.Fatalf("ExtractEmbeddedArt() error: %v", err)
+			}
+			if !bytes.Equal(data, png) {
+				t.Errorf("art = %d bytes, want %d", len(data), len(png))
+			}
+			assertEqual(t, "mimeType", mimeType, mimePNG)
+		})
+	}
+}
+
+func TestReadAudioInfo_WavPackUnknownLength(t *testing.T) {
+	path := createTestWavPack(t, t.TempDir(), nil)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A second block, and no total in the first header
+	block := append([]byte(nil), data...)
+	binary.LittleEndian.PutUint32(block[16:], 44100) // Block index
+	data = append(data, block...)
+	binary.LittleEndian.PutUint32(data[12:], 0xFFFFFFFF)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := ReadAudioInfo(path)
+	if err != nil {
+		t.Fatalf("ReadAudioInfo() error: %v", err)
+	}
+	assertEqual(t, "Duration", info.Duration, 2*time.Second)
+}
@@ -2,6 +2,7 @@ package tags
 
 import (
 	"path/filepath"
+	"strings"
 
 	"go.senan.xyz/taglib"
 )
@@ -111,4 +112,12 @@ func readM4AExtendedTags(path string, t *Tag) {
 		"MUSICBRAINZ RELEASE TRACK ID",
 		"MusicBrainz Release Track Id",
 	)
+
+	// ReplayGain values are stored as iTunes freeform atoms, usually lowercase
+	t.ReplayGain = readReplayGain(func(key string) string {
+		return tags.get(key, strings.ToLower(key))
+	})
+	t.Rating, t.AlbumRating = readRatings(func(key string) string {
+		return tags.get(key, strings.ToLower(key))
+	})
 }
@@ -67,6 +67,19 @@ func readMP3ExtendedTags(path string, t *Tag) {
 	t.Script = getID3TXXXFrame(id3tag, "SCRIPT")
 	t.Country = getID3TXXXFrame(id3tag, "MusicBrainz Album Release Country")
 
+	// ReplayGain values (taggers differ in description case)
+	t.ReplayGain = readReplayGain(func(key string) string {
+		return getID3TXXXFrameFold(id3tag, key)
+	})
+
+	// Ratings: POPM, with TXXX fallback for taggers that write Vorbis-style keys
+	t.Rating, t.AlbumRating = readRatings(func(key string) string {
+		return getID3TXXXFrameFold(id3tag, key)
+	})
+	if r := readPOPMRating(id3tag); r > 0 {
+		t.Rating = r
+	}
+
 	// Read UFID frame for MusicBrainz Recording ID
 	if frames := id3tag.GetFrames("UFID"); len(frames) > 0 {
 		for _, frame := range frames {
@@ -168,3 +181,16 @@ func getID3TXXXFrame(id3tag *id3v2.Tag, description string) string {
 	}
 	return ""
 }
+
+// getID3TXXXFrameFold reads a TXXX frame value, matching the description case-insensitively.
+func getID3TXXXFrameFold(id3tag *id3v2.Tag, description string) string {
+	frames := id3tag.GetFrames("TXXX")
+	for _, frame := range frames {
+		if txxx, ok := frame.(id3v2.UserDefinedTextFrame); ok {
+			if strings.EqualFold(txxx.Description, description) {
+				return txxx.Value
+			}
+		}
+	}
+	return ""
+}
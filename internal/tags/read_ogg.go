@@ -78,6 +78,10 @@ func readOggExtendedTags(path string, t *Tag) {
 	t.MBRecordingID = tags.get(taglib.MusicBrainzTrackID) // Recording ID uses MUSICBRAINZ_TRACKID
 	t.MBTrackID = tags.get(taglib.MusicBrainzReleaseTrackID)
 
+	// ReplayGain values, with R128_* fallback for Opus
+	t.ReplayGain = readReplayGain(func(key string) string { return tags.get(key) })
+	t.Rating, t.AlbumRating = readRatings(func(key string) string { return tags.get(key) })
+
 	// Track/disc totals (dhowden/tag may not return these)
 	if t.TotalTracks == 0 {
 		t.TotalTracks = tags.getInt("TOTALTRACKS")
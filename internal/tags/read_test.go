@@ -650,8 +650,8 @@ func TestWrite_NonexistentFile(t *testing.T) {
 
 func TestWrite_UnsupportedFormat(t *testing.T) {
 	dir := t.TempDir()
-	path := filepath.Join(dir, "test.wav")
-	if err := os.WriteFile(path, []byte("RIFF"), 0o600); err != nil {
+	path := filepath.Join(dir, "test.wma")
+	if err := os.WriteFile(path, []byte("0&\xb2u"), 0o600); err != nil {
 		t.Fatalf("create file: %v", err)
 	}
 
@@ -856,8 +856,8 @@ func TestReadAudioInfo_M4A(t *testing.T) {
 
 func TestReadAudioInfo_UnsupportedFormat(t *testing.T) {
 	dir := t.TempDir()
-	path := filepath.Join(dir, "test.wav")
-	if err := os.WriteFile(path, []byte("RIFF"), 0o600); err != nil {
+	path := filepath.Join(dir, "test.wma")
+	if err := os.WriteFile(path, []byte("0&\xb2u"), 0o600); err != nil {
 		t.Fatalf("create file: %v", err)
 	}
 
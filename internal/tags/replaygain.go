@@ -0,0 +1,257 @@
+package tags
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bogem/id3v2/v2"
+	"go.senan.xyz/taglib"
+
+	"github.com/llehouerou/waves/internal/cue"
+)
+
+// ReplayGain tag keys (Vorbis comments, TXXX descriptions and MP4 freeform atoms).
+const (
+	keyRGTrackGain = "REPLAYGAIN_TRACK_GAIN"
+	keyRGTrackPeak = "REPLAYGAIN_TRACK_PEAK"
+	keyRGAlbumGain = "REPLAYGAIN_ALBUM_GAIN"
+	keyRGAlbumPeak = "REPLAYGAIN_ALBUM_PEAK"
+	keyR128Track   = "R128_TRACK_GAIN"
+	keyR128Album   = "R128_ALBUM_GAIN"
+)
+
+// r128ReferenceOffset is the difference in dB between the ReplayGain 2.0
+// reference level (-18 LUFS) and the EBU R128 reference level (-23 LUFS)
+// used by Opus R128_* tags.
+const r128ReferenceOffset = 5.0
+
+// ReplayGain holds loudness normalization values read from tags.
+// Gains are in dB relative to the ReplayGain 2.0 reference (-18 LUFS),
+// peaks are linear sample values (1.0 = full scale).
+type ReplayGain struct {
+	TrackGain    float64
+	TrackPeak    float64
+	AlbumGain    float64
+	AlbumPeak    float64
+	HasTrackGain bool
+	HasAlbumGain bool
+}
+
+// IsEmpty returns true if no gain value is present.
+func (rg ReplayGain) IsEmpty() bool {
+	return !rg.HasTrackGain && !rg.HasAlbumGain
+}
+
+// readReplayGain fills ReplayGain values using a key lookup function.
+// R128_* values (Opus) are only used when no REPLAYGAIN_* value is present.
+func readReplayGain(get func(key string) string) ReplayGain {
+	var rg ReplayGain
+
+	if g, ok := parseGain(get(keyRGTrackGain)); ok {
+		rg.TrackGain, rg.HasTrackGain = g, true
+	} else if g, ok := parseR128Gain(get(keyR128Track)); ok {
+		rg.TrackGain, rg.HasTrackGain = g, true
+	}
+	if g, ok := parseGain(get(keyRGAlbumGain)); ok {
+		rg.AlbumGain, rg.HasAlbumGain = g, true
+	} else if g, ok := parseR128Gain(get(keyR128Album)); ok {
+		rg.AlbumGain, rg.HasAlbumGain = g, true
+	}
+
+	rg.TrackPeak = parsePeak(get(keyRGTrackPeak))
+	rg.AlbumPeak = parsePeak(get(keyRGAlbumPeak))
+	return rg
+}
+
+// parseGain parses a ReplayGain value like "-6.54 dB" or "+1.2dB".
+func parseGain(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	lower := strings.ToLower(s)
+	lower = strings.TrimSuffix(lower, "db")
+	lower = strings.TrimSpace(lower)
+	g, err := strconv.ParseFloat(lower, 64)
+	if err != nil || math.IsNaN(g) || math.IsInf(g, 0) {
+		return 0, false
+	}
+	return g, true
+}
+
+// parsePeak parses a linear peak value like "0.988525". Returns 0 if invalid.
+func parsePeak(s string) float64 {
+	p, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || p < 0 || math.IsNaN(p) || math.IsInf(p, 0) {
+		return 0
+	}
+	return p
+}
+
+// parseR128Gain parses an Opus R128 gain (Q7.8 fixed point, relative to
+// -23 LUFS) and converts it to a ReplayGain 2.0 gain in dB.
+func parseR128Gain(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	q, err := strconv.Atoi(s)
+	if err != nil || q < math.MinInt16 || q > math.MaxInt16 {
+		return 0, false
+	}
+	return float64(q)/256 + r128ReferenceOffset, true
+}
+
+// tagValue is a single tag key/value pair.
+type tagValue struct {
+	key   string
+	value string
+}
+
+// tagValues returns the tags to write for the gain values present.
+// Opus files get R128_* gains (RFC 7845) instead of REPLAYGAIN_* tags;
+// R128 has no peak values.
+func (rg ReplayGain) tagValues(opus bool) []tagValue {
+	var out []tagValue
+	if opus {
+		if rg.HasTrackGain {
+			out = append(out, tagValue{keyR128Track, formatR128Gain(rg.TrackGain)})
+		}
+		if rg.HasAlbumGain {
+			out = append(out, tagValue{keyR128Album, formatR128Gain(rg.AlbumGain)})
+		}
+		return out
+	}
+
+	if rg.HasTrackGain {
+		out = append(out, tagValue{keyRGTrackGain, formatGain(rg.TrackGain)})
+		if rg.TrackPeak > 0 {
+			out = append(out, tagValue{keyRGTrackPeak, formatPeak(rg.TrackPeak)})
+		}
+	}
+	if rg.HasAlbumGain {
+		out = append(out, tagValue{keyRGAlbumGain, formatGain(rg.AlbumGain)})
+		if rg.AlbumPeak > 0 {
+			out = append(out, tagValue{keyRGAlbumPeak, formatPeak(rg.AlbumPeak)})
+		}
+	}
+	return out
+}
+
+// replayGainKeys are the gain tags WriteReplayGain replaces.
+var replayGainKeys = []string{
+	keyRGTrackGain, keyRGTrackPeak, keyRGAlbumGain, keyRGAlbumPeak, keyR128Track, keyR128Album,
+}
+
+// isReplayGainKey returns true if key is one of the gain tags, whatever its
+// case.
+func isReplayGainKey(key string) bool {
+	for _, k := range replayGainKeys {
+		if strings.EqualFold(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteReplayGain replaces the gain tags of a music file with rg, leaving
+// its other tags untouched. Gains rg doesn't have are removed.
+func WriteReplayGain(path string, rg ReplayGain) error {
+	if cue.IsTrackPath(path) {
+		return errCueTrackReadOnly
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("file not found: %w", err)
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if !isSupportedExt(ext) {
+		return fmt.Errorf("unsupported file format: %s", ext)
+	}
+	if ext == ExtMP3 {
+		return writeMP3ReplayGain(path, rg)
+	}
+
+	// Empty values remove the tags of gains not set
+	props := make(map[string][]string, len(replayGainKeys))
+	for _, k := range replayGainKeys {
+		props[k] = nil
+	}
+	for _, v := range rg.tagValues(isOpusFile(path)) {
+		props[v.key] = []string{v.value}
+	}
+	if err := taglib.WriteTags(path, props, 0); err != nil {
+		return fmt.Errorf("write tags: %w", err)
+	}
+	return nil
+}
+
+// writeMP3ReplayGain replaces the gain TXXX frames of an MP3 file.
+func writeMP3ReplayGain(path string, rg ReplayGain) error {
+	tag, err := id3v2.Open(path, id3v2.Options{Parse: true})
+	if errors.Is(err, id3v2.ErrUnsupportedVersion) {
+		return errors.New("unsupported ID3v2 version")
+	}
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	defer tag.Close()
+
+	txxx := tag.GetFrames("TXXX")
+	tag.DeleteFrames("TXXX")
+	for _, frame := range txxx {
+		if udtf, ok := frame.(id3v2.UserDefinedTextFrame); ok && isReplayGainKey(udtf.Description) {
+			continue
+		}
+		tag.AddFrame("TXXX", frame)
+	}
+	for _, g := range rg.tagValues(false) {
+		addTXXXFrame(tag, g.key, g.value)
+	}
+
+	if err := tag.Save(); err != nil {
+		return fmt.Errorf("save tags: %w", err)
+	}
+	return nil
+}
+
+// formatGain formats a gain the way most taggers do, e.g. "-6.54 dB".
+func formatGain(g float64) string {
+	return fmt.Sprintf("%.2f dB", g)
+}
+
+// formatPeak formats a linear peak value, e.g. "0.988525".
+func formatPeak(p float64) string {
+	return strconv.FormatFloat(p, 'f', 6, 64)
+}
+
+// formatR128Gain converts a ReplayGain 2.0 gain in dB to an Opus R128 gain
+// (Q7.8 fixed point, relative to -23 LUFS).
+func formatR128Gain(g float64) string {
+	q := math.Round((g - r128ReferenceOffset) * 256)
+	q = max(min(q, math.MaxInt16), math.MinInt16)
+	return strconv.Itoa(int(q))
+}
+
+// isOpusFile returns true if path is an Ogg file containing an Opus stream.
+func isOpusFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ExtOPUS {
+		return true
+	}
+	if ext != ExtOGG && ext != ExtOGA {
+		return false
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	format, _, err := detectOggCodecInfo(f)
+	return err == nil && format == "OPUS"
+}
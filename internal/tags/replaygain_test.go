@@ -0,0 +1,398 @@
+package tags
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/bogem/id3v2/v2"
+	"go.senan.xyz/taglib"
+)
+
+func TestParseGain(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   float64
+		wantOK bool
+	}{
+		{"-6.54 dB", -6.54, true},
+		{"+1.20 dB", 1.2, true},
+		{"3.5dB", 3.5, true},
+		{"-2.00 DB", -2, true},
+		{"0", 0, true},
+		{"", 0, false},
+		{"loud", 0, false},
+		{"NaN dB", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := parseGain(tt.input)
+			if ok != tt.wantOK {
+				t.Fatalf("parseGain(%q) ok = %v, want %v", tt.input, ok, tt.wantOK)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("parseGain(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePeak(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+	}{
+		{"0.988525", 0.988525},
+		{" 1.2 ", 1.2},
+		{"", 0},
+		{"-0.5", 0},
+		{"abc", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := parsePeak(tt.input); got != tt.want {
+				t.Errorf("parsePeak(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseR128Gain(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   float64
+		wantOK bool
+	}{
+		{"0", 5, true},        // -23 LUFS reference is 5 dB below ReplayGain's
+		{"-1280", 0, true},    // -5 dB in Q7.8
+		{"-2560", -5, true},   // -10 dB in Q7.8
+		{"256", 6, true},      // +1 dB in Q7.8
+		{"40000", 0, false},   // out of int16 range
+		{"-1.5 dB", 0, false}, // not Q7.8
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := parseR128Gain(tt.input)
+			if ok != tt.wantOK {
+				t.Fatalf("parseR128Gain(%q) ok = %v, want %v", tt.input, ok, tt.wantOK)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("parseR128Gain(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadReplayGain(t *testing.T) {
+	t.Run("replaygain tags", func(t *testing.T) {
+		values := map[string]string{
+			keyRGTrackGain: "-7.10 dB",
+			keyRGTrackPeak: "0.999969",
+			keyRGAlbumGain: "-6.50 dB",
+			keyRGAlbumPeak: "1.000000",
+		}
+		rg := readReplayGain(func(key string) string { return values[key] })
+
+		if !rg.HasTrackGain || rg.TrackGain != -7.1 {
+			t.Errorf("track gain = %v (has=%v), want -7.1", rg.TrackGain, rg.HasTrackGain)
+		}
+		if !rg.HasAlbumGain || rg.AlbumGain != -6.5 {
+			t.Errorf("album gain = %v (has=%v), want -6.5", rg.AlbumGain, rg.HasAlbumGain)
+		}
+		if rg.TrackPeak != 0.999969 || rg.AlbumPeak != 1 {
+			t.Errorf("peaks = %v/%v, want 0.999969/1", rg.TrackPeak, rg.AlbumPeak)
+		}
+	})
+
+	t.Run("r128 fallback", func(t *testing.T) {
+		values := map[string]string{
+			keyR128Track: "-2560",
+			keyR128Album: "-1280",
+		}
+		rg := readReplayGain(func(key string) string { return values[key] })
+
+		if !rg.HasTrackGain || rg.TrackGain != -5 {
+			t.Errorf("track gain = %v, want -5", rg.TrackGain)
+		}
+		if !rg.HasAlbumGain || rg.AlbumGain != 0 {
+			t.Errorf("album gain = %v, want 0", rg.AlbumGain)
+		}
+	})
+
+	t.Run("replaygain wins over r128", func(t *testing.T) {
+		values := map[string]string{
+			keyRGTrackGain: "-3.00 dB",
+			keyR128Track:   "-2560",
+		}
+		rg := readReplayGain(func(key string) string { return values[key] })
+
+		if rg.TrackGain != -3 {
+			t.Errorf("track gain = %v, want -3", rg.TrackGain)
+		}
+		if rg.HasAlbumGain {
+			t.Error("album gain should be absent")
+		}
+	})
+
+	t.Run("no tags", func(t *testing.T) {
+		rg := readReplayGain(func(string) string { return "" })
+		if !rg.IsEmpty() {
+			t.Errorf("expected empty ReplayGain, got %+v", rg)
+		}
+	})
+}
+
+func TestFormatR128Gain(t *testing.T) {
+	tests := []struct {
+		gain float64
+		want string
+	}{
+		{5, "0"},
+		{0, "-1280"},
+		{-5, "-2560"},
+		{6, "256"},
+		{-200, "-32768"}, // clamped to int16
+	}
+
+	for _, tt := range tests {
+		if got := formatR128Gain(tt.gain); got != tt.want {
+			t.Errorf("formatR128Gain(%v) = %q, want %q", tt.gain, got, tt.want)
+		}
+	}
+}
+
+func testReplayGain() ReplayGain {
+	return ReplayGain{
+		TrackGain: -7.25, TrackPeak: 0.988525, HasTrackGain: true,
+		AlbumGain: -6.5, AlbumPeak: 1.012, HasAlbumGain: true,
+	}
+}
+
+func assertReplayGain(t *testing.T, got, want ReplayGain, peaks bool) {
+	t.Helper()
+	if !got.HasTrackGain || math.Abs(got.TrackGain-want.TrackGain) > 0.01 {
+		t.Errorf("track gain = %v (has=%v), want %v", got.TrackGain, got.HasTrackGain, want.TrackGain)
+	}
+	if !got.HasAlbumGain || math.Abs(got.AlbumGain-want.AlbumGain) > 0.01 {
+		t.Errorf("album gain = %v (has=%v), want %v", got.AlbumGain, got.HasAlbumGain, want.AlbumGain)
+	}
+	if peaks && (got.TrackPeak != want.TrackPeak || got.AlbumPeak != want.AlbumPeak) {
+		t.Errorf("peaks = %v/%v, want %v/%v", got.TrackPeak, got.AlbumPeak, want.TrackPeak, want.AlbumPeak)
+	}
+}
+
+func TestWriteReplayGain_MP3(t *testing.T) {
+	path := createTestMP3(t, t.TempDir(), &Tag{Title: "Song"})
+
+	want := testReplayGain()
+	if err := Write(path, &Tag{Title: "Song", ReplayGain: want}); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+
+	result, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	assertReplayGain(t, result.ReplayGain, want, true)
+}
+
+func TestWriteReplayGain_FLAC(t *testing.T) {
+	path := createTestFLAC(t, t.TempDir(), nil)
+
+	want := testReplayGain()
+	if err := Write(path, &Tag{Title: "Song", ReplayGain: want}); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+
+	result, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	assertReplayGain(t, result.ReplayGain, want, true)
+}
+
+func TestWriteReplayGain_Opus(t *testing.T) {
+	path := createTestOpus(t, t.TempDir(), nil)
+
+	want := testReplayGain()
+	if err := Write(path, &Tag{Title: "Song", ReplayGain: want}); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+
+	raw, err := taglib.ReadTags(path)
+	if err != nil {
+		t.Fatalf("ReadTags() error: %v", err)
+	}
+	if len(raw[keyRGTrackGain]) > 0 {
+		t.Errorf("Opus files should not get REPLAYGAIN_* tags, got %v", raw[keyRGTrackGain])
+	}
+
+	result, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	assertReplayGain(t, result.ReplayGain, want, false)
+}
+
+func TestWriteReplayGain_M4A(t *testing.T) {
+	path := createTestM4A(t)
+
+	want := testReplayGain()
+	if err := Write(path, &Tag{Title: "Song", ReplayGain: want}); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+
+	result, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	assertReplayGain(t, result.ReplayGain, want, true)
+}
+
+func TestWriteReplayGain_KeepsOtherMP3Frames(t *testing.T) {
+	path := createTestMP3(t, t.TempDir(), &Tag{Title: "Song", Artist: "Artist"})
+
+	// Frames Tag doesn't model, and a gain written by another tagger
+	tag, err := id3v2.Open(path, id3v2.Options{Parse: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag.AddCommentFrame(id3v2.CommentFrame{
+		Encoding: id3v2.EncodingUTF8, Language: "eng", Description: "", Text: "Live take",
+	})
+	tag.AddUnsynchronisedLyricsFrame(id3v2.UnsynchronisedLyricsFrame{
+		Encoding: id3v2.EncodingUTF8, Language: "eng", Lyrics: "la la la",
+	})
+	addTXXXFrame(tag, "MOOD", "Calm")
+	addTXXXFrame(tag, "replaygain_track_gain", "+3.00 dB")
+	if err := tag.Save(); err != nil {
+		t.Fatal(err)
+	}
+	tag.Close()
+
+	want := testReplayGain()
+	if err := WriteReplayGain(path, want); err != nil {
+		t.Fatalf("WriteReplayGain() error: %v", err)
+	}
+
+	result, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	assertEqual(t, "Title", result.Title, "Song")
+	assertEqual(t, "Artist", result.Artist, "Artist")
+	assertReplayGain(t, result.ReplayGain, want, true)
+
+	tag, err = id3v2.Open(path, id3v2.Options{Parse: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tag.Close()
+	if frames := tag.GetFrames(tag.CommonID("Comments")); len(frames) != 1 {
+		t.Errorf("%d comment frames, want the one kept", len(frames))
+	}
+	if frames := tag.GetFrames(tag.CommonID("Unsynchronised lyrics/text transcription")); len(frames) != 1 {
+		t.Errorf("%d lyrics frames, want the one kept", len(frames))
+	}
+	var mood string
+	gains := 0
+	for _, frame := range tag.GetFrames("TXXX") {
+		udtf := frame.(id3v2.UserDefinedTextFrame)
+		switch {
+		case udtf.Description == "MOOD":
+			mood = udtf.Value
+		case strings.EqualFold(udtf.Description, keyRGTrackGain):
+			gains++
+		}
+	}
+	assertEqual(t, "MOOD", mood, "Calm")
+	assertEqual(t, "track gain frames", gains, 1)
+}
+
+func TestWriteReplayGain_KeepsOtherFLACTags(t *testing.T) {
+	path := createTestFLAC(t, t.TempDir(), nil)
+	if err := Write(path, &Tag{Title: "Song", ReplayGain: testReplayGain()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := taglib.WriteTags(path, map[string][]string{"COMPOSER": {"Someone"}}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without an album gain, the album tags are removed
+	want := ReplayGain{TrackGain: -4, TrackPeak: 0.5, HasTrackGain: true}
+	if err := WriteReplayGain(path, want); err != nil {
+		t.Fatalf("WriteReplayGain() error: %v", err)
+	}
+
+	raw, err := taglib.ReadTags(path)
+	if err != nil {
+		t.Fatalf("ReadTags() error: %v", err)
+	}
+	assertTaglibTag(t, raw, "COMPOSER", "Someone")
+	assertTaglibTag(t, raw, "TITLE", "Song")
+	assertTaglibTag(t, raw, keyRGTrackGain, "-4.00 dB")
+	if len(raw[keyRGAlbumGain]) > 0 {
+		t.Errorf("album gain kept: %v", raw[keyRGAlbumGain])
+	}
+}
+
+func TestWriteReplayGain_OpusReplacesStaleTags(t *testing.T) {
+	path := createTestOpus(t, t.TempDir(), &Tag{Title: "Song"})
+	if err := taglib.WriteTags(path, map[string][]string{keyRGTrackGain: {"+3.00 dB"}}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	want := testReplayGain()
+	if err := WriteReplayGain(path, want); err != nil {
+		t.Fatalf("WriteReplayGain() error: %v", err)
+	}
+
+	raw, err := taglib.ReadTags(path)
+	if err != nil {
+		t.Fatalf("ReadTags() error: %v", err)
+	}
+	assertTaglibTag(t, raw, "TITLE", "Song")
+	if len(raw[keyRGTrackGain]) > 0 {
+		t.Errorf("stale REPLAYGAIN_* tag kept: %v", raw[keyRGTrackGain])
+	}
+	result, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	assertReplayGain(t, result.ReplayGain, want, false)
+}
+
+func TestWriteReplayGain_CueTrack(t *testing.T) {
+	if err := WriteReplayGain("/music/album.cue#2", testReplayGain()); err == nil {
+		t.Error("WriteReplayGain() on a CUE track should fail")
+	}
+}
+
+func TestWriteReplayGain_KeepsOtherWavPackTags(t *testing.T) {
+	path := createTestWavPack(t, t.TempDir(), &Tag{Title: "Song", Artist: "Artist"})
+	if err := taglib.WriteTags(path, map[string][]string{"COMPOSER": {"Someone"}}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	want := testReplayGain()
+	if err := WriteReplayGain(path, want); err != nil {
+		t.Fatalf("WriteReplayGain() error: %v", err)
+	}
+
+	raw, err := taglib.ReadTags(path)
+	if err != nil {
+		t.Fatalf("ReadTags() error: %v", err)
+	}
+	assertTaglibTag(t, raw, "COMPOSER", "Someone")
+	result, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	assertEqual(t, "Title", result.Title, "Song")
+	assertEqual(t, "Artist", result.Artist, "Artist")
+	assertReplayGain(t, result.ReplayGain, want, true)
+}
@@ -1,5 +1,6 @@
 // Package tags provides unified tag reading and writing for music files.
-// It consolidates metadata handling for MP3, FLAC, Opus, and M4A formats.
+// It consolidates metadata handling for MP3, FLAC, Opus, M4A, WAV, AIFF and
+// WavPack formats.
 package tags
 
 import (
@@ -18,7 +19,14 @@ const (
 	ExtOGG  = ".ogg"
 	ExtOGA  = ".oga" // Ogg Audio container (Vorbis/Opus)
 	ExtM4A  = ".m4a"
+	ExtM4B  = ".m4b" // Audiobook
 	ExtMP4  = ".mp4"
+	ExtWAV  = ".wav"
+	ExtWAVE = ".wave"
+	ExtAIF  = ".aif"
+	ExtAIFF = ".aiff"
+	ExtAIFC = ".aifc"
+	ExtWV   = ".wv" // WavPack
 )
 
 // id3Magic is the magic bytes for ID3v2 header detection.
@@ -67,6 +75,13 @@ type Tag struct {
 	MBRecordingID    string
 	MBTrackID        string
 
+	// Loudness normalization
+	ReplayGain ReplayGain
+
+	// Ratings in half stars, 0 (unrated) to MaxRating (five stars)
+	Rating      int
+	AlbumRating int
+
 	// Artwork (write-only, not populated during read)
 	CoverArt []byte
 }
@@ -162,6 +177,7 @@ type AudioInfo struct {
 type FileInfo struct {
 	Tag
 	AudioInfo
+	Chapters []Chapter // Audiobook and podcast chapters, nil if none
 }
 
 // IsMusicFile returns true if the path has a supported music file extension.
@@ -172,7 +188,28 @@ func IsMusicFile(path string) bool {
 	} else {
 		return false
 	}
-	return ext == ExtMP3 || ext == ExtFLAC || ext == ExtOPUS || ext == ExtOGG || ext == ExtOGA || ext == ExtM4A || ext == ExtMP4
+	return isSupportedExt(ext)
+}
+
+// isSupportedExt returns true if ext (lowercase, with the dot) is a format
+// the tags package can read.
+func isSupportedExt(ext string) bool {
+	switch ext {
+	case ExtMP3, ExtFLAC, ExtOPUS, ExtOGG, ExtOGA, ExtM4A, ExtM4B, ExtMP4,
+		ExtWAV, ExtWAVE, ExtAIF, ExtAIFF, ExtAIFC, ExtWV:
+		return true
+	}
+	return false
+}
+
+// isTaglibOnlyExt returns true for the formats dhowden/tag can't parse,
+// whose tags and artwork are only read through TagLib.
+func isTaglibOnlyExt(ext string) bool {
+	switch ext {
+	case ExtWAV, ExtWAVE, ExtAIF, ExtAIFF, ExtAIFC, ExtWV:
+		return true
+	}
+	return false
 }
 
 // taglibTags wraps a taglib result map with helper methods.
@@ -64,7 +64,13 @@ func TestIsMusicFile(t *testing.T) {
 		{"song.OGA", true},
 		{"song.m4a", true},
 		{"song.mp4", true},
-		{"song.wav", false},
+		{"song.wav", true},
+		{"song.WAVE", true},
+		{"song.aif", true},
+		{"song.aiff", true},
+		{"song.aifc", true},
+		{"song.wv", true},
+		{"song.wma", false},
 		{"song.txt", false},
 		{"song", false},
 		{"/path/to/music.flac", true},
@@ -6,11 +6,17 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/llehouerou/waves/internal/cue"
 )
 
 // Write writes tag metadata to a music file.
 // The file must already exist. This operation modifies the file in place.
 func Write(path string, t *Tag) error {
+	if cue.IsTrackPath(path) {
+		return errCueTrackReadOnly
+	}
+
 	// Check file exists
 	if _, err := os.Stat(path); err != nil {
 		return fmt.Errorf("file not found: %w", err)
@@ -18,7 +24,7 @@ func Write(path string, t *Tag) error {
 
 	// Detect file format from extension
 	ext := strings.ToLower(filepath.Ext(path))
-	if ext != ExtMP3 && ext != ExtFLAC && ext != ExtOPUS && ext != ExtOGG && ext != ExtOGA && ext != ExtM4A && ext != ExtMP4 {
+	if !isSupportedExt(ext) {
 		return fmt.Errorf("unsupported file format: %s", ext)
 	}
 
@@ -29,8 +35,10 @@ func Write(path string, t *Tag) error {
 		return writeFLACTags(path, t)
 	case ExtOPUS, ExtOGG, ExtOGA:
 		return writeOggTags(path, t)
-	case ExtM4A, ExtMP4:
+	case ExtM4A, ExtM4B, ExtMP4:
 		return writeM4ATags(path, t)
+	case ExtWAV, ExtWAVE, ExtAIF, ExtAIFF, ExtAIFC, ExtWV:
+		return writeLosslessTags(path, t)
 	}
 
 	return nil
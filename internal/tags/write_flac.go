@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/go-flac/flacpicture"
 	"github.com/go-flac/flacvorbis"
@@ -159,6 +160,20 @@ func writeFLACTags(path string, t *Tag) error {
 		return fmt.Errorf("add isrc: %w", err)
 	}
 
+	// Loudness normalization
+	for _, g := range t.ReplayGain.tagValues(false) {
+		if err := addTag(g.key, g.value); err != nil {
+			return fmt.Errorf("add %s: %w", strings.ToLower(g.key), err)
+		}
+	}
+
+	// Ratings
+	for _, r := range ratingTagValues(t, false) {
+		if err := addTag(r.key, r.value); err != nil {
+			return fmt.Errorf("add %s: %w", strings.ToLower(r.key), err)
+		}
+	}
+
 	// Marshal the comment block
 	cmtBlock := cmts.Marshal()
 
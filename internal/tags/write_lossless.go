@@ -0,0 +1,33 @@
+package tags
+
+import (
+	"strconv"
+
+	"go.senan.xyz/taglib"
+)
+
+// writeLosslessTags writes tags to a WAV or AIFF file (ID3v2 chunk) or a
+// WavPack file (APEv2) using TagLib.
+func writeLosslessTags(path string, t *Tag) error {
+	tags := taglibTagMap(path, t)
+
+	// ID3v2 and APEv2 store totals with the number, as "N/M"
+	delete(tags, totalTracks)
+	delete(tags, totalDiscs)
+	setNumberPair(tags, taglib.TrackNumber, t.TrackNumber, t.TotalTracks)
+	setNumberPair(tags, taglib.DiscNumber, t.DiscNumber, t.TotalDiscs)
+
+	return writeTaglibTags(path, tags, t.CoverArt)
+}
+
+// setNumberPair stores num as "N" or "N/M" if total is known.
+func setNumberPair(tags map[string][]string, key string, num, total int) {
+	if num <= 0 {
+		return
+	}
+	value := strconv.Itoa(num)
+	if total > 0 {
+		value += "/" + strconv.Itoa(total)
+	}
+	tags[key] = []string{value}
+}
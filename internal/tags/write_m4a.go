@@ -50,6 +50,16 @@ func writeM4ATags(path string, t *Tag) error {
 	// Recording info
 	addCustom("ISRC", t.ISRC)
 
+	// Loudness normalization
+	for _, g := range t.ReplayGain.tagValues(false) {
+		addCustom(g.key, g.value)
+	}
+
+	// Ratings
+	for _, r := range ratingTagValues(t, true) {
+		addCustom(r.key, r.value)
+	}
+
 	// Track/disc totals as custom atoms (redundant with standard fields below,
 	// but some players only read freeform atoms)
 	if t.TotalTracks > 0 {
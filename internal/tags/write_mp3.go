@@ -3,6 +3,7 @@ package tags
 import (
 	"errors"
 	"fmt"
+	"math/big"
 	"os"
 	"strconv"
 
@@ -115,6 +116,23 @@ func writeMP3Tags(path string, t *Tag) error {
 	addTXXXFrame(tag, "SCRIPT", t.Script)
 	addTXXXFrame(tag, "MusicBrainz Album Release Country", t.Country)
 
+	// Loudness normalization (foobar2000/Picard TXXX convention)
+	for _, g := range t.ReplayGain.tagValues(false) {
+		addTXXXFrame(tag, g.key, g.value)
+	}
+
+	// Ratings: the track rating goes to POPM, which most players read
+	if t.Rating > 0 {
+		tag.AddFrame("POPM", id3v2.PopularimeterFrame{
+			Email:   popmEmail,
+			Rating:  popmRatings[ClampRating(t.Rating)],
+			Counter: big.NewInt(0),
+		})
+	}
+	if t.AlbumRating > 0 {
+		addTXXXFrame(tag, keyAlbumRating, formatRating(t.AlbumRating))
+	}
+
 	// Add cover art if provided
 	if len(t.CoverArt) > 0 {
 		mimeType := detectMimeType(t.CoverArt)
@@ -16,6 +16,12 @@ const (
 
 // writeOggTags writes Vorbis comments to an Ogg file using TagLib.
 func writeOggTags(path string, t *Tag) error {
+	return writeTaglibTags(path, taglibTagMap(path, t), t.CoverArt)
+}
+
+// taglibTagMap converts t to TagLib properties. Track and disc totals are
+// stored separately, as Vorbis comments do.
+func taglibTagMap(path string, t *Tag) map[string][]string {
 	tags := make(map[string][]string)
 
 	// Helper to add tag if non-empty
@@ -76,14 +82,30 @@ func writeOggTags(path string, t *Tag) error {
 	// Recording info
 	addTag(taglib.ISRC, t.ISRC)
 
+	// Loudness normalization
+	for _, g := range t.ReplayGain.tagValues(isOpusFile(path)) {
+		addTag(g.key, g.value)
+	}
+
+	// Ratings
+	for _, r := range ratingTagValues(t, false) {
+		addTag(r.key, r.value)
+	}
+
+	return tags
+}
+
+// writeTaglibTags replaces all tags of the file with tags, and embeds
+// cover if not empty.
+func writeTaglibTags(path string, tags map[string][]string, cover []byte) error {
 	// Write tags (Clear removes any existing tags not in our map)
 	if err := taglib.WriteTags(path, tags, taglib.Clear); err != nil {
 		return fmt.Errorf("write tags: %w", err)
 	}
 
 	// Write cover art if provided
-	if len(t.CoverArt) > 0 {
-		if err := taglib.WriteImage(path, t.CoverArt); err != nil {
+	if len(cover) > 0 {
+		if err := taglib.WriteImage(path, cover); err != nil {
 			return fmt.Errorf("write cover art: %w", err)
 		}
 	}
@@ -1,6 +1,7 @@
 package albumview
 
 import (
+	"cmp"
 	"fmt"
 	"sort"
 	"strconv"
@@ -104,6 +105,14 @@ func (m *Model) compareByField(a, b library.AlbumEntry, field SortField) int {
 			strings.ToLower(a.Label),
 			strings.ToLower(b.Label),
 		)
+	case SortFieldPlayCount:
+		return cmp.Compare(a.PlayCount, b.PlayCount)
+	case SortFieldLastPlayed:
+		return a.LastPlayed.Compare(b.LastPlayed)
+	case SortFieldSkipCount:
+		return cmp.Compare(a.SkipCount, b.SkipCount)
+	case SortFieldRating:
+		return cmp.Compare(a.Rating, b.Rating)
 	default:
 		return 0
 	}
@@ -293,11 +302,74 @@ func (m *Model) groupKeyAndHeader(album library.AlbumEntry, field GroupField) (k
 			key = "5-" + addedAt.Format("2006-01")
 			header = addedAt.Format("January 2006")
 		}
+
+	case GroupFieldPlayCount:
+		key, header = playCountGroup(album.PlayCount)
+
+	case GroupFieldLastPlayed:
+		key, header = lastPlayedGroup(album.LastPlayed, time.Now())
+
+	case GroupFieldRating:
+		key, header = ratingGroup(album.Rating)
 	}
 
 	return key, header
 }
 
+// playCountGroup returns the group of albums played a number of times.
+// Keys sort from never played to most played.
+func playCountGroup(plays int) (key, header string) {
+	switch {
+	case plays == 0:
+		return "0-never", "Never Played"
+	case plays == 1:
+		return "1-once", "Played Once"
+	case plays < 10:
+		return "2-few", "Played 2-9 Times"
+	case plays < 50:
+		return "3-often", "Played 10-49 Times"
+	default:
+		return "4-most", "Played 50+ Times"
+	}
+}
+
+// lastPlayedGroup returns the group of albums last played at a time. Keys
+// sort from never played to most recently played.
+func lastPlayedGroup(lastPlayed, now time.Time) (key, header string) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	thisWeekStart := today.AddDate(0, 0, -int(today.Weekday()))
+	thisMonthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	thisYearStart := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location())
+
+	switch {
+	case lastPlayed.IsZero():
+		return "0-never", "Never Played"
+	case !lastPlayed.Before(today):
+		return "5-today", "Today"
+	case !lastPlayed.Before(thisWeekStart):
+		return "4-this-week", "This Week"
+	case !lastPlayed.Before(thisMonthStart):
+		return "3-this-month", "This Month"
+	case !lastPlayed.Before(thisYearStart):
+		return "2-this-year", "This Year"
+	default:
+		return "1-earlier", "Earlier"
+	}
+}
+
+// ratingGroup returns the group of albums with a rating in half stars.
+// Keys sort from unrated to five stars.
+func ratingGroup(rating int) (key, header string) {
+	key = fmt.Sprintf("%02d", rating)
+	switch rating {
+	case 0:
+		return key, "Unrated"
+	case 2:
+		return key, "1 Star"
+	}
+	return key, strconv.FormatFloat(float64(rating)/2, 'f', -1, 64) + " Stars"
+}
+
 // sortGroupKeys sorts the keys by their natural order (alphabetical/chronological).
 // GroupSortOrder determines ascending or descending. Unknown groups always sort last.
 func (m *Model) sortGroupKeys(keys []string) {
@@ -307,6 +379,8 @@ func (m *Model) sortGroupKeys(keys []string) {
 	// - Week: "2024-W51", "2024-W50"
 	// - Artist/Genre/Label: alphabetical
 	// - AddedAt: "0-today", "1-this-week", etc.
+	// - PlayCount/LastPlayed: "0-never" up to the most played/most recent
+	// - Rating: "00" (unrated) up to "10" (five stars)
 	// - Unknown: always last
 	sort.Slice(keys, func(i, j int) bool {
 		// Unknown groups always sort last
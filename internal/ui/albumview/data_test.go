@@ -0,0 +1,114 @@
+package albumview
+
+import (
+	"testing"
+	"time"
+
+	"github.com/llehouerou/waves/internal/library"
+)
+
+func TestSortAlbums_PlayStats(t *testing.T) {
+	now := time.Now()
+	albums := []library.AlbumEntry{
+		{Album: "Never", PlayCount: 0},
+		{Album: "Often", PlayCount: 12, LastPlayed: now.Add(-48 * time.Hour)},
+		{Album: "Once", PlayCount: 1, LastPlayed: now.Add(-time.Hour)},
+	}
+
+	tests := []struct {
+		field SortField
+		want  []string
+	}{
+		{SortFieldPlayCount, []string{"Often", "Once", "Never"}},
+		{SortFieldLastPlayed, []string{"Once", "Often", "Never"}},
+	}
+	for _, tt := range tests {
+		t.Run(SortFieldName(tt.field), func(t *testing.T) {
+			m := New(nil)
+			m.settings.SortCriteria = []SortCriterion{{Field: tt.field, Order: SortDesc}}
+			sorted := append([]library.AlbumEntry(nil), albums...)
+			m.sortAlbums(sorted)
+			for i, a := range sorted {
+				if a.Album != tt.want[i] {
+					t.Fatalf("album %d = %q, want order %v", i, a.Album, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestPlayCountGroup(t *testing.T) {
+	tests := []struct {
+		plays      int
+		wantHeader string
+	}{
+		{0, "Never Played"},
+		{1, "Played Once"},
+		{9, "Played 2-9 Times"},
+		{10, "Played 10-49 Times"},
+		{50, "Played 50+ Times"},
+	}
+	prevKey := ""
+	for _, tt := range tests {
+		key, header := playCountGroup(tt.plays)
+		if header != tt.wantHeader {
+			t.Errorf("playCountGroup(%d) header = %q, want %q", tt.plays, header, tt.wantHeader)
+		}
+		if key <= prevKey {
+			t.Errorf("playCountGroup(%d) key %q should sort after %q", tt.plays, key, prevKey)
+		}
+		prevKey = key
+	}
+}
+
+func TestLastPlayedGroup(t *testing.T) {
+	// A Wednesday, so that the start of the week is in the same month
+	now := time.Date(2024, time.May, 15, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		name       string
+		lastPlayed time.Time
+		wantHeader string
+	}{
+		{"never", time.Time{}, "Never Played"},
+		{"earlier", time.Date(2023, time.December, 31, 0, 0, 0, 0, time.Local), "Earlier"},
+		{"this year", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.Local), "This Year"},
+		{"this month", time.Date(2024, time.May, 2, 0, 0, 0, 0, time.Local), "This Month"},
+		{"this week", time.Date(2024, time.May, 13, 9, 0, 0, 0, time.Local), "This Week"},
+		{"today", time.Date(2024, time.May, 15, 8, 0, 0, 0, time.Local), "Today"},
+	}
+	prevKey := ""
+	for _, tt := range tests {
+		key, header := lastPlayedGroup(tt.lastPlayed, now)
+		if header != tt.wantHeader {
+			t.Errorf("%s: header = %q, want %q", tt.name, header, tt.wantHeader)
+		}
+		if key <= prevKey {
+			t.Errorf("%s: key %q should sort after %q", tt.name, key, prevKey)
+		}
+		prevKey = key
+	}
+}
+
+func TestRatingGroup(t *testing.T) {
+	tests := []struct {
+		rating     int
+		wantHeader string
+	}{
+		{0, "Unrated"},
+		{1, "0.5 Stars"},
+		{2, "1 Star"},
+		{7, "3.5 Stars"},
+		{10, "5 Stars"},
+	}
+	prevKey := ""
+	for _, tt := range tests {
+		key, header := ratingGroup(tt.rating)
+		if header != tt.wantHeader {
+			t.Errorf("ratingGroup(%d) header = %q, want %q", tt.rating, header, tt.wantHeader)
+		}
+		if key <= prevKey {
+			t.Errorf("ratingGroup(%d) key %q should sort after %q", tt.rating, key, prevKey)
+		}
+		prevKey = key
+	}
+}
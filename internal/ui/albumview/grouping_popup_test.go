@@ -400,12 +400,12 @@ func TestGroupingPopup_NavigateBackFromOptions(t *testing.T) {
 	// Navigate back up
 	h.SendUp()
 
-	// Should be back in field list at last field (Added)
-	h.SendKey(" ") // Toggle Added
+	// Should be back in field list at last field (Last Played)
+	h.SendKey(" ") // Toggle Last Played
 	h.SendEnter()
 
 	result := getGroupingApplied(t, h)
-	// Should have Artist and Added
+	// Should have Artist and Last Played
 	if len(result.Fields) != 2 {
 		t.Fatalf("expected 2 fields, got %d", len(result.Fields))
 	}
@@ -424,9 +424,9 @@ func TestGroupingPopup_NoOptionsWhenNoSelection(t *testing.T) {
 	h.SendEnter()
 
 	result := getGroupingApplied(t, h)
-	// Last field is Added
-	if len(result.Fields) != 1 || result.Fields[0] != GroupFieldAddedAt {
-		t.Errorf("Fields = %v, want [GroupFieldAddedAt]", result.Fields)
+	// Last field is Rating
+	if len(result.Fields) != 1 || result.Fields[0] != GroupFieldRating {
+		t.Errorf("Fields = %v, want [GroupFieldRating]", result.Fields)
 	}
 }
 
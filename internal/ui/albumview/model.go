@@ -19,14 +19,17 @@ type (
 
 // Re-export constants from albumpreset.
 const (
-	GroupFieldArtist  = albumpreset.GroupFieldArtist
-	GroupFieldGenre   = albumpreset.GroupFieldGenre
-	GroupFieldLabel   = albumpreset.GroupFieldLabel
-	GroupFieldYear    = albumpreset.GroupFieldYear
-	GroupFieldMonth   = albumpreset.GroupFieldMonth
-	GroupFieldWeek    = albumpreset.GroupFieldWeek
-	GroupFieldAddedAt = albumpreset.GroupFieldAddedAt
-	GroupFieldCount   = albumpreset.GroupFieldCount
+	GroupFieldArtist     = albumpreset.GroupFieldArtist
+	GroupFieldGenre      = albumpreset.GroupFieldGenre
+	GroupFieldLabel      = albumpreset.GroupFieldLabel
+	GroupFieldYear       = albumpreset.GroupFieldYear
+	GroupFieldMonth      = albumpreset.GroupFieldMonth
+	GroupFieldWeek       = albumpreset.GroupFieldWeek
+	GroupFieldAddedAt    = albumpreset.GroupFieldAddedAt
+	GroupFieldPlayCount  = albumpreset.GroupFieldPlayCount
+	GroupFieldLastPlayed = albumpreset.GroupFieldLastPlayed
+	GroupFieldRating     = albumpreset.GroupFieldRating
+	GroupFieldCount      = albumpreset.GroupFieldCount
 
 	SortFieldOriginalDate = albumpreset.SortFieldOriginalDate
 	SortFieldReleaseDate  = albumpreset.SortFieldReleaseDate
@@ -35,6 +38,10 @@ const (
 	SortFieldAlbum        = albumpreset.SortFieldAlbum
 	SortFieldTrackCount   = albumpreset.SortFieldTrackCount
 	SortFieldLabel        = albumpreset.SortFieldLabel
+	SortFieldPlayCount    = albumpreset.SortFieldPlayCount
+	SortFieldLastPlayed   = albumpreset.SortFieldLastPlayed
+	SortFieldSkipCount    = albumpreset.SortFieldSkipCount
+	SortFieldRating       = albumpreset.SortFieldRating
 	SortFieldCount        = albumpreset.SortFieldCount
 
 	SortDesc = albumpreset.SortDesc
@@ -95,6 +102,12 @@ func GroupFieldName(f GroupField) string {
 		return "Week"
 	case GroupFieldAddedAt:
 		return "Added"
+	case GroupFieldPlayCount:
+		return "Play Count"
+	case GroupFieldLastPlayed:
+		return "Last Played"
+	case GroupFieldRating:
+		return "Rating"
 	default:
 		return ""
 	}
@@ -117,6 +130,14 @@ func SortFieldName(f SortField) string {
 		return "Track Count"
 	case SortFieldLabel:
 		return "Label"
+	case SortFieldPlayCount:
+		return "Play Count"
+	case SortFieldLastPlayed:
+		return "Last Played"
+	case SortFieldSkipCount:
+		return "Skip Count"
+	case SortFieldRating:
+		return "Rating"
 	default:
 		return ""
 	}
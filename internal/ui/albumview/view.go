@@ -5,7 +5,9 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 
+	"github.com/llehouerou/waves/internal/icons"
 	"github.com/llehouerou/waves/internal/library"
 	"github.com/llehouerou/waves/internal/ui/render"
 	"github.com/llehouerou/waves/internal/ui/styles"
@@ -41,6 +43,10 @@ func yearStyle() lipgloss.Style {
 	return styles.T().S().Subtle
 }
 
+func ratingStyle() lipgloss.Style {
+	return styles.T().S().Playing
+}
+
 func cursorStyle() lipgloss.Style {
 	return styles.T().S().Cursor
 }
@@ -267,8 +273,14 @@ func (m Model) renderAlbumLine(album *library.AlbumEntry, width int, isCursor bo
 		usedWidth += yearColumnWidth
 	}
 
-	// Album column (remaining width)
+	// Album column (remaining width), with the rating right-aligned in it
 	albumColWidth := max(availableWidth-usedWidth, 0)
+	stars := icons.Rating(album.Rating)
+	var ratingCol string
+	if stars != "" && albumColWidth > runewidth.StringWidth(stars)+1 {
+		ratingCol = " " + stars
+		albumColWidth -= runewidth.StringWidth(ratingCol)
+	}
 	albumCol := render.TruncateAndPad(album.Album, albumColWidth)
 
 	// Year column (fixed width at end, only when not grouped by release date)
@@ -283,15 +295,19 @@ func (m Model) renderAlbumLine(album *library.AlbumEntry, width int, isCursor bo
 
 	if isCursor {
 		// When cursor, use cursor background for the whole line with selection indicator
-		line := " > " + artistCol + " " + albumCol + yearCol
+		line := " > " + artistCol + " " + albumCol + ratingCol + yearCol
 		return cursorStyle().Render(line)
 	}
 
 	// Normal rendering with different colors per column
+	line := indent + artistStyle().Render(artistCol) + render.EmptyLine(1) + albumNameStyle().Render(albumCol)
+	if ratingCol != "" {
+		line += ratingStyle().Render(ratingCol)
+	}
 	if showYear {
-		return indent + artistStyle().Render(artistCol) + render.EmptyLine(1) + albumNameStyle().Render(albumCol) + yearStyle().Render(yearCol)
+		line += yearStyle().Render(yearCol)
 	}
-	return indent + artistStyle().Render(artistCol) + render.EmptyLine(1) + albumNameStyle().Render(albumCol)
+	return line
 }
 
 // isGroupedByArtist returns true if any grouping level is by artist.
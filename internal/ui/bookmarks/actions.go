@@ -0,0 +1,47 @@
+package bookmarks
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/bookmarks"
+	"github.com/llehouerou/waves/internal/ui/action"
+)
+
+// Source is the action source identifier for the bookmarks popup.
+const Source = "bookmarks"
+
+// ActionMsg wraps an action with the source identifier.
+func ActionMsg(a action.Action) tea.Msg {
+	return action.Msg{
+		Source: Source,
+		Action: a,
+	}
+}
+
+// Close requests closing the popup.
+type Close struct{}
+
+func (Close) ActionType() string { return "bookmarks.Close" }
+
+// Jump requests seeking to a bookmark.
+type Jump struct {
+	Position time.Duration
+}
+
+func (Jump) ActionType() string { return "bookmarks.Jump" }
+
+// Rename requests renaming a bookmark.
+type Rename struct {
+	Bookmark bookmarks.Bookmark
+}
+
+func (Rename) ActionType() string { return "bookmarks.Rename" }
+
+// Delete requests deleting a bookmark.
+type Delete struct {
+	ID int64
+}
+
+func (Delete) ActionType() string { return "bookmarks.Delete" }
@@ -0,0 +1,77 @@
+package bookmarks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/llehouerou/waves/internal/bookmarks"
+	"github.com/llehouerou/waves/internal/ui/action"
+	"github.com/llehouerou/waves/internal/ui/testutil"
+)
+
+func newTestPopup() (*Model, *testutil.PopupHarness) {
+	m := New("Song", []bookmarks.Bookmark{
+		{ID: 1, Position: 30 * time.Second, Name: "Verse"},
+		{ID: 2, Position: 90 * time.Second, Name: "Solo"},
+	})
+	m.SetSize(80, 20)
+	return m, testutil.NewPopupHarness(m)
+}
+
+func lastAction(t *testing.T, h *testutil.PopupHarness) action.Action {
+	t.Helper()
+	msg := testutil.ExecuteCmd(h.LastCommand())
+	actionMsg, ok := msg.(action.Msg)
+	if !ok {
+		t.Fatalf("expected action.Msg, got %T", msg)
+	}
+	if actionMsg.Source != Source {
+		t.Errorf("Source = %q, want %q", actionMsg.Source, Source)
+	}
+	return actionMsg.Action
+}
+
+func TestEnter_JumpsToSelected(t *testing.T) {
+	_, h := newTestPopup()
+
+	h.SendDown()
+	h.SendEnter()
+	jump, ok := lastAction(t, h).(Jump)
+	if !ok {
+		t.Fatalf("expected Jump, got %T", lastAction(t, h))
+	}
+	if jump.Position != 90*time.Second {
+		t.Errorf("Position = %v, want 1m30s", jump.Position)
+	}
+}
+
+func TestDeleteAndRename(t *testing.T) {
+	m, h := newTestPopup()
+
+	h.SendKey("d")
+	if del, ok := lastAction(t, h).(Delete); !ok || del.ID != 1 {
+		t.Errorf("got %#v, want Delete of bookmark 1", lastAction(t, h))
+	}
+
+	m.SetBookmarks([]bookmarks.Bookmark{{ID: 2, Position: 90 * time.Second, Name: "Solo"}})
+	h.SendKey("r")
+	if ren, ok := lastAction(t, h).(Rename); !ok || ren.Bookmark.ID != 2 {
+		t.Errorf("got %#v, want Rename of bookmark 2", lastAction(t, h))
+	}
+}
+
+func TestEmptyList(t *testing.T) {
+	m := New("Song", nil)
+	m.SetSize(80, 20)
+	h := testutil.NewPopupHarness(m)
+
+	h.SendEnter()
+	h.SendKey("d")
+	if cmd := h.LastCommand(); cmd != nil {
+		t.Errorf("expected no action on an empty list, got %T", testutil.ExecuteCmd(cmd))
+	}
+	h.SendEscape()
+	if _, ok := lastAction(t, h).(Close); !ok {
+		t.Errorf("expected Close, got %T", lastAction(t, h))
+	}
+}
@@ -0,0 +1,84 @@
+// Package bookmarks provides the popup listing the bookmarks of a track.
+package bookmarks
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/bookmarks"
+	"github.com/llehouerou/waves/internal/ui/popup"
+)
+
+// Compile-time check that Model implements popup.Popup.
+var _ popup.Popup = (*Model)(nil)
+
+// Model is the bookmarks popup model.
+type Model struct {
+	title  string // Title of the track
+	list   []bookmarks.Bookmark
+	cursor int
+
+	width  int
+	height int
+}
+
+// New creates a popup listing the bookmarks of a track.
+func New(title string, list []bookmarks.Bookmark) *Model {
+	return &Model{title: title, list: list}
+}
+
+// SetBookmarks replaces the list, e.g. after renaming or deleting one.
+func (m *Model) SetBookmarks(list []bookmarks.Bookmark) {
+	m.list = list
+	m.cursor = min(m.cursor, max(len(list)-1, 0))
+}
+
+// SetSize implements popup.Popup.
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// Init implements popup.Popup.
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements popup.Popup.
+func (m *Model) Update(msg tea.Msg) (popup.Popup, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q", "B":
+		return m, func() tea.Msg { return ActionMsg(Close{}) }
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+	case "down", "j":
+		m.cursor = min(m.cursor+1, max(len(m.list)-1, 0))
+	case "enter":
+		if b := m.selected(); b != nil {
+			return m, func() tea.Msg { return ActionMsg(Jump{Position: b.Position}) }
+		}
+	case "r", "ctrl+r":
+		if b := m.selected(); b != nil {
+			bookmark := *b
+			return m, func() tea.Msg { return ActionMsg(Rename{Bookmark: bookmark}) }
+		}
+	case "d":
+		if b := m.selected(); b != nil {
+			id := b.ID
+			return m, func() tea.Msg { return ActionMsg(Delete{ID: id}) }
+		}
+	}
+	return m, nil
+}
+
+// selected returns the bookmark under the cursor, nil if there is none.
+func (m *Model) selected() *bookmarks.Bookmark {
+	if m.cursor < 0 || m.cursor >= len(m.list) {
+		return nil
+	}
+	return &m.list[m.cursor]
+}
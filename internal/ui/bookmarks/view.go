@@ -0,0 +1,50 @@
+package bookmarks
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/llehouerou/waves/internal/bookmarks"
+	"github.com/llehouerou/waves/internal/ui/render"
+	"github.com/llehouerou/waves/internal/ui/styles"
+)
+
+func titleStyle() lipgloss.Style {
+	return styles.T().S().Title
+}
+
+func baseStyle() lipgloss.Style {
+	return styles.T().S().Base
+}
+
+func cursorStyle() lipgloss.Style {
+	return styles.T().S().Cursor
+}
+
+func hintStyle() lipgloss.Style {
+	return styles.T().S().Subtle
+}
+
+// View implements popup.Popup.
+func (m *Model) View() string {
+	if m.width == 0 || m.height == 0 {
+		return ""
+	}
+	title := titleStyle().Render(render.TruncateEllipsis("Bookmarks · "+m.title, m.width))
+
+	var lines []string
+	if len(m.list) == 0 {
+		lines = append(lines, hintStyle().Italic(true).Render("  No bookmarks, press b while playing to add one"))
+	}
+	for i, b := range m.list {
+		line := "  " + bookmarks.FormatPosition(b.Position) + "  " + b.Name
+		if i == m.cursor {
+			line = cursorStyle().Render("> " + bookmarks.FormatPosition(b.Position) + "  " + b.Name)
+		}
+		lines = append(lines, baseStyle().Render(line))
+	}
+
+	hint := hintStyle().Render("↑↓ navigate · enter jump · r rename · d delete · esc close")
+	return title + "\n\n" + strings.Join(lines, "\n") + "\n\n" + hint
+}
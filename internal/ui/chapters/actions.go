@@ -0,0 +1,32 @@
+package chapters
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/ui/action"
+)
+
+// Source is the action source identifier for the chapters popup.
+const Source = "chapters"
+
+// ActionMsg wraps an action with the source identifier.
+func ActionMsg(a action.Action) tea.Msg {
+	return action.Msg{
+		Source: Source,
+		Action: a,
+	}
+}
+
+// Close requests closing the popup.
+type Close struct{}
+
+func (Close) ActionType() string { return "chapters.Close" }
+
+// Jump requests seeking to the start of a chapter.
+type Jump struct {
+	Position time.Duration
+}
+
+func (Jump) ActionType() string { return "chapters.Jump" }
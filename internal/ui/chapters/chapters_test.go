@@ -0,0 +1,89 @@
+package chapters
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/llehouerou/waves/internal/tags"
+	"github.com/llehouerou/waves/internal/ui/action"
+	"github.com/llehouerou/waves/internal/ui/testutil"
+)
+
+func testChapters(n int) []tags.Chapter {
+	list := make([]tags.Chapter, n)
+	for i := range list {
+		list[i] = tags.Chapter{
+			Title: "Chapter " + string(rune('A'+i)),
+			Start: time.Duration(i) * time.Minute,
+			End:   time.Duration(i+1) * time.Minute,
+		}
+	}
+	return list
+}
+
+func lastAction(t *testing.T, h *testutil.PopupHarness) action.Action {
+	t.Helper()
+	msg := testutil.ExecuteCmd(h.LastCommand())
+	actionMsg, ok := msg.(action.Msg)
+	if !ok {
+		t.Fatalf("expected action.Msg, got %T", msg)
+	}
+	if actionMsg.Source != Source {
+		t.Errorf("Source = %q, want %q", actionMsg.Source, Source)
+	}
+	return actionMsg.Action
+}
+
+func TestEnter_JumpsFromCurrentChapter(t *testing.T) {
+	m := New("Book", testChapters(3), 1)
+	m.SetSize(80, 20)
+	h := testutil.NewPopupHarness(m)
+
+	h.SendDown()
+	h.SendEnter()
+	jump, ok := lastAction(t, h).(Jump)
+	if !ok {
+		t.Fatalf("expected Jump, got %T", lastAction(t, h))
+	}
+	if jump.Position != 2*time.Minute {
+		t.Errorf("Position = %v, want 2m (chapter after the current one)", jump.Position)
+	}
+}
+
+func TestView_ScrollsToCursor(t *testing.T) {
+	m := New("Book", testChapters(20), 15)
+	m.SetSize(80, 8)
+	h := testutil.NewPopupHarness(m)
+
+	view := h.View()
+	if !strings.Contains(view, "Chapter P") {
+		t.Errorf("view should show the current chapter:\n%s", view)
+	}
+	if strings.Contains(view, "Chapter A") {
+		t.Errorf("view should scroll past the first chapter:\n%s", view)
+	}
+
+	h.SendKey("g")
+	if view := h.View(); !strings.Contains(view, "Chapter A") {
+		t.Errorf("g should scroll back to the first chapter:\n%s", view)
+	}
+}
+
+func TestEmptyList(t *testing.T) {
+	m := New("Song", nil, -1)
+	m.SetSize(80, 20)
+	h := testutil.NewPopupHarness(m)
+
+	h.SendEnter()
+	if cmd := h.LastCommand(); cmd != nil {
+		t.Errorf("expected no action on an empty list, got %T", testutil.ExecuteCmd(cmd))
+	}
+	if !strings.Contains(h.View(), "no chapters") {
+		t.Errorf("view should say the track has no chapters:\n%s", h.View())
+	}
+	h.SendKey("C")
+	if _, ok := lastAction(t, h).(Close); !ok {
+		t.Errorf("expected Close, got %T", lastAction(t, h))
+	}
+}
@@ -0,0 +1,86 @@
+// Package chapters provides the popup listing the chapters of an audiobook
+// or podcast episode.
+package chapters
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/tags"
+	"github.com/llehouerou/waves/internal/ui/popup"
+)
+
+// Compile-time check that Model implements popup.Popup.
+var _ popup.Popup = (*Model)(nil)
+
+// Model is the chapters popup model.
+type Model struct {
+	title    string // Title of the track
+	list     []tags.Chapter
+	current  int // Chapter playing when the popup opened, -1 if none
+	cursor   int
+	offset   int // First chapter shown, for lists taller than the popup
+	width    int
+	height   int
+	maxLines int
+}
+
+// New creates a popup listing the chapters of a track, with the cursor on
+// the chapter playing.
+func New(title string, list []tags.Chapter, current int) *Model {
+	return &Model{title: title, list: list, current: current, cursor: max(current, 0)}
+}
+
+// SetSize implements popup.Popup.
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	// Title, hint and the blank lines around the list.
+	m.maxLines = max(height-4, 1)
+	m.scrollToCursor()
+}
+
+// Init implements popup.Popup.
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements popup.Popup.
+func (m *Model) Update(msg tea.Msg) (popup.Popup, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q", "C":
+		return m, func() tea.Msg { return ActionMsg(Close{}) }
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+	case "down", "j":
+		m.cursor = min(m.cursor+1, max(len(m.list)-1, 0))
+	case "g", "home":
+		m.cursor = 0
+	case "G", "end":
+		m.cursor = max(len(m.list)-1, 0)
+	case "enter":
+		if m.cursor < len(m.list) {
+			pos := m.list[m.cursor].Start
+			return m, func() tea.Msg { return ActionMsg(Jump{Position: pos}) }
+		}
+	}
+	m.scrollToCursor()
+	return m, nil
+}
+
+// scrollToCursor keeps the cursor within the visible chapters.
+func (m *Model) scrollToCursor() {
+	if m.maxLines == 0 {
+		return
+	}
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.maxLines {
+		m.offset = m.cursor - m.maxLines + 1
+	}
+}
@@ -0,0 +1,57 @@
+package chapters
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/llehouerou/waves/internal/bookmarks"
+	"github.com/llehouerou/waves/internal/ui/render"
+	"github.com/llehouerou/waves/internal/ui/styles"
+)
+
+func titleStyle() lipgloss.Style {
+	return styles.T().S().Title
+}
+
+func baseStyle() lipgloss.Style {
+	return styles.T().S().Base
+}
+
+func cursorStyle() lipgloss.Style {
+	return styles.T().S().Cursor
+}
+
+func hintStyle() lipgloss.Style {
+	return styles.T().S().Subtle
+}
+
+// View implements popup.Popup.
+func (m *Model) View() string {
+	if m.width == 0 || m.height == 0 {
+		return ""
+	}
+	title := titleStyle().Render(render.TruncateEllipsis("Chapters · "+m.title, m.width))
+
+	var lines []string
+	if len(m.list) == 0 {
+		lines = append(lines, hintStyle().Italic(true).Render("  This track has no chapters"))
+	}
+	end := min(m.offset+m.maxLines, len(m.list))
+	for i := m.offset; i < end; i++ {
+		c := m.list[i]
+		marker := "  "
+		if i == m.current {
+			marker = "▶ "
+		}
+		text := render.TruncateEllipsis(bookmarks.FormatPosition(c.Start)+"  "+c.Title, max(m.width-2, 1))
+		if i == m.cursor {
+			lines = append(lines, cursorStyle().Render(marker+text))
+			continue
+		}
+		lines = append(lines, baseStyle().Render(marker+text))
+	}
+
+	hint := hintStyle().Render("↑↓ navigate · enter jump · { } previous/next chapter · esc close")
+	return title + "\n\n" + strings.Join(lines, "\n") + "\n\n" + hint
+}
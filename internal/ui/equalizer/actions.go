@@ -0,0 +1,55 @@
+package equalizer
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/equalizer"
+	"github.com/llehouerou/waves/internal/ui/action"
+)
+
+// Source is the action source identifier for the equalizer popup.
+const Source = "equalizer"
+
+// ActionMsg wraps an action with the source identifier.
+func ActionMsg(a action.Action) tea.Msg {
+	return action.Msg{
+		Source: Source,
+		Action: a,
+	}
+}
+
+// Preview signals that the settings were edited and should be heard right away.
+type Preview struct {
+	Settings equalizer.Settings
+}
+
+func (Preview) ActionType() string { return "equalizer.Preview" }
+
+// Applied signals that the edits should be kept and saved.
+type Applied struct {
+	State equalizer.State
+}
+
+func (Applied) ActionType() string { return "equalizer.Applied" }
+
+// Canceled signals that the edits should be discarded.
+type Canceled struct {
+	Original equalizer.State
+}
+
+func (Canceled) ActionType() string { return "equalizer.Canceled" }
+
+// PresetSaved signals that the current settings should be saved as a preset.
+type PresetSaved struct {
+	Name     string
+	Settings equalizer.Settings
+}
+
+func (PresetSaved) ActionType() string { return "equalizer.PresetSaved" }
+
+// PresetDeleted signals that a preset should be deleted.
+type PresetDeleted struct {
+	ID int64
+}
+
+func (PresetDeleted) ActionType() string { return "equalizer.PresetDeleted" }
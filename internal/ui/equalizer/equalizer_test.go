@@ -0,0 +1,225 @@
+package equalizer
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/equalizer"
+	"github.com/llehouerou/waves/internal/ui/action"
+	"github.com/llehouerou/waves/internal/ui/testutil"
+)
+
+func newTestPopup(presets []equalizer.Preset) (*Model, *testutil.PopupHarness) {
+	m := New(equalizer.DefaultState(), presets)
+	m.SetSize(100, 30)
+	return m, testutil.NewPopupHarness(m)
+}
+
+func lastAction(t *testing.T, h *testutil.PopupHarness) action.Action {
+	t.Helper()
+	msg := testutil.ExecuteCmd(h.LastCommand())
+	actionMsg, ok := msg.(action.Msg)
+	if !ok {
+		t.Fatalf("expected action.Msg, got %T", msg)
+	}
+	if actionMsg.Source != Source {
+		t.Errorf("Source = %q, want %q", actionMsg.Source, Source)
+	}
+	return actionMsg.Action
+}
+
+func lastPreview(t *testing.T, h *testutil.PopupHarness) equalizer.Settings {
+	t.Helper()
+	p, ok := lastAction(t, h).(Preview)
+	if !ok {
+		t.Fatalf("expected Preview, got %T", lastAction(t, h))
+	}
+	return p.Settings
+}
+
+func TestAdjustGain_Previews(t *testing.T) {
+	_, h := newTestPopup(nil)
+
+	h.SendDown() // First band, gain field
+	h.SendKey("+")
+	h.SendKey("+")
+	if got := lastPreview(t, h).Bands[0].GainDB; got != 1 {
+		t.Errorf("gain = %v, want 1", got)
+	}
+
+	h.SendKey("]")
+	h.SendKey("]")
+	h.SendKey("]")
+	h.SendKey("]")
+	h.SendKey("]")
+	if got := lastPreview(t, h).Bands[0].GainDB; got != equalizer.MaxGain {
+		t.Errorf("gain = %v, want clamped to %v", got, equalizer.MaxGain)
+	}
+
+	h.SendKey("0")
+	if got := lastPreview(t, h).Bands[0].GainDB; got != 0 {
+		t.Errorf("gain = %v after reset, want 0", got)
+	}
+}
+
+func TestAdjustFrequencyAndType(t *testing.T) {
+	_, h := newTestPopup(nil)
+
+	h.SendDown()
+	h.SendSpecialKey(tea.KeyRight) // Freq
+	h.SendKey("]")
+	if got := lastPreview(t, h).Bands[0].Freq; got != 62 {
+		t.Errorf("freq = %v, want one octave above 31", got)
+	}
+
+	h.SendSpecialKey(tea.KeyLeft)
+	h.SendSpecialKey(tea.KeyLeft) // Wraps to type
+	h.SendKey("+")
+	if got := lastPreview(t, h).Bands[0].Type; got != equalizer.LowShelf {
+		t.Errorf("type = %v, want low shelf", got)
+	}
+}
+
+func TestPreampAndAuto(t *testing.T) {
+	_, h := newTestPopup(nil)
+
+	h.SendDown()
+	h.SendKey("]") // +3 dB on the first band
+	h.SendUp()
+	h.SendKey("a")
+	s := lastPreview(t, h)
+	if s.PreampDB >= -2.9 || s.PreampDB < -3.5 {
+		t.Errorf("auto preamp = %v, want about -3", s.PreampDB)
+	}
+	if err := h.AssertViewNotContains("may clip"); err != "" {
+		t.Error(err)
+	}
+
+	h.SendKey("+")
+	h.SendKey("+")
+	if err := h.AssertViewContains("may clip"); err != "" {
+		t.Error(err)
+	}
+}
+
+func TestToggleEnabledAndProfile(t *testing.T) {
+	m, h := newTestPopup(nil)
+
+	h.SendKey("e")
+	if !lastPreview(t, h).Enabled {
+		t.Error("expected enabled settings")
+	}
+	if err := h.AssertViewContains("Speakers · on"); err != "" {
+		t.Error(err)
+	}
+
+	h.SendTab()
+	if lastPreview(t, h).Enabled {
+		t.Error("headphones profile should have its own, disabled settings")
+	}
+	if err := h.AssertViewContains("Headphones · off"); err != "" {
+		t.Error(err)
+	}
+	if m.State().Profile != equalizer.ProfileHeadphones {
+		t.Errorf("Profile = %v, want headphones", m.State().Profile)
+	}
+}
+
+func TestAddAndRemoveBands(t *testing.T) {
+	m, h := newTestPopup(nil)
+	n := len(equalizer.Default().Bands)
+
+	h.SendDown() // 31 Hz
+	h.SendKey("n")
+	bands := lastPreview(t, h).Bands
+	if len(bands) != n+1 || bands[1].Freq != 44 {
+		t.Errorf("bands = %+v, want a new band between 31 and 62 Hz", bands)
+	}
+
+	h.SendKey("d")
+	if got := len(lastPreview(t, h).Bands); got != n {
+		t.Errorf("got %d bands after remove, want %d", got, n)
+	}
+
+	// The preamp row can't be removed
+	h.ClearCommands()
+	m.row = 0
+	h.SendKey("d")
+	if h.LastCommand() != nil {
+		t.Error("removing the preamp should do nothing")
+	}
+}
+
+func TestApplyAndCancel(t *testing.T) {
+	_, h := newTestPopup(nil)
+
+	h.SendKey("e")
+	h.SendEnter()
+	applied, ok := lastAction(t, h).(Applied)
+	if !ok || !applied.State.Speakers.Enabled {
+		t.Fatalf("expected Applied with the edits, got %+v", lastAction(t, h))
+	}
+
+	h.SendEscape()
+	canceled, ok := lastAction(t, h).(Canceled)
+	if !ok || canceled.Original.Speakers.Enabled {
+		t.Fatalf("expected Canceled with the original state, got %+v", lastAction(t, h))
+	}
+}
+
+func TestLoadPreset(t *testing.T) {
+	presets := equalizer.BuiltinPresets()
+	m, h := newTestPopup(presets)
+
+	h.SendKey("p")
+	if m.Mode() != ModePresets {
+		t.Fatalf("Mode = %v, want presets", m.Mode())
+	}
+	if err := h.AssertViewContains("Bass Boost"); err != "" {
+		t.Error(err)
+	}
+
+	h.SendDown()
+	h.SendEnter()
+	s := lastPreview(t, h)
+	if s.PreampDB != presets[1].Settings.PreampDB || s.Bands[0].GainDB != presets[1].Settings.Bands[0].GainDB {
+		t.Errorf("loaded %+v, want Bass Boost", s)
+	}
+	if m.Mode() != ModeBands {
+		t.Errorf("Mode = %v, want back to bands", m.Mode())
+	}
+}
+
+func TestSaveAndDeletePreset(t *testing.T) {
+	m, h := newTestPopup([]equalizer.Preset{{ID: 7, Name: "Old"}})
+
+	h.SendKey("s")
+	h.SendKey("Mine")
+	h.SendEnter()
+	saved, ok := lastAction(t, h).(PresetSaved)
+	if !ok || saved.Name != "Mine" {
+		t.Fatalf("expected PresetSaved named Mine, got %+v", lastAction(t, h))
+	}
+
+	h.SendKey("p")
+	h.SendKey("d")
+	deleted, ok := lastAction(t, h).(PresetDeleted)
+	if !ok || deleted.ID != 7 {
+		t.Fatalf("expected PresetDeleted for 7, got %+v", lastAction(t, h))
+	}
+
+	m.SetPresets(nil)
+	if err := h.AssertViewContains("No saved presets"); err != "" {
+		t.Error(err)
+	}
+}
+
+func TestFormatFreq(t *testing.T) {
+	tests := map[float64]string{31: "31 Hz", 1000: "1 kHz", 2500: "2.5 kHz", 16000: "16 kHz"}
+	for f, want := range tests {
+		if got := formatFreq(f); got != want {
+			t.Errorf("formatFreq(%v) = %q, want %q", f, got, want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Package equalizer provides the equalizer editor popup.
+package equalizer
+
+import (
+	"github.com/llehouerou/waves/internal/equalizer"
+	"github.com/llehouerou/waves/internal/ui/popup"
+)
+
+// Compile-time check that Model implements popup.Popup.
+var _ popup.Popup = (*Model)(nil)
+
+// Mode represents the current mode of the popup.
+type Mode int
+
+const (
+	ModeBands   Mode = iota // Editing the bands
+	ModePresets             // Browsing presets
+	ModeSave                // Entering a name for a new preset
+)
+
+// Field is the band parameter being edited.
+type Field int
+
+const (
+	FieldGain Field = iota
+	FieldFreq
+	FieldQ
+	FieldType
+)
+
+// fieldCount is the number of editable band fields.
+const fieldCount = 4
+
+// Model is the equalizer popup model.
+type Model struct {
+	state    equalizer.State // Settings being edited
+	original equalizer.State // Restored on cancel
+	presets  []equalizer.Preset
+
+	mode         Mode
+	row          int // 0 is the preamp, bands start at 1
+	field        Field
+	presetCursor int
+	input        string
+
+	width  int
+	height int
+}
+
+// New creates an equalizer popup editing the given state.
+func New(state equalizer.State, presets []equalizer.Preset) *Model {
+	return &Model{
+		state:    state.Clone(),
+		original: state.Clone(),
+		presets:  presets,
+	}
+}
+
+// SetPresets replaces the preset list, e.g. after saving or deleting one.
+func (m *Model) SetPresets(presets []equalizer.Preset) {
+	m.presets = presets
+	m.presetCursor = min(m.presetCursor, max(len(presets)-1, 0))
+}
+
+// State returns the settings being edited.
+func (m *Model) State() equalizer.State {
+	return m.state.Clone()
+}
+
+// Mode returns the current mode.
+func (m *Model) Mode() Mode {
+	return m.mode
+}
+
+// SetSize implements popup.Popup.
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// active returns the settings of the profile being edited.
+func (m *Model) active() *equalizer.Settings {
+	if m.state.Profile == equalizer.ProfileHeadphones {
+		return &m.state.Headphones
+	}
+	return &m.state.Speakers
+}
+
+// band returns the band under the cursor, or nil on the preamp row.
+func (m *Model) band() *equalizer.Band {
+	s := m.active()
+	if m.row < 1 || m.row > len(s.Bands) {
+		return nil
+	}
+	return &s.Bands[m.row-1]
+}
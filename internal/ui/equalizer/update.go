@@ -0,0 +1,258 @@
+package equalizer
+
+import (
+	"math"
+	"slices"
+	"unicode"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/equalizer"
+	"github.com/llehouerou/waves/internal/ui/popup"
+)
+
+// Step sizes for fine (+/-) and coarse ([/]) adjustments.
+const (
+	gainStep       = 0.5
+	gainStepCoarse = 3.0
+	preampStep     = 0.5
+	qStep          = 0.1
+	freqStep       = 1.0 / 12 // Octaves
+	freqStepCoarse = 1.0      // Octaves
+)
+
+// Init implements popup.Popup.
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements popup.Popup.
+func (m *Model) Update(msg tea.Msg) (popup.Popup, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch m.mode {
+	case ModePresets:
+		return m, m.handlePresetsKey(keyMsg)
+	case ModeSave:
+		return m, m.handleSaveKey(keyMsg)
+	case ModeBands:
+	}
+	return m, m.handleBandsKey(keyMsg)
+}
+
+func (m *Model) handleBandsKey(msg tea.KeyMsg) tea.Cmd {
+	s := m.active()
+
+	switch msg.String() {
+	case "up", "k":
+		m.row = max(m.row-1, 0)
+		return nil
+	case "down", "j":
+		m.row = min(m.row+1, len(s.Bands))
+		return nil
+	case "left", "h":
+		m.field = (m.field + fieldCount - 1) % fieldCount
+		return nil
+	case "right", "l":
+		m.field = (m.field + 1) % fieldCount
+		return nil
+	case "+", "=":
+		m.adjust(1, false)
+	case "-":
+		m.adjust(-1, false)
+	case "]":
+		m.adjust(1, true)
+	case "[":
+		m.adjust(-1, true)
+	case "0":
+		m.reset()
+	case "e":
+		s.Enabled = !s.Enabled
+	case "a":
+		s.PreampDB = max(s.AutoPreamp(), equalizer.MinPreamp)
+	case "n":
+		if !m.addBand() {
+			return nil
+		}
+	case "d", "delete":
+		if !m.removeBand() {
+			return nil
+		}
+	case "tab":
+		if m.state.Profile == equalizer.ProfileHeadphones {
+			m.state.Profile = equalizer.ProfileSpeakers
+		} else {
+			m.state.Profile = equalizer.ProfileHeadphones
+		}
+		m.row = min(m.row, len(m.active().Bands))
+	case "p":
+		m.mode = ModePresets
+		return nil
+	case "s":
+		m.mode = ModeSave
+		m.input = ""
+		return nil
+	case "enter":
+		state := m.state.Clone()
+		return func() tea.Msg { return ActionMsg(Applied{State: state}) }
+	case "esc":
+		original := m.original.Clone()
+		return func() tea.Msg { return ActionMsg(Canceled{Original: original}) }
+	default:
+		return nil
+	}
+
+	return m.preview()
+}
+
+// preview emits the settings of the edited profile for live preview.
+func (m *Model) preview() tea.Cmd {
+	settings := m.active().Clone()
+	return func() tea.Msg { return ActionMsg(Preview{Settings: settings}) }
+}
+
+// adjust changes the selected value up (dir 1) or down (dir -1).
+func (m *Model) adjust(dir float64, coarse bool) {
+	s := m.active()
+	b := m.band()
+	if b == nil {
+		step := preampStep
+		if coarse {
+			step = gainStepCoarse
+		}
+		s.PreampDB = clampRound(s.PreampDB+dir*step, equalizer.MinPreamp, equalizer.MaxPreamp, 10)
+		return
+	}
+
+	switch m.field {
+	case FieldGain:
+		step := gainStep
+		if coarse {
+			step = gainStepCoarse
+		}
+		b.GainDB = clampRound(b.GainDB+dir*step, equalizer.MinGain, equalizer.MaxGain, 10)
+	case FieldFreq:
+		step := freqStep
+		if coarse {
+			step = freqStepCoarse
+		}
+		b.Freq = clampRound(b.Freq*math.Pow(2, dir*step), equalizer.MinFreq, equalizer.MaxFreq, 1)
+	case FieldQ:
+		step := qStep
+		if coarse {
+			step = 1
+		}
+		b.Q = clampRound(b.Q+dir*step, equalizer.MinQ, equalizer.MaxQ, 100)
+	case FieldType:
+		b.Type = equalizer.BandType((int(b.Type) + int(dir) + equalizer.BandTypeCount) % equalizer.BandTypeCount)
+	}
+}
+
+// reset sets the selected value back to neutral.
+func (m *Model) reset() {
+	b := m.band()
+	if b == nil {
+		m.active().PreampDB = 0
+		return
+	}
+	switch m.field {
+	case FieldGain:
+		b.GainDB = 0
+	case FieldQ:
+		b.Q = equalizer.Default().Bands[0].Q
+	case FieldFreq, FieldType:
+		// No neutral value
+	}
+}
+
+// addBand inserts a flat band after the cursor.
+func (m *Model) addBand() bool {
+	s := m.active()
+	if len(s.Bands) >= equalizer.MaxBands {
+		return false
+	}
+	nb := equalizer.Band{Type: equalizer.Peaking, Freq: 1000, Q: equalizer.Default().Bands[0].Q}
+	if b := m.band(); b != nil {
+		// Halfway (in octaves) to the next band, or an octave above the last one
+		nb.Freq = min(b.Freq*2, equalizer.MaxFreq)
+		if m.row < len(s.Bands) {
+			nb.Freq = math.Round(math.Sqrt(b.Freq * s.Bands[m.row].Freq))
+		}
+	}
+	s.Bands = slices.Insert(s.Bands, m.row, nb)
+	m.row++
+	return true
+}
+
+// removeBand deletes the band under the cursor.
+func (m *Model) removeBand() bool {
+	if m.band() == nil {
+		return false
+	}
+	s := m.active()
+	s.Bands = slices.Delete(s.Bands, m.row-1, m.row)
+	m.row = min(m.row, len(s.Bands))
+	return true
+}
+
+func (m *Model) handlePresetsKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "up", "k":
+		m.presetCursor = max(m.presetCursor-1, 0)
+	case "down", "j":
+		m.presetCursor = min(m.presetCursor+1, max(len(m.presets)-1, 0))
+	case "enter":
+		if m.presetCursor < len(m.presets) {
+			settings := m.presets[m.presetCursor].Settings.Clone()
+			settings.Enabled = true
+			*m.active() = settings
+			m.row = min(m.row, len(settings.Bands))
+			m.mode = ModeBands
+			return m.preview()
+		}
+	case "d", "delete":
+		if m.presetCursor < len(m.presets) {
+			id := m.presets[m.presetCursor].ID
+			return func() tea.Msg { return ActionMsg(PresetDeleted{ID: id}) }
+		}
+	case "esc", "p":
+		m.mode = ModeBands
+	}
+	return nil
+}
+
+func (m *Model) handleSaveKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		if m.input == "" {
+			return nil
+		}
+		name := m.input
+		settings := m.active().Clone()
+		m.mode = ModeBands
+		return func() tea.Msg { return ActionMsg(PresetSaved{Name: name, Settings: settings}) }
+	case "esc":
+		m.mode = ModeBands
+	case "backspace":
+		if m.input != "" {
+			_, size := utf8.DecodeLastRuneInString(m.input)
+			m.input = m.input[:len(m.input)-size]
+		}
+	default:
+		for _, r := range msg.Runes {
+			if !unicode.IsControl(r) {
+				m.input += string(r)
+			}
+		}
+	}
+	return nil
+}
+
+// clampRound limits v to [lo, hi] and rounds it to 1/scale.
+func clampRound(v, lo, hi, scale float64) float64 {
+	return max(min(math.Round(v*scale)/scale, hi), lo)
+}
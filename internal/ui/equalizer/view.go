@@ -0,0 +1,178 @@
+package equalizer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/llehouerou/waves/internal/equalizer"
+	"github.com/llehouerou/waves/internal/ui/styles"
+)
+
+func titleStyle() lipgloss.Style {
+	return styles.T().S().Title
+}
+
+func baseStyle() lipgloss.Style {
+	return styles.T().S().Base
+}
+
+func cursorStyle() lipgloss.Style {
+	return styles.T().S().Cursor
+}
+
+func hintStyle() lipgloss.Style {
+	return styles.T().S().Subtle
+}
+
+func warningStyle() lipgloss.Style {
+	return styles.T().S().Warning
+}
+
+// barWidth is the width of the gain bar of each band.
+const barWidth = 21
+
+// View implements popup.Popup.
+func (m *Model) View() string {
+	if m.width == 0 || m.height == 0 {
+		return ""
+	}
+	switch m.mode {
+	case ModePresets:
+		return m.viewPresets()
+	case ModeSave:
+		return m.viewSave()
+	case ModeBands:
+	}
+	return m.viewBands()
+}
+
+func (m *Model) viewBands() string {
+	s := m.active()
+
+	status := "off"
+	if s.Enabled {
+		status = "on"
+	}
+	profile := "Speakers"
+	if m.state.Profile == equalizer.ProfileHeadphones {
+		profile = "Headphones"
+	}
+	title := titleStyle().Render(fmt.Sprintf("Equalizer · %s · %s", profile, status))
+
+	lines := []string{m.preampLine(s), ""}
+	lines = append(lines, hintStyle().Render(fmt.Sprintf("   %-3s %-10s %9s %9s %6s", "#", "Type", "Freq", "Gain", "Q")))
+	for i, b := range s.Bands {
+		lines = append(lines, m.bandLine(i, b))
+	}
+	if len(s.Bands) == 0 {
+		lines = append(lines, hintStyle().Italic(true).Render("   No bands, press n to add one"))
+	}
+
+	hints := hintStyle().Render("↑↓ band · ←→ field · +/- adjust · [ ] coarse · 0 reset · n add · d remove") + "\n" +
+		hintStyle().Render("e on/off · a auto preamp · tab profile · p presets · s save preset · enter apply · esc cancel")
+
+	return title + "\n\n" + strings.Join(lines, "\n") + "\n\n" + hints
+}
+
+func (m *Model) preampLine(s *equalizer.Settings) string {
+	prefix := "  "
+	value := fmt.Sprintf("%+.1f dB", s.PreampDB)
+	if m.row == 0 {
+		prefix = "> "
+		value = cursorStyle().Render(value)
+	}
+	line := prefix + baseStyle().Render("Preamp ") + value
+
+	if auto := s.AutoPreamp(); s.PreampDB > auto {
+		line += "  " + warningStyle().Render(fmt.Sprintf("may clip, auto %+.1f dB", auto))
+	}
+	return line
+}
+
+func (m *Model) bandLine(i int, b equalizer.Band) string {
+	selected := m.row == i+1
+	cell := func(f Field, text string) string {
+		if selected && m.field == f {
+			return cursorStyle().Render(text)
+		}
+		return baseStyle().Render(text)
+	}
+
+	prefix := "  "
+	if selected {
+		prefix = "> "
+	}
+	return prefix + baseStyle().Render(fmt.Sprintf(" %-3d ", i+1)) +
+		cell(FieldType, fmt.Sprintf("%-10s", b.Type)) + " " +
+		cell(FieldFreq, fmt.Sprintf("%9s", formatFreq(b.Freq))) + " " +
+		cell(FieldGain, fmt.Sprintf("%9s", fmt.Sprintf("%+.1f dB", b.GainDB))) + " " +
+		cell(FieldQ, fmt.Sprintf("%6.2f", b.Q)) + "  " +
+		hintStyle().Render(gainBar(b.GainDB))
+}
+
+// formatFreq formats a frequency as "125 Hz" or "2.5 kHz".
+func formatFreq(f float64) string {
+	if f >= 1000 {
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", f/1000), ".0") + " kHz"
+	}
+	return fmt.Sprintf("%.0f Hz", f)
+}
+
+// gainBar draws the gain as a marker on a horizontal scale centered on 0 dB.
+func gainBar(gain float64) string {
+	half := barWidth / 2
+	pos := half + int(gain/equalizer.MaxGain*float64(half)+0.5*sign(gain))
+	pos = max(min(pos, barWidth-1), 0)
+
+	var sb strings.Builder
+	for i := range barWidth {
+		switch {
+		case i == pos:
+			sb.WriteString("●")
+		case i == half:
+			sb.WriteString("┼")
+		default:
+			sb.WriteString("─")
+		}
+	}
+	return sb.String()
+}
+
+func sign(v float64) float64 {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func (m *Model) viewPresets() string {
+	title := titleStyle().Render("Equalizer Presets")
+
+	var lines []string
+	if len(m.presets) == 0 {
+		lines = append(lines, hintStyle().Italic(true).Render("  No saved presets"))
+	}
+	for i, p := range m.presets {
+		line := "  " + p.Name
+		if i == m.presetCursor {
+			line = cursorStyle().Render("> " + p.Name)
+		}
+		lines = append(lines, baseStyle().Render(line))
+	}
+
+	hint := hintStyle().Render("↑↓ navigate · enter load · d delete · esc back")
+	return title + "\n\n" + strings.Join(lines, "\n") + "\n\n" + hint
+}
+
+func (m *Model) viewSave() string {
+	title := titleStyle().Render("Save Equalizer Preset")
+	prompt := baseStyle().Render("Name: ") + baseStyle().Render(m.input+"█")
+	hint := hintStyle().Render("enter save · esc cancel")
+	return title + "\n\n" + prompt + "\n\n" + hint
+}
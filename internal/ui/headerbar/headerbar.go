@@ -2,6 +2,7 @@
 package headerbar
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -37,6 +38,9 @@ var baseTabs = []tab{
 // downloadsTab is shown when slskd is configured.
 var downloadsTab = tab{"F4", "Downloads", "downloads"}
 
+// historyTab is always shown, after the downloads tab.
+var historyTab = tab{"F5", "History", "history"}
+
 // LibrarySubMode represents which library view mode is active.
 type LibrarySubMode int
 
@@ -47,7 +51,7 @@ const (
 )
 
 // Render returns the header bar string for the given width.
-// currentMode should be "library", "file", "playlists", or "downloads".
+// currentMode should be "library", "file", "playlists", "downloads", or "history".
 // showDownloads controls whether the F4 Downloads tab is shown.
 // librarySubMode indicates which library sub-mode is active (only shown when in library view).
 func Render(currentMode string, width int, showDownloads bool, librarySubMode LibrarySubMode) string {
@@ -112,10 +116,11 @@ func renderTabs(currentMode string, showDownloads bool, librarySubMode LibrarySu
 	t := styles.T()
 
 	// Build tab list
-	tabs := baseTabs
+	tabs := slices.Clone(baseTabs)
 	if showDownloads {
 		tabs = append(tabs, downloadsTab)
 	}
+	tabs = append(tabs, historyTab)
 
 	parts := make([]string, 0, len(tabs))
 
@@ -29,6 +29,7 @@ var categoryOrder = []string{
 	"queue",
 	"playlist",
 	"playlist-track",
+	"history",
 }
 
 // categoryLabels maps context names to display labels.
@@ -42,6 +43,7 @@ var categoryLabels = map[string]string{
 	"queue":          "Queue Panel",
 	"playlist":       "Playlist",
 	"playlist-track": "Playlist Tracks",
+	"history":        "History",
 }
 
 // Model holds the state for the help bindings popup.
@@ -0,0 +1,35 @@
+package history
+
+import (
+	"github.com/llehouerou/waves/internal/history"
+	"github.com/llehouerou/waves/internal/ui/action"
+)
+
+// PlayNow requests adding tracks to the queue and playing the first one.
+type PlayNow struct {
+	Plays []history.Play
+}
+
+// ActionType implements action.Action.
+func (a PlayNow) ActionType() string { return "history.play_now" }
+
+// AddToQueue requests adding tracks to the queue.
+type AddToQueue struct {
+	Plays []history.Play
+}
+
+// ActionType implements action.Action.
+func (a AddToQueue) ActionType() string { return "history.add_to_queue" }
+
+// Locate requests locating a played track in the library.
+type Locate struct {
+	Play history.Play
+}
+
+// ActionType implements action.Action.
+func (a Locate) ActionType() string { return "history.locate" }
+
+// ActionMsg creates an action.Msg for a history view action.
+func ActionMsg(a action.Action) action.Msg {
+	return action.Msg{Source: "history", Action: a}
+}
@@ -0,0 +1,152 @@
+package history
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/history"
+	"github.com/llehouerou/waves/internal/ui/action"
+	"github.com/llehouerou/waves/internal/ui/testutil"
+)
+
+// samplePlays returns two plays today and one yesterday, most recent first.
+func samplePlays() []history.Play {
+	now := time.Now()
+	yesterday := now.AddDate(0, 0, -1)
+	return []history.Play{
+		{ID: 3, TrackID: 30, Path: "/c.flac", Title: "Third", Artist: "Artist", StartedAt: now, Completed: true},
+		{ID: 2, Path: "/b.flac", Title: "Second", StartedAt: now, Played: 75 * time.Second},
+		{ID: 1, TrackID: 10, Path: "/a.flac", Title: "First", Artist: "Artist", StartedAt: yesterday, Completed: true},
+	}
+}
+
+func newFocused() Model {
+	m := New()
+	m.SetSize(80, 20)
+	m.SetFocused(true)
+	m.SetPlays(samplePlays())
+	return m
+}
+
+func sendKey(m *Model, key string) {
+	*m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+}
+
+func getAction(t *testing.T, m *Model, msg tea.Msg) action.Action {
+	t.Helper()
+	var cmd tea.Cmd
+	*m, cmd = m.Update(msg)
+	if cmd == nil {
+		t.Fatal("expected command, got nil")
+	}
+	actionMsg, ok := testutil.ExecuteCmd(cmd).(action.Msg)
+	if !ok {
+		t.Fatal("expected action.Msg")
+	}
+	return actionMsg.Action
+}
+
+func TestHistory_GroupsByDay(t *testing.T) {
+	m := newFocused()
+
+	items := m.list.Items()
+	if len(items) != 5 {
+		t.Fatalf("rows = %d, want 5 (2 headers + 3 plays)", len(items))
+	}
+	if items[0].day != "Today" || len(items[0].plays) != 2 {
+		t.Errorf("first header = %q with %d plays, want Today with 2", items[0].day, len(items[0].plays))
+	}
+	if items[3].day != "Yesterday" || len(items[3].plays) != 1 {
+		t.Errorf("second header = %q with %d plays, want Yesterday with 1", items[3].day, len(items[3].plays))
+	}
+	if m.SelectedPlay() != nil {
+		t.Error("SelectedPlay() on a header should be nil")
+	}
+	sendKey(&m, "j")
+	if p := m.SelectedPlay(); p == nil || p.ID != 3 {
+		t.Errorf("SelectedPlay() = %v, want play 3", p)
+	}
+}
+
+func TestHistory_EnterPlaysSelection(t *testing.T) {
+	m := newFocused()
+
+	// On a header, the plays of the day in the order they were played
+	a := getAction(t, &m, tea.KeyMsg{Type: tea.KeyEnter})
+	play, ok := a.(PlayNow)
+	if !ok {
+		t.Fatalf("action = %T, want PlayNow", a)
+	}
+	if len(play.Plays) != 2 || play.Plays[0].ID != 2 || play.Plays[1].ID != 3 {
+		t.Errorf("PlayNow.Plays = %+v, want plays 2 then 3", play.Plays)
+	}
+
+	sendKey(&m, "j")
+	a = getAction(t, &m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	add, ok := a.(AddToQueue)
+	if !ok || len(add.Plays) != 1 || add.Plays[0].ID != 3 {
+		t.Errorf("action = %+v, want AddToQueue with play 3", a)
+	}
+}
+
+func TestHistory_Locate(t *testing.T) {
+	m := newFocused()
+
+	// Nothing to locate on a header
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("L")})
+	if cmd != nil {
+		t.Error("L on a header should do nothing")
+	}
+
+	sendKey(&m, "j")
+	a := getAction(t, &m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("L")})
+	if loc, ok := a.(Locate); !ok || loc.Play.TrackID != 30 {
+		t.Errorf("action = %+v, want Locate of track 30", a)
+	}
+}
+
+func TestHistory_IgnoresKeysWhenUnfocused(t *testing.T) {
+	m := newFocused()
+	m.SetFocused(false)
+
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+		t.Error("unfocused view should not emit actions")
+	}
+}
+
+func TestHistory_View(t *testing.T) {
+	m := newFocused()
+	view := m.View()
+
+	for _, want := range []string{"History (3 plays)", "Today", "Yesterday", "Artist - Third", "Second", "1:15"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view should contain %q", want)
+		}
+	}
+
+	m.SetPlays(nil)
+	if !strings.Contains(m.View(), "Nothing played yet") {
+		t.Error("empty view should say nothing was played")
+	}
+}
+
+func TestDayLabel(t *testing.T) {
+	now := time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{now.Add(-time.Hour), "Today"},
+		{time.Date(2024, 5, 9, 23, 0, 0, 0, time.UTC), "Yesterday"},
+		{time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), "Wednesday 1 May"},
+		{time.Date(2023, 12, 31, 12, 0, 0, 0, time.UTC), "Sunday 31 December 2023"},
+	}
+	for _, tt := range tests {
+		if got := dayLabel(tt.t, now); got != tt.want {
+			t.Errorf("dayLabel(%v) = %q, want %q", tt.t, got, tt.want)
+		}
+	}
+}
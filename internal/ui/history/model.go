@@ -0,0 +1,129 @@
+// Package history provides the History view listing recently played tracks,
+// grouped by day.
+package history
+
+import (
+	"time"
+
+	"github.com/llehouerou/waves/internal/history"
+	"github.com/llehouerou/waves/internal/ui"
+	"github.com/llehouerou/waves/internal/ui/list"
+)
+
+// row is a line of the view: a day header or a play.
+type row struct {
+	day   string // Day label, set on header rows only
+	plays []history.Play
+}
+
+// isHeader returns true if the row is a day header.
+func (r row) isHeader() bool {
+	return r.day != ""
+}
+
+// Model represents the history view state.
+type Model struct {
+	list  list.Model[row]
+	count int // Number of plays
+}
+
+// New creates a new history view model.
+func New() Model {
+	return Model{
+		list: list.New[row](2), // Small scroll margin
+	}
+}
+
+// SetFocused sets whether the component is focused.
+func (m *Model) SetFocused(focused bool) {
+	m.list.SetFocused(focused)
+}
+
+// IsFocused returns whether the component is focused.
+func (m Model) IsFocused() bool {
+	return m.list.IsFocused()
+}
+
+// SetSize sets the component dimensions.
+func (m *Model) SetSize(width, height int) {
+	m.list.SetSize(width, height)
+}
+
+// Width returns the component width.
+func (m Model) Width() int {
+	return m.list.Width()
+}
+
+// Height returns the component height.
+func (m Model) Height() int {
+	return m.list.Height()
+}
+
+// SetPlays updates the list of plays, most recent first. Plays are grouped
+// under a header for each day, which holds the plays of that day.
+func (m *Model) SetPlays(plays []history.Play) {
+	m.count = len(plays)
+	now := time.Now()
+	var rows []row
+	header := -1
+	for _, p := range plays {
+		day := dayLabel(p.StartedAt, now)
+		if header < 0 || rows[header].day != day {
+			header = len(rows)
+			rows = append(rows, row{day: day})
+		}
+		rows[header].plays = append(rows[header].plays, p)
+		rows = append(rows, row{plays: []history.Play{p}})
+	}
+	m.list.SetItems(rows)
+}
+
+// IsEmpty returns true if there are no plays.
+func (m Model) IsEmpty() bool {
+	return m.count == 0
+}
+
+// SelectedPlay returns the play under the cursor, or nil on a day header.
+func (m Model) SelectedPlay() *history.Play {
+	r, ok := m.list.Selected()
+	if !ok || r.isHeader() {
+		return nil
+	}
+	return &r.plays[0]
+}
+
+// selectedPlays returns the plays of the row under the cursor, oldest first.
+func (m Model) selectedPlays() []history.Play {
+	r, ok := m.list.Selected()
+	if !ok {
+		return nil
+	}
+	plays := make([]history.Play, len(r.plays))
+	for i, p := range r.plays {
+		plays[len(plays)-1-i] = p
+	}
+	return plays
+}
+
+// listHeight returns the available height for the history list.
+func (m Model) listHeight() int {
+	return m.list.ListHeight(ui.PanelOverhead)
+}
+
+// dayLabel returns the header of the day a play started on.
+func dayLabel(t, now time.Time) string {
+	y, mo, d := t.Date()
+	day := time.Date(y, mo, d, 0, 0, 0, 0, t.Location())
+	ny, nmo, nd := now.In(t.Location()).Date()
+	today := time.Date(ny, nmo, nd, 0, 0, 0, 0, t.Location())
+	switch {
+	case day.Equal(today):
+		return "Today"
+	case day.Equal(today.AddDate(0, 0, -1)):
+		return "Yesterday"
+	case y == ny:
+		return t.Format("Monday 2 January")
+	default:
+		return t.Format("Monday 2 January 2006")
+	}
+}
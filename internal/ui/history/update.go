@@ -0,0 +1,43 @@
+package history
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/ui/list"
+)
+
+// Update handles messages for the history view.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	// Delegate to list for common handling (navigation, enter, mouse)
+	result := m.list.Update(msg, m.list.Len())
+	switch result.Action { //nolint:exhaustive // Only handling specific actions
+	case list.ActionEnter, list.ActionMiddleClick:
+		if plays := m.selectedPlays(); len(plays) > 0 {
+			return m, func() tea.Msg {
+				return ActionMsg(PlayNow{Plays: plays})
+			}
+		}
+		return m, nil
+	}
+
+	// Handle custom keys (only if focused)
+	if key, ok := msg.(tea.KeyMsg); ok && m.IsFocused() {
+		switch key.String() {
+		case "a":
+			if plays := m.selectedPlays(); len(plays) > 0 {
+				return m, func() tea.Msg {
+					return ActionMsg(AddToQueue{Plays: plays})
+				}
+			}
+		case "L":
+			if p := m.SelectedPlay(); p != nil {
+				play := *p
+				return m, func() tea.Msg {
+					return ActionMsg(Locate{Play: play})
+				}
+			}
+		}
+	}
+
+	return m, nil
+}
@@ -0,0 +1,166 @@
+package history
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/llehouerou/waves/internal/history"
+	"github.com/llehouerou/waves/internal/ui"
+	"github.com/llehouerou/waves/internal/ui/render"
+	"github.com/llehouerou/waves/internal/ui/styles"
+)
+
+// Symbols for play outcomes
+const (
+	completedSymbol = "\u2713" // ✓
+	skippedSymbol   = "\u21B7" // ↷
+)
+
+func headerStyle() lipgloss.Style {
+	return styles.T().S().Title
+}
+
+func dayStyle() lipgloss.Style {
+	return styles.T().S().Playing.Bold(true)
+}
+
+func playStyle() lipgloss.Style {
+	return styles.T().S().Base
+}
+
+func cursorStyle() lipgloss.Style {
+	return styles.T().S().Cursor
+}
+
+func completedStyle() lipgloss.Style {
+	return styles.T().S().Success
+}
+
+func skippedStyle() lipgloss.Style {
+	return styles.T().S().Subtle
+}
+
+func emptyStyle() lipgloss.Style {
+	return styles.T().S().Subtle.Italic(true)
+}
+
+// View renders the history view.
+func (m Model) View() string {
+	if m.Width() == 0 || m.Height() == 0 {
+		return ""
+	}
+
+	innerWidth := m.Width() - ui.BorderHeight
+	innerHeight := m.Height() - ui.BorderHeight // Account for top/bottom border
+	listHeight := m.listHeight()
+
+	header := m.renderHeader(innerWidth)
+	separator := render.Separator(innerWidth)
+	content := header + "\n" + separator + "\n" + m.renderList(innerWidth, listHeight)
+
+	return styles.PanelStyle(m.IsFocused()).
+		Width(innerWidth).
+		Height(innerHeight).
+		Render(content)
+}
+
+// renderHeader renders the history header.
+func (m Model) renderHeader(innerWidth int) string {
+	headerText := "History"
+	if m.count > 0 {
+		headerText = fmt.Sprintf("History (%d plays)", m.count)
+	}
+	return headerStyle().Render(render.TruncateAndPad(headerText, innerWidth))
+}
+
+// renderList renders the visible rows.
+func (m Model) renderList(innerWidth, listHeight int) string {
+	if m.list.Len() == 0 {
+		return m.renderEmptyState(innerWidth, listHeight)
+	}
+
+	items := m.list.Items()
+	start, end := m.list.VisibleRange(ui.PanelOverhead)
+	lines := make([]string, 0, listHeight)
+	for i := start; i < end; i++ {
+		lines = append(lines, m.renderRow(items[i], i, innerWidth))
+	}
+
+	// Fill remaining lines
+	for len(lines) < listHeight {
+		lines = append(lines, render.EmptyLine(innerWidth))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// renderEmptyState renders the empty history view.
+func (m Model) renderEmptyState(innerWidth, listHeight int) string {
+	centerLine := listHeight / 2
+	lines := make([]string, listHeight)
+	for i := range lines {
+		if i == centerLine {
+			centered := styles.T().BaseStyle().Width(innerWidth).Align(lipgloss.Center).Render("Nothing played yet")
+			lines[i] = emptyStyle().Render(centered)
+		} else {
+			lines[i] = render.EmptyLine(innerWidth)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderRow renders a day header or a play.
+func (m Model) renderRow(r row, idx, width int) string {
+	isCursor := idx == m.list.Cursor().Pos() && m.IsFocused()
+
+	var line string
+	if r.isHeader() {
+		count := fmt.Sprintf("%d plays", len(r.plays))
+		line = render.Row(dayStyle().Render(r.day), skippedStyle().Render(count), width)
+	} else {
+		line = renderPlay(&r.plays[0], width)
+	}
+
+	if isCursor {
+		return cursorStyle().Width(width).Render(line)
+	}
+	return playStyle().Render(line)
+}
+
+// renderPlay renders a play as: time, artist - title, and how it ended.
+func renderPlay(p *history.Play, width int) string {
+	var status string
+	if p.Completed {
+		status = completedStyle().Render(completedSymbol)
+	} else {
+		status = skippedStyle().Render(skippedSymbol + " " + formatPlayed(p.Played))
+	}
+
+	prefix := "  " + p.StartedAt.Format("15:04") + "  "
+	contentWidth := max(width-lipgloss.Width(prefix)-lipgloss.Width(status)-1, 0)
+	content := render.TruncateAndPadEllipsis(playTitle(p), contentWidth)
+
+	return prefix + content + render.EmptyLine(1) + status
+}
+
+// playTitle returns "Artist - Title", or the file name for untagged tracks.
+func playTitle(p *history.Play) string {
+	title := p.Title
+	if title == "" {
+		title = filepath.Base(p.Path)
+	}
+	if p.Artist == "" {
+		return title
+	}
+	return p.Artist + " - " + title
+}
+
+// formatPlayed formats how long a skipped track played, as m:ss.
+func formatPlayed(d time.Duration) string {
+	total := int(d.Seconds())
+	return fmt.Sprintf("%d:%02d", total/60, total%60)
+}
@@ -8,7 +8,18 @@ func (m *Model) Refresh() error {
 	}
 	m.artists = artists
 	m.artistCursor.ClampToBounds(len(m.artists))
+
+	// Keep the track selection while the same album stays selected
+	var prevAlbum string
+	if album := m.SelectedAlbum(); album != nil {
+		prevAlbum = album.Name
+	}
+	trackCursor := m.trackCursor
 	m.loadAlbumsForSelectedArtist()
+	if album := m.SelectedAlbum(); album != nil && album.Name == prevAlbum {
+		m.trackCursor = trackCursor
+		m.trackCursor.ClampToBounds(len(m.tracks))
+	}
 	return nil
 }
 
@@ -46,6 +46,11 @@ func setupTestDB(t *testing.T) *sql.DB {
 			original_date TEXT,
 			release_date TEXT,
 			label TEXT,
+			play_count INTEGER NOT NULL DEFAULT 0,
+			skip_count INTEGER NOT NULL DEFAULT 0,
+			last_played_at INTEGER,
+			rating INTEGER NOT NULL DEFAULT 0,
+			album_rating INTEGER NOT NULL DEFAULT 0,
 			added_at INTEGER NOT NULL,
 			updated_at INTEGER NOT NULL
 		);
@@ -171,6 +176,18 @@ func TestRefresh_LoadsTracksForFirstAlbum(t *testing.T) {
 	}
 }
 
+func TestRefresh_KeepsTrackSelection(t *testing.T) {
+	m := newTestBrowser(t)
+	m.trackCursor.SetPos(1)
+
+	if err := m.Refresh(); err != nil {
+		t.Fatalf("Refresh() failed: %v", err)
+	}
+	if m.trackCursor.Pos() != 1 {
+		t.Errorf("track cursor pos = %d, want 1 after refresh", m.trackCursor.Pos())
+	}
+}
+
 // --- Selection accessors ---
 
 func TestSelectedArtist(t *testing.T) {
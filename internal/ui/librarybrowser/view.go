@@ -9,6 +9,7 @@ import (
 	"github.com/mattn/go-runewidth"
 
 	"github.com/llehouerou/waves/internal/icons"
+	"github.com/llehouerou/waves/internal/library"
 	"github.com/llehouerou/waves/internal/ui/render"
 	"github.com/llehouerou/waves/internal/ui/styles"
 )
@@ -165,6 +166,9 @@ func (m Model) renderAlbumItems(width, height int) []string {
 		if album.Year > 0 {
 			yearStr = strconv.Itoa(album.Year)
 		}
+		if stars := icons.Rating(album.Rating); stars != "" {
+			yearStr = strings.TrimSpace(stars + " " + yearStr)
+		}
 
 		// Reserve space: prefix(2) + name + gap(1) + year + trailing(1)
 		yearWidth := runewidth.StringWidth(yearStr)
@@ -199,8 +203,6 @@ func (m Model) renderAlbumItems(width, height int) []string {
 // renderTrackItems renders the track list items.
 func (m Model) renderTrackItems(width, height int) []string {
 	isActive := m.activeColumn == ColumnTracks
-	favIcon := icons.Favorite()
-	favIconWidth := runewidth.StringWidth(favIcon)
 	lines := make([]string, height)
 
 	for i := range height {
@@ -213,12 +215,13 @@ func (m Model) renderTrackItems(width, height int) []string {
 		isCursor := idx == m.trackCursor.Pos()
 		track := m.tracks[idx]
 		name := fmt.Sprintf("%02d. %s", track.TrackNumber, track.Title)
-		isFavorite := m.favorites[track.ID]
+		badge := m.trackBadge(track)
+		badgeWidth := runewidth.StringWidth(badge)
 
-		// Reserve space for prefix and optional favorite icon
+		// Reserve space for prefix and optional rating and favorite icon
 		maxNameWidth := width - 2 // 2 for prefix
-		if isFavorite {
-			maxNameWidth -= favIconWidth + 1
+		if badge != "" {
+			maxNameWidth -= badgeWidth + 1
 		}
 		name = render.Truncate(name, maxNameWidth)
 
@@ -228,13 +231,13 @@ func (m Model) renderTrackItems(width, height int) []string {
 		}
 
 		line := prefix + name
-		if isFavorite {
+		if badge != "" {
 			currentWidth := runewidth.StringWidth(line)
-			padding := width - currentWidth - favIconWidth
+			padding := width - currentWidth - badgeWidth
 			if padding > 0 {
-				line = line + strings.Repeat(" ", padding) + favIcon
+				line = line + strings.Repeat(" ", padding) + badge
 			} else {
-				line = render.Pad(line, width-favIconWidth) + favIcon
+				line = render.Pad(line, width-badgeWidth) + badge
 			}
 		} else {
 			line = render.Pad(line, width)
@@ -245,3 +248,16 @@ func (m Model) renderTrackItems(width, height int) []string {
 
 	return lines
 }
+
+// trackBadge returns the rating and favorite icon shown at the right of a
+// track, or an empty string.
+func (m Model) trackBadge(track library.Track) string {
+	var parts []string
+	if stars := icons.Rating(track.Rating); stars != "" {
+		parts = append(parts, stars)
+	}
+	if m.favorites[track.ID] {
+		parts = append(parts, icons.Favorite())
+	}
+	return strings.Join(parts, " ")
+}
@@ -4,11 +4,11 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/llehouerou/waves/internal/icons"
+	"github.com/llehouerou/waves/internal/player"
 	"github.com/llehouerou/waves/internal/ui"
 	"github.com/llehouerou/waves/internal/ui/render"
 	"github.com/llehouerou/waves/internal/ui/styles"
@@ -27,14 +27,10 @@ func RenderExpanded(s State, width int) string {
 	innerWidth := max(width-6, 0)
 	if innerWidth < ui.MinExpandedWidth {
 		// Too narrow, fall back to compact
-		return Render(s, width)
+		return renderCompact(s, width)
 	}
 
-	// Calculate width available for text content
-	textWidth := innerWidth
-	if s.HasAlbumArt {
-		textWidth = innerWidth - AlbumArtWidth - 2
-	}
+	textWidth, vizWidth := expandedWidths(s, innerWidth)
 
 	lines := make([]string, 0, 4)
 
@@ -50,7 +46,7 @@ func RenderExpanded(s State, width int) string {
 	metaMaxWidth := textWidth * 45 / 100
 	formatInfo := ""
 	if s.Format != "" {
-		formatInfo = formatAudioInfo(s.Format, s.SampleRate, s.BitDepth)
+		formatInfo = formatAudioInfo(s.Format, s.SampleRate, s.BitDepth, s.OutputSampleRate)
 	}
 
 	var metaLine string
@@ -112,13 +108,25 @@ func RenderExpanded(s State, width int) string {
 	}
 	trackInfo := strings.Join(trackParts, " · ")
 
-	// Line 3: Radio indicator (right-aligned) or empty spacer
-	radioLine := ""
+	// Line 3: Speed, sleep timer and normalization status (left) | Radio indicator (right), or empty spacer
+	statusLine := ""
+	var statusParts []string
+	if speed := formatSpeed(s.Speed); speed != "" {
+		statusParts = append(statusParts, "Speed "+speed)
+	}
+	if sleep := formatSleep(s); sleep != "" {
+		statusParts = append(statusParts, sleep)
+	}
+	if gain := formatReplayGain(s.ReplayGain); gain != "" {
+		statusParts = append(statusParts, gain)
+	}
+	statusLabel := strings.Join(statusParts, " · ")
+	radioLabel := ""
 	if s.RadioEnabled {
-		radioLabel := radioStyle().Render(icons.Radio() + " Radio on")
-		radioLabelWidth := lipgloss.Width(radioLabel)
-		padding := max(textWidth-radioLabelWidth, 0)
-		radioLine = render.EmptyLine(padding) + radioLabel
+		radioLabel = radioStyle().Render(icons.Radio() + " Radio on")
+	}
+	if statusLabel != "" || radioLabel != "" {
+		statusLine = renderRow(metaStyle().Render(statusLabel), radioLabel, textWidth)
 	}
 
 	lines = append(lines,
@@ -127,15 +135,19 @@ func RenderExpanded(s State, width int) string {
 			metaStyle().Render(trackInfo),
 			textWidth,
 		),
-		radioLine,
+		statusLine,
 	)
 
 	// Line 4: Progress bar + volume indicator
 	volumeStr := RenderVolumeCompact(s.Volume, s.Muted)
 	volumeWidth := lipgloss.Width(volumeStr)
-	progressBar := renderStyledProgressBar(s.Position, s.Duration, textWidth-volumeWidth-2, s.Playing)
+	progressBar := renderStyledProgressBar(s, textWidth-volumeWidth-2)
 	lines = append(lines, progressBar+render.EmptyLine(2)+volumeStr)
 
+	if vizWidth > 0 {
+		lines = joinVisualizer(lines, textWidth, s.Visualizer, vizWidth)
+	}
+
 	textContent := strings.Join(lines, "\n")
 
 	// Combine album art and text content
@@ -149,6 +161,22 @@ func RenderExpanded(s State, width int) string {
 	return expandedBarStyle().Width(width - 2).Render(content)
 }
 
+// expandedWidths returns the width available for text content, and the
+// width of the visualizer (0 when hidden).
+func expandedWidths(s State, innerWidth int) (textWidth, vizWidth int) {
+	textWidth = innerWidth
+	if s.HasAlbumArt {
+		textWidth = innerWidth - AlbumArtWidth - 2
+	}
+	if s.Visualizer != nil {
+		vizWidth = visualizerWidth(textWidth)
+		if vizWidth > 0 {
+			textWidth -= vizWidth + 2
+		}
+	}
+	return textWidth, vizWidth
+}
+
 // renderRow creates a row with left and right aligned content.
 func renderRow(left, right string, width int) string {
 	return render.Row(left, right, width)
@@ -170,56 +198,82 @@ func formatGenre(genre string) string {
 	return strings.TrimSpace(result)
 }
 
-func formatAudioInfo(format string, sampleRate, bitDepth int) string {
+// formatAudioInfo describes the source format, e.g. "FLAC 96 kHz 24-bit".
+// The output rate is appended when the track is resampled, e.g. "→ 48 kHz".
+func formatAudioInfo(format string, sampleRate, bitDepth, outputRate int) string {
 	var parts []string
 	parts = append(parts, format)
 
 	if sampleRate > 0 {
-		khz := float64(sampleRate) / 1000.0
-		if khz == float64(int(khz)) {
-			parts = append(parts, fmt.Sprintf("%d kHz", int(khz)))
-		} else {
-			parts = append(parts, fmt.Sprintf("%.1f kHz", khz))
-		}
+		parts = append(parts, formatKHz(sampleRate))
 	}
 
 	if bitDepth > 0 && format != "MP3" {
 		parts = append(parts, fmt.Sprintf("%d-bit", bitDepth))
 	}
 
+	if outputRate > 0 && sampleRate > 0 && outputRate != sampleRate {
+		parts = append(parts, "→ "+formatKHz(outputRate))
+	}
+
 	return strings.Join(parts, " ")
 }
 
-func renderStyledProgressBar(position, duration time.Duration, width int, playing bool) string {
+// formatKHz formats a sample rate, e.g. "44.1 kHz".
+func formatKHz(rate int) string {
+	khz := float64(rate) / 1000.0
+	if khz == float64(int(khz)) {
+		return fmt.Sprintf("%d kHz", int(khz))
+	}
+	return fmt.Sprintf("%.1f kHz", khz)
+}
+
+// formatReplayGain describes the applied normalization, e.g. "RG auto (album) -6.2 dB".
+// Returns an empty string when normalization is off.
+func formatReplayGain(rg player.ReplayGainStatus) string {
+	if rg.Mode == player.ReplayGainOff {
+		return ""
+	}
+	label := "RG " + rg.Mode.String()
+	if rg.Applied == player.ReplayGainOff {
+		return label + " · no tags"
+	}
+	if rg.Mode == player.ReplayGainAuto {
+		label += " (" + rg.Applied.String() + ")"
+	}
+	return fmt.Sprintf("%s %+.1f dB", label, rg.GainDB)
+}
+
+func renderStyledProgressBar(s State, width int) string {
 	status := playSymbol()
-	if !playing {
+	if !s.Playing {
 		status = pauseSymbol()
 	}
 
-	posStr := formatDuration(position)
-	durStr := formatDuration(duration)
+	posStr := formatDuration(s.Position)
+	durStr := durationLabel(s)
 	sp2 := render.EmptyLine(2)
 
-	// Calculate space for the bar itself
-	// Format: "▶  1:23  ━━━━━───  4:56"
-	fixedWidth := lipgloss.Width(status) + 2 + lipgloss.Width(posStr) + 2 + 2 + lipgloss.Width(durStr)
-	barWidth := width - fixedWidth
-
+	_, barWidth := styledProgressBarLayout(s, width)
 	if barWidth < 5 {
 		// Too narrow for bar, just show times
 		return styles.T().Bg(status) + sp2 + progressTimeStyle().Render(posStr+" / "+durStr)
 	}
 
-	// Calculate filled portion
-	var ratio float64
-	if duration > 0 {
-		ratio = float64(position) / float64(duration)
-	}
-	filled := min(int(float64(barWidth)*ratio), barWidth)
-
-	// Use thin bar characters for modern look
-	filledBar := progressBarFilled().Render(strings.Repeat("━", filled))
-	emptyBar := progressBarEmpty().Render(strings.Repeat("─", barWidth-filled))
+	bar := renderSeekBar(s, barWidth)
+	return styles.T().Bg(status) + sp2 + progressTimeStyle().Render(posStr) + sp2 + bar + sp2 + progressTimeStyle().Render(durStr)
+}
 
-	return styles.T().Bg(status) + sp2 + progressTimeStyle().Render(posStr) + sp2 + filledBar + emptyBar + sp2 + progressTimeStyle().Render(durStr)
+// styledProgressBarLayout returns the width before the bar of
+// renderStyledProgressBar and the width of the bar itself.
+// Format: "▶  1:23  ━━━━━───  4:56"
+func styledProgressBarLayout(s State, width int) (offset, barWidth int) {
+	status := playSymbol()
+	if !s.Playing {
+		status = pauseSymbol()
+	}
+	posWidth := lipgloss.Width(formatDuration(s.Position))
+	durWidth := lipgloss.Width(durationLabel(s))
+	offset = lipgloss.Width(status) + 2 + posWidth + 2
+	return offset, width - offset - 2 - durWidth
 }
@@ -2,6 +2,7 @@ package playerbar
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -12,6 +13,8 @@ import (
 	"github.com/llehouerou/waves/internal/player"
 	"github.com/llehouerou/waves/internal/ui/render"
 	"github.com/llehouerou/waves/internal/ui/styles"
+	"github.com/llehouerou/waves/internal/visualizer"
+	"github.com/llehouerou/waves/internal/waveform"
 )
 
 // DisplayMode controls the player bar appearance.
@@ -36,10 +39,12 @@ type State struct {
 	Year                int
 	Position            time.Duration
 	Duration            time.Duration
+	Live                bool // A stream: no duration, no seeking
 	DisplayMode         DisplayMode
 	Genre               string
 	Format              string  // "MP3" or "FLAC"
 	SampleRate          int     // e.g., 44100
+	OutputSampleRate    int     // Rate the output runs at, differs when resampling
 	BitDepth            int     // e.g., 16, 24
 	RadioEnabled        bool    // Radio mode is active
 	TrackPath           string  // Path to current track (for album art extraction)
@@ -47,6 +52,15 @@ type State struct {
 	HasAlbumArt         bool    // Whether album art is available for placement
 	Volume              float64 // 0.0 to 1.0
 	Muted               bool
+	ReplayGain          player.ReplayGainStatus // Loudness normalization applied to the track
+	Speed               float64                 // Playback speed, 1 (or 0) is normal speed
+	Sleep               bool                    // The sleep timer is set
+	SleepLeft           time.Duration           // Until the sleep timer pauses, 0 when unknown
+	LoopA               time.Duration           // Start of the A-B loop
+	LoopB               time.Duration           // End of the A-B loop, 0 while only its start is set
+	Bookmarks           []time.Duration         // Positions of the bookmarks of the track
+	Visualizer          *visualizer.Analyzer    // Spectrum and levels, shown in the expanded view when set
+	Waveform            *waveform.Waveform      // Drawn as the seek bar when set
 }
 
 // Height returns the total height of the player bar for the given mode.
@@ -70,26 +84,30 @@ func NewState(p player.Interface, mode DisplayMode) State {
 	}
 
 	return State{
-		Playing:     p.State() == player.Playing,
-		Paused:      p.State() == player.Paused,
-		Track:       info.TrackNumber,
-		TotalTracks: info.TotalTracks,
-		Disc:        info.DiscNumber,
-		TotalDiscs:  info.TotalDiscs,
-		Title:       info.Title,
-		Artist:      info.Artist,
-		Album:       info.Album,
-		Year:        info.Year(),
-		Position:    p.Position(),
-		Duration:    p.Duration(),
-		DisplayMode: mode,
-		Genre:       info.Genre,
-		Format:      info.Format,
-		SampleRate:  info.SampleRate,
-		BitDepth:    info.BitDepth,
-		TrackPath:   info.Path,
-		Volume:      p.Volume(),
-		Muted:       p.Muted(),
+		Playing:          p.State() == player.Playing,
+		Paused:           p.State() == player.Paused,
+		Track:            info.TrackNumber,
+		TotalTracks:      info.TotalTracks,
+		Disc:             info.DiscNumber,
+		TotalDiscs:       info.TotalDiscs,
+		Title:            info.Title,
+		Artist:           info.Artist,
+		Album:            info.Album,
+		Year:             info.Year(),
+		Position:         p.Position(),
+		Duration:         p.Duration(),
+		Live:             !p.Seekable(),
+		DisplayMode:      mode,
+		Genre:            info.Genre,
+		Format:           info.Format,
+		SampleRate:       info.SampleRate,
+		OutputSampleRate: p.OutputSampleRate(),
+		BitDepth:         info.BitDepth,
+		TrackPath:        info.Path,
+		Volume:           p.Volume(),
+		Muted:            p.Muted(),
+		ReplayGain:       p.ReplayGain(),
+		Speed:            p.Speed(),
 	}
 }
 
@@ -108,6 +126,22 @@ func Render(s State, width int) string {
 }
 
 func renderCompact(s State, width int) string {
+	l := layoutCompact(s, width)
+	content := l.prefix + renderSeekBar(s, l.barWidth) + l.suffix
+	return barStyle().Padding(0, 2).Width(width - 2).Render(content)
+}
+
+// compactLine is the single line of the compact view, split around the
+// seek bar.
+type compactLine struct {
+	prefix   string // Title, info, track number and status before the bar
+	barWidth int
+	suffix   string // Time and volume after the bar
+}
+
+// layoutCompact lays out the line of the compact view:
+// Title   Info   3/12   󰐹 ▶ ━━━───   1:23 / 3:58
+func layoutCompact(s State, width int) compactLine {
 	// Calculate available width (subtract border and padding)
 	innerWidth := max(width-6, 0)
 
@@ -158,7 +192,13 @@ func renderCompact(s State, width int) string {
 	trackNum := strings.Join(trackParts, " · ")
 
 	// Time display
-	timeStr := fmt.Sprintf("%s / %s", formatDuration(s.Position), formatDuration(s.Duration))
+	timeStr := fmt.Sprintf("%s / %s", formatDuration(s.Position), durationLabel(s))
+	if speed := formatSpeed(s.Speed); speed != "" {
+		timeStr += " · " + speed
+	}
+	if sleep := formatSleep(s); sleep != "" {
+		timeStr += " · " + sleep
+	}
 
 	// Volume indicator
 	volumeStr := RenderVolumeCompact(s.Volume, s.Muted)
@@ -212,44 +252,62 @@ func renderCompact(s State, width int) string {
 	// Calculate progress bar width (use remaining space, accounting for volume)
 	barWidth := max(innerWidth-usedContentWidth-trackNumSpace-statusWidth-timeWidth-volumeWidth-sepWidth*3, 5)
 
-	// Build progress bar
-	var ratio float64
-	if s.Duration > 0 {
-		ratio = float64(s.Position) / float64(s.Duration)
-	}
-	filled := min(int(float64(barWidth)*ratio), barWidth)
-	filledBar := progressBarFilled().Render(strings.Repeat("━", filled))
-	emptyBar := progressBarEmpty().Render(strings.Repeat("─", barWidth-filled))
-
-	// Build the line: Title   Info   3/12   󰐹 ▶ ━━━───   1:23 / 3:58
-	var content strings.Builder
-	content.WriteString(styledTitle)
+	var prefix strings.Builder
+	prefix.WriteString(styledTitle)
 	if styledInfo != "" {
-		content.WriteString(separator)
-		content.WriteString(styledInfo)
+		prefix.WriteString(separator)
+		prefix.WriteString(styledInfo)
 	}
 	if trackNum != "" {
-		content.WriteString(separator)
-		content.WriteString(metaStyle().Render(trackNum))
+		prefix.WriteString(separator)
+		prefix.WriteString(metaStyle().Render(trackNum))
+	}
+	prefix.WriteString(separator)
+	prefix.WriteString(radioIndicator)
+	prefix.WriteString(styles.T().Bg(status))
+	prefix.WriteString(render.EmptyLine(2))
+
+	return compactLine{
+		prefix:   prefix.String(),
+		barWidth: barWidth,
+		suffix:   separator + progressTimeStyle().Render(timeStr) + separator + volumeStr,
 	}
-	content.WriteString(separator)
-	content.WriteString(radioIndicator)
-	content.WriteString(styles.T().Bg(status))
-	content.WriteString(render.EmptyLine(2))
-	content.WriteString(filledBar)
-	content.WriteString(emptyBar)
-	content.WriteString(separator)
-	content.WriteString(progressTimeStyle().Render(timeStr))
-	content.WriteString(separator)
-	content.WriteString(volumeStr)
-
-	return barStyle().Padding(0, 2).Width(width - 2).Render(content.String())
 }
 
 func truncateCompact(s string, maxWidth int) string {
 	return render.TruncateEllipsis(s, maxWidth)
 }
 
+// formatSpeed formats the playback speed, e.g. "1.25×".
+// Returns an empty string at normal speed.
+func formatSpeed(speed float64) string {
+	if speed == 0 || speed == 1 {
+		return ""
+	}
+	return strconv.FormatFloat(math.Round(speed*100)/100, 'f', -1, 64) + "×"
+}
+
+// formatSleep formats the sleep timer countdown, e.g. "Sleep 12:34".
+// Returns an empty string when the timer is off.
+func formatSleep(s State) string {
+	if !s.Sleep {
+		return ""
+	}
+	if s.SleepLeft <= 0 {
+		return "Sleep"
+	}
+	return "Sleep " + formatDuration(s.SleepLeft)
+}
+
+// durationLabel returns the duration shown after the position: the
+// duration of the track, or LIVE for streams.
+func durationLabel(s State) string {
+	if s.Live {
+		return "LIVE"
+	}
+	return formatDuration(s.Duration)
+}
+
 func formatDuration(d time.Duration) string {
 	m := int(d.Minutes())
 	s := int(d.Seconds()) % 60
@@ -0,0 +1,189 @@
+package playerbar
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/llehouerou/waves/internal/ui"
+	"github.com/llehouerou/waves/internal/waveform"
+)
+
+// SeekBar is where the seek bar is drawn within the player bar, in cells
+// from its top-left corner.
+type SeekBar struct {
+	Row   int
+	X     int
+	Width int
+}
+
+// Contains returns true if the cell at column x of row is on the seek bar.
+func (b SeekBar) Contains(x, row int) bool {
+	return row == b.Row && x >= b.X && x < b.X+b.Width
+}
+
+// Ratio returns the position in the track of column x, from 0 to 1. The
+// middle of the column is used, so the first and last columns don't jump
+// to the very start and end.
+func (b SeekBar) Ratio(x int) float64 {
+	if b.Width <= 0 {
+		return 0
+	}
+	return max(0, min(1, (float64(x-b.X)+0.5)/float64(b.Width)))
+}
+
+// LocateSeekBar returns where Render draws the seek bar for width, or
+// false when nothing is playing, a stream is playing, or there is no room
+// for it.
+func LocateSeekBar(s State, width int) (SeekBar, bool) {
+	if !s.Playing && !s.Paused || s.Live {
+		return SeekBar{}, false
+	}
+
+	// Both views have a border and 2 cells of padding
+	const left = 3
+	innerWidth := max(width-6, 0)
+
+	if s.DisplayMode != ModeExpanded || innerWidth < ui.MinExpandedWidth {
+		l := layoutCompact(s, width)
+		return SeekBar{Row: 1, X: left + lipgloss.Width(l.prefix), Width: l.barWidth}, true
+	}
+
+	x := left
+	if s.HasAlbumArt && s.AlbumArtPlaceholder != "" {
+		x += AlbumArtWidth + 2
+	}
+	textWidth, _ := expandedWidths(s, innerWidth)
+	volumeWidth := lipgloss.Width(RenderVolumeCompact(s.Volume, s.Muted))
+	offset, barWidth := styledProgressBarLayout(s, textWidth-volumeWidth-2)
+	if barWidth < 5 {
+		return SeekBar{}, false
+	}
+	// The progress bar is on the 4th content line
+	return SeekBar{Row: 4, X: x + offset, Width: barWidth}, true
+}
+
+// progressRatio returns how much of the track was played, from 0 to 1.
+func progressRatio(position, duration time.Duration) float64 {
+	if duration <= 0 {
+		return 0
+	}
+	return max(0, min(1, float64(position)/float64(duration)))
+}
+
+// seekBarCell is how a column of the seek bar is drawn.
+type seekBarCell int
+
+const (
+	cellEmpty seekBarCell = iota
+	cellFilled
+	cellLoopEmpty
+	cellLoopFilled
+	cellBookmark
+)
+
+func (c seekBarCell) style() lipgloss.Style {
+	switch c {
+	case cellFilled:
+		return progressBarFilled()
+	case cellLoopEmpty:
+		return progressBarLoopEmpty()
+	case cellLoopFilled:
+		return progressBarLoop()
+	case cellBookmark:
+		return progressBarBookmark()
+	case cellEmpty:
+	}
+	return progressBarEmpty()
+}
+
+// renderSeekBar draws the seek bar: the waveform of the track when it is
+// known, a plain line otherwise. The part already played is highlighted,
+// the A-B loop is drawn in another color and bookmarks are marked.
+func renderSeekBar(s State, width int) string {
+	filled := min(int(float64(width)*progressRatio(s.Position, s.Duration)), width)
+
+	var columns []rune
+	if s.Waveform != nil {
+		columns = waveformColumns(s.Waveform, width)
+	} else {
+		// Use thin bar characters for modern look
+		columns = []rune(strings.Repeat("━", filled) + strings.Repeat("─", width-filled))
+	}
+
+	cells := make([]seekBarCell, width)
+	loopStart, loopEnd := -1, -1
+	if s.LoopA > 0 || s.LoopB > 0 {
+		loopStart = seekBarColumn(s.LoopA, s.Duration, width)
+		loopEnd = loopStart
+		if s.LoopB > s.LoopA {
+			loopEnd = seekBarColumn(s.LoopB, s.Duration, width)
+		}
+	}
+	for i := range cells {
+		inLoop := i >= loopStart && i <= loopEnd
+		switch {
+		case inLoop && i < filled:
+			cells[i] = cellLoopFilled
+		case inLoop:
+			cells[i] = cellLoopEmpty
+		case i < filled:
+			cells[i] = cellFilled
+		}
+	}
+	for _, pos := range s.Bookmarks {
+		i := seekBarColumn(pos, s.Duration, width)
+		if i < 0 {
+			continue
+		}
+		cells[i] = cellBookmark
+		if s.Waveform == nil {
+			columns[i] = '┃'
+		}
+	}
+
+	// Render runs of cells drawn alike together
+	var b strings.Builder
+	for start := 0; start < width; {
+		end := start + 1
+		for end < width && cells[end] == cells[start] {
+			end++
+		}
+		b.WriteString(cells[start].style().Render(string(columns[start:end])))
+		start = end
+	}
+	return b.String()
+}
+
+// seekBarColumn returns the column of the seek bar at position pos, -1
+// when it can't be placed.
+func seekBarColumn(pos, duration time.Duration, width int) int {
+	if duration <= 0 || width <= 0 || pos < 0 || pos > duration {
+		return -1
+	}
+	return min(int(float64(width)*float64(pos)/float64(duration)), width-1)
+}
+
+// waveformColumns draws a waveform on width block characters. The height
+// follows the RMS level, which reads like loudness, scaled to the loudest
+// column so quiet masters still fill the bar. Silent columns keep the
+// lowest block so the bar stays visible.
+func waveformColumns(w *waveform.Waveform, width int) []rune {
+	_, rms := w.Columns(width)
+	var loudest float64
+	for _, v := range rms {
+		loudest = max(loudest, v)
+	}
+
+	columns := make([]rune, width)
+	for i, v := range rms {
+		level := 1
+		if loudest > 0 {
+			level = max(int(math.Round(v/loudest*8)), 1)
+		}
+		columns[i] = verticalBlocks[level]
+	}
+	return columns
+}
@@ -0,0 +1,161 @@
+package playerbar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/llehouerou/waves/internal/waveform"
+)
+
+// rampWaveform returns a waveform getting louder from start to end.
+func rampWaveform() *waveform.Waveform {
+	w := &waveform.Waveform{
+		Peak: make([]float64, waveform.Resolution),
+		RMS:  make([]float64, waveform.Resolution),
+	}
+	for i := range w.RMS {
+		w.RMS[i] = float64(i+1) / waveform.Resolution
+		w.Peak[i] = w.RMS[i]
+	}
+	return w
+}
+
+// seekBarCells returns the cells of a rendered player bar covered by bar.
+func seekBarCells(t *testing.T, rendered string, bar SeekBar) string {
+	t.Helper()
+	lines := strings.Split(ansi.Strip(rendered), "\n")
+	if bar.Row >= len(lines) {
+		t.Fatalf("row %d out of %d lines", bar.Row, len(lines))
+	}
+	return ansi.Cut(lines[bar.Row], bar.X, bar.X+bar.Width)
+}
+
+func TestLocateSeekBar_MatchesRender(t *testing.T) {
+	for _, mode := range []DisplayMode{ModeCompact, ModeExpanded} {
+		s := State{
+			Playing:     true,
+			Title:       "Song",
+			Artist:      "Artist",
+			Album:       "Album",
+			Track:       3,
+			TotalTracks: 12,
+			Position:    time.Minute,
+			Duration:    4 * time.Minute,
+			DisplayMode: mode,
+			Volume:      0.5,
+			Waveform:    rampWaveform(),
+		}
+		const width = 120
+
+		bar, ok := LocateSeekBar(s, width)
+		if !ok {
+			t.Fatalf("mode %d: no seek bar", mode)
+		}
+		cells := []rune(seekBarCells(t, Render(s, width), bar))
+		if len(cells) != bar.Width {
+			t.Fatalf("mode %d: got %d cells, want %d", mode, len(cells), bar.Width)
+		}
+		// The ramp starts with the lowest block and ends with the highest
+		if cells[0] != '▁' || cells[len(cells)-1] != '█' {
+			t.Errorf("mode %d: seek bar = %q, want the waveform", mode, string(cells))
+		}
+	}
+}
+
+func TestLocateSeekBar_Stopped(t *testing.T) {
+	if _, ok := LocateSeekBar(State{}, 120); ok {
+		t.Error("no seek bar when stopped")
+	}
+}
+
+func TestSeekBar_Ratio(t *testing.T) {
+	bar := SeekBar{Row: 1, X: 10, Width: 4}
+	tests := []struct {
+		x    int
+		want float64
+	}{
+		{10, 0.125},
+		{13, 0.875},
+		{0, 0},
+		{20, 1},
+	}
+	for _, tt := range tests {
+		if got := bar.Ratio(tt.x); got != tt.want {
+			t.Errorf("Ratio(%d) = %v, want %v", tt.x, got, tt.want)
+		}
+	}
+	if !bar.Contains(13, 1) || bar.Contains(14, 1) || bar.Contains(10, 0) {
+		t.Error("Contains should cover the bar cells only")
+	}
+}
+
+func TestRenderSeekBar_PlainWithoutWaveform(t *testing.T) {
+	s := State{Position: time.Minute, Duration: 2 * time.Minute}
+	got := ansi.Strip(renderSeekBar(s, 10))
+	if got != "━━━━━─────" {
+		t.Errorf("renderSeekBar() = %q", got)
+	}
+}
+
+func TestRenderSeekBar_Bookmarks(t *testing.T) {
+	s := State{
+		Position:  time.Minute,
+		Duration:  2 * time.Minute,
+		LoopA:     30 * time.Second,
+		LoopB:     90 * time.Second,
+		Bookmarks: []time.Duration{0, 96 * time.Second, 2 * time.Minute, 3 * time.Minute},
+	}
+	got := ansi.Strip(renderSeekBar(s, 10))
+	// The bookmark past the end of the track isn't drawn
+	if got != "┃━━━━───┃┃" {
+		t.Errorf("renderSeekBar() = %q", got)
+	}
+
+	s.Waveform = rampWaveform()
+	if got := []rune(ansi.Strip(renderSeekBar(s, 10))); got[0] != '▁' {
+		t.Errorf("bookmarks should keep the waveform, got %q", string(got))
+	}
+}
+
+func TestSeekBarColumn(t *testing.T) {
+	tests := []struct {
+		pos  time.Duration
+		want int
+	}{
+		{0, 0},
+		{time.Minute, 5},
+		{2 * time.Minute, 9},
+		{-time.Second, -1},
+		{3 * time.Minute, -1},
+	}
+	for _, tt := range tests {
+		if got := seekBarColumn(tt.pos, 2*time.Minute, 10); got != tt.want {
+			t.Errorf("seekBarColumn(%v) = %d, want %d", tt.pos, got, tt.want)
+		}
+	}
+	if got := seekBarColumn(time.Second, 0, 10); got != -1 {
+		t.Errorf("seekBarColumn() without duration = %d, want -1", got)
+	}
+}
+
+func TestLocateSeekBar_Live(t *testing.T) {
+	for _, mode := range []DisplayMode{ModeCompact, ModeExpanded} {
+		s := State{
+			Playing:     true,
+			Title:       "Song",
+			Album:       "Radio",
+			Position:    time.Minute,
+			DisplayMode: mode,
+			Live:        true,
+		}
+		if _, ok := LocateSeekBar(s, 120); ok {
+			t.Errorf("mode %d: streams can't seek", mode)
+		}
+		if got := ansi.Strip(Render(s, 120)); !strings.Contains(got, "LIVE") {
+			t.Errorf("mode %d: render should show LIVE:\n%s", mode, got)
+		}
+	}
+}
@@ -64,6 +64,18 @@ func progressBarEmpty() lipgloss.Style {
 	return styles.T().BaseStyle().Foreground(styles.T().FgSubtle)
 }
 
+func progressBarLoop() lipgloss.Style {
+	return styles.T().BaseStyle().Foreground(styles.T().Secondary)
+}
+
+func progressBarLoopEmpty() lipgloss.Style {
+	return styles.T().BaseStyle().Foreground(styles.T().FgMuted)
+}
+
+func progressBarBookmark() lipgloss.Style {
+	return styles.T().BaseStyle().Foreground(styles.T().Warning)
+}
+
 func radioStyle() lipgloss.Style {
 	return styles.T().BaseStyle().Foreground(styles.T().Secondary)
 }
@@ -0,0 +1,113 @@
+package playerbar
+
+import (
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/llehouerou/waves/internal/ui/render"
+	"github.com/llehouerou/waves/internal/ui/styles"
+	"github.com/llehouerou/waves/internal/visualizer"
+)
+
+const (
+	visualizerMinWidth = 16
+	visualizerMaxWidth = visualizer.Bands
+	// visualizerMinTextWidth is the width left to the metadata before the
+	// visualizer is hidden.
+	visualizerMinTextWidth = 50
+)
+
+var (
+	verticalBlocks   = []rune(" ▁▂▃▄▅▆▇█")
+	horizontalBlocks = []rune(" ▏▎▍▌▋▊▉█")
+)
+
+// visualizerWidth returns the width of the visualizer shown next to text
+// content of textWidth, 0 when there isn't room for it.
+func visualizerWidth(textWidth int) int {
+	w := min(textWidth/4, visualizerMaxWidth)
+	if w < visualizerMinWidth || textWidth-w-2 < visualizerMinTextWidth {
+		return 0
+	}
+	return w
+}
+
+// joinVisualizer appends the visualizer to the right of the text lines.
+func joinVisualizer(lines []string, textWidth int, a *visualizer.Analyzer, width int) []string {
+	viz := renderVisualizer(a, width)
+	joined := make([]string, len(lines))
+	for i, line := range lines {
+		gap := max(textWidth-lipgloss.Width(line), 0) + 2
+		joined[i] = line + render.EmptyLine(gap) + viz[i]
+	}
+	return joined
+}
+
+// renderVisualizer renders the spectrum on two rows, followed by the left
+// and right level meters, in the theme gradient. Each line is width cells.
+func renderVisualizer(a *visualizer.Analyzer, width int) []string {
+	t := styles.T()
+	colors := styles.GradientColors(width, t.Primary, t.Secondary)
+	cells := make([]lipgloss.Style, width)
+	for i, c := range colors {
+		cells[i] = t.BaseStyle().Foreground(c)
+	}
+
+	var top, bottom strings.Builder
+	for i, v := range fitBands(a.Bands(), width) {
+		level := int(math.Round(v * 16))
+		top.WriteString(cells[i].Render(string(verticalBlocks[max(min(level-8, 8), 0)])))
+		bottom.WriteString(cells[i].Render(string(verticalBlocks[min(level, 8)])))
+	}
+
+	levels := a.Levels()
+	return []string{
+		top.String(),
+		bottom.String(),
+		renderMeter("L", levels[0], cells),
+		renderMeter("R", levels[1], cells),
+	}
+}
+
+// renderMeter renders a level meter with its peak marker, e.g.
+// "L ████▊───│──".
+func renderMeter(label string, l visualizer.Level, cells []lipgloss.Style) string {
+	width := len(cells) - 2
+	eighths := int(math.Round(l.RMS * float64(width*8)))
+	peak := -1
+	if l.Peak > 0 {
+		peak = min(int(l.Peak*float64(width)), width-1)
+	}
+
+	var b strings.Builder
+	b.WriteString(metaStyle().Render(label))
+	b.WriteString(render.EmptyLine(1))
+	for i := range width {
+		style := cells[i+2]
+		switch fill := eighths - i*8; {
+		case fill > 0:
+			b.WriteString(style.Render(string(horizontalBlocks[min(fill, 8)])))
+		case i == peak:
+			b.WriteString(style.Render("│"))
+		default:
+			b.WriteString(progressBarEmpty().Render("─"))
+		}
+	}
+	return b.String()
+}
+
+// fitBands maps the spectrum bands to width columns. Columns covering
+// several bands show the loudest.
+func fitBands(bands []float64, width int) []float64 {
+	cols := make([]float64, width)
+	for c := range cols {
+		first := c * len(bands) / width
+		last := max((c+1)*len(bands)/width, first+1)
+		for _, v := range bands[first:last] {
+			cols[c] = max(cols[c], v)
+		}
+	}
+	return cols
+}
@@ -26,6 +26,16 @@ type ToggleFavorite struct {
 // ActionType implements action.Action.
 func (a ToggleFavorite) ActionType() string { return "queuepanel.toggle_favorite" }
 
+// Rate requests rating tracks with a rating key: "0" to "5" set the
+// number of stars, "<" and ">" lower or raise the rating.
+type Rate struct {
+	TrackIDs []int64
+	Key      string
+}
+
+// ActionType implements action.Action.
+func (a Rate) ActionType() string { return "queuepanel.rate" }
+
 // AddToPlaylist requests adding tracks to a playlist.
 type AddToPlaylist struct {
 	TrackIDs []int64
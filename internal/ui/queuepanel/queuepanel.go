@@ -111,6 +111,13 @@ func (m Model) handleCustomKey(key string) (Model, tea.Cmd) {
 				return ActionMsg(ToggleFavorite{TrackIDs: trackIDs})
 			}
 		}
+	case "0", "1", "2", "3", "4", "5", "<", ">":
+		trackIDs := m.getSelectedTrackIDs()
+		if len(trackIDs) > 0 {
+			return m, func() tea.Msg {
+				return ActionMsg(Rate{TrackIDs: trackIDs, Key: key})
+			}
+		}
 	case "ctrl+a":
 		trackIDs := m.getSelectedTrackIDs()
 		if len(trackIDs) > 0 {
@@ -0,0 +1,35 @@
+package smartplaylist
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/playlists"
+	"github.com/llehouerou/waves/internal/ui/action"
+)
+
+// Source is the action source identifier for the smart playlist editor.
+const Source = "smartplaylist"
+
+// ActionMsg wraps an action with the source identifier.
+func ActionMsg(a action.Action) tea.Msg {
+	return action.Msg{
+		Source: Source,
+		Action: a,
+	}
+}
+
+// Saved signals that the playlist should be created (ID 0) or its rules
+// replaced.
+type Saved struct {
+	ID       int64
+	FolderID *int64
+	Name     string
+	Rules    playlists.Rules
+}
+
+func (Saved) ActionType() string { return "smartplaylist.Saved" }
+
+// Canceled signals that the edits should be discarded.
+type Canceled struct{}
+
+func (Canceled) ActionType() string { return "smartplaylist.Canceled" }
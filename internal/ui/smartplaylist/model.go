@@ -0,0 +1,140 @@
+// Package smartplaylist provides the smart playlist rule editor popup.
+package smartplaylist
+
+import (
+	"github.com/llehouerou/waves/internal/playlists"
+	"github.com/llehouerou/waves/internal/ui/popup"
+)
+
+// Compile-time check that Model implements popup.Popup.
+var _ popup.Popup = (*Model)(nil)
+
+// rowKind is the kind of a line of the editor.
+type rowKind int
+
+const (
+	rowName rowKind = iota
+	rowGroup
+	rowRule
+	rowSort
+	rowLimit
+)
+
+// row is a line of the editor. Groups are addressed by their path of
+// indexes from the root group.
+type row struct {
+	kind  rowKind
+	path  []int // Group of the row (rowGroup, rowRule)
+	rule  int   // Index of the rule in its group (rowRule)
+	depth int
+}
+
+// Columns of a rule row.
+const (
+	colField = iota
+	colOp
+	colValue
+	colTo
+)
+
+// Model is the smart playlist editor popup model.
+type Model struct {
+	id       int64 // 0 when creating a playlist
+	folderID *int64
+	name     string
+	rules    playlists.Rules
+
+	row int
+	col int
+	err string
+
+	width  int
+	height int
+}
+
+// New creates an editor for a new smart playlist in the given folder.
+func New(folderID *int64) *Model {
+	return &Model{
+		folderID: folderID,
+		rules:    playlists.DefaultRules(),
+	}
+}
+
+// NewEdit creates an editor for the rules of an existing smart playlist.
+// The cursor starts on the first rule.
+func NewEdit(id int64, name string, rules playlists.Rules) *Model {
+	if rules.Match == "" {
+		rules.Match = playlists.MatchAll
+	}
+	if rules.Sort.Field == "" {
+		rules.Sort.Field = playlists.SortAlbum
+	}
+	return &Model{
+		id:    id,
+		name:  name,
+		rules: rules,
+		row:   min(2, len(rules.Rules)+len(rules.Groups)+1),
+	}
+}
+
+// Rules returns the rules being edited.
+func (m *Model) Rules() playlists.Rules {
+	return m.rules
+}
+
+// SetSize implements popup.Popup.
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// rows flattens the name, the group tree, the sort and the limit into the
+// lines of the editor.
+func (m *Model) rows() []row {
+	rows := []row{{kind: rowName}}
+	rows = appendGroupRows(rows, &m.rules.Group, nil, 0)
+	return append(rows, row{kind: rowSort}, row{kind: rowLimit})
+}
+
+func appendGroupRows(rows []row, g *playlists.Group, path []int, depth int) []row {
+	rows = append(rows, row{kind: rowGroup, path: path, depth: depth})
+	for i := range g.Rules {
+		rows = append(rows, row{kind: rowRule, path: path, rule: i, depth: depth + 1})
+	}
+	for i := range g.Groups {
+		sub := append(append([]int(nil), path...), i)
+		rows = appendGroupRows(rows, &g.Groups[i], sub, depth+1)
+	}
+	return rows
+}
+
+// group returns the group at the given path.
+func (m *Model) group(path []int) *playlists.Group {
+	g := &m.rules.Group
+	for _, i := range path {
+		g = &g.Groups[i]
+	}
+	return g
+}
+
+// current returns the row under the cursor.
+func (m *Model) current() row {
+	rows := m.rows()
+	m.row = max(min(m.row, len(rows)-1), 0)
+	return rows[m.row]
+}
+
+// columns returns the number of cells of a row.
+func (m *Model) columns(r row) int {
+	switch r.kind {
+	case rowRule:
+		if m.group(r.path).Rules[r.rule].Op == playlists.OpBetween {
+			return 4
+		}
+		return 3
+	case rowSort:
+		return 2
+	case rowName, rowGroup, rowLimit:
+	}
+	return 1
+}
@@ -0,0 +1,163 @@
+package smartplaylist
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/playlists"
+	"github.com/llehouerou/waves/internal/ui/action"
+	"github.com/llehouerou/waves/internal/ui/testutil"
+)
+
+func newTestPopup(m *Model) *testutil.PopupHarness {
+	m.SetSize(100, 30)
+	return testutil.NewPopupHarness(m)
+}
+
+func lastAction(t *testing.T, h *testutil.PopupHarness) action.Action {
+	t.Helper()
+	msg := testutil.ExecuteCmd(h.LastCommand())
+	actionMsg, ok := msg.(action.Msg)
+	if !ok {
+		t.Fatalf("expected action.Msg, got %T", msg)
+	}
+	if actionMsg.Source != Source {
+		t.Errorf("Source = %q, want %q", actionMsg.Source, Source)
+	}
+	return actionMsg.Action
+}
+
+func TestNew_Save(t *testing.T) {
+	folderID := int64(3)
+	m := New(&folderID)
+	h := newTestPopup(m)
+
+	h.SendKey("Jazz")
+	h.SendDown() // Root group
+	h.SendDown() // Artist rule
+	h.SendTab()
+	h.SendTab()
+	h.SendKey("Miles")
+	h.SendEnter()
+
+	saved, ok := lastAction(t, h).(Saved)
+	if !ok {
+		t.Fatalf("expected Saved, got %T", lastAction(t, h))
+	}
+	if saved.ID != 0 || saved.FolderID == nil || *saved.FolderID != 3 || saved.Name != "Jazz" {
+		t.Errorf("Saved = %+v, want new playlist Jazz in folder 3", saved)
+	}
+	want := playlists.Rule{Field: playlists.FieldArtist, Op: playlists.OpContains, Value: "Miles"}
+	if len(saved.Rules.Rules) != 1 || saved.Rules.Rules[0] != want {
+		t.Errorf("Rules = %+v, want %+v", saved.Rules.Rules, want)
+	}
+}
+
+func TestSave_InvalidShowsError(t *testing.T) {
+	m := New(nil)
+	h := newTestPopup(m)
+
+	h.SendEnter()
+	if h.LastCommand() != nil {
+		t.Error("saving without a name should not emit an action")
+	}
+	h.AssertViewContains("name missing")
+
+	h.SendKey("Empty")
+	h.SendEnter()
+	if h.LastCommand() != nil {
+		t.Error("saving an empty rule value should not emit an action")
+	}
+	h.AssertViewContains("value missing")
+}
+
+func TestCycleField_ResetsOperator(t *testing.T) {
+	m := NewEdit(1, "Recent", playlists.DefaultRules())
+	h := newTestPopup(m)
+
+	h.SendKey("x")
+	// Year is after the text fields
+	for range 7 {
+		h.SendSpecialKey(tea.KeyRight)
+	}
+	rule := m.Rules().Rules[0]
+	if rule.Field != playlists.FieldYear || rule.Op != playlists.OpIs || rule.Value != "" {
+		t.Fatalf("rule = %+v, want an empty year rule", rule)
+	}
+
+	h.SendTab()
+	for range 4 {
+		h.SendSpecialKey(tea.KeyRight)
+	}
+	if got := m.Rules().Rules[0].Op; got != playlists.OpBetween {
+		t.Fatalf("Op = %s, want between", got)
+	}
+	h.SendTab()
+	h.SendKey("1990")
+	h.SendTab()
+	h.SendKey("1999")
+	h.SendEnter()
+
+	saved := lastAction(t, h).(Saved)
+	want := playlists.Rule{Field: playlists.FieldYear, Op: playlists.OpBetween, Value: "1990", To: "1999"}
+	if saved.ID != 1 || saved.Rules.Rules[0] != want {
+		t.Errorf("Saved = %+v, want rule %+v", saved, want)
+	}
+}
+
+func TestGroups_AddAndRemove(t *testing.T) {
+	rules := playlists.DefaultRules()
+	rules.Rules[0].Value = "a"
+	m := NewEdit(1, "Mix", rules)
+	h := newTestPopup(m)
+
+	h.SendSpecialKey(tea.KeyCtrlG)
+	if got := m.Rules().Groups; len(got) != 1 || got[0].Match != playlists.MatchAny {
+		t.Fatalf("Groups = %+v, want one any group", got)
+	}
+
+	// The cursor is on the rule of the new group
+	h.SendSpecialKey(tea.KeyCtrlN)
+	if got := len(m.Rules().Groups[0].Rules); got != 2 {
+		t.Fatalf("group rules = %d, want 2", got)
+	}
+
+	h.SendUp()
+	h.SendUp() // Group header
+	h.SendSpecialKey(tea.KeyCtrlD)
+	if got := m.Rules().Groups; len(got) != 0 {
+		t.Errorf("Groups = %+v, want the group removed", got)
+	}
+}
+
+func TestSortAndLimit(t *testing.T) {
+	rules := playlists.DefaultRules()
+	rules.Rules[0].Value = "a"
+	m := NewEdit(1, "Top", rules)
+	h := newTestPopup(m)
+
+	h.SendDown() // Sort
+	h.SendSpecialKey(tea.KeyLeft)
+	h.SendTab()
+	h.SendSpecialKey(tea.KeyRight)
+	h.SendDown() // Limit
+	h.SendKey("2")
+	h.SendKey("5")
+	h.SendKey("x")
+	h.SendKey("0")
+	h.SendSpecialKey(tea.KeyBackspace)
+
+	got := m.Rules()
+	if got.Sort != (playlists.Sort{Field: playlists.SortRandom, Desc: true}) || got.Limit != 25 {
+		t.Errorf("Sort = %+v, Limit = %d, want random descending, 25", got.Sort, got.Limit)
+	}
+}
+
+func TestEscape_Cancels(t *testing.T) {
+	h := newTestPopup(New(nil))
+	h.SendEscape()
+	if _, ok := lastAction(t, h).(Canceled); !ok {
+		t.Errorf("expected Canceled, got %T", lastAction(t, h))
+	}
+}
@@ -0,0 +1,244 @@
+package smartplaylist
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/llehouerou/waves/internal/playlists"
+	"github.com/llehouerou/waves/internal/ui/popup"
+)
+
+// maxLimit caps the limit typed in the editor.
+const maxLimit = 100000
+
+// Init implements popup.Popup.
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements popup.Popup.
+func (m *Model) Update(msg tea.Msg) (popup.Popup, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	return m, m.handleKey(keyMsg)
+}
+
+func (m *Model) handleKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "up":
+		m.row = max(m.row-1, 0)
+		m.col = min(m.col, m.columns(m.current())-1)
+		return nil
+	case "down":
+		m.row = min(m.row+1, len(m.rows())-1)
+		m.col = min(m.col, m.columns(m.current())-1)
+		return nil
+	case "tab":
+		m.col = (m.col + 1) % m.columns(m.current())
+		return nil
+	case "shift+tab":
+		n := m.columns(m.current())
+		m.col = (m.col + n - 1) % n
+		return nil
+	case "enter":
+		return m.save()
+	case "esc":
+		return func() tea.Msg { return ActionMsg(Canceled{}) }
+	}
+
+	m.err = ""
+	switch msg.String() {
+	case "left":
+		m.cycle(-1)
+	case "right":
+		m.cycle(1)
+	case "ctrl+n":
+		m.addRule()
+	case "ctrl+g":
+		m.addGroup()
+	case "ctrl+d":
+		m.remove()
+	case "backspace":
+		m.editText(func(s string) string {
+			if s == "" {
+				return s
+			}
+			_, size := utf8.DecodeLastRuneInString(s)
+			return s[:len(s)-size]
+		})
+	default:
+		m.editText(func(s string) string {
+			for _, r := range msg.Runes {
+				if !unicode.IsControl(r) {
+					s += string(r)
+				}
+			}
+			return s
+		})
+	}
+	return nil
+}
+
+// save validates the rules and emits them.
+func (m *Model) save() tea.Cmd {
+	name := strings.TrimSpace(m.name)
+	err := m.rules.Validate()
+	if name == "" {
+		err = errors.New("name missing")
+	}
+	if err != nil {
+		m.err = err.Error()
+		return nil
+	}
+	saved := Saved{ID: m.id, FolderID: m.folderID, Name: name, Rules: m.rules}
+	return func() tea.Msg { return ActionMsg(saved) }
+}
+
+// cycle changes the choice under the cursor to the next (dir 1) or previous
+// (dir -1) option.
+func (m *Model) cycle(dir int) {
+	r := m.current()
+	switch r.kind {
+	case rowGroup:
+		g := m.group(r.path)
+		if g.Match == playlists.MatchAny {
+			g.Match = playlists.MatchAll
+		} else {
+			g.Match = playlists.MatchAny
+		}
+	case rowRule:
+		rule := &m.group(r.path).Rules[r.rule]
+		switch m.col {
+		case colField:
+			rule.Field = next(playlists.Fields, rule.Field, dir)
+			rule.Op = rule.Field.Ops()[0]
+			rule.Value, rule.To = "", ""
+		case colOp:
+			rule.Op = next(rule.Field.Ops(), rule.Op, dir)
+			m.col = min(m.col, m.columns(r)-1)
+		}
+	case rowSort:
+		if m.col == 0 {
+			m.rules.Sort.Field = next(playlists.SortFields, m.rules.Sort.Field, dir)
+		} else {
+			m.rules.Sort.Desc = !m.rules.Sort.Desc
+		}
+	case rowName, rowLimit:
+	}
+}
+
+// next returns the option dir steps away from current, wrapping around.
+func next[T comparable](options []T, current T, dir int) T {
+	i := max(slices.Index(options, current), 0)
+	return options[(i+dir+len(options))%len(options)]
+}
+
+// editText applies edit to the text cell under the cursor.
+func (m *Model) editText(edit func(string) string) {
+	r := m.current()
+	switch r.kind {
+	case rowName:
+		m.name = edit(m.name)
+	case rowRule:
+		rule := &m.group(r.path).Rules[r.rule]
+		switch m.col {
+		case colValue:
+			rule.Value = edit(rule.Value)
+		case colTo:
+			rule.To = edit(rule.To)
+		}
+	case rowLimit:
+		limit := ""
+		if m.rules.Limit > 0 {
+			limit = strconv.Itoa(m.rules.Limit)
+		}
+		limit = edit(limit)
+		n := 0
+		for _, c := range limit {
+			if c < '0' || c > '9' {
+				return
+			}
+			n = n*10 + int(c-'0')
+		}
+		m.rules.Limit = min(n, maxLimit)
+	case rowGroup, rowSort:
+	}
+}
+
+// addRule adds a rule after the cursor, or at the end of the group whose
+// header is selected.
+func (m *Model) addRule() {
+	r := m.current()
+	rule := playlists.Rule{Field: playlists.FieldArtist, Op: playlists.OpContains}
+	switch r.kind {
+	case rowRule:
+		g := m.group(r.path)
+		g.Rules = slices.Insert(g.Rules, r.rule+1, rule)
+		m.row++
+	case rowGroup:
+		g := m.group(r.path)
+		g.Rules = append(g.Rules, rule)
+		m.row += len(g.Rules)
+	default:
+		m.rules.Rules = append(m.rules.Rules, rule)
+		m.row = len(m.rules.Rules) + 1
+	}
+	m.col = colField
+}
+
+// addGroup adds a nested group to the group under the cursor. The new group
+// uses the other match mode, which is the only useful nesting.
+func (m *Model) addGroup() {
+	r := m.current()
+	var path []int
+	if r.kind == rowGroup || r.kind == rowRule {
+		path = r.path
+	}
+	g := m.group(path)
+	match := playlists.MatchAny
+	if g.Match == playlists.MatchAny {
+		match = playlists.MatchAll
+	}
+	g.Groups = append(g.Groups, playlists.Group{
+		Match: match,
+		Rules: []playlists.Rule{{Field: playlists.FieldArtist, Op: playlists.OpContains}},
+	})
+
+	target := append(append([]int(nil), path...), len(g.Groups)-1)
+	for i, row := range m.rows() {
+		if row.kind == rowGroup && slices.Equal(row.path, target) {
+			m.row = i + 1 // Its rule
+			break
+		}
+	}
+	m.col = colField
+}
+
+// remove deletes the rule or nested group under the cursor.
+func (m *Model) remove() {
+	r := m.current()
+	switch r.kind {
+	case rowRule:
+		g := m.group(r.path)
+		g.Rules = slices.Delete(g.Rules, r.rule, r.rule+1)
+	case rowGroup:
+		if len(r.path) == 0 {
+			return
+		}
+		parent := m.group(r.path[:len(r.path)-1])
+		i := r.path[len(r.path)-1]
+		parent.Groups = slices.Delete(parent.Groups, i, i+1)
+	case rowName, rowSort, rowLimit:
+		return
+	}
+	m.row = min(m.row, len(m.rows())-1)
+	m.col = min(m.col, m.columns(m.current())-1)
+}
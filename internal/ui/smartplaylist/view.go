@@ -0,0 +1,115 @@
+package smartplaylist
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/llehouerou/waves/internal/playlists"
+	"github.com/llehouerou/waves/internal/ui/styles"
+)
+
+func titleStyle() lipgloss.Style {
+	return styles.T().S().Title
+}
+
+func baseStyle() lipgloss.Style {
+	return styles.T().S().Base
+}
+
+func cursorStyle() lipgloss.Style {
+	return styles.T().S().Cursor
+}
+
+func hintStyle() lipgloss.Style {
+	return styles.T().S().Subtle
+}
+
+func warningStyle() lipgloss.Style {
+	return styles.T().S().Warning
+}
+
+// View implements popup.Popup.
+func (m *Model) View() string {
+	if m.width == 0 || m.height == 0 {
+		return ""
+	}
+
+	title := "New Smart Playlist"
+	if m.id != 0 {
+		title = "Edit Smart Playlist"
+	}
+
+	rows := m.rows()
+	m.row = max(min(m.row, len(rows)-1), 0)
+	lines := make([]string, 0, len(rows)+2)
+	for i, r := range rows {
+		if r.kind == rowSort {
+			lines = append(lines, "")
+		}
+		lines = append(lines, m.rowLine(r, i == m.row))
+	}
+	if m.err != "" {
+		lines = append(lines, "", warningStyle().Render("  "+m.err))
+	}
+
+	hints := hintStyle().Render("↑↓ row · tab field · ←→ change · ctrl+n add rule · ctrl+g add group · ctrl+d remove") + "\n" +
+		hintStyle().Render("enter save · esc cancel")
+
+	return titleStyle().Render(title) + "\n\n" + strings.Join(lines, "\n") + "\n\n" + hints
+}
+
+func (m *Model) rowLine(r row, selected bool) string {
+	cell := func(col int, text string, editable bool) string {
+		if !selected || m.col != col {
+			return baseStyle().Render(text)
+		}
+		if editable {
+			text += "█"
+		}
+		return cursorStyle().Render(text)
+	}
+
+	prefix := "  "
+	if selected {
+		prefix = "> "
+	}
+	prefix += strings.Repeat("  ", r.depth)
+
+	switch r.kind {
+	case rowName:
+		return prefix + baseStyle().Render("Name  ") + cell(0, m.name, true)
+	case rowGroup:
+		g := m.group(r.path)
+		return prefix + baseStyle().Render("Match ") + cell(0, string(g.Match), false) +
+			baseStyle().Render(" of:")
+	case rowRule:
+		rule := m.group(r.path).Rules[r.rule]
+		line := prefix + cell(colField, fmt.Sprintf("%-12s", rule.Field), false) + " " +
+			cell(colOp, fmt.Sprintf("%-18s", rule.Op), false) + " " +
+			cell(colValue, rule.Value, true)
+		if rule.Op == playlists.OpBetween {
+			line += baseStyle().Render(" and ") + cell(colTo, rule.To, true)
+		}
+		return line
+	case rowSort:
+		dir := "ascending"
+		if m.rules.Sort.Desc {
+			dir = "descending"
+		}
+		return prefix + baseStyle().Render("Sort  ") + cell(0, m.rules.Sort.Field.String(), false) + " " +
+			cell(1, dir, false)
+	case rowLimit:
+		limit := ""
+		if m.rules.Limit > 0 {
+			limit = fmt.Sprint(m.rules.Limit)
+		}
+		line := prefix + baseStyle().Render("Limit ") + cell(0, limit, true)
+		if limit == "" && !selected {
+			line += hintStyle().Render("none")
+		}
+		return line
+	}
+	return prefix
+}
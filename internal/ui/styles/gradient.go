@@ -60,6 +60,17 @@ func applyGradient(text string, bold bool, from, to lipgloss.Color) string {
 	return b.String()
 }
 
+// GradientColors returns size colors blended from one color to another,
+// for content styled cell by cell.
+func GradientColors(size int, from, to lipgloss.Color) []lipgloss.Color {
+	blended := blendColors(size, from, to)
+	colors := make([]lipgloss.Color, len(blended))
+	for i, c := range blended {
+		colors[i] = lipgloss.Color(colorToHex(c))
+	}
+	return colors
+}
+
 // blendColors returns a slice of colors blended between from and to.
 // Blending is done in HCL color space for perceptually uniform transitions.
 func blendColors(size int, from, to lipgloss.Color) []color.Color {
@@ -0,0 +1,222 @@
+package visualizer
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// FFTSize is the number of frames analyzed for the spectrum.
+	FFTSize = 2048
+	// Bands is the number of spectrum bands, spaced logarithmically.
+	Bands = 48
+	// RingSize is a ring size that covers the analysis windows at any
+	// supported sample rate.
+	RingSize = 1 << 15
+
+	minFreq       = 40.0
+	maxFreq       = 16000.0
+	spectrumFloor = -60.0 // dB shown as an empty band
+	meterFloor    = -48.0 // dB shown as an empty meter
+	meterWindow   = 50 * time.Millisecond
+	fallRate      = 1.5 // Full scale per second
+	peakHold      = time.Second
+	peakFallRate  = 0.5 // Full scale per second, once the hold is over
+	staleAfter    = 500 * time.Millisecond
+)
+
+// Level is the level of one channel, 0 to 1 on a dB scale.
+type Level struct {
+	RMS  float64
+	Peak float64 // Highest recent peak, held for a moment
+}
+
+// Analyzer turns the frames of a Ring into spectrum bands and stereo
+// levels. Values rise immediately and fall at a fixed rate, so the display
+// looks the same at any refresh rate. It is not safe for concurrent use.
+type Analyzer struct {
+	plan   *fftPlan
+	window []float64 // Hann window
+	gain   float64   // Scales magnitudes to the amplitude of a sine
+	frames [][2]float32
+	re, im []float64
+
+	bands  []float64
+	levels [2]Level
+	held   [2]time.Duration // Time left holding each peak
+
+	written    uint64        // Ring position at the last Update
+	prev       uint64        // Ring position before the last write
+	sinceWrite time.Duration // Time since the ring last moved
+}
+
+// NewAnalyzer creates an analyzer with all values at zero.
+func NewAnalyzer() *Analyzer {
+	a := &Analyzer{
+		plan:   newFFTPlan(FFTSize),
+		window: make([]float64, FFTSize),
+		frames: make([][2]float32, RingSize),
+		re:     make([]float64, FFTSize),
+		im:     make([]float64, FFTSize),
+		bands:  make([]float64, Bands),
+	}
+	var sum float64
+	for i := range a.window {
+		a.window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(FFTSize-1))
+		sum += a.window[i]
+	}
+	a.gain = 2 / sum
+	return a
+}
+
+// Bands returns the spectrum bands from low to high frequencies, 0 to 1.
+func (a *Analyzer) Bands() []float64 {
+	return a.bands
+}
+
+// Levels returns the levels of the left and right channels.
+func (a *Analyzer) Levels() [2]Level {
+	return a.levels
+}
+
+// Update analyzes the audio of r played at sampleRate, dt after the
+// previous update.
+//
+// Outputs are written a buffer ahead of what is heard, in bursts. The
+// analysis window moves through the last burst in real time, so the display
+// follows the audio even when it is refreshed faster than the output
+// writes.
+func (a *Analyzer) Update(r *Ring, sampleRate int, dt time.Duration) {
+	if w := r.Written(); w != a.written {
+		a.prev = a.written
+		if a.sinceWrite >= staleAfter {
+			// Resuming after silence, don't replay what was left in the ring
+			a.prev = w
+		}
+		a.written = w
+		a.sinceWrite = 0
+	} else {
+		a.sinceWrite += dt
+	}
+
+	var bands []float64
+	var levels [2]float64
+	var peaks [2]float64
+	if sampleRate > 0 && a.sinceWrite < staleAfter {
+		end := min(a.written, a.prev+uint64(a.sinceWrite.Seconds()*float64(sampleRate)))
+		bands = a.spectrum(r, end, sampleRate)
+		levels, peaks = a.measure(r, end, sampleRate)
+	}
+
+	fall := fallRate * dt.Seconds()
+	for i := range a.bands {
+		var v float64
+		if bands != nil {
+			v = bands[i]
+		}
+		a.bands[i] = decay(a.bands[i], v, fall)
+	}
+
+	for c := range a.levels {
+		l := &a.levels[c]
+		l.RMS = decay(l.RMS, levels[c], fall)
+		if peaks[c] >= l.Peak {
+			l.Peak = peaks[c]
+			a.held[c] = peakHold
+			continue
+		}
+		if a.held[c] > 0 {
+			a.held[c] -= dt
+			continue
+		}
+		l.Peak = decay(l.Peak, max(peaks[c], l.RMS), peakFallRate*dt.Seconds())
+	}
+}
+
+// spectrum computes the bands of the FFTSize frames before end, without
+// smoothing.
+func (a *Analyzer) spectrum(r *Ring, end uint64, sampleRate int) []float64 {
+	frames := a.frames[:FFTSize]
+	n := r.ReadAt(frames, end)
+	// Zero-pad at the start when fewer frames are available
+	offset := FFTSize - n
+	for i := range FFTSize {
+		a.im[i] = 0
+		if i < offset {
+			a.re[i] = 0
+			continue
+		}
+		f := frames[i-offset]
+		a.re[i] = float64(f[0]+f[1]) / 2 * a.window[i]
+	}
+	a.plan.transform(a.re, a.im)
+
+	binHz := float64(sampleRate) / FFTSize
+	top := min(maxFreq, float64(sampleRate)/2)
+	bands := make([]float64, Bands)
+	for b := range bands {
+		lo := bandFreq(b, top)
+		hi := bandFreq(b+1, top)
+		first := int(math.Ceil(lo / binHz))
+		last := int(math.Floor(hi / binHz))
+		if last < first {
+			// Narrower than a bin: use the bin nearest to the band center
+			first = int(math.Round(math.Sqrt(lo*hi) / binHz))
+			last = first
+		}
+		var peak float64
+		for k := max(first, 1); k <= min(last, FFTSize/2); k++ {
+			peak = max(peak, math.Hypot(a.re[k], a.im[k])*a.gain)
+		}
+		bands[b] = scaleDB(peak, spectrumFloor)
+	}
+	return bands
+}
+
+// measure computes the RMS and peak levels of the frames in the meter
+// window before end.
+func (a *Analyzer) measure(r *Ring, end uint64, sampleRate int) (rms, peak [2]float64) {
+	size := min(int(float64(sampleRate)*meterWindow.Seconds()), len(a.frames))
+	frames := a.frames[:size]
+	n := r.ReadAt(frames, end)
+	if n == 0 {
+		return rms, peak
+	}
+	var sum [2]float64
+	for _, f := range frames[:n] {
+		for c, v := range f {
+			v := float64(v)
+			sum[c] += v * v
+			peak[c] = max(peak[c], math.Abs(v))
+		}
+	}
+	for c := range rms {
+		rms[c] = scaleDB(math.Sqrt(sum[c]/float64(n)), meterFloor)
+		peak[c] = scaleDB(peak[c], meterFloor)
+	}
+	return rms, peak
+}
+
+// bandFreq returns the lower edge of band b, the bands spanning minFreq
+// to top logarithmically.
+func bandFreq(b int, top float64) float64 {
+	return minFreq * math.Pow(top/minFreq, float64(b)/Bands)
+}
+
+// scaleDB maps an amplitude to 0 at floor dB and below, up to 1 at 0 dBFS.
+func scaleDB(amplitude, floor float64) float64 {
+	if amplitude <= 0 {
+		return 0
+	}
+	db := 20 * math.Log10(amplitude)
+	return max(0, min(1, 1-db/floor))
+}
+
+// decay returns target if it is above v, otherwise v lowered by at most
+// fall.
+func decay(v, target, fall float64) float64 {
+	if target >= v {
+		return target
+	}
+	return max(target, v-fall)
+}
@@ -0,0 +1,57 @@
+package visualizer
+
+import (
+	"math"
+	"math/bits"
+)
+
+// fftPlan holds the twiddle factors of a transform size, so they aren't
+// computed again for every frame.
+type fftPlan struct {
+	n        int
+	cos, sin []float64 // e^(-2πik/n) for k < n/2
+}
+
+// newFFTPlan prepares transforms of n points. n must be a power of two.
+func newFFTPlan(n int) *fftPlan {
+	p := &fftPlan{n: n, cos: make([]float64, n/2), sin: make([]float64, n/2)}
+	for k := range n / 2 {
+		angle := -2 * math.Pi * float64(k) / float64(n)
+		p.cos[k], p.sin[k] = math.Cos(angle), math.Sin(angle)
+	}
+	return p
+}
+
+// transform computes the discrete Fourier transform of re + i·im in place
+// with the iterative radix-2 algorithm.
+func (p *fftPlan) transform(re, im []float64) {
+	n := p.n
+	if n < 2 {
+		return
+	}
+	shift := 64 - bits.TrailingZeros(uint(n))
+
+	// Bit-reversal permutation
+	for i := range n {
+		j := int(bits.Reverse64(uint64(i)) >> shift)
+		if i < j {
+			re[i], re[j] = re[j], re[i]
+			im[i], im[j] = im[j], im[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		half := size / 2
+		stride := n / size
+		for start := 0; start < n; start += size {
+			for k := range half {
+				wr, wi := p.cos[k*stride], p.sin[k*stride]
+				a, b := start+k, start+k+half
+				tr := wr*re[b] - wi*im[b]
+				ti := wr*im[b] + wi*re[b]
+				re[b], im[b] = re[a]-tr, im[a]-ti
+				re[a], im[a] = re[a]+tr, im[a]+ti
+			}
+		}
+	}
+}
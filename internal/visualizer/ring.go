@@ -0,0 +1,84 @@
+// Package visualizer analyzes the audio being played for the spectrum
+// analyzer and level meters of the player bar.
+package visualizer
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// Ring holds the last frames played. It has a single writer, the audio
+// callback, and readers on the UI side; neither side blocks or takes a lock,
+// so the audio callback is never held up by rendering. A reader racing with
+// the writer may get a few frames of the next lap, which doesn't matter for
+// display.
+type Ring struct {
+	frames []atomic.Uint64 // Left and right samples as float32 bits
+	mask   uint64
+	pos    atomic.Uint64 // Frames written since creation
+}
+
+// NewRing creates a ring holding at least size frames. The size is rounded
+// up to a power of two.
+func NewRing(size int) *Ring {
+	n := 1
+	for n < size {
+		n <<= 1
+	}
+	return &Ring{
+		frames: make([]atomic.Uint64, n),
+		mask:   uint64(n - 1),
+	}
+}
+
+// Size returns the number of frames the ring holds.
+func (r *Ring) Size() int {
+	return len(r.frames)
+}
+
+// Written returns the number of frames written since the ring was created.
+func (r *Ring) Written() uint64 {
+	return r.pos.Load()
+}
+
+// Write appends frames, overwriting the oldest ones. It must not be called
+// concurrently with itself.
+func (r *Ring) Write(samples [][2]float64) {
+	pos := r.pos.Load()
+	for i, s := range samples {
+		r.frames[(pos+uint64(i))&r.mask].Store(pack(s))
+	}
+	r.pos.Store(pos + uint64(len(samples)))
+}
+
+// Read copies the latest frames into dst, oldest first, and returns how
+// many were copied: len(dst), or fewer if not that many were written yet.
+func (r *Ring) Read(dst [][2]float32) int {
+	return r.ReadAt(dst, r.pos.Load())
+}
+
+// ReadAt is like Read, for the frames before frame end (counted like
+// Written). Frames that were already overwritten are not copied.
+func (r *Ring) ReadAt(dst [][2]float32, end uint64) int {
+	written := r.pos.Load()
+	end = min(end, written)
+	size := uint64(len(r.frames))
+	n := min(uint64(len(dst)), end)
+	if written-end >= size {
+		return 0
+	}
+	n = min(n, size-(written-end))
+	start := end - n
+	for i := range n {
+		dst[i] = unpack(r.frames[(start+i)&r.mask].Load())
+	}
+	return int(n)
+}
+
+func pack(s [2]float64) uint64 {
+	return uint64(math.Float32bits(float32(s[0])))<<32 | uint64(math.Float32bits(float32(s[1])))
+}
+
+func unpack(v uint64) [2]float32 {
+	return [2]float32{math.Float32frombits(uint32(v >> 32)), math.Float32frombits(uint32(v))}
+}
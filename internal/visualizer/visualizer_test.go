@@ -0,0 +1,162 @@
+package visualizer
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sine returns n stereo frames of a sine at freq Hz with the given
+// amplitude on each channel.
+func sine(n, rate int, freq float64, amp [2]float64) [][2]float64 {
+	frames := make([][2]float64, n)
+	for i := range frames {
+		v := math.Sin(2 * math.Pi * freq * float64(i) / float64(rate))
+		frames[i] = [2]float64{v * amp[0], v * amp[1]}
+	}
+	return frames
+}
+
+func TestRing_RoundsSizeUp(t *testing.T) {
+	assert.Equal(t, 8, NewRing(5).Size())
+	assert.Equal(t, 8, NewRing(8).Size())
+}
+
+func TestRing_ReadsLatestFrames(t *testing.T) {
+	r := NewRing(4)
+
+	dst := make([][2]float32, 3)
+	assert.Equal(t, 0, r.Read(dst))
+
+	r.Write([][2]float64{{1, -1}, {2, -2}})
+	n := r.Read(dst)
+	require.Equal(t, 2, n)
+	assert.Equal(t, [][2]float32{{1, -1}, {2, -2}}, dst[:n])
+
+	// Wrap around: the oldest frames are overwritten
+	r.Write([][2]float64{{3, -3}, {4, -4}, {5, -5}})
+	assert.Equal(t, uint64(5), r.Written())
+	n = r.Read(dst)
+	require.Equal(t, 3, n)
+	assert.Equal(t, [][2]float32{{3, -3}, {4, -4}, {5, -5}}, dst)
+}
+
+func TestRing_ReadAt(t *testing.T) {
+	r := NewRing(4)
+	r.Write([][2]float64{{1, 1}, {2, 2}, {3, 3}, {4, 4}, {5, 5}, {6, 6}})
+
+	dst := make([][2]float32, 2)
+	n := r.ReadAt(dst, 4)
+	require.Equal(t, 2, n)
+	assert.Equal(t, [][2]float32{{3, 3}, {4, 4}}, dst)
+
+	// Frame 2 was overwritten, only frame 3 is left before frame 4
+	dst = make([][2]float32, 4)
+	n = r.ReadAt(dst, 4)
+	require.Equal(t, 2, n)
+	assert.Equal(t, [][2]float32{{3, 3}, {4, 4}}, dst[:n])
+
+	assert.Equal(t, 0, r.ReadAt(dst, 2), "everything before frame 2 is gone")
+}
+
+func TestFFT_MatchesDFT(t *testing.T) {
+	const n = 16
+	re := make([]float64, n)
+	im := make([]float64, n)
+	for i := range re {
+		re[i] = math.Sin(float64(i)) + 0.5*math.Cos(3*float64(i))
+	}
+	input := append([]float64(nil), re...)
+
+	newFFTPlan(n).transform(re, im)
+
+	for k := range n {
+		var wantRe, wantIm float64
+		for i, x := range input {
+			angle := -2 * math.Pi * float64(k*i) / n
+			wantRe += x * math.Cos(angle)
+			wantIm += x * math.Sin(angle)
+		}
+		assert.InDelta(t, wantRe, re[k], 1e-9, "re[%d]", k)
+		assert.InDelta(t, wantIm, im[k], 1e-9, "im[%d]", k)
+	}
+}
+
+func TestAnalyzer_SpectrumPeaksAtToneFrequency(t *testing.T) {
+	const rate = 44100
+	r := NewRing(RingSize)
+	r.Write(sine(rate/2, rate, 1000, [2]float64{0.5, 0.5}))
+
+	a := NewAnalyzer()
+	a.Update(r, rate, 0)
+	a.Update(r, rate, 200*time.Millisecond) // The window moves into the burst
+
+	bands := a.Bands()
+	loudest := 0
+	for i, v := range bands {
+		if v > bands[loudest] {
+			loudest = i
+		}
+	}
+	assert.LessOrEqual(t, bandFreq(loudest, maxFreq), 1000.0)
+	assert.Greater(t, bandFreq(loudest+1, maxFreq), 1000.0)
+	// A -6 dB tone on a 60 dB scale
+	assert.InDelta(t, 0.9, bands[loudest], 0.03)
+	assert.Less(t, bands[0], 0.3, "far bands stay low")
+}
+
+func TestAnalyzer_StereoLevels(t *testing.T) {
+	const rate = 48000
+	r := NewRing(RingSize)
+	r.Write(sine(rate/2, rate, 440, [2]float64{1, 0}))
+
+	a := NewAnalyzer()
+	a.Update(r, rate, 0)
+	a.Update(r, rate, 200*time.Millisecond)
+
+	levels := a.Levels()
+	// The RMS of a full scale sine is -3 dB, on a 48 dB scale
+	assert.InDelta(t, 1-3.01/48, levels[0].RMS, 0.01)
+	assert.InDelta(t, 1, levels[0].Peak, 0.01)
+	assert.Zero(t, levels[1].RMS)
+	assert.Zero(t, levels[1].Peak)
+}
+
+func TestAnalyzer_FallsWhenAudioStops(t *testing.T) {
+	const rate = 48000
+	r := NewRing(RingSize)
+	r.Write(sine(rate/2, rate, 440, [2]float64{1, 1}))
+
+	a := NewAnalyzer()
+	a.Update(r, rate, 0)
+	a.Update(r, rate, 200*time.Millisecond)
+	require.Positive(t, a.Levels()[0].RMS)
+
+	// Nothing written since: the values fall gradually, then the peak
+	// is released after its hold time
+	a.Update(r, rate, 100*time.Millisecond)
+	a.Update(r, rate, 500*time.Millisecond)
+	rms := a.Levels()[0].RMS
+	assert.Positive(t, rms)
+	assert.Less(t, rms, 1-3.01/48)
+	assert.InDelta(t, 1, a.Levels()[0].Peak, 0.01, "peak is held")
+
+	for range 20 {
+		a.Update(r, rate, 200*time.Millisecond)
+	}
+	assert.Zero(t, a.Levels()[0].RMS)
+	assert.Zero(t, a.Levels()[0].Peak)
+	for _, v := range a.Bands() {
+		assert.Zero(t, v)
+	}
+}
+
+func TestScaleDB(t *testing.T) {
+	assert.Zero(t, scaleDB(0, -60))
+	assert.Zero(t, scaleDB(0.0001, -60), "below the floor")
+	assert.InDelta(t, 0.5, scaleDB(math.Pow(10, -30.0/20), -60), 1e-9)
+	assert.InDelta(t, 1, scaleDB(2, -60), 1e-9, "clipped to full scale")
+}
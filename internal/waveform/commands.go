@@ -0,0 +1,73 @@
+package waveform
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// LoadedMsg carries the waveform of a track loaded in the background.
+type LoadedMsg struct {
+	Path     string
+	Waveform *Waveform
+	Err      error
+}
+
+// ProgressMsg reports that a file of a Job was processed.
+type ProgressMsg struct {
+	JobID   string
+	Current int
+	Total   int
+}
+
+// CompleteMsg signals a Job finished or was canceled.
+type CompleteMsg struct {
+	JobID    string
+	Computed int
+	Errors   []FileError
+	Canceled bool
+}
+
+// LoadCmd loads the waveform of a track, computing it if it isn't cached.
+func LoadCmd(store *Store, path string) tea.Cmd {
+	return func() tea.Msg {
+		w, err := store.Load(context.Background(), path)
+		return LoadedMsg{Path: path, Waveform: w, Err: err}
+	}
+}
+
+// BatchCmd starts the job. Each step processes one file and returns a
+// ProgressMsg; the handler chains to the next step with ContinueCmd.
+func BatchCmd(job *Job) tea.Cmd {
+	return computeNextFile(job)
+}
+
+// ContinueCmd returns a command to process the next file.
+func ContinueCmd(job *Job) tea.Cmd {
+	return computeNextFile(job)
+}
+
+// computeNextFile computes and caches the waveform of a single file.
+func computeNextFile(job *Job) tea.Cmd {
+	return func() tea.Msg {
+		path, ok := job.next()
+		if !ok || job.IsCanceled() {
+			canceled := job.IsCanceled()
+			job.complete()
+			return CompleteMsg{
+				JobID:    job.ID(),
+				Computed: job.Computed(),
+				Errors:   job.Errors(),
+				Canceled: canceled && ok,
+			}
+		}
+
+		_, err := job.store.Load(job.ctx, path)
+		if job.IsCanceled() {
+			// The next step completes the job
+			return job.progressMsg()
+		}
+		job.record(path, err)
+		return job.progressMsg()
+	}
+}
@@ -0,0 +1,129 @@
+package waveform
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/llehouerou/waves/internal/ui/jobbar"
+)
+
+// FileError records a file whose waveform could not be computed.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+// Job computes the waveforms of scanned files ahead of playback, one file
+// per step. Files already cached are skipped quickly.
+type Job struct {
+	mu       sync.Mutex
+	bar      *jobbar.Job
+	ctx      context.Context
+	cancel   context.CancelFunc
+	store    *Store
+	paths    []string
+	file     int // Index of the next file
+	computed int
+	errors   []FileError
+}
+
+// NewJob creates a job computing the waveforms of paths into store.
+func NewJob(store *Store, paths []string) *Job {
+	ctx, cancel := context.WithCancel(context.Background())
+	j := &Job{
+		bar: &jobbar.Job{
+			ID:    fmt.Sprintf("waveform-%d", time.Now().UnixNano()),
+			Label: "Computing waveforms",
+		},
+		ctx:    ctx,
+		cancel: cancel,
+		store:  store,
+	}
+	j.Add(paths...)
+	return j
+}
+
+// JobBar returns the jobbar.Job for display.
+func (j *Job) JobBar() *jobbar.Job {
+	return j.bar
+}
+
+// ID returns the job identifier.
+func (j *Job) ID() string {
+	return j.bar.ID
+}
+
+// Add queues more files on a running job.
+func (j *Job) Add(paths ...string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.paths = append(j.paths, paths...)
+	j.bar.Total += len(paths)
+}
+
+// Cancel stops the job; the file being processed is abandoned.
+func (j *Job) Cancel() {
+	j.cancel()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.bar.Label = "Canceling waveforms"
+}
+
+// IsCanceled returns true if the job was canceled.
+func (j *Job) IsCanceled() bool {
+	return j.ctx.Err() != nil
+}
+
+// Computed returns the number of files processed successfully.
+func (j *Job) Computed() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.computed
+}
+
+// Errors returns all failures.
+func (j *Job) Errors() []FileError {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]FileError(nil), j.errors...)
+}
+
+// next returns the file to process, or false when all are done.
+func (j *Job) next() (string, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file >= len(j.paths) {
+		return "", false
+	}
+	return j.paths[j.file], true
+}
+
+// record stores the result of the current file.
+func (j *Job) record(path string, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err != nil {
+		j.errors = append(j.errors, FileError{Path: path, Err: err})
+	} else {
+		j.computed++
+	}
+	j.file++
+	j.bar.Current++
+}
+
+// progressMsg reports the current progress.
+func (j *Job) progressMsg() ProgressMsg {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return ProgressMsg{JobID: j.bar.ID, Current: j.bar.Current, Total: j.bar.Total}
+}
+
+// complete marks the job as done.
+func (j *Job) complete() {
+	j.cancel()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.bar.Done = true
+}
@@ -0,0 +1,103 @@
+package waveform
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/llehouerou/waves/internal/cue"
+)
+
+// Store caches waveforms in the state database, keyed by track path. A
+// cached waveform is discarded when the file is modified.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore creates a store on the state database.
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Get returns the cached waveform of a track, or nil if there is none or
+// the file changed since it was computed.
+func (s *Store) Get(path string) (*Waveform, error) {
+	mtime, err := modTime(path)
+	if err != nil {
+		return nil, err
+	}
+	var cached int64
+	var data []byte
+	err = s.db.QueryRow(`SELECT mtime, data FROM waveforms WHERE path = ?`, path).
+		Scan(&cached, &data)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && cached != mtime) {
+		return nil, nil //nolint:nilnil // Not cached
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query waveform: %w", err)
+	}
+	w := &Waveform{}
+	if err := w.UnmarshalBinary(data); err != nil {
+		return nil, nil //nolint:nilerr,nilnil // A corrupted entry is recomputed
+	}
+	return w, nil
+}
+
+// Put caches the waveform of a track.
+func (s *Store) Put(path string, w *Waveform) error {
+	mtime, err := modTime(path)
+	if err != nil {
+		return err
+	}
+	data, err := w.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`
+		INSERT OR REPLACE INTO waveforms (path, mtime, data) VALUES (?, ?, ?)
+	`, path, mtime, data)
+	if err != nil {
+		return fmt.Errorf("save waveform: %w", err)
+	}
+	return nil
+}
+
+// Load returns the waveform of a track, computing and caching it if it
+// isn't cached yet.
+func (s *Store) Load(ctx context.Context, path string) (*Waveform, error) {
+	if w, err := s.Get(path); err != nil || w != nil {
+		return w, err
+	}
+	w, err := Compute(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Put(path, w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// modTime returns the modification time of a track in nanoseconds. A CUE
+// track changes when either its sheet or its audio file does.
+func modTime(path string) (int64, error) {
+	files := []string{path}
+	if cue.IsTrackPath(path) {
+		ref, err := cue.Lookup(path)
+		if err != nil {
+			return 0, err
+		}
+		files = []string{ref.SheetPath, ref.AudioPath}
+	}
+	var mtime int64
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			return 0, err
+		}
+		mtime = max(mtime, info.ModTime().UnixNano())
+	}
+	return mtime, nil
+}
@@ -0,0 +1,154 @@
+// Package waveform computes the downsampled waveforms drawn in the progress
+// bar of the player, and caches them in the state database.
+package waveform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/llehouerou/waves/internal/player"
+)
+
+const (
+	// Resolution is the number of points of a waveform, whatever the
+	// length of the track.
+	Resolution = 1024
+
+	bufferSize = 8192 // Frames decoded per read
+	blockSize  = 1024 // Frames summarized together while decoding
+)
+
+// Waveform is the outline of a track: the peak and RMS amplitude of
+// Resolution consecutive slices, from 0 to 1.
+type Waveform struct {
+	Peak []float64
+	RMS  []float64
+}
+
+// block summarizes blockSize frames while decoding, before the length of
+// the track is known for sure.
+type block struct {
+	peak  float64
+	sumSq float64
+	n     int
+}
+
+// Compute decodes a track and computes its waveform. Decoding stops early
+// with ctx.Err() if ctx is canceled.
+func Compute(ctx context.Context, path string) (*Waveform, error) {
+	streamer, _, err := player.Decode(path)
+	if err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	defer streamer.Close()
+
+	blocks := make([]block, 0, streamer.Len()/blockSize+1)
+	cur := block{}
+	buf := make([][2]float64, bufferSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		n, ok := streamer.Stream(buf)
+		for _, frame := range buf[:n] {
+			// Mono sum, like the ear hears both channels
+			v := (frame[0] + frame[1]) / 2
+			cur.peak = max(cur.peak, math.Abs(v))
+			cur.sumSq += v * v
+			cur.n++
+			if cur.n == blockSize {
+				blocks = append(blocks, cur)
+				cur = block{}
+			}
+		}
+		if !ok {
+			break
+		}
+	}
+	if err := streamer.Err(); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	if cur.n > 0 {
+		blocks = append(blocks, cur)
+	}
+	if len(blocks) == 0 {
+		return nil, errors.New("no audio")
+	}
+	return fromBlocks(blocks), nil
+}
+
+// fromBlocks merges blocks into Resolution points.
+func fromBlocks(blocks []block) *Waveform {
+	w := &Waveform{
+		Peak: make([]float64, Resolution),
+		RMS:  make([]float64, Resolution),
+	}
+	for i := range Resolution {
+		first := i * len(blocks) / Resolution
+		last := max((i+1)*len(blocks)/Resolution, first+1)
+		var peak, sumSq float64
+		var n int
+		for _, b := range blocks[first:last] {
+			peak = max(peak, b.peak)
+			sumSq += b.sumSq
+			n += b.n
+		}
+		w.Peak[i] = min(peak, 1)
+		w.RMS[i] = min(math.Sqrt(sumSq/float64(n)), 1)
+	}
+	return w
+}
+
+// Columns downsamples the waveform to n columns. Each column has the
+// highest peak and the overall RMS of the points it covers.
+func (w *Waveform) Columns(n int) (peak, rms []float64) {
+	peak = make([]float64, n)
+	rms = make([]float64, n)
+	points := len(w.Peak)
+	if points == 0 {
+		return peak, rms
+	}
+	for c := range n {
+		first := c * points / n
+		last := max((c+1)*points/n, first+1)
+		var sumSq float64
+		for i := first; i < last; i++ {
+			peak[c] = max(peak[c], w.Peak[i])
+			sumSq += w.RMS[i] * w.RMS[i]
+		}
+		rms[c] = math.Sqrt(sumSq / float64(last-first))
+	}
+	return peak, rms
+}
+
+// MarshalBinary encodes the waveform with one byte per value: the peaks,
+// then the RMS values.
+func (w *Waveform) MarshalBinary() ([]byte, error) {
+	if len(w.Peak) != len(w.RMS) {
+		return nil, errors.New("waveform: peak and RMS lengths differ")
+	}
+	data := make([]byte, 0, 2*len(w.Peak))
+	for _, values := range [][]float64{w.Peak, w.RMS} {
+		for _, v := range values {
+			data = append(data, byte(math.Round(max(0, min(v, 1))*math.MaxUint8)))
+		}
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes a waveform encoded by MarshalBinary.
+func (w *Waveform) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || len(data)%2 != 0 {
+		return errors.New("waveform: invalid data")
+	}
+	n := len(data) / 2
+	w.Peak = make([]float64, n)
+	w.RMS = make([]float64, n)
+	for i := range n {
+		w.Peak[i] = float64(data[i]) / math.MaxUint8
+		w.RMS[i] = float64(data[n+i]) / math.MaxUint8
+	}
+	return nil
+}
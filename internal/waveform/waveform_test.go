@@ -0,0 +1,160 @@
+package waveform
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/llehouerou/waves/internal/state"
+)
+
+const testFile = "../player/testdata/vorbis_44100_stereo.ogg"
+
+// copyTestFile copies the Vorbis test file into a temporary directory.
+func copyTestFile(t *testing.T) string {
+	t.Helper()
+	data, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Skipf("test file not available: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "a.ogg")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+// setupTestDB opens a database with the schema of the state package.
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := state.OpenDB(filepath.Join(t.TempDir(), "waves.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestCompute(t *testing.T) {
+	path := copyTestFile(t)
+
+	w, err := Compute(context.Background(), path)
+	require.NoError(t, err)
+	require.Len(t, w.Peak, Resolution)
+	require.Len(t, w.RMS, Resolution)
+	var loudest float64
+	for i := range w.Peak {
+		assert.LessOrEqual(t, w.RMS[i], w.Peak[i]+1e-9, "point %d", i)
+		loudest = max(loudest, w.Peak[i])
+	}
+	assert.Positive(t, loudest)
+}
+
+func TestCompute_Canceled(t *testing.T) {
+	path := copyTestFile(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Compute(ctx, path)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFromBlocks_ShortTrack(t *testing.T) {
+	// Fewer blocks than points: each block is repeated
+	w := fromBlocks([]block{{peak: 0.5, sumSq: 0.25 * 4, n: 4}, {peak: 1, sumSq: 4, n: 4}})
+	assert.InDelta(t, 0.5, w.Peak[0], 1e-9)
+	assert.InDelta(t, 0.5, w.RMS[0], 1e-9)
+	assert.InDelta(t, 1, w.Peak[Resolution-1], 1e-9)
+	assert.InDelta(t, 1, w.RMS[Resolution-1], 1e-9)
+}
+
+func TestColumns(t *testing.T) {
+	w := &Waveform{
+		Peak: []float64{0.2, 0.8, 0.4, 0.4},
+		RMS:  []float64{0.1, 0.7, 0.3, 0.3},
+	}
+	peak, rms := w.Columns(2)
+	assert.Equal(t, []float64{0.8, 0.4}, peak)
+	assert.InDelta(t, 0.5, rms[0], 1e-9)
+	assert.InDelta(t, 0.3, rms[1], 1e-9)
+
+	// More columns than points
+	peak, _ = w.Columns(8)
+	assert.Equal(t, []float64{0.2, 0.2, 0.8, 0.8, 0.4, 0.4, 0.4, 0.4}, peak)
+}
+
+func TestMarshalBinary_RoundTrip(t *testing.T) {
+	w := &Waveform{Peak: []float64{0, 0.5, 1}, RMS: []float64{0, 0.25, 2}}
+	data, err := w.MarshalBinary()
+	require.NoError(t, err)
+	assert.Len(t, data, 6)
+
+	var got Waveform
+	require.NoError(t, got.UnmarshalBinary(data))
+	assert.InDeltaSlice(t, []float64{0, 0.5, 1}, got.Peak, 0.01)
+	assert.InDeltaSlice(t, []float64{0, 0.25, 1}, got.RMS, 0.01, "clipped to 1")
+
+	assert.Error(t, got.UnmarshalBinary([]byte{1, 2, 3}))
+}
+
+func TestStore_CachesUntilModified(t *testing.T) {
+	path := copyTestFile(t)
+	s := NewStore(setupTestDB(t))
+
+	w, err := s.Get(path)
+	require.NoError(t, err)
+	assert.Nil(t, w, "not cached yet")
+
+	loaded, err := s.Load(context.Background(), path)
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+
+	w, err = s.Get(path)
+	require.NoError(t, err)
+	require.NotNil(t, w)
+	assert.Len(t, w.Peak, Resolution)
+
+	// Touching the file invalidates the cached waveform
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(path, later, later))
+	w, err = s.Get(path)
+	require.NoError(t, err)
+	assert.Nil(t, w)
+}
+
+func TestStore_MissingFile(t *testing.T) {
+	s := NewStore(setupTestDB(t))
+	_, err := s.Load(context.Background(), filepath.Join(t.TempDir(), "missing.ogg"))
+	assert.Error(t, err)
+}
+
+func TestJob_ComputesAllFiles(t *testing.T) {
+	path := copyTestFile(t)
+	missing := filepath.Join(t.TempDir(), "missing.ogg")
+	s := NewStore(setupTestDB(t))
+	job := NewJob(s, []string{path, missing})
+
+	cmd := BatchCmd(job)
+	var done CompleteMsg
+	for range 10 {
+		msg := cmd()
+		if c, ok := msg.(CompleteMsg); ok {
+			done = c
+			break
+		}
+		require.IsType(t, ProgressMsg{}, msg)
+		cmd = ContinueCmd(job)
+	}
+
+	assert.Equal(t, 1, done.Computed)
+	require.Len(t, done.Errors, 1)
+	assert.Equal(t, missing, done.Errors[0].Path)
+	assert.False(t, done.Canceled)
+	assert.True(t, job.JobBar().Done)
+
+	w, err := s.Get(path)
+	require.NoError(t, err)
+	assert.NotNil(t, w)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
@@ -8,6 +9,7 @@ import (
 
 	"github.com/llehouerou/waves/internal/app"
 	"github.com/llehouerou/waves/internal/config"
+	"github.com/llehouerou/waves/internal/ctl"
 	"github.com/llehouerou/waves/internal/diag"
 	"github.com/llehouerou/waves/internal/icons"
 	"github.com/llehouerou/waves/internal/state"
@@ -22,11 +24,27 @@ func main() {
 		fmt.Println("waves", version)
 		os.Exit(0)
 	}
+	if len(os.Args) > 1 && os.Args[1] == "playlist" {
+		os.Exit(runPlaylist(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "ctl" {
+		os.Exit(runCtl(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "daemon" {
+		os.Exit(runDaemon(os.Args[2:], os.Stderr))
+	}
 
-	os.Exit(run())
+	flags := flag.NewFlagSet("waves", flag.ExitOnError)
+	output := flags.String("output", "", `audio output: "speaker", "wav" or "pcm" (overrides the config)`)
+	outputPath := flags.String("output-path", "", `file written by the wav and pcm outputs, "-" for stdout`)
+	_ = flags.Parse(os.Args[1:])
+
+	os.Exit(run(*output, *outputPath))
 }
 
-func run() int {
+// run starts the application. output and outputPath override the output
+// configuration when not empty.
+func run(output, outputPath string) int {
 	// Capture stderr from C libraries (ALSA, minimp3) to prevent TUI corruption
 	_ = stderr.Start()
 	defer stderr.Stop()
@@ -46,6 +64,13 @@ func run() int {
 		return 1
 	}
 
+	if output != "" {
+		cfg.Output.Backend = output
+	}
+	if outputPath != "" {
+		cfg.Output.Path = outputPath
+	}
+
 	if err := styles.InitTheme(cfg.Theme); err != nil {
 		stderr.WriteOriginal(fmt.Sprintf("Error loading theme: %v\n", err))
 		return 1
@@ -59,14 +84,37 @@ func run() int {
 		return 1
 	}
 
-	m, err := app.New(cfg, stateMgr)
-	if err != nil {
+	// Attach to a running daemon, which keeps playing when we quit
+	remote := attachDaemon()
+	if remote != nil && (output != "" || outputPath != "") {
+		_ = remote.Close()
+		stateMgr.Close()
+		stderr.WriteOriginal("Error: --output and --output-path can't be used while a daemon plays, stop it with \"waves ctl shutdown\" first\n")
+		return 1
+	}
+	var m app.Model
+	if remote != nil {
+		m = app.Attach(cfg, stateMgr, remote)
+	} else if m, err = app.New(cfg, stateMgr); err != nil {
 		stateMgr.Close()
 		stderr.WriteOriginal(fmt.Sprintf("Error initializing: %v\n", err))
 		return 1
 	}
 
-	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	opts := []tea.ProgramOption{tea.WithAltScreen(), tea.WithMouseCellMotion()}
+	if remote == nil && cfg.GetOutputConfig().WritesToStdout() {
+		// Audio goes to stdout, draw the interface on the terminal directly
+		tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+		if err != nil {
+			stateMgr.Close()
+			stderr.WriteOriginal(fmt.Sprintf("Error opening terminal: %v\n", err))
+			return 1
+		}
+		defer tty.Close()
+		opts = append(opts, tea.WithOutput(tty))
+	}
+
+	p := tea.NewProgram(m, opts...)
 
 	if _, err := p.Run(); err != nil {
 		stderr.WriteOriginal(fmt.Sprintf("Error running program: %v\n", err))
@@ -75,3 +123,17 @@ func run() int {
 
 	return 0
 }
+
+// attachDaemon attaches to the daemon started by "waves daemon", returning
+// nil when none runs.
+func attachDaemon() *ctl.Remote {
+	path, err := ctl.SocketPath()
+	if err != nil {
+		return nil
+	}
+	remote, err := ctl.Attach(path)
+	if err != nil {
+		return nil
+	}
+	return remote
+}
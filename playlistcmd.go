@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/llehouerou/waves/internal/library"
+	"github.com/llehouerou/waves/internal/playlistfile"
+	"github.com/llehouerou/waves/internal/playlists"
+	"github.com/llehouerou/waves/internal/state"
+)
+
+const playlistUsage = `usage:
+  waves playlist import [-name NAME] FILE...
+  waves playlist export [-relative] PLAYLIST FILE
+  waves playlist export [-relative] -queue FILE
+
+FILE is an .m3u, .m3u8, .pls or .xspf playlist. PLAYLIST is a playlist name or ID.
+`
+
+// runPlaylist runs the playlist subcommands, which import and export
+// playlist files without starting the interface.
+func runPlaylist(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, playlistUsage)
+		return 2
+	}
+
+	stateMgr, err := state.Open()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error opening state: %v\n", err)
+		return 1
+	}
+	defer stateMgr.Close()
+
+	lib := library.New(stateMgr.DB())
+	pls := playlists.New(stateMgr.DB(), lib)
+
+	switch args[0] {
+	case "import":
+		return runPlaylistImport(pls, lib, args[1:], stdout, stderr)
+	case "export":
+		return runPlaylistExport(pls, stateMgr, args[1:], stderr)
+	}
+	fmt.Fprint(stderr, playlistUsage)
+	return 2
+}
+
+func runPlaylistImport(pls *playlists.Playlists, lib *library.Library, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("waves playlist import", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	name := flags.String("name", "", "playlist name (defaults to the title or name of the file)")
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 {
+		fmt.Fprint(stderr, playlistUsage)
+		return 2
+	}
+
+	code := 0
+	for _, path := range flags.Args() {
+		result, err := playlistfile.Import(pls, lib, path, nil, *name)
+		if err != nil && !errors.Is(err, playlistfile.ErrNoTracks) {
+			fmt.Fprintf(stderr, "%s: %v\n", path, err)
+			code = 1
+			continue
+		}
+		if errors.Is(err, playlistfile.ErrNoTracks) {
+			fmt.Fprintf(stderr, "%s: %v\n", path, err)
+			code = 1
+		} else {
+			fmt.Fprintf(stdout, "%s: %s\n", path, result.Summary())
+		}
+		for _, e := range result.Unresolved {
+			fmt.Fprintf(stdout, "  not found: %s\n", e)
+		}
+	}
+	return code
+}
+
+func runPlaylistExport(pls *playlists.Playlists, stateMgr *state.Manager, args []string, stderr io.Writer) int {
+	flags := flag.NewFlagSet("waves playlist export", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	relative := flags.Bool("relative", false, "write paths relative to the playlist file")
+	queue := flags.Bool("queue", false, "export the saved queue instead of a playlist")
+	if err := flags.Parse(args); err != nil {
+		fmt.Fprint(stderr, playlistUsage)
+		return 2
+	}
+
+	if *queue {
+		if flags.NArg() != 1 {
+			fmt.Fprint(stderr, playlistUsage)
+			return 2
+		}
+		if err := exportQueue(stateMgr, flags.Arg(0), *relative); err != nil {
+			fmt.Fprintf(stderr, "Error exporting queue: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	if flags.NArg() != 2 {
+		fmt.Fprint(stderr, playlistUsage)
+		return 2
+	}
+	pl, err := findPlaylist(pls, flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "Playlist %q not found\n", flags.Arg(0))
+		return 1
+	}
+	if _, err := playlistfile.ExportPlaylist(pls, pl.ID, flags.Arg(1), *relative); err != nil {
+		fmt.Fprintf(stderr, "Error exporting %q: %v\n", pl.Name, err)
+		return 1
+	}
+	return 0
+}
+
+// findPlaylist finds a playlist by ID, then by name.
+func findPlaylist(pls *playlists.Playlists, arg string) (*playlists.Playlist, error) {
+	if id, err := strconv.ParseInt(arg, 10, 64); err == nil {
+		if pl, err := pls.Get(id); err == nil {
+			return pl, nil
+		}
+	}
+	return pls.GetByName(arg)
+}
+
+// exportQueue writes the queue saved by the last session.
+func exportQueue(stateMgr *state.Manager, path string, relative bool) error {
+	q, err := stateMgr.GetQueue()
+	if err != nil {
+		return err
+	}
+	entries := make([]playlistfile.Entry, len(q.Tracks))
+	for i, t := range q.Tracks {
+		entries[i] = playlistfile.Entry{Location: t.Path, Artist: t.Artist, Title: t.Title}
+	}
+	return playlistfile.Export(path, "Queue", entries, relative)
+}